@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Generates the typed clientset, listers and informers for the karpenter.sh APIs under pkg/client so that external
+# controllers can use them without the dynamic client or controller-runtime caches
+CODE_GENERATOR_VERSION="v0.32.0"
+MODULE="sigs.k8s.io/karpenter"
+HEADER="hack/boilerplate.go.txt"
+
+rm -rf pkg/client/clientset pkg/client/listers pkg/client/informers
+go run "k8s.io/code-generator/cmd/client-gen@${CODE_GENERATOR_VERSION}" \
+  --go-header-file "${HEADER}" \
+  --input-base "" \
+  --input "${MODULE}/pkg/apis/v1" \
+  --input "${MODULE}/pkg/apis/v1alpha1" \
+  --clientset-name versioned \
+  --output-pkg "${MODULE}/pkg/client/clientset" \
+  --output-dir pkg/client/clientset
+go run "k8s.io/code-generator/cmd/lister-gen@${CODE_GENERATOR_VERSION}" \
+  --go-header-file "${HEADER}" \
+  --output-pkg "${MODULE}/pkg/client/listers" \
+  --output-dir pkg/client/listers \
+  ./pkg/apis/v1 \
+  ./pkg/apis/v1alpha1
+go run "k8s.io/code-generator/cmd/informer-gen@${CODE_GENERATOR_VERSION}" \
+  --go-header-file "${HEADER}" \
+  --versioned-clientset-package "${MODULE}/pkg/client/clientset/versioned" \
+  --listers-package "${MODULE}/pkg/client/listers" \
+  --output-pkg "${MODULE}/pkg/client/informers" \
+  --output-dir pkg/client/informers \
+  ./pkg/apis/v1 \
+  ./pkg/apis/v1alpha1
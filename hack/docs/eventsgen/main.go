@@ -0,0 +1,48 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+// Generates the events documentation from the events catalog, e.g. go run hack/docs/eventsgen/main.go docs/events.md
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: %s <output file>", os.Args[0])
+	}
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "# Events")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "<!-- Generated by hack/docs/eventsgen from pkg/events/catalog.go. DO NOT EDIT. -->")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "Karpenter publishes the following Kubernetes events. Reasons are stable across versions. Values that are filled into a message are written in braces.")
+	fmt.Fprintln(b)
+	fmt.Fprintln(b, "| Reason | Type | Involved Objects | Message | Description |")
+	fmt.Fprintln(b, "|--------|------|------------------|---------|-------------|")
+	for _, s := range events.Catalog {
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", s.Reason, s.Type, strings.Join(s.InvolvedObjects, ", "), strings.ReplaceAll(s.Message, "|", "\\|"), s.Description)
+	}
+	if err := os.WriteFile(os.Args[1], []byte(b.String()), 0644); err != nil {
+		log.Fatalf("writing events documentation, %s", err)
+	}
+}
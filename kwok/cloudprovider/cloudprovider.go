@@ -51,8 +51,12 @@ type CloudProvider struct {
 }
 
 func (c CloudProvider) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
+	nodePool := &v1.NodePool{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: nodeClaim.Labels[v1.NodePoolLabelKey]}, nodePool); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("getting nodepool, %w", err)
+	}
 	// Create the Node because KwoK nodes don't have a kubelet, which is what Karpenter normally relies on to create the node.
-	node, err := c.toNode(nodeClaim)
+	node, err := c.toNode(nodeClaim, c.instanceTypesForNodePool(nodePool))
 	if err != nil {
 		return nil, fmt.Errorf("translating nodeclaim to node, %w", err)
 	}
@@ -108,9 +112,30 @@ func (c CloudProvider) List(ctx context.Context) ([]*v1.NodeClaim, error) {
 	return nodeClaims, nil
 }
 
-// Return the hard-coded instance types.
+// Return the hard-coded instance types, with the kube-reserved and system-reserved overhead computed using the NodePool's strategy.
 func (c CloudProvider) GetInstanceTypes(ctx context.Context, nodePool *v1.NodePool) ([]*cloudprovider.InstanceType, error) {
-	return c.instanceTypes, nil
+	return c.instanceTypesForNodePool(nodePool), nil
+}
+
+func (c CloudProvider) instanceTypesForNodePool(nodePool *v1.NodePool) []*cloudprovider.InstanceType {
+	strategy := cloudprovider.KubeReservedStrategyForNodePool(nodePool, v1.KubeReservedStrategyFlat)
+	if strategy == v1.KubeReservedStrategyFlat {
+		return c.instanceTypes
+	}
+	return lo.Map(c.instanceTypes, func(it *cloudprovider.InstanceType, _ int) *cloudprovider.InstanceType {
+		return &cloudprovider.InstanceType{
+			Name:         it.Name,
+			Family:       it.Family,
+			Requirements: it.Requirements,
+			Offerings:    it.Offerings,
+			Capacity:     it.Capacity,
+			Overhead: &cloudprovider.InstanceTypeOverhead{
+				KubeReserved:      cloudprovider.KubeReserved(strategy, it.Capacity),
+				SystemReserved:    cloudprovider.SystemReserved(strategy, it.Capacity),
+				EvictionThreshold: it.Overhead.EvictionThreshold,
+			},
+		}
+	})
 }
 
 // Return nothing since there's no cloud provider drift.
@@ -130,8 +155,8 @@ func (c *CloudProvider) RepairPolicies() []cloudprovider.RepairPolicy {
 	return []cloudprovider.RepairPolicy{}
 }
 
-func (c CloudProvider) getInstanceType(instanceTypeName string) (*cloudprovider.InstanceType, error) {
-	it, found := lo.Find(c.instanceTypes, func(it *cloudprovider.InstanceType) bool {
+func getInstanceType(instanceTypes []*cloudprovider.InstanceType, instanceTypeName string) (*cloudprovider.InstanceType, error) {
+	it, found := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool {
 		return it.Name == instanceTypeName
 	})
 	if !found {
@@ -140,7 +165,7 @@ func (c CloudProvider) getInstanceType(instanceTypeName string) (*cloudprovider.
 	return it, nil
 }
 
-func (c CloudProvider) toNode(nodeClaim *v1.NodeClaim) (*corev1.Node, error) {
+func (c CloudProvider) toNode(nodeClaim *v1.NodeClaim, instanceTypes []*cloudprovider.InstanceType) (*corev1.Node, error) {
 	newName := strings.Replace(namesgenerator.GetRandomName(0), "_", "-", -1)
 	//nolint
 	newName = fmt.Sprintf("%s-%d", newName, rand.Uint32())
@@ -157,7 +182,7 @@ func (c CloudProvider) toNode(nodeClaim *v1.NodeClaim) (*corev1.Node, error) {
 	var cheapestOffering *cloudprovider.Offering
 	// Loop through instance type values, as the node claim will only have the In operator.
 	for _, val := range req.Values {
-		it, err := c.getInstanceType(val)
+		it, err := getInstanceType(instanceTypes, val)
 		if err != nil {
 			return nil, fmt.Errorf("instance type %s not found", val)
 		}
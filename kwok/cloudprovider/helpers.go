@@ -183,6 +183,7 @@ func newInstanceType(options InstanceTypeOptions) *cloudprovider.InstanceType {
 
 	return &cloudprovider.InstanceType{
 		Name:         options.Name,
+		Family:       options.instanceTypeLabels[v1alpha1.InstanceFamilyLabelKey],
 		Requirements: requirements,
 		Offerings: lo.Map(options.Offerings, func(off KWOKOffering, _ int) cloudprovider.Offering {
 			return cloudprovider.Offering{
@@ -195,10 +196,8 @@ func newInstanceType(options InstanceTypeOptions) *cloudprovider.InstanceType {
 		}),
 		Capacity: options.Resources,
 		Overhead: &cloudprovider.InstanceTypeOverhead{
-			KubeReserved: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("100m"),
-				corev1.ResourceMemory: resource.MustParse("10Mi"),
-			},
+			KubeReserved:   cloudprovider.KubeReserved(v1.KubeReservedStrategyFlat, options.Resources),
+			SystemReserved: cloudprovider.SystemReserved(v1.KubeReservedStrategyFlat, options.Resources),
 		},
 	}
 }
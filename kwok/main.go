@@ -43,5 +43,6 @@ func main() {
 			op.EventRecorder,
 			cloudProvider,
 			clusterState,
+			nil,
 		)...).Start(ctx)
 }
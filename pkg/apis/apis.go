@@ -34,8 +34,20 @@ var (
 	NodePoolCRD []byte
 	//go:embed crds/karpenter.sh_nodeclaims.yaml
 	NodeClaimCRD []byte
-	CRDs         = []*apiextensionsv1.CustomResourceDefinition{
+	//go:embed crds/karpenter.sh_labelnormalizations.yaml
+	LabelNormalizationCRD []byte
+	//go:embed crds/karpenter.sh_orphanreports.yaml
+	OrphanReportCRD []byte
+	//go:embed crds/karpenter.sh_unavailableofferings.yaml
+	UnavailableOfferingsCRD []byte
+	//go:embed crds/karpenter.sh_nodedisruptionbudgets.yaml
+	NodeDisruptionBudgetCRD []byte
+	CRDs                    = []*apiextensionsv1.CustomResourceDefinition{
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodePoolCRD),
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodeClaimCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](LabelNormalizationCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](OrphanReportCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](UnavailableOfferingsCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodeDisruptionBudgetCRD),
 	}
 )
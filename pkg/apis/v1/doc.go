@@ -21,19 +21,38 @@ limitations under the License.
 package v1 // doc.go is discovered by codegen
 
 import (
+	"github.com/samber/lo"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 )
 
-func init() {
-	gv := schema.GroupVersion{Group: apis.Group, Version: "v1"}
-	v1.AddToGroupVersion(scheme.Scheme, gv)
-	scheme.Scheme.AddKnownTypes(gv,
+var (
+	// SchemeGroupVersion is the group version that the types are registered under, used by the generated clients
+	SchemeGroupVersion = schema.GroupVersion{Group: apis.Group, Version: "v1"}
+	SchemeBuilder      = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme registers the types with a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+func addKnownTypes(s *runtime.Scheme) error {
+	v1.AddToGroupVersion(s, SchemeGroupVersion)
+	s.AddKnownTypes(SchemeGroupVersion,
 		&NodePool{},
 		&NodePoolList{},
 		&NodeClaim{},
 		&NodeClaimList{})
+	return nil
+}
+
+func init() {
+	lo.Must0(AddToScheme(scheme.Scheme))
 }
@@ -33,6 +33,9 @@ const (
 	ArchitectureArm64    = "arm64"
 	CapacityTypeSpot     = "spot"
 	CapacityTypeOnDemand = "on-demand"
+	// CapacityTypeReserved is capacity that has been reserved or committed to ahead of time with the cloud provider.
+	// Karpenter prefers reserved offerings over spot and on-demand offerings when they're available.
+	CapacityTypeReserved = "reserved"
 )
 
 // Karpenter specific domains and labels
@@ -41,15 +44,81 @@ const (
 	NodeInitializedLabelKey = apis.Group + "/initialized"
 	NodeRegisteredLabelKey  = apis.Group + "/registered"
 	CapacityTypeLabelKey    = apis.Group + "/capacity-type"
+	// NodePoolGenerationLabelKey is the generation of the NodePool that a NodeClaim and its Node were launched from
+	NodePoolGenerationLabelKey = apis.Group + "/nodepool-generation"
+	// CanaryLabelKey is set on the canary pods that Karpenter creates to verify that a NodePool can provision capacity,
+	// e.g. karpenter.sh/canary=default
+	CanaryLabelKey = apis.Group + "/canary"
 )
 
 // Karpenter specific annotations
 const (
-	DoNotDisruptAnnotationKey                  = apis.Group + "/do-not-disrupt"
-	ProviderCompatibilityAnnotationKey         = apis.CompatibilityGroup + "/provider"
-	NodePoolHashAnnotationKey                  = apis.Group + "/nodepool-hash"
-	NodePoolHashVersionAnnotationKey           = apis.Group + "/nodepool-hash-version"
+	DoNotDisruptAnnotationKey          = apis.Group + "/do-not-disrupt"
+	ProviderCompatibilityAnnotationKey = apis.CompatibilityGroup + "/provider"
+	NodePoolHashAnnotationKey          = apis.Group + "/nodepool-hash"
+	NodePoolHashVersionAnnotationKey   = apis.Group + "/nodepool-hash-version"
+	// NodePoolTemplateHashesAnnotationKey holds the NodePool's TemplateHashes, which static drift compares instead of the
+	// nodepool-hash when the NodePool propagates some of its template fields in place
+	NodePoolTemplateHashesAnnotationKey        = apis.Group + "/nodepool-template-hashes"
+	SchedulingRunAnnotationKey                 = apis.Group + "/scheduling-run"
 	NodeClaimTerminationTimestampAnnotationKey = apis.Group + "/nodeclaim-termination-timestamp"
+	NodeExpireAfterAnnotationKey               = apis.Group + "/expire-after"
+	InstanceFamilyPreferenceAnnotationKey      = apis.Group + "/instance-family-preference"
+	CostlyToMoveWeightAnnotationKey            = apis.Group + "/costly-to-move-weight"
+	// ExpiredNodeDrainedAnnotationKey is set on a node that isn't owned by Karpenter to the RFC3339 timestamp that it
+	// finished draining after it expired, so that a node its owner never deletes only holds up the rotation for a while
+	ExpiredNodeDrainedAnnotationKey = apis.Group + "/expired-node-drained"
+	// RestartNodesAnnotationKey is set on a NodePool to an RFC3339 timestamp to drift all of its NodeClaims that were
+	// created before that time, e.g. karpenter.sh/restart-nodes=2024-01-01T00:00:00Z
+	RestartNodesAnnotationKey = apis.Group + "/restart-nodes"
+	// CanaryTimestampAnnotationKey is set on a NodePool to the RFC3339 timestamp that its last canary pod finished, so
+	// that the canary interval is kept across restarts
+	CanaryTimestampAnnotationKey = apis.Group + "/canary-timestamp"
+	// PredictedInstanceTypeAnnotationKey is the instance type that the scheduler expected the cloud provider to launch
+	// for a NodeClaim. The cloud provider may launch any of the NodeClaim's compatible instance types.
+	PredictedInstanceTypeAnnotationKey = apis.Group + "/predicted-instance-type"
+	// JobExpectedCompletionTimeAnnotationKey is set on Job pods to the RFC3339 timestamp that they're expected to complete
+	// by, e.g. karpenter.sh/expected-completion-time=2024-01-01T00:00:00Z
+	JobExpectedCompletionTimeAnnotationKey = apis.Group + "/expected-completion-time"
+	// VirtualNodeAnnotationKey is set by cloud providers on the NodeClaims that they launch as virtual nodes, e.g.
+	// with a virtual kubelet. Virtual nodes don't heartbeat, so they aren't required to be Ready to be initialized, and
+	// their pods are managed by the provider, so they aren't drained when the node is terminated.
+	VirtualNodeAnnotationKey = apis.Group + "/virtual-node"
+	// ReservedResourceAnnotationKeyPrefix is the prefix of annotations that reserve headroom for a resource on a node
+	// e.g. karpenter.sh/reserved-cpu=500m
+	ReservedResourceAnnotationKeyPrefix = apis.Group + "/reserved-"
+	// TagAnnotationKeyPrefix is the prefix of annotations that pass tags for a NodeClaim's instance to the cloud provider
+	// e.g. karpenter.sh/tag-environment=prod
+	TagAnnotationKeyPrefix = apis.Group + "/tag-"
+	// OwnerAnnotationKey is set on NodeClaims that users create directly, without pending pods, to mark who manages
+	// their lifecycle, e.g. karpenter.sh/owner=manual. Manually owned NodeClaims are launched like any other NodeClaim
+	// but are never consolidated, since nothing is expected to schedule to them.
+	OwnerAnnotationKey = apis.Group + "/owner"
+	// AdoptedProviderIDAnnotationKey is set on NodeClaims that were created to adopt an existing Node to the provider id
+	// of its instance, so that the instance is resolved rather than launching another one
+	AdoptedProviderIDAnnotationKey = apis.Group + "/adopted-provider-id"
+	// DrainOrderAnnotationKey is set on pods to an integer that orders their eviction when the Annotation drain ordering
+	// is used, e.g. karpenter.sh/drain-order=10. Pods are drained in ascending order, pods without it are ordered as 0.
+	DrainOrderAnnotationKey = apis.Group + "/drain-order"
+	// DeletionBlockAnnotationKeyPrefix is the prefix of annotations that external systems set on a node to delay its
+	// termination, e.g. deletion-block.karpenter.sh/checkpointer=30m. The value is how long the block may hold the node
+	// from when it started deleting, and it's capped by --max-deletion-block-ttl. Removing the annotation releases it.
+	DeletionBlockAnnotationKeyPrefix = "deletion-block." + apis.Group + "/"
+	// DrainSnapshotAnnotationKey is set on a node when it starts draining to a JSON summary of the pods that were on it,
+	// their owners and the reason that the node was terminated. The summary lists a capped number of pods and counts
+	// the pods that were omitted.
+	DrainSnapshotAnnotationKey = apis.Group + "/drain-snapshot"
+)
+
+// Owners of NodeClaims that are set through the karpenter.sh/owner annotation
+const (
+	OwnerManual = "manual"
+)
+
+// Cluster Autoscaler annotations that Karpenter honors to ease migrations from the Cluster Autoscaler
+const (
+	ClusterAutoscalerSafeToEvictAnnotationKey             = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	ClusterAutoscalerSafeToEvictLocalVolumesAnnotationKey = "cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes"
 )
 
 // Karpenter specific finalizers
@@ -123,6 +123,8 @@ func (ncr *NodeClassReference) GroupKind() schema.GroupKind {
 type Provider = runtime.RawExtension
 
 // NodeClaim is the Schema for the NodeClaims API
+// +genclient
+// +genclient:nonNamespaced
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=nodeclaims,scope=Cluster,categories=karpenter
 // +kubebuilder:subresource:status
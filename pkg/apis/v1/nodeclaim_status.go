@@ -33,6 +33,50 @@ const (
 	ConditionTypeDisruptionReason     = "DisruptionReason"
 )
 
+// Reasons that the Consolidatable status condition is false after the NodePool's consolidateAfter has elapsed
+const (
+	ConsolidatableReasonNominated           = "Nominated"
+	ConsolidatableReasonDoNotDisruptPod     = "DoNotDisruptPod"
+	ConsolidatableReasonPodDisruptionBudget = "PodDisruptionBudget"
+	ConsolidatableReasonManuallyOwned       = "ManuallyOwned"
+)
+
+// TerminationReason is the reason that a NodeClaim was terminated
+// +kubebuilder:validation:Enum:={consolidation,expiration,drift,interruption,repair,manual,insufficient_capacity,nodeclass_not_ready}
+type TerminationReason string
+
+const (
+	TerminationReasonConsolidation TerminationReason = "consolidation"
+	TerminationReasonExpiration    TerminationReason = "expiration"
+	TerminationReasonDrift         TerminationReason = "drift"
+	// TerminationReasonInterruption is used when the instance was terminated outside of Karpenter's control, including
+	// when its Node was deleted because the instance no longer exists
+	TerminationReasonInterruption TerminationReason = "interruption"
+	// TerminationReasonRepair is used when a registered node was replaced because it was unhealthy
+	TerminationReasonRepair TerminationReason = "repair"
+	// TerminationReasonManual is used when the NodeClaim or Node was deleted without Karpenter recording a reason
+	TerminationReasonManual TerminationReason = "manual"
+	// TerminationReasonInsufficientCapacity is used when the NodeClaim couldn't be launched or its instance never
+	// registered a Node, so it never provided capacity to the cluster
+	TerminationReasonInsufficientCapacity TerminationReason = "insufficient_capacity"
+	// TerminationReasonNodeClassNotReady is used when the NodeClaim couldn't be launched because its NodeClass wasn't ready
+	TerminationReasonNodeClassNotReady TerminationReason = "nodeclass_not_ready"
+)
+
+// TerminationReasonForDisruption returns the reason that a NodeClaim is terminated when it's disrupted for the given reason
+func TerminationReasonForDisruption(reason DisruptionReason) TerminationReason {
+	switch reason {
+	case DisruptionReasonEmpty, DisruptionReasonUnderutilized:
+		return TerminationReasonConsolidation
+	case DisruptionReasonDrifted:
+		return TerminationReasonDrift
+	case DisruptionReasonProblemDetected:
+		return TerminationReasonRepair
+	default:
+		return TerminationReasonManual
+	}
+}
+
 // NodeClaimStatus defines the observed state of NodeClaim
 type NodeClaimStatus struct {
 	// NodeName is the name of the corresponding node object
@@ -58,6 +102,14 @@ type NodeClaimStatus struct {
 	// is also considered as removed.
 	// +optional
 	LastPodEventTime metav1.Time `json:"lastPodEventTime,omitempty"`
+	// TerminationReason is the reason that the NodeClaim was terminated. It's set when the NodeClaim is deleted
+	// and defaults to manual when the NodeClaim was deleted without Karpenter recording a reason.
+	// +optional
+	TerminationReason TerminationReason `json:"terminationReason,omitempty"`
+	// Utilization is the ratio of the resources requested by pods on the node to the node's allocatable resources
+	// e.g. {"cpu": "0.53", "memory": "0.21"}
+	// +optional
+	Utilization map[v1.ResourceName]string `json:"utilization,omitempty"`
 }
 
 func (in *NodeClaim) StatusConditions() status.ConditionSet {
@@ -0,0 +1,36 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+var _ = Describe("TerminationReason", func() {
+	DescribeTable("should map each disruption reason to its termination reason",
+		func(reason DisruptionReason, expected TerminationReason) {
+			Expect(TerminationReasonForDisruption(reason)).To(Equal(expected))
+		},
+		Entry("empty", DisruptionReasonEmpty, TerminationReasonConsolidation),
+		Entry("underutilized", DisruptionReasonUnderutilized, TerminationReasonConsolidation),
+		Entry("drifted", DisruptionReasonDrifted, TerminationReasonDrift),
+		Entry("problem detected", DisruptionReasonProblemDetected, TerminationReasonRepair),
+	)
+})
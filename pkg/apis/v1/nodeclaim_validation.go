@@ -62,6 +62,10 @@ type taintKeyEffect struct {
 func (in *NodeClaimTemplateSpec) validateTaints() (errs error) {
 	existing := map[taintKeyEffect]struct{}{}
 	errs = multierr.Combine(validateTaintsField(in.Taints, existing, "taints"), validateTaintsField(in.StartupTaints, existing, "startupTaints"))
+	// Conditional startup taints may overlap with each other since their requirements may not match the same instance types
+	for _, conditional := range in.ConditionalStartupTaints {
+		errs = multierr.Append(errs, validateTaintsField(conditional.Taints, lo.Assign(existing), "conditionalStartupTaints"))
+	}
 	return errs
 }
 
@@ -20,14 +20,17 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/mitchellh/hashstructure/v2"
 	"github.com/robfig/cron/v3"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/clock"
 )
 
@@ -44,9 +47,55 @@ type NodePoolSpec struct {
 	// +kubebuilder:default:={consolidateAfter: "0s"}
 	// +optional
 	Disruption Disruption `json:"disruption"`
-	// Limits define a set of bounds for provisioning capacity.
+	// Limits define a set of bounds for provisioning capacity. Limits can be scoped to a capacity type by prefixing
+	// the resource with the capacity type, e.g. on-demand/cpu bounds the CPU of the on-demand capacity.
 	// +optional
 	Limits Limits `json:"limits,omitempty"`
+	// AdditionalResources are extended resources defined by the cluster operator (e.g. example.com/licenses) that are
+	// advertised on the nodes launched by this nodepool. These resources aren't known to the cloud provider, but pods
+	// requesting them are still considered for provisioning against the selected instance types.
+	// +kubebuilder:validation:MaxItems:=50
+	// +optional
+	AdditionalResources []AdditionalResource `json:"additionalResources,omitempty"`
+	// ZoneBalancing determines how new NodeClaims are spread across zones when the pods that they're launched for don't
+	// constrain the zone. None leaves the choice of zone to the cloud provider, Balanced launches into the zone with the
+	// fewest nodes from this nodepool for better resilience and Packed launches into the zone with the cheapest offering.
+	// +kubebuilder:validation:Enum:={None,Balanced,Packed}
+	// +optional
+	ZoneBalancing ZoneBalancing `json:"zoneBalancing,omitempty"`
+	// StartupTaintTimeout is the maximum duration that the startup taints of a nodeclaim may stay on its node, measured
+	// from when the node was created or last became ready, e.g. after a reboot. Startup taints that remain after this
+	// duration are removed so that workloads aren't stranded when the DaemonSet that removes them is gone.
+	// If left undefined, startup taints are only removed by the DaemonSets that own them.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	StartupTaintTimeout *metav1.Duration `json:"startupTaintTimeout,omitempty"`
+	// MaxPodsPerNode is the maximum number of pods that are scheduled to each node launched by this nodepool, regardless
+	// of how many pods its instance type supports. This only lowers the pod capacity of instance types, instance types
+	// that support fewer pods are unaffected.
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	MaxPodsPerNode *int32 `json:"maxPodsPerNode,omitempty"`
+	// SoftMaxNodeSize is the preferred ceiling on the cpu and memory of each node launched by this nodepool. Pods that
+	// would grow a new node past the ceiling are scheduled to additional nodes instead, which bounds how many pods a single
+	// node failure affects. The ceiling is only exceeded by a pod that can't fit on a node within it on its own.
+	// +kubebuilder:validation:XValidation:message="softMaxNodeSize may only constrain cpu and memory",rule="self.all(x, x in ['cpu', 'memory'])"
+	// +optional
+	SoftMaxNodeSize v1.ResourceList `json:"softMaxNodeSize,omitempty"`
+	// TemplatePropagation determines how changes to the labels, annotations and taints of the template are applied to
+	// the nodes that this nodepool has already launched. Each of these can either be replaced, where the existing nodes
+	// are considered drifted, or patched in place onto the existing NodeClaims and Nodes. Changes to the other fields of
+	// the template always replace the existing nodes.
+	// +optional
+	TemplatePropagation TemplatePropagation `json:"templatePropagation,omitempty"`
+	// TopologyDomains declare additional topology domains that the nodes launched by this nodepool can be placed into,
+	// keyed by a custom node label like a rack or hypervisor group. Pods that spread across or have affinity to these
+	// keys can then be scheduled to new capacity, which is labeled with the domain that the scheduler selects.
+	// +kubebuilder:validation:XValidation:message="topologyDomains keys must be unique",rule="self.all(x, self.exists_one(y, y.key == x.key))"
+	// +kubebuilder:validation:MaxItems:=20
+	// +optional
+	TopologyDomains []TopologyDomain `json:"topologyDomains,omitempty"`
 	// Weight is the priority given to the nodepool during scheduling. A higher
 	// numerical weight indicates that this nodepool will be ordered
 	// ahead of other nodepools with lower weights. A nodepool with no weight
@@ -57,6 +106,106 @@ type NodePoolSpec struct {
 	Weight *int32 `json:"weight,omitempty"`
 }
 
+// ZoneBalancing is the policy used to break ties between the zones that a new NodeClaim can launch into
+type ZoneBalancing string
+
+const (
+	ZoneBalancingNone     ZoneBalancing = "None"
+	ZoneBalancingBalanced ZoneBalancing = "Balanced"
+	ZoneBalancingPacked   ZoneBalancing = "Packed"
+)
+
+// TemplatePropagation is the propagation policy for each class of template fields that can be patched in place
+type TemplatePropagation struct {
+	// Labels is the propagation policy for the labels of the template. Labels that are patched in place are only added
+	// or updated on the existing nodes, labels that are removed from the template are left on them.
+	// +kubebuilder:validation:Enum:={Replace,InPlace}
+	// +optional
+	Labels PropagationPolicy `json:"labels,omitempty"`
+	// Annotations is the propagation policy for the annotations of the template. Annotations that are patched in place
+	// are only added or updated on the existing nodes, annotations that are removed from the template are left on them.
+	// +kubebuilder:validation:Enum:={Replace,InPlace}
+	// +optional
+	Annotations PropagationPolicy `json:"annotations,omitempty"`
+	// Taints is the propagation policy for the taints of the template. Taints that are patched in place are only applied
+	// to the existing Nodes, since the taints of a NodeClaim are immutable.
+	// +kubebuilder:validation:Enum:={Replace,InPlace}
+	// +optional
+	Taints PropagationPolicy `json:"taints,omitempty"`
+}
+
+// ConditionalStartupTaints are startup taints that are applied to the NodeClaims with instance types that match the requirements
+type ConditionalStartupTaints struct {
+	// Requirements are matched against the labels of the instance types that a NodeClaim can launch with.
+	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=100
+	// +required
+	Requirements []v1.NodeSelectorRequirement `json:"requirements"`
+	// Taints are the startup taints that are applied when the requirements are matched.
+	// +kubebuilder:validation:MinItems:=1
+	// +required
+	Taints []v1.Taint `json:"taints"`
+}
+
+// PropagationPolicy determines how changes to a class of template fields are applied to existing nodes. If left
+// undefined, existing nodes are replaced.
+type PropagationPolicy string
+
+const (
+	PropagationPolicyReplace PropagationPolicy = "Replace"
+	PropagationPolicyInPlace PropagationPolicy = "InPlace"
+)
+
+const (
+	templateClassLabels      = "labels"
+	templateClassAnnotations = "annotations"
+	templateClassTaints      = "taints"
+)
+
+// InPlace returns the classes of template fields that are patched in place onto existing nodes
+func (in TemplatePropagation) InPlace() []string {
+	return lo.Compact([]string{
+		lo.Ternary(in.Labels == PropagationPolicyInPlace, templateClassLabels, ""),
+		lo.Ternary(in.Annotations == PropagationPolicyInPlace, templateClassAnnotations, ""),
+		lo.Ternary(in.Taints == PropagationPolicyInPlace, templateClassTaints, ""),
+	})
+}
+
+// AdditionalResource is an extended resource that's advertised on nodes for the instance types that match its requirements
+type AdditionalResource struct {
+	// Name is the name of the extended resource. This must either be a fully-qualified resource name outside of the
+	// kubernetes.io domain or a huge page resource (e.g. hugepages-2Mi). Huge pages are preallocated from the memory
+	// of the node, so the memory that's available to pods is reduced by their quantity.
+	// +kubebuilder:validation:XValidation:message="name must not be in the kubernetes.io domain",rule="!self.startsWith('kubernetes.io/') && !self.contains('.kubernetes.io/')"
+	// +kubebuilder:validation:MaxLength:=317
+	// +kubebuilder:validation:Pattern:=`^(hugepages-[0-9]+(Ki|Mi|Gi)|([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)*[a-z0-9]([-a-z0-9]*[a-z0-9])?/[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)$`
+	// +required
+	Name string `json:"name"`
+	// Quantity is the amount of the resource that's advertised on each node.
+	// +required
+	Quantity resource.Quantity `json:"quantity"`
+	// Requirements select the instance types that advertise the resource. If left undefined, every instance type
+	// launched by the nodepool advertises the resource.
+	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
+	// +kubebuilder:validation:MaxItems:=100
+	// +optional
+	Requirements []v1.NodeSelectorRequirement `json:"requirements,omitempty"`
+}
+
+type TopologyDomain struct {
+	// Key is the node label that identifies the topology domain, e.g. example.com/rack
+	// +kubebuilder:validation:MaxLength=316
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*(\/))?([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
+	// +required
+	Key string `json:"key"`
+	// Values are the domains of the key that nodes can be placed into
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:validation:MaxItems:=100
+	// +required
+	Values []string `json:"values"`
+}
+
 type Disruption struct {
 	// ConsolidateAfter is the duration the controller will wait
 	// before attempting to terminate nodes that are underutilized.
@@ -81,6 +230,29 @@ type Disruption struct {
 	// +kubebuilder:validation:MaxItems=50
 	// +optional
 	Budgets []Budget `json:"budgets,omitempty" hash:"ignore"`
+	// JobCompletionGracePeriod is the longest that Karpenter will delay disrupting a node so that the Jobs running on
+	// it can complete. Karpenter waits on a Job pod that's expected to complete within this period, either by its
+	// activeDeadlineSeconds or by the time in its karpenter.sh/expected-completion-time annotation.
+	// If omitted, Karpenter doesn't wait on Jobs before disrupting nodes.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	JobCompletionGracePeriod *metav1.Duration `json:"jobCompletionGracePeriod,omitempty"`
+	// MinNodeLifetime is the duration after a node is launched that Karpenter won't consolidate it or replace it for
+	// drift. This keeps nodes that were launched for a spike in load around for when the spike repeats, rather than
+	// consolidating them away minutes later only to launch them again.
+	// If omitted, nodes can be consolidated or replaced for drift as soon as they are launched.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	MinNodeLifetime *metav1.Duration `json:"minNodeLifetime,omitempty"`
+	// ReplacementPolicy describes when Karpenter cordons a node that it replaces through drift or single-node
+	// consolidation. "MakeBeforeBreak" waits for the replacement to initialize before the node is cordoned, so that
+	// capacity doesn't dip below what workloads need during the replacement.
+	// If omitted, the node is cordoned as soon as its replacement is launched.
+	// +kubebuilder:validation:Enum:={BreakBeforeMake,MakeBeforeBreak}
+	// +optional
+	ReplacementPolicy ReplacementPolicy `json:"replacementPolicy,omitempty"`
 }
 
 // Budget defines when Karpenter will restrict the
@@ -88,7 +260,7 @@ type Disruption struct {
 type Budget struct {
 	// Reasons is a list of disruption methods that this budget applies to. If Reasons is not set, this budget applies to all methods.
 	// Otherwise, this will apply to each reason defined.
-	// allowed reasons are Underutilized, Empty, and Drifted.
+	// allowed reasons are Underutilized, Empty, Drifted, and ProblemDetected.
 	// +optional
 	Reasons []DisruptionReason `json:"reasons,omitempty"`
 	// Nodes dictates the maximum number of NodeClaims owned by this NodePool
@@ -127,18 +299,101 @@ const (
 	ConsolidationPolicyWhenEmptyOrUnderutilized ConsolidationPolicy = "WhenEmptyOrUnderutilized"
 )
 
+type ReplacementPolicy string
+
+const (
+	ReplacementPolicyBreakBeforeMake ReplacementPolicy = "BreakBeforeMake"
+	ReplacementPolicyMakeBeforeBreak ReplacementPolicy = "MakeBeforeBreak"
+)
+
 // DisruptionReason defines valid reasons for disruption budgets.
-// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted}
+// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted,ProblemDetected}
 type DisruptionReason string
 
 const (
-	DisruptionReasonUnderutilized DisruptionReason = "Underutilized"
-	DisruptionReasonEmpty         DisruptionReason = "Empty"
-	DisruptionReasonDrifted       DisruptionReason = "Drifted"
+	DisruptionReasonUnderutilized   DisruptionReason = "Underutilized"
+	DisruptionReasonEmpty           DisruptionReason = "Empty"
+	DisruptionReasonDrifted         DisruptionReason = "Drifted"
+	DisruptionReasonProblemDetected DisruptionReason = "ProblemDetected"
 )
 
 type Limits v1.ResourceList
 
+// ByCapacityType returns the limits that are scoped to a capacity type, keyed by the capacity type. Limits are scoped
+// to a capacity type through keys of the form <capacity-type>/<resource>.
+func (l Limits) ByCapacityType() map[string]v1.ResourceList {
+	scoped := map[string]v1.ResourceList{}
+	for key, quantity := range l {
+		capacityType, resourceName, ok := capacityTypeScope(key)
+		if !ok {
+			continue
+		}
+		if _, ok := scoped[capacityType]; !ok {
+			scoped[capacityType] = v1.ResourceList{}
+		}
+		scoped[capacityType][resourceName] = quantity
+	}
+	return scoped
+}
+
+// capacityTypeScope splits a limit that's scoped to a capacity type into the capacity type and the resource. A limit is
+// only scoped when the part after the capacity type is a native resource (e.g. on-demand/cpu) or a fully-qualified
+// extended resource (e.g. on-demand/nvidia.com/gpu), so that extended resources such as nvidia.com/gpu or example/gpu
+// aren't mistaken for a resource scoped to a capacity type.
+func capacityTypeScope(key v1.ResourceName) (string, v1.ResourceName, bool) {
+	capacityType, resourceName, ok := strings.Cut(string(key), "/")
+	if !ok || strings.Contains(capacityType, ".") {
+		return "", "", false
+	}
+	if !isNativeResourceName(v1.ResourceName(resourceName)) && !IsExtendedResourceName(v1.ResourceName(resourceName)) {
+		return "", "", false
+	}
+	return capacityType, v1.ResourceName(resourceName), true
+}
+
+// isNativeResourceName returns true if the resource is one of the resources that the kubelet reports for a node
+func isNativeResourceName(name v1.ResourceName) bool {
+	switch name {
+	case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage, v1.ResourceStorage, v1.ResourcePods:
+		return true
+	}
+	return strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix)
+}
+
+// IsExtendedResourceName returns true if the resource is an extended resource, i.e. a resource outside of the
+// kubernetes.io domain whose name is a qualified name when it's used in a quota. This matches the upstream helper in
+// k8s.io/kubernetes/pkg/apis/core/v1/helper.
+func IsExtendedResourceName(name v1.ResourceName) bool {
+	if !strings.Contains(string(name), "/") || strings.Contains(string(name), v1.ResourceDefaultNamespacePrefix) ||
+		strings.HasPrefix(string(name), v1.DefaultResourceRequestsPrefix) {
+		return false
+	}
+	return len(validation.IsQualifiedName(v1.DefaultResourceRequestsPrefix+string(name))) == 0
+}
+
+// Remaining returns the amount of each limited resource that can still be provisioned, given the resources that have
+// been provisioned in total and by capacity type. Limits that are scoped to a capacity type are reduced by the resources
+// of that capacity type. Remaining limits are negative when more resources have been provisioned than the limit allows,
+// e.g. when the limit is lowered after provisioning.
+func (l Limits) Remaining(provisioned v1.ResourceList, provisionedByCapacityType map[string]v1.ResourceList) v1.ResourceList {
+	if l == nil {
+		return nil
+	}
+	remaining := v1.ResourceList{}
+	for key, limit := range l {
+		usage, ok := provisioned[key]
+		if capacityType, resourceName, scoped := capacityTypeScope(key); scoped {
+			usage, ok = provisionedByCapacityType[capacityType][resourceName]
+		}
+		quantity := limit.DeepCopy()
+		if ok {
+			quantity.Sub(usage)
+		}
+		remaining[key] = quantity
+	}
+	return remaining
+}
+
 func (l Limits) ExceededBy(resources v1.ResourceList) error {
 	if l == nil {
 		return nil
@@ -155,6 +410,11 @@ func (l Limits) ExceededBy(resources v1.ResourceList) error {
 
 type NodeClaimTemplate struct {
 	ObjectMeta `json:"metadata,omitempty"`
+	// NodeMetadata contains labels and annotations that are only applied to the Node when it registers, and not to the
+	// NodeClaim. This keeps metadata that's only consumed by node agents from bloating the NodeClaim. Since the labels
+	// aren't known to the scheduler, pods can't select them to have capacity provisioned for them.
+	// +optional
+	NodeMetadata ObjectMeta `json:"nodeMetadata,omitempty"`
 	// +required
 	Spec NodeClaimTemplateSpec `json:"spec"`
 }
@@ -172,6 +432,13 @@ type NodeClaimTemplateSpec struct {
 	// purposes in that pods are not required to tolerate a StartupTaint in order to have nodes provisioned for them.
 	// +optional
 	StartupTaints []v1.Taint `json:"startupTaints,omitempty"`
+	// ConditionalStartupTaints are startupTaints that are only applied to NodeClaims whose instance types match their
+	// requirements, e.g. taints that are removed by a GPU device plugin which only runs on nodes with GPUs. They're
+	// evaluated when the NodeClaim is created and only applied when every instance type that the NodeClaim can launch
+	// with matches the requirements.
+	// +kubebuilder:validation:MaxItems:=50
+	// +optional
+	ConditionalStartupTaints []ConditionalStartupTaints `json:"conditionalStartupTaints,omitempty"`
 	// Requirements are layered with GetLabels and applied to every node.
 	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
 	// +kubebuilder:validation:XValidation:message="requirements operator 'Gt' or 'Lt' must have a single positive integer value",rule="self.all(x, (x.operator == 'Gt' || x.operator == 'Lt') ? (x.values.size() == 1 && int(x.values[0]) >= 0) : true)"
@@ -211,8 +478,40 @@ type NodeClaimTemplateSpec struct {
 	// +kubebuilder:validation:Schemaless
 	// +optional
 	ExpireAfter NillableDuration `json:"expireAfter,omitempty"`
+	// RegistrationTTL is the duration the controller will wait for the Node of a launched NodeClaim to register
+	// before deleting the NodeClaim and launching replacement capacity with a different offering.
+	// If left undefined, the controller will wait 15 minutes.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	RegistrationTTL *metav1.Duration `json:"registrationTTL,omitempty" hash:"ignore"`
+	// ReadinessTTL is the duration the controller will wait for the Node of a NodeClaim to go Ready after it registers
+	// before deleting the NodeClaim and launching replacement capacity with a different offering.
+	// If left undefined, the controller won't replace Nodes that don't go Ready.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	ReadinessTTL *metav1.Duration `json:"readinessTTL,omitempty" hash:"ignore"`
+	// KubeReservedStrategy selects how the cloud provider computes the kube-reserved and system-reserved resources of
+	// the instance types, so that the allocatable that's packed against matches what the kubelet on the node reserves.
+	// If left undefined, the cloud provider's default is used.
+	// +kubebuilder:validation:Enum:={gke,eks,flat}
+	// +optional
+	KubeReservedStrategy KubeReservedStrategy `json:"kubeReservedStrategy,omitempty" hash:"ignore"`
 }
 
+// KubeReservedStrategy is the formula used to compute the kube-reserved and system-reserved resources of an instance type
+type KubeReservedStrategy string
+
+const (
+	// KubeReservedStrategyGKE reserves memory based on the total memory of the instance and CPU based on the number of cores
+	KubeReservedStrategyGKE KubeReservedStrategy = "gke"
+	// KubeReservedStrategyEKS reserves memory based on the maximum number of pods and CPU based on the number of cores
+	KubeReservedStrategyEKS KubeReservedStrategy = "eks"
+	// KubeReservedStrategyFlat reserves a fixed amount of CPU and memory regardless of the size of the instance
+	KubeReservedStrategyFlat KubeReservedStrategy = "flat"
+)
+
 // This is used to convert between the NodeClaim's NodeClaimSpec to the Nodepool NodeClaimTemplate's NodeClaimSpec.
 func (in *NodeClaimTemplate) ToNodeClaim() *NodeClaim {
 	return &NodeClaim{
@@ -248,16 +547,22 @@ type ObjectMeta struct {
 }
 
 // NodePool is the Schema for the NodePools API
+// +genclient
+// +genclient:nonNamespaced
 // +kubebuilder:object:root=true
 // +kubebuilder:storageversion
 // +kubebuilder:resource:path=nodepools,scope=Cluster,categories=karpenter
 // +kubebuilder:printcolumn:name="NodeClass",type="string",JSONPath=".spec.template.spec.nodeClassRef.name",description=""
 // +kubebuilder:printcolumn:name="Nodes",type="string",JSONPath=".status.resources.nodes",description=""
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+// +kubebuilder:printcolumn:name="Launching",type="integer",JSONPath=".status.nodeClaims.launching.count",description=""
+// +kubebuilder:printcolumn:name="Deleting",type="integer",JSONPath=".status.nodeClaims.deleting.count",description=""
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 // +kubebuilder:printcolumn:name="Weight",type="integer",JSONPath=".spec.weight",priority=1,description=""
 // +kubebuilder:printcolumn:name="CPU",type="string",JSONPath=".status.resources.cpu",priority=1,description=""
 // +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".status.resources.memory",priority=1,description=""
+// +kubebuilder:printcolumn:name="Registered",type="integer",JSONPath=".status.nodeClaims.registered.count",priority=1,description=""
+// +kubebuilder:printcolumn:name="Initialized",type="integer",JSONPath=".status.nodeClaims.initialized.count",priority=1,description=""
 // +kubebuilder:subresource:status
 type NodePool struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -275,7 +580,49 @@ type NodePool struct {
 const NodePoolHashVersion = "v3"
 
 func (in *NodePool) Hash() string {
-	return fmt.Sprint(lo.Must(hashstructure.Hash(in.Spec.Template, hashstructure.FormatV2, &hashstructure.HashOptions{
+	return hashTemplate(in.Spec.Template)
+}
+
+// TemplateHashes returns the hashes of the labels, annotations and taints of the template, which can be propagated in
+// place, and of the rest of the template, e.g. labels=1,annotations=2,taints=3,template=4. When a NodePool propagates
+// some of these classes in place, static drift compares these hashes while skipping the classes that are propagated in
+// place, so changes to those classes or to the propagation policy don't drift existing nodes.
+func (in *NodePool) TemplateHashes() string {
+	labels := NodeClaimTemplate{ObjectMeta: ObjectMeta{Labels: in.Spec.Template.Labels}, NodeMetadata: ObjectMeta{Labels: in.Spec.Template.NodeMetadata.Labels}}
+	annotations := NodeClaimTemplate{ObjectMeta: ObjectMeta{Annotations: in.Spec.Template.Annotations}, NodeMetadata: ObjectMeta{Annotations: in.Spec.Template.NodeMetadata.Annotations}}
+	taints := NodeClaimTemplate{Spec: NodeClaimTemplateSpec{Taints: in.Spec.Template.Spec.Taints}}
+	template := in.Spec.Template.DeepCopy()
+	template.Labels, template.NodeMetadata.Labels = nil, nil
+	template.Annotations, template.NodeMetadata.Annotations = nil, nil
+	template.Spec.Taints = nil
+	return fmt.Sprintf("%s=%s,%s=%s,%s=%s,template=%s",
+		templateClassLabels, hashTemplate(labels),
+		templateClassAnnotations, hashTemplate(annotations),
+		templateClassTaints, hashTemplate(taints),
+		hashTemplate(*template))
+}
+
+// TemplateHashesDrifted returns true if the template hashes of a NodeClaim differ from the template hashes of the
+// NodePool for any class of template fields that isn't propagated in place
+func (in *NodePool) TemplateHashesDrifted(nodePoolHashes, nodeClaimHashes string) bool {
+	nodeClaimHashesByClass := parseTemplateHashes(nodeClaimHashes)
+	for class, hash := range parseTemplateHashes(nodePoolHashes) {
+		if nodeClaimHashesByClass[class] != hash && !lo.Contains(in.Spec.TemplatePropagation.InPlace(), class) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTemplateHashes(hashes string) map[string]string {
+	return lo.SliceToMap(strings.Split(hashes, ","), func(hash string) (string, string) {
+		class, value, _ := strings.Cut(hash, "=")
+		return class, value
+	})
+}
+
+func hashTemplate(template NodeClaimTemplate) string {
+	return fmt.Sprint(lo.Must(hashstructure.Hash(template, hashstructure.FormatV2, &hashstructure.HashOptions{
 		SlicesAsSets:    true,
 		IgnoreZeroValue: true,
 		ZeroNil:         true,
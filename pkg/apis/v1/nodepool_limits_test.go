@@ -0,0 +1,78 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	. "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+var _ = Describe("Limits", func() {
+	DescribeTable("should only scope limits to a capacity type when they're prefixed with one",
+		func(key corev1.ResourceName, expected map[string]corev1.ResourceList) {
+			limits := Limits(corev1.ResourceList{key: resource.MustParse("10")})
+			Expect(limits.ByCapacityType()).To(BeComparableTo(expected))
+		},
+		Entry("native resource", corev1.ResourceName("on-demand/cpu"), map[string]corev1.ResourceList{
+			CapacityTypeOnDemand: {corev1.ResourceCPU: resource.MustParse("10")},
+		}),
+		Entry("huge pages", corev1.ResourceName("spot/hugepages-2Mi"), map[string]corev1.ResourceList{
+			CapacityTypeSpot: {"hugepages-2Mi": resource.MustParse("10")},
+		}),
+		Entry("extended resource", corev1.ResourceName("spot/nvidia.com/gpu"), map[string]corev1.ResourceList{
+			CapacityTypeSpot: {"nvidia.com/gpu": resource.MustParse("10")},
+		}),
+		Entry("unscoped native resource", corev1.ResourceCPU, map[string]corev1.ResourceList{}),
+		Entry("unscoped extended resource", corev1.ResourceName("nvidia.com/gpu"), map[string]corev1.ResourceList{}),
+		Entry("unscoped extended resource without a dot", corev1.ResourceName("example/gpu"), map[string]corev1.ResourceList{}),
+	)
+	It("should reduce scoped limits by the resources of their capacity type", func() {
+		limits := Limits(corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10"),
+			"on-demand/cpu":       resource.MustParse("4"),
+			"example/gpu":         resource.MustParse("2"),
+			"spot/nvidia.com/gpu": resource.MustParse("3"),
+		})
+		remaining := limits.Remaining(
+			corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("6"), "example/gpu": resource.MustParse("1")},
+			map[string]corev1.ResourceList{
+				CapacityTypeOnDemand: {corev1.ResourceCPU: resource.MustParse("1")},
+				CapacityTypeSpot:     {"nvidia.com/gpu": resource.MustParse("1")},
+			},
+		)
+		Expect(remaining).To(BeComparableTo(corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			"on-demand/cpu":       resource.MustParse("3"),
+			"example/gpu":         resource.MustParse("1"),
+			"spot/nvidia.com/gpu": resource.MustParse("2"),
+		}))
+	})
+	DescribeTable("should detect extended resources",
+		func(name corev1.ResourceName, expected bool) {
+			Expect(IsExtendedResourceName(name)).To(Equal(expected))
+		},
+		Entry("fully-qualified", corev1.ResourceName("nvidia.com/gpu"), true),
+		Entry("without a dot", corev1.ResourceName("example/gpu"), true),
+		Entry("native", corev1.ResourceCPU, false),
+		Entry("kubernetes.io domain", corev1.ResourceName("kubernetes.io/batteries"), false),
+		Entry("quota prefix", corev1.ResourceName("requests.example.com/gpu"), false),
+	)
+})
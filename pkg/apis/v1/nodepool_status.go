@@ -27,6 +27,53 @@ const (
 	ConditionTypeValidationSucceeded = "ValidationSucceeded"
 	// ConditionTypeNodeClassReady = "NodeClassReady" condition indicates that underlying nodeClass was resolved and is reporting as Ready
 	ConditionTypeNodeClassReady = "NodeClassReady"
+	// ConditionTypeLaunchDegraded = "LaunchDegraded" condition indicates that too many of the NodePool's recently launched
+	// NodeClaims didn't initialize within the launch SLO target, exhausting the NodePool's error budget
+	ConditionTypeLaunchDegraded = "LaunchDegraded"
+	// ConditionTypeWorkloadsCompatible = "WorkloadsCompatible" condition indicates whether the pods that are running on
+	// or nominated to the NodePool's nodes could schedule to replacement nodes launched from the NodePool's current template
+	ConditionTypeWorkloadsCompatible = "WorkloadsCompatible"
+	// ConditionTypeCanaryFailed = "CanaryFailed" condition indicates that the NodePool's most recent canary pod wasn't
+	// running within the canary timeout, meaning that the NodePool may not be able to provision capacity
+	ConditionTypeCanaryFailed = "CanaryFailed"
+	// ConditionTypeLimitsExceeded = "LimitsExceeded" condition indicates that the NodePool has provisioned as much of
+	// a resource as its limits allow, so it can't launch any more NodeClaims
+	ConditionTypeLimitsExceeded = "LimitsExceeded"
+	// ConditionTypeQuarantined = "Quarantined" condition indicates that none of the offerings that the NodePool's
+	// requirements allow are currently available, so it can't launch capacity until one of them becomes available
+	ConditionTypeQuarantined = "Quarantined"
+	// ConditionTypeDegraded = "Degraded" condition summarizes the conditions that indicate the NodePool may not be
+	// able to provision working capacity, i.e. LaunchDegraded and CanaryFailed
+	ConditionTypeDegraded = "Degraded"
+)
+
+// Reasons for the NodePool's status conditions. These are part of the NodePool's API so that automation can be built
+// on top of them, a reason is never renamed or reused for a different condition once it's released.
+const (
+	// ValidationSucceeded
+	ConditionReasonNodePoolValidationFailed = "NodePoolValidationFailed"
+	// NodeClassReady
+	ConditionReasonNodeClassNotFound         = "NodeClassNotFound"
+	ConditionReasonNodeClassTerminating      = "NodeClassTerminating"
+	ConditionReasonNodeClassReadinessUnknown = "NodeClassReadinessUnknown"
+	// LaunchDegraded
+	ConditionReasonErrorBudgetExhausted = "ErrorBudgetExhausted"
+	ConditionReasonWithinErrorBudget    = "WithinErrorBudget"
+	// WorkloadsCompatible
+	ConditionReasonIncompatibleWorkloads = "IncompatibleWorkloads"
+	// CanaryFailed
+	ConditionReasonCanaryRunning  = "CanaryRunning"
+	ConditionReasonCanaryTimedOut = "CanaryTimedOut"
+	// LimitsExceeded
+	ConditionReasonLimitsExceeded = "LimitsExceeded"
+	ConditionReasonWithinLimits   = "WithinLimits"
+	// Quarantined
+	ConditionReasonNoAvailableOfferings = "NoAvailableOfferings"
+	ConditionReasonOfferingsAvailable   = "OfferingsAvailable"
+	// Degraded
+	ConditionReasonLaunchDegraded = "LaunchDegraded"
+	ConditionReasonCanaryFailed   = "CanaryFailed"
+	ConditionReasonHealthy        = "Healthy"
 )
 
 // NodePoolStatus defines the observed state of NodePool
@@ -34,11 +81,48 @@ type NodePoolStatus struct {
 	// Resources is the list of resources that have been provisioned.
 	// +optional
 	Resources v1.ResourceList `json:"resources,omitempty"`
+	// ResourcesByCapacityType is the list of resources that have been provisioned, grouped by capacity type.
+	// +optional
+	ResourcesByCapacityType map[string]v1.ResourceList `json:"resourcesByCapacityType,omitempty"`
+	// RemainingLimits is the amount of each resource in the limits of the NodePool that can still be provisioned before
+	// the limit is reached. This is negative for resources where more has been provisioned than the limit allows.
+	// +optional
+	RemainingLimits v1.ResourceList `json:"remainingLimits,omitempty"`
+	// NodeClaims summarizes the NodeClaims that are owned by the NodePool by lifecycle phase.
+	// +optional
+	NodeClaims *NodeClaimPhases `json:"nodeClaims,omitempty"`
+	// NodesByGeneration is the number of nodes owned by the NodePool that aren't being deleted, grouped by the
+	// generation of the NodePool that they were launched from. Nodes without the karpenter.sh/nodepool-generation
+	// label aren't counted.
+	// +optional
+	NodesByGeneration map[string]int64 `json:"nodesByGeneration,omitempty"`
 	// Conditions contains signals for health and readiness
 	// +optional
 	Conditions []status.Condition `json:"conditions,omitempty"`
 }
 
+// NodeClaimPhases groups the NodeClaims that are owned by a NodePool by their lifecycle phase. NodeClaims that are
+// being deleted are only counted as deleting.
+type NodeClaimPhases struct {
+	// Launching is the NodeClaims with nodes that haven't registered with the cluster.
+	Launching NodeClaimPhase `json:"launching"`
+	// Registered is the NodeClaims with nodes that have registered but haven't initialized.
+	Registered NodeClaimPhase `json:"registered"`
+	// Initialized is the NodeClaims with nodes that have initialized.
+	Initialized NodeClaimPhase `json:"initialized"`
+	// Deleting is the NodeClaims that are being deleted.
+	Deleting NodeClaimPhase `json:"deleting"`
+}
+
+// NodeClaimPhase is the number of NodeClaims in a lifecycle phase along with their aggregate capacity
+type NodeClaimPhase struct {
+	// Count is the number of NodeClaims in the phase.
+	Count int64 `json:"count"`
+	// Resources is the sum of the capacity of the NodeClaims in the phase.
+	// +optional
+	Resources v1.ResourceList `json:"resources,omitempty"`
+}
+
 func (in *NodePool) StatusConditions() status.ConditionSet {
 	return status.NewReadyConditions(
 		ConditionTypeValidationSucceeded,
@@ -18,30 +18,37 @@ package v1
 
 import (
 	"fmt"
+	"strings"
 
 	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // RuntimeValidate will be used to validate any part of the CRD that can not be validated at CRD creation
 func (in *NodePool) RuntimeValidate() (errs error) {
-	errs = multierr.Combine(in.Spec.Template.validateLabels(), in.Spec.Template.Spec.validateTaints(), in.Spec.Template.Spec.validateRequirements(), in.Spec.Template.validateRequirementsNodePoolKeyDoesNotExist())
+	errs = multierr.Combine(in.Spec.Template.validateLabels(), in.Spec.Template.Spec.validateTaints(), in.Spec.Template.Spec.validateRequirements(), in.Spec.Template.validateRequirementsNodePoolKeyDoesNotExist(), in.Spec.validateAdditionalResources(), in.Spec.validateTopologyDomains())
 	return errs
 }
 
 func (in *NodeClaimTemplate) validateLabels() (errs error) {
-	for key, value := range in.Labels {
+	return multierr.Combine(validateLabels(in.Labels, "labels"), validateLabels(in.NodeMetadata.Labels, "nodeMetadata.labels"))
+}
+
+func validateLabels(labels map[string]string, field string) (errs error) {
+	for key, value := range labels {
 		if key == NodePoolLabelKey {
-			errs = multierr.Append(errs, fmt.Errorf("invalid key name %q in labels, restricted", key))
+			errs = multierr.Append(errs, fmt.Errorf("invalid key name %q in %s, restricted", key, field))
 		}
 		for _, err := range validation.IsQualifiedName(key) {
-			errs = multierr.Append(errs, fmt.Errorf("invalid key name %q in labels, %q", key, err))
+			errs = multierr.Append(errs, fmt.Errorf("invalid key name %q in %s, %q", key, field, err))
 		}
 		for _, err := range validation.IsValidLabelValue(value) {
 			errs = multierr.Append(errs, fmt.Errorf("invalid value: %s for label[%s], %s", value, key, err))
 		}
 		if err := IsRestrictedLabel(key); err != nil {
-			errs = multierr.Append(errs, fmt.Errorf("invalid key name %q in labels, %s", key, err.Error()))
+			errs = multierr.Append(errs, fmt.Errorf("invalid key name %q in %s, %s", key, field, err.Error()))
 		}
 	}
 	return errs
@@ -55,3 +62,41 @@ func (in *NodeClaimTemplate) validateRequirementsNodePoolKeyDoesNotExist() (errs
 	}
 	return errs
 }
+
+func (in *NodePoolSpec) validateAdditionalResources() (errs error) {
+	for _, r := range in.AdditionalResources {
+		if strings.HasPrefix(r.Name, corev1.ResourceHugePagesPrefix) {
+			if _, err := resource.ParseQuantity(strings.TrimPrefix(r.Name, corev1.ResourceHugePagesPrefix)); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("invalid additional resource name %q, huge page size must be a quantity", r.Name))
+			}
+			continue
+		}
+		if !strings.Contains(r.Name, "/") || strings.HasPrefix(r.Name, "kubernetes.io/") || strings.Contains(r.Name, ".kubernetes.io/") {
+			errs = multierr.Append(errs, fmt.Errorf("invalid additional resource name %q, must be a fully-qualified extended resource name outside of the kubernetes.io domain", r.Name))
+		}
+		for _, err := range validation.IsQualifiedName(r.Name) {
+			errs = multierr.Append(errs, fmt.Errorf("invalid additional resource name %q, %s", r.Name, err))
+		}
+	}
+	return errs
+}
+
+func (in *NodePoolSpec) validateTopologyDomains() (errs error) {
+	for _, domain := range in.TopologyDomains {
+		if domain.Key == NodePoolLabelKey {
+			errs = multierr.Append(errs, fmt.Errorf("invalid key %q in topologyDomains, restricted", domain.Key))
+		}
+		for _, err := range validation.IsQualifiedName(domain.Key) {
+			errs = multierr.Append(errs, fmt.Errorf("invalid key %q in topologyDomains, %s", domain.Key, err))
+		}
+		if err := IsRestrictedLabel(domain.Key); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("invalid key %q in topologyDomains, %s", domain.Key, err))
+		}
+		for _, value := range domain.Values {
+			for _, err := range validation.IsValidLabelValue(value) {
+				errs = multierr.Append(errs, fmt.Errorf("invalid value %q for topologyDomains[%s], %s", value, domain.Key, err))
+			}
+		}
+	}
+	return errs
+}
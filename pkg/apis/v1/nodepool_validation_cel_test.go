@@ -27,6 +27,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -76,6 +77,14 @@ var _ = Describe("CEL/Validation", func() {
 			nodePool.Spec.Template.Spec.ExpireAfter = MustParseNillableDuration("30s")
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 		})
+		It("should succeed on a valid replacementPolicy", func() {
+			nodePool.Spec.Disruption.ReplacementPolicy = ReplacementPolicyMakeBeforeBreak
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on an invalid replacementPolicy", func() {
+			nodePool.Spec.Disruption.ReplacementPolicy = "Invalid"
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
 		It("should fail on negative consolidateAfter", func() {
 			nodePool.Spec.Disruption.ConsolidateAfter = MustParseNillableDuration("-1s")
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
@@ -272,6 +281,119 @@ var _ = Describe("CEL/Validation", func() {
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 		})
 	})
+	Context("AdditionalResources", func() {
+		It("should succeed for valid extended resource names", func() {
+			nodePool.Spec.AdditionalResources = []AdditionalResource{
+				{Name: "example.com/licenses", Quantity: resource.MustParse("1")},
+				{Name: "example.com/Licenses_v2", Quantity: resource.MustParse("10")},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate()).To(Succeed())
+		})
+		It("should succeed for huge page resource names", func() {
+			nodePool.Spec.AdditionalResources = []AdditionalResource{
+				{Name: "hugepages-2Mi", Quantity: resource.MustParse("1Gi")},
+				{Name: "hugepages-1Gi", Quantity: resource.MustParse("2Gi")},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate()).To(Succeed())
+		})
+		It("should fail for huge page resource names without a valid page size", func() {
+			nodePool.Spec.AdditionalResources = []AdditionalResource{{Name: "hugepages-large", Quantity: resource.MustParse("1Gi")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
+		It("should fail for resource names that aren't fully-qualified", func() {
+			nodePool.Spec.AdditionalResources = []AdditionalResource{{Name: "licenses", Quantity: resource.MustParse("1")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
+		It("should fail for resource names in the kubernetes.io domain", func() {
+			nodePool.Spec.AdditionalResources = []AdditionalResource{{Name: "kubernetes.io/licenses", Quantity: resource.MustParse("1")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+			nodePool.Spec.AdditionalResources = []AdditionalResource{{Name: "node.kubernetes.io/licenses", Quantity: resource.MustParse("1")}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
+		It("should fail for requirements with operator 'In' and no values", func() {
+			nodePool.Spec.AdditionalResources = []AdditionalResource{{
+				Name:         "example.com/licenses",
+				Quantity:     resource.MustParse("1"),
+				Requirements: []v1.NodeSelectorRequirement{{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("MaxPodsPerNode", func() {
+		It("should succeed for a positive max pods", func() {
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](20)
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail for a max pods of zero", func() {
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](0)
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("SoftMaxNodeSize", func() {
+		It("should succeed for cpu and memory", func() {
+			nodePool.Spec.SoftMaxNodeSize = v1.ResourceList{v1.ResourceCPU: resource.MustParse("16"), v1.ResourceMemory: resource.MustParse("64Gi")}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail for other resources", func() {
+			nodePool.Spec.SoftMaxNodeSize = v1.ResourceList{v1.ResourcePods: resource.MustParse("50")}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("TopologyDomains", func() {
+		It("should succeed for custom topology keys", func() {
+			nodePool.Spec.TopologyDomains = []TopologyDomain{
+				{Key: "example.com/rack", Values: []string{"rack-1", "rack-2"}},
+				{Key: "example.com/hypervisor-group", Values: []string{"a"}},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate()).To(Succeed())
+		})
+		It("should fail for duplicate keys", func() {
+			nodePool.Spec.TopologyDomains = []TopologyDomain{
+				{Key: "example.com/rack", Values: []string{"rack-1"}},
+				{Key: "example.com/rack", Values: []string{"rack-2"}},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail without values", func() {
+			nodePool.Spec.TopologyDomains = []TopologyDomain{{Key: "example.com/rack"}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail for an invalid key", func() {
+			nodePool.Spec.TopologyDomains = []TopologyDomain{{Key: "example.com/rack/", Values: []string{"rack-1"}}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail runtime validation for restricted keys", func() {
+			for _, key := range []string{NodePoolLabelKey, "kubernetes.io/rack", "karpenter.sh/rack"} {
+				nodePool.Spec.TopologyDomains = []TopologyDomain{{Key: key, Values: []string{"rack-1"}}}
+				Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+			}
+		})
+		It("should fail runtime validation for invalid values", func() {
+			nodePool.Spec.TopologyDomains = []TopologyDomain{{Key: "example.com/rack", Values: []string{"rack/1"}}}
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
+	})
+	Context("TemplatePropagation", func() {
+		It("should succeed for valid propagation policies", func() {
+			nodePool.Spec.TemplatePropagation = TemplatePropagation{
+				Labels:      PropagationPolicyInPlace,
+				Annotations: PropagationPolicyReplace,
+				Taints:      PropagationPolicyInPlace,
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail for an invalid propagation policy", func() {
+			nodePool.Spec.TemplatePropagation = TemplatePropagation{Labels: "Patch"}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
 	Context("Taints", func() {
 		It("should succeed for valid taints", func() {
 			nodePool.Spec.Template.Spec.Taints = []v1.Taint{
@@ -303,6 +425,40 @@ var _ = Describe("CEL/Validation", func() {
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
 			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
 		})
+		It("should succeed for valid conditional startup taints", func() {
+			nodePool.Spec.Template.Spec.ConditionalStartupTaints = []ConditionalStartupTaints{{
+				Requirements: []v1.NodeSelectorRequirement{{Key: "example.com/gpu-count", Operator: v1.NodeSelectorOpGt, Values: []string{"0"}}},
+				Taints:       []v1.Taint{{Key: "example.com/device-plugin", Effect: v1.TaintEffectNoSchedule}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate()).To(Succeed())
+		})
+		It("should fail for conditional startup taints without requirements or taints", func() {
+			nodePool.Spec.Template.Spec.ConditionalStartupTaints = []ConditionalStartupTaints{{
+				Taints: []v1.Taint{{Key: "example.com/device-plugin", Effect: v1.TaintEffectNoSchedule}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			nodePool.Spec.Template.Spec.ConditionalStartupTaints = []ConditionalStartupTaints{{
+				Requirements: []v1.NodeSelectorRequirement{{Key: "example.com/gpu-count", Operator: v1.NodeSelectorOpGt, Values: []string{"0"}}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail for invalid conditional startup taint keys", func() {
+			nodePool.Spec.Template.Spec.ConditionalStartupTaints = []ConditionalStartupTaints{{
+				Requirements: []v1.NodeSelectorRequirement{{Key: "example.com/gpu-count", Operator: v1.NodeSelectorOpGt, Values: []string{"0"}}},
+				Taints:       []v1.Taint{{Key: "test/test/test", Effect: v1.TaintEffectNoSchedule}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
+		It("should fail at runtime for conditional startup taints that duplicate startup taints", func() {
+			nodePool.Spec.Template.Spec.StartupTaints = []v1.Taint{{Key: "example.com/device-plugin", Effect: v1.TaintEffectNoSchedule}}
+			nodePool.Spec.Template.Spec.ConditionalStartupTaints = []ConditionalStartupTaints{{
+				Requirements: []v1.NodeSelectorRequirement{{Key: "example.com/gpu-count", Operator: v1.NodeSelectorOpGt, Values: []string{"0"}}},
+				Taints:       []v1.Taint{{Key: "example.com/device-plugin", Effect: v1.TaintEffectNoSchedule}},
+			}}
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
 		It("should fail at runtime for taint keys that are too long", func() {
 			oldNodePool := nodePool.DeepCopy()
 			nodePool.Spec.Template.Spec.Taints = []v1.Taint{{Key: fmt.Sprintf("test.com.test.%s/test", strings.ToLower(randomdata.Alphanumeric(250))), Effect: v1.TaintEffectNoSchedule}}
@@ -609,6 +765,25 @@ var _ = Describe("CEL/Validation", func() {
 				nodePool = oldNodePool.DeepCopy()
 			}
 		})
+		It("should allow unrecognized node metadata labels", func() {
+			nodePool.Spec.Template.NodeMetadata.Labels = map[string]string{"foo": randomdata.SillyName()}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate()).To(Succeed())
+		})
+		It("should fail for restricted node metadata labels", func() {
+			oldNodePool := nodePool.DeepCopy()
+			for _, label := range []string{NodePoolLabelKey, v1.LabelHostname, "karpenter.sh/custom-label"} {
+				nodePool.Spec.Template.NodeMetadata.Labels = map[string]string{label: randomdata.SillyName()}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+				Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+				nodePool = oldNodePool.DeepCopy()
+			}
+		})
+		It("should fail for invalid node metadata label values", func() {
+			nodePool.Spec.Template.NodeMetadata.Labels = map[string]string{randomdata.SillyName(): "/ is not allowed"}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate()).ToNot(Succeed())
+		})
 	})
 	Context("TerminationGracePeriod", func() {
 		It("should succeed on a positive terminationGracePeriod duration", func() {
@@ -620,6 +795,26 @@ var _ = Describe("CEL/Validation", func() {
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
 		})
 	})
+	Context("ReadinessTTL", func() {
+		It("should succeed on a positive readinessTTL duration", func() {
+			nodePool.Spec.Template.Spec.ReadinessTTL = &metav1.Duration{Duration: time.Minute * 10}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on a negative readinessTTL duration", func() {
+			nodePool.Spec.Template.Spec.ReadinessTTL = &metav1.Duration{Duration: time.Minute * -10}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("RegistrationTTL", func() {
+		It("should succeed on a positive registrationTTL duration", func() {
+			nodePool.Spec.Template.Spec.RegistrationTTL = &metav1.Duration{Duration: time.Minute * 5}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail on a negative registrationTTL duration", func() {
+			nodePool.Spec.Template.Spec.RegistrationTTL = &metav1.Duration{Duration: time.Minute * -5}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
 	Context("NodeClassRef", func() {
 		It("should fail to mutate group", func() {
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
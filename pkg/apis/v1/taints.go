@@ -24,8 +24,9 @@ import (
 
 // Karpenter specific taints
 const (
-	DisruptedTaintKey    = apis.Group + "/disrupted"
-	UnregisteredTaintKey = apis.Group + "/unregistered"
+	DisruptedTaintKey           = apis.Group + "/disrupted"
+	DisruptionCandidateTaintKey = apis.Group + "/disruption-candidate"
+	UnregisteredTaintKey        = apis.Group + "/unregistered"
 )
 
 var (
@@ -35,6 +36,13 @@ var (
 		Key:    DisruptedTaintKey,
 		Effect: v1.TaintEffectNoSchedule,
 	}
+	// DisruptionCandidatePreferNoScheduleTaint is applied by the disruption controller to consolidation candidates while
+	// the consolidation decision is validated. This steers the kube-scheduler away from placing new pods on nodes that
+	// are likely to be disrupted, which would otherwise invalidate the decision.
+	DisruptionCandidatePreferNoScheduleTaint = v1.Taint{
+		Key:    DisruptionCandidateTaintKey,
+		Effect: v1.TaintEffectPreferNoSchedule,
+	}
 	UnregisteredNoExecuteTaint = v1.Taint{
 		Key:    UnregisteredTaintKey,
 		Effect: v1.TaintEffectNoExecute,
@@ -23,11 +23,35 @@ package v1
 import (
 	"github.com/awslabs/operatorpkg/status"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	timex "time"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalResource) DeepCopyInto(out *AdditionalResource) {
+	*out = *in
+	out.Quantity = in.Quantity.DeepCopy()
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make([]corev1.NodeSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalResource.
+func (in *AdditionalResource) DeepCopy() *AdditionalResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Budget) DeepCopyInto(out *Budget) {
 	*out = *in
@@ -58,6 +82,35 @@ func (in *Budget) DeepCopy() *Budget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionalStartupTaints) DeepCopyInto(out *ConditionalStartupTaints) {
+	*out = *in
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make([]corev1.NodeSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionalStartupTaints.
+func (in *ConditionalStartupTaints) DeepCopy() *ConditionalStartupTaints {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionalStartupTaints)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Disruption) DeepCopyInto(out *Disruption) {
 	*out = *in
@@ -69,6 +122,16 @@ func (in *Disruption) DeepCopyInto(out *Disruption) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.JobCompletionGracePeriod != nil {
+		in, out := &in.JobCompletionGracePeriod, &out.JobCompletionGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinNodeLifetime != nil {
+		in, out := &in.MinNodeLifetime, &out.MinNodeLifetime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Disruption.
@@ -186,6 +249,47 @@ func (in *NodeClaimList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeClaimPhase) DeepCopyInto(out *NodeClaimPhase) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimPhase.
+func (in *NodeClaimPhase) DeepCopy() *NodeClaimPhase {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeClaimPhase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeClaimPhases) DeepCopyInto(out *NodeClaimPhases) {
+	*out = *in
+	in.Launching.DeepCopyInto(&out.Launching)
+	in.Registered.DeepCopyInto(&out.Registered)
+	in.Initialized.DeepCopyInto(&out.Initialized)
+	in.Deleting.DeepCopyInto(&out.Deleting)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimPhases.
+func (in *NodeClaimPhases) DeepCopy() *NodeClaimPhases {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeClaimPhases)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeClaimSpec) DeepCopyInto(out *NodeClaimSpec) {
 	*out = *in
@@ -259,6 +363,13 @@ func (in *NodeClaimStatus) DeepCopyInto(out *NodeClaimStatus) {
 		}
 	}
 	in.LastPodEventTime.DeepCopyInto(&out.LastPodEventTime)
+	if in.Utilization != nil {
+		in, out := &in.Utilization, &out.Utilization
+		*out = make(map[corev1.ResourceName]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimStatus.
@@ -275,6 +386,7 @@ func (in *NodeClaimStatus) DeepCopy() *NodeClaimStatus {
 func (in *NodeClaimTemplate) DeepCopyInto(out *NodeClaimTemplate) {
 	*out = *in
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.NodeMetadata.DeepCopyInto(&out.NodeMetadata)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
@@ -305,6 +417,13 @@ func (in *NodeClaimTemplateSpec) DeepCopyInto(out *NodeClaimTemplateSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConditionalStartupTaints != nil {
+		in, out := &in.ConditionalStartupTaints, &out.ConditionalStartupTaints
+		*out = make([]ConditionalStartupTaints, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Requirements != nil {
 		in, out := &in.Requirements, &out.Requirements
 		*out = make([]NodeSelectorRequirementWithMinValues, len(*in))
@@ -323,6 +442,16 @@ func (in *NodeClaimTemplateSpec) DeepCopyInto(out *NodeClaimTemplateSpec) {
 		**out = **in
 	}
 	in.ExpireAfter.DeepCopyInto(&out.ExpireAfter)
+	if in.RegistrationTTL != nil {
+		in, out := &in.RegistrationTTL, &out.RegistrationTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ReadinessTTL != nil {
+		in, out := &in.ReadinessTTL, &out.ReadinessTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimTemplateSpec.
@@ -421,6 +550,38 @@ func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.AdditionalResources != nil {
+		in, out := &in.AdditionalResources, &out.AdditionalResources
+		*out = make([]AdditionalResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartupTaintTimeout != nil {
+		in, out := &in.StartupTaintTimeout, &out.StartupTaintTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxPodsPerNode != nil {
+		in, out := &in.MaxPodsPerNode, &out.MaxPodsPerNode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SoftMaxNodeSize != nil {
+		in, out := &in.SoftMaxNodeSize, &out.SoftMaxNodeSize
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	out.TemplatePropagation = in.TemplatePropagation
+	if in.TopologyDomains != nil {
+		in, out := &in.TopologyDomains, &out.TopologyDomains
+		*out = make([]TopologyDomain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Weight != nil {
 		in, out := &in.Weight, &out.Weight
 		*out = new(int32)
@@ -448,6 +609,43 @@ func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.ResourcesByCapacityType != nil {
+		in, out := &in.ResourcesByCapacityType, &out.ResourcesByCapacityType
+		*out = make(map[string]corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			var outVal map[corev1.ResourceName]resource.Quantity
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(corev1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.RemainingLimits != nil {
+		in, out := &in.RemainingLimits, &out.RemainingLimits
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeClaims != nil {
+		in, out := &in.NodeClaims, &out.NodeClaims
+		*out = new(NodeClaimPhases)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodesByGeneration != nil {
+		in, out := &in.NodesByGeneration, &out.NodesByGeneration
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]status.Condition, len(*in))
@@ -538,3 +736,38 @@ func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplatePropagation) DeepCopyInto(out *TemplatePropagation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatePropagation.
+func (in *TemplatePropagation) DeepCopy() *TemplatePropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatePropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyDomain) DeepCopyInto(out *TopologyDomain) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyDomain.
+func (in *TopologyDomain) DeepCopy() *TopologyDomain {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyDomain)
+	in.DeepCopyInto(out)
+	return out
+}
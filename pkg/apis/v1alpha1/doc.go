@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen=package,register
+// +k8s:defaulter-gen=TypeMeta
+// +groupName=karpenter.sh
+package v1alpha1 // doc.go is discovered by codegen
+
+import (
+	"github.com/samber/lo"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+)
+
+var (
+	// SchemeGroupVersion is the group version that the types are registered under, used by the generated clients
+	SchemeGroupVersion = schema.GroupVersion{Group: apis.Group, Version: "v1alpha1"}
+	SchemeBuilder      = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme registers the types with a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+func addKnownTypes(s *runtime.Scheme) error {
+	v1.AddToGroupVersion(s, SchemeGroupVersion)
+	s.AddKnownTypes(SchemeGroupVersion,
+		&LabelNormalization{},
+		&LabelNormalizationList{},
+		&OrphanReport{},
+		&OrphanReportList{},
+		&UnavailableOfferings{},
+		&UnavailableOfferingsList{},
+		&NodeDisruptionBudget{},
+		&NodeDisruptionBudgetList{})
+	return nil
+}
+
+func init() {
+	lo.Must0(AddToScheme(scheme.Scheme))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NodeDisruptionBudgetSpec is the top level specification for a NodeDisruptionBudget
+type NodeDisruptionBudgetSpec struct {
+	// NodeSelector selects the nodes that the budget applies to. Nodes are selected regardless of which NodePool
+	// they belong to. An empty selector selects every node that Karpenter manages.
+	// +required
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+	// MaxUnavailable is the maximum number of selected nodes that can be disrupted or NotReady at the same time.
+	// This can be an absolute number or a percentage of the selected nodes, which is rounded up.
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:XValidation:message="maxUnavailable must be a non-negative integer or a percentage between 0% and 100%",rule="type(self) == int ? self >= 0 : self.matches('^((100|[0-9]{1,2})%)$')"
+	// +required
+	MaxUnavailable intstr.IntOrString `json:"maxUnavailable"`
+}
+
+// NodeDisruptionBudget limits the number of nodes that Karpenter voluntarily disrupts at the same time across
+// a set of nodes selected by label, in addition to the disruption budgets of each NodePool. This lets workload
+// owners protect the nodes backing their services when those nodes span multiple NodePools.
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodedisruptionbudgets,scope=Cluster,categories=karpenter,shortName={ndb,ndbs}
+// +kubebuilder:printcolumn:name="MaxUnavailable",type="string",JSONPath=".spec.maxUnavailable",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+type NodeDisruptionBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec NodeDisruptionBudgetSpec `json:"spec"`
+}
+
+// NodeDisruptionBudgetList contains a list of NodeDisruptionBudget
+// +kubebuilder:object:root=true
+type NodeDisruptionBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeDisruptionBudget `json:"items"`
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OrphanReportName is the name of the singleton OrphanReport that Karpenter maintains
+const OrphanReportName = "default"
+
+// MaxReportedOrphans is the maximum number of orphans of each type that are listed in the OrphanReport, which keeps the
+// report within the object size limit when a large number of resources are orphaned. The orphan metrics still count
+// every orphan.
+const MaxReportedOrphans = 100
+
+// OrphanReportStatus is the state of the world that was observed when the report was last generated
+type OrphanReportStatus struct {
+	// LastReportTime is the time that the report was last generated
+	// +optional
+	LastReportTime *metav1.Time `json:"lastReportTime,omitempty"`
+	// NodeClaimsWithoutInstances are the names of registered NodeClaims whose instance is no longer returned by the cloud provider.
+	// Only the first 100 names, in sorted order, are listed.
+	// +listType=set
+	// +kubebuilder:validation:MaxItems:=100
+	// +optional
+	NodeClaimsWithoutInstances []string `json:"nodeClaimsWithoutInstances,omitempty"`
+	// InstancesWithoutNodeClaims are the provider ids of cloud provider instances that aren't tracked by a NodeClaim.
+	// Only the first 100 provider ids, in sorted order, are listed.
+	// +listType=set
+	// +kubebuilder:validation:MaxItems:=100
+	// +optional
+	InstancesWithoutNodeClaims []string `json:"instancesWithoutNodeClaims,omitempty"`
+	// NodesWithoutNodeClaimsOrInstances are the names of Karpenter-managed Nodes that have neither a NodeClaim nor an instance.
+	// Only the first 100 names, in sorted order, are listed.
+	// +listType=set
+	// +kubebuilder:validation:MaxItems:=100
+	// +optional
+	NodesWithoutNodeClaimsOrInstances []string `json:"nodesWithoutNodeClaimsOrInstances,omitempty"`
+}
+
+// OrphanReport summarizes the NodeClaims, cloud provider instances and Nodes that have drifted out of sync with each
+// other. Karpenter only reports these resources so that they can be audited; it never deletes them based on the report.
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=orphanreports,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="LastReport",type="date",JSONPath=".status.lastReportTime",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+type OrphanReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Status OrphanReportStatus `json:"status,omitempty"`
+}
+
+// OrphanReportList contains a list of OrphanReport
+// +kubebuilder:object:root=true
+type OrphanReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OrphanReport `json:"items"`
+}
@@ -0,0 +1,72 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UnavailableOfferingsName is the name of the singleton UnavailableOfferings that Karpenter maintains
+const UnavailableOfferingsName = "default"
+
+// UnavailableOffering is an offering that recently failed to launch because the cloud provider was out of capacity
+type UnavailableOffering struct {
+	// InstanceType is the instance type of the offering
+	// +required
+	InstanceType string `json:"instanceType"`
+	// Zone is the zone of the offering
+	// +required
+	Zone string `json:"zone"`
+	// CapacityType is the capacity type of the offering
+	// +required
+	CapacityType string `json:"capacityType"`
+	// ExpirationTime is the time after which the offering is considered for launches again
+	// +required
+	ExpirationTime metav1.Time `json:"expirationTime"`
+}
+
+// UnavailableOfferingsStatus is the set of offerings that are currently considered unavailable
+type UnavailableOfferingsStatus struct {
+	// Offerings are the offerings that are currently considered unavailable
+	// +optional
+	Offerings []UnavailableOffering `json:"offerings,omitempty"`
+}
+
+// UnavailableOfferings persists the offerings that recently failed to launch because the cloud provider was out of
+// capacity, so that Karpenter doesn't retry them after it restarts or another replica becomes the leader.
+// +genclient
+// +genclient:nonNamespaced
+// +resourceName=unavailableofferings
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=unavailableofferings,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+type UnavailableOfferings struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Status UnavailableOfferingsStatus `json:"status,omitempty"`
+}
+
+// UnavailableOfferingsList contains a list of UnavailableOfferings
+// +kubebuilder:object:root=true
+type UnavailableOfferingsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UnavailableOfferings `json:"items"`
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/karpenter/pkg/client/informers/externalversions"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+
+func TestClient(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Client")
+}
+
+var _ = Describe("Client", func() {
+	It("should get NodePools and NodeClaims through the typed client", func() {
+		nodePool := test.NodePool()
+		nodeClaim := test.NodeClaim()
+		clientset := fake.NewSimpleClientset(nodePool, nodeClaim)
+
+		gotNodePool, err := clientset.KarpenterV1().NodePools().Get(ctx, nodePool.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotNodePool.Spec).To(Equal(nodePool.Spec))
+		gotNodeClaim, err := clientset.KarpenterV1().NodeClaims().Get(ctx, nodeClaim.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotNodeClaim.Spec).To(Equal(nodeClaim.Spec))
+	})
+	It("should list NodePools and NodeClaims through the informers", func() {
+		nodePool := test.NodePool()
+		nodeClaim := test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+		clientset := fake.NewSimpleClientset(nodePool, nodeClaim)
+
+		factory := externalversions.NewSharedInformerFactory(clientset, time.Minute)
+		nodePoolInformer := factory.Karpenter().V1().NodePools()
+		nodeClaimInformer := factory.Karpenter().V1().NodeClaims()
+		// informers have to be requested before the factory is started
+		nodePoolSynced := nodePoolInformer.Informer().HasSynced
+		nodeClaimSynced := nodeClaimInformer.Informer().HasSynced
+		stop := make(chan struct{})
+		defer close(stop)
+		factory.Start(stop)
+		Expect(cache.WaitForCacheSync(stop, nodePoolSynced, nodeClaimSynced)).To(BeTrue())
+
+		gotNodePool, err := nodePoolInformer.Lister().Get(nodePool.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotNodePool.Name).To(Equal(nodePool.Name))
+		nodeClaims, err := nodeClaimInformer.Lister().List(labels.SelectorFromSet(labels.Set{v1.NodePoolLabelKey: nodePool.Name}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Name).To(Equal(nodeClaim.Name))
+	})
+	It("should get and list v1alpha1 resources through the typed client and the informers", func() {
+		budget := &v1alpha1.NodeDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "budget"}}
+		clientset := fake.NewSimpleClientset(budget)
+
+		gotBudget, err := clientset.KarpenterV1alpha1().NodeDisruptionBudgets().Get(ctx, budget.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotBudget.Name).To(Equal(budget.Name))
+
+		factory := externalversions.NewSharedInformerFactory(clientset, time.Minute)
+		informer := factory.Karpenter().V1alpha1().NodeDisruptionBudgets()
+		synced := informer.Informer().HasSynced
+		stop := make(chan struct{})
+		defer close(stop)
+		factory.Start(stop)
+		Expect(cache.WaitForCacheSync(stop, synced)).To(BeTrue())
+		budgets, err := informer.Lister().List(labels.Everything())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(budgets).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,81 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// AdditionalResources returns the extended resources that the NodePool advertises on capacity with the given
+// requirements. Requirements on keys that aren't defined by the capacity don't prevent a resource from matching.
+func AdditionalResources(nodePool *v1.NodePool, requirements scheduling.Requirements) corev1.ResourceList {
+	additional := corev1.ResourceList{}
+	for _, r := range nodePool.Spec.AdditionalResources {
+		if requirements.Intersects(scheduling.NewNodeSelectorRequirements(r.Requirements...)) != nil {
+			continue
+		}
+		resources.MergeInto(additional, corev1.ResourceList{corev1.ResourceName(r.Name): r.Quantity})
+	}
+	return additional
+}
+
+// ApplyAdditionalResources returns the passed instance types with the NodePool's additional resources added to their
+// capacity. Instance types that advertise additional resources are copied so that the instance types cached by the
+// cloud provider aren't mutated. Huge pages are carved out of the memory capacity of the instance type, and instance
+// types that don't have enough memory to back the huge pages are removed.
+func ApplyAdditionalResources(nodePool *v1.NodePool, instanceTypes []*InstanceType) []*InstanceType {
+	if len(nodePool.Spec.AdditionalResources) == 0 {
+		return instanceTypes
+	}
+	return lo.FilterMap(instanceTypes, func(it *InstanceType, _ int) (*InstanceType, bool) {
+		additional := AdditionalResources(nodePool, it.Requirements)
+		if len(additional) == 0 {
+			return it, true
+		}
+		capacity := resources.Subtract(resources.Merge(it.Capacity, additional), corev1.ResourceList{corev1.ResourceMemory: HugePages(additional)})
+		if capacity.Memory().Sign() < 0 {
+			return nil, false
+		}
+		return &InstanceType{
+			Name:         it.Name,
+			Family:       it.Family,
+			Requirements: it.Requirements,
+			Offerings:    it.Offerings,
+			Capacity:     capacity,
+			Overhead:     it.Overhead,
+		}, true
+	})
+}
+
+// HugePages returns the total memory that's preallocated to the huge page resources in the resource list
+func HugePages(rl corev1.ResourceList) resource.Quantity {
+	total := resource.Quantity{}
+	for name, quantity := range rl {
+		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			total.Add(quantity)
+		}
+	}
+	return total
+}
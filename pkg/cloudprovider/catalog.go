@@ -0,0 +1,180 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// InstanceTypeCatalogTTL is how long an instance type stays in the catalog after it was last resolved for a NodePool
+const InstanceTypeCatalogTTL = 5 * time.Minute
+
+// InstanceTypeCatalog interns the instance types that are resolved for NodePools. Cloud providers commonly resolve
+// instance types per NodePool, so NodePools with overlapping instance types hold duplicate copies of the same
+// requirements and offerings. The catalog collapses instance types that are identical onto a single shared copy, and
+// caches the NodePool specific views of the shared copies (e.g. with additional resources or max pods applied) so they
+// aren't rebuilt on every scheduling simulation. Both are keyed by the fingerprint of the instance type's contents.
+// Fingerprints are remembered by identity, so instance types that the cloud provider returns on every resolution aren't
+// hashed again. Cloud providers must not mutate the instance types that they return in place, and should return new
+// instance types when their contents change instead.
+type InstanceTypeCatalog struct {
+	mu sync.Mutex
+	// interned holds the shared copy of each distinct instance type, keyed by the fingerprint of its contents
+	interned *cache.Cache
+	// views holds the NodePool specific views of the shared copies, keyed by the fingerprint of the shared copy and the
+	// NodePool settings that the view applies
+	views *cache.Cache
+	// fingerprints holds the fingerprint of each instance type that was seen since the last sweep, and swept holds the
+	// fingerprints from before it. Instance types that aren't seen for a whole sweep are forgotten, so that copies that
+	// the cloud provider only returned once aren't kept alive.
+	fingerprints map[*InstanceType]uint64
+	swept        map[*InstanceType]uint64
+}
+
+func NewInstanceTypeCatalog() *InstanceTypeCatalog {
+	return &InstanceTypeCatalog{
+		interned:     cache.New(InstanceTypeCatalogTTL, time.Minute),
+		views:        cache.New(InstanceTypeCatalogTTL, time.Minute),
+		fingerprints: map[*InstanceType]uint64{},
+		swept:        map[*InstanceType]uint64{},
+	}
+}
+
+// Intern returns the shared copy of each of the passed instance types. Instance types that haven't been seen before
+// become the shared copy.
+func (c *InstanceTypeCatalog) Intern(instanceTypes []*InstanceType) []*InstanceType {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return lo.Map(instanceTypes, func(it *InstanceType, _ int) *InstanceType {
+		key := strconv.FormatUint(c.fingerprint(it), 36)
+		if v, ok := c.interned.Get(key); ok {
+			c.interned.SetDefault(key, v)
+			return v.(*InstanceType)
+		}
+		c.interned.SetDefault(key, it)
+		return it
+	})
+}
+
+// Sweep forgets the fingerprints of the instance types that weren't seen since the previous sweep. It should be called
+// once the instance types of every NodePool are resolved.
+func (c *InstanceTypeCatalog) Sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.swept, c.fingerprints = c.fingerprints, make(map[*InstanceType]uint64, len(c.fingerprints))
+}
+
+// NodePoolView returns the passed instance types with the NodePool's additional resources and max pods applied. The
+// views are cached so that NodePools with the same settings share them.
+func (c *InstanceTypeCatalog) NodePoolView(nodePool *v1.NodePool, instanceTypes []*InstanceType) []*InstanceType {
+	if len(nodePool.Spec.AdditionalResources) == 0 && nodePool.Spec.MaxPodsPerNode == nil {
+		return instanceTypes
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	settings := viewSettings(nodePool)
+	return lo.FilterMap(instanceTypes, func(it *InstanceType, _ int) (*InstanceType, bool) {
+		key := strconv.FormatUint(c.fingerprint(it), 36) + "/" + settings
+		if v, ok := c.views.Get(key); ok {
+			c.views.SetDefault(key, v)
+			return v.(*InstanceType), v.(*InstanceType) != nil
+		}
+		// Instance types without enough memory to back the NodePool's huge pages are filtered out of the view
+		view, ok := lo.First(ApplyMaxPods(nodePool, ApplyAdditionalResources(nodePool, []*InstanceType{it})))
+		c.views.SetDefault(key, lo.Ternary(ok, view, nil))
+		return view, ok
+	})
+}
+
+// Flush removes every instance type from the catalog
+func (c *InstanceTypeCatalog) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interned.Flush()
+	c.views.Flush()
+	c.fingerprints = map[*InstanceType]uint64{}
+	c.swept = map[*InstanceType]uint64{}
+}
+
+func viewSettings(nodePool *v1.NodePool) string {
+	additionalResources := lo.Must(json.Marshal(nodePool.Spec.AdditionalResources))
+	return fmt.Sprintf("%d/%s", lo.FromPtr(nodePool.Spec.MaxPodsPerNode), additionalResources)
+}
+
+// fingerprint returns the fingerprint of the instance type, which is only hashed if it wasn't seen since the previous
+// sweep
+func (c *InstanceTypeCatalog) fingerprint(it *InstanceType) uint64 {
+	if f, ok := c.fingerprints[it]; ok {
+		return f
+	}
+	f, ok := c.swept[it]
+	if !ok {
+		f = fingerprint(it)
+	}
+	c.fingerprints[it] = f
+	return f
+}
+
+// fingerprint hashes the contents of an instance type, so that instance types with the same fingerprint are
+// interchangeable
+func fingerprint(it *InstanceType) uint64 {
+	// The contents are written to a single buffer that's hashed at once, since hashing each field separately is
+	// considerably slower for instance types with many offerings
+	buf := make([]byte, 0, 512)
+	write := func(values ...string) {
+		for _, v := range values {
+			buf = append(append(buf, v...), 0)
+		}
+		buf = append(buf, 1)
+	}
+	quantities := func(rl corev1.ResourceList) {
+		names := lo.Keys(rl)
+		slices.Sort(names)
+		for _, name := range names {
+			write(string(name), strconv.FormatInt(lo.ToPtr(rl[name]).MilliValue(), 10))
+		}
+		write()
+	}
+	write(it.Name, it.Family)
+	buf = binary.LittleEndian.AppendUint64(buf, it.Requirements.Fingerprint())
+	for _, o := range it.Offerings {
+		buf = binary.LittleEndian.AppendUint64(buf, o.Requirements.Fingerprint())
+		write(strconv.FormatFloat(o.Price, 'g', -1, 64), strconv.FormatBool(o.Available))
+	}
+	write()
+	overhead := lo.FromPtr(it.Overhead)
+	quantities(it.Capacity)
+	quantities(overhead.KubeReserved)
+	quantities(overhead.SystemReserved)
+	quantities(overhead.EvictionThreshold)
+	h := fnv.New64a()
+	lo.Must(h.Write(buf))
+	return h.Sum64()
+}
@@ -0,0 +1,137 @@
+//go:build test_performance
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/samber/lo"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+// To run the benchmarks use:
+// `go test -tags=test_performance -run=XXX -bench=InstanceTypeResolution -benchmem`
+//
+// Each benchmark resolves the instance types of every NodePool once per iteration, as a scheduling loop does. The
+// Baseline benchmarks apply the NodePool's settings to the instance types without the catalog, and the Catalog
+// benchmarks intern them and take the NodePool's view. The retained-B/loop metric is the heap that the result of the
+// first loop keeps live on top of the instance types that the cloud provider holds on to.
+
+const (
+	benchmarkNodePools     = 20
+	benchmarkInstanceTypes = 400
+)
+
+// BenchmarkInstanceTypeResolutionCachedBaseline resolves instance types that the cloud provider caches and returns on
+// every resolution, without the catalog
+func BenchmarkInstanceTypeResolutionCachedBaseline(b *testing.B) {
+	benchmarkInstanceTypeResolution(b, cachedInstanceTypes(), nil)
+}
+
+// BenchmarkInstanceTypeResolutionCachedCatalog resolves instance types that the cloud provider caches and returns on
+// every resolution, with the catalog
+func BenchmarkInstanceTypeResolutionCachedCatalog(b *testing.B) {
+	benchmarkInstanceTypeResolution(b, cachedInstanceTypes(), cloudprovider.NewInstanceTypeCatalog())
+}
+
+// BenchmarkInstanceTypeResolutionPerNodePoolBaseline resolves instance types that the cloud provider caches per
+// NodePool, so that each NodePool has a distinct copy of the same instance types, without the catalog
+func BenchmarkInstanceTypeResolutionPerNodePoolBaseline(b *testing.B) {
+	benchmarkInstanceTypeResolution(b, perNodePoolInstanceTypes(), nil)
+}
+
+// BenchmarkInstanceTypeResolutionPerNodePoolCatalog resolves instance types that the cloud provider caches per
+// NodePool, so that each NodePool has a distinct copy of the same instance types, with the catalog
+func BenchmarkInstanceTypeResolutionPerNodePoolCatalog(b *testing.B) {
+	benchmarkInstanceTypeResolution(b, perNodePoolInstanceTypes(), cloudprovider.NewInstanceTypeCatalog())
+}
+
+func baseline(nodePool *v1.NodePool, instanceTypes []*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+	return cloudprovider.ApplyMaxPods(nodePool, cloudprovider.ApplyAdditionalResources(nodePool, instanceTypes))
+}
+
+func withCatalog(catalog *cloudprovider.InstanceTypeCatalog) func(*v1.NodePool, []*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+	return func(nodePool *v1.NodePool, instanceTypes []*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+		return catalog.NodePoolView(nodePool, catalog.Intern(instanceTypes))
+	}
+}
+
+// cachedInstanceTypes returns the same instance types for every NodePool on every resolution
+func cachedInstanceTypes() func(int) []*cloudprovider.InstanceType {
+	instanceTypes := fake.InstanceTypes(benchmarkInstanceTypes)
+	return func(int) []*cloudprovider.InstanceType { return instanceTypes }
+}
+
+// perNodePoolInstanceTypes returns a distinct copy of the instance types for each NodePool, which is the same on every
+// resolution
+func perNodePoolInstanceTypes() func(int) []*cloudprovider.InstanceType {
+	instanceTypes := lo.Times(benchmarkNodePools, func(_ int) []*cloudprovider.InstanceType { return fake.InstanceTypes(benchmarkInstanceTypes) })
+	return func(i int) []*cloudprovider.InstanceType { return instanceTypes[i] }
+}
+
+func benchmarkInstanceTypeResolution(b *testing.B, getInstanceTypes func(int) []*cloudprovider.InstanceType, catalog *cloudprovider.InstanceTypeCatalog) {
+	// Half of the NodePools lower the max pods, so that their views differ from the instance types
+	nodePools := lo.Times(benchmarkNodePools, func(i int) *v1.NodePool {
+		nodePool := test.NodePool()
+		nodePool.Name = fmt.Sprintf("nodepool-%d", i)
+		if i%2 == 0 {
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](50)
+		}
+		return nodePool
+	})
+	resolve := lo.Ternary(catalog == nil, baseline, withCatalog(catalog))
+	loop := func() map[string][]*cloudprovider.InstanceType {
+		resolved := map[string][]*cloudprovider.InstanceType{}
+		for i, np := range nodePools {
+			resolved[np.Name] = resolve(np, getInstanceTypes(i))
+		}
+		if catalog != nil {
+			catalog.Sweep()
+		}
+		return resolved
+	}
+
+	// The instance types that the cloud provider holds on to are resolved before measuring, so that only what the loop
+	// retains on top of them is measured
+	getInstanceTypes(0)
+	before := liveHeap()
+	resolved := loop()
+	retained := liveHeap() - before
+	runtime.KeepAlive(resolved)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resolved = loop()
+	}
+	b.ReportMetric(float64(retained), "retained-B/loop")
+}
+
+func liveHeap() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -125,12 +126,18 @@ func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v
 			i.Offerings.Available().HasCompatible(reqs) &&
 			resources.Fits(nodeClaim.Spec.Resources.Requests, i.Allocatable())
 	})
-	// Order instance types so that we get the cheapest instance types of the available offerings
+	// Order instance types so that we get the cheapest instance types of the available offerings, preferring reserved offerings
 	sort.Slice(instanceTypes, func(i, j int) bool {
 		iOfferings := instanceTypes[i].Offerings.Available().Compatible(reqs)
 		jOfferings := instanceTypes[j].Offerings.Available().Compatible(reqs)
+		if iReserved, jReserved := iOfferings.HasCompatible(cloudprovider.ReservedRequirement), jOfferings.HasCompatible(cloudprovider.ReservedRequirement); iReserved != jReserved {
+			return iReserved
+		}
 		return iOfferings.Cheapest().Price < jOfferings.Cheapest().Price
 	})
+	if preference, ok := nodeClaim.Annotations[v1.InstanceFamilyPreferenceAnnotationKey]; ok {
+		instanceTypes = cloudprovider.InstanceTypes(instanceTypes).OrderByFamilyPreference(strings.Split(preference, ","))
+	}
 	instanceType := instanceTypes[0]
 	// Labels
 	labels := map[string]string{}
@@ -140,7 +147,11 @@ func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v
 		}
 	}
 	// Find Offering
-	for _, o := range instanceType.Offerings.Available() {
+	offerings := instanceType.Offerings.Available()
+	if reserved := offerings.Compatible(reqs).Compatible(cloudprovider.ReservedRequirement); len(reserved) > 0 {
+		offerings = reserved
+	}
+	for _, o := range offerings {
 		if reqs.IsCompatible(o.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
 			labels[corev1.LabelTopologyZone] = o.Requirements.Get(corev1.LabelTopologyZone).Any()
 			labels[v1.CapacityTypeLabelKey] = o.Requirements.Get(v1.CapacityTypeLabelKey).Any()
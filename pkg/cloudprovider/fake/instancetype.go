@@ -118,8 +118,11 @@ func NewInstanceTypeWithCustomRequirement(options InstanceTypeOptions, customReq
 		requirements.Get(LabelInstanceSize).Insert("small")
 	}
 
+	// Instance types are in the family before the first "." of their name, e.g. m5 for m5.large
+	family, _, _ := strings.Cut(options.Name, ".")
 	return &cloudprovider.InstanceType{
 		Name:         options.Name,
+		Family:       family,
 		Requirements: requirements,
 		Offerings:    options.Offerings,
 		Capacity:     options.Resources,
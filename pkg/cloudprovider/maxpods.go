@@ -0,0 +1,63 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// ApplyMaxPods returns the passed instance types with their pod capacity lowered to the NodePool's maxPodsPerNode.
+// Instance types whose pod capacity is lowered are copied so that the instance types cached by the cloud provider
+// aren't mutated.
+func ApplyMaxPods(nodePool *v1.NodePool, instanceTypes []*InstanceType) []*InstanceType {
+	if nodePool.Spec.MaxPodsPerNode == nil {
+		return instanceTypes
+	}
+	return lo.Map(instanceTypes, func(it *InstanceType, _ int) *InstanceType {
+		capacity := MaxPods(nodePool, it.Capacity)
+		if resources.Cmp(capacity[corev1.ResourcePods], it.Capacity[corev1.ResourcePods]) == 0 {
+			return it
+		}
+		return &InstanceType{
+			Name:         it.Name,
+			Family:       it.Family,
+			Requirements: it.Requirements,
+			Offerings:    it.Offerings,
+			Capacity:     capacity,
+			Overhead:     it.Overhead,
+		}
+	})
+}
+
+// MaxPods returns a copy of the resource list with its pods lowered to the NodePool's maxPodsPerNode. The pods are
+// never raised, so resource lists without pods or with fewer pods are returned unchanged.
+func MaxPods(nodePool *v1.NodePool, rl corev1.ResourceList) corev1.ResourceList {
+	pods, ok := rl[corev1.ResourcePods]
+	if nodePool.Spec.MaxPodsPerNode == nil || !ok {
+		return rl
+	}
+	maxPods := resource.NewQuantity(int64(lo.FromPtr(nodePool.Spec.MaxPodsPerNode)), resource.DecimalSI)
+	if pods.Cmp(*maxPods) <= 0 {
+		return rl
+	}
+	return lo.Assign(rl, corev1.ResourceList{corev1.ResourcePods: *maxPods})
+}
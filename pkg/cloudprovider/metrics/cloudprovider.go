@@ -107,7 +107,8 @@ func (d *decorator) Delete(ctx context.Context, nodeClaim *v1.NodeClaim) error {
 	method := "Delete"
 	defer metrics.Measure(MethodDuration, getLabelsMapForDuration(ctx, d, method))()
 	err := d.CloudProvider.Delete(ctx, nodeClaim)
-	if err != nil {
+	// An asynchronous deletion that is still in progress isn't a failure
+	if err != nil && !cloudprovider.IsDeletionInProgressError(err) {
 		ErrorsTotal.Inc(getLabelsMapForError(ctx, d, method, err))
 	}
 	return err
@@ -177,6 +178,9 @@ func getLabelsMapForError(ctx context.Context, d *decorator, method string, err
 // GetErrorTypeLabelValue is a convenience func that returns
 // a string representation of well-known CloudProvider error types
 func GetErrorTypeLabelValue(err error) string {
+	if providerErr := cloudprovider.ProviderErrorFor(err); providerErr != nil {
+		return string(providerErr.Reason)
+	}
 	switch {
 	case cloudprovider.IsInsufficientCapacityError(err):
 		return InsufficientCapacityError
@@ -18,6 +18,7 @@ package metrics_test
 
 import (
 	"errors"
+	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +31,7 @@ var _ = Describe("Cloudprovider", func() {
 	var nodeClaimNotFoundErr = cloudprovider.NewNodeClaimNotFoundError(errors.New("not found"))
 	var insufficientCapacityErr = cloudprovider.NewInsufficientCapacityError(errors.New("not enough capacity"))
 	var nodeClassNotReadyErr = cloudprovider.NewNodeClassNotReadyError(errors.New("not ready"))
+	var quotaExceededErr = cloudprovider.NewProviderError(errors.New("vcpu limit exceeded"), cloudprovider.ProviderErrorReasonQuotaExceeded, "VcpuLimitExceeded")
 	var unknownErr = errors.New("this is an error we don't know about")
 
 	Describe("CloudProvider nodeclaim errors via GetErrorTypeLabelValue()", func() {
@@ -43,6 +45,10 @@ var _ = Describe("Cloudprovider", func() {
 			It("nodeclass not ready should be recognized", func() {
 				Expect(metrics.GetErrorTypeLabelValue(nodeClassNotReadyErr)).To(Equal(metrics.NodeClassNotReadyError))
 			})
+			It("provider errors should be recognized by their reason", func() {
+				Expect(metrics.GetErrorTypeLabelValue(quotaExceededErr)).To(Equal(string(cloudprovider.ProviderErrorReasonQuotaExceeded)))
+				Expect(metrics.GetErrorTypeLabelValue(fmt.Errorf("creating instance, %w", quotaExceededErr))).To(Equal(string(cloudprovider.ProviderErrorReasonQuotaExceeded)))
+			})
 		})
 		Context("when the error is unknown", func() {
 			It("should always return empty string", func() {
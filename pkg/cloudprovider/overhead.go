@@ -0,0 +1,131 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+var (
+	flatKubeReservedCPU    = resource.MustParse("100m")
+	flatKubeReservedMemory = resource.MustParse("10Mi")
+
+	eksSystemReservedCPU              = resource.MustParse("100m")
+	eksSystemReservedMemory           = resource.MustParse("100Mi")
+	eksSystemReservedEphemeralStorage = resource.MustParse("1Gi")
+)
+
+// KubeReservedStrategyForNodePool returns the KubeReservedStrategy that the NodePool's template selects, falling back to
+// the cloud provider's default when the NodePool doesn't select one
+func KubeReservedStrategyForNodePool(nodePool *v1.NodePool, defaultStrategy v1.KubeReservedStrategy) v1.KubeReservedStrategy {
+	if nodePool == nil || nodePool.Spec.Template.Spec.KubeReservedStrategy == "" {
+		return defaultStrategy
+	}
+	return nodePool.Spec.Template.Spec.KubeReservedStrategy
+}
+
+// KubeReserved computes the kube-reserved resources for an instance type with the given capacity using the passed strategy
+func KubeReserved(strategy v1.KubeReservedStrategy, capacity corev1.ResourceList) corev1.ResourceList {
+	switch strategy {
+	case v1.KubeReservedStrategyEKS:
+		return corev1.ResourceList{
+			corev1.ResourceCPU:              reservedCPU(capacity.Cpu().MilliValue()),
+			corev1.ResourceMemory:           *resource.NewQuantity(((11*capacity.Pods().Value())+255)*1024*1024, resource.BinarySI),
+			corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		}
+	case v1.KubeReservedStrategyGKE:
+		return corev1.ResourceList{
+			corev1.ResourceCPU:    reservedCPU(capacity.Cpu().MilliValue()),
+			corev1.ResourceMemory: reservedMemoryGKE(capacity.Memory().Value()),
+		}
+	default:
+		return corev1.ResourceList{
+			corev1.ResourceCPU:    flatKubeReservedCPU.DeepCopy(),
+			corev1.ResourceMemory: flatKubeReservedMemory.DeepCopy(),
+		}
+	}
+}
+
+// SystemReserved computes the system-reserved resources for an instance type with the given capacity using the passed
+// strategy. The EKS kubelet configuration reserves a fixed amount of resources for the OS system daemons, while the GKE
+// kubelet configuration accounts for them in kube-reserved, so the GKE and Flat strategies don't reserve anything.
+func SystemReserved(strategy v1.KubeReservedStrategy, _ corev1.ResourceList) corev1.ResourceList {
+	switch strategy {
+	case v1.KubeReservedStrategyEKS:
+		return corev1.ResourceList{
+			corev1.ResourceCPU:              eksSystemReservedCPU.DeepCopy(),
+			corev1.ResourceMemory:           eksSystemReservedMemory.DeepCopy(),
+			corev1.ResourceEphemeralStorage: eksSystemReservedEphemeralStorage.DeepCopy(),
+		}
+	default:
+		return corev1.ResourceList{}
+	}
+}
+
+// reservedCPU reserves 6% of the first core, 1% of the second core, 0.5% of the next two cores and 0.25% of any
+// cores above four. Both the EKS and GKE kubelet configurations use these ranges.
+func reservedCPU(milliCPU int64) resource.Quantity {
+	ranges := []struct {
+		start, end int64
+		percentage float64
+	}{
+		{start: 0, end: 1000, percentage: 0.06},
+		{start: 1000, end: 2000, percentage: 0.01},
+		{start: 2000, end: 4000, percentage: 0.005},
+		{start: 4000, end: math.MaxInt64, percentage: 0.0025},
+	}
+	var reserved float64
+	for _, r := range ranges {
+		if milliCPU <= r.start {
+			break
+		}
+		reserved += float64(min(milliCPU, r.end)-r.start) * r.percentage
+	}
+	return *resource.NewMilliQuantity(int64(reserved), resource.DecimalSI)
+}
+
+// reservedMemoryGKE reserves 255Mi on machines with less than 1Gi of memory, and otherwise reserves 25% of the first
+// 4Gi, 20% of the next 4Gi, 10% of the next 8Gi, 6% of the next 112Gi and 2% of any memory above 128Gi
+func reservedMemoryGKE(memory int64) resource.Quantity {
+	const gi = int64(1 << 30)
+	if memory < gi {
+		return resource.MustParse("255Mi")
+	}
+	ranges := []struct {
+		start, end int64
+		percentage float64
+	}{
+		{start: 0, end: 4 * gi, percentage: 0.25},
+		{start: 4 * gi, end: 8 * gi, percentage: 0.2},
+		{start: 8 * gi, end: 16 * gi, percentage: 0.1},
+		{start: 16 * gi, end: 128 * gi, percentage: 0.06},
+		{start: 128 * gi, end: math.MaxInt64, percentage: 0.02},
+	}
+	var reserved float64
+	for _, r := range ranges {
+		if memory <= r.start {
+			break
+		}
+		reserved += float64(min(memory, r.end)-r.start) * r.percentage
+	}
+	return *resource.NewQuantity(int64(reserved), resource.BinarySI)
+}
@@ -0,0 +1,261 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+func TestCloudProvider(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CloudProvider")
+}
+
+var _ = Describe("InstanceTypeCatalog", func() {
+	var catalog *cloudprovider.InstanceTypeCatalog
+	var nodePool *v1.NodePool
+	newInstanceType := func(name string) *cloudprovider.InstanceType {
+		return fake.NewInstanceType(fake.InstanceTypeOptions{
+			Name: name,
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		catalog = cloudprovider.NewInstanceTypeCatalog()
+		nodePool = test.NodePool()
+	})
+	Context("Intern", func() {
+		It("should share a single copy of identical instance types", func() {
+			first := catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a"), newInstanceType("b")})
+			second := catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a"), newInstanceType("b")})
+			Expect(second[0]).To(BeIdenticalTo(first[0]))
+			Expect(second[1]).To(BeIdenticalTo(first[1]))
+			Expect(first[0]).ToNot(BeIdenticalTo(first[1]))
+		})
+		It("should return instance types that have already been interned", func() {
+			its := []*cloudprovider.InstanceType{newInstanceType("a")}
+			Expect(catalog.Intern(its)[0]).To(BeIdenticalTo(its[0]))
+			Expect(catalog.Intern(its)[0]).To(BeIdenticalTo(its[0]))
+		})
+		It("should not share instance types with different offerings", func() {
+			first := catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a")})
+			other := newInstanceType("a")
+			other.Offerings[0].Price = 0.001
+			second := catalog.Intern([]*cloudprovider.InstanceType{other})
+			Expect(second[0]).To(BeIdenticalTo(other))
+			Expect(second[0]).ToNot(BeIdenticalTo(first[0]))
+		})
+		It("should not share instance types with different requirements", func() {
+			first := catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a")})
+			other := newInstanceType("a")
+			other.Requirements.Get(corev1.LabelTopologyZone).Insert("test-zone-other")
+			second := catalog.Intern([]*cloudprovider.InstanceType{other})
+			Expect(second[0]).To(BeIdenticalTo(other))
+			Expect(second[0]).ToNot(BeIdenticalTo(first[0]))
+		})
+		It("should keep sharing the first copy after a copy with different contents is interned", func() {
+			first := catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a")})
+			other := newInstanceType("a")
+			other.Offerings[0].Available = false
+			catalog.Intern([]*cloudprovider.InstanceType{other})
+			Expect(catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a")})[0]).To(BeIdenticalTo(first[0]))
+			Expect(catalog.Intern([]*cloudprovider.InstanceType{newInstanceType("a")})[0]).ToNot(BeIdenticalTo(other))
+		})
+		It("should only remember the fingerprints of the instance types that were seen since the previous sweep", func() {
+			its := []*cloudprovider.InstanceType{newInstanceType("a")}
+			Expect(catalog.Intern(its)[0]).To(BeIdenticalTo(its[0]))
+			// The contents change in place, which cloud providers must not do, so the change is only noticed once the
+			// fingerprint is forgotten
+			its[0].Offerings[0].Price = 0.001
+			other := newInstanceType("a")
+			other.Offerings[0].Price = 0.001
+			Expect(catalog.Intern([]*cloudprovider.InstanceType{other})[0]).To(BeIdenticalTo(other))
+
+			catalog.Sweep()
+			Expect(catalog.Intern(its)[0]).To(BeIdenticalTo(its[0]))
+			catalog.Sweep()
+			catalog.Sweep()
+			Expect(catalog.Intern(its)[0]).To(BeIdenticalTo(other))
+		})
+	})
+	Context("NodePoolView", func() {
+		It("should return the instance types when the NodePool doesn't change them", func() {
+			its := []*cloudprovider.InstanceType{newInstanceType("a")}
+			Expect(catalog.NodePoolView(nodePool, its)[0]).To(BeIdenticalTo(its[0]))
+		})
+		It("should share views between NodePools with the same settings", func() {
+			its := []*cloudprovider.InstanceType{newInstanceType("a")}
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](10)
+			other := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{MaxPodsPerNode: lo.ToPtr[int32](10)}})
+			view := catalog.NodePoolView(nodePool, its)
+			Expect(view[0].Capacity.Pods().Value()).To(BeNumerically("==", 10))
+			Expect(its[0].Capacity.Pods().Value()).To(BeNumerically("==", 110))
+			Expect(catalog.NodePoolView(other, its)[0]).To(BeIdenticalTo(view[0]))
+
+			other.Spec.MaxPodsPerNode = lo.ToPtr[int32](20)
+			Expect(catalog.NodePoolView(other, its)[0].Capacity.Pods().Value()).To(BeNumerically("==", 20))
+		})
+		It("should build a new view when the instance type's capacity changes", func() {
+			its := []*cloudprovider.InstanceType{newInstanceType("a")}
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: "example.com/licenses", Quantity: resource.MustParse("2")}}
+			view := catalog.NodePoolView(nodePool, its)
+			Expect(view[0].Capacity.Name("example.com/licenses", resource.DecimalSI).Value()).To(BeNumerically("==", 2))
+
+			// Cloud providers return new instance types when their contents change
+			its = []*cloudprovider.InstanceType{newInstanceType("a")}
+			its[0].Capacity[corev1.ResourceCPU] = resource.MustParse("8")
+			view = catalog.NodePoolView(nodePool, its)
+			Expect(view[0].Capacity.Cpu().Value()).To(BeNumerically("==", 8))
+			Expect(view[0].Capacity.Name("example.com/licenses", resource.DecimalSI).Value()).To(BeNumerically("==", 2))
+		})
+		It("should filter out instance types that can't back the NodePool's huge pages", func() {
+			its := []*cloudprovider.InstanceType{newInstanceType("a")}
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: "hugepages-1Gi", Quantity: resource.MustParse("16Gi")}}
+			Expect(catalog.NodePoolView(nodePool, its)).To(BeEmpty())
+			Expect(catalog.NodePoolView(nodePool, its)).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Offerings", func() {
+	offering := func(capacityType string, price float64) cloudprovider.Offering {
+		return cloudprovider.Offering{
+			Requirements: scheduling.NewLabelRequirements(map[string]string{
+				v1.CapacityTypeLabelKey:  capacityType,
+				corev1.LabelTopologyZone: "test-zone-1",
+			}),
+			Price:     price,
+			Available: true,
+		}
+	}
+	offerings := cloudprovider.Offerings{
+		offering(v1.CapacityTypeReserved, 1),
+		offering(v1.CapacityTypeSpot, 2),
+		offering(v1.CapacityTypeOnDemand, 3),
+	}
+	capacityTypes := func(capacityTypes ...string) scheduling.Requirements {
+		return scheduling.NewRequirements(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, capacityTypes...))
+	}
+	Context("WorstLaunchPrice", func() {
+		It("should use the offerings of the preferred capacity type", func() {
+			Expect(offerings.WorstLaunchPrice(capacityTypes(v1.CapacityTypeReserved, v1.CapacityTypeSpot, v1.CapacityTypeOnDemand))).To(BeNumerically("==", 1))
+			Expect(offerings.WorstLaunchPrice(capacityTypes(v1.CapacityTypeSpot, v1.CapacityTypeOnDemand))).To(BeNumerically("==", 2))
+			Expect(offerings.WorstLaunchPrice(capacityTypes(v1.CapacityTypeOnDemand))).To(BeNumerically("==", 3))
+		})
+		It("should return the max price when no offering is compatible", func() {
+			Expect(offerings.WorstLaunchPrice(capacityTypes("unknown"))).To(BeNumerically("==", math.MaxFloat64))
+		})
+	})
+	Context("MaxLaunchPrice", func() {
+		It("should use the most expensive offering across all of the allowed capacity types", func() {
+			Expect(offerings.MaxLaunchPrice(capacityTypes(v1.CapacityTypeReserved, v1.CapacityTypeSpot, v1.CapacityTypeOnDemand))).To(BeNumerically("==", 3))
+			Expect(offerings.MaxLaunchPrice(capacityTypes(v1.CapacityTypeReserved, v1.CapacityTypeSpot))).To(BeNumerically("==", 2))
+		})
+		It("should only use the offerings of the allowed capacity types", func() {
+			Expect(offerings.MaxLaunchPrice(capacityTypes(v1.CapacityTypeReserved))).To(BeNumerically("==", 1))
+		})
+		It("should return the max price when no offering is compatible", func() {
+			Expect(offerings.MaxLaunchPrice(capacityTypes("unknown"))).To(BeNumerically("==", math.MaxFloat64))
+		})
+	})
+})
+
+var _ = Describe("Overhead", func() {
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	Context("KubeReservedStrategyForNodePool", func() {
+		It("should use the strategy from the NodePool's template", func() {
+			nodePool := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{
+				KubeReservedStrategy: v1.KubeReservedStrategyGKE,
+			}}}})
+			Expect(cloudprovider.KubeReservedStrategyForNodePool(nodePool, v1.KubeReservedStrategyFlat)).To(Equal(v1.KubeReservedStrategyGKE))
+		})
+		It("should fall back to the default strategy when the NodePool doesn't select one", func() {
+			Expect(cloudprovider.KubeReservedStrategyForNodePool(nil, v1.KubeReservedStrategyEKS)).To(Equal(v1.KubeReservedStrategyEKS))
+			Expect(cloudprovider.KubeReservedStrategyForNodePool(test.NodePool(), v1.KubeReservedStrategyEKS)).To(Equal(v1.KubeReservedStrategyEKS))
+		})
+	})
+	Context("KubeReserved", func() {
+		It("should reserve memory based on the maximum number of pods with the EKS strategy", func() {
+			kubeReserved := cloudprovider.KubeReserved(v1.KubeReservedStrategyEKS, capacity)
+			Expect(kubeReserved.Cpu().MilliValue()).To(BeNumerically("==", 80))
+			Expect(kubeReserved.Memory().Value()).To(BeNumerically("==", (11*110+255)*1024*1024))
+			Expect(kubeReserved.StorageEphemeral().String()).To(Equal("1Gi"))
+		})
+		It("should reserve memory based on the total memory with the GKE strategy", func() {
+			kubeReserved := cloudprovider.KubeReserved(v1.KubeReservedStrategyGKE, capacity)
+			Expect(kubeReserved.Cpu().MilliValue()).To(BeNumerically("==", 80))
+			// 25% of the first 4Gi, 20% of the next 4Gi and 10% of the next 8Gi
+			Expect(kubeReserved.Memory().Value()).To(BeNumerically("~", 2.6*(1<<30), 1))
+		})
+		It("should reserve 255Mi of memory on small instance types with the GKE strategy", func() {
+			kubeReserved := cloudprovider.KubeReserved(v1.KubeReservedStrategyGKE, corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			})
+			Expect(kubeReserved.Cpu().MilliValue()).To(BeNumerically("==", 60))
+			Expect(kubeReserved.Memory().String()).To(Equal("255Mi"))
+		})
+		It("should reserve a fixed amount of resources with the Flat strategy", func() {
+			kubeReserved := cloudprovider.KubeReserved(v1.KubeReservedStrategyFlat, capacity)
+			Expect(kubeReserved.Cpu().String()).To(Equal("100m"))
+			Expect(kubeReserved.Memory().String()).To(Equal("10Mi"))
+		})
+	})
+	Context("SystemReserved", func() {
+		It("should reserve resources for the OS system daemons with the EKS strategy", func() {
+			systemReserved := cloudprovider.SystemReserved(v1.KubeReservedStrategyEKS, capacity)
+			Expect(systemReserved.Cpu().String()).To(Equal("100m"))
+			Expect(systemReserved.Memory().String()).To(Equal("100Mi"))
+			Expect(systemReserved.StorageEphemeral().String()).To(Equal("1Gi"))
+		})
+		It("should not reserve resources with the GKE and Flat strategies", func() {
+			Expect(cloudprovider.SystemReserved(v1.KubeReservedStrategyGKE, capacity)).To(BeEmpty())
+			Expect(cloudprovider.SystemReserved(v1.KubeReservedStrategyFlat, capacity)).To(BeEmpty())
+		})
+		It("should be included in the instance type's overhead", func() {
+			overhead := cloudprovider.InstanceTypeOverhead{
+				KubeReserved:   cloudprovider.KubeReserved(v1.KubeReservedStrategyEKS, capacity),
+				SystemReserved: cloudprovider.SystemReserved(v1.KubeReservedStrategyEKS, capacity),
+			}
+			total := overhead.Total()
+			Expect(total.Cpu().MilliValue()).To(BeNumerically("==", 180))
+			Expect(total.StorageEphemeral().String()).To(Equal("2Gi"))
+		})
+	})
+})
@@ -38,6 +38,7 @@ import (
 var (
 	SpotRequirement     = scheduling.NewRequirements(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeSpot))
 	OnDemandRequirement = scheduling.NewRequirements(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeOnDemand))
+	ReservedRequirement = scheduling.NewRequirements(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeReserved))
 )
 
 type DriftReason string
@@ -66,7 +67,8 @@ type CloudProvider interface {
 	// GetInstanceTypes returns instance types supported by the cloudprovider.
 	// Availability of types or zone may vary by nodepool or over time.  Regardless of
 	// availability, the GetInstanceTypes method should always return all instance types,
-	// even those with no offerings available.
+	// even those with no offerings available. The returned instance types are shared across NodePools and scheduling
+	// simulations, so they must not be mutated in place once they're returned.
 	GetInstanceTypes(context.Context, *v1.NodePool) ([]*InstanceType, error)
 	// IsDrifted returns whether a NodeClaim has drifted from the provisioning requirements
 	// it is tied to.
@@ -86,6 +88,10 @@ type CloudProvider interface {
 type InstanceType struct {
 	// Name of the instance type, must correspond to corev1.LabelInstanceTypeStable
 	Name string
+	// Family of the instance type, e.g. m5 for m5.large. Pods can prefer instance types in a family with the
+	// karpenter.sh/instance-family-preference annotation. Cloud providers that don't group their instance types into
+	// families leave it empty.
+	Family string
 	// Requirements returns a flexible set of properties that may be selected
 	// for scheduling. Must be defined for every well known label, even if empty.
 	Requirements scheduling.Requirements
@@ -115,8 +121,14 @@ func (i *InstanceType) Allocatable() corev1.ResourceList {
 }
 
 func (its InstanceTypes) OrderByPrice(reqs scheduling.Requirements) InstanceTypes {
-	// Order instance types so that we get the cheapest instance types of the available offerings
+	// Order instance types so that we get the cheapest instance types of the available offerings. Instance types
+	// with available reserved offerings are always ordered first since that capacity has already been paid for.
 	sort.Slice(its, func(i, j int) bool {
+		iReserved := its[i].Offerings.Available().Compatible(reqs).HasCompatible(ReservedRequirement)
+		jReserved := its[j].Offerings.Available().Compatible(reqs).HasCompatible(ReservedRequirement)
+		if iReserved != jReserved {
+			return iReserved
+		}
 		iPrice := math.MaxFloat64
 		jPrice := math.MaxFloat64
 		if ofs := its[i].Offerings.Available().Compatible(reqs); len(ofs) > 0 {
@@ -211,10 +223,24 @@ func (its InstanceTypes) SatisfiesMinValues(requirements scheduling.Requirements
 	return len(its), nil
 }
 
-// Truncate truncates the InstanceTypes based on the passed-in requirements
+// OrderByFamilyPreference moves the instance types in the preferred families ahead of the others, keeping the order of
+// the instance types within each group
+func (its InstanceTypes) OrderByFamilyPreference(families []string) InstanceTypes {
+	if len(families) == 0 {
+		return its
+	}
+	preferred := sets.New(families...)
+	sort.SliceStable(its, func(i, j int) bool {
+		return preferred.Has(its[i].Family) && !preferred.Has(its[j].Family)
+	})
+	return its
+}
+
+// Truncate truncates the InstanceTypes based on the passed-in requirements, keeping the instance types in the preferred
+// families ahead of the others
 // It returns an error if it isn't possible to truncate the instance types on maxItems without violating minValues
-func (its InstanceTypes) Truncate(requirements scheduling.Requirements, maxItems int) (InstanceTypes, error) {
-	truncatedInstanceTypes := lo.Slice(its.OrderByPrice(requirements), 0, maxItems)
+func (its InstanceTypes) Truncate(requirements scheduling.Requirements, maxItems int, preferredFamilies ...string) (InstanceTypes, error) {
+	truncatedInstanceTypes := lo.Slice(its.OrderByPrice(requirements).OrderByFamilyPreference(preferredFamilies), 0, maxItems)
 	// Only check for a validity of NodeClaim if its requirement has minValues in it.
 	if requirements.HasMinValues() {
 		if _, err := truncatedInstanceTypes.SatisfiesMinValues(requirements); err != nil {
@@ -290,9 +316,17 @@ func (ofs Offerings) MostExpensive() Offering {
 }
 
 // WorstLaunchPrice gets the worst-case launch price from the offerings that are offered
-// on an instance type. If the instance type has a spot offering available, then it uses the spot offering
-// to get the launch price; else, it uses the on-demand launch price
+// on an instance type. If the instance type has a reserved offering available, then it uses the reserved offering
+// to get the launch price; else if it has a spot offering available, then it uses the spot offering to get the
+// launch price; else, it uses the on-demand launch price
 func (ofs Offerings) WorstLaunchPrice(reqs scheduling.Requirements) float64 {
+	// Reserved capacity is launched ahead of spot and on-demand capacity when it's available
+	if reqs.Get(v1.CapacityTypeLabelKey).Has(v1.CapacityTypeReserved) {
+		reservedOfferings := ofs.Compatible(reqs).Compatible(ReservedRequirement)
+		if len(reservedOfferings) > 0 {
+			return reservedOfferings.MostExpensive().Price
+		}
+	}
 	// We prefer to launch spot offerings, so we will get the worst price based on the node requirements
 	if reqs.Get(v1.CapacityTypeLabelKey).Has(v1.CapacityTypeSpot) {
 		spotOfferings := ofs.Compatible(reqs).Compatible(SpotRequirement)
@@ -309,6 +343,20 @@ func (ofs Offerings) WorstLaunchPrice(reqs scheduling.Requirements) float64 {
 	return math.MaxFloat64
 }
 
+// MaxLaunchPrice gets the most expensive launch price across all of the capacity types that the requirements allow.
+// Unlike WorstLaunchPrice, this doesn't assume that the preferred capacity type is launched, e.g. since reserved
+// capacity can run out before the launch.
+func (ofs Offerings) MaxLaunchPrice(reqs scheduling.Requirements) float64 {
+	capacityTypes := reqs.Get(v1.CapacityTypeLabelKey)
+	offerings := lo.Filter(ofs.Compatible(reqs), func(of Offering, _ int) bool {
+		return capacityTypes.Has(of.Requirements.Get(v1.CapacityTypeLabelKey).Any())
+	})
+	if len(offerings) == 0 {
+		return math.MaxFloat64
+	}
+	return offerings.MostExpensive().Price
+}
+
 // NodeClaimNotFoundError is an error type returned by CloudProviders when the reason for failure is NotFound
 type NodeClaimNotFoundError struct {
 	error
@@ -339,14 +387,44 @@ func IgnoreNodeClaimNotFoundError(err error) error {
 	return err
 }
 
+// DeletionInProgressError is an error type returned by CloudProviders from Delete when the instance deletion has been
+// accepted but completes asynchronously. Rather than blocking until the instance is gone, CloudProviders can return
+// this error with an identifier for the ongoing operation, which is tracked on the NodeClaim while Get is polled for completion
+type DeletionInProgressError struct {
+	error
+	OperationID string
+}
+
+func NewDeletionInProgressError(err error, operationID string) *DeletionInProgressError {
+	return &DeletionInProgressError{
+		error:       err,
+		OperationID: operationID,
+	}
+}
+
+func (e *DeletionInProgressError) Error() string {
+	return fmt.Sprintf("deletion in progress (operation %s), %s", e.OperationID, e.error)
+}
+
+func IsDeletionInProgressError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dipErr *DeletionInProgressError
+	return errors.As(err, &dipErr)
+}
+
 // InsufficientCapacityError is an error type returned by CloudProviders when a launch fails due to a lack of capacity from NodeClaim requirements
 type InsufficientCapacityError struct {
 	error
+	// Offerings are the offerings that were out of capacity, if the CloudProvider knows which offerings were tried
+	Offerings []OfferingID
 }
 
-func NewInsufficientCapacityError(err error) *InsufficientCapacityError {
+func NewInsufficientCapacityError(err error, offerings ...OfferingID) *InsufficientCapacityError {
 	return &InsufficientCapacityError{
-		error: err,
+		error:     err,
+		Offerings: offerings,
 	}
 }
 
@@ -362,6 +440,22 @@ func IsInsufficientCapacityError(err error) bool {
 	return errors.As(err, &icErr)
 }
 
+// InsufficientCapacityOfferings returns the offerings that were out of capacity according to an InsufficientCapacityError
+func InsufficientCapacityOfferings(err error) []OfferingID {
+	var icErr *InsufficientCapacityError
+	if !errors.As(err, &icErr) {
+		return nil
+	}
+	return icErr.Offerings
+}
+
+// OfferingID identifies an offering of an instance type by its zone and capacity type
+type OfferingID struct {
+	InstanceType string
+	Zone         string
+	CapacityType string
+}
+
 // NodeClassNotReadyError is an error type returned by CloudProviders when a NodeClass that is used by the launch process doesn't have all its resolved fields
 type NodeClassNotReadyError struct {
 	error
@@ -397,3 +491,51 @@ func NewCreateError(err error, message string) *CreateError {
 		ConditionMessage: message,
 	}
 }
+
+// ProviderErrorReason is the provider-agnostic classification of a CloudProvider error. It's used as the reason on the
+// NodeClaim status condition and as the error label on the cloudprovider metrics so that failures can be alerted on
+// without parsing provider-specific messages.
+type ProviderErrorReason string
+
+const (
+	// ProviderErrorReasonQuotaExceeded is returned when an account or project limit prevents the operation
+	ProviderErrorReasonQuotaExceeded ProviderErrorReason = "QuotaExceeded"
+	// ProviderErrorReasonUnauthorized is returned when the controller's credentials aren't permitted to perform the operation
+	ProviderErrorReasonUnauthorized ProviderErrorReason = "Unauthorized"
+	// ProviderErrorReasonInvalidSubnet is returned when the network the instance is launched into doesn't exist or can't be used
+	ProviderErrorReasonInvalidSubnet ProviderErrorReason = "InvalidSubnet"
+	// ProviderErrorReasonInvalidConfiguration is returned for any other invalid NodeClass or request configuration
+	ProviderErrorReasonInvalidConfiguration ProviderErrorReason = "InvalidConfiguration"
+)
+
+// ProviderError is an error type returned by CloudProviders to describe a failure with a well-known reason along with
+// the provider-specific error code, e.g. "VcpuLimitExceeded"
+type ProviderError struct {
+	error
+	Reason ProviderErrorReason
+	Code   string
+}
+
+func NewProviderError(err error, reason ProviderErrorReason, code string) *ProviderError {
+	return &ProviderError{
+		error:  err,
+		Reason: reason,
+		Code:   code,
+	}
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.error
+}
+
+// ProviderErrorFor returns the ProviderError wrapped by err, or nil if the CloudProvider didn't classify the error
+func ProviderErrorFor(err error) *ProviderError {
+	if err == nil {
+		return nil
+	}
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr
+	}
+	return nil
+}
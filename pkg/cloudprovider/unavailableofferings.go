@@ -0,0 +1,183 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+)
+
+// UnavailableOfferingsTTL is the duration that an offering is considered unavailable after a launch failed because the
+// cloud provider was out of capacity
+const UnavailableOfferingsTTL = 3 * time.Minute
+
+// UnavailableOfferings caches offerings that failed to launch because the cloud provider was out of capacity. Unlike
+// the insufficient capacity caches that cloud providers keep in memory, the offerings are persisted in the singleton
+// UnavailableOfferings resource so that Karpenter doesn't retry them after it restarts or another replica becomes the leader.
+type UnavailableOfferings struct {
+	kubeClient client.Client
+	clock      clock.Clock
+
+	mu        sync.RWMutex
+	offerings map[OfferingID]time.Time
+	// syncMu serializes writes to the persisted offerings so that concurrent syncs don't conflict with each other
+	syncMu sync.Mutex
+	// pending is armed when offerings are marked unavailable, so that they're persisted in the background
+	pending chan event.GenericEvent
+}
+
+func NewUnavailableOfferings(kubeClient client.Client, clk clock.Clock) *UnavailableOfferings {
+	return &UnavailableOfferings{
+		kubeClient: kubeClient,
+		clock:      clk,
+		offerings:  map[OfferingID]time.Time{},
+		pending:    make(chan event.GenericEvent, 1),
+	}
+}
+
+// MarkUnavailable marks the offerings as unavailable for the UnavailableOfferingsTTL. The offerings are persisted in
+// the background by the next Sync, so that launches aren't blocked on writing them. Offerings that are marked
+// unavailable while a Sync is pending are persisted together.
+func (u *UnavailableOfferings) MarkUnavailable(offerings ...OfferingID) {
+	// The expiration is persisted with a precision of seconds, so it's truncated to match the persisted offerings
+	expiration := u.clock.Now().Add(UnavailableOfferingsTTL).Truncate(time.Second)
+	u.mu.Lock()
+	for _, o := range offerings {
+		u.offerings[o] = expiration
+	}
+	u.mu.Unlock()
+	// The sync is idempotently requested. This statement never blocks
+	select {
+	case u.pending <- event.GenericEvent{}:
+	default:
+	}
+}
+
+// Pending receives an event when offerings were marked unavailable that haven't been persisted yet
+func (u *UnavailableOfferings) Pending() <-chan event.GenericEvent {
+	return u.pending
+}
+
+// IsUnavailable returns true if the offering with the given instance type, zone and capacity type is unavailable
+func (u *UnavailableOfferings) IsUnavailable(instanceType, zone, capacityType string) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	expiration, ok := u.offerings[OfferingID{InstanceType: instanceType, Zone: zone, CapacityType: capacityType}]
+	return ok && u.clock.Now().Before(expiration)
+}
+
+// Apply returns the passed instance types with any unavailable offerings marked as unavailable. Instance types
+// that have unavailable offerings are copied so that the instance types cached by the cloud provider aren't mutated.
+func (u *UnavailableOfferings) Apply(instanceTypes []*InstanceType) []*InstanceType {
+	u.mu.RLock()
+	empty := len(u.offerings) == 0
+	u.mu.RUnlock()
+	if empty {
+		return instanceTypes
+	}
+	return withUnavailableOfferings(instanceTypes, func(instanceType string, o Offering) bool {
+		return u.IsUnavailable(instanceType, o.Requirements.Get(corev1.LabelTopologyZone).Any(), o.Requirements.Get(v1.CapacityTypeLabelKey).Any())
+	})
+}
+
+// Flush removes all offerings from the cache. Offerings that are persisted are loaded again on the next Sync.
+func (u *UnavailableOfferings) Flush() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.offerings = map[OfferingID]time.Time{}
+}
+
+// Sync merges the persisted offerings with the cached offerings, drops the offerings that have expired from both and
+// persists the result. Offerings that were persisted by another replica are picked up by the cache through Sync.
+func (u *UnavailableOfferings) Sync(ctx context.Context) error {
+	u.syncMu.Lock()
+	defer u.syncMu.Unlock()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		persisted := &v1alpha1.UnavailableOfferings{}
+		if err := u.kubeClient.Get(ctx, client.ObjectKey{Name: v1alpha1.UnavailableOfferingsName}, persisted); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			persisted = &v1alpha1.UnavailableOfferings{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.UnavailableOfferingsName}}
+			if err = u.kubeClient.Create(ctx, persisted); err != nil {
+				// Another replica created the offerings since we read them, so they're read again and updated
+				if errors.IsAlreadyExists(err) {
+					return errors.NewConflict(schema.GroupResource{Group: apis.Group, Resource: "unavailableofferings"}, persisted.Name, err)
+				}
+				return err
+			}
+		}
+		stored := persisted.DeepCopy()
+		persisted.Status.Offerings = u.merge(persisted.Status.Offerings)
+		if equality.Semantic.DeepEqual(stored, persisted) {
+			return nil
+		}
+		return u.kubeClient.Status().Patch(ctx, persisted, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+// merge adds the persisted offerings to the cache, removes the expired offerings from the cache and returns the
+// cached offerings in the order that they're persisted in
+func (u *UnavailableOfferings) merge(persisted []v1alpha1.UnavailableOffering) []v1alpha1.UnavailableOffering {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, o := range persisted {
+		id := OfferingID{InstanceType: o.InstanceType, Zone: o.Zone, CapacityType: o.CapacityType}
+		if o.ExpirationTime.Time.After(u.offerings[id]) {
+			u.offerings[id] = o.ExpirationTime.Time
+		}
+	}
+	var offerings []v1alpha1.UnavailableOffering
+	for id, expiration := range u.offerings {
+		if !u.clock.Now().Before(expiration) {
+			delete(u.offerings, id)
+			continue
+		}
+		offerings = append(offerings, v1alpha1.UnavailableOffering{
+			InstanceType:   id.InstanceType,
+			Zone:           id.Zone,
+			CapacityType:   id.CapacityType,
+			ExpirationTime: metav1.NewTime(expiration),
+		})
+	}
+	sort.Slice(offerings, func(i, j int) bool {
+		if offerings[i].InstanceType != offerings[j].InstanceType {
+			return offerings[i].InstanceType < offerings[j].InstanceType
+		}
+		if offerings[i].Zone != offerings[j].Zone {
+			return offerings[i].Zone < offerings[j].Zone
+		}
+		return offerings[i].CapacityType < offerings[j].CapacityType
+	})
+	return offerings
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// UnhealthyOfferingsTTL is the duration that an offering is considered unhealthy after it launched capacity that
+// registered with the cluster but never went Ready
+const UnhealthyOfferingsTTL = 30 * time.Minute
+
+// UnhealthyOfferings caches offerings that produced nodes which failed to boot. It's analogous to the
+// insufficient capacity caches that cloud providers keep, but it's populated by Karpenter after observing
+// the node rather than by the cloud provider when the launch fails.
+type UnhealthyOfferings struct {
+	cache *cache.Cache
+}
+
+func NewUnhealthyOfferings() *UnhealthyOfferings {
+	return &UnhealthyOfferings{
+		cache: cache.New(UnhealthyOfferingsTTL, time.Minute),
+	}
+}
+
+// MarkUnhealthy marks the offering with the given instance type, zone and capacity type as unhealthy
+func (u *UnhealthyOfferings) MarkUnhealthy(instanceType, zone, capacityType string) {
+	u.cache.SetDefault(u.key(instanceType, zone, capacityType), struct{}{})
+}
+
+// IsUnhealthy returns true if the offering with the given instance type, zone and capacity type is unhealthy
+func (u *UnhealthyOfferings) IsUnhealthy(instanceType, zone, capacityType string) bool {
+	_, found := u.cache.Get(u.key(instanceType, zone, capacityType))
+	return found
+}
+
+// Apply returns the passed instance types with any unhealthy offerings marked as unavailable. Instance types
+// that have unhealthy offerings are copied so that the instance types cached by the cloud provider aren't mutated.
+func (u *UnhealthyOfferings) Apply(instanceTypes []*InstanceType) []*InstanceType {
+	if u.cache.ItemCount() == 0 {
+		return instanceTypes
+	}
+	return withUnavailableOfferings(instanceTypes, u.isOfferingUnhealthy)
+}
+
+// Flush removes all offerings from the cache
+func (u *UnhealthyOfferings) Flush() {
+	u.cache.Flush()
+}
+
+func (u *UnhealthyOfferings) isOfferingUnhealthy(instanceType string, o Offering) bool {
+	return u.IsUnhealthy(instanceType, o.Requirements.Get(corev1.LabelTopologyZone).Any(), o.Requirements.Get(v1.CapacityTypeLabelKey).Any())
+}
+
+func (u *UnhealthyOfferings) key(instanceType, zone, capacityType string) string {
+	return fmt.Sprintf("%s:%s:%s", instanceType, zone, capacityType)
+}
+
+// withUnavailableOfferings returns the passed instance types with the offerings that match isUnavailable marked as
+// unavailable. Only the instance types that have such offerings are copied.
+func withUnavailableOfferings(instanceTypes []*InstanceType, isUnavailable func(instanceType string, o Offering) bool) []*InstanceType {
+	return lo.Map(instanceTypes, func(it *InstanceType, _ int) *InstanceType {
+		if !lo.ContainsBy(it.Offerings, func(o Offering) bool { return o.Available && isUnavailable(it.Name, o) }) {
+			return it
+		}
+		return &InstanceType{
+			Name:         it.Name,
+			Family:       it.Family,
+			Requirements: it.Requirements,
+			Offerings: lo.Map(it.Offerings, func(o Offering, _ int) Offering {
+				o.Available = o.Available && !isUnavailable(it.Name, o)
+				return o
+			}),
+			Capacity: it.Capacity,
+			Overhead: it.Overhead,
+		}
+	})
+}
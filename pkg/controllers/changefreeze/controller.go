@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changefreeze
+
+import (
+	"context"
+	"strings"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// FreezeKey is the key of the change freeze ConfigMap that pauses voluntary disruption while it's set to "true"
+const FreezeKey = "freeze"
+
+// Controller watches the change freeze ConfigMap and pauses voluntary disruption cluster-wide while the freeze is
+// enabled. Removing the ConfigMap lifts the freeze.
+type Controller struct {
+	kubeClient client.Client
+	cluster    *state.Cluster
+	recorder   events.Recorder
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, cluster *state.Cluster, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient: kubeClient,
+		cluster:    cluster,
+		recorder:   recorder,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "changefreeze")
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("ConfigMap", klog.KRef(req.Namespace, req.Name)))
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.kubeClient.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name}}
+	}
+	frozen := configMap.DeletionTimestamp.IsZero() && strings.EqualFold(configMap.Data[FreezeKey], "true")
+	ChangeFreezeActive.Set(lo.Ternary(frozen, 1.0, 0.0), nil)
+	if !c.cluster.SetChangeFrozen(frozen) {
+		return reconcile.Result{}, nil
+	}
+	if frozen {
+		log.FromContext(ctx).Info("change freeze enabled, pausing voluntary disruption")
+		c.recorder.Publish(ChangeFreezeEnabled(configMap))
+	} else {
+		log.FromContext(ctx).Info("change freeze lifted, resuming voluntary disruption")
+		c.recorder.Publish(ChangeFreezeLifted(configMap))
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	key := ConfigMapKey(ctx)
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("changefreeze").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return client.ObjectKeyFromObject(o) == key
+		}))).
+		Complete(c)
+}
+
+// ConfigMapKey returns the namespace and name of the change freeze ConfigMap
+func ConfigMapKey(ctx context.Context) types.NamespacedName {
+	return options.FromContext(ctx).ChangeFreezeConfigMapKey()
+}
@@ -0,0 +1,43 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changefreeze
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func ChangeFreezeEnabled(configMap *corev1.ConfigMap) events.Event {
+	return events.Event{
+		InvolvedObject: configMap,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.ChangeFreezeEnabled,
+		Message:        "Change freeze enabled, voluntary disruption is paused",
+		DedupeValues:   []string{string(configMap.UID), "enabled"},
+	}
+}
+
+func ChangeFreezeLifted(configMap *corev1.ConfigMap) events.Event {
+	return events.Event{
+		InvolvedObject: configMap,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.ChangeFreezeLifted,
+		Message:        "Change freeze lifted, voluntary disruption resumed",
+		DedupeValues:   []string{string(configMap.UID), "lifted"},
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changefreeze_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/changefreeze"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller *changefreeze.Controller
+	ctx        context.Context
+	env        *test.Environment
+	cluster    *state.Cluster
+	recorder   *test.EventRecorder
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ChangeFreeze")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ChangeFreezeConfigMap: lo.ToPtr("default/change-freeze")}))
+	cluster = state.NewCluster(clock.NewFakeClock(time.Now()), env.Client, fake.NewCloudProvider())
+	recorder = test.NewEventRecorder()
+	controller = changefreeze.NewController(env.Client, cluster, recorder)
+})
+
+var _ = BeforeEach(func() {
+	cluster.Reset()
+	recorder.Reset()
+})
+
+var _ = AfterEach(func() {
+	Expect(env.Client.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default"))).To(Succeed())
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("ChangeFreeze", func() {
+	var configMap *corev1.ConfigMap
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "change-freeze"},
+			Data:       map[string]string{changefreeze.FreezeKey: "true"},
+		}
+	})
+	It("should enable the change freeze when the freeze key is true", func() {
+		ExpectApplied(ctx, env.Client, configMap)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		Expect(cluster.ChangeFrozen()).To(BeTrue())
+		Expect(recorder.Calls("ChangeFreezeEnabled")).To(Equal(1))
+		ExpectMetricGaugeValue(changefreeze.ChangeFreezeActive, 1, map[string]string{})
+	})
+	It("should lift the change freeze when the freeze key is false", func() {
+		ExpectApplied(ctx, env.Client, configMap)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		Expect(cluster.ChangeFrozen()).To(BeTrue())
+
+		configMap.Data[changefreeze.FreezeKey] = "false"
+		ExpectApplied(ctx, env.Client, configMap)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		Expect(cluster.ChangeFrozen()).To(BeFalse())
+		Expect(recorder.Calls("ChangeFreezeLifted")).To(Equal(1))
+		ExpectMetricGaugeValue(changefreeze.ChangeFreezeActive, 0, map[string]string{})
+	})
+	It("should lift the change freeze when the ConfigMap is deleted", func() {
+		ExpectApplied(ctx, env.Client, configMap)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		Expect(cluster.ChangeFrozen()).To(BeTrue())
+
+		ExpectDeleted(ctx, env.Client, configMap)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		Expect(cluster.ChangeFrozen()).To(BeFalse())
+		Expect(recorder.Calls("ChangeFreezeLifted")).To(Equal(1))
+	})
+	It("should only publish an event when the freeze is toggled", func() {
+		ExpectApplied(ctx, env.Client, configMap)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(configMap))
+		Expect(recorder.Calls("ChangeFreezeEnabled")).To(Equal(1))
+	})
+})
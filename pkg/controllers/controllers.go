@@ -31,14 +31,21 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/changefreeze"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption/orchestration"
+	"sigs.k8s.io/karpenter/pkg/controllers/labelnormalization"
 	metricsnode "sigs.k8s.io/karpenter/pkg/controllers/metrics/node"
 	metricsnodepool "sigs.k8s.io/karpenter/pkg/controllers/metrics/nodepool"
 	metricspod "sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
+	nodeadoption "sigs.k8s.io/karpenter/pkg/controllers/node/adoption"
+	nodebinding "sigs.k8s.io/karpenter/pkg/controllers/node/binding"
+	nodeexpiration "sigs.k8s.io/karpenter/pkg/controllers/node/expiration"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
 	nodehydration "sigs.k8s.io/karpenter/pkg/controllers/node/hydration"
+	nodestartuptaint "sigs.k8s.io/karpenter/pkg/controllers/node/startuptaint"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/placement"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	nodeclaimconsistency "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/consistency"
 	nodeclaimdisruption "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/disruption"
@@ -46,16 +53,28 @@ import (
 	nodeclaimgarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/garbagecollection"
 	nodeclaimhydration "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/hydration"
 	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	nodeclaimorphanreport "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/orphanreport"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/podevents"
+	nodeclaimpropagation "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/propagation"
+	nodepoolcanary "sigs.k8s.io/karpenter/pkg/controllers/nodepool/canary"
 	nodepoolcounter "sigs.k8s.io/karpenter/pkg/controllers/nodepool/counter"
 	nodepoolhash "sigs.k8s.io/karpenter/pkg/controllers/nodepool/hash"
+	nodepoollaunchslo "sigs.k8s.io/karpenter/pkg/controllers/nodepool/launchslo"
+	nodepoolpreflight "sigs.k8s.io/karpenter/pkg/controllers/nodepool/preflight"
 	nodepoolreadiness "sigs.k8s.io/karpenter/pkg/controllers/nodepool/readiness"
+	nodepoolstatus "sigs.k8s.io/karpenter/pkg/controllers/nodepool/status"
+	nodepooltermination "sigs.k8s.io/karpenter/pkg/controllers/nodepool/termination"
 	nodepoolvalidation "sigs.k8s.io/karpenter/pkg/controllers/nodepool/validation"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/controllers/unavailableofferings"
 	"sigs.k8s.io/karpenter/pkg/events"
+	operatorhealth "sigs.k8s.io/karpenter/pkg/operator/health"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
+	"sigs.k8s.io/karpenter/pkg/webhooks/podadvisory"
 )
 
 func NewControllers(
@@ -66,37 +85,60 @@ func NewControllers(
 	recorder events.Recorder,
 	cloudProvider cloudprovider.CloudProvider,
 	cluster *state.Cluster,
+	initializationChecks []nodeclaimlifecycle.InitializationCheck,
+	nodeClaimHooks ...provisioning.NodeClaimHook,
 ) []controller.Controller {
-	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clock)
-	evictionQueue := terminator.NewQueue(kubeClient, recorder)
+	unhealthyOfferings := cloudprovider.NewUnhealthyOfferings()
+	unavailableOfferings := cloudprovider.NewUnavailableOfferings(kubeClient, clock)
+	labelAliases := scheduling.NewLabelAliases()
+	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clock, unhealthyOfferings, unavailableOfferings, labelAliases)
+	p.RegisterNodeClaimHooks(nodeClaimHooks...)
+	lo.Must0(mgr.AddMetricsServerExtraHandler("/debug/scheduling/explanations", p.Explanations()))
+	evictionQueue := terminator.NewQueue(ctx, kubeClient, recorder, clock)
+	nodeTerminator := terminator.NewTerminator(clock, kubeClient, evictionQueue, recorder)
+	placements := placement.NewTracker()
+	providerIDs := providerid.NewMapping()
+	lo.Must0(providerIDs.Register(ctx, mgr))
 	disruptionQueue := orchestration.NewQueue(kubeClient, recorder, cluster, clock, p)
+	disruptionController := disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue, evictionQueue)
+	lo.Must0(operatorhealth.Register(mgr, p.Heartbeat(), disruptionController.Heartbeat()))
 
 	controllers := []controller.Controller{
 		p, evictionQueue, disruptionQueue,
-		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue),
+		disruptionController,
 		provisioning.NewPodController(kubeClient, p, cluster),
 		provisioning.NewNodeController(kubeClient, p),
 		nodepoolhash.NewController(kubeClient, cloudProvider),
-		expiration.NewController(clock, kubeClient, cloudProvider),
+		expiration.NewController(clock, kubeClient, cloudProvider, cluster),
 		informer.NewDaemonSetController(kubeClient, cluster),
 		informer.NewNodeController(kubeClient, cluster),
 		informer.NewPodController(kubeClient, cluster),
 		informer.NewNodePoolController(kubeClient, cloudProvider, cluster),
 		informer.NewNodeClaimController(kubeClient, cloudProvider, cluster),
-		termination.NewController(clock, kubeClient, cloudProvider, terminator.NewTerminator(clock, kubeClient, evictionQueue, recorder), recorder),
-		metricspod.NewController(kubeClient, cluster),
+		termination.NewController(clock, kubeClient, cloudProvider, nodeTerminator, placements, providerIDs, recorder),
+		placement.NewController(placements, recorder),
+		metricspod.NewController(clock, kubeClient, cluster),
 		metricsnodepool.NewController(kubeClient, cloudProvider),
 		metricsnode.NewController(cluster),
+		nodepoolpreflight.NewController(kubeClient, cloudProvider, cluster),
 		nodepoolreadiness.NewController(kubeClient, cloudProvider),
+		nodepoollaunchslo.NewController(clock, kubeClient, cloudProvider),
+		labelnormalization.NewController(kubeClient, labelAliases),
 		nodepoolcounter.NewController(kubeClient, cloudProvider, cluster),
+		nodepoolstatus.NewController(kubeClient, cloudProvider),
+		nodepooltermination.NewController(clock, kubeClient, cloudProvider, cluster),
 		nodepoolvalidation.NewController(kubeClient, cloudProvider),
 		podevents.NewController(clock, kubeClient, cloudProvider),
 		nodeclaimconsistency.NewController(clock, kubeClient, cloudProvider, recorder),
-		nodeclaimlifecycle.NewController(clock, kubeClient, cloudProvider, recorder),
+		nodeclaimlifecycle.NewController(clock, kubeClient, cloudProvider, recorder, cluster, p, unhealthyOfferings, unavailableOfferings, providerIDs, initializationChecks...),
+		unavailableofferings.NewController(unavailableOfferings),
 		nodeclaimgarbagecollection.NewController(clock, kubeClient, cloudProvider),
-		nodeclaimdisruption.NewController(clock, kubeClient, cloudProvider),
+		nodeclaimorphanreport.NewController(clock, kubeClient, cloudProvider),
+		nodeclaimdisruption.NewController(clock, kubeClient, cloudProvider, cluster),
 		nodeclaimhydration.NewController(kubeClient, cloudProvider),
+		nodeclaimpropagation.NewController(kubeClient, cloudProvider),
 		nodehydration.NewController(kubeClient, cloudProvider),
+		nodestartuptaint.NewController(clock, kubeClient, cloudProvider, recorder),
 		status.NewController[*v1.NodeClaim](kubeClient, mgr.GetEventRecorderFor("karpenter"), status.EmitDeprecatedMetrics, status.WithLabels(append(lo.Map(cloudProvider.GetSupportedNodeClasses(), func(obj status.Object, _ int) string { return v1.NodeClassLabelKey(object.GVK(obj).GroupKind()) }), v1.NodePoolLabelKey)...)),
 		status.NewController[*v1.NodePool](kubeClient, mgr.GetEventRecorderFor("karpenter"), status.EmitDeprecatedMetrics),
 		status.NewGenericObjectController[*corev1.Node](kubeClient, mgr.GetEventRecorderFor("karpenter"), status.WithLabels(append(lo.Map(cloudProvider.GetSupportedNodeClasses(), func(obj status.Object, _ int) string { return v1.NodeClassLabelKey(object.GVK(obj).GroupKind()) }), v1.NodePoolLabelKey, v1.NodeInitializedLabelKey)...)),
@@ -106,6 +148,24 @@ func NewControllers(
 	if len(cloudProvider.RepairPolicies()) != 0 && options.FromContext(ctx).FeatureGates.NodeRepair {
 		controllers = append(controllers, health.NewController(kubeClient, cloudProvider, clock, recorder))
 	}
+	if options.FromContext(ctx).ChangeFreezeConfigMap != "" {
+		controllers = append(controllers, changefreeze.NewController(kubeClient, cluster, recorder))
+	}
+	if options.FromContext(ctx).FeatureGates.NodeAdoption {
+		controllers = append(controllers, nodeadoption.NewController(kubeClient, cloudProvider, providerIDs, recorder))
+	}
+	if options.FromContext(ctx).FeatureGates.PodBinding {
+		controllers = append(controllers, nodebinding.NewController(kubeClient, cloudProvider, cluster, recorder))
+	}
+	if options.FromContext(ctx).UnmanagedNodeExpiration {
+		controllers = append(controllers, nodeexpiration.NewController(clock, kubeClient, cloudProvider, nodeTerminator, recorder, cluster))
+	}
+	if options.FromContext(ctx).CanaryInterval != 0 {
+		controllers = append(controllers, nodepoolcanary.NewController(clock, kubeClient, cloudProvider))
+	}
+	if options.FromContext(ctx).PodAdvisoryWebhookMode != "Disabled" {
+		controllers = append(controllers, podadvisory.NewWebhook(kubeClient, cloudProvider))
+	}
 
 	return controllers
 }
@@ -20,11 +20,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -59,6 +63,7 @@ type consolidation struct {
 	provisioner            *provisioning.Provisioner
 	cloudProvider          cloudprovider.CloudProvider
 	recorder               events.Recorder
+	rebalances             *rebalances
 	lastConsolidationState time.Time
 }
 
@@ -72,6 +77,7 @@ func MakeConsolidation(clock clock.Clock, cluster *state.Cluster, kubeClient cli
 		provisioner:   provisioner,
 		cloudProvider: cloudProvider,
 		recorder:      recorder,
+		rebalances:    newRebalances(clock),
 	}
 }
 
@@ -108,6 +114,10 @@ func (c *consolidation) ShouldDisrupt(_ context.Context, cn *Candidate) bool {
 		c.recorder.Publish(disruptionevents.Unconsolidatable(cn.Node, cn.NodeClaim, fmt.Sprintf("NodePool %q has consolidation disabled", cn.nodePool.Name))...)
 		return false
 	}
+	if withinMinNodeLifetime(c.clock, cn) {
+		c.recorder.Publish(disruptionevents.Unconsolidatable(cn.Node, cn.NodeClaim, fmt.Sprintf("Node is within the minNodeLifetime of NodePool %q", cn.nodePool.Name))...)
+		return false
+	}
 	// If we don't have the "WhenEmptyOrUnderutilized" policy set, we should not do any of the consolidation methods, but
 	// we should also not fire an event here to users since this can be confusing when the field on the NodePool
 	// is named "consolidationPolicy"
@@ -119,14 +129,83 @@ func (c *consolidation) ShouldDisrupt(_ context.Context, cn *Candidate) bool {
 	return cn.NodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsTrue()
 }
 
-// sortCandidates sorts candidates by disruption cost (where the lowest disruption cost is first) and returns the result
-func (c *consolidation) sortCandidates(candidates []*Candidate) []*Candidate {
-	sort.Slice(candidates, func(i int, j int) bool {
-		return candidates[i].disruptionCost < candidates[j].disruptionCost
+// disruptionCostEpsilon is the largest difference between two disruption costs for them to be considered equal
+const disruptionCostEpsilon = 1e-6
+
+// sortCandidates sorts candidates by disruption cost (where the lowest disruption cost is first) and returns the result.
+// If zonal rebalancing is enabled, candidates with the same disruption cost are ordered so that candidates in the zones
+// with the most nodes of their NodePool are disrupted first.
+func (c *consolidation) sortCandidates(ctx context.Context, candidates []*Candidate) []*Candidate {
+	if !options.FromContext(ctx).FeatureGates.ZonalRebalance {
+		sort.Slice(candidates, func(i int, j int) bool {
+			return candidates[i].disruptionCost < candidates[j].disruptionCost
+		})
+		return candidates
+	}
+	zoneCounts := map[string]map[string]int{}
+	for _, cn := range candidates {
+		if _, ok := zoneCounts[cn.nodePool.Name]; !ok {
+			zoneCounts[cn.nodePool.Name] = c.zoneNodeCounts(cn.nodePool.Name)
+		}
+	}
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		// Disruption costs are sums of floats, so costs that only differ by rounding error are considered equal
+		if math.Abs(candidates[i].disruptionCost-candidates[j].disruptionCost) > disruptionCostEpsilon {
+			return candidates[i].disruptionCost < candidates[j].disruptionCost
+		}
+		return zoneCounts[candidates[i].nodePool.Name][candidates[i].zone] > zoneCounts[candidates[j].nodePool.Name][candidates[j].zone]
 	})
 	return candidates
 }
 
+// zoneNodeCounts returns the number of nodes of the NodePool in each zone, ignoring nodes that are being disrupted
+func (c *consolidation) zoneNodeCounts(nodePoolName string) map[string]int {
+	counts := map[string]int{}
+	for _, n := range c.cluster.Nodes() {
+		if n.Labels()[v1.NodePoolLabelKey] != nodePoolName || n.MarkedForDeletion() {
+			continue
+		}
+		if zone, ok := n.Labels()[corev1.LabelTopologyZone]; ok {
+			counts[zone]++
+		}
+	}
+	return counts
+}
+
+// preferLeastPopulatedZones restricts the replacement to the zones with the fewest nodes of its NodePool when an instance
+// type option can still launch there, so that replacing nodes moves capacity towards under-represented zones rather than
+// away from them. The replacement is left unchanged if restricting it would violate its minValues requirements.
+func (c *consolidation) preferLeastPopulatedZones(replacement *pscheduling.NodeClaim) {
+	counts := c.zoneNodeCounts(replacement.NodePoolName)
+	candidateZones := sets.New[string]()
+	for _, it := range replacement.InstanceTypeOptions {
+		for _, o := range it.Offerings.Available().Compatible(replacement.Requirements) {
+			if zone := o.Requirements.Get(corev1.LabelTopologyZone).Any(); zone != "" {
+				candidateZones.Insert(zone)
+			}
+		}
+	}
+	if candidateZones.Len() <= 1 {
+		return
+	}
+	minCount := lo.Min(lo.Map(candidateZones.UnsortedList(), func(z string, _ int) int { return counts[z] }))
+	leastPopulated := lo.Filter(sets.List(candidateZones), func(z string, _ int) bool { return counts[z] == minCount })
+	if len(leastPopulated) == candidateZones.Len() {
+		return
+	}
+	reqs := scheduling.NewRequirements(replacement.Requirements.Values()...)
+	reqs.Add(scheduling.NewRequirement(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, leastPopulated...))
+	instanceTypes := replacement.InstanceTypeOptions.Compatible(reqs)
+	if len(instanceTypes) == 0 {
+		return
+	}
+	if _, err := instanceTypes.SatisfiesMinValues(reqs); err != nil {
+		return
+	}
+	replacement.Requirements = reqs
+	replacement.InstanceTypeOptions = instanceTypes
+}
+
 // computeConsolidation computes a consolidation action to take
 //
 // nolint:gocyclo
@@ -144,6 +223,17 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 
 	// if not all of the pods were scheduled, we can't do anything
 	if !results.AllNonPendingPodsScheduled() {
+		// If partial drains are enabled, we can still migrate the pods that fit on existing nodes off of a single
+		// candidate, leaving the pods that can't reschedule and the candidate itself in place. A candidate that was just
+		// rebalanced isn't rebalanced again until its cooldown elapses.
+		if len(candidates) == 1 && options.FromContext(ctx).FeatureGates.PartialDrain && !c.rebalances.Has(candidates[0].ProviderID()) {
+			if evictions := movablePods(candidates[0], results); len(evictions) > 0 {
+				return Command{
+					candidates: candidates,
+					evictions:  evictions,
+				}, results, nil
+			}
+		}
 		// This method is used by multi-node consolidation as well, so we'll only report in the single node case
 		if len(candidates) == 1 {
 			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, pretty.Sentence(results.NonPendingPodSchedulingErrors()))...)
@@ -174,10 +264,33 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	}
 
 	allExistingAreSpot := true
+	anyExistingAreReserved := false
 	for _, cn := range candidates {
 		if cn.capacityType != v1.CapacityTypeSpot {
 			allExistingAreSpot = false
 		}
+		if cn.capacityType == v1.CapacityTypeReserved {
+			anyExistingAreReserved = true
+		}
+	}
+
+	// We don't want to churn nodes off of reserved capacity that's already been paid for, so if any of the candidates
+	// are reserved, the replacement must also launch on reserved capacity
+	if anyExistingAreReserved {
+		if !results.NewNodeClaims[0].Requirements.Get(v1.CapacityTypeLabelKey).Has(v1.CapacityTypeReserved) {
+			if len(candidates) == 1 {
+				c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, "Can't replace a reserved node with a non-reserved node")...)
+			}
+			return Command{}, pscheduling.Results{}, nil
+		}
+		results.NewNodeClaims[0].Requirements.Add(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeReserved))
+		results.NewNodeClaims[0].NodeClaimTemplate.InstanceTypeOptions = results.NewNodeClaims[0].NodeClaimTemplate.InstanceTypeOptions.Compatible(results.NewNodeClaims[0].Requirements)
+		if len(results.NewNodeClaims[0].NodeClaimTemplate.InstanceTypeOptions) == 0 {
+			if len(candidates) == 1 {
+				c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, "Can't replace a reserved node with a non-reserved node")...)
+			}
+			return Command{}, pscheduling.Results{}, nil
+		}
 	}
 
 	// sort the instanceTypes by price before we take any actions like truncation for spot-to-spot consolidation or finding the nodeclaim
@@ -216,6 +329,9 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	if ctReq.Has(v1.CapacityTypeSpot) && ctReq.Has(v1.CapacityTypeOnDemand) {
 		results.NewNodeClaims[0].Requirements.Add(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeSpot))
 	}
+	if options.FromContext(ctx).FeatureGates.ZonalRebalance {
+		c.preferLeastPopulatedZones(results.NewNodeClaims[0])
+	}
 
 	return Command{
 		candidates:   candidates,
@@ -223,6 +339,22 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	}, results, nil
 }
 
+// movablePods returns the candidate's reschedulable pods that the scheduling simulation placed on existing nodes, which
+// can be evicted from the candidate without launching any capacity, as long as they have a controller to recreate them
+func movablePods(candidate *Candidate, results pscheduling.Results) []*corev1.Pod {
+	scheduled := sets.New[types.UID]()
+	for _, n := range results.ExistingNodes {
+		for _, p := range n.Pods {
+			scheduled.Insert(p.UID)
+		}
+	}
+	return lo.Filter(candidate.reschedulablePods, func(p *corev1.Pod, _ int) bool {
+		// Pods without a controller wouldn't be recreated after they're evicted
+		_, failed := results.PodErrors[p]
+		return !failed && scheduled.Has(p.UID) && metav1.GetControllerOf(p) != nil
+	})
+}
+
 // Compute command to execute spot-to-spot consolidation if:
 //  1. The SpotToSpotConsolidation feature flag is set to true.
 //  2. For single-node consolidation:
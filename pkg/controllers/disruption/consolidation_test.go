@@ -34,12 +34,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
@@ -929,6 +931,93 @@ var _ = Describe("Consolidation", func() {
 			Entry("if the candidate is on-demand node", false),
 			Entry("if the candidate is spot node", true),
 		)
+		It("can replace a reserved node with a cheaper reserved node", func() {
+			// Move the most and least expensive instance types onto reserved capacity
+			mostExpensiveOffering.Requirements[v1.CapacityTypeLabelKey] = scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeReserved)
+			leastExpensiveOffering.Requirements[v1.CapacityTypeLabelKey] = scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeReserved)
+			nodeClaim.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeReserved
+			node.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeReserved
+
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					}}})
+			ExpectApplied(ctx, env.Client, rs, pod, node, nodeClaim, nodePool)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+
+			ExpectSingletonReconciled(ctx, queue)
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			// The replacement should only be able to launch on reserved capacity
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(nodeClaims[0].Name).ToNot(Equal(nodeClaim.Name))
+			reqs := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...)
+			Expect(reqs.Get(v1.CapacityTypeLabelKey).Values()).To(ConsistOf(v1.CapacityTypeReserved))
+			Expect(reqs.Get(corev1.LabelInstanceTypeStable).Has(leastExpensiveInstance.Name)).To(BeTrue())
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("cannot replace a reserved node with a non-reserved node", func() {
+			mostExpensiveOffering.Requirements[v1.CapacityTypeLabelKey] = scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeReserved)
+			nodeClaim.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeReserved
+			node.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeReserved
+
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					}}})
+			ExpectApplied(ctx, env.Client, rs, pod, node, nodeClaim, nodePool)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectSingletonReconciled(ctx, queue)
+
+			// shouldn't delete the node
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+
+			_, ok := lo.Find(recorder.Events(), func(e events.Event) bool {
+				return strings.Contains(e.Message, "Can't replace a reserved node with a non-reserved node")
+			})
+			Expect(ok).To(BeTrue())
+		})
 		It("cannot replace spot with spot if less than minimum InstanceTypes flexibility", func() {
 			// Forcefully shrink the possible instanceTypes to be lower than 15 to replace a nodeclaim
 			cloudProvider.InstanceTypes = lo.Slice(fake.InstanceTypesAssorted(), 0, 5)
@@ -3069,6 +3158,192 @@ var _ = Describe("Consolidation", func() {
 			ExpectNotFound(ctx, env.Client, nodeClaims[1], nodes[1])
 		})
 	})
+	Context("Partial Drain", func() {
+		var nodeClaims []*v1.NodeClaim
+		var nodes []*corev1.Node
+		var rs *appsv1.ReplicaSet
+		var movable, pinned []*corev1.Pod
+
+		BeforeEach(func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(2, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: leastExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        leastExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       leastExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			for i := range nodeClaims {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+				// Pin a pod to each node with a label that no new capacity could satisfy
+				nodes[i].Labels["test-pin"] = fmt.Sprint(i)
+			}
+			rs = test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			ownerRefs := []metav1.OwnerReference{
+				{
+					APIVersion:         "apps/v1",
+					Kind:               "ReplicaSet",
+					Name:               rs.Name,
+					UID:                rs.UID,
+					Controller:         lo.ToPtr(true),
+					BlockOwnerDeletion: lo.ToPtr(true),
+				},
+			}
+			movable = test.Pods(1, test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels, OwnerReferences: ownerRefs}})
+			pinned = lo.Times(2, func(i int) *corev1.Pod {
+				return test.Pod(test.PodOptions{
+					ObjectMeta:   metav1.ObjectMeta{Labels: labels, OwnerReferences: ownerRefs},
+					NodeSelector: map[string]string{"test-pin": fmt.Sprint(i)},
+				})
+			})
+		})
+		It("should evict only the pods that can reschedule to existing nodes", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{PartialDrain: lo.ToPtr(true)}}))
+			ExpectApplied(ctx, env.Client, movable[0], pinned[0], pinned[1], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+
+			ExpectManualBinding(ctx, env.Client, pinned[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pinned[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, movable[0], nodes[1])
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+
+			// The candidate is tainted so that the movable pod doesn't land back on it, and the pod is evicted through the
+			// eviction queue
+			Expect(ExpectExists(ctx, env.Client, nodes[1]).Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeTrue())
+			Expect(evictionQueue.Has(nodes[1], pinned[1])).To(BeFalse())
+			ExpectSingletonReconciled(ctx, evictionQueue)
+
+			// The movable pod is evicted, but the pinned pods and both nodes remain
+			ExpectNotFound(ctx, env.Client, movable[0])
+			ExpectExists(ctx, env.Client, pinned[0])
+			ExpectExists(ctx, env.Client, pinned[1])
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+			Expect(queue.HasAny(nodeClaims[0].Status.ProviderID, nodeClaims[1].Status.ProviderID)).To(BeFalse())
+			ExpectMetricCounterValue(disruption.DecisionsPerformedTotal, 1, map[string]string{
+				"decision":           "rebalance",
+				metrics.ReasonLabel:  "underutilized",
+				"consolidation_type": "single",
+			})
+		})
+		It("should not evict pods without a controller", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{PartialDrain: lo.ToPtr(true)}}))
+			movable[0].OwnerReferences = nil
+			ExpectApplied(ctx, env.Client, movable[0], pinned[0], pinned[1], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+
+			ExpectManualBinding(ctx, env.Client, pinned[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pinned[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, movable[0], nodes[1])
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeFalse())
+			ExpectExists(ctx, env.Client, movable[0])
+			Expect(ExpectExists(ctx, env.Client, nodes[1]).Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+		})
+		It("should keep a rebalanced node tainted and not rebalance it again until its cooldown elapses", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{PartialDrain: lo.ToPtr(true)}}))
+			ExpectApplied(ctx, env.Client, movable[0], pinned[0], pinned[1], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+
+			ExpectManualBinding(ctx, env.Client, pinned[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pinned[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, movable[0], nodes[1])
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeTrue())
+
+			// The candidate keeps its taint and isn't rebalanced again while it's cooling down, even though its pod
+			// hasn't been evicted yet
+			*evictionQueue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeFalse())
+			Expect(ExpectExists(ctx, env.Client, nodes[1]).Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+
+			// Once the cooldown elapses, the candidate can be rebalanced again
+			fakeClock.Step(10 * time.Minute)
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeTrue())
+		})
+		It("should forget a rebalanced node once it leaves the cluster", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{PartialDrain: lo.ToPtr(true)}}))
+			ExpectApplied(ctx, env.Client, movable[0], pinned[0], pinned[1], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+
+			ExpectManualBinding(ctx, env.Client, pinned[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pinned[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, movable[0], nodes[1])
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeTrue())
+
+			// The candidate leaves the cluster while it's cooling down, so it's no longer tracked
+			cluster.DeleteNode(nodes[1].Name)
+			cluster.DeleteNodeClaim(nodeClaims[1].Name)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// A node that joins with the same provider ID isn't cooling down and can be rebalanced right away
+			*evictionQueue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
+			ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaims[1]))
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[1]))
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+			Expect(evictionQueue.Has(nodes[1], movable[0])).To(BeTrue())
+		})
+		It("should not evict pods when partial drains are disabled", func() {
+			ExpectApplied(ctx, env.Client, movable[0], pinned[0], pinned[1], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+
+			ExpectManualBinding(ctx, env.Client, pinned[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pinned[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, movable[0], nodes[1])
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			ExpectExists(ctx, env.Client, movable[0])
+			ExpectExists(ctx, env.Client, pinned[0])
+			ExpectExists(ctx, env.Client, pinned[1])
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+		})
+	})
 	Context("TTL", func() {
 		var nodeClaims []*v1.NodeClaim
 		var nodes []*corev1.Node
@@ -3309,6 +3584,60 @@ var _ = Describe("Consolidation", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectExists(ctx, env.Client, nodeClaims[0])
 		})
+		It("should taint candidates with the disruption candidate taint during the node TTL wait and remove it if the action becomes invalid", func() {
+			pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1.DoNotDisruptAnnotationKey: "true",
+				},
+			}})
+			ExpectApplied(ctx, env.Client, nodeClaims[0], nodes[0], nodePool, pod)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0]}, []*v1.NodeClaim{nodeClaims[0]})
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			finished := atomic.Bool{}
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				defer finished.Store(true)
+				ExpectSingletonReconciled(ctx, disruptionController)
+			}()
+
+			// wait for the disruptionController to block on the validation timeout
+			Eventually(fakeClock.HasWaiters, time.Second*10).Should(BeTrue())
+			node := ExpectExists(ctx, env.Client, nodes[0])
+			Expect(node.Spec.Taints).To(ContainElement(v1.DisruptionCandidatePreferNoScheduleTaint))
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+
+			// make the node non-empty by binding it
+			ExpectManualBinding(ctx, env.Client, pod, nodes[0])
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[0]))
+
+			// advance the clock so that the timeout expires
+			fakeClock.Step(31 * time.Second)
+			// controller should finish
+			Eventually(finished.Load, 10*time.Second).Should(BeTrue())
+			wg.Wait()
+
+			node = ExpectExists(ctx, env.Client, nodes[0])
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptionCandidatePreferNoScheduleTaint))
+		})
+		It("should keep the disruption candidate taint when the action is executed", func() {
+			ExpectApplied(ctx, env.Client, nodeClaims[0], nodes[0], nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0]}, []*v1.NodeClaim{nodeClaims[0]})
+
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+
+			node := ExpectExists(ctx, env.Client, nodes[0])
+			Expect(node.Spec.Taints).To(ContainElements(v1.DisruptionCandidatePreferNoScheduleTaint, v1.DisruptedNoScheduleTaint))
+		})
 		It("should not replace node if a pod schedules with karpenter.sh/do-not-disrupt during the TTL wait", func() {
 			pod := test.Pod()
 			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
@@ -3594,6 +3923,116 @@ var _ = Describe("Consolidation", func() {
 			Entry("if the candidate is on-demand node", false),
 			Entry("if the candidate is spot node", true),
 		)
+		DescribeTable("should only merge nodes with costly-to-move local data when the savings justify it", func(weight string, expectedDecision disruption.Decision) {
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					Annotations: map[string]string{v1.CostlyToMoveWeightAnnotationKey: weight},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					}},
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("100Gi")},
+				}})
+			for _, p := range pods {
+				p.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+			}
+			// the local data is estimated from the pods' ephemeral-storage requests, so the replacement needs the storage
+			for _, it := range cloudProvider.InstanceTypes {
+				it.Capacity[corev1.ResourceEphemeralStorage] = resource.MustParse("1Ti")
+			}
+			for _, n := range nodes {
+				n.Status.Allocatable[corev1.ResourceEphemeralStorage] = resource.MustParse("1Ti")
+			}
+
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodeClaims[2], nodes[2], nodePool)
+			ExpectMakeNodesInitialized(ctx, env.Client, nodes[0], nodes[1], nodes[2])
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, pods[2], nodes[2])
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0], nodes[1], nodes[2]}, []*v1.NodeClaim{nodeClaims[0], nodeClaims[1], nodeClaims[2]})
+
+			multiConsolidation := disruption.NewMultiNodeConsolidation(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue))
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, multiConsolidation.Reason())
+			Expect(err).To(Succeed())
+
+			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, multiConsolidation.ShouldDisrupt, multiConsolidation.Class(), queue)
+			Expect(err).To(Succeed())
+
+			// commands are validated after the consolidation TTL, so we need to step the clock once the command is computed
+			var wg sync.WaitGroup
+			if expectedDecision != disruption.NoOpDecision {
+				ExpectToWait(fakeClock, &wg)
+			}
+			cmd, _, err := multiConsolidation.ComputeCommand(ctx, budgets, candidates...)
+			wg.Wait()
+			Expect(err).To(Succeed())
+			Expect(cmd.Decision()).To(Equal(expectedDecision))
+		},
+			Entry("if the savings outweigh the cost of moving the local data", "0.000001", disruption.ReplaceDecision),
+			Entry("if the savings don't outweigh the cost of moving the local data", "1000000", disruption.NoOpDecision),
+		)
+		It("should fall back to consolidating a single node when the time budget runs out", func() {
+			// the clock steps past the budget every time that it's read, so the budget runs out before the first batch is
+			// considered
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MultiNodeConsolidationTimeout: lo.ToPtr(time.Second)}))
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					}}})
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodeClaims[2], nodes[2], nodePool)
+			ExpectMakeNodesInitialized(ctx, env.Client, nodes[0], nodes[1], nodes[2])
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, pods[2], nodes[2])
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0], nodes[1], nodes[2]}, []*v1.NodeClaim{nodeClaims[0], nodeClaims[1], nodeClaims[2]})
+
+			multiConsolidation := disruption.NewMultiNodeConsolidation(disruption.MakeConsolidation(&steppingClock{FakeClock: fakeClock, step: 2 * time.Second}, cluster, env.Client, prov, cloudProvider, recorder, queue))
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, multiConsolidation.Reason())
+			Expect(err).To(Succeed())
+
+			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, multiConsolidation.ShouldDisrupt, multiConsolidation.Class(), queue)
+			Expect(err).To(Succeed())
+
+			// commands are validated after the consolidation TTL, so we need to step the clock once the command is computed
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			cmd, _, err := multiConsolidation.ComputeCommand(ctx, budgets, candidates...)
+			wg.Wait()
+			Expect(err).To(Succeed())
+			Expect(cmd.Decision()).To(Equal(disruption.ReplaceDecision))
+			Expect(cmd.String()).To(ContainSubstring("terminating 1 nodes"))
+			ExpectMetricCounterValue(disruption.ConsolidationTimeoutsTotal, 1, map[string]string{"consolidation_type": "multi"})
+			ExpectMetricCounterValue(disruption.ConsolidationTimeoutFallbacksTotal, 1, map[string]string{"consolidation_type": "multi"})
+			// the pass didn't finish, so the cluster isn't marked as consolidated
+			Expect(multiConsolidation.IsConsolidated()).To(BeFalse())
+		})
 		It("can merge 3 nodes into 1 if the candidates have both spot and on-demand", func() {
 			// By default all the 3 nodeClaims are OD.
 			nodeClaims = lo.Ternary(false, spotNodeClaims, nodeClaims)
@@ -4190,6 +4629,64 @@ var _ = Describe("Consolidation", func() {
 			// we should maintain our skew, the new node must be in the same zone as the old node it replaced
 			ExpectSkew(ctx, env.Client, "default", &tsc).To(ConsistOf(1, 1, 1))
 		})
+		It("should replace nodes in the least populated zones when zonal rebalance is enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{ZonalRebalance: lo.ToPtr(true)}}))
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("1")}},
+				PodAntiRequirements: []corev1.PodAffinityTerm{
+					{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+						TopologyKey:   corev1.LabelHostname,
+					},
+				},
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					},
+				},
+			})
+
+			// Move the Zone 3 node into Zone 1 so that Zone 1 is over-represented and Zone 3 has no nodes
+			nodes[2].Labels[corev1.LabelTopologyZone] = "test-zone-1"
+			nodeClaims[2].Labels[corev1.LabelTopologyZone] = "test-zone-1"
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodeClaims[2], nodes[2], nodePool)
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+			ExpectManualBinding(ctx, env.Client, pods[1], nodes[1])
+			ExpectManualBinding(ctx, env.Client, pods[2], nodes[2])
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0], nodes[1], nodes[2]}, []*v1.NodeClaim{nodeClaims[0], nodeClaims[1], nodeClaims[2]})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectToWait(fakeClock, &wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+
+			// The expensive Zone 2 node is replaced, and the replacement can't launch in the zones that already have nodes
+			newNodeClaim, ok := lo.Find(ExpectNodeClaims(ctx, env.Client), func(m *v1.NodeClaim) bool {
+				return !oldNodeClaimNames.Has(m.Name)
+			})
+			Expect(ok).To(BeTrue())
+			zones := scheduling.NewNodeSelectorRequirementsWithMinValues(newNodeClaim.Spec.Requirements...).Get(corev1.LabelTopologyZone)
+			Expect(zones.Has("test-zone-3")).To(BeTrue())
+			Expect(zones.Has("test-zone-1")).To(BeFalse())
+			Expect(zones.Has("test-zone-2")).To(BeFalse())
+		})
 		It("won't delete node if it would violate pod anti-affinity", func() {
 			// create our RS so we can link a pod to it
 			rs := test.ReplicaSet()
@@ -4380,3 +4877,19 @@ var _ = Describe("Consolidation", func() {
 		})
 	})
 })
+
+// steppingClock steps the fake clock every time that the current time is read, so that time budgets run out between
+// reads regardless of how little work is done in between
+type steppingClock struct {
+	*clock.FakeClock
+	step time.Duration
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.FakeClock.Step(c.step)
+	return c.FakeClock.Now()
+}
+
+func (c *steppingClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -39,51 +41,68 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption/orchestration"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/health"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
 type Controller struct {
 	queue         *orchestration.Queue
+	evictionQueue *terminator.Queue
+	rebalances    *rebalances
 	kubeClient    client.Client
 	cluster       *state.Cluster
 	provisioner   *provisioning.Provisioner
 	recorder      events.Recorder
 	clock         clock.Clock
 	cloudProvider cloudprovider.CloudProvider
+	webhooks      *Webhooks
 	methods       []Method
 	mu            sync.Mutex
 	lastRun       map[string]time.Time
+	heartbeat     *health.Heartbeat
 }
 
 // pollingPeriod that we inspect cluster to look for opportunities to disrupt
 const pollingPeriod = 10 * time.Second
 
+// heartbeatTimeout is how long the disruption loop can go without evaluating the cluster before it is considered stuck
+const heartbeatTimeout = 10 * time.Minute
+
 func NewController(clk clock.Clock, kubeClient client.Client, provisioner *provisioning.Provisioner,
-	cp cloudprovider.CloudProvider, recorder events.Recorder, cluster *state.Cluster, queue *orchestration.Queue,
+	cp cloudprovider.CloudProvider, recorder events.Recorder, cluster *state.Cluster, queue *orchestration.Queue, evictionQueue *terminator.Queue,
 ) *Controller {
 	c := MakeConsolidation(clk, cluster, kubeClient, provisioner, cp, recorder, queue)
 
 	return &Controller{
 		queue:         queue,
+		evictionQueue: evictionQueue,
+		rebalances:    c.rebalances,
 		clock:         clk,
 		kubeClient:    kubeClient,
 		cluster:       cluster,
 		provisioner:   provisioner,
 		recorder:      recorder,
 		cloudProvider: cp,
+		webhooks:      NewWebhooks(&http.Client{}),
 		lastRun:       map[string]time.Time{},
+		heartbeat:     health.NewHeartbeat(clk, "disruption", heartbeatTimeout, queue.Len),
 		methods: []Method{
+			// Replace any NodeClaims with problems reported on their nodes, e.g. by node-problem-detector, since their pods may not be running correctly.
+			NewProblemDetected(kubeClient, cluster, provisioner, recorder),
 			// Terminate any NodeClaims that have drifted from provisioning specifications, allowing the pods to reschedule.
-			NewDrift(kubeClient, cluster, provisioner, recorder),
+			NewDrift(clk, kubeClient, cluster, provisioner, recorder),
 			// Delete any empty NodeClaims as there is zero cost in terms of disruption.
 			NewEmptiness(c),
 			// Attempt to identify multiple NodeClaims that we can consolidate simultaneously to reduce pod churn
@@ -94,7 +113,20 @@ func NewController(clk clock.Clock, kubeClient client.Client, provisioner *provi
 	}
 }
 
+// Heartbeat returns the Heartbeat that tracks whether the disruption loop is making progress
+func (c *Controller) Heartbeat() *health.Heartbeat {
+	return c.heartbeat
+}
+
+// Webhooks returns the Webhooks that are notified of disruptions before they're performed
+func (c *Controller) Webhooks() *Webhooks {
+	return c.webhooks
+}
+
 func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	if err := m.Add(c.webhooks); err != nil {
+		return fmt.Errorf("adding disruption webhooks, %w", err)
+	}
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("disruption").
 		WatchesRawSource(singleton.Source()).
@@ -103,6 +135,7 @@ func (c *Controller) Register(_ context.Context, m manager.Manager) error {
 
 func (c *Controller) Reconcile(ctx context.Context) (reconcile.Result, error) {
 	ctx = injection.WithControllerName(ctx, "disruption")
+	c.heartbeat.Start()
 
 	// this won't catch if the reconcile loop hangs forever, but it will catch other issues
 	c.logAbnormalRuns(ctx)
@@ -120,12 +153,15 @@ func (c *Controller) Reconcile(ctx context.Context) (reconcile.Result, error) {
 		log.FromContext(ctx).V(1).Info("waiting on cluster sync")
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
+	c.heartbeat.Beat()
+	c.rebalances.Prune(sets.New(lo.Map(c.cluster.Nodes(), func(s *state.StateNode, _ int) string { return s.ProviderID() })...))
 
-	// Karpenter taints nodes with a karpenter.sh/disruption taint as part of the disruption process while it progresses in memory.
+	// Karpenter taints nodes with the karpenter.sh/disrupted and karpenter.sh/disruption-candidate taints as part of the disruption process while it progresses in memory.
 	// If Karpenter restarts or fails with an error during a disruption action, some nodes can be left tainted.
-	// Idempotently remove this taint from candidates that are not in the orchestration queue before continuing.
+	// Idempotently remove this taint from candidates that are not in the orchestration queue or cooling down after a
+	// rebalance before continuing.
 	outdatedNodes := lo.Filter(c.cluster.Nodes(), func(s *state.StateNode, _ int) bool {
-		return !c.queue.HasAny(s.ProviderID()) && !s.Deleted()
+		return !c.queue.HasAny(s.ProviderID()) && !c.rebalances.Has(s.ProviderID()) && !s.Deleted()
 	})
 	if err := state.RequireNoScheduleTaint(ctx, c.kubeClient, false, outdatedNodes...); err != nil {
 		if errors.IsConflict(err) {
@@ -133,6 +169,12 @@ func (c *Controller) Reconcile(ctx context.Context) (reconcile.Result, error) {
 		}
 		return reconcile.Result{}, fmt.Errorf("removing taint %s from nodes, %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err)
 	}
+	if err := state.RequireDisruptionCandidateTaint(ctx, c.kubeClient, false, outdatedNodes...); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("removing taint %s from nodes, %w", pretty.Taint(v1.DisruptionCandidatePreferNoScheduleTaint), err)
+	}
 	if err := state.ClearNodeClaimsCondition(ctx, c.kubeClient, v1.ConditionTypeDisruptionReason, outdatedNodes...); err != nil {
 		if errors.IsConflict(err) {
 			return reconcile.Result{Requeue: true}, nil
@@ -140,6 +182,12 @@ func (c *Controller) Reconcile(ctx context.Context) (reconcile.Result, error) {
 		return reconcile.Result{}, fmt.Errorf("removing %s condition from nodeclaims, %w", v1.ConditionTypeDisruptionReason, err)
 	}
 
+	// Voluntary disruption is paused cluster-wide while a change freeze is enabled
+	if c.cluster.ChangeFrozen() {
+		log.FromContext(ctx).V(1).Info("waiting on change freeze to be lifted")
+		return reconcile.Result{RequeueAfter: pollingPeriod}, nil
+	}
+
 	// Attempt different disruption methods. We'll only let one method perform an action
 	for _, m := range c.methods {
 		c.recordRun(fmt.Sprintf("%T", m))
@@ -204,8 +252,33 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 	commandID := uuid.NewUUID()
 	log.FromContext(ctx).WithValues("command-id", commandID, "reason", strings.ToLower(string(m.Reason()))).Info(fmt.Sprintf("disrupting nodeclaim(s) via %s", cmd))
 
-	// Cordon the old nodes before we launch the replacements to prevent new pods from scheduling to the old nodes
-	if err := c.MarkDisrupted(ctx, m, cmd.candidates...); err != nil {
+	// Notify external change management systems before anything is disrupted. If they're required to acknowledge the
+	// disruption, we don't disrupt until they do.
+	if err := c.webhooks.Notify(ctx, NewNotification(commandID, m, cmd, c.clock.Now())); err != nil {
+		if options.FromContext(ctx).DisruptionWebhookRequireAck {
+			return fmt.Errorf("waiting on disruption webhook acknowledgement (command-id: %s), %w", commandID, err)
+		}
+		log.FromContext(ctx).WithValues("command-id", commandID).Error(err, "failed notifying disruption webhooks")
+	}
+
+	// A rebalance only evicts the pods that can reschedule, so the candidate isn't marked as disrupted or queued for deletion
+	if cmd.Decision() == RebalanceDecision {
+		if err := c.rebalance(ctx, cmd); err != nil {
+			return fmt.Errorf("rebalancing (command-id: %s), %w", commandID, err)
+		}
+		schedulingResults.Record(log.IntoContext(ctx, operatorlogging.NopLogger), c.recorder, c.cluster)
+		DecisionsPerformedTotal.Inc(map[string]string{
+			decisionLabel:          string(cmd.Decision()),
+			metrics.ReasonLabel:    strings.ToLower(string(m.Reason())),
+			consolidationTypeLabel: m.ConsolidationType(),
+		})
+		return nil
+	}
+
+	// Cordon the old nodes before we launch the replacements to prevent new pods from scheduling to the old nodes. If the
+	// replacement is make-before-break, the old node is only cordoned once the replacement has initialized.
+	makeBeforeBreak := isMakeBeforeBreak(m, cmd)
+	if err := c.markDisrupted(ctx, m, !makeBeforeBreak, cmd.candidates...); err != nil {
 		return fmt.Errorf("marking disrupted (command-id: %s), %w", commandID, err)
 	}
 
@@ -231,11 +304,25 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 	schedulingResults.Record(log.IntoContext(ctx, operatorlogging.NopLogger), c.recorder, c.cluster)
 
 	statenodes := lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode })
-	if err := c.queue.Add(orchestration.NewCommand(nodeClaimNames, statenodes, commandID, m.Reason(), m.ConsolidationType())); err != nil {
+	queueCmd := orchestration.NewCommand(nodeClaimNames, statenodes, commandID, m.Reason(), m.ConsolidationType())
+	if makeBeforeBreak {
+		queueCmd = queueCmd.WithMakeBeforeBreak()
+	}
+	bulkDelete := isBulkDelete(m, cmd)
+	if bulkDelete {
+		queueCmd = queueCmd.WithBulkDelete()
+	}
+	if err := c.queue.Add(queueCmd); err != nil {
 		providerIDs := lo.Map(cmd.candidates, func(c *Candidate, _ int) string { return c.ProviderID() })
 		c.cluster.UnmarkForDeletion(providerIDs...)
 		return fmt.Errorf("adding command to queue (command-id: %s), %w", commandID, err)
 	}
+	// Bulk deletes publish a single event for each NodePool rather than an event for each of the candidates
+	if bulkDelete {
+		for _, candidates := range lo.GroupBy(cmd.candidates, func(c *Candidate) string { return c.nodePool.Name }) {
+			c.recorder.Publish(disruptionevents.TerminatingEmpty(candidates[0].nodePool, lo.Map(candidates, func(c *Candidate, _ int) *v1.NodeClaim { return c.NodeClaim })))
+		}
+	}
 
 	// An action is only performed and pods/nodes are only disrupted after a successful add to the queue
 	DecisionsPerformedTotal.Inc(map[string]string{
@@ -246,6 +333,19 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command,
 	return nil
 }
 
+// rebalance taints the candidate of a rebalance command so that the evicted pods don't land back on it, and then
+// hands its pods to the eviction queue, which respects their PDBs. The candidate stays tainted and isn't rebalanced
+// again until its cooldown elapses.
+func (c *Controller) rebalance(ctx context.Context, cmd Command) error {
+	candidate := cmd.candidates[0]
+	if err := state.RequireNoScheduleTaint(ctx, c.kubeClient, true, candidate.StateNode); err != nil {
+		return fmt.Errorf("tainting node with %s, %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err)
+	}
+	c.rebalances.Add(candidate.ProviderID())
+	c.evictionQueue.Add(candidate.Node, cmd.evictions...)
+	return nil
+}
+
 // createReplacementNodeClaims creates replacement NodeClaims
 func (c *Controller) createReplacementNodeClaims(ctx context.Context, m Method, cmd Command) ([]string, error) {
 	nodeClaimNames, err := c.provisioner.CreateNodeClaims(ctx, cmd.replacements, provisioning.WithReason(strings.ToLower(string(m.Reason()))))
@@ -259,12 +359,36 @@ func (c *Controller) createReplacementNodeClaims(ctx context.Context, m Method,
 	return nodeClaimNames, nil
 }
 
+// isMakeBeforeBreak returns true if the command replaces a single node through drift or single-node consolidation and
+// the node's NodePool has opted in to waiting for the replacement to initialize before the node is cordoned
+func isMakeBeforeBreak(m Method, cmd Command) bool {
+	if cmd.Decision() != ReplaceDecision || len(cmd.candidates) != 1 {
+		return false
+	}
+	if m.Reason() != v1.DisruptionReasonDrifted && m.ConsolidationType() != SingleNodeConsolidationType {
+		return false
+	}
+	return cmd.candidates[0].nodePool.Spec.Disruption.ReplacementPolicy == v1.ReplacementPolicyMakeBeforeBreak
+}
+
+// isBulkDelete returns true if the command deletes empty nodes, which are deleted together rather than one at a time
+// since there are no pods to reschedule
+func isBulkDelete(m Method, cmd Command) bool {
+	return cmd.Decision() == DeleteDecision && m.Reason() == v1.DisruptionReasonEmpty
+}
+
 func (c *Controller) MarkDisrupted(ctx context.Context, m Method, candidates ...*Candidate) error {
-	stateNodes := lo.Map(candidates, func(c *Candidate, _ int) *state.StateNode {
-		return c.StateNode
-	})
-	if err := state.RequireNoScheduleTaint(ctx, c.kubeClient, true, stateNodes...); err != nil {
-		return fmt.Errorf("tainting nodes with %s: %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err)
+	return c.markDisrupted(ctx, m, true, candidates...)
+}
+
+func (c *Controller) markDisrupted(ctx context.Context, m Method, taint bool, candidates ...*Candidate) error {
+	if taint {
+		stateNodes := lo.Map(candidates, func(c *Candidate, _ int) *state.StateNode {
+			return c.StateNode
+		})
+		if err := state.RequireNoScheduleTaint(ctx, c.kubeClient, true, stateNodes...); err != nil {
+			return fmt.Errorf("tainting nodes with %s: %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err)
+		}
 	}
 
 	providerIDs := lo.Map(candidates, func(c *Candidate, _ int) string { return c.ProviderID() })
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"sort"
 
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
@@ -35,14 +36,16 @@ import (
 
 // Drift is a subreconciler that deletes drifted candidates.
 type Drift struct {
+	clock       clock.Clock
 	kubeClient  client.Client
 	cluster     *state.Cluster
 	provisioner *provisioning.Provisioner
 	recorder    events.Recorder
 }
 
-func NewDrift(kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Drift {
+func NewDrift(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Drift {
 	return &Drift{
+		clock:       clk,
 		kubeClient:  kubeClient,
 		cluster:     cluster,
 		provisioner: provisioner,
@@ -52,11 +55,12 @@ func NewDrift(kubeClient client.Client, cluster *state.Cluster, provisioner *pro
 
 // ShouldDisrupt is a predicate used to filter candidates
 func (d *Drift) ShouldDisrupt(ctx context.Context, c *Candidate) bool {
-	return c.NodeClaim.StatusConditions().Get(string(d.Reason())).IsTrue()
+	// Drifted nodes are left in place until they've lived for their NodePool's MinNodeLifetime
+	return c.NodeClaim.StatusConditions().Get(string(d.Reason())).IsTrue() && !withinMinNodeLifetime(d.clock, c)
 }
 
 // ComputeCommand generates a disruption command given candidates
-func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
+func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) (Command, scheduling.Results, error) {
 	sort.Slice(candidates, func(i int, j int) bool {
 		return candidates[i].NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time.Before(
 			candidates[j].NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time)
@@ -70,11 +74,11 @@ func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[
 		if len(candidate.reschedulablePods) > 0 {
 			continue
 		}
-		// If there's disruptions allowed for the candidate's nodepool,
+		// If the candidate's budgets allow a disruption,
 		// add it to the list of candidates, and decrement the budget.
-		if disruptionBudgetMapping[candidate.nodePool.Name] > 0 {
+		if disruptionBudgetMapping.Allows(candidate) {
 			empty = append(empty, candidate)
-			disruptionBudgetMapping[candidate.nodePool.Name]--
+			disruptionBudgetMapping.Consume(candidate)
 		}
 	}
 	// Disrupt all empty drifted candidates, as they require no scheduling simulations.
@@ -88,7 +92,7 @@ func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[
 		// If the disruption budget doesn't allow this candidate to be disrupted,
 		// continue to the next candidate. We don't need to decrement any budget
 		// counter since drift commands can only have one candidate.
-		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			continue
 		}
 		// Check if we need to create any NodeClaims.
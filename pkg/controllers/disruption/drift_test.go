@@ -28,9 +28,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	karpv1alpha1 "sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
@@ -238,6 +240,57 @@ var _ = Describe("Drift", func() {
 			ExpectSingletonReconciled(ctx, queue)
 			Expect(len(ExpectNodeClaims(ctx, env.Client))).To(Equal(7))
 		})
+		It("should only allow as many empty nodes to be disrupted across nodepools as the node disruption budget allows", func() {
+			nodePool2 := test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Disruption: v1.Disruption{
+						ConsolidateAfter: v1.MustParseNillableDuration("Never"),
+						Budgets:          []v1.Budget{{Nodes: "100%"}},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodePool2)
+			nodeClaims, nodes = nil, nil
+			for _, np := range []*v1.NodePool{nodePool, nodePool2} {
+				ncs, ns := test.NodeClaimsAndNodes(numNodes/2, v1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							v1.NodePoolLabelKey:            np.Name,
+							corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+							v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+							corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+							"app":                          "database",
+						},
+					},
+					Status: v1.NodeClaimStatus{
+						Allocatable: map[corev1.ResourceName]resource.Quantity{
+							corev1.ResourceCPU:  resource.MustParse("32"),
+							corev1.ResourcePods: resource.MustParse("100"),
+						},
+					},
+				})
+				nodeClaims = append(nodeClaims, ncs...)
+				nodes = append(nodes, ns...)
+			}
+			ExpectApplied(ctx, env.Client, &karpv1alpha1.NodeDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "database"},
+				Spec: karpv1alpha1.NodeDisruptionBudgetSpec{
+					NodeSelector:   metav1.LabelSelector{MatchLabels: map[string]string{"app": "database"}},
+					MaxUnavailable: intstr.FromInt32(2),
+				},
+			})
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Execute command, thus deleting 2 nodes
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(len(ExpectNodeClaims(ctx, env.Client))).To(Equal(8))
+		})
 		It("should disrupt 3 nodes, taking into account commands in progress", func() {
 			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
 				ObjectMeta: metav1.ObjectMeta{
@@ -633,6 +686,38 @@ var _ = Describe("Drift", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
 			ExpectNotFound(ctx, env.Client, nodeClaim, node)
 		})
+		It("should not delete drifted nodes within the NodePool's minNodeLifetime", func() {
+			nodePool.Spec.Disruption.MinNodeLifetime = &metav1.Duration{Duration: time.Hour}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectSingletonReconciled(ctx, queue)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should delete drifted nodes once they've lived for the NodePool's minNodeLifetime", func() {
+			nodePool.Spec.Disruption.MinNodeLifetime = &metav1.Duration{Duration: time.Hour}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(2 * time.Hour)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			// Process the item so that the nodes can be deleted.
+			ExpectSingletonReconciled(ctx, queue)
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
 		It("should disrupt all empty drifted nodes in parallel", func() {
 			nodeClaims, nodes := test.NodeClaimsAndNodes(100, v1.NodeClaim{
 				ObjectMeta: metav1.ObjectMeta{
@@ -725,6 +810,39 @@ var _ = Describe("Drift", func() {
 			Expect(nodeclaims[0].Name).ToNot(Equal(nodeClaim.Name))
 			Expect(nodes[0].Name).ToNot(Equal(node.Name))
 		})
+		It("should not taint drifted nodes before the replacement initializes when make-before-break is enabled", func() {
+			nodePool.Spec.Disruption.ReplacementPolicy = v1.ReplacementPolicyMakeBeforeBreak
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// the replacement has launched but the drifted node is still schedulable
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			node = ExpectExists(ctx, env.Client, node)
+			Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+			Expect(ExpectStateNodeExists(cluster, node).MarkedForDeletion()).To(BeTrue())
+		})
 		It("should untaint nodes when drift replacement fails", func() {
 			cloudProvider.AllowedCreateCalls = 0 // fail the replacement and expect it to untaint
 
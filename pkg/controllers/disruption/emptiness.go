@@ -47,6 +47,10 @@ func (e *Emptiness) ShouldDisrupt(_ context.Context, c *Candidate) bool {
 		e.recorder.Publish(disruptionevents.Unconsolidatable(c.Node, c.NodeClaim, fmt.Sprintf("NodePool %q has consolidation disabled", c.nodePool.Name))...)
 		return false
 	}
+	if withinMinNodeLifetime(e.clock, c) {
+		e.recorder.Publish(disruptionevents.Unconsolidatable(c.Node, c.NodeClaim, fmt.Sprintf("Node is within the minNodeLifetime of NodePool %q", c.nodePool.Name))...)
+		return false
+	}
 	// return true if there are no pods and the nodeclaim is consolidatable
 	return len(c.reschedulablePods) == 0 && c.NodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsTrue()
 }
@@ -54,11 +58,11 @@ func (e *Emptiness) ShouldDisrupt(_ context.Context, c *Candidate) bool {
 // ComputeCommand generates a disruption command given candidates
 //
 //nolint:gocyclo
-func (e *Emptiness) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
+func (e *Emptiness) ComputeCommand(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) (Command, scheduling.Results, error) {
 	if e.IsConsolidated() {
 		return Command{}, scheduling.Results{}, nil
 	}
-	candidates = e.sortCandidates(candidates)
+	candidates = e.sortCandidates(ctx, candidates)
 
 	empty := make([]*Candidate, 0, len(candidates))
 	constrainedByBudgets := false
@@ -66,15 +70,15 @@ func (e *Emptiness) ComputeCommand(ctx context.Context, disruptionBudgetMapping
 		if len(candidate.reschedulablePods) > 0 {
 			continue
 		}
-		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			// set constrainedByBudgets to true if any node was a candidate but was constrained by a budget
 			constrainedByBudgets = true
 			continue
 		}
-		// If there's disruptions allowed for the candidate's nodepool,
+		// If the candidate's budgets allow a disruption,
 		// add it to the list of candidates, and decrement the budget.
 		empty = append(empty, candidate)
-		disruptionBudgetMapping[candidate.nodePool.Name]--
+		disruptionBudgetMapping.Consume(candidate)
 	}
 	// none empty, so do nothing
 	if len(empty) == 0 {
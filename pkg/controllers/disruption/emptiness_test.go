@@ -18,6 +18,7 @@ limitations under the License.
 package disruption_test
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -34,6 +35,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -411,6 +413,46 @@ var _ = Describe("Emptiness", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
 			ExpectNotFound(ctx, env.Client, nodeClaim, node)
 		})
+		It("should not delete empty nodes while a change freeze is enabled", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			cluster.SetChangeFrozen(true)
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should not delete empty nodes within the NodePool's minNodeLifetime", func() {
+			nodePool.Spec.Disruption.MinNodeLifetime = &metav1.Duration{Duration: time.Hour}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Node is within the minNodeLifetime of NodePool %q", nodePool.Name))).To(BeTrue())
+		})
+		It("should delete empty nodes once they've lived for the NodePool's minNodeLifetime", func() {
+			nodePool.Spec.Disruption.MinNodeLifetime = &metav1.Duration{Duration: time.Hour}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(2 * time.Hour)
+			wg := sync.WaitGroup{}
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			wg.Wait()
+
+			ExpectSingletonReconciled(ctx, queue)
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
 		It("should ignore nodes without the consolidatable status condition", func() {
 			_ = nodeClaim.StatusConditions().Clear(v1.ConditionTypeConsolidatable)
 			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
@@ -494,6 +536,27 @@ var _ = Describe("Emptiness", func() {
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim2)
 	})
+	It("should publish a single event for the empty nodes of a NodePool", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodeClaim2, node2, nodePool)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+		fakeClock.Step(10 * time.Minute)
+
+		wg := sync.WaitGroup{}
+		ExpectToWait(fakeClock, &wg)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		wg.Wait()
+
+		ExpectSingletonReconciled(ctx, queue)
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim, nodeClaim2)
+		ExpectNotFound(ctx, env.Client, nodeClaim, nodeClaim2)
+
+		// The empty nodes are summarized by their NodePool rather than an event for each of them
+		Expect(recorder.Calls(events.DisruptionTerminatingEmpty)).To(Equal(1))
+		Expect(recorder.Calls(events.DisruptionTerminating)).To(Equal(0))
+	})
 	It("considers pending pods when consolidating", func() {
 		largeTypes := lo.Filter(cloudProvider.InstanceTypes, func(item *cloudprovider.InstanceType, index int) bool {
 			return item.Capacity.Cpu().Cmp(resource.MustParse("64")) >= 0
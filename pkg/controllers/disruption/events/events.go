@@ -20,19 +20,21 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/samber/lo"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	corev1 "k8s.io/api/core/v1"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
 func Launching(nodeClaim *v1.NodeClaim, reason string) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "DisruptionLaunching",
+		Reason:         events.DisruptionLaunching,
 		Message:        fmt.Sprintf("Launching NodeClaim: %s", cases.Title(language.Und, cases.NoLower).String(reason)),
 		DedupeValues:   []string{string(nodeClaim.UID), reason},
 	}
@@ -42,31 +44,54 @@ func WaitingOnReadiness(nodeClaim *v1.NodeClaim) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "DisruptionWaitingReadiness",
+		Reason:         events.DisruptionWaitingReadiness,
 		Message:        "Waiting on readiness to continue disruption",
 		DedupeValues:   []string{string(nodeClaim.UID)},
 	}
 }
 
+func WaitingOnVolumeDetachment(node *corev1.Node) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DisruptionWaitingVolumeDetachment,
+		Message:        "Waiting on volumes to detach to continue disruption",
+		DedupeValues:   []string{string(node.UID)},
+	}
+}
+
 func Terminating(node *corev1.Node, nodeClaim *v1.NodeClaim, reason string) []events.Event {
 	return []events.Event{
 		{
 			InvolvedObject: node,
 			Type:           corev1.EventTypeNormal,
-			Reason:         "DisruptionTerminating",
+			Reason:         events.DisruptionTerminating,
 			Message:        fmt.Sprintf("Disrupting Node: %s", cases.Title(language.Und, cases.NoLower).String(reason)),
 			DedupeValues:   []string{string(node.UID), reason},
 		},
 		{
 			InvolvedObject: nodeClaim,
 			Type:           corev1.EventTypeNormal,
-			Reason:         "DisruptionTerminating",
+			Reason:         events.DisruptionTerminating,
 			Message:        fmt.Sprintf("Disrupting NodeClaim: %s", cases.Title(language.Und, cases.NoLower).String(reason)),
 			DedupeValues:   []string{string(nodeClaim.UID), reason},
 		},
 	}
 }
 
+// TerminatingEmpty is a single event that summarizes the empty NodeClaims of a NodePool that are deleted together,
+// rather than an event for each of them
+func TerminatingEmpty(nodePool *v1.NodePool, nodeClaims []*v1.NodeClaim) events.Event {
+	names := lo.Map(nodeClaims, func(nc *v1.NodeClaim, _ int) string { return nc.Name })
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DisruptionTerminatingEmpty,
+		Message:        fmt.Sprintf("Disrupting %d empty NodeClaims: %s", len(nodeClaims), pretty.Slice(names, 5)),
+		DedupeValues:   append([]string{string(nodePool.UID)}, names...),
+	}
+}
+
 // Unconsolidatable is an event that informs the user that a NodeClaim/Node combination cannot be consolidated
 // due to the state of the NodeClaim/Node or due to some state of the pods that are scheduled to the NodeClaim/Node
 func Unconsolidatable(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) []events.Event {
@@ -74,7 +99,7 @@ func Unconsolidatable(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) []
 		{
 			InvolvedObject: node,
 			Type:           corev1.EventTypeNormal,
-			Reason:         "Unconsolidatable",
+			Reason:         events.Unconsolidatable,
 			Message:        msg,
 			DedupeValues:   []string{string(node.UID)},
 			DedupeTimeout:  time.Minute * 15,
@@ -82,7 +107,7 @@ func Unconsolidatable(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) []
 		{
 			InvolvedObject: nodeClaim,
 			Type:           corev1.EventTypeNormal,
-			Reason:         "Unconsolidatable",
+			Reason:         events.Unconsolidatable,
 			Message:        msg,
 			DedupeValues:   []string{string(nodeClaim.UID)},
 			DedupeTimeout:  time.Minute * 15,
@@ -97,7 +122,7 @@ func Blocked(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) (evs []even
 		evs = append(evs, events.Event{
 			InvolvedObject: node,
 			Type:           corev1.EventTypeNormal,
-			Reason:         "DisruptionBlocked",
+			Reason:         events.DisruptionBlocked,
 			Message:        msg,
 			DedupeValues:   []string{string(node.UID)},
 		})
@@ -106,7 +131,7 @@ func Blocked(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) (evs []even
 		evs = append(evs, events.Event{
 			InvolvedObject: nodeClaim,
 			Type:           corev1.EventTypeNormal,
-			Reason:         "DisruptionBlocked",
+			Reason:         events.DisruptionBlocked,
 			Message:        msg,
 			DedupeValues:   []string{string(nodeClaim.UID)},
 		})
@@ -118,7 +143,7 @@ func NodePoolBlockedForDisruptionReason(nodePool *v1.NodePool, reason v1.Disrupt
 	return events.Event{
 		InvolvedObject: nodePool,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "DisruptionBlocked",
+		Reason:         events.DisruptionBlocked,
 		Message:        fmt.Sprintf("No allowed disruptions for disruption reason %s due to blocking budget", reason),
 		DedupeValues:   []string{string(nodePool.UID), string(reason)},
 		DedupeTimeout:  1 * time.Minute,
@@ -129,7 +154,7 @@ func NodePoolBlocked(nodePool *v1.NodePool) events.Event {
 	return events.Event{
 		InvolvedObject: nodePool,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "DisruptionBlocked",
+		Reason:         events.DisruptionBlocked,
 		Message:        "No allowed disruptions due to blocking budget",
 		DedupeValues:   []string{string(nodePool.UID)},
 		// Set a small timeout as a NodePool's disruption budget can change every minute.
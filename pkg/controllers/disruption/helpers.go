@@ -23,12 +23,17 @@ import (
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption/orchestration"
@@ -38,6 +43,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pdb"
@@ -79,7 +85,11 @@ func SimulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 		pods = append(pods, n.reschedulablePods...)
 	}
 	pods = append(pods, deletingNodePods...)
-	scheduler, err := provisioner.NewScheduler(log.IntoContext(ctx, operatorlogging.NopLogger), pods, stateNodes)
+	// pods that never trigger provisioning shouldn't cause replacement capacity to be launched either
+	pods = lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return provisioning.TriggersProvisioning(ctx, p) })
+	// Disruption simulations don't launch capacity from their results, so there's no reason to defer their pods when the
+	// instance types get old
+	scheduler, err := provisioner.NewScheduler(log.IntoContext(ctx, operatorlogging.NopLogger), pods, stateNodes, simulation.WithoutInstanceTypesMaxAge())
 	if err != nil {
 		return pscheduling.Results{}, fmt.Errorf("creating scheduler, %w", err)
 	}
@@ -183,21 +193,59 @@ func BuildNodePoolMap(ctx context.Context, kubeClient client.Client, cloudProvid
 			continue
 		}
 		nodePoolToInstanceTypesMap[np.Name] = map[string]*cloudprovider.InstanceType{}
-		for _, it := range nodePoolInstanceTypes {
+		for _, it := range cloudprovider.ApplyMaxPods(np, cloudprovider.ApplyAdditionalResources(np, nodePoolInstanceTypes)) {
 			nodePoolToInstanceTypesMap[np.Name][it.Name] = it
 		}
 	}
 	return nodePoolMap, nodePoolToInstanceTypesMap, nil
 }
 
-// BuildDisruptionBudgets prepares our disruption budget mapping. The disruption budget maps each disruption reason to the number of allowed disruptions.
-// We calculate allowed disruptions by taking the max disruptions allowed by disruption reason and subtracting the number of nodes that are NotReady and already being deleted by that disruption reason.
+// DisruptionBudgetMapping tracks the number of disruptions that are still allowed by each NodePool and each
+// NodeDisruptionBudget. A candidate can only be disrupted if its NodePool and every NodeDisruptionBudget that
+// selects its node allow a disruption.
+type DisruptionBudgetMapping struct {
+	NodePools             map[string]int // map[nodepool] -> allowed disruptions
+	NodeDisruptionBudgets map[string]int // map[nodedisruptionbudget] -> allowed disruptions
+	selectors             map[string]labels.Selector
+}
+
+// Allows returns true if the candidate can be disrupted without violating any of the budgets that apply to it
+func (m DisruptionBudgetMapping) Allows(c *Candidate) bool {
+	if m.NodePools[c.nodePool.Name] == 0 {
+		return false
+	}
+	return lo.EveryBy(m.selecting(c), func(name string) bool { return m.NodeDisruptionBudgets[name] > 0 })
+}
+
+// Consume decrements each of the budgets that apply to the candidate
+func (m DisruptionBudgetMapping) Consume(c *Candidate) {
+	m.NodePools[c.nodePool.Name]--
+	for _, name := range m.selecting(c) {
+		m.NodeDisruptionBudgets[name]--
+	}
+}
+
+// selecting returns the names of the NodeDisruptionBudgets that select the candidate's node
+func (m DisruptionBudgetMapping) selecting(c *Candidate) []string {
+	return lo.Filter(lo.Keys(m.selectors), func(name string, _ int) bool {
+		return m.selectors[name].Matches(labels.Set(c.Labels()))
+	})
+}
+
+// BuildDisruptionBudgetMapping prepares our disruption budget mapping. The disruption budget maps each NodePool and each NodeDisruptionBudget
+// to the number of allowed disruptions for the disruption reason. We calculate allowed disruptions by taking the max disruptions allowed and
+// subtracting the number of nodes that are NotReady and already being deleted.
 //
 //nolint:gocyclo
-func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, reason v1.DisruptionReason) (map[string]int, error) {
-	disruptionBudgetMapping := map[string]int{}
+func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, reason v1.DisruptionReason) (DisruptionBudgetMapping, error) {
+	disruptionBudgetMapping := DisruptionBudgetMapping{
+		NodePools:             map[string]int{},
+		NodeDisruptionBudgets: map[string]int{},
+		selectors:             map[string]labels.Selector{},
+	}
 	numNodes := map[string]int{}   // map[nodepool] -> node count in nodepool
 	disrupting := map[string]int{} // map[nodepool] -> nodes undergoing disruption
+	var counted []*state.StateNode
 	for _, node := range cluster.Nodes() {
 		// We only consider nodes that we own and are initialized towards the total.
 		// If a node is launched/registered, but not initialized, pods aren't scheduled
@@ -219,11 +267,12 @@ func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, c
 
 		nodePool := node.Labels()[v1.NodePoolLabelKey]
 		numNodes[nodePool]++
+		counted = append(counted, node)
 
 		// If the node satisfies one of the following, we subtract it from the allowed disruptions.
 		// 1. Has a NotReady conditiion
 		// 2. Is marked as disrupting
-		if cond := nodeutils.GetCondition(node.Node, corev1.NodeReady); cond.Status != corev1.ConditionTrue || node.MarkedForDeletion() {
+		if isUnavailable(node) {
 			disrupting[nodePool]++
 		}
 	}
@@ -233,7 +282,7 @@ func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, c
 	}
 	for _, nodePool := range nodePools {
 		allowedDisruptions := nodePool.MustGetAllowedDisruptions(clk, numNodes[nodePool.Name], reason)
-		disruptionBudgetMapping[nodePool.Name] = lo.Max([]int{allowedDisruptions - disrupting[nodePool.Name], 0})
+		disruptionBudgetMapping.NodePools[nodePool.Name] = lo.Max([]int{allowedDisruptions - disrupting[nodePool.Name], 0})
 		NodePoolAllowedDisruptions.Set(float64(allowedDisruptions), map[string]string{
 			metrics.NodePoolLabel: nodePool.Name, metrics.ReasonLabel: string(reason),
 		})
@@ -241,9 +290,35 @@ func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, c
 			recorder.Publish(disruptionevents.NodePoolBlockedForDisruptionReason(nodePool, reason))
 		}
 	}
+	nodeDisruptionBudgets := &v1alpha1.NodeDisruptionBudgetList{}
+	if err = kubeClient.List(ctx, nodeDisruptionBudgets); err != nil {
+		return disruptionBudgetMapping, fmt.Errorf("listing node disruption budgets, %w", err)
+	}
+	for _, ndb := range nodeDisruptionBudgets.Items {
+		selector, selectorErr := metav1.LabelSelectorAsSelector(&ndb.Spec.NodeSelector)
+		if selectorErr != nil {
+			// If the selector is misconfigured, fail closed for every node, since we don't know which nodes it's meant to protect
+			log.FromContext(ctx).WithValues("NodeDisruptionBudget", klog.KObj(&ndb)).Error(selectorErr, "failed parsing node selector")
+			selector = labels.Everything()
+		}
+		selected := lo.Filter(counted, func(n *state.StateNode, _ int) bool { return selector.Matches(labels.Set(n.Labels())) })
+		// This rounds up to the nearest whole number, the same as the budgets of a NodePool
+		allowedDisruptions, err := intstr.GetScaledValueFromIntOrPercent(&ndb.Spec.MaxUnavailable, len(selected), true)
+		if err != nil || selectorErr != nil {
+			allowedDisruptions = 0
+		}
+		disruptionBudgetMapping.NodeDisruptionBudgets[ndb.Name] = lo.Max([]int{allowedDisruptions - lo.CountBy(selected, isUnavailable), 0})
+		disruptionBudgetMapping.selectors[ndb.Name] = selector
+	}
 	return disruptionBudgetMapping, nil
 }
 
+// isUnavailable returns true if the node is NotReady or is already being disrupted
+func isUnavailable(node *state.StateNode) bool {
+	cond := nodeutils.GetCondition(node.Node, corev1.NodeReady)
+	return cond.Status != corev1.ConditionTrue || node.MarkedForDeletion()
+}
+
 // mapCandidates maps the list of proposed candidates with the current state
 func mapCandidates(proposed, current []*Candidate) []*Candidate {
 	proposedNames := sets.NewString(lo.Map(proposed, func(c *Candidate, i int) string { return c.Name() })...)
@@ -33,6 +33,7 @@ const (
 func init() {
 	ConsolidationTimeoutsTotal.Add(0, map[string]string{consolidationTypeLabel: MultiNodeConsolidationType})
 	ConsolidationTimeoutsTotal.Add(0, map[string]string{consolidationTypeLabel: SingleNodeConsolidationType})
+	ConsolidationTimeoutFallbacksTotal.Add(0, map[string]string{consolidationTypeLabel: MultiNodeConsolidationType})
 }
 
 var (
@@ -77,6 +78,16 @@ var (
 		},
 		[]string{consolidationTypeLabel},
 	)
+	ConsolidationTimeoutFallbacksTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "consolidation_timeout_fallbacks_total",
+			Help:      "Number of times the Consolidation algorithm reached a timeout without finding a command and fell back to consolidating a single node. Labeled by consolidation type.",
+		},
+		[]string{consolidationTypeLabel},
+	)
 	NodePoolAllowedDisruptions = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -29,12 +29,17 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	scheduler "sigs.k8s.io/karpenter/pkg/scheduling"
 )
 
-const MultiNodeConsolidationTimeoutDuration = 1 * time.Minute
 const MultiNodeConsolidationType = "multi"
 
+// MultiNodeConsolidationTimeoutDuration is the default time budget for a multi-node consolidation pass
+//
+// Deprecated: the time budget is configured with the --multi-node-consolidation-timeout option
+const MultiNodeConsolidationTimeoutDuration = 1 * time.Minute
+
 type MultiNodeConsolidation struct {
 	consolidation
 }
@@ -43,11 +48,11 @@ func NewMultiNodeConsolidation(consolidation consolidation) *MultiNodeConsolidat
 	return &MultiNodeConsolidation{consolidation: consolidation}
 }
 
-func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
+func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) (Command, scheduling.Results, error) {
 	if m.IsConsolidated() {
 		return Command{}, scheduling.Results{}, nil
 	}
-	candidates = m.sortCandidates(candidates)
+	candidates = m.sortCandidates(ctx, candidates)
 
 	// In order, filter out all candidates that would violate the budget.
 	// Since multi-node consolidation relies on the ordering of
@@ -59,9 +64,9 @@ func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 	disruptableCandidates := make([]*Candidate, 0, len(candidates))
 	constrainedByBudgets := false
 	for _, candidate := range candidates {
-		// If there's disruptions allowed for the candidate's nodepool,
+		// If the candidate's budgets allow a disruption,
 		// add it to the list of candidates, and decrement the budget.
-		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			constrainedByBudgets = true
 			continue
 		}
@@ -73,14 +78,14 @@ func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 		}
 		// set constrainedByBudgets to true if any node was a candidate but was constrained by a budget
 		disruptableCandidates = append(disruptableCandidates, candidate)
-		disruptionBudgetMapping[candidate.nodePool.Name]--
+		disruptionBudgetMapping.Consume(candidate)
 	}
 
 	// Only consider a maximum batch of 100 NodeClaims to save on computation.
 	// This could be further configurable in the future.
 	maxParallel := lo.Clamp(len(disruptableCandidates), 0, 100)
 
-	cmd, results, err := m.firstNConsolidationOption(ctx, disruptableCandidates, maxParallel)
+	cmd, results, timedOut, err := m.firstNConsolidationOption(ctx, disruptableCandidates, maxParallel)
 	if err != nil {
 		return Command{}, scheduling.Results{}, err
 	}
@@ -88,8 +93,9 @@ func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 	if cmd.Decision() == NoOpDecision {
 		// if there are no candidates because of a budget, don't mark
 		// as consolidated, as it's possible it should be consolidatable
-		// the next time we try to disrupt.
-		if !constrainedByBudgets {
+		// the next time we try to disrupt. The same goes for passes that
+		// ran out of time before considering every batch.
+		if !constrainedByBudgets && !timedOut {
 			m.markConsolidated()
 		}
 		return cmd, scheduling.Results{}, nil
@@ -106,11 +112,13 @@ func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionB
 }
 
 // firstNConsolidationOption looks at the first N NodeClaims to determine if they can all be consolidated at once.  The
-// NodeClaims are sorted by increasing disruption order which correlates to likelihood of being able to consolidate the node
-func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context, candidates []*Candidate, max int) (Command, scheduling.Results, error) {
+// NodeClaims are sorted by increasing disruption order which correlates to likelihood of being able to consolidate the node.
+// If the time budget for the pass runs out or the context is cancelled, this stops searching and returns the best command
+// found so far, falling back to consolidating the first candidate on its own if no multi-node command was found.
+func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context, candidates []*Candidate, max int) (Command, scheduling.Results, bool, error) {
 	// we always operate on at least two NodeClaims at once, for single NodeClaims standard consolidation will find all solutions
 	if len(candidates) < 2 {
-		return Command{}, scheduling.Results{}, nil
+		return Command{}, scheduling.Results{}, false, nil
 	}
 	min := 1
 	if len(candidates) <= max {
@@ -120,24 +128,25 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 	lastSavedCommand := Command{}
 	lastSavedResults := scheduling.Results{}
 	// Set a timeout
-	timeout := m.clock.Now().Add(MultiNodeConsolidationTimeoutDuration)
+	timeout := m.clock.Now().Add(options.FromContext(ctx).MultiNodeConsolidationTimeout)
 	// binary search to find the maximum number of NodeClaims we can terminate
 	for min <= max {
-		if m.clock.Now().After(timeout) {
+		if m.clock.Now().After(timeout) || ctx.Err() != nil {
 			ConsolidationTimeoutsTotal.Inc(map[string]string{consolidationTypeLabel: m.ConsolidationType()})
 			if lastSavedCommand.candidates == nil {
-				log.FromContext(ctx).V(1).Info(fmt.Sprintf("failed to find a multi-node consolidation after timeout, last considered batch had %d", (min+max)/2))
-			} else {
-				log.FromContext(ctx).V(1).Info(fmt.Sprintf("stopping multi-node consolidation after timeout, returning last valid command %s", lastSavedCommand))
+				log.FromContext(ctx).V(1).Info(fmt.Sprintf("failed to find a multi-node consolidation after timeout, last considered batch had %d, falling back to single-node consolidation", (min+max)/2))
+				cmd, results := m.singleNodeFallback(ctx, candidates[0])
+				return cmd, results, true, nil
 			}
-			return lastSavedCommand, lastSavedResults, nil
+			log.FromContext(ctx).V(1).Info(fmt.Sprintf("stopping multi-node consolidation after timeout, returning last valid command %s", lastSavedCommand))
+			return lastSavedCommand, lastSavedResults, true, nil
 		}
 		mid := (min + max) / 2
 		candidatesToConsolidate := candidates[0 : mid+1]
 
 		cmd, results, err := m.computeConsolidation(ctx, candidatesToConsolidate...)
 		if err != nil {
-			return Command{}, scheduling.Results{}, err
+			return Command{}, scheduling.Results{}, false, err
 		}
 
 		// ensure that the action is sensical for replacements, see explanation on filterOutSameType for why this is
@@ -149,7 +158,7 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 		}
 
 		// replacementHasValidInstanceTypes will be false if the replacement action has valid instance types remaining after filtering.
-		if replacementHasValidInstanceTypes || cmd.Decision() == DeleteDecision {
+		if (replacementHasValidInstanceTypes || cmd.Decision() == DeleteDecision) && m.justifiesLocalDataMove(ctx, cmd) {
 			// We can consolidate NodeClaims [0,mid]
 			lastSavedCommand = cmd
 			lastSavedResults = results
@@ -158,7 +167,50 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 			max = mid - 1
 		}
 	}
-	return lastSavedCommand, lastSavedResults, nil
+	return lastSavedCommand, lastSavedResults, false, nil
+}
+
+// singleNodeFallback computes the consolidation of the first candidate on its own, which is the candidate that's
+// cheapest to disrupt. This is a single simulation, so it's cheap compared to continuing the search.
+func (m *MultiNodeConsolidation) singleNodeFallback(ctx context.Context, candidate *Candidate) (Command, scheduling.Results) {
+	// the context may already be cancelled, so the fallback is computed without it being cancelled
+	ctx = context.WithoutCancel(ctx)
+	cmd, results, err := m.computeConsolidation(ctx, candidate)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed computing single-node consolidation fallback")
+		return Command{}, scheduling.Results{}
+	}
+	// Rebalances are only performed by single-node consolidation
+	if cmd.Decision() == NoOpDecision || cmd.Decision() == RebalanceDecision || !m.justifiesLocalDataMove(ctx, cmd) {
+		return Command{}, scheduling.Results{}
+	}
+	ConsolidationTimeoutFallbacksTotal.Inc(map[string]string{consolidationTypeLabel: m.ConsolidationType()})
+	return cmd, results
+}
+
+// justifiesLocalDataMove returns true if the hourly savings of the command outweigh the cost of moving the local data
+// of the pods that opted in through the karpenter.sh/costly-to-move-weight annotation. Replacements are priced at their
+// worst-case launch price since we don't know which of the instance types will be launched.
+func (m *MultiNodeConsolidation) justifiesLocalDataMove(ctx context.Context, cmd Command) bool {
+	moveCost := lo.SumBy(cmd.candidates, func(c *Candidate) float64 { return c.localDataMoveCost })
+	if moveCost == 0 {
+		return true
+	}
+	candidatePrice, err := getCandidatePrices(cmd.candidates)
+	if err != nil {
+		return false
+	}
+	replacementPrice := lo.SumBy(cmd.replacements, func(r *scheduling.NodeClaim) float64 {
+		return lo.Max(lo.Map(r.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) float64 {
+			return it.Offerings.Available().WorstLaunchPrice(r.Requirements)
+		}))
+	})
+	if savings := candidatePrice - replacementPrice; savings < moveCost*options.FromContext(ctx).LocalDataMoveThreshold {
+		log.FromContext(ctx).V(1).Info(fmt.Sprintf("skipping multi-node consolidation of %d candidates, savings of %.4f/hour don't justify moving local data with a cost of %.2f",
+			len(cmd.candidates), savings, moveCost))
+		return false
+	}
+	return true
 }
 
 // filterOutSameType filters out instance types that are more expensive than the cheapest instance type that is being
@@ -27,6 +27,7 @@ import (
 	"github.com/awslabs/operatorpkg/singleton"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -44,6 +45,8 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
@@ -51,6 +54,8 @@ const (
 	queueBaseDelay   = 1 * time.Second
 	queueMaxDelay    = 10 * time.Second
 	maxRetryDuration = 10 * time.Minute
+	// bulkDeleteParallelism is the number of candidates of a bulk delete command that are deleted concurrently
+	bulkDeleteParallelism = 20
 )
 
 type Command struct {
@@ -60,6 +65,9 @@ type Command struct {
 	id                types.UID           // used for log tracking
 	reason            v1.DisruptionReason // used for metrics
 	consolidationType string              // used for metrics
+	makeBeforeBreak   bool                // candidates are only cordoned once the replacements are initialized
+	bulkDelete        bool                // candidates are deleted in parallel without an event for each of them
+	candidatesDeleted bool                // candidates have been deleted and the command is waiting on their volumes to detach
 	lastError         error
 }
 
@@ -153,6 +161,20 @@ func NewCommand(replacements []string, candidates []*state.StateNode, id types.U
 	}
 }
 
+// WithMakeBeforeBreak defers cordoning the candidates until all replacements have initialized, so that the capacity
+// of the candidates remains available to new pods while the replacements launch
+func (c *Command) WithMakeBeforeBreak() *Command {
+	c.makeBeforeBreak = true
+	return c
+}
+
+// WithBulkDelete deletes the candidates in parallel rather than one at a time. The caller summarizes the deletion, so
+// no event is published for each of the candidates.
+func (c *Command) WithBulkDelete() *Command {
+	c.bulkDelete = true
+	return c
+}
+
 func (q *Queue) Register(_ context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("disruption.queue").
@@ -219,6 +241,9 @@ func (q *Queue) Reconcile(ctx context.Context) (reconcile.Result, error) {
 // timed out, this will return false.
 // nolint:gocyclo
 func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) error {
+	if cmd.candidatesDeleted {
+		return q.waitForVolumeDetachment(ctx, cmd)
+	}
 	if q.clock.Since(cmd.timeAdded) > maxRetryDuration {
 		return NewUnrecoverableError(fmt.Errorf("command reached timeout after %s", q.clock.Since(cmd.timeAdded)))
 	}
@@ -256,31 +281,91 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) error {
 		return fmt.Errorf("waiting for replacement initialization, %w", err)
 	}
 
+	// Make-before-break candidates weren't cordoned when the command was added, so cordon them now that
+	// the replacements have initialized
+	if cmd.makeBeforeBreak {
+		if err := state.RequireNoScheduleTaint(ctx, q.kubeClient, true, cmd.candidates...); err != nil {
+			return fmt.Errorf("tainting nodes with %s, %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err)
+		}
+	}
+
 	// All replacements have been provisioned.
 	// All we need to do now is get a successful delete call for each node claim,
 	// then the termination controller will handle the eventual deletion of the nodes.
-	var multiErr error
-	for i := range cmd.candidates {
+	deleteErrs := make([]error, len(cmd.candidates))
+	deleteCandidate := func(i int) {
 		candidate := cmd.candidates[i]
-		q.recorder.Publish(disruptionevents.Terminating(candidate.Node, candidate.NodeClaim, cmd.Reason())...)
-		if err := q.kubeClient.Delete(ctx, candidate.NodeClaim); err != nil {
-			multiErr = multierr.Append(multiErr, client.IgnoreNotFound(err))
+		if !cmd.bulkDelete {
+			q.recorder.Publish(disruptionevents.Terminating(candidate.Node, candidate.NodeClaim, cmd.Reason())...)
+		}
+		if err := nodeclaimutils.Delete(ctx, q.kubeClient, candidate.NodeClaim, v1.TerminationReasonForDisruption(cmd.reason)); err != nil {
+			deleteErrs[i] = client.IgnoreNotFound(err)
 		} else {
 			metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 				metrics.ReasonLabel:       pretty.ToSnakeCase(string(cmd.reason)),
-				metrics.NodePoolLabel:     cmd.candidates[i].NodeClaim.Labels[v1.NodePoolLabelKey],
-				metrics.CapacityTypeLabel: cmd.candidates[i].NodeClaim.Labels[v1.CapacityTypeLabelKey],
+				metrics.NodePoolLabel:     candidate.NodeClaim.Labels[v1.NodePoolLabelKey],
+				metrics.CapacityTypeLabel: candidate.NodeClaim.Labels[v1.CapacityTypeLabelKey],
 			})
 		}
 	}
+	if cmd.bulkDelete {
+		workqueue.ParallelizeUntil(ctx, bulkDeleteParallelism, len(cmd.candidates), deleteCandidate)
+	} else {
+		for i := range cmd.candidates {
+			deleteCandidate(i)
+		}
+	}
 	// If there were any deletion failures, we should requeue.
 	// In the case where we requeue, but the timeout for the command is reached, we'll mark this as a failure.
-	if multiErr != nil {
+	if multiErr := multierr.Combine(deleteErrs...); multiErr != nil {
 		return fmt.Errorf("terminating nodeclaims, %w", multiErr)
 	}
+	cmd.candidatesDeleted = true
+	return q.waitForVolumeDetachment(ctx, cmd)
+}
+
+// waitForVolumeDetachment waits for the volumes of the drained pods to detach from the candidates of a replace command,
+// in the same way that the termination of the candidates' nodes does. The pods that were evicted from the candidates can't start on the replacements
+// until their volumes detach, so the command isn't complete until then. This keeps the replacements from being
+// treated as settled capacity while their pods are still waiting on volumes. Once the candidates are deleted, timing
+// out completes the command rather than failing it since there's nothing left to roll back.
+func (q *Queue) waitForVolumeDetachment(ctx context.Context, cmd *Command) error {
+	if len(cmd.Replacements) == 0 || q.clock.Since(cmd.timeAdded) > maxRetryDuration {
+		return nil
+	}
+	var waitErrs []error
+	for _, candidate := range cmd.candidates {
+		attached, err := q.attachedVolumes(ctx, candidate)
+		if err != nil {
+			waitErrs = append(waitErrs, err)
+			continue
+		}
+		if len(attached) > 0 {
+			q.recorder.Publish(disruptionevents.WaitingOnVolumeDetachment(candidate.Node))
+			waitErrs = append(waitErrs, fmt.Errorf("node %s has attached volumes %s", candidate.Name(), strings.Join(attached, ",")))
+		}
+	}
+	if err := multierr.Combine(waitErrs...); err != nil {
+		return fmt.Errorf("waiting for volume detachment, %w", err)
+	}
 	return nil
 }
 
+// attachedVolumes returns the names of the PersistentVolumes that are still attached to the candidate's node and that
+// block the node's termination
+func (q *Queue) attachedVolumes(ctx context.Context, candidate *state.StateNode) ([]string, error) {
+	if candidate.Node == nil {
+		return nil, nil
+	}
+	volumeAttachments, err := nodeutils.GetBlockingVolumeAttachments(ctx, q.kubeClient, candidate.Node, q.clock)
+	if err != nil {
+		return nil, fmt.Errorf("listing volume attachments, %w", err)
+	}
+	return lo.Map(volumeAttachments, func(va *storagev1.VolumeAttachment, _ int) string {
+		return lo.FromPtr(va.Spec.Source.PersistentVolumeName)
+	}), nil
+}
+
 // Add adds commands to the Queue
 // Each command added to the queue should already be validated and ready for execution.
 func (q *Queue) Add(cmd *Command) error {
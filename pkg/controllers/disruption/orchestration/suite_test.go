@@ -25,6 +25,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
 
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
 
@@ -38,6 +39,7 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption/orchestration"
@@ -77,7 +79,7 @@ func TestAPIs(t *testing.T) {
 }
 
 var _ = BeforeSuite(func() {
-	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.VolumeAttachmentFieldIndexer(ctx)))
 	ctx = options.ToContext(ctx, test.Options())
 	fakeClock = clock.NewFakeClock(time.Now())
 	cloudProvider = fake.NewCloudProvider()
@@ -85,7 +87,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster)
 	recorder = test.NewEventRecorder()
-	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock)
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock, cloudprovider.NewUnhealthyOfferings(), cloudprovider.NewUnavailableOfferings(env.Client, fakeClock), scheduling.NewLabelAliases())
 	queue = NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
 })
 
@@ -235,6 +237,28 @@ var _ = Describe("Queue", func() {
 			// And expect the nodeClaim and node to be deleted
 			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
 		})
+		It("should only taint make-before-break candidates once the replacements are initialized", func() {
+			node1.Spec.Taints = nil
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type").WithMakeBeforeBreak()
+			Expect(queue.Add(cmd)).To(BeNil())
+			ExpectSingletonReconciled(ctx, queue)
+
+			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
+			Expect(node1.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController,
+				[]*corev1.Node{replacementNode}, []*v1.NodeClaim{replacementNodeClaim})
+			ExpectSingletonReconciled(ctx, queue)
+
+			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
+			Expect(node1.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+		})
 		It("should only finish a command when all replacements are initialized", func() {
 			ncName2 := test.RandomName()
 			replacements = []string{ncName, ncName2}
@@ -273,6 +297,47 @@ var _ = Describe("Queue", func() {
 			// And expect the nodeClaim and node to be deleted
 			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
 		})
+		It("should wait for volumes to detach from the candidates before finishing a replace command", func() {
+			pv := test.PersistentVolume()
+			va := test.VolumeAttachment(test.VolumeAttachmentOptions{NodeName: node1.Name, VolumeName: pv.Name})
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode, pv, va)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController,
+				[]*corev1.Node{node1, replacementNode}, []*v1.NodeClaim{nodeClaim1, replacementNodeClaim})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type")
+			Expect(queue.Add(cmd)).To(BeNil())
+			ExpectSingletonReconciled(ctx, queue)
+
+			// The candidate is deleted, but the command waits on the volume to detach
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.DeletionTimestamp.IsZero()).To(BeFalse())
+			Expect(recorder.DetectedEvent(disruptionevents.WaitingOnVolumeDetachment(node1).Message)).To(BeTrue())
+			Expect(queue.HasAny(stateNode.ProviderID())).To(BeTrue())
+
+			ExpectDeleted(ctx, env.Client, va)
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(queue.HasAny(stateNode.ProviderID())).To(BeFalse())
+		})
+		It("should finish a replace command once it times out waiting on volumes to detach", func() {
+			pv := test.PersistentVolume()
+			va := test.VolumeAttachment(test.VolumeAttachmentOptions{NodeName: node1.Name, VolumeName: pv.Name})
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode, pv, va)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController,
+				[]*corev1.Node{node1, replacementNode}, []*v1.NodeClaim{nodeClaim1, replacementNodeClaim})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "", "test-method", "fake-type"))).To(BeNil())
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(queue.HasAny(stateNode.ProviderID())).To(BeTrue())
+
+			fakeClock.Step(11 * time.Minute)
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(queue.HasAny(stateNode.ProviderID())).To(BeFalse())
+			// The candidate isn't untainted since it's already being deleted
+			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
+			Expect(node1.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		})
 		It("should not wait for replacements when none are needed", func() {
 			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
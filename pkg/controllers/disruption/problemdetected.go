@@ -0,0 +1,150 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+)
+
+// ProblemDetected is a subreconciler that replaces candidates with problems reported through node conditions or taints,
+// e.g. by node-problem-detector. Unlike drift, the problem is read directly from the node so that the node is replaced
+// as soon as the problem is reported.
+type ProblemDetected struct {
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+	recorder    events.Recorder
+}
+
+func NewProblemDetected(kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *ProblemDetected {
+	return &ProblemDetected{
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+		recorder:    recorder,
+	}
+}
+
+// ShouldDisrupt is a predicate used to filter candidates
+func (p *ProblemDetected) ShouldDisrupt(ctx context.Context, c *Candidate) bool {
+	_, ok := nodeProblemDetectedAt(ctx, c.Node)
+	return ok
+}
+
+// ComputeCommand generates a disruption command given candidates
+func (p *ProblemDetected) ComputeCommand(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) (Command, scheduling.Results, error) {
+	// replace the nodes that have had problems for the longest first
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		a, _ := nodeProblemDetectedAt(ctx, candidates[i].Node)
+		b, _ := nodeProblemDetectedAt(ctx, candidates[j].Node)
+		return a.Before(b)
+	})
+
+	// Empty candidates require no scheduling simulations, so disrupt all of them that the budgets allow at once
+	empty := make([]*Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if len(candidate.reschedulablePods) > 0 {
+			continue
+		}
+		if disruptionBudgetMapping.Allows(candidate) {
+			empty = append(empty, candidate)
+			disruptionBudgetMapping.Consume(candidate)
+		}
+	}
+	if len(empty) > 0 {
+		return Command{
+			candidates: empty,
+		}, scheduling.Results{}, nil
+	}
+
+	for _, candidate := range candidates {
+		// Commands only have one candidate, so we don't need to decrement the budget
+		if !disruptionBudgetMapping.Allows(candidate) {
+			continue
+		}
+		results, err := SimulateScheduling(ctx, p.kubeClient, p.cluster, p.provisioner, candidate)
+		if err != nil {
+			// if a candidate is now deleting, just retry
+			if errors.Is(err, errCandidateDeleting) {
+				continue
+			}
+			return Command{}, scheduling.Results{}, err
+		}
+		if !results.AllNonPendingPodsScheduled() {
+			p.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, pretty.Sentence(results.NonPendingPodSchedulingErrors()))...)
+			continue
+		}
+		return Command{
+			candidates:   []*Candidate{candidate},
+			replacements: results.NewNodeClaims,
+		}, results, nil
+	}
+	return Command{}, scheduling.Results{}, nil
+}
+
+func (p *ProblemDetected) Reason() v1.DisruptionReason {
+	return v1.DisruptionReasonProblemDetected
+}
+
+func (p *ProblemDetected) Class() string {
+	return EventualDisruptionClass
+}
+
+func (p *ProblemDetected) ConsolidationType() string {
+	return ""
+}
+
+// nodeProblemDetectedAt returns when the earliest of the node's problems was reported through one of the node
+// conditions or taints that are configured as node problems
+func nodeProblemDetectedAt(ctx context.Context, node *corev1.Node) (time.Time, bool) {
+	if node == nil {
+		return time.Time{}, false
+	}
+	conditionTypes := options.FromContext(ctx).NodeProblems.ConditionTypes
+	taintKeys := options.FromContext(ctx).NodeProblems.TaintKeys
+	var detectedAt []time.Time
+	for _, cond := range node.Status.Conditions {
+		if cond.Status == corev1.ConditionTrue && lo.Contains(conditionTypes, string(cond.Type)) {
+			detectedAt = append(detectedAt, cond.LastTransitionTime.Time)
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if lo.Contains(taintKeys, taint.Key) {
+			detectedAt = append(detectedAt, lo.FromPtr(taint.TimeAdded).Time)
+		}
+	}
+	if len(detectedAt) == 0 {
+		return time.Time{}, false
+	}
+	return lo.MinBy(detectedAt, func(a, b time.Time) bool { return a.Before(b) }), true
+}
@@ -0,0 +1,192 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("ProblemDetected", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeProblemConditions: []string{"KernelDeadlock", "ReadonlyFilesystem"}}))
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Disruption: v1.Disruption{
+					ConsolidateAfter: v1.MustParseNillableDuration("Never"),
+					Budgets: []v1.Budget{{
+						Nodes: "100%",
+					}},
+				},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+				Allocatable: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:  resource.MustParse("32"),
+					corev1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+	})
+	// reportProblem sets a node condition after the node is initialized, since initializing the node resets its conditions
+	reportProblem := func(condition corev1.NodeCondition) {
+		GinkgoHelper()
+		node = ExpectExists(ctx, env.Client, node)
+		node.Status.Conditions = append(node.Status.Conditions, condition)
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+	}
+	It("can delete nodes with a problem condition", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		reportProblem(corev1.NodeCondition{Type: "KernelDeadlock", Status: corev1.ConditionTrue, Reason: "DockerHung"})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		// Process the item so that the nodes can be deleted.
+		ExpectSingletonReconciled(ctx, queue)
+		// Cascade any deletion of the nodeClaim to the node
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+	})
+	It("can replace nodes with a problem condition", func() {
+		rs := test.ReplicaSet()
+		ExpectApplied(ctx, env.Client, rs)
+		Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         "apps/v1",
+						Kind:               "ReplicaSet",
+						Name:               rs.Name,
+						UID:                rs.UID,
+						Controller:         lo.ToPtr(true),
+						BlockOwnerDeletion: lo.ToPtr(true),
+					},
+				}}})
+		ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		reportProblem(corev1.NodeCondition{Type: "ReadonlyFilesystem", Status: corev1.ConditionTrue, Reason: "FilesystemIsReadOnly"})
+
+		// disruption won't delete the old nodeClaim until the new nodeClaim is ready
+		var wg sync.WaitGroup
+		ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		wg.Wait()
+
+		// Process the item so that the nodes can be deleted.
+		ExpectSingletonReconciled(ctx, queue)
+		// Cascade any deletion of the nodeClaim to the node
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Name).ToNot(Equal(nodeClaim.Name))
+	})
+	It("can delete nodes with a problem taint", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeProblemTaints: []string{"example.com/kernel-deadlock"}}))
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: "example.com/kernel-deadlock", Effect: corev1.TaintEffectNoSchedule})
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		ExpectSingletonReconciled(ctx, queue)
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+	})
+	It("should ignore nodes with a problem condition that isn't True", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		reportProblem(corev1.NodeCondition{Type: "KernelDeadlock", Status: corev1.ConditionFalse, Reason: "KernelHasNoDeadlock"})
+
+		fakeClock.Step(10 * time.Minute)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+	It("should ignore nodes with conditions that aren't configured as problems", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeProblemConditions: []string{"KernelDeadlock"}}))
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		reportProblem(corev1.NodeCondition{Type: "ReadonlyFilesystem", Status: corev1.ConditionTrue, Reason: "FilesystemIsReadOnly"})
+
+		fakeClock.Step(10 * time.Minute)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+	It("should ignore nodes with problem conditions when no conditions are configured", func() {
+		ctx = options.ToContext(ctx, test.Options())
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		reportProblem(corev1.NodeCondition{Type: "KernelDeadlock", Status: corev1.ConditionTrue, Reason: "DockerHung"})
+
+		fakeClock.Step(10 * time.Minute)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+	It("should respect the budgets for the ProblemDetected reason", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0", Reasons: []v1.DisruptionReason{v1.DisruptionReasonProblemDetected}}}
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		reportProblem(corev1.NodeCondition{Type: "KernelDeadlock", Status: corev1.ConditionTrue, Reason: "DockerHung"})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+})
@@ -0,0 +1,82 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+)
+
+// rebalanceCooldown is how long a rebalanced candidate stays tainted and is skipped by later rebalances, so that the
+// pods evicted from it can reschedule to other nodes rather than landing back on it
+const rebalanceCooldown = 5 * time.Minute
+
+// rebalances tracks the candidates that were recently rebalanced by their provider IDs
+type rebalances struct {
+	mu         sync.Mutex
+	clock      clock.Clock
+	rebalanced map[string]time.Time
+}
+
+func newRebalances(clk clock.Clock) *rebalances {
+	return &rebalances{
+		clock:      clk,
+		rebalanced: map[string]time.Time{},
+	}
+}
+
+// Add starts the cooldown of the candidates with the given provider IDs
+func (r *rebalances) Add(providerIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range providerIDs {
+		r.rebalanced[id] = r.clock.Now()
+	}
+}
+
+// Has returns true if the candidate with the given provider ID was rebalanced within the cooldown
+func (r *rebalances) Has(providerID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rebalanced, ok := r.rebalanced[providerID]
+	if !ok {
+		return false
+	}
+	if r.clock.Since(rebalanced) >= rebalanceCooldown {
+		delete(r.rebalanced, providerID)
+		return false
+	}
+	return true
+}
+
+// Prune drops the candidates whose cooldown elapsed and the candidates that are no longer in the cluster, so that
+// tracking them doesn't grow with every node that was ever rebalanced
+func (r *rebalances) Prune(providerIDs sets.Set[string]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, rebalanced := range r.rebalanced {
+		if !providerIDs.Has(id) || r.clock.Since(rebalanced) >= rebalanceCooldown {
+			delete(r.rebalanced, id)
+		}
+	}
+}
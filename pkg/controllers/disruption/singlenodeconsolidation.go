@@ -25,11 +25,16 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
-const SingleNodeConsolidationTimeoutDuration = 3 * time.Minute
 const SingleNodeConsolidationType = "single"
 
+// SingleNodeConsolidationTimeoutDuration is the default time budget for a single-node consolidation pass
+//
+// Deprecated: the time budget is configured with the --single-node-consolidation-timeout option
+const SingleNodeConsolidationTimeoutDuration = 3 * time.Minute
+
 // SingleNodeConsolidation is the consolidation controller that performs single-node consolidation.
 type SingleNodeConsolidation struct {
 	consolidation
@@ -41,16 +46,16 @@ func NewSingleNodeConsolidation(consolidation consolidation) *SingleNodeConsolid
 
 // ComputeCommand generates a disruption command given candidates
 // nolint:gocyclo
-func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, scheduling.Results, error) {
+func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) (Command, scheduling.Results, error) {
 	if s.IsConsolidated() {
 		return Command{}, scheduling.Results{}, nil
 	}
-	candidates = s.sortCandidates(candidates)
+	candidates = s.sortCandidates(ctx, candidates)
 
 	v := NewValidation(s.clock, s.cluster, s.kubeClient, s.provisioner, s.cloudProvider, s.recorder, s.queue, s.Reason())
 
 	// Set a timeout
-	timeout := s.clock.Now().Add(SingleNodeConsolidationTimeoutDuration)
+	timeout := s.clock.Now().Add(options.FromContext(ctx).SingleNodeConsolidationTimeout)
 	constrainedByBudgets := false
 
 	// binary search to find the maximum number of NodeClaims we can terminate
@@ -58,7 +63,7 @@ func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, disruption
 		// If the disruption budget doesn't allow this candidate to be disrupted,
 		// continue to the next candidate. We don't need to decrement any budget
 		// counter since single node consolidation commands can only have one candidate.
-		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			constrainedByBudgets = true
 			continue
 		}
@@ -68,7 +73,8 @@ func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, disruption
 		if len(candidate.reschedulablePods) == 0 {
 			continue
 		}
-		if s.clock.Now().After(timeout) {
+		// The remaining candidates are evaluated in a later pass, so we don't mark the cluster as consolidated
+		if s.clock.Now().After(timeout) || ctx.Err() != nil {
 			ConsolidationTimeoutsTotal.Inc(map[string]string{consolidationTypeLabel: s.ConsolidationType()})
 			log.FromContext(ctx).V(1).Info(fmt.Sprintf("abandoning single-node consolidation due to timeout after evaluating %d candidates", i))
 			return Command{}, scheduling.Results{}, nil
@@ -27,6 +27,8 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	clockiface "k8s.io/utils/clock"
 
+	karpv1alpha1 "sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 
@@ -72,6 +74,7 @@ var nodeClaimStateController *informer.NodeClaimController
 var fakeClock *clock.FakeClock
 var recorder *test.EventRecorder
 var queue *orchestration.Queue
+var evictionQueue *terminator.Queue
 var allKnownDisruptionReasons []v1.DisruptionReason
 
 var onDemandInstances []*cloudprovider.InstanceType
@@ -96,9 +99,10 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster)
 	recorder = test.NewEventRecorder()
-	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock)
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock, cloudprovider.NewUnhealthyOfferings(), cloudprovider.NewUnavailableOfferings(env.Client, fakeClock), scheduling.NewLabelAliases())
 	queue = NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
-	disruptionController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue)
+	evictionQueue = terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock)
+	disruptionController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue, evictionQueue)
 })
 
 var _ = AfterSuite(func() {
@@ -118,6 +122,7 @@ var _ = BeforeEach(func() {
 	fakeClock.SetTime(time.Now())
 	cluster.Reset()
 	*queue = lo.FromPtr(NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov))
+	*evictionQueue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
 	cluster.MarkUnconsolidated()
 
 	// Reset Feature Flags to test defaults
@@ -163,6 +168,8 @@ var _ = AfterEach(func() {
 
 	// Reset the metrics collectors
 	disruption.DecisionsPerformedTotal.Reset()
+	disruption.ConsolidationTimeoutsTotal.Reset()
+	disruption.ConsolidationTimeoutFallbacksTotal.Reset()
 })
 
 var _ = Describe("Simulate Scheduling", func() {
@@ -661,7 +668,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
 			// This should not bring in the unmanaged node.
-			Expect(budgets[nodePool.Name]).To(Equal(10))
+			Expect(budgets.NodePools[nodePool.Name]).To(Equal(10))
 		}
 	})
 	It("should not consider nodes that are not initialized as part of disruption count", func() {
@@ -692,7 +699,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
 			// This should not bring in the uninitialized node.
-			Expect(budgets[nodePool.Name]).To(Equal(10))
+			Expect(budgets.NodePools[nodePool.Name]).To(Equal(10))
 		}
 	})
 	It("should not consider nodes that have the terminating status condition as part of disruption count", func() {
@@ -724,7 +731,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
 			// This should not bring in the terminating node.
-			Expect(budgets[nodePool.Name]).To(Equal(10))
+			Expect(budgets.NodePools[nodePool.Name]).To(Equal(10))
 		}
 	})
 	It("should not return a negative disruption value", func() {
@@ -745,7 +752,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		for _, reason := range allKnownDisruptionReasons {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
-			Expect(budgets[nodePool.Name]).To(Equal(0))
+			Expect(budgets.NodePools[nodePool.Name]).To(Equal(0))
 		}
 	})
 	It("should consider nodes with a deletion timestamp set and MarkedForDeletion to the disruption count", func() {
@@ -769,7 +776,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		for _, reason := range allKnownDisruptionReasons {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
-			Expect(budgets[nodePool.Name]).To(Equal(8))
+			Expect(budgets.NodePools[nodePool.Name]).To(Equal(8))
 		}
 	})
 	It("should consider not ready nodes to the disruption count", func() {
@@ -790,9 +797,61 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		for _, reason := range allKnownDisruptionReasons {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
-			Expect(budgets[nodePool.Name]).To(Equal(8))
+			Expect(budgets.NodePools[nodePool.Name]).To(Equal(8))
 		}
 	})
+	Context("NodeDisruptionBudgets", func() {
+		It("should compute allowed disruptions for the nodes selected by a node disruption budget", func() {
+			for i := range nodes[:4] {
+				nodes[i].Labels["app"] = "database"
+				ExpectApplied(ctx, env.Client, nodes[i])
+			}
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ndb := &karpv1alpha1.NodeDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "database"},
+				Spec: karpv1alpha1.NodeDisruptionBudgetSpec{
+					NodeSelector:   metav1.LabelSelector{MatchLabels: map[string]string{"app": "database"}},
+					MaxUnavailable: intstr.FromString("50%"),
+				},
+			}
+			ExpectApplied(ctx, env.Client, ndb)
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+			Expect(err).To(Succeed())
+			Expect(budgets.NodeDisruptionBudgets[ndb.Name]).To(Equal(2))
+		})
+		It("should subtract the selected nodes that are already unavailable", func() {
+			ndb := &karpv1alpha1.NodeDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "all"},
+				Spec: karpv1alpha1.NodeDisruptionBudgetSpec{
+					MaxUnavailable: intstr.FromInt32(3),
+				},
+			}
+			ExpectApplied(ctx, env.Client, ndb)
+			ExpectMakeNodesNotReady(ctx, env.Client, nodes[0])
+			cluster.MarkForDeletion(nodeClaims[1].Status.ProviderID)
+			for _, i := range nodes {
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(i))
+			}
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+			Expect(err).To(Succeed())
+			Expect(budgets.NodeDisruptionBudgets[ndb.Name]).To(Equal(1))
+		})
+		It("should block all disruptions when a node disruption budget has an invalid selector", func() {
+			ndb := &karpv1alpha1.NodeDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+				Spec: karpv1alpha1.NodeDisruptionBudgetSpec{
+					NodeSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "app", Operator: metav1.LabelSelectorOpIn},
+					}},
+					MaxUnavailable: intstr.FromInt32(3),
+				},
+			}
+			ExpectApplied(ctx, env.Client, ndb)
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+			Expect(err).To(Succeed())
+			Expect(budgets.NodeDisruptionBudgets[ndb.Name]).To(Equal(0))
+		})
+	})
 })
 
 var _ = Describe("Pod Eviction Cost", func() {
@@ -974,6 +1033,199 @@ var _ = Describe("Candidate Filtering", func() {
 		Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(pod))))
 		Expect(recorder.DetectedEvent(fmt.Sprintf(`Pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(pod)))).To(BeTrue())
 	})
+	Context("Job Completion", func() {
+		var nodeClaim *v1.NodeClaim
+		var node *corev1.Node
+		var jobPod func(time.Time) *corev1.Pod
+		BeforeEach(func() {
+			nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+			})
+			jobPod = func(completionTime time.Time) *corev1.Pod {
+				return test.Pod(test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							v1.JobExpectedCompletionTimeAnnotationKey: completionTime.Format(time.RFC3339),
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "batch/v1",
+								Kind:               "Job",
+								Name:               "test-job",
+								UID:                "test-job-uid",
+								Controller:         lo.ToPtr(true),
+								BlockOwnerDeletion: lo.ToPtr(true),
+							},
+						},
+					},
+				})
+			}
+		})
+		It("should not consider candidates with Job pods that are expected to complete within the JobCompletionGracePeriod", func() {
+			nodePool.Spec.Disruption.JobCompletionGracePeriod = &metav1.Duration{Duration: time.Hour}
+			pod := jobPod(fakeClock.Now().Add(30 * time.Minute))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.EventualDisruptionClass)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("waiting on job pod %q to complete", client.ObjectKeyFromObject(pod))))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Waiting on job pod %q to complete", client.ObjectKeyFromObject(pod)))).To(BeTrue())
+		})
+		It("should consider candidates with Job pods that aren't expected to complete within the JobCompletionGracePeriod", func() {
+			nodePool.Spec.Disruption.JobCompletionGracePeriod = &metav1.Duration{Duration: time.Hour}
+			pod := jobPod(fakeClock.Now().Add(2 * time.Hour))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should consider candidates with Job pods that are expected to complete soon when the JobCompletionGracePeriod isn't set", func() {
+			pod := jobPod(fakeClock.Now().Add(time.Minute))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should use the Job pod's activeDeadlineSeconds when it isn't annotated", func() {
+			nodePool.Spec.Disruption.JobCompletionGracePeriod = &metav1.Duration{Duration: time.Hour}
+			pod := jobPod(time.Time{})
+			delete(pod.Annotations, v1.JobExpectedCompletionTimeAnnotationKey)
+			pod.Spec.ActiveDeadlineSeconds = lo.ToPtr(int64(600))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			pod.Status.StartTime = &metav1.Time{Time: fakeClock.Now()}
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("waiting on job pod %q to complete", client.ObjectKeyFromObject(pod))))
+		})
+	})
+	Context("Cluster Autoscaler Annotations", func() {
+		var nodeClaim *v1.NodeClaim
+		var node *corev1.Node
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{HonorClusterAutoscalerAnnotations: lo.ToPtr(true)}))
+			nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+			})
+		})
+		It("should not consider candidates that have safe-to-evict=false pods scheduled", func() {
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.ClusterAutoscalerSafeToEvictAnnotationKey: "false",
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q has "cluster-autoscaler.kubernetes.io/safe-to-evict=false" annotation`, client.ObjectKeyFromObject(pod))))
+		})
+		It("should not consider candidates that have pods with local storage scheduled", func() {
+			pod := test.Pod()
+			pod.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf(`pod %q has local storage volumes [scratch] that aren't safe to evict`, client.ObjectKeyFromObject(pod))))
+		})
+		It("should consider candidates that have pods with local storage that is safe to evict", func() {
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.ClusterAutoscalerSafeToEvictLocalVolumesAnnotationKey: "scratch",
+					},
+				},
+			})
+			pod.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should consider candidates that have safe-to-evict=false daemonset pods scheduled", func() {
+			daemonSet := test.DaemonSet()
+			ExpectApplied(ctx, env.Client, daemonSet)
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.ClusterAutoscalerSafeToEvictAnnotationKey: "false",
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "apps/v1",
+							Kind:       "DaemonSet",
+							Name:       daemonSet.Name,
+							UID:        daemonSet.UID,
+							Controller: lo.ToPtr(true),
+						},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should ignore the annotations when they aren't honored", func() {
+			ctx = options.ToContext(ctx, test.Options())
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.ClusterAutoscalerSafeToEvictAnnotationKey: "false",
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			Expect(cluster.Nodes()).To(HaveLen(1))
+			_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.Nodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
 	It("should consider candidates that have do-not-disrupt pods scheduled with a terminationGracePeriod set for eventual disruption", func() {
 		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -47,7 +47,7 @@ const (
 
 type Method interface {
 	ShouldDisrupt(context.Context, *Candidate) bool
-	ComputeCommand(context.Context, map[string]int, ...*Candidate) (Command, scheduling.Results, error)
+	ComputeCommand(context.Context, DisruptionBudgetMapping, ...*Candidate) (Command, scheduling.Results, error)
 	Reason() v1.DisruptionReason
 	Class() string
 	ConsolidationType() string
@@ -65,6 +65,9 @@ type Candidate struct {
 	capacityType      string
 	disruptionCost    float64
 	reschedulablePods []*corev1.Pod
+	// localDataMoveCost is the cost of moving the local data of the reschedulable pods that opted in through the
+	// karpenter.sh/costly-to-move-weight annotation
+	localDataMoveCost float64
 }
 
 //nolint:gocyclo
@@ -104,21 +107,56 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 			return nil, err
 		}
 	}
+	// Give Jobs that are expected to complete within the NodePool's JobCompletionGracePeriod the chance to complete
+	if p, ok := completingJobPod(clk, nodePool, pods); ok {
+		err = fmt.Errorf("waiting on job pod %q to complete", client.ObjectKeyFromObject(p))
+		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, pretty.Sentence(err.Error()))...)
+		return nil, err
+	}
+	reschedulablePods := lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return pod.IsReschedulable(p) })
 	return &Candidate{
 		StateNode:         node.DeepCopy(),
 		instanceType:      instanceType,
 		nodePool:          nodePool,
 		capacityType:      node.Labels()[v1.CapacityTypeLabelKey],
 		zone:              node.Labels()[corev1.LabelTopologyZone],
-		reschedulablePods: lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return pod.IsReschedulable(p) }),
+		reschedulablePods: reschedulablePods,
 		// We get the disruption cost from all pods in the candidate, not just the reschedulable pods
 		disruptionCost: disruptionutils.ReschedulingCost(ctx, pods) * disruptionutils.LifetimeRemaining(clk, nodePool, node.NodeClaim),
+		localDataMoveCost: lo.SumBy(reschedulablePods, func(p *corev1.Pod) float64 {
+			return disruptionutils.LocalDataMoveCost(ctx, p, node.PodLocalData(client.ObjectKeyFromObject(p)))
+		}),
 	}, nil
 }
 
+// completingJobPod returns an active Job pod on the candidate that's expected to complete within the NodePool's
+// JobCompletionGracePeriod
+func completingJobPod(clk clock.Clock, nodePool *v1.NodePool, pods []*corev1.Pod) (*corev1.Pod, bool) {
+	if nodePool.Spec.Disruption.JobCompletionGracePeriod == nil {
+		return nil, false
+	}
+	return lo.Find(pods, func(p *corev1.Pod) bool {
+		if !pod.IsOwnedByJob(p) || !pod.IsActive(p) {
+			return false
+		}
+		completionTime, ok := pod.ExpectedCompletionTime(p)
+		return ok && completionTime.After(clk.Now()) && completionTime.Sub(clk.Now()) <= nodePool.Spec.Disruption.JobCompletionGracePeriod.Duration
+	})
+}
+
+// withinMinNodeLifetime returns true if the candidate was launched more recently than its NodePool's MinNodeLifetime
+func withinMinNodeLifetime(clk clock.Clock, c *Candidate) bool {
+	if c.nodePool.Spec.Disruption.MinNodeLifetime == nil {
+		return false
+	}
+	return clk.Since(c.NodeClaim.CreationTimestamp.Time) < c.nodePool.Spec.Disruption.MinNodeLifetime.Duration
+}
+
 type Command struct {
 	candidates   []*Candidate
 	replacements []*scheduling.NodeClaim
+	// evictions are the pods that a rebalance evicts from its candidate. The candidate itself isn't disrupted.
+	evictions []*corev1.Pod
 }
 
 type Decision string
@@ -127,10 +165,14 @@ var (
 	NoOpDecision    Decision = "no-op"
 	ReplaceDecision Decision = "replace"
 	DeleteDecision  Decision = "delete"
+	// RebalanceDecision partially drains a candidate, evicting only the pods that can reschedule to other nodes
+	RebalanceDecision Decision = "rebalance"
 )
 
 func (c Command) Decision() Decision {
 	switch {
+	case len(c.candidates) > 0 && len(c.evictions) > 0:
+		return RebalanceDecision
 	case len(c.candidates) > 0 && len(c.replacements) > 0:
 		return ReplaceDecision
 	case len(c.candidates) > 0 && len(c.replacements) == 0:
@@ -142,6 +184,10 @@ func (c Command) Decision() Decision {
 
 func (c Command) String() string {
 	var buf bytes.Buffer
+	if c.Decision() == RebalanceDecision {
+		fmt.Fprintf(&buf, "%s, evicting %d of %d pods from %s", c.Decision(), len(c.evictions), len(c.candidates[0].reschedulablePods), c.candidates[0].Name())
+		return buf.String()
+	}
 	podCount := lo.Reduce(c.candidates, func(_ int, cd *Candidate, _ int) int { return len(cd.reschedulablePods) }, 0)
 	fmt.Fprintf(&buf, "%s, terminating %d nodes (%d pods) ", c.Decision(), len(c.candidates), podCount)
 	for i, old := range c.candidates {
@@ -157,6 +203,7 @@ func (c Command) String() string {
 	}
 	odNodeClaims := 0
 	spotNodeClaims := 0
+	reservedNodeClaims := 0
 	for _, nodeClaim := range c.replacements {
 		ct := nodeClaim.Requirements.Get(v1.CapacityTypeLabelKey)
 		if ct.Has(v1.CapacityTypeOnDemand) {
@@ -165,9 +212,18 @@ func (c Command) String() string {
 		if ct.Has(v1.CapacityTypeSpot) {
 			spotNodeClaims++
 		}
+		if ct.Has(v1.CapacityTypeReserved) {
+			reservedNodeClaims++
+		}
 	}
 	// Print list of instance types for the first replacements.
 	if len(c.replacements) > 1 {
+		if reservedNodeClaims > 0 {
+			fmt.Fprintf(&buf, " and replacing with %d reserved, %d spot and %d on-demand, from types %s",
+				reservedNodeClaims, spotNodeClaims, odNodeClaims,
+				scheduling.InstanceTypeList(c.replacements[0].InstanceTypeOptions))
+			return buf.String()
+		}
 		fmt.Fprintf(&buf, " and replacing with %d spot and %d on-demand, from types %s",
 			spotNodeClaims, odNodeClaims,
 			scheduling.InstanceTypeList(c.replacements[0].InstanceTypeOptions))
@@ -23,8 +23,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
@@ -32,6 +37,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
 type ValidationError struct {
@@ -80,11 +86,24 @@ func NewValidation(clk clock.Clock, cluster *state.Cluster, kubeClient client.Cl
 	}
 }
 
-func (v *Validation) IsValid(ctx context.Context, cmd Command, validationPeriod time.Duration) error {
-	var err error
+func (v *Validation) IsValid(ctx context.Context, cmd Command, validationPeriod time.Duration) (err error) {
 	v.once.Do(func() {
 		v.start = v.clock.Now()
 	})
+	// Steer the kube-scheduler away from the candidates while we validate so that newly created pods don't land on them
+	// and invalidate the decision. If the command is executed, the candidates are tainted with the disrupted taint next.
+	stateNodes := lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode })
+	if err = state.RequireDisruptionCandidateTaint(ctx, v.kubeClient, true, stateNodes...); err != nil {
+		return fmt.Errorf("tainting nodes with %s, %w", pretty.Taint(v1.DisruptionCandidatePreferNoScheduleTaint), err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if untaintErr := state.RequireDisruptionCandidateTaint(ctx, v.kubeClient, false, stateNodes...); untaintErr != nil {
+			log.FromContext(ctx).Error(untaintErr, fmt.Sprintf("failed removing taint %s from nodes", pretty.Taint(v1.DisruptionCandidatePreferNoScheduleTaint)))
+		}
+	}()
 
 	waitDuration := validationPeriod - v.clock.Since(v.start)
 	if waitDuration > 0 {
@@ -139,10 +158,10 @@ func (v *Validation) ValidateCandidates(ctx context.Context, candidates ...*Cand
 		if v.cluster.IsNodeNominated(vc.ProviderID()) {
 			return nil, NewValidationError(fmt.Errorf("a candidate was nominated during validation"))
 		}
-		if disruptionBudgetMapping[vc.nodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(vc) {
 			return nil, NewValidationError(fmt.Errorf("a candidate can no longer be disrupted without violating budgets"))
 		}
-		disruptionBudgetMapping[vc.nodePool.Name]--
+		disruptionBudgetMapping.Consume(vc)
 	}
 	return validatedCandidates, nil
 }
@@ -162,6 +181,14 @@ func (v *Validation) ValidateCommand(ctx context.Context, cmd Command, candidate
 	if err != nil {
 		return fmt.Errorf("simluating scheduling, %w", err)
 	}
+	// A rebalance is valid as long as each of the pods that it evicts can still reschedule to an existing node
+	if cmd.Decision() == RebalanceDecision {
+		movable := sets.New(lo.Map(movablePods(candidates[0], results), func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
+		if !lo.EveryBy(cmd.evictions, func(p *corev1.Pod) bool { return movable.Has(p.UID) }) {
+			return NewValidationError(fmt.Errorf("scheduling simulation produced new results"))
+		}
+		return nil
+	}
 	if !results.AllNonPendingPodsScheduled() {
 		return NewValidationError(errors.New(results.NonPendingPodSchedulingErrors()))
 	}
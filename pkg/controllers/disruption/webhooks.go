@@ -0,0 +1,226 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+const (
+	// WebhookSignatureHeader is set to the hex encoded HMAC-SHA256 of the request body when a signing key is configured
+	WebhookSignatureHeader = "X-Karpenter-Signature"
+	// WebhookQueueSize is the number of notifications that can be waiting to be sent before new notifications are
+	// dropped
+	WebhookQueueSize = 100
+	webhookTimeout   = 5 * time.Second
+	webhookAttempts  = 3
+)
+
+var (
+	// WebhookRetryDelay is the delay between attempts to notify a webhook. This is a var so that it can be shortened in
+	// testing.
+	WebhookRetryDelay = time.Second
+	// WebhookAckTimeout bounds how long the disruption loop waits on the webhooks to acknowledge a disruption, across
+	// every webhook and retry, so that an unresponsive webhook can't stall disruption. This is a var so that it can be
+	// shortened in testing.
+	WebhookAckTimeout = 15 * time.Second
+)
+
+// Notification is the JSON body that is POSTed to the disruption webhooks before a disruption is performed
+type Notification struct {
+	CommandID string `json:"commandID"`
+	Reason    string `json:"reason"`
+	Decision  string `json:"decision"`
+	// ETA is the earliest time that the nodes will start draining. Nodes that are replaced only drain once their
+	// replacements are initialized.
+	ETA          time.Time          `json:"eta"`
+	Nodes        []NotificationNode `json:"nodes"`
+	Replacements int                `json:"replacements"`
+}
+
+type NotificationNode struct {
+	Name      string   `json:"name"`
+	NodeClaim string   `json:"nodeClaim"`
+	NodePool  string   `json:"nodePool"`
+	Pods      []string `json:"pods"`
+}
+
+func NewNotification(commandID types.UID, m Method, cmd Command, eta time.Time) Notification {
+	return Notification{
+		CommandID: string(commandID),
+		Reason:    strings.ToLower(string(m.Reason())),
+		Decision:  string(cmd.Decision()),
+		ETA:       eta,
+		Nodes: lo.Map(cmd.candidates, func(c *Candidate, _ int) NotificationNode {
+			// A rebalance only moves some of the candidate's pods
+			pods := lo.Ternary(cmd.Decision() == RebalanceDecision, cmd.evictions, c.reschedulablePods)
+			return NotificationNode{
+				Name:      c.Name(),
+				NodeClaim: c.NodeClaim.Name,
+				NodePool:  c.Labels()[v1.NodePoolLabelKey],
+				Pods: lo.Map(pods, func(p *corev1.Pod, _ int) string {
+					return client.ObjectKeyFromObject(p).String()
+				}),
+			}
+		}),
+		Replacements: len(cmd.replacements),
+	}
+}
+
+// Webhooks notifies external systems, e.g. change management tooling, of disruptions before they're performed
+type Webhooks struct {
+	httpClient *http.Client
+	queue      chan delivery
+}
+
+// delivery is a marshaled notification along with the options that were configured when it was queued
+type delivery struct {
+	commandID  string
+	urls       []string
+	signingKey string
+	body       []byte
+}
+
+func NewWebhooks(httpClient *http.Client) *Webhooks {
+	return &Webhooks{httpClient: httpClient, queue: make(chan delivery, WebhookQueueSize)}
+}
+
+// Start sends the queued notifications until the context is cancelled
+func (w *Webhooks) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d := <-w.queue:
+			if err := w.deliver(ctx, d); err != nil {
+				log.FromContext(ctx).WithValues("command-id", d.commandID).Error(err, "failed notifying disruption webhooks")
+			}
+		}
+	}
+}
+
+// Notify POSTs the notification to each of the configured webhooks, retrying failed requests. When the webhooks are
+// required to acknowledge the disruption, the notification is sent synchronously and an error is returned if any
+// webhook doesn't respond with a 2xx status code within the WebhookAckTimeout. Otherwise, the notification is queued
+// so that slow webhooks don't block disruption, and an error is only returned if the queue is full.
+func (w *Webhooks) Notify(ctx context.Context, n Notification) error {
+	urls := sets.List(sets.New(lo.Compact(lo.Map(strings.Split(options.FromContext(ctx).DisruptionWebhookURLs, ","), func(s string, _ int) string {
+		return strings.TrimSpace(s)
+	}))...))
+	if len(urls) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("marshaling notification, %w", err)
+	}
+	d := delivery{
+		commandID:  n.CommandID,
+		urls:       urls,
+		signingKey: options.FromContext(ctx).DisruptionWebhookSigningKey,
+		body:       body,
+	}
+	if options.FromContext(ctx).DisruptionWebhookRequireAck {
+		ctx, cancel := context.WithTimeout(ctx, WebhookAckTimeout)
+		defer cancel()
+		return w.deliver(ctx, d)
+	}
+	select {
+	case w.queue <- d:
+		return nil
+	default:
+		return errors.New("webhook queue is full, dropping notification")
+	}
+}
+
+// deliver notifies the webhooks in parallel so that a slow webhook doesn't use up the time that the others have to
+// respond
+func (w *Webhooks) deliver(ctx context.Context, d delivery) error {
+	errs := make([]error, len(d.urls))
+	wg := sync.WaitGroup{}
+	for i := range d.urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := retry.Do(func() error { return w.post(ctx, d.urls[i], d.signingKey, d.body) },
+				retry.Context(ctx),
+				retry.Attempts(webhookAttempts),
+				retry.Delay(WebhookRetryDelay),
+				retry.LastErrorOnly(true),
+			); err != nil {
+				errs[i] = fmt.Errorf("notifying %s, %w", d.urls[i], err)
+				return
+			}
+			log.FromContext(ctx).WithValues("url", d.urls[i], "command-id", d.commandID).V(1).Info("notified disruption webhook")
+		}()
+	}
+	wg.Wait()
+	return multierr.Combine(errs...)
+}
+
+func (w *Webhooks) post(ctx context.Context, url, signingKey string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Unrecoverable(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signingKey != "" {
+		req.Header.Set(WebhookSignatureHeader, Sign([]byte(signingKey), body))
+	}
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body, which receivers can use to verify that the notification was
+// sent by Karpenter
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
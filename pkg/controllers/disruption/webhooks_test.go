@@ -0,0 +1,228 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("Webhooks", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	var server *httptest.Server
+	var mu sync.Mutex
+	var statusCode int
+	var unresponsive bool
+	var notifications []disruption.Notification
+	var signatures []string
+
+	BeforeEach(func() {
+		disruption.WebhookRetryDelay = time.Millisecond
+		statusCode = http.StatusOK
+		unresponsive = false
+		notifications = nil
+		signatures = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			mu.Lock()
+			if unresponsive {
+				mu.Unlock()
+				<-r.Context().Done()
+				return
+			}
+			defer mu.Unlock()
+			body, err := io.ReadAll(r.Body)
+			Expect(err).ToNot(HaveOccurred())
+			n := disruption.Notification{}
+			Expect(json.Unmarshal(body, &n)).To(Succeed())
+			notifications = append(notifications, n)
+			signatures = append(signatures, r.Header.Get(disruption.WebhookSignatureHeader))
+			if r.Header.Get(disruption.WebhookSignatureHeader) != "" {
+				Expect(r.Header.Get(disruption.WebhookSignatureHeader)).To(Equal(disruption.Sign([]byte("test-signing-key"), body)))
+			}
+			w.WriteHeader(statusCode)
+		}))
+		DeferCleanup(server.Close)
+
+		// The webhooks are sent from the queue by a runnable that the manager would otherwise start
+		webhooksCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			defer GinkgoRecover()
+			Expect(disruptionController.Webhooks().Start(webhooksCtx)).To(Succeed())
+		}()
+		DeferCleanup(cancel)
+
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Disruption: v1.Disruption{
+					ConsolidateAfter:    v1.MustParseNillableDuration("0s"),
+					ConsolidationPolicy: v1.ConsolidationPolicyWhenEmpty,
+					Budgets:             []v1.Budget{{Nodes: "100%"}},
+				},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: leastExpensiveSpotInstance.Name,
+					v1.CapacityTypeLabelKey:        leastExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       leastExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:  resource.MustParse("32"),
+					corev1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+	})
+	It("should notify the webhooks of a disruption before it's performed", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			DisruptionWebhookURLs:       lo.ToPtr(server.URL),
+			DisruptionWebhookSigningKey: lo.ToPtr("test-signing-key"),
+		}))
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		fakeClock.Step(10 * time.Minute)
+		wg := sync.WaitGroup{}
+		ExpectToWait(fakeClock, &wg)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		wg.Wait()
+		Eventually(func(g Gomega) {
+			mu.Lock()
+			defer mu.Unlock()
+			g.Expect(notifications).To(HaveLen(1))
+		}).Should(Succeed())
+		Expect(signatures[0]).ToNot(BeEmpty())
+		Expect(notifications[0].Reason).To(Equal("empty"))
+		Expect(notifications[0].Decision).To(Equal(string(disruption.DeleteDecision)))
+		Expect(notifications[0].Nodes).To(ConsistOf(disruption.NotificationNode{
+			Name:      node.Name,
+			NodeClaim: nodeClaim.Name,
+			NodePool:  nodePool.Name,
+		}))
+
+		ExpectSingletonReconciled(ctx, queue)
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+	})
+	It("should disrupt when a webhook fails if acknowledgement isn't required", func() {
+		statusCode = http.StatusInternalServerError
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionWebhookURLs: lo.ToPtr(server.URL)}))
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		fakeClock.Step(10 * time.Minute)
+		wg := sync.WaitGroup{}
+		ExpectToWait(fakeClock, &wg)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		wg.Wait()
+		// The webhook is retried before giving up
+		Eventually(func(g Gomega) {
+			mu.Lock()
+			defer mu.Unlock()
+			g.Expect(notifications).To(HaveLen(3))
+		}).Should(Succeed())
+		Expect(signatures[0]).To(BeEmpty())
+
+		ExpectSingletonReconciled(ctx, queue)
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+	})
+	It("should not disrupt until the webhooks acknowledge the disruption when acknowledgement is required", func() {
+		statusCode = http.StatusServiceUnavailable
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			DisruptionWebhookURLs:       lo.ToPtr(server.URL),
+			DisruptionWebhookRequireAck: lo.ToPtr(true),
+		}))
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		fakeClock.Step(10 * time.Minute)
+		wg := sync.WaitGroup{}
+		ExpectToWait(fakeClock, &wg)
+		ExpectSingletonReconcileFailed(ctx, disruptionController)
+		wg.Wait()
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+
+		statusCode = http.StatusAccepted
+		ExpectToWait(fakeClock, &wg)
+		ExpectSingletonReconciled(ctx, disruptionController)
+		wg.Wait()
+		ExpectSingletonReconciled(ctx, queue)
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+	})
+	It("should not disrupt when the webhooks don't acknowledge the disruption before the timeout", func() {
+		timeout := disruption.WebhookAckTimeout
+		disruption.WebhookAckTimeout = 100 * time.Millisecond
+		DeferCleanup(func() { disruption.WebhookAckTimeout = timeout })
+		unresponsive = true
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			DisruptionWebhookURLs:       lo.ToPtr(server.URL),
+			DisruptionWebhookRequireAck: lo.ToPtr(true),
+		}))
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		fakeClock.Step(10 * time.Minute)
+		wg := sync.WaitGroup{}
+		ExpectToWait(fakeClock, &wg)
+		start := time.Now()
+		ExpectSingletonReconcileFailed(ctx, disruptionController)
+		wg.Wait()
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeFalse())
+	})
+	It("should drop notifications when the queue is full", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionWebhookURLs: lo.ToPtr(server.URL)}))
+		// Nothing sends the notifications from this queue, so it fills up
+		webhooks := disruption.NewWebhooks(&http.Client{})
+		for i := 0; i < disruption.WebhookQueueSize; i++ {
+			Expect(webhooks.Notify(ctx, disruption.Notification{})).To(Succeed())
+		}
+		Expect(webhooks.Notify(ctx, disruption.Notification{})).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labelnormalization
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// Controller registers the label aliases from LabelNormalizations so that they're translated in requirements
+type Controller struct {
+	kubeClient   client.Client
+	labelAliases *scheduling.LabelAliases
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, labelAliases *scheduling.LabelAliases) *Controller {
+	return &Controller{
+		kubeClient:   kubeClient,
+		labelAliases: labelAliases,
+	}
+}
+
+// Reconcile rebuilds the registered aliases from all of the LabelNormalizations on any change, since an alias may
+// move between resources
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "labelnormalization")
+
+	labelNormalizations := &v1alpha1.LabelNormalizationList{}
+	if err := c.kubeClient.List(ctx, labelNormalizations); err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing labelnormalizations, %w", err)
+	}
+	// Order by name so that conflicting aliases are resolved consistently
+	sort.Slice(labelNormalizations.Items, func(i, j int) bool {
+		return labelNormalizations.Items[i].Name < labelNormalizations.Items[j].Name
+	})
+	aliases := map[string]string{}
+	for _, ln := range labelNormalizations.Items {
+		for _, alias := range ln.Spec.Aliases {
+			if err := validateAlias(alias, ln.Spec.Key, aliases); err != nil {
+				log.FromContext(ctx).WithValues("LabelNormalization", ln.Name).Error(err, "ignoring label alias")
+				continue
+			}
+			aliases[alias] = ln.Spec.Key
+		}
+	}
+	c.labelAliases.Set(aliases)
+	return reconcile.Result{}, nil
+}
+
+// validateAlias ensures that the alias doesn't shadow a well known or built-in normalized label and that it doesn't
+// conflict with an alias that was already registered
+func validateAlias(alias, key string, aliases map[string]string) error {
+	if errs := validation.IsQualifiedName(alias); len(errs) > 0 {
+		return fmt.Errorf("alias %s is not a qualified name, %s", alias, strings.Join(errs, ", "))
+	}
+	if alias == key {
+		return fmt.Errorf("alias %s is the same as its key", alias)
+	}
+	if v1.WellKnownLabels.Has(alias) {
+		return fmt.Errorf("alias %s is a well known label", alias)
+	}
+	if _, ok := v1.NormalizedLabels[alias]; ok {
+		return fmt.Errorf("alias %s is already normalized to %s", alias, v1.NormalizedLabels[alias])
+	}
+	if existing, ok := aliases[alias]; ok {
+		return fmt.Errorf("alias %s is already registered for %s", alias, existing)
+	}
+	return nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("labelnormalization").
+		For(&v1alpha1.LabelNormalization{}).
+		Complete(c)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labelnormalization_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/controllers/labelnormalization"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller   *labelnormalization.Controller
+	labelAliases *scheduling.LabelAliases
+	ctx          context.Context
+	env          *test.Environment
+)
+
+const csiZoneLabel = "topology.csi.example.com/zone"
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LabelNormalization")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...))
+	labelAliases = scheduling.NewLabelAliases()
+	controller = labelnormalization.NewController(env.Client, labelAliases)
+})
+
+var _ = AfterEach(func() {
+	Expect(env.Client.DeleteAllOf(ctx, &v1alpha1.LabelNormalization{})).To(Succeed())
+	labelAliases.Set(nil)
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("LabelNormalization", func() {
+	var labelNormalization *v1alpha1.LabelNormalization
+	BeforeEach(func() {
+		labelNormalization = &v1alpha1.LabelNormalization{
+			ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()},
+			Spec: v1alpha1.LabelNormalizationSpec{
+				Key:     corev1.LabelTopologyZone,
+				Aliases: []string{csiZoneLabel},
+			},
+		}
+	})
+	It("should translate registered aliases in requirements", func() {
+		ExpectApplied(ctx, env.Client, labelNormalization)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(labelNormalization))
+
+		Expect(labelAliases.Normalize(csiZoneLabel)).To(Equal(corev1.LabelTopologyZone))
+		pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{csiZoneLabel: "test-zone-1"}})
+		labelAliases.InjectPod(pod)
+		requirements := scheduling.NewPodRequirements(pod)
+		Expect(requirements.Has(csiZoneLabel)).To(BeFalse())
+		Expect(requirements.Get(corev1.LabelTopologyZone).Has("test-zone-1")).To(BeTrue())
+	})
+	It("should stop translating aliases after the LabelNormalization is deleted", func() {
+		ExpectApplied(ctx, env.Client, labelNormalization)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(labelNormalization))
+		Expect(labelAliases.Normalize(csiZoneLabel)).To(Equal(corev1.LabelTopologyZone))
+
+		ExpectDeleted(ctx, env.Client, labelNormalization)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(labelNormalization))
+		Expect(labelAliases.Normalize(csiZoneLabel)).To(Equal(csiZoneLabel))
+	})
+	It("should ignore aliases for well known labels", func() {
+		labelNormalization.Spec.Aliases = []string{corev1.LabelInstanceTypeStable, csiZoneLabel}
+		ExpectApplied(ctx, env.Client, labelNormalization)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(labelNormalization))
+
+		Expect(labelAliases.Normalize(corev1.LabelInstanceTypeStable)).To(Equal(corev1.LabelInstanceTypeStable))
+		Expect(labelAliases.Normalize(csiZoneLabel)).To(Equal(corev1.LabelTopologyZone))
+	})
+	It("should keep the alias from the first LabelNormalization by name when aliases conflict", func() {
+		labelNormalization.Name = "a"
+		other := &v1alpha1.LabelNormalization{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Spec: v1alpha1.LabelNormalizationSpec{
+				Key:     corev1.LabelTopologyRegion,
+				Aliases: []string{csiZoneLabel},
+			},
+		}
+		ExpectApplied(ctx, env.Client, labelNormalization, other)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(other))
+
+		Expect(labelAliases.Normalize(csiZoneLabel)).To(Equal(corev1.LabelTopologyZone))
+	})
+})
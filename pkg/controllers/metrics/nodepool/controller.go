@@ -29,6 +29,7 @@ import (
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -37,6 +38,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 )
 
@@ -59,6 +61,19 @@ var (
 			nodePoolNameLabel,
 		},
 	)
+	LimitRemaining = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodePoolSubsystem,
+			Name:      "limit_remaining",
+			Help:      "The amount of resources that can still be provisioned for a nodepool before its limits are reached. Labeled by nodepool name and resource type.",
+		},
+		[]string{
+			resourceTypeLabel,
+			nodePoolNameLabel,
+		},
+	)
 	Usage = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -112,6 +127,8 @@ func buildMetrics(nodePool *v1.NodePool) (res []*metrics.StoreMetric) {
 	for gaugeVec, resourceList := range map[opmetrics.GaugeMetric]corev1.ResourceList{
 		Usage: nodePool.Status.Resources,
 		Limit: getLimits(nodePool),
+		// Remaining limits are computed by the nodepool counter alongside the usage in the status
+		LimitRemaining: nodePool.Status.RemainingLimits,
 	} {
 		for k, v := range resourceList {
 			res = append(res, &metrics.StoreMetric{
@@ -138,9 +155,10 @@ func makeLabels(nodePool *v1.NodePool, resourceTypeName string) prometheus.Label
 	}
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("metrics.nodepool").
 		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		WithOptions(controller.Options{NeedLeaderElection: lo.ToPtr(!options.FromContext(ctx).ReadOnlyStandby)}).
 		Complete(c)
 }
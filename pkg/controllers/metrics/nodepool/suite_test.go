@@ -128,8 +128,27 @@ var _ = Describe("Metrics", func() {
 			Expect(m.GetGauge().GetValue()).To(BeNumerically("~", v.AsApproximateFloat64()))
 		}
 	})
+	It("should update the nodepool remaining limit metrics", func() {
+		remaining := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("90"),
+			corev1.ResourceMemory: resource.MustParse("-10Mi"),
+		}
+		nodePool.Status.RemainingLimits = remaining
+
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
+
+		for k, v := range remaining {
+			m, found := FindMetricWithLabelValues("karpenter_nodepools_limit_remaining", map[string]string{
+				"nodepool":      nodePool.GetName(),
+				"resource_type": strings.ReplaceAll(k.String(), "-", "_"),
+			})
+			Expect(found).To(BeTrue())
+			Expect(m.GetGauge().GetValue()).To(BeNumerically("~", v.AsApproximateFloat64()))
+		}
+	})
 	It("should delete the nodepool state metrics on nodepool delete", func() {
-		expectedMetrics := []string{"karpenter_nodepools_limit", "karpenter_nodepools_usage"}
+		expectedMetrics := []string{"karpenter_nodepools_limit", "karpenter_nodepools_limit_remaining", "karpenter_nodepools_usage"}
 		nodePool.Spec.Limits = v1.Limits{
 			corev1.ResourceCPU:              resource.MustParse("100"),
 			corev1.ResourceMemory:           resource.MustParse("100Mi"),
@@ -140,6 +159,7 @@ var _ = Describe("Metrics", func() {
 			corev1.ResourceMemory:           resource.MustParse("10Mi"),
 			corev1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
 		}
+		nodePool.Status.RemainingLimits = nodePool.Spec.Limits.Remaining(nodePool.Status.Resources, nil)
 		ExpectApplied(ctx, env.Client, nodePool)
 		ExpectReconcileSucceeded(ctx, nodePoolController, client.ObjectKeyFromObject(nodePool))
 
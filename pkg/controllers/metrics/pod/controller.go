@@ -29,8 +29,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -39,6 +41,8 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
 const (
@@ -53,6 +57,7 @@ const (
 	podHostInstanceType = "instance_type"
 	podPhase            = "phase"
 	podScheduled        = "scheduled"
+	launchedCapacity    = "launched_capacity"
 )
 
 var (
@@ -98,6 +103,17 @@ var (
 		},
 		[]string{},
 	)
+	PodPendingToBoundDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.PodSubsystem,
+			Name:      "pending_to_bound_duration_seconds",
+			Help:      "The time from when Karpenter first saw a pod pending until the pod bound. Labeled by whether the pod bound to capacity that Karpenter launched after the pod became pending.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{launchedCapacity},
+	)
 	PodUnboundTimeSeconds = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -169,12 +185,21 @@ var (
 
 // Controller for the resource
 type Controller struct {
+	clock       clock.Clock
 	kubeClient  client.Client
 	metricStore *metrics.Store
 	cluster     *state.Cluster
 
 	pendingPods     sets.Set[string]
 	unscheduledPods sets.Set[string]
+	// unboundPods tracks when we first saw each pod pending. These are only kept in memory so that tracking the
+	// pending to bound latency doesn't write to every pod in the cluster.
+	unboundPods map[string]unboundPod
+}
+
+type unboundPod struct {
+	uid   types.UID
+	since time.Time
 }
 
 func labelNames() []string {
@@ -194,12 +219,14 @@ func labelNames() []string {
 }
 
 // NewController constructs a podController instance
-func NewController(kubeClient client.Client, cluster *state.Cluster) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster) *Controller {
 	return &Controller{
+		clock:           clk,
 		kubeClient:      kubeClient,
 		metricStore:     metrics.NewStore(),
 		pendingPods:     sets.New[string](),
 		unscheduledPods: sets.New[string](),
+		unboundPods:     map[string]unboundPod{},
 		cluster:         cluster,
 	}
 }
@@ -222,6 +249,7 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 				podNamespace: req.Namespace,
 			})
 			c.unscheduledPods.Delete(req.NamespacedName.String())
+			delete(c.unboundPods, req.NamespacedName.String())
 			// Delete the unbound metric since the pod is deleted
 			PodUnboundTimeSeconds.Delete(map[string]string{
 				podName:      req.Name,
@@ -255,6 +283,9 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	schedulableTime := c.cluster.PodSchedulingSuccessTime(types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace})
 	c.recordPodStartupMetric(pod, schedulableTime)
 	c.recordPodBoundMetric(pod, schedulableTime)
+	if err = c.recordPodPendingToBoundMetric(ctx, pod); err != nil {
+		return reconcile.Result{}, err
+	}
 	// Requeue every 30s for pods that are stuck without a state change
 	return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 }
@@ -371,6 +402,63 @@ func (c *Controller) recordPodBoundMetric(pod *corev1.Pod, schedulableTime time.
 	}
 }
 
+// recordPodPendingToBoundMetric observes the latency from when we first saw the pod pending until it bound, split by
+// whether the pod bound to capacity that Karpenter launched after the pod became pending. This distinguishes the latency
+// caused by launching capacity from the latency of the kube-scheduler binding to capacity that already existed.
+func (c *Controller) recordPodPendingToBoundMetric(ctx context.Context, pod *corev1.Pod) error {
+	key := client.ObjectKeyFromObject(pod).String()
+	if !podutils.IsActive(pod) {
+		delete(c.unboundPods, key)
+		return nil
+	}
+	unbound, ok := c.unboundPods[key]
+	if pod.Spec.NodeName == "" {
+		// A pod that was recreated with the same name is pending from when we first saw the new pod
+		if !ok || unbound.uid != pod.UID {
+			c.unboundPods[key] = unboundPod{uid: pod.UID, since: c.clock.Now()}
+		}
+		return nil
+	}
+	// Pods that were already bound when we first saw them didn't wait on us, so there's nothing to attribute
+	if !ok || unbound.uid != pod.UID {
+		delete(c.unboundPods, key)
+		return nil
+	}
+	launched, err := c.boundToLaunchedCapacity(ctx, pod, unbound.since)
+	if err != nil {
+		return err
+	}
+	PodPendingToBoundDurationSeconds.Observe(c.boundTime(pod).Sub(unbound.since).Seconds(), map[string]string{
+		launchedCapacity: fmt.Sprint(launched),
+	})
+	delete(c.unboundPods, key)
+	return nil
+}
+
+// boundTime returns when the pod was bound, falling back to the current time if the pod's PodScheduled condition
+// hasn't been updated yet
+func (c *Controller) boundTime(pod *corev1.Pod) time.Time {
+	if cond, ok := lo.Find(pod.Status.Conditions, func(c corev1.PodCondition) bool {
+		return c.Type == corev1.PodScheduled && c.Status == corev1.ConditionTrue
+	}); ok && !cond.LastTransitionTime.IsZero() {
+		return cond.LastTransitionTime.Time
+	}
+	return c.clock.Now()
+}
+
+// boundToLaunchedCapacity returns true if the pod bound to a Karpenter managed node that was created after the pod
+// became pending
+func (c *Controller) boundToLaunchedCapacity(ctx context.Context, pod *corev1.Pod, pendingTime time.Time) (bool, error) {
+	node := &corev1.Node{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if _, ok := node.Labels[v1.NodePoolLabelKey]; !ok {
+		return false, nil
+	}
+	return node.CreationTimestamp.After(pendingTime), nil
+}
+
 // makeLabels creates the makeLabels using the current state of the pod
 func (c *Controller) makeLabels(ctx context.Context, pod *corev1.Pod) (prometheus.Labels, error) {
 	metricLabels := map[string]string{}
@@ -402,9 +490,10 @@ func (c *Controller) makeLabels(ctx context.Context, pod *corev1.Pod) (prometheu
 	return metricLabels, nil
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("metrics.pod").
 		For(&corev1.Pod{}).
+		WithOptions(controller.Options{NeedLeaderElection: lo.ToPtr(!options.FromContext(ctx).ReadOnlyStandby)}).
 		Complete(c)
 }
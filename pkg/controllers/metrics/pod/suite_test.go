@@ -29,6 +29,7 @@ import (
 	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
@@ -55,11 +56,12 @@ var _ = BeforeSuite(func() {
 	fakeClock = clock.NewFakeClock(time.Now())
 	cloudProvider = fake.NewCloudProvider()
 	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
-	podController = pod.NewController(env.Client, cluster)
+	podController = pod.NewController(fakeClock, env.Client, cluster)
 })
 
 var _ = AfterEach(func() {
 	cluster.Reset()
+	pod.PodPendingToBoundDurationSeconds.Reset()
 })
 
 var _ = AfterSuite(func() {
@@ -379,4 +381,78 @@ var _ = Describe("Pod Metrics", func() {
 		})
 		Expect(found).To(BeFalse())
 	})
+	Context("Pending to Bound Latency", func() {
+		It("should observe latency for pods that bind to capacity launched after they became pending", func() {
+			// The API server sets the node's creation timestamp, so make sure the pod is seen pending before it
+			fakeClock.SetTime(time.Now().Add(-time.Minute))
+			p := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, p)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+			node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: "default"}}})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectManualBinding(ctx, env.Client, p, node)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+			_, found := FindMetricWithLabelValues("karpenter_pods_pending_to_bound_duration_seconds", map[string]string{
+				"launched_capacity": "true",
+			})
+			Expect(found).To(BeTrue())
+		})
+		It("should observe latency for pods that bind to existing capacity", func() {
+			node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: "default"}}})
+			ExpectApplied(ctx, env.Client, node)
+			node = ExpectExists(ctx, env.Client, node)
+
+			fakeClock.SetTime(node.CreationTimestamp.Add(time.Minute))
+			p := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, p)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+			ExpectManualBinding(ctx, env.Client, p, node)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+			_, found := FindMetricWithLabelValues("karpenter_pods_pending_to_bound_duration_seconds", map[string]string{
+				"launched_capacity": "false",
+			})
+			Expect(found).To(BeTrue())
+		})
+		It("should not observe latency for pods that were already bound when first seen", func() {
+			node := test.Node()
+			p := test.Pod(test.PodOptions{NodeName: node.Name})
+			ExpectApplied(ctx, env.Client, node, p)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+			_, found := FindMetricWithLabelValues("karpenter_pods_pending_to_bound_duration_seconds", map[string]string{})
+			Expect(found).To(BeFalse())
+		})
+		It("should not observe latency for a pod recreated with the same name that was already bound when first seen", func() {
+			p := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, p)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+			ExpectDeleted(ctx, env.Client, p)
+
+			node := test.Node()
+			recreated := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace}, NodeName: node.Name})
+			ExpectApplied(ctx, env.Client, node, recreated)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(recreated))
+
+			_, found := FindMetricWithLabelValues("karpenter_pods_pending_to_bound_duration_seconds", map[string]string{})
+			Expect(found).To(BeFalse())
+		})
+		It("should not observe latency more than once", func() {
+			node := test.Node()
+			p := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, node, p)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+			ExpectManualBinding(ctx, env.Client, p, node)
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+			ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+			m, found := FindMetricWithLabelValues("karpenter_pods_pending_to_bound_duration_seconds", map[string]string{
+				"launched_capacity": "false",
+			})
+			Expect(found).To(BeTrue())
+			Expect(m.GetHistogram().GetSampleCount()).To(BeNumerically("==", 1))
+		})
+	})
 })
@@ -0,0 +1,208 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adoption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/awslabs/operatorpkg/object"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
+)
+
+// Controller re-adopts Nodes that were registered by a NodeClaim which no longer exists, e.g. because the NodeClaim
+// was deleted accidentally with its finalizer removed. Rather than leaving the Node unmanaged, a NodeClaim is
+// recreated from the Node and the cloud provider instance backing it and goes through registration again.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	providerIDs   *providerid.Mapping
+	recorder      events.Recorder
+}
+
+// NewController constructs a controller instance
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, providerIDs *providerid.Mapping, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		providerIDs:   providerIDs,
+		recorder:      recorder,
+	}
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("node.adoption").
+		For(&corev1.Node{}, builder.WithPredicates(nodeutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Watches(&v1.NodeClaim{}, nodeutils.NodeClaimEventHandler(c.kubeClient)).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "node.adoption")
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Node", klog.KRef(node.Namespace, node.Name)))
+
+	if !node.DeletionTimestamp.IsZero() || node.Spec.ProviderID == "" {
+		return reconcile.Result{}, nil
+	}
+	// Only Nodes that registered through a NodeClaim are adopted. Other Nodes that carry our labels were never
+	// launched by us, so they're left alone.
+	if _, ok := node.Labels[v1.NodeRegisteredLabelKey]; !ok {
+		return reconcile.Result{}, nil
+	}
+	nodePoolName, ok := node.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	if _, err := c.providerIDs.NodeClaimForNode(ctx, c.kubeClient, node); !nodeutils.IsNodeClaimNotFoundError(err) {
+		return reconcile.Result{}, nodeutils.IgnoreDuplicateNodeClaimError(err)
+	}
+	nodePool := &v1.NodePool{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: nodePoolName}, nodePool); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	// Nodes without an instance are cleaned up by garbage collection, so there's nothing to adopt
+	instance, err := c.cloudProvider.Get(ctx, node.Spec.ProviderID)
+	if err != nil {
+		if cloudprovider.IsNodeClaimNotFoundError(err) {
+			log.FromContext(ctx).WithValues("provider-id", node.Spec.ProviderID).V(1).Info("skipping adoption, instance no longer exists")
+			c.recorder.Publish(NodeAdoptionSkipped(node))
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("getting instance, %w", err)
+	}
+	if !instance.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	// The Node still references the NodeClaim that was deleted, which would cause it to be garbage collected
+	if err = c.removeNodeClaimOwnerReferences(ctx, node); err != nil {
+		return reconcile.Result{}, err
+	}
+	nodeClaim, err := c.adopt(ctx, node, nodePool, instance)
+	if err != nil {
+		// The Node was already adopted, but the cache hasn't seen the NodeClaim yet
+		if errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	log.FromContext(ctx).WithValues("NodeClaim", klog.KRef("", nodeClaim.Name), "provider-id", node.Spec.ProviderID).Info("adopted node")
+	c.recorder.Publish(NodeAdopted(node, nodeClaim))
+	NodeClaimsAdoptedTotal.Inc(map[string]string{
+		metrics.NodePoolLabel: nodePoolName,
+	})
+	return reconcile.Result{}, nil
+}
+
+// adopt creates a NodeClaim for the Node, marking it as launched, registered and, if the Node was, initialized with the
+// details of the instance. The NodeClaim is annotated with the provider id of the instance so that lifecycle resolves
+// it rather than launching another instance if it sees the NodeClaim before its status is patched. The NodeClaim is
+// named after the Node so that a stale cache can't lead to a Node being adopted twice.
+func (c *Controller) adopt(ctx context.Context, node *corev1.Node, nodePool *v1.NodePool, instance *v1.NodeClaim) (*v1.NodeClaim, error) {
+	nodeClaim := &v1.NodeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        node.Name,
+			Labels:      node.Labels,
+			Annotations: map[string]string{v1.AdoptedProviderIDAnnotationKey: node.Spec.ProviderID},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         object.GVK(nodePool).GroupVersion().String(),
+					Kind:               object.GVK(nodePool).Kind,
+					Name:               nodePool.Name,
+					UID:                nodePool.UID,
+					BlockOwnerDeletion: lo.ToPtr(true),
+				},
+			},
+		},
+		Spec: v1.NodeClaimSpec{
+			Taints:                 nodePool.Spec.Template.Spec.Taints,
+			NodeClassRef:           nodePool.Spec.Template.Spec.NodeClassRef,
+			ExpireAfter:            nodePool.Spec.Template.Spec.ExpireAfter,
+			TerminationGracePeriod: nodePool.Spec.Template.Spec.TerminationGracePeriod,
+			// The Node's well known labels are the only record left of what the NodeClaim was launched with
+			Requirements: scheduling.NewLabelRequirements(lo.PickBy(node.Labels, func(k string, _ string) bool {
+				return v1.WellKnownLabels.Has(k)
+			})).NodeSelectorRequirements(),
+		},
+	}
+	nodeClaim = lifecycle.PopulateNodeClaimDetails(nodeClaim, instance)
+	if err := c.kubeClient.Create(ctx, nodeClaim); err != nil {
+		return nil, fmt.Errorf("creating nodeclaim, %w", err)
+	}
+	// The status isn't persisted on create, so it's populated again and patched separately
+	stored := nodeClaim.DeepCopy()
+	nodeClaim = lifecycle.PopulateNodeClaimDetails(nodeClaim, instance)
+	// The Node reports what's actually available on it, so it's preferred over what the cloud provider expected
+	if len(node.Status.Capacity) != 0 {
+		nodeClaim.Status.Capacity = node.Status.Capacity
+		nodeClaim.Status.Allocatable = node.Status.Allocatable
+	}
+	nodeClaim.Status.NodeName = node.Name
+	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+	if node.Labels[v1.NodeInitializedLabelKey] == "true" {
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+	}
+	if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+		return nil, fmt.Errorf("patching nodeclaim status, %w", err)
+	}
+	// Registration is skipped for the adopted NodeClaim, so the Node is owned by it here instead
+	storedNode := node.DeepCopy()
+	node = nodeclaimutils.UpdateNodeOwnerReferences(nodeClaim, node)
+	if err := c.kubeClient.Patch(ctx, node, client.MergeFromWithOptions(storedNode, client.MergeFromWithOptimisticLock{})); err != nil {
+		return nil, fmt.Errorf("adding nodeclaim owner reference, %w", err)
+	}
+	return nodeClaim, nil
+}
+
+func (c *Controller) removeNodeClaimOwnerReferences(ctx context.Context, node *corev1.Node) error {
+	gvk := object.GVK(&v1.NodeClaim{})
+	stored := node.DeepCopy()
+	node.OwnerReferences = lo.Reject(node.OwnerReferences, func(o metav1.OwnerReference, _ int) bool {
+		return o.APIVersion == gvk.GroupVersion().String() && o.Kind == gvk.Kind
+	})
+	if len(node.OwnerReferences) == len(stored.OwnerReferences) {
+		return nil
+	}
+	// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+	// can cause races due to the fact that it fully replaces the list on a change
+	if err := c.kubeClient.Patch(ctx, node, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("removing nodeclaim owner references, %w", err)
+	}
+	return nil
+}
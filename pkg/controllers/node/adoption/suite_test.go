@@ -0,0 +1,211 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adoption_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/adoption"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var adoptionController *adoption.Controller
+var env *test.Environment
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Node Adoption")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(
+		test.WithCRDs(apis.CRDs...),
+		test.WithCRDs(v1alpha1.CRDs...),
+	)
+	cloudProvider = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	adoptionController = adoption.NewController(env.Client, cloudProvider, providerid.NewMapping(), recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Node Adoption", func() {
+	var nodePool *v1.NodePool
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		cloudProvider.Reset()
+		recorder.Reset()
+		nodePool = test.NodePool()
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoSchedule}}
+		node = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					v1.NodeRegisteredLabelKey:      "true",
+					v1.NodeInitializedLabelKey:     "true",
+					v1.CapacityTypeLabelKey:        v1.CapacityTypeOnDemand,
+					corev1.LabelInstanceTypeStable: "default-instance-type",
+					corev1.LabelTopologyZone:       "test-zone-1",
+					v1.NodeClassLabelKey(nodePool.Spec.Template.Spec.NodeClassRef.GroupKind()): nodePool.Spec.Template.Spec.NodeClassRef.Name,
+					"example.com/custom": "value",
+				},
+			},
+			ProviderID: test.RandomProviderID(),
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3800m"),
+				corev1.ResourceMemory: resource.MustParse("15Gi"),
+			},
+		})
+		cloudProvider.CreatedNodeClaims[node.Spec.ProviderID] = test.NodeClaim(v1.NodeClaim{
+			Status: v1.NodeClaimStatus{
+				ProviderID: node.Spec.ProviderID,
+				ImageID:    "test-image",
+			},
+		})
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+	})
+
+	It("should adopt a node that lost its nodeclaim", func() {
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		nodeClaim := nodeClaims[0]
+		Expect(nodeClaim.Name).To(Equal(node.Name))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePool.Name))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue("example.com/custom", "value"))
+		Expect(nodeClaim.OwnerReferences).To(ContainElement(HaveField("UID", nodePool.UID)))
+		Expect(nodeClaim.Spec.NodeClassRef).To(Equal(nodePool.Spec.Template.Spec.NodeClassRef))
+		Expect(nodeClaim.Spec.Taints).To(Equal(nodePool.Spec.Template.Spec.Taints))
+		Expect(nodeClaim.Spec.Requirements).To(ContainElement(v1.NodeSelectorRequirementWithMinValues{
+			NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}},
+		}))
+		Expect(nodeClaim.Spec.Requirements).ToNot(ContainElement(HaveField("Key", "example.com/custom")))
+		Expect(nodeClaim.Status.ProviderID).To(Equal(node.Spec.ProviderID))
+		Expect(nodeClaim.Status.ImageID).To(Equal("test-image"))
+		ExpectResources(nodeClaim.Status.Capacity, node.Status.Capacity)
+		ExpectResources(nodeClaim.Status.Allocatable, node.Status.Allocatable)
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue()).To(BeTrue())
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()).To(BeTrue())
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue()).To(BeTrue())
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.AdoptedProviderIDAnnotationKey, node.Spec.ProviderID))
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+
+		Expect(recorder.Calls("Adopted")).To(Equal(1))
+		ExpectMetricCounterValue(adoption.NodeClaimsAdoptedTotal, 1, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should remove owner references to the deleted nodeclaim", func() {
+		node.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "NodeClaim",
+			Name:       "deleted",
+			UID:        "deleted-uid",
+		}}
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.OwnerReferences).To(HaveLen(1))
+		Expect(node.OwnerReferences[0].UID).To(Equal(nodeClaims[0].UID))
+	})
+	It("should leave the nodeclaim to be initialized when the node wasn't initialized", func() {
+		delete(node.Labels, v1.NodeInitializedLabelKey)
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()).To(BeTrue())
+		Expect(nodeClaims[0].StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue()).To(BeFalse())
+	})
+	It("should not adopt a node that has a nodeclaim", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{Status: v1.NodeClaimStatus{ProviderID: node.Spec.ProviderID}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Name).To(Equal(nodeClaim.Name))
+	})
+	It("should not adopt a node that never registered", func() {
+		delete(node.Labels, v1.NodeRegisteredLabelKey)
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+	})
+	It("should not adopt a node whose nodepool doesn't exist", func() {
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+	})
+	It("should not adopt a node whose instance doesn't exist", func() {
+		delete(cloudProvider.CreatedNodeClaims, node.Spec.ProviderID)
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+		Expect(recorder.Calls("AdoptionSkipped")).To(Equal(1))
+	})
+	It("should retry adopting a node when getting its instance fails", func() {
+		cloudProvider.NextGetErr = fmt.Errorf("failed")
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		_ = ExpectObjectReconcileFailed(ctx, env.Client, adoptionController, node)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+		Expect(recorder.Calls("AdoptionSkipped")).To(Equal(0))
+
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+	})
+	It("should not adopt a node that is deleting", func() {
+		node.Finalizers = []string{v1.TerminationFinalizer}
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		Expect(env.Client.Delete(ctx, node)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,158 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binding
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// Controller binds the pods that were nominated to a NodeClaim directly to its node once the node is ready and
+// initialized, rather than waiting on the kube-scheduler to pick the node up. This avoids races where the
+// kube-scheduler places the pods elsewhere and the new node is left empty. Pods that fail any of the checks below, or
+// whose binding fails, are left to the kube-scheduler.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+	recorder      events.Recorder
+}
+
+// NewController constructs a controller instance
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+		recorder:      recorder,
+	}
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("node.binding").
+		For(&corev1.Node{}, builder.WithPredicates(nodeutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "node.binding")
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Node", klog.KRef(node.Namespace, node.Name)))
+
+	if !node.DeletionTimestamp.IsZero() || node.Spec.Unschedulable {
+		return reconcile.Result{}, nil
+	}
+	if nodeutils.GetCondition(node, corev1.NodeReady).Status != corev1.ConditionTrue || node.Labels[v1.NodeInitializedLabelKey] != "true" {
+		return reconcile.Result{}, nil
+	}
+	nodeClaim, err := nodeutils.NodeClaimForNode(ctx, c.kubeClient, node)
+	if err != nil {
+		return reconcile.Result{}, nodeutils.IgnoreNodeClaimNotFoundError(err)
+	}
+	nominated := c.cluster.NominatedPods(nodeClaim.Name)
+	if len(nominated) == 0 || !nodeClaim.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	pods, err := nodeutils.GetPods(ctx, c.kubeClient, node)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	pods = lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return !podutils.IsTerminal(p) })
+	available := resources.Subtract(node.Status.Allocatable, resources.RequestsForPods(pods...))
+	hostPorts := scheduling.NewHostPortUsage()
+	for _, p := range pods {
+		hostPorts.Add(p, scheduling.GetHostPorts(p))
+	}
+	for _, key := range nominated {
+		pod := &corev1.Pod{}
+		if err = c.kubeClient.Get(ctx, key, pod); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return reconcile.Result{}, err
+			}
+			continue
+		}
+		if !canBind(pod, node, available, hostPorts) {
+			continue
+		}
+		if err = c.kubeClient.Create(ctx, &corev1.Binding{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID},
+			Target:     corev1.ObjectReference{Kind: "Node", Name: node.Name},
+		}); err != nil {
+			// The pod is still pending, so the kube-scheduler will schedule it as it normally would
+			log.FromContext(ctx).WithValues("Pod", klog.KObj(pod)).Error(err, "failed binding pod, falling back to the kube-scheduler")
+			continue
+		}
+		log.FromContext(ctx).WithValues("Pod", klog.KObj(pod)).V(1).Info("bound pod")
+		c.recorder.Publish(PodBound(pod, node))
+		available = resources.Subtract(available, resources.RequestsForPods(pod))
+		hostPorts.Add(pod, scheduling.GetHostPorts(pod))
+	}
+	c.cluster.ClearNominatedPods(nodeClaim.Name)
+	return reconcile.Result{}, nil
+}
+
+// canBind returns true if the pod is still pending and would be scheduled to the node by the kube-scheduler. Pods
+// that rely on scheduling decisions we don't simulate here, like volume binding and inter-pod affinity, are always
+// left to the kube-scheduler.
+func canBind(pod *corev1.Pod, node *corev1.Node, available corev1.ResourceList, hostPorts *scheduling.HostPortUsage) bool {
+	if pod.Spec.NodeName != "" || !pod.DeletionTimestamp.IsZero() || podutils.IsTerminal(pod) {
+		return false
+	}
+	if pod.Spec.SchedulerName != corev1.DefaultSchedulerName || len(pod.Spec.SchedulingGates) != 0 {
+		return false
+	}
+	if lo.ContainsBy(pod.Spec.Volumes, func(v corev1.Volume) bool { return v.PersistentVolumeClaim != nil || v.Ephemeral != nil }) ||
+		len(pod.Spec.ResourceClaims) != 0 {
+		return false
+	}
+	if pod.Spec.Affinity != nil && (pod.Spec.Affinity.PodAffinity != nil || pod.Spec.Affinity.PodAntiAffinity != nil) {
+		return false
+	}
+	if len(pod.Spec.TopologySpreadConstraints) != 0 {
+		return false
+	}
+	if err := scheduling.Taints(node.Spec.Taints).Tolerates(pod); err != nil {
+		return false
+	}
+	if err := scheduling.NewLabelRequirements(node.Labels).Compatible(scheduling.NewStrictPodRequirements(pod)); err != nil {
+		return false
+	}
+	if err := hostPorts.Conflicts(pod, scheduling.GetHostPorts(pod)); err != nil {
+		return false
+	}
+	return resources.Fits(resources.RequestsForPods(pod), available)
+}
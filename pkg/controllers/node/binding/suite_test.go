@@ -0,0 +1,185 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binding_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/binding"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var bindingController *binding.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+var cluster *state.Cluster
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Node Binding")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(
+		test.WithCRDs(apis.CRDs...),
+		test.WithCRDs(v1alpha1.CRDs...),
+	)
+	cloudProvider = fake.NewCloudProvider()
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	recorder = test.NewEventRecorder()
+	bindingController = binding.NewController(env.Client, cloudProvider, cluster, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Node Binding", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		cluster.Reset()
+		recorder.Reset()
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:        nodePool.Name,
+					v1.NodeInitializedLabelKey: "true",
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:  resource.MustParse("2"),
+					corev1.ResourcePods: resource.MustParse("10"),
+				},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+	})
+
+	It("should bind nominated pods once the node is ready and initialized", func() {
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Spec.NodeName).To(Equal(node.Name))
+		Expect(recorder.Calls("Bound")).To(Equal(1))
+		Expect(cluster.NominatedPods(nodeClaim.Name)).To(BeEmpty())
+	})
+	It("should not bind pods that weren't nominated to the node", func() {
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Spec.NodeName).To(BeEmpty())
+	})
+	It("should not bind pods to a node that isn't initialized", func() {
+		delete(node.Labels, v1.NodeInitializedLabelKey)
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Spec.NodeName).To(BeEmpty())
+		Expect(cluster.NominatedPods(nodeClaim.Name)).To(HaveLen(1))
+	})
+	It("should leave pods that don't tolerate the node's taints to the kube-scheduler", func() {
+		node.Spec.Taints = []corev1.Taint{{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoSchedule}}
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Spec.NodeName).To(BeEmpty())
+		Expect(cluster.NominatedPods(nodeClaim.Name)).To(BeEmpty())
+	})
+	It("should leave pods with persistent volume claims to the kube-scheduler", func() {
+		pod := test.UnschedulablePod(test.PodOptions{PersistentVolumeClaims: []string{"test-claim"}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Spec.NodeName).To(BeEmpty())
+	})
+	It("should only bind the pods that fit on the node", func() {
+		pods := test.UnschedulablePods(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		}, 3)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		for _, pod := range pods {
+			ExpectApplied(ctx, env.Client, pod)
+		}
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pods...)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		Expect(recorder.Calls("Bound")).To(Equal(2))
+	})
+	It("should leave pods whose host ports conflict with the node's pods to the kube-scheduler", func() {
+		existing := test.Pod(test.PodOptions{NodeName: node.Name, HostPorts: []int32{80}})
+		pod := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{80}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, existing, pod)
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		pod = ExpectExists(ctx, env.Client, pod)
+		Expect(pod.Spec.NodeName).To(BeEmpty())
+	})
+	It("should only bind one of the nominated pods that use the same host port", func() {
+		pods := test.UnschedulablePods(test.PodOptions{HostPorts: []int32{80}}, 2)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		for _, pod := range pods {
+			ExpectApplied(ctx, env.Client, pod)
+		}
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pods...)
+
+		ExpectObjectReconciled(ctx, env.Client, bindingController, node)
+		Expect(recorder.Calls("Bound")).To(Equal(1))
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expiration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+)
+
+const (
+	// recheckPeriod is how often expired nodes are rechecked while they can't be disrupted, either because a change
+	// freeze is enabled or because another expired node is being disrupted
+	recheckPeriod = time.Minute
+	// OwnerDeletionTimeout is how long a drained node waits for its owner to delete it before the next expired node is
+	// disrupted anyway, so that a node that's never deleted doesn't stall the rotation
+	OwnerDeletionTimeout = time.Hour
+)
+
+// Controller rotates nodes that aren't owned by Karpenter, such as nodes in static node groups, based on the
+// karpenter.sh/expire-after annotation. Expired nodes are cordoned and drained, but deleting the node and its
+// instance is left to the node's owner. Expired nodes are disrupted one at a time, and the next one isn't cordoned
+// until the owner has deleted the previous one, so that a rotation never removes more than a single node's capacity.
+// A drained node that its owner doesn't delete within the OwnerDeletionTimeout no longer holds up the rotation.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	terminator    *terminator.Terminator
+	recorder      events.Recorder
+	cluster       *state.Cluster
+}
+
+// NewController constructs a node expiration controller
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder, cluster *state.Cluster) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		terminator:    terminator,
+		recorder:      recorder,
+		cluster:       cluster,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "node.expiration")
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Node", klog.KRef(node.Namespace, node.Name)))
+
+	// Nodes that are owned by Karpenter are expired through the expireAfter of their NodeClaim
+	if nodeutils.IsManaged(node, c.cloudProvider) || !node.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	value, ok := node.Annotations[v1.NodeExpireAfterAnnotationKey]
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	expireAfter, err := time.ParseDuration(value)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed parsing expire-after annotation")
+		return reconcile.Result{}, nil
+	}
+	expirationTime := node.CreationTimestamp.Add(expireAfter)
+	if c.clock.Now().Before(expirationTime) {
+		// Use t.Sub(clock.Now()) instead of time.Until() to ensure we're using the injected clock.
+		return reconcile.Result{RequeueAfter: expirationTime.Sub(c.clock.Now())}, nil
+	}
+	// Expiring a node is voluntary disruption, so nodes aren't tainted or drained while a change freeze is enabled
+	if c.cluster.ChangeFrozen() {
+		return reconcile.Result{RequeueAfter: recheckPeriod}, nil
+	}
+	if !lo.ContainsBy(node.Spec.Taints, func(t corev1.Taint) bool { return t.MatchTaint(&v1.DisruptedNoScheduleTaint) }) {
+		disrupting, err := c.disrupting(ctx, node)
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("listing nodes, %w", err)
+		}
+		if disrupting {
+			return reconcile.Result{RequeueAfter: recheckPeriod}, nil
+		}
+		if err = c.terminator.Taint(ctx, node, v1.DisruptedNoScheduleTaint); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, client.IgnoreNotFound(fmt.Errorf("tainting node with %s, %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err))
+		}
+		c.recorder.Publish(NodeExpired(node))
+	}
+	if err = c.terminator.Drain(ctx, node, nil); err != nil {
+		if !terminator.IsNodeDrainError(err) {
+			return reconcile.Result{}, fmt.Errorf("draining node, %w", err)
+		}
+		c.recorder.Publish(terminatorevents.NodeFailedToDrain(node, err))
+		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+	// The node has been drained, so we leave it to its owner to delete the node and its instance
+	if _, ok := node.Annotations[v1.ExpiredNodeDrainedAnnotationKey]; ok {
+		return reconcile.Result{}, nil
+	}
+	stored := node.DeepCopy()
+	node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.ExpiredNodeDrainedAnnotationKey: c.clock.Now().UTC().Format(time.RFC3339)})
+	if err = c.kubeClient.Patch(ctx, node, client.MergeFrom(stored)); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(fmt.Errorf("patching node, %w", err))
+	}
+	return reconcile.Result{}, nil
+}
+
+// disrupting returns true if an expired node other than the given one is cordoned, which means that it's being drained
+// or that it's waiting for its owner to delete it. Nodes that were drained longer than the OwnerDeletionTimeout ago
+// are skipped.
+func (c *Controller) disrupting(ctx context.Context, node *corev1.Node) (bool, error) {
+	nodeList := &corev1.NodeList{}
+	if err := c.kubeClient.List(ctx, nodeList); err != nil {
+		return false, err
+	}
+	return lo.ContainsBy(nodeList.Items, func(n corev1.Node) bool {
+		if _, ok := n.Annotations[v1.NodeExpireAfterAnnotationKey]; !ok || n.Name == node.Name || nodeutils.IsManaged(&n, c.cloudProvider) {
+			return false
+		}
+		if !lo.ContainsBy(n.Spec.Taints, func(t corev1.Taint) bool { return t.MatchTaint(&v1.DisruptedNoScheduleTaint) }) {
+			return false
+		}
+		if drained, err := time.Parse(time.RFC3339, n.Annotations[v1.ExpiredNodeDrainedAnnotationKey]); err == nil && c.clock.Since(drained) >= OwnerDeletionTimeout {
+			log.FromContext(ctx).WithValues("drained-node", n.Name).V(1).Info("skipping drained node that its owner hasn't deleted")
+			return false
+		}
+		return true
+	}), nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("node.expiration").
+		For(&corev1.Node{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := o.GetAnnotations()[v1.NodeExpireAfterAnnotationKey]
+			return ok && !nodeutils.IsManaged(o.(*corev1.Node), c.cloudProvider)
+		}))).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,210 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expiration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/expiration"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var expirationController *expiration.Controller
+var env *test.Environment
+var defaultOwnerRefs = []metav1.OwnerReference{{Kind: "ReplicaSet", APIVersion: "appsv1", Name: "rs", UID: "1234567890"}}
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+var queue *terminator.Queue
+var cluster *state.Cluster
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Node Expiration")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(
+		test.WithCRDs(apis.CRDs...),
+		test.WithCRDs(v1alpha1.CRDs...),
+	)
+	cloudProvider = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	queue = terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock)
+	expirationController = expiration.NewController(fakeClock, env.Client, cloudProvider, terminator.NewTerminator(fakeClock, env.Client, queue, recorder), recorder, cluster)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Node Expiration", func() {
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		fakeClock.SetTime(time.Now())
+		recorder.Reset()
+		*queue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
+		node = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.NodeExpireAfterAnnotationKey: "1h"},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		cluster.Reset()
+		ExpectCleanedUp(ctx, env.Client)
+	})
+
+	It("should requeue nodes that haven't expired", func() {
+		ExpectApplied(ctx, env.Client, node)
+		result := ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+	})
+	It("should cordon and drain expired nodes without deleting them", func() {
+		pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+		ExpectApplied(ctx, env.Client, node, pod)
+		fakeClock.Step(2 * time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(queue.Has(node, pod)).To(BeTrue())
+
+		ExpectSingletonReconciled(ctx, queue)
+		EventuallyExpectTerminating(ctx, env.Client, pod)
+		ExpectDeleted(ctx, env.Client, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.DeletionTimestamp.IsZero()).To(BeTrue())
+		Expect(recorder.Calls(events.Expired)).To(Equal(1))
+	})
+	It("should only cordon a single expired node at a time", func() {
+		other := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.NodeExpireAfterAnnotationKey: "1h"},
+			},
+		})
+		ExpectApplied(ctx, env.Client, node, other)
+		fakeClock.Step(2 * time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		result := ExpectObjectReconciled(ctx, env.Client, expirationController, other)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		other = ExpectExists(ctx, env.Client, other)
+		Expect(other.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(recorder.Calls(events.Expired)).To(Equal(1))
+
+		// The next node is cordoned once its owner has deleted the drained node
+		ExpectDeleted(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, expirationController, other)
+		other = ExpectExists(ctx, env.Client, other)
+		Expect(other.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(recorder.Calls(events.Expired)).To(Equal(2))
+	})
+	It("should cordon the next expired node once a drained node isn't deleted by its owner within the timeout", func() {
+		other := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.NodeExpireAfterAnnotationKey: "1h"},
+			},
+		})
+		ExpectApplied(ctx, env.Client, node, other)
+		fakeClock.Step(2 * time.Hour)
+
+		// The node has no pods, so it's drained as soon as it's cordoned
+		ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(node.Annotations).To(HaveKey(v1.ExpiredNodeDrainedAnnotationKey))
+
+		result := ExpectObjectReconciled(ctx, env.Client, expirationController, other)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(ExpectExists(ctx, env.Client, other).Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+
+		// The owner never deletes the drained node, so the rotation moves on once the timeout elapses
+		fakeClock.Step(expiration.OwnerDeletionTimeout)
+		ExpectObjectReconciled(ctx, env.Client, expirationController, other)
+		Expect(ExpectExists(ctx, env.Client, other).Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(recorder.Calls(events.Expired)).To(Equal(2))
+	})
+	It("should not cordon or drain expired nodes while a change freeze is enabled", func() {
+		pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+		ExpectApplied(ctx, env.Client, node, pod)
+		fakeClock.Step(2 * time.Hour)
+		cluster.SetChangeFrozen(true)
+
+		result := ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(queue.Has(node, pod)).To(BeFalse())
+
+		cluster.SetChangeFrozen(false)
+		ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+		Expect(queue.Has(node, pod)).To(BeTrue())
+	})
+	It("should ignore nodes that are owned by Karpenter", func() {
+		nodeClaim, managedNode := test.NodeClaimAndNode()
+		managedNode.Annotations = lo.Assign(managedNode.Annotations, map[string]string{v1.NodeExpireAfterAnnotationKey: "1h"})
+		ExpectApplied(ctx, env.Client, nodeClaim, managedNode)
+		fakeClock.Step(2 * time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, expirationController, managedNode)
+		managedNode = ExpectExists(ctx, env.Client, managedNode)
+		Expect(managedNode.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+	})
+	It("should ignore nodes with an invalid expire-after annotation", func() {
+		node.Annotations[v1.NodeExpireAfterAnnotationKey] = "invalid"
+		ExpectApplied(ctx, env.Client, node)
+		fakeClock.Step(2 * time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, expirationController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+	})
+})
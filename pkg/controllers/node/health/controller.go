@@ -42,6 +42,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
@@ -130,7 +131,7 @@ func (c *Controller) deleteNodeClaim(ctx context.Context, nodeClaim *v1.NodeClai
 	if !nodeClaim.DeletionTimestamp.IsZero() {
 		return reconcile.Result{}, nil
 	}
-	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+	if err := nodeclaimutils.Delete(ctx, c.kubeClient, nodeClaim, v1.TerminationReasonRepair); err != nil {
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
 	// The deletion timestamp has successfully been set for the Node, update relevant metrics.
@@ -67,7 +67,7 @@ var _ = BeforeSuite(func() {
 	cloudProvider = fake.NewCloudProvider()
 	cloudProvider = fake.NewCloudProvider()
 	recorder = test.NewEventRecorder()
-	queue = terminator.NewTestingQueue(env.Client, recorder)
+	queue = terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock)
 	healthController = health.NewController(env.Client, cloudProvider, fakeClock, recorder)
 })
 
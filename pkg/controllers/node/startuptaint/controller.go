@@ -0,0 +1,136 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package startuptaint
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+)
+
+// Controller removes the startup taints of a node once they have outlived the startup taint timeout of its NodePool.
+// Startup taints are expected to be removed by a DaemonSet shortly after the node starts, so taints that remain are
+// most likely stranding workloads, e.g. because the DaemonSet that removes them was deleted before the node rebooted.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	recorder      events.Recorder
+}
+
+// NewController constructs a controller instance
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		recorder:      recorder,
+	}
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("node.startuptaint").
+		For(&corev1.Node{}, builder.WithPredicates(nodeutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "node.startuptaint")
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Node", klog.KRef(node.Namespace, node.Name)))
+
+	if !node.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	nodeClaim, err := nodeutils.NodeClaimForNode(ctx, c.kubeClient, node)
+	if err != nil {
+		return reconcile.Result{}, nodeutils.IgnoreNodeClaimNotFoundError(err)
+	}
+	taints := lo.Filter(node.Spec.Taints, func(t corev1.Taint, _ int) bool {
+		return lo.ContainsBy(nodeClaim.Spec.StartupTaints, func(startupTaint corev1.Taint) bool { return t.MatchTaint(&startupTaint) })
+	})
+	if len(taints) == 0 {
+		return reconcile.Result{}, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err = c.kubeClient.Get(ctx, types.NamespacedName{Name: nodeClaim.Labels[v1.NodePoolLabelKey]}, nodePool); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if nodePool.Spec.StartupTaintTimeout == nil {
+		return reconcile.Result{}, nil
+	}
+	deadline := startedAt(node).Add(nodePool.Spec.StartupTaintTimeout.Duration)
+	if c.clock.Now().Before(deadline) {
+		return reconcile.Result{RequeueAfter: deadline.Sub(c.clock.Now())}, nil
+	}
+
+	stored := node.DeepCopy()
+	node.Spec.Taints = lo.Without(node.Spec.Taints, taints...)
+	// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+	// can cause races due to the fact that it fully replaces the list on a change
+	if err = c.kubeClient.Patch(ctx, node, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).WithValues("taints", formatTaints(taints)).Info("removed startup taints that exceeded the startup taint timeout")
+	c.recorder.Publish(StartupTaintsRemoved(node, formatTaints(taints), nodePool.Spec.StartupTaintTimeout.Duration))
+	NodesStartupTaintsRemovedTotal.Inc(map[string]string{
+		metrics.NodePoolLabel: nodePool.Name,
+	})
+	return reconcile.Result{}, nil
+}
+
+// startedAt returns when the node last started, which is the later of when the node was created and when its Ready
+// condition last transitioned. The Ready condition transitions when the node reboots and re-registers.
+func startedAt(node *corev1.Node) time.Time {
+	ready := nodeutils.GetCondition(node, corev1.NodeReady)
+	if ready.LastTransitionTime.After(node.CreationTimestamp.Time) {
+		return ready.LastTransitionTime.Time
+	}
+	return node.CreationTimestamp.Time
+}
+
+func formatTaints(taints []corev1.Taint) []string {
+	return lo.Map(taints, func(t corev1.Taint, _ int) string {
+		if t.Value == "" {
+			return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+		}
+		return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+	})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package startuptaint_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/startuptaint"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var startupTaintController *startuptaint.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Node Startup Taint")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(
+		test.WithCRDs(apis.CRDs...),
+		test.WithCRDs(v1alpha1.CRDs...),
+	)
+	cloudProvider = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	startupTaintController = startuptaint.NewController(fakeClock, env.Client, cloudProvider, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Node Startup Taint", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	startupTaint := corev1.Taint{Key: "example.com/agent-not-ready", Effect: corev1.TaintEffectNoSchedule}
+
+	BeforeEach(func() {
+		fakeClock.SetTime(time.Now())
+		recorder.Reset()
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				StartupTaintTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+			},
+			Spec: v1.NodeClaimSpec{
+				StartupTaints: []corev1.Taint{startupTaint},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+	})
+
+	It("should requeue nodes whose startup taints haven't timed out", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		result := ExpectObjectReconciled(ctx, env.Client, startupTaintController, node)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(startupTaint))
+	})
+	It("should remove startup taints that have timed out", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, startupTaintController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(startupTaint))
+		Expect(recorder.Calls("StartupTaintsRemoved")).To(Equal(1))
+		ExpectMetricCounterValue(startuptaint.NodesStartupTaintsRemovedTotal, 1, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should measure the timeout from when the node last became ready", func() {
+		node.Status.Conditions = []corev1.NodeCondition{{
+			Type:               corev1.NodeReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(fakeClock.Now().Add(time.Hour)),
+		}}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		result := ExpectObjectReconciled(ctx, env.Client, startupTaintController, node)
+		Expect(result.RequeueAfter).To(BeNumerically("~", 10*time.Minute, time.Second))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(startupTaint))
+	})
+	It("should keep taints that aren't startup taints", func() {
+		taint := corev1.Taint{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoSchedule}
+		node.Spec.Taints = append(node.Spec.Taints, taint)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, startupTaintController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(startupTaint))
+		Expect(node.Spec.Taints).To(ContainElement(taint))
+	})
+	It("should not remove startup taints when the NodePool doesn't define a timeout", func() {
+		nodePool.Spec.StartupTaintTimeout = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		fakeClock.Step(time.Hour)
+
+		ExpectObjectReconciled(ctx, env.Client, startupTaintController, node)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ContainElement(startupTaint))
+	})
+})
@@ -19,15 +19,15 @@ package termination
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
 	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
-	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -39,19 +39,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/placement"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
-	"sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/termination"
-	volumeutil "sigs.k8s.io/karpenter/pkg/utils/volume"
 )
 
 // Controller for the resource
@@ -60,16 +62,20 @@ type Controller struct {
 	kubeClient    client.Client
 	cloudProvider cloudprovider.CloudProvider
 	terminator    *terminator.Terminator
+	placements    *placement.Tracker
+	providerIDs   *providerid.Mapping
 	recorder      events.Recorder
 }
 
 // NewController constructs a controller instance
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, placements *placement.Tracker, providerIDs *providerid.Mapping, recorder events.Recorder) *Controller {
 	return &Controller{
 		clock:         clk,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
 		terminator:    terminator,
+		placements:    placements,
+		providerIDs:   providerIDs,
 		recorder:      recorder,
 	}
 }
@@ -92,12 +98,12 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 		return reconcile.Result{}, nil
 	}
 
-	nodeClaims, err := nodeutils.GetNodeClaims(ctx, c.kubeClient, node)
+	nodeClaims, err := c.providerIDs.NodeClaims(ctx, c.kubeClient, node.Spec.ProviderID)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
 	}
 
-	if err = c.deleteAllNodeClaims(ctx, nodeClaims...); err != nil {
+	if err = c.deleteAllNodeClaims(ctx, node, nodeClaims...); err != nil {
 		return reconcile.Result{}, fmt.Errorf("deleting nodeclaims, %w", err)
 	}
 
@@ -112,23 +118,36 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 		}
 		return reconcile.Result{}, client.IgnoreNotFound(fmt.Errorf("tainting node with %s, %w", pretty.Taint(v1.DisruptedNoScheduleTaint), err))
 	}
+	// External systems can hold the node before it's drained, e.g. to let a batch job checkpoint. The blocks are
+	// ignored once the node's termination grace period has elapsed.
+	if nodeTerminationTime == nil || c.clock.Now().Before(*nodeTerminationTime) {
+		requeueAfter, err := c.deletionBlocked(ctx, node)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if requeueAfter > 0 {
+			return reconcile.Result{RequeueAfter: requeueAfter}, nil
+		}
+	}
+	if err = c.snapshotPlacement(ctx, node, nodeClaims...); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(fmt.Errorf("snapshotting pod placement, %w", err))
+	}
 	if err = c.terminator.Drain(ctx, node, nodeTerminationTime); err != nil {
 		if !terminator.IsNodeDrainError(err) {
 			return reconcile.Result{}, fmt.Errorf("draining node, %w", err)
 		}
 		c.recorder.Publish(terminatorevents.NodeFailedToDrain(node, err))
 		// If the underlying NodeClaim no longer exists, we want to delete to avoid trying to gracefully draining
-		// on nodes that are no longer alive. We do a check on the Ready condition of the node since, even
-		// though the CloudProvider says the instance is not around, we know that the kubelet process is still running
-		// if the Node Ready condition is true
-		// Similar logic to: https://github.com/kubernetes/kubernetes/blob/3a75a8c8d9e6a1ebd98d8572132e675d4980f184/staging/src/k8s.io/cloud-provider/controllers/nodelifecycle/node_lifecycle_controller.go#L144
-		if nodeutils.GetCondition(node, corev1.NodeReady).Status != corev1.ConditionTrue {
-			if _, err = c.cloudProvider.Get(ctx, node.Spec.ProviderID); err != nil {
-				if cloudprovider.IsNodeClaimNotFoundError(err) {
-					return reconcile.Result{}, c.removeFinalizer(ctx, node)
-				}
-				return reconcile.Result{}, fmt.Errorf("getting nodeclaim, %w", err)
-			}
+		// on nodes that are no longer alive
+		terminated, err := c.instanceTerminated(ctx, node)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if terminated {
+			return reconcile.Result{}, c.removeFinalizer(ctx, node, nodeClaims...)
 		}
 
 		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
@@ -148,7 +167,7 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 			return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
 		}
 	}
-	nodeClaims, err = nodeutils.GetNodeClaims(ctx, c.kubeClient, node)
+	nodeClaims, err = c.providerIDs.NodeClaims(ctx, c.kubeClient, node.Spec.ProviderID)
 	if err != nil {
 		return reconcile.Result{}, fmt.Errorf("deleting nodeclaims, %w", err)
 	}
@@ -169,77 +188,137 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 	}
-	if err := c.removeFinalizer(ctx, node); err != nil {
+	if err := c.removeFinalizer(ctx, node, nodeClaims...); err != nil {
 		return reconcile.Result{}, err
 	}
 	return reconcile.Result{}, nil
 }
 
-func (c *Controller) deleteAllNodeClaims(ctx context.Context, nodeClaims ...*v1.NodeClaim) error {
-	for _, nodeClaim := range nodeClaims {
-		// If we still get the NodeClaim, but it's already marked as terminating, we don't need to call Delete again
-		if nodeClaim.DeletionTimestamp.IsZero() {
-			if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
-				return client.IgnoreNotFound(err)
-			}
+// deletionBlocked returns how long until the next deletion block on the node expires, or zero if the node isn't
+// blocked. Each block holds the node for the duration in its annotation value, capped by the maximum deletion block
+// TTL, from when the node started deleting. Removing the annotation releases the block through the Node watch. Blocks
+// are skipped once the node's instance is gone since there's nothing left on the node for them to wait on.
+func (c *Controller) deletionBlocked(ctx context.Context, node *corev1.Node) (time.Duration, error) {
+	maxTTL := options.FromContext(ctx).MaxDeletionBlockTTL
+	var blockers []string
+	var requeueAfter time.Duration
+	var expiration time.Time
+	for key, value := range node.Annotations {
+		blocker, ok := strings.CutPrefix(key, v1.DeletionBlockAnnotationKeyPrefix)
+		if !ok {
+			continue
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 || ttl > maxTTL {
+			ttl = maxTTL
+		}
+		deadline := node.DeletionTimestamp.Add(ttl)
+		remaining := deadline.Sub(c.clock.Now())
+		if remaining <= 0 {
+			continue
+		}
+		blockers = append(blockers, blocker)
+		if requeueAfter == 0 || remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+		if deadline.After(expiration) {
+			expiration = deadline
 		}
 	}
-	return nil
+	if len(blockers) == 0 {
+		return 0, nil
+	}
+	if terminated, err := c.instanceTerminated(ctx, node); err != nil || terminated {
+		return 0, err
+	}
+	sort.Strings(blockers)
+	log.FromContext(ctx).V(1).WithValues("blockers", blockers, "expiration", expiration.Format(time.RFC3339)).Info("waiting on deletion blocks")
+	c.recorder.Publish(terminatorevents.NodeDeletionBlocked(node, blockers, expiration.Format(time.RFC3339)))
+	return requeueAfter, nil
 }
 
-func (c *Controller) ensureVolumesDetached(ctx context.Context, node *corev1.Node) (volumesDetached bool, err error) {
-	volumeAttachments, err := nodeutils.GetVolumeAttachments(ctx, c.kubeClient, node)
-	if err != nil {
-		return false, err
+// instanceTerminated returns true if the node isn't ready and the CloudProvider no longer has its instance. We check
+// the Ready condition since, even though the CloudProvider says the instance is not around, we know that the kubelet
+// process is still running if the Node Ready condition is true.
+// Similar logic to: https://github.com/kubernetes/kubernetes/blob/3a75a8c8d9e6a1ebd98d8572132e675d4980f184/staging/src/k8s.io/cloud-provider/controllers/nodelifecycle/node_lifecycle_controller.go#L144
+func (c *Controller) instanceTerminated(ctx context.Context, node *corev1.Node) (bool, error) {
+	if nodeutils.GetCondition(node, corev1.NodeReady).Status == corev1.ConditionTrue {
+		return false, nil
 	}
-	// Filter out VolumeAttachments associated with not drain-able Pods
-	filteredVolumeAttachments, err := filterVolumeAttachments(ctx, c.kubeClient, node, volumeAttachments, c.clock)
-	if err != nil {
-		return false, err
+	if _, err := c.cloudProvider.Get(ctx, node.Spec.ProviderID); err != nil {
+		if cloudprovider.IsNodeClaimNotFoundError(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("getting nodeclaim, %w", err)
 	}
-	return len(filteredVolumeAttachments) == 0, nil
+	return false, nil
 }
 
-// filterVolumeAttachments filters out storagev1.VolumeAttachments that should not block the termination
-// of the passed corev1.Node
-func filterVolumeAttachments(ctx context.Context, kubeClient client.Client, node *corev1.Node, volumeAttachments []*storagev1.VolumeAttachment, clk clock.Clock) ([]*storagev1.VolumeAttachment, error) {
-	// No need to filter empty VolumeAttachments list
-	if len(volumeAttachments) == 0 {
-		return volumeAttachments, nil
-	}
-	// Create list of non-drain-able Pods associated with Node
-	pods, err := nodeutils.GetPods(ctx, kubeClient, node)
+// snapshotPlacement records the pods on the node and their owners before the node is drained, so that the workloads
+// impacted by the node's termination can be audited afterwards. The pods that replace the drained pods are tracked so
+// that the nodes that they're rescheduled to are recorded as well.
+func (c *Controller) snapshotPlacement(ctx context.Context, node *corev1.Node, nodeClaims ...*v1.NodeClaim) error {
+	if value, ok := node.Annotations[v1.DrainSnapshotAnnotationKey]; ok {
+		// The snapshot is tracked again in case the controller restarted since it was recorded
+		if snapshot, err := placement.ParseSnapshot(value); err == nil {
+			c.placements.Track(snapshot)
+		}
+		return nil
+	}
+	pods, err := nodeutils.GetPods(ctx, c.kubeClient, node)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("listing pods, %w", err)
+	}
+	reason := v1.TerminationReasonManual
+	if len(nodeClaims) > 0 {
+		reason = nodeclaimutils.TerminationReason(nodeClaims[0])
+	}
+	snapshot := placement.NewSnapshot(node, string(reason), c.clock.Now(), pods)
+	stored := node.DeepCopy()
+	node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DrainSnapshotAnnotationKey: snapshot.Summary().String()})
+	// We use client.MergeFromWithOptimisticLock so that the snapshot is only recorded once
+	if err = c.kubeClient.Patch(ctx, node, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return err
 	}
-	unDrainablePods := lo.Reject(pods, func(p *corev1.Pod, _ int) bool {
-		return pod.IsDrainable(p, clk)
+	c.placements.Track(snapshot)
+	podNames := lo.Map(snapshot.Pods, func(p placement.Pod, _ int) string {
+		return lo.Ternary(p.Owner != "", fmt.Sprintf("%s/%s (%s)", p.Namespace, p.Name, p.Owner), fmt.Sprintf("%s/%s", p.Namespace, p.Name))
 	})
-	// Filter out VolumeAttachments associated with non-drain-able Pods
-	// Match on Pod -> PersistentVolumeClaim -> PersistentVolume Name <- VolumeAttachment
-	shouldFilterOutVolume := sets.New[string]()
-	for _, p := range unDrainablePods {
-		for _, v := range p.Spec.Volumes {
-			pvc, err := volumeutil.GetPersistentVolumeClaim(ctx, kubeClient, p, v)
-			if errors.IsNotFound(err) {
-				continue
-			}
-			if err != nil {
-				return nil, err
-			}
-			if pvc != nil {
-				shouldFilterOutVolume.Insert(pvc.Spec.VolumeName)
-			}
+	log.FromContext(ctx).WithValues("reason", reason, "pod-count", len(snapshot.Pods), "pods", lo.Slice(podNames, 0, placement.MaxSummaryPods)).V(1).Info("snapshotted pod placement before draining")
+	c.recorder.Publish(terminatorevents.NodeDrainSnapshot(node, string(reason), podNames))
+	return nil
+}
+
+func (c *Controller) deleteAllNodeClaims(ctx context.Context, node *corev1.Node, nodeClaims ...*v1.NodeClaim) error {
+	// If we still get the NodeClaim, but it's already marked as terminating, we don't need to call Delete again
+	nodeClaims = lo.Filter(nodeClaims, func(nc *v1.NodeClaim, _ int) bool { return nc.DeletionTimestamp.IsZero() })
+	if len(nodeClaims) == 0 {
+		return nil
+	}
+	// The Node may have been deleted because its instance is gone, e.g. by the cloud-controller-manager, in which case
+	// the NodeClaim was interrupted rather than deleted by a user
+	terminated, err := c.instanceTerminated(ctx, node)
+	if err != nil {
+		return err
+	}
+	reason := lo.Ternary(terminated, v1.TerminationReasonInterruption, v1.TerminationReasonManual)
+	for _, nodeClaim := range nodeClaims {
+		if err := nodeclaimutils.Delete(ctx, c.kubeClient, nodeClaim, reason); err != nil {
+			return client.IgnoreNotFound(err)
 		}
 	}
-	filteredVolumeAttachments := lo.Reject(volumeAttachments, func(v *storagev1.VolumeAttachment, _ int) bool {
-		pvName := v.Spec.Source.PersistentVolumeName
-		return pvName == nil || shouldFilterOutVolume.Has(*pvName)
-	})
-	return filteredVolumeAttachments, nil
+	return nil
 }
 
-func (c *Controller) removeFinalizer(ctx context.Context, n *corev1.Node) error {
+func (c *Controller) ensureVolumesDetached(ctx context.Context, node *corev1.Node) (volumesDetached bool, err error) {
+	volumeAttachments, err := nodeutils.GetBlockingVolumeAttachments(ctx, c.kubeClient, node, c.clock)
+	if err != nil {
+		return false, err
+	}
+	return len(volumeAttachments) == 0, nil
+}
+
+func (c *Controller) removeFinalizer(ctx context.Context, n *corev1.Node, nodeClaims ...*v1.NodeClaim) error {
 	stored := n.DeepCopy()
 	controllerutil.RemoveFinalizer(n, v1.TerminationFinalizer)
 	if !equality.Semantic.DeepEqual(stored, n) {
@@ -251,7 +330,13 @@ func (c *Controller) removeFinalizer(ctx context.Context, n *corev1.Node) error
 			return client.IgnoreNotFound(fmt.Errorf("removing finalizer, %w", err))
 		}
 
+		// The Node is attributed to the reason that its NodeClaim was terminated, or treated as manually deleted if it has no NodeClaim
+		reason := v1.TerminationReasonManual
+		if len(nodeClaims) > 0 {
+			reason = nodeclaimutils.TerminationReason(nodeClaims[0])
+		}
 		metrics.NodesTerminatedTotal.Inc(map[string]string{
+			metrics.ReasonLabel:   string(reason),
 			metrics.NodePoolLabel: n.Labels[v1.NodePoolLabelKey],
 		})
 
@@ -285,7 +370,7 @@ func (c *Controller) nodeTerminationTime(node *corev1.Node, nodeClaims ...*v1.No
 	return &expirationTime, nil
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("node.termination").
 		For(&corev1.Node{}, builder.WithPredicates(nodeutils.IsManagedPredicateFuncs(c.cloudProvider))).
@@ -293,10 +378,10 @@ func (c *Controller) Register(_ context.Context, m manager.Manager) error {
 			controller.Options{
 				RateLimiter: workqueue.NewTypedMaxOfRateLimiter[reconcile.Request](
 					workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](100*time.Millisecond, 10*time.Second),
-					// 10 qps, 100 bucket size
-					&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+					// 10 qps, 100 bucket size by default
+					&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(options.FromContext(ctx).ControllerQPS), options.FromContext(ctx).ControllerBurst)},
 				),
-				MaxConcurrentReconciles: 100,
+				MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("node.termination", 100),
 			},
 		).
 		Complete(reconcile.AsReconciler(m.GetClient(), c))
@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// Controller records the nodes that the replacements of drained pods are rescheduled to. Together with the snapshot
+// of the pods on a node when it started draining, this shows where the workloads impacted by a disruption went.
+type Controller struct {
+	tracker  *Tracker
+	recorder events.Recorder
+}
+
+// NewController constructs a controller instance
+func NewController(tracker *Tracker, recorder events.Recorder) *Controller {
+	return &Controller{
+		tracker:  tracker,
+		recorder: recorder,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "node.termination.placement")
+
+	origin, ok := c.tracker.Resolve(pod)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	log.FromContext(ctx).WithValues(
+		"Pod", klog.KObj(pod),
+		"Node", klog.KRef("", pod.Spec.NodeName),
+		"drained-node", origin.Node,
+		"reason", origin.Reason,
+	).Info("rescheduled drained pod")
+	c.recorder.Publish(terminatorevents.PodRescheduled(pod, origin.Node, origin.Reason))
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("node.termination.placement").
+		// Only the pods whose owners had pods drained can be replacements, so every other pod update is filtered out
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return c.tracker.Tracks(o.(*corev1.Pod))
+		}))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("node.termination.placement", 10)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
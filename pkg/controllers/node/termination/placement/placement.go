@@ -0,0 +1,201 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+const (
+	// TrackerTTL is how long after a node is drained that the pods which replace its pods are attributed to the drain
+	TrackerTTL = time.Hour
+	// MaxSummaryPods is the number of pods that are listed in the summary of a snapshot that's recorded on the node
+	MaxSummaryPods = 50
+)
+
+// Snapshot is the placement of the pods on a node when it started draining, so that it can be shown which workloads
+// were impacted by a disruption. Its summary is recorded on the node so that it's only taken once and can be tracked
+// again if the controller restarts while the node drains.
+type Snapshot struct {
+	Node   string    `json:"node"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+	Pods   []Pod     `json:"pods"`
+	// Omitted is the number of pods that were left out of the summary of the snapshot
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// Pod is a pod that was placed on a node when the node started draining
+type Pod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Owner is the kind and name of the pod's controller, e.g. ReplicaSet/web-5d4f8
+	Owner string `json:"owner,omitempty"`
+	// OwnerUID is only set for pods that are expected to be replaced on another node once they're drained
+	OwnerUID types.UID `json:"ownerUID,omitempty"`
+}
+
+// NewSnapshot snapshots the placement of the passed pods, ignoring the pods that aren't running on the node
+func NewSnapshot(node *corev1.Node, reason string, t time.Time, pods []*corev1.Pod) Snapshot {
+	snapshot := Snapshot{Node: node.Name, Reason: reason, Time: t.UTC().Truncate(time.Second)}
+	for _, pod := range pods {
+		if !podutils.IsActive(pod) {
+			continue
+		}
+		p := Pod{Namespace: pod.Namespace, Name: pod.Name}
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			p.Owner = fmt.Sprintf("%s/%s", owner.Kind, owner.Name)
+			if podutils.IsReschedulable(pod) {
+				p.OwnerUID = owner.UID
+			}
+		}
+		snapshot.Pods = append(snapshot.Pods, p)
+	}
+	return snapshot
+}
+
+// ParseSnapshot parses a snapshot that was recorded on a node
+func ParseSnapshot(value string) (Snapshot, error) {
+	snapshot := Snapshot{}
+	if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("parsing drain snapshot, %w", err)
+	}
+	return snapshot, nil
+}
+
+// Summary returns the snapshot with at most MaxSummaryPods pods, so that recording it on the node doesn't grow the node
+// with the number of pods that were on it. The pods that are expected to be rescheduled are listed first, since they're
+// the ones whose replacements are tracked.
+func (s Snapshot) Summary() Snapshot {
+	rescheduled, rest := lo.FilterReject(s.Pods, func(p Pod, _ int) bool { return p.OwnerUID != "" })
+	pods := append(rescheduled, rest...)
+	s.Omitted += max(len(pods)-MaxSummaryPods, 0)
+	s.Pods = lo.Slice(pods, 0, MaxSummaryPods)
+	return s
+}
+
+func (s Snapshot) String() string {
+	return string(lo.Must(json.Marshal(s)))
+}
+
+// Origin is the drained node that a replacement pod was rescheduled from
+type Origin struct {
+	Node   string
+	Reason string
+	Time   time.Time
+
+	remaining int
+	resolved  sets.Set[types.UID]
+}
+
+// Tracker tracks the owners of the pods that were drained from nodes so that the nodes that their replacements are
+// rescheduled to can be recorded
+type Tracker struct {
+	mu     sync.Mutex
+	owners *cache.Cache
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		owners: cache.New(TrackerTTL, time.Minute),
+	}
+}
+
+// Track starts tracking the replacements of the pods in the snapshot. Snapshots that are already tracked are ignored.
+func (t *Tracker) Track(snapshot Snapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for ownerUID, pods := range lo.GroupBy(lo.Filter(snapshot.Pods, func(p Pod, _ int) bool { return p.OwnerUID != "" }), func(p Pod) types.UID { return p.OwnerUID }) {
+		origins := t.origins(ownerUID)
+		if lo.ContainsBy(origins, func(o *Origin) bool { return o.Node == snapshot.Node }) {
+			continue
+		}
+		t.owners.SetDefault(string(ownerUID), append(origins, &Origin{
+			Node:      snapshot.Node,
+			Reason:    snapshot.Reason,
+			Time:      snapshot.Time,
+			remaining: len(pods),
+			resolved:  sets.New[types.UID](),
+		}))
+	}
+}
+
+// Tracks returns true if the pod's controller owned pods that were drained and aren't all rescheduled yet
+func (t *Tracker) Tracks(pod *corev1.Pod) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return false
+	}
+	_, ok := t.owners.Get(string(owner.UID))
+	return ok
+}
+
+// Resolve returns the drained node that the pod replaces a pod from, if any. Each drained pod is only resolved once,
+// by the first replacement that's bound to a different node.
+func (t *Tracker) Resolve(pod *corev1.Pod) (Origin, bool) {
+	if pod.Spec.NodeName == "" || t.owners.ItemCount() == 0 {
+		return Origin{}, false
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return Origin{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	origins := t.origins(owner.UID)
+	if lo.ContainsBy(origins, func(o *Origin) bool { return o.resolved.Has(pod.UID) }) {
+		return Origin{}, false
+	}
+	origin, ok := lo.Find(origins, func(o *Origin) bool {
+		return o.remaining > 0 && o.Node != pod.Spec.NodeName && !pod.CreationTimestamp.Time.Before(o.Time)
+	})
+	if !ok {
+		return Origin{}, false
+	}
+	origin.remaining--
+	origin.resolved.Insert(pod.UID)
+	if lo.EveryBy(origins, func(o *Origin) bool { return o.remaining == 0 }) {
+		t.owners.Delete(string(owner.UID))
+	}
+	return *origin, true
+}
+
+// Reset stops tracking all drained pods
+func (t *Tracker) Reset() {
+	t.owners.Flush()
+}
+
+func (t *Tracker) origins(ownerUID types.UID) []*Origin {
+	if origins, ok := t.owners.Get(string(ownerUID)); ok {
+		return origins.([]*Origin)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/placement"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var env *test.Environment
+var recorder *test.EventRecorder
+var tracker *placement.Tracker
+var controller *placement.Controller
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Placement")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	recorder = test.NewEventRecorder()
+	tracker = placement.NewTracker()
+	controller = placement.NewController(tracker, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	recorder.Reset()
+	tracker.Reset()
+})
+
+var _ = Describe("Placement", func() {
+	var drained *corev1.Node
+	var replacement *corev1.Node
+	var owner metav1.OwnerReference
+
+	BeforeEach(func() {
+		drained = test.Node()
+		replacement = test.Node()
+		owner = metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", UID: "web-uid", Controller: &[]bool{true}[0]}
+	})
+
+	Context("Snapshot", func() {
+		It("should snapshot the pods on the node and their owners", func() {
+			owned := test.Pod(test.PodOptions{NodeName: drained.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			unowned := test.Pod(test.PodOptions{NodeName: drained.Name})
+			snapshot := placement.NewSnapshot(drained, string(v1.DisruptionReasonUnderutilized), time.Now(), []*corev1.Pod{owned, unowned})
+
+			Expect(snapshot.Node).To(Equal(drained.Name))
+			Expect(snapshot.Reason).To(Equal(string(v1.DisruptionReasonUnderutilized)))
+			Expect(snapshot.Pods).To(ConsistOf(
+				placement.Pod{Namespace: owned.Namespace, Name: owned.Name, Owner: "ReplicaSet/web", OwnerUID: owner.UID},
+				placement.Pod{Namespace: unowned.Namespace, Name: unowned.Name},
+			))
+		})
+		It("should not snapshot pods that are terminal", func() {
+			pod := test.Pod(test.PodOptions{NodeName: drained.Name, Phase: corev1.PodSucceeded})
+			snapshot := placement.NewSnapshot(drained, string(v1.DisruptionReasonEmpty), time.Now(), []*corev1.Pod{pod})
+			Expect(snapshot.Pods).To(BeEmpty())
+		})
+		It("should not expect daemonset pods to be rescheduled", func() {
+			pod := test.Pod(test.PodOptions{NodeName: drained.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "ds", UID: "ds-uid", Controller: &[]bool{true}[0]},
+			}}})
+			snapshot := placement.NewSnapshot(drained, string(v1.DisruptionReasonEmpty), time.Now(), []*corev1.Pod{pod})
+			Expect(snapshot.Pods).To(ConsistOf(placement.Pod{Namespace: pod.Namespace, Name: pod.Name, Owner: "DaemonSet/ds"}))
+		})
+		It("should round trip through its string representation", func() {
+			pod := test.Pod(test.PodOptions{NodeName: drained.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			snapshot := placement.NewSnapshot(drained, string(v1.DisruptionReasonDrifted), time.Now(), []*corev1.Pod{pod})
+			parsed, err := placement.ParseSnapshot(snapshot.String())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(Equal(snapshot))
+		})
+		It("should cap the pods in its summary and list the rescheduled pods first", func() {
+			unowned := lo.Times(placement.MaxSummaryPods, func(_ int) *corev1.Pod { return test.Pod(test.PodOptions{NodeName: drained.Name}) })
+			owned := test.Pod(test.PodOptions{NodeName: drained.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			snapshot := placement.NewSnapshot(drained, string(v1.DisruptionReasonDrifted), time.Now(), append(unowned, owned))
+			Expect(snapshot.Pods).To(HaveLen(placement.MaxSummaryPods + 1))
+
+			summary := snapshot.Summary()
+			Expect(summary.Pods).To(HaveLen(placement.MaxSummaryPods))
+			Expect(summary.Pods[0]).To(Equal(placement.Pod{Namespace: owned.Namespace, Name: owned.Name, Owner: "ReplicaSet/web", OwnerUID: owner.UID}))
+			Expect(summary.Omitted).To(Equal(1))
+			// The snapshot itself is left whole
+			Expect(snapshot.Pods).To(HaveLen(placement.MaxSummaryPods + 1))
+		})
+	})
+	Context("Rescheduling", func() {
+		var snapshot placement.Snapshot
+
+		BeforeEach(func() {
+			drainedPod := test.Pod(test.PodOptions{NodeName: drained.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			snapshot = placement.NewSnapshot(drained, string(v1.DisruptionReasonUnderutilized), time.Now().Add(-time.Minute), []*corev1.Pod{drainedPod})
+		})
+		It("should record the node that a drained pod was rescheduled to", func() {
+			tracker.Track(snapshot)
+			pod := test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(1))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Rescheduled to node %s after node %s was drained for %s", replacement.Name, drained.Name, v1.DisruptionReasonUnderutilized))).To(BeTrue())
+		})
+		It("should only record a rescheduled pod once", func() {
+			tracker.Track(snapshot)
+			pod := test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(1))
+		})
+		It("should only record as many rescheduled pods as were drained", func() {
+			tracker.Track(snapshot)
+			pods := []*corev1.Pod{
+				test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}}),
+				test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}}),
+			}
+			for _, pod := range pods {
+				ExpectApplied(ctx, env.Client, pod)
+				ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			}
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(1))
+		})
+		It("should not record pods that are scheduled to the drained node", func() {
+			tracker.Track(snapshot)
+			pod := test.Pod(test.PodOptions{NodeName: drained.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(0))
+		})
+		It("should not record pods that aren't bound yet", func() {
+			tracker.Track(snapshot)
+			pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(0))
+		})
+		It("should not record pods from owners that weren't drained", func() {
+			tracker.Track(snapshot)
+			pod := test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "other", UID: "other-uid", Controller: &[]bool{true}[0]},
+			}}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(0))
+		})
+		It("should not record pods that were created before the node was drained", func() {
+			snapshot.Time = time.Now().Add(time.Hour)
+			tracker.Track(snapshot)
+			pod := test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(0))
+		})
+		It("should only track the pods whose owners had pods drained", func() {
+			other := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "other", UID: "other-uid", Controller: &[]bool{true}[0]},
+			}}})
+			pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+			Expect(tracker.Tracks(pod)).To(BeFalse())
+			tracker.Track(snapshot)
+			Expect(tracker.Tracks(pod)).To(BeTrue())
+			Expect(tracker.Tracks(other)).To(BeFalse())
+			Expect(tracker.Tracks(test.Pod())).To(BeFalse())
+		})
+		It("should only track a node's snapshot once", func() {
+			tracker.Track(snapshot)
+			tracker.Track(snapshot)
+			for range 2 {
+				pod := test.Pod(test.PodOptions{NodeName: replacement.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}}})
+				ExpectApplied(ctx, env.Client, pod)
+				ExpectObjectReconciled(ctx, env.Client, controller, pod)
+			}
+			Expect(recorder.Calls(events.Rescheduled)).To(Equal(1))
+		})
+	})
+})
@@ -18,6 +18,7 @@ package termination_test
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -35,11 +36,16 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/placement"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -68,8 +74,8 @@ var _ = BeforeSuite(func() {
 
 	cloudProvider = fake.NewCloudProvider()
 	recorder = test.NewEventRecorder()
-	queue = terminator.NewTestingQueue(env.Client, recorder)
-	terminationController = termination.NewController(fakeClock, env.Client, cloudProvider, terminator.NewTerminator(fakeClock, env.Client, queue, recorder), recorder)
+	queue = terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock)
+	terminationController = termination.NewController(fakeClock, env.Client, cloudProvider, terminator.NewTerminator(fakeClock, env.Client, queue, recorder), placement.NewTracker(), providerid.NewMapping(), recorder)
 })
 
 var _ = AfterSuite(func() {
@@ -82,9 +88,11 @@ var _ = Describe("Termination", func() {
 	var nodePool *v1.NodePool
 
 	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
 		fakeClock.SetTime(time.Now())
 		cloudProvider.Reset()
-		*queue = lo.FromPtr(terminator.NewTestingQueue(env.Client, recorder))
+		recorder.Reset()
+		*queue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
 
 		nodePool = test.NodePool()
 		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{v1.TerminationFinalizer}}})
@@ -112,6 +120,50 @@ var _ = Describe("Termination", func() {
 			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should label the terminated nodes metric with the reason that the nodeclaim was terminated", func() {
+			nodeClaim.Status.TerminationReason = v1.TerminationReasonDrift
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectNotFound(ctx, env.Client, node)
+			ExpectMetricCounterValue(metrics.NodesTerminatedTotal, 1, map[string]string{
+				metrics.ReasonLabel:   string(v1.TerminationReasonDrift),
+				metrics.NodePoolLabel: node.Labels[v1.NodePoolLabelKey],
+			})
+		})
+		DescribeTable("should record the termination reason when the node is deleted directly",
+			func(ready bool, instanceExists bool, reason v1.TerminationReason) {
+				ExpectApplied(ctx, env.Client, node, nodeClaim)
+				if ready {
+					ExpectMakeNodesReady(ctx, env.Client, node)
+				} else {
+					ExpectMakeNodesNotReady(ctx, env.Client, node)
+				}
+				if !instanceExists {
+					cloudProvider.CreatedNodeClaims = map[string]*v1.NodeClaim{}
+				}
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+				node = ExpectNodeExists(ctx, env.Client, node.Name)
+				ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+				Expect(ExpectExists(ctx, env.Client, nodeClaim).Status.TerminationReason).To(Equal(reason))
+			},
+			Entry("manual when the node is ready", true, true, v1.TerminationReasonManual),
+			Entry("manual when the node is ready but its instance no longer exists", true, false, v1.TerminationReasonManual),
+			Entry("manual when the node isn't ready but its instance still exists", false, true, v1.TerminationReasonManual),
+			Entry("interruption when the node isn't ready and its instance no longer exists", false, false, v1.TerminationReasonInterruption),
+		)
+		It("shouldn't overwrite the termination reason of a nodeclaim that is already being deleted", func() {
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(nodeclaimutils.Delete(ctx, env.Client, nodeClaim, v1.TerminationReasonDrift)).To(Succeed())
+			ExpectMakeNodesNotReady(ctx, env.Client, node)
+			cloudProvider.CreatedNodeClaims = map[string]*v1.NodeClaim{}
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).Status.TerminationReason).To(Equal(v1.TerminationReasonDrift))
+		})
 		It("should ignore nodes not managed by this Karpenter instance", func() {
 			delete(node.Labels, "karpenter.test.sh/testnodeclass")
 			node.Labels = lo.Assign(node.Labels, map[string]string{"karpenter.test.sh/unmanagednodeclass": "default"})
@@ -190,6 +242,18 @@ var _ = Describe("Termination", func() {
 			node = ExpectNodeExists(ctx, env.Client, node.Name)
 			Expect(node.Labels[corev1.LabelNodeExcludeBalancers]).Should(Equal("karpenter"))
 		})
+		It("should not drain virtual nodes", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.VirtualNodeAnnotationKey: "true"})
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(queue.Has(node, pod)).To(BeFalse())
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectNotFound(ctx, env.Client, node)
+		})
 		It("should not evict pods that tolerate karpenter disruption taint with equal operator", func() {
 			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
 			podSkip := test.Pod(test.PodOptions{
@@ -447,6 +511,83 @@ var _ = Describe("Termination", func() {
 			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should evict pods in drain-last namespaces after other non-critical pods", func() {
+			ns := test.Namespace()
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DrainLastNamespaces: []string{ns.Name}}))
+			ExpectApplied(ctx, env.Client, ns)
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podDrainLast := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, OwnerReferences: defaultOwnerRefs}})
+
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podEvict, podDrainLast)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectSingletonReconciled(ctx, queue)
+
+			// Expect podEvict to be evicting while the drain-last pod keeps running
+			EventuallyExpectTerminating(ctx, env.Client, podEvict)
+			ConsistentlyExpectNotTerminating(ctx, env.Client, podDrainLast)
+			ExpectDeleted(ctx, env.Client, podEvict)
+
+			// Expect the drain-last pod to be evicted once the other pods are gone
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectSingletonReconciled(ctx, queue)
+			EventuallyExpectTerminating(ctx, env.Client, podDrainLast)
+		})
+		It("should evict pods in the order of their drain-order annotation with the Annotation drain ordering", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DrainOrdering: lo.ToPtr(options.DrainOrderingAnnotation)}))
+			podFirst := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs, Annotations: map[string]string{v1.DrainOrderAnnotationKey: "-1"}}})
+			podSecond := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podFirst, podSecond)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectSingletonReconciled(ctx, queue)
+
+			// Expect the pod with the lower drain order to be evicting while the other pod keeps running
+			EventuallyExpectTerminating(ctx, env.Client, podFirst)
+			ConsistentlyExpectNotTerminating(ctx, env.Client, podSecond)
+			ExpectDeleted(ctx, env.Client, podFirst)
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectSingletonReconciled(ctx, queue)
+			EventuallyExpectTerminating(ctx, env.Client, podSecond)
+		})
+		It("should evict Job pods last with the OwnerKind drain ordering", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DrainOrdering: lo.ToPtr(options.DrainOrderingOwnerKind)}))
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podJob := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Name:       "job",
+				UID:        "1234567890",
+			}}}})
+
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podEvict, podJob)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectSingletonReconciled(ctx, queue)
+
+			// Expect podEvict to be evicting while the Job pod keeps running
+			EventuallyExpectTerminating(ctx, env.Client, podEvict)
+			ConsistentlyExpectNotTerminating(ctx, env.Client, podJob)
+			ExpectDeleted(ctx, env.Client, podEvict)
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectSingletonReconciled(ctx, queue)
+			EventuallyExpectTerminating(ctx, env.Client, podJob)
+		})
 		It("should evict non-critical pods first", func() {
 			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
 			podNodeCritical := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: "system-node-critical", ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
@@ -836,6 +977,165 @@ var _ = Describe("Termination", func() {
 			})
 		})
 	})
+	Context("Deletion Blocks", func() {
+		var blockKey string
+		BeforeEach(func() {
+			blockKey = v1.DeletionBlockAnnotationKeyPrefix + "checkpointer"
+		})
+		It("should not drain or delete nodes until their deletion blocks are removed", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: "30m"})
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			result := ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(result.RequeueAfter).To(BeNumerically(">", 29*time.Minute))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", 30*time.Minute))
+			Expect(recorder.DetectedEvent("Deletion blocked by checkpointer")).To(BeTrue())
+			Expect(queue.Has(node, pod)).To(BeFalse())
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectExists(ctx, env.Client, node)
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			stored := node.DeepCopy()
+			delete(node.Annotations, blockKey)
+			Expect(env.Client.Patch(ctx, node, client.MergeFrom(stored))).To(Succeed())
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(queue.Has(node, pod)).To(BeTrue())
+		})
+		It("should skip deletion blocks when the node isn't ready and its instance no longer exists", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: "30m"})
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+			ExpectMakeNodesNotReady(ctx, env.Client, node)
+			cloudProvider.CreatedNodeClaims = map[string]*v1.NodeClaim{}
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(recorder.DetectedEvent("Deletion blocked by checkpointer")).To(BeFalse())
+			Expect(queue.Has(node, pod)).To(BeTrue())
+		})
+		It("should honor deletion blocks when the node isn't ready but its instance still exists", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: "30m"})
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+			ExpectMakeNodesNotReady(ctx, env.Client, node)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			result := ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(recorder.DetectedEvent("Deletion blocked by checkpointer")).To(BeTrue())
+			Expect(queue.Has(node, pod)).To(BeFalse())
+		})
+		It("should release deletion blocks once their TTL has elapsed", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: "30m"})
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectExists(ctx, env.Client, node)
+
+			fakeClock.Step(31 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should cap deletion blocks at the max deletion block TTL", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MaxDeletionBlockTTL: lo.ToPtr(10 * time.Minute)}))
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: "30m"})
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			result := ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(result.RequeueAfter).To(BeNumerically("<=", 10*time.Minute))
+
+			fakeClock.Step(11 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should hold nodes for the max deletion block TTL when the block doesn't set a duration", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: ""})
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			result := ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+		})
+		It("should ignore deletion blocks once the nodeclaim's termination grace period expires", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{blockKey: "30m"})
+			nodeClaim.Annotations = map[string]string{
+				v1.NodeClaimTerminationTimestampAnnotationKey: fakeClock.Now().Add(time.Minute).Format(time.RFC3339),
+			}
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectExists(ctx, env.Client, node)
+
+			fakeClock.Step(5 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			ExpectNotFound(ctx, env.Client, node)
+		})
+	})
+	Context("Drain Snapshot", func() {
+		It("should record the pods on the node and their owners before draining", func() {
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			Expect(queue.Has(node, pod)).To(BeTrue())
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Annotations).To(HaveKey(v1.DrainSnapshotAnnotationKey))
+			snapshot, err := placement.ParseSnapshot(node.Annotations[v1.DrainSnapshotAnnotationKey])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.Node).To(Equal(node.Name))
+			Expect(snapshot.Reason).To(Equal(string(v1.TerminationReasonManual)))
+			Expect(snapshot.Pods).To(ConsistOf(placement.Pod{Namespace: pod.Namespace, Name: pod.Name, Owner: "ReplicaSet/rs", OwnerUID: "1234567890"}))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Draining 1 pods for %s: %s/%s (ReplicaSet/rs)", v1.TerminationReasonManual, pod.Namespace, pod.Name))).To(BeTrue())
+		})
+		It("should record the termination reason of the nodeclaim", func() {
+			nodeClaim.Status.TerminationReason = v1.TerminationReasonDrift
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			snapshot, err := placement.ParseSnapshot(node.Annotations[v1.DrainSnapshotAnnotationKey])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.Reason).To(Equal(string(v1.TerminationReasonDrift)))
+		})
+		It("should only record the snapshot once", func() {
+			pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			value := node.Annotations[v1.DrainSnapshotAnnotationKey]
+
+			ExpectApplied(ctx, env.Client, test.Pod(test.PodOptions{NodeName: node.Name}))
+			fakeClock.Step(time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Annotations[v1.DrainSnapshotAnnotationKey]).To(Equal(value))
+			Expect(recorder.Calls(events.DrainSnapshot)).To(Equal(1))
+		})
+	})
 	Context("Metrics", func() {
 		It("should fire the terminationSummary metric when deleting nodes", func() {
 			ExpectApplied(ctx, env.Client, node, nodeClaim)
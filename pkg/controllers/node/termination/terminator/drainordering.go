@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// drainOrdering splits pods into the groups that they're drained in, where each group is only drained once the groups
+// before it are gone
+type drainOrdering func(pods []*corev1.Pod) [][]*corev1.Pod
+
+// newDrainOrdering returns the ordering of the non-critical, non-daemon pods on a node for the drain ordering that the
+// operator is configured with
+func newDrainOrdering(ctx context.Context, kubeClient client.Client) (drainOrdering, error) {
+	switch options.FromContext(ctx).DrainOrdering {
+	case options.DrainOrderingPriorityClass:
+		// higher priority pods are drained first
+		return orderBy(func(pod *corev1.Pod) int64 { return -int64(lo.FromPtr(pod.Spec.Priority)) }), nil
+	case options.DrainOrderingPDBTightness:
+		pdbs := &policyv1.PodDisruptionBudgetList{}
+		if err := kubeClient.List(ctx, pdbs); err != nil {
+			return nil, fmt.Errorf("listing pod disruption budgets, %w", err)
+		}
+		// pods that their budgets allow more disruptions for are drained first so that they aren't held back by the pods
+		// that have to wait on their budgets
+		return orderBy(func(pod *corev1.Pod) int64 { return -disruptionsAllowed(pod, pdbs.Items) }), nil
+	case options.DrainOrderingOwnerKind:
+		// Job pods are drained last since they can't resume where they left off once they're evicted
+		return orderBy(func(pod *corev1.Pod) int64 { return lo.Ternary[int64](podutil.IsOwnedByJob(pod), 1, 0) }), nil
+	case options.DrainOrderingAnnotation:
+		return orderBy(drainOrder), nil
+	default:
+		return func(pods []*corev1.Pod) [][]*corev1.Pod { return [][]*corev1.Pod{pods} }, nil
+	}
+}
+
+// orderBy groups pods with the same key together, ordering the groups by their key in ascending order
+func orderBy(key func(*corev1.Pod) int64) drainOrdering {
+	return func(pods []*corev1.Pod) [][]*corev1.Pod {
+		groups := lo.GroupBy(pods, key)
+		keys := lo.Keys(groups)
+		slices.Sort(keys)
+		return lo.Map(keys, func(k int64, _ int) []*corev1.Pod { return groups[k] })
+	}
+}
+
+// disruptionsAllowed returns the fewest disruptions that the budgets selecting the pod allow. Pods that aren't selected
+// by any budget can always be disrupted.
+func disruptionsAllowed(pod *corev1.Pod, pdbs []policyv1.PodDisruptionBudget) int64 {
+	allowed := int64(math.MaxInt32)
+	for _, pdb := range pdbs {
+		if pdb.Namespace != pod.Namespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		allowed = min(allowed, int64(pdb.Status.DisruptionsAllowed))
+	}
+	return allowed
+}
+
+// drainOrder returns the order of the pod from its karpenter.sh/drain-order annotation, pods without a valid order are
+// ordered as 0
+func drainOrder(pod *corev1.Pod) int64 {
+	order, err := strconv.ParseInt(pod.Annotations[v1.DrainOrderAnnotationKey], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return order
+}
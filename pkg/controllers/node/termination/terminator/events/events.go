@@ -18,19 +18,24 @@ package events
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/events"
 )
 
+// maxSnapshotPods is the number of pods that are listed in a drain snapshot event
+const maxSnapshotPods = 10
+
 func EvictPod(pod *corev1.Pod, message string) events.Event {
 	return events.Event{
 		InvolvedObject: pod,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "Evicted",
+		Reason:         events.Evicted,
 		Message:        "Evicted pod: " + message,
 		DedupeValues:   []string{pod.Name},
 	}
@@ -40,27 +45,63 @@ func DisruptPodDelete(pod *corev1.Pod, gracePeriodSeconds *int64, nodeGracePerio
 	return events.Event{
 		InvolvedObject: pod,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "Disrupted",
+		Reason:         events.Disrupted,
 		Message:        fmt.Sprintf("Deleting the pod to accommodate the terminationTime %v of the node. The pod was granted %v seconds of grace-period of its %v terminationGracePeriodSeconds. This bypasses the PDB of the pod and the do-not-disrupt annotation.", *nodeGracePeriodTerminationTime, *gracePeriodSeconds, pod.Spec.TerminationGracePeriodSeconds),
 		DedupeValues:   []string{pod.Name},
 	}
 }
 
+func PodRescheduled(pod *corev1.Pod, drainedNode, reason string) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Rescheduled,
+		Message:        fmt.Sprintf("Rescheduled to node %s after node %s was drained for %s", pod.Spec.NodeName, drainedNode, reason),
+		DedupeValues:   []string{string(pod.UID)},
+	}
+}
+
+// NodeDrainSnapshot lists the pods that are drained from the node. Only the first pods are listed to keep the event
+// small, the rest are counted.
+func NodeDrainSnapshot(node *corev1.Node, reason string, pods []string) events.Event {
+	listed := strings.Join(lo.Slice(pods, 0, maxSnapshotPods), ", ")
+	if len(pods) > maxSnapshotPods {
+		listed = fmt.Sprintf("%s and %d more", listed, len(pods)-maxSnapshotPods)
+	}
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DrainSnapshot,
+		Message:        fmt.Sprintf("Draining %d pods for %s: %s", len(pods), reason, listed),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
 func NodeFailedToDrain(node *corev1.Node, err error) events.Event {
 	return events.Event{
 		InvolvedObject: node,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "FailedDraining",
+		Reason:         events.FailedDraining,
 		Message:        fmt.Sprintf("Failed to drain node, %s", err),
 		DedupeValues:   []string{node.Name},
 	}
 }
 
+func NodeDeletionBlocked(node *corev1.Node, blockers []string, expirationTime string) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DeletionBlocked,
+		Message:        fmt.Sprintf("Deletion blocked by %s until %s", strings.Join(blockers, ", "), expirationTime),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
 func NodeTerminationGracePeriodExpiring(node *corev1.Node, terminationTime string) events.Event {
 	return events.Event{
 		InvolvedObject: node,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "TerminationGracePeriodExpiring",
+		Reason:         events.TerminationGracePeriodExpiring,
 		Message:        fmt.Sprintf("All pods will be deleted by %s", terminationTime),
 		DedupeValues:   []string{node.Name},
 	}
@@ -70,7 +111,7 @@ func NodeClaimTerminationGracePeriodExpiring(nodeClaim *v1.NodeClaim, terminatio
 	return events.Event{
 		InvolvedObject: nodeClaim,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "TerminationGracePeriodExpiring",
+		Reason:         events.TerminationGracePeriodExpiring,
 		Message:        fmt.Sprintf("All pods will be deleted by %s", terminationTime),
 		DedupeValues:   []string{nodeClaim.Name},
 	}
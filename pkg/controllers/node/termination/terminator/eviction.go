@@ -21,19 +21,22 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/awslabs/operatorpkg/singleton"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
-	controllerruntime "sigs.k8s.io/controller-runtime"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -44,7 +47,10 @@ import (
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/workloadcluster"
 	"sigs.k8s.io/karpenter/pkg/utils/node"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
 const (
@@ -82,43 +88,109 @@ func NewQueueKey(pod *corev1.Pod, providerID string) QueueKey {
 	}
 }
 
+// queueEntry tracks the node that an enqueued pod is being evicted from and when it was enqueued
+type queueEntry struct {
+	nodeName string
+	added    time.Time
+}
+
 type Queue struct {
 	workqueue.TypedRateLimitingInterface[QueueKey]
 
-	mu  sync.Mutex
-	set sets.Set[QueueKey]
+	mu        sync.Mutex
+	entries   map[QueueKey]queueEntry
+	nodeDepth map[string]int // node name -> number of pods waiting to be evicted from the node
+	// rebuilt tracks if the Queue has been repopulated from the nodes that were draining before it started
+	rebuilt atomic.Bool
+
+	// discoveredAPIVersion is the Eviction API version that the cluster serves, which is used unless the version is
+	// configured explicitly
+	discoveredAPIVersion string
+	// deleteNamespaces are the namespaces that opt out of PDB enforcement, whose pods are deleted rather than evicted
+	deleteNamespaces sets.Set[string]
 
 	kubeClient client.Client
 	recorder   events.Recorder
+	clock      clock.Clock
 }
 
-func NewQueue(kubeClient client.Client, recorder events.Recorder) *Queue {
+func NewQueue(ctx context.Context, kubeClient client.Client, recorder events.Recorder, clk clock.Clock) *Queue {
+	// The namespaces are validated when the options are parsed
+	deleteNamespaces, _ := options.ParseNamespaces(options.FromContext(ctx).DeleteEvictionNamespaces)
 	return &Queue{
 		TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueueWithConfig[QueueKey](
 			workqueue.NewTypedItemExponentialFailureRateLimiter[QueueKey](evictionQueueBaseDelay, evictionQueueMaxDelay),
 			workqueue.TypedRateLimitingQueueConfig[QueueKey]{
 				Name: "eviction.workqueue",
 			}),
-		set:        sets.New[QueueKey](),
-		kubeClient: kubeClient,
-		recorder:   recorder,
+		entries:              map[QueueKey]queueEntry{},
+		nodeDepth:            map[string]int{},
+		discoveredAPIVersion: options.EvictionAPIVersionV1,
+		deleteNamespaces:     deleteNamespaces,
+		kubeClient:           kubeClient,
+		recorder:             recorder,
+		clock:                clk,
 	}
 }
 
-func NewTestingQueue(kubeClient client.Client, recorder events.Recorder) *Queue {
+func NewTestingQueue(ctx context.Context, kubeClient client.Client, recorder events.Recorder, clk clock.Clock) *Queue {
+	deleteNamespaces, _ := options.ParseNamespaces(options.FromContext(ctx).DeleteEvictionNamespaces)
 	return &Queue{
 		TypedRateLimitingInterface: &controllertest.TypedQueue[QueueKey]{TypedInterface: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[QueueKey]{Name: "eviction.workqueue"})},
-		set:                        sets.New[QueueKey](),
+		entries:                    map[QueueKey]queueEntry{},
+		nodeDepth:                  map[string]int{},
+		discoveredAPIVersion:       options.EvictionAPIVersionV1,
+		deleteNamespaces:           deleteNamespaces,
 		kubeClient:                 kubeClient,
 		recorder:                   recorder,
+		clock:                      clk,
+	}
+}
+
+func (q *Queue) Register(ctx context.Context, m manager.Manager) error {
+	if options.FromContext(ctx).EvictionAPIVersion == options.EvictionAPIVersionAuto {
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(workloadcluster.Config(m))
+		if err != nil {
+			return fmt.Errorf("creating discovery client, %w", err)
+		}
+		q.discoveredAPIVersion = discoverEvictionAPIVersion(ctx, discoveryClient)
 	}
+	return m.Add(q)
 }
 
-func (q *Queue) Register(_ context.Context, m manager.Manager) error {
-	return controllerruntime.NewControllerManagedBy(m).
-		Named("eviction-queue").
-		WatchesRawSource(singleton.Source()).
-		Complete(singleton.AsReconciler(q))
+// Start implements manager.Runnable. It runs a pool of workers that evict pods as soon as they're added to the Queue,
+// until the manager stops. The Queue is rebuilt in the background and retried until it succeeds, so that pods that are
+// added by the termination controller are still evicted while rebuilding fails.
+func (q *Queue) Start(ctx context.Context) error {
+	ctx = injection.WithControllerName(ctx, "eviction-queue")
+	// Shutting down the underlying queue wakes up the workers that are waiting on it, so that they return
+	go func() {
+		<-ctx.Done()
+		q.TypedRateLimitingInterface.ShutDown()
+	}()
+	go func() {
+		// This only returns an error once the manager stops, at which point the Queue doesn't need to be rebuilt
+		_ = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			if err := q.rebuild(ctx); err != nil {
+				log.FromContext(ctx).Error(err, "failed rebuilding eviction queue")
+				return false, nil
+			}
+			q.rebuilt.Store(true)
+			return true, nil
+		})
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < options.FromContext(ctx).EvictionQueueWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q.processNextItem(ctx) {
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
 }
 
 // Add adds pods to the Queue
@@ -128,48 +200,122 @@ func (q *Queue) Add(node *corev1.Node, pods ...*corev1.Pod) {
 
 	for _, pod := range pods {
 		qk := NewQueueKey(pod, node.Spec.ProviderID)
-		if !q.set.Has(qk) {
-			q.set.Insert(qk)
+		if _, ok := q.entries[qk]; !ok {
+			q.entries[qk] = queueEntry{nodeName: node.Name, added: q.clock.Now()}
+			q.nodeDepth[node.Name]++
 			q.TypedRateLimitingInterface.Add(qk)
 		}
 	}
+	q.updateDepthMetrics(node.Name)
 }
 
 func (q *Queue) Has(node *corev1.Node, pod *corev1.Pod) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	return q.set.Has(NewQueueKey(pod, node.Spec.ProviderID))
+	_, ok := q.entries[NewQueueKey(pod, node.Spec.ProviderID)]
+	return ok
 }
 
+// remove removes the key from the Queue's tracked entries once the pod has been evicted
+func (q *Queue) remove(key QueueKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[key]
+	if !ok {
+		return
+	}
+	delete(q.entries, key)
+	if q.nodeDepth[entry.nodeName]--; q.nodeDepth[entry.nodeName] <= 0 {
+		delete(q.nodeDepth, entry.nodeName)
+	}
+	EvictionQueueDurationSeconds.Observe(q.clock.Since(entry.added).Seconds(), nil)
+	q.updateDepthMetrics(entry.nodeName)
+}
+
+// updateDepthMetrics must be called while holding the Queue's lock
+func (q *Queue) updateDepthMetrics(nodeName string) {
+	EvictionQueueDepth.Set(float64(len(q.entries)), nil)
+	if depth, ok := q.nodeDepth[nodeName]; ok {
+		EvictionQueueNodeDepth.Set(float64(depth), map[string]string{NodeLabel: nodeName})
+	} else {
+		EvictionQueueNodeDepth.Delete(map[string]string{NodeLabel: nodeName})
+	}
+}
+
+// Reconcile rebuilds the Queue if it hasn't been rebuilt yet and evicts the next pod without waiting for one to be
+// added. The workers started by Start evict pods when running under the manager, so this drives the Queue synchronously.
 func (q *Queue) Reconcile(ctx context.Context) (reconcile.Result, error) {
 	ctx = injection.WithControllerName(ctx, "eviction-queue")
+	if !q.rebuilt.Load() {
+		if err := q.rebuild(ctx); err != nil {
+			return reconcile.Result{}, fmt.Errorf("rebuilding eviction queue, %w", err)
+		}
+		q.rebuilt.Store(true)
+	}
 	// Check if the queue is empty. client-go recommends not using this function to gate the subsequent
 	// get call, but since we're popping items off the queue synchronously, there should be no synchonization
 	// issues.
 	if q.TypedRateLimitingInterface.Len() == 0 {
-		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+		return reconcile.Result{}, nil
 	}
-	// Get pod from queue. This waits until queue is non-empty.
-	item, shutdown := q.TypedRateLimitingInterface.Get()
-	if shutdown {
+	if !q.processNextItem(ctx) {
 		return reconcile.Result{}, fmt.Errorf("EvictionQueue is broken and has shutdown")
 	}
+	return reconcile.Result{}, nil
+}
 
+// processNextItem waits until a pod is in the Queue and evicts it, requeueing the pod with a backoff if the eviction
+// failed. This returns false once the Queue has shut down.
+func (q *Queue) processNextItem(ctx context.Context) bool {
+	item, shutdown := q.TypedRateLimitingInterface.Get()
+	if shutdown {
+		return false
+	}
 	defer q.TypedRateLimitingInterface.Done(item)
 
-	// Evict the pod
 	if q.Evict(ctx, item) {
 		q.TypedRateLimitingInterface.Forget(item)
-		q.mu.Lock()
-		q.set.Delete(item)
-		q.mu.Unlock()
-		return reconcile.Result{RequeueAfter: singleton.RequeueImmediately}, nil
+		q.remove(item)
+		return true
 	}
-
 	// Requeue pod if eviction failed
 	q.TypedRateLimitingInterface.AddRateLimited(item)
-	return reconcile.Result{RequeueAfter: singleton.RequeueImmediately}, nil
+	return true
+}
+
+// rebuild repopulates the Queue from the nodes that were already draining when the Queue started. The Queue is only
+// held in memory, so without this evictions wouldn't resume after a restart until the termination controller
+// reconciled each of the draining nodes again.
+func (q *Queue) rebuild(ctx context.Context) error {
+	nodes := &corev1.NodeList{}
+	if err := q.kubeClient.List(ctx, nodes); err != nil {
+		return fmt.Errorf("listing nodes, %w", err)
+	}
+	order, err := newDrainOrdering(ctx, q.kubeClient)
+	if err != nil {
+		return fmt.Errorf("resolving drain ordering, %w", err)
+	}
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		if n.DeletionTimestamp.IsZero() || !lo.ContainsBy(n.Spec.Taints, func(t corev1.Taint) bool { return t.MatchTaint(&v1.DisruptedNoScheduleTaint) }) {
+			continue
+		}
+		pods, err := node.GetPods(ctx, q.kubeClient, n)
+		if err != nil {
+			return fmt.Errorf("listing pods on node, %w", err)
+		}
+		// Only enqueue the first group of pods that are waiting on eviction so that we maintain the same eviction
+		// ordering as the terminator
+		for _, group := range groupPodsByPriority(ctx, lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, q.clock) }), order) {
+			if len(group) > 0 {
+				q.Add(n, lo.Filter(group, func(p *corev1.Pod, _ int) bool { return podutil.IsEvictable(p) })...)
+				break
+			}
+		}
+	}
+	return nil
 }
 
 // Evict returns true if successful eviction call, and false if there was an eviction-related error
@@ -180,15 +326,7 @@ func (q *Queue) Evict(ctx context.Context, key QueueKey) bool {
 		// XXX(cmcavoy): this should be unreachable, but we log it if it happens
 		log.FromContext(ctx).V(1).Error(err, "failed looking up pod eviction reason")
 	}
-	if err := q.kubeClient.SubResource("eviction").Create(ctx,
-		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}},
-		&policyv1.Eviction{
-			DeleteOptions: &metav1.DeleteOptions{
-				Preconditions: &metav1.Preconditions{
-					UID: lo.ToPtr(key.UID),
-				},
-			},
-		}); err != nil {
+	if err := q.evictOrDelete(ctx, key); err != nil {
 		var apiStatus apierrors.APIStatus
 		if errors.As(err, &apiStatus) {
 			code := apiStatus.Status().Code
@@ -208,9 +346,11 @@ func (q *Queue) Evict(ctx context.Context, key QueueKey) bool {
 				Name:      key.Name,
 				Namespace: key.Namespace,
 			}}, fmt.Errorf("evicting pod %s/%s violates a PDB", key.Namespace, key.Name)))
+			EvictionQueueRequeuesTotal.Inc(map[string]string{ReasonLabel: pdbViolationReason})
 			return false
 		}
 		log.FromContext(ctx).Error(err, "failed evicting pod")
+		EvictionQueueRequeuesTotal.Inc(map[string]string{ReasonLabel: errorReason})
 		return false
 	}
 	NodesEvictionRequestsTotal.Inc(map[string]string{CodeLabel: "200"})
@@ -218,6 +358,51 @@ func (q *Queue) Evict(ctx context.Context, key QueueKey) bool {
 	return true
 }
 
+// evictOrDelete deletes the pod if its namespace opts out of PDB enforcement, and otherwise evicts it through the
+// Eviction API version that the cluster serves. Both are preconditioned on the pod's UID, so a conflict is returned if
+// the pod was replaced.
+func (q *Queue) evictOrDelete(ctx context.Context, key QueueKey) error {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	deleteOptions := &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID: lo.ToPtr(key.UID),
+		},
+	}
+	if q.deleteNamespaces.Has(key.Namespace) {
+		// The pod's termination grace period is honored since we don't override it
+		return q.kubeClient.Delete(ctx, pod, &client.DeleteOptions{Raw: deleteOptions})
+	}
+	if q.evictionAPIVersion(ctx) == options.EvictionAPIVersionV1Beta1 {
+		return q.kubeClient.SubResource("eviction").Create(ctx, pod, &policyv1beta1.Eviction{DeleteOptions: deleteOptions})
+	}
+	return q.kubeClient.SubResource("eviction").Create(ctx, pod, &policyv1.Eviction{DeleteOptions: deleteOptions})
+}
+
+// evictionAPIVersion returns the configured Eviction API version, or the discovered version if it's configured to be
+// detected automatically
+func (q *Queue) evictionAPIVersion(ctx context.Context) string {
+	if version := options.FromContext(ctx).EvictionAPIVersion; version != options.EvictionAPIVersionAuto {
+		return version
+	}
+	return q.discoveredAPIVersion
+}
+
+// discoverEvictionAPIVersion returns the Eviction API version that the cluster serves for the pods/eviction
+// subresource, which is policy/v1beta1 for clusters older than 1.22. This falls back to policy/v1 if discovery fails.
+func discoverEvictionAPIVersion(ctx context.Context, discoveryClient discovery.DiscoveryInterface) string {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion("v1")
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed discovering eviction API version, falling back to policy/v1")
+		return options.EvictionAPIVersionV1
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "pods/eviction" && resource.Kind == "Eviction" && resource.Group == "policy" && resource.Version == "v1beta1" {
+			return options.EvictionAPIVersionV1Beta1
+		}
+	}
+	return options.EvictionAPIVersionV1
+}
+
 func evictionReason(ctx context.Context, key QueueKey, kubeClient client.Client) (string, error) {
 	nodeClaim, err := node.NodeClaimForNode(ctx, kubeClient, &corev1.Node{Spec: corev1.NodeSpec{ProviderID: key.providerID}})
 	if err != nil {
@@ -27,8 +27,20 @@ import (
 const (
 	// CodeLabel for eviction request
 	CodeLabel = "code"
+	// ReasonLabel for eviction requeues
+	ReasonLabel = "reason"
+	// NodeLabel for the node that pods are being evicted from
+	NodeLabel = "node"
+
+	pdbViolationReason = "pdb_violation"
+	errorReason        = "error"
 )
 
+func init() {
+	EvictionQueueRequeuesTotal.Add(0, map[string]string{ReasonLabel: pdbViolationReason})
+	EvictionQueueRequeuesTotal.Add(0, map[string]string{ReasonLabel: errorReason})
+}
+
 var NodesEvictionRequestsTotal = opmetrics.NewPrometheusCounter(
 	crmetrics.Registry,
 	prometheus.CounterOpts{
@@ -39,3 +51,48 @@ var NodesEvictionRequestsTotal = opmetrics.NewPrometheusCounter(
 	},
 	[]string{CodeLabel},
 )
+
+var EvictionQueueDepth = opmetrics.NewPrometheusGauge(
+	crmetrics.Registry,
+	prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeSubsystem,
+		Name:      "eviction_queue_depth",
+		Help:      "The number of pods currently waiting to be evicted by Karpenter",
+	},
+	[]string{},
+)
+
+var EvictionQueueNodeDepth = opmetrics.NewPrometheusGauge(
+	crmetrics.Registry,
+	prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeSubsystem,
+		Name:      "eviction_queue_node_depth",
+		Help:      "The number of pods currently waiting to be evicted from a node by Karpenter. Labeled by node name.",
+	},
+	[]string{NodeLabel},
+)
+
+var EvictionQueueRequeuesTotal = opmetrics.NewPrometheusCounter(
+	crmetrics.Registry,
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeSubsystem,
+		Name:      "eviction_queue_requeues_total",
+		Help:      "The total number of eviction requests that were requeued because the eviction failed. Labeled by the reason the eviction failed.",
+	},
+	[]string{ReasonLabel},
+)
+
+var EvictionQueueDurationSeconds = opmetrics.NewPrometheusHistogram(
+	crmetrics.Registry,
+	prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeSubsystem,
+		Name:      "eviction_queue_duration_seconds",
+		Help:      "The amount of time that a pod waited in the eviction queue before it was successfully evicted",
+		Buckets:   metrics.DurationBuckets(),
+	},
+	[]string{},
+)
@@ -18,6 +18,7 @@ package terminator_test
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -34,6 +35,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -61,8 +63,9 @@ func TestAPIs(t *testing.T) {
 var _ = BeforeSuite(func() {
 	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
 	ctx = options.ToContext(ctx, test.Options())
+	fakeClock = clock.NewFakeClock(time.Now())
 	recorder = test.NewEventRecorder()
-	queue = terminator.NewTestingQueue(env.Client, recorder)
+	queue = terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock)
 	terminatorInstance = terminator.NewTerminator(fakeClock, env.Client, queue, recorder)
 })
 
@@ -71,9 +74,10 @@ var _ = AfterSuite(func() {
 })
 
 var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options())
 	recorder.Reset() // Reset the events that we captured during the run
 	// Shut down the queue and restart it to ensure no races
-	*queue = lo.FromPtr(terminator.NewTestingQueue(env.Client, recorder))
+	*queue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
 })
 
 var _ = AfterEach(func() {
@@ -95,6 +99,9 @@ var _ = Describe("Eviction/Queue", func() {
 		})
 		node = test.Node(test.NodeOptions{ProviderID: "123456789"})
 		terminator.NodesEvictionRequestsTotal.Reset()
+		terminator.EvictionQueueRequeuesTotal.Reset()
+		terminator.EvictionQueueNodeDepth.Reset()
+		terminator.EvictionQueueDurationSeconds.Reset()
 	})
 
 	Context("Eviction API", func() {
@@ -127,6 +134,7 @@ var _ = Describe("Eviction/Queue", func() {
 			ExpectApplied(ctx, env.Client, pdb, pod)
 			Expect(queue.Evict(ctx, terminator.NewQueueKey(pod, node.Spec.ProviderID))).To(BeFalse())
 			Expect(recorder.Calls("FailedDraining")).To(Equal(1))
+			ExpectMetricCounterValue(terminator.EvictionQueueRequeuesTotal, 1, map[string]string{terminator.ReasonLabel: "pdb_violation"})
 		})
 		It("should fail when two PDBs refer to the same pod", func() {
 			pdb2 := test.PodDisruptionBudget(test.PDBOptions{
@@ -137,6 +145,49 @@ var _ = Describe("Eviction/Queue", func() {
 			Expect(queue.Evict(ctx, terminator.NewQueueKey(pod, node.Spec.ProviderID))).To(BeFalse())
 			ExpectMetricCounterValue(terminator.NodesEvictionRequestsTotal, 1, map[string]string{terminator.CodeLabel: "500"})
 		})
+		It("should evict through the policy/v1beta1 Eviction API when configured", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EvictionAPIVersion: lo.ToPtr(options.EvictionAPIVersionV1Beta1)}))
+			ExpectApplied(ctx, env.Client, pdb, pod)
+			Expect(queue.Evict(ctx, terminator.NewQueueKey(pod, node.Spec.ProviderID))).To(BeFalse())
+			Expect(recorder.Calls("FailedDraining")).To(Equal(1))
+
+			ExpectDeleted(ctx, env.Client, pdb)
+			Expect(queue.Evict(ctx, terminator.NewQueueKey(pod, node.Spec.ProviderID))).To(BeTrue())
+			Expect(recorder.Calls("Evicted")).To(Equal(1))
+		})
+		It("should delete pods in namespaces that opt out of PDB enforcement", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DeleteEvictionNamespaces: lo.ToPtr("other, " + pod.Namespace)}))
+			*queue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
+			ExpectApplied(ctx, env.Client, pdb, pod)
+			Expect(queue.Evict(ctx, terminator.NewQueueKey(pod, node.Spec.ProviderID))).To(BeTrue())
+			Expect(recorder.Calls("Evicted")).To(Equal(1))
+			ExpectNotFound(ctx, env.Client, pod)
+		})
+		It("should not delete pods in namespaces that opt out of PDB enforcement when the pod UID conflicts", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DeleteEvictionNamespaces: lo.ToPtr(pod.Namespace)}))
+			*queue = lo.FromPtr(terminator.NewTestingQueue(ctx, env.Client, recorder, fakeClock))
+			ExpectApplied(ctx, env.Client, pod)
+			Expect(queue.Evict(ctx, terminator.QueueKey{NamespacedName: client.ObjectKeyFromObject(pod), UID: uuid.NewUUID()})).To(BeTrue())
+			Expect(recorder.Events()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, pod)
+		})
+		It("should track the queue depth and the time that pods spend in the queue", func() {
+			ExpectApplied(ctx, env.Client, pod)
+			queue.Add(node, pod)
+			ExpectMetricGaugeValue(terminator.EvictionQueueDepth, 1, map[string]string{})
+			ExpectMetricGaugeValue(terminator.EvictionQueueNodeDepth, 1, map[string]string{terminator.NodeLabel: node.Name})
+
+			fakeClock.Step(30 * time.Second)
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(recorder.Calls("Evicted")).To(Equal(1))
+			ExpectMetricGaugeValue(terminator.EvictionQueueDepth, 0, map[string]string{})
+			_, found := FindMetricWithLabelValues("karpenter_nodes_eviction_queue_node_depth", map[string]string{terminator.NodeLabel: node.Name})
+			Expect(found).To(BeFalse())
+			ExpectMetricHistogramSampleCountValue("karpenter_nodes_eviction_queue_duration_seconds", 1, map[string]string{})
+			metric, found := FindMetricWithLabelValues("karpenter_nodes_eviction_queue_duration_seconds", map[string]string{})
+			Expect(found).To(BeTrue())
+			Expect(metric.GetHistogram().GetSampleSum()).To(BeNumerically("==", 30))
+		})
 		It("should ensure that calling Evict() is valid while making Add() calls", func() {
 			cancelCtx, cancel := context.WithCancel(ctx)
 			wg := sync.WaitGroup{}
@@ -165,6 +216,75 @@ var _ = Describe("Eviction/Queue", func() {
 		})
 	})
 
+	Context("Workers", func() {
+		It("should evict pods with the worker pool until the queue is stopped", func() {
+			pods := lo.Times(20, func(_ int) *corev1.Pod { return test.Pod() })
+			for _, p := range pods {
+				ExpectApplied(ctx, env.Client, p)
+			}
+			cancelCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(queue.Start(cancelCtx)).To(Succeed())
+			}()
+			queue.Add(node, pods...)
+			Eventually(func() int { return recorder.Calls("Evicted") }).Should(Equal(len(pods)))
+			for _, p := range pods {
+				Expect(queue.Has(node, p)).To(BeFalse())
+			}
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+		It("should evict pods with the worker pool while the queue fails to rebuild", func() {
+			*queue = lo.FromPtr(terminator.NewTestingQueue(ctx, &failingNodeListClient{Client: env.Client}, recorder, fakeClock))
+			ExpectApplied(ctx, env.Client, pod)
+			cancelCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(queue.Start(cancelCtx)).To(Succeed())
+			}()
+			queue.Add(node, pod)
+			Eventually(func() int { return recorder.Calls("Evicted") }).Should(Equal(1))
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+	})
+	Context("Rebuild", func() {
+		It("should enqueue the pods on nodes that were draining before the queue started", func() {
+			node.Finalizers = []string{v1.TerminationFinalizer}
+			node.Spec.Taints = []corev1.Taint{v1.DisruptedNoScheduleTaint}
+			pod.Spec.NodeName = node.Name
+			ExpectApplied(ctx, env.Client, node, pod)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(recorder.Calls("Evicted")).To(Equal(1))
+		})
+		It("should only enqueue the pods in the first group that's waiting on eviction", func() {
+			node.Finalizers = []string{v1.TerminationFinalizer}
+			node.Spec.Taints = []corev1.Taint{v1.DisruptedNoScheduleTaint}
+			pod.Spec.NodeName = node.Name
+			criticalPod := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: "system-node-critical"})
+			ExpectApplied(ctx, env.Client, node, pod, criticalPod)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(queue.Has(node, criticalPod)).To(BeFalse())
+			Expect(recorder.Calls("Evicted")).To(Equal(1))
+		})
+		It("should not enqueue the pods on nodes that aren't draining", func() {
+			pod.Spec.NodeName = node.Name
+			ExpectApplied(ctx, env.Client, node, pod)
+
+			ExpectSingletonReconciled(ctx, queue)
+			Expect(queue.Has(node, pod)).To(BeFalse())
+			Expect(recorder.Calls("Evicted")).To(Equal(0))
+		})
+	})
 	Context("Pod Deletion API", func() {
 		It("should not delete a pod with no nodeTerminationTime", func() {
 			ExpectApplied(ctx, env.Client, pod)
@@ -193,3 +313,15 @@ var _ = Describe("Eviction/Queue", func() {
 		})
 	})
 })
+
+// failingNodeListClient fails to list nodes, so that the queue can't be rebuilt
+type failingNodeListClient struct {
+	client.Client
+}
+
+func (c *failingNodeListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*corev1.NodeList); ok {
+		return fmt.Errorf("failed listing nodes")
+	}
+	return c.Client.List(ctx, list, opts...)
+}
@@ -29,8 +29,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
@@ -93,7 +95,11 @@ func (t *Terminator) Taint(ctx context.Context, node *corev1.Node, taint corev1.
 
 // Drain evicts pods from the node and returns true when all pods are evicted
 // https://kubernetes.io/docs/concepts/architecture/nodes/#graceful-node-shutdown
+// Virtual nodes aren't drained since their pods are managed by the cloud provider.
 func (t *Terminator) Drain(ctx context.Context, node *corev1.Node, nodeGracePeriodExpirationTime *time.Time) error {
+	if node.Annotations[v1.VirtualNodeAnnotationKey] == "true" {
+		return nil
+	}
 	pods, err := nodeutils.GetPods(ctx, t.kubeClient, node)
 	if err != nil {
 		return fmt.Errorf("listing pods on node, %w", err)
@@ -104,8 +110,12 @@ func (t *Terminator) Drain(ctx context.Context, node *corev1.Node, nodeGracePeri
 	if err := t.DeleteExpiringPods(ctx, podsToDelete, nodeGracePeriodExpirationTime); err != nil {
 		return fmt.Errorf("deleting expiring pods, %w", err)
 	}
+	order, err := newDrainOrdering(ctx, t.kubeClient)
+	if err != nil {
+		return fmt.Errorf("resolving drain ordering, %w", err)
+	}
 	// Monitor pods in pod groups that either haven't been evicted or are actively evicting
-	podGroups := t.groupPodsByPriority(lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, t.clock) }))
+	podGroups := groupPodsByPriority(ctx, lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, t.clock) }), order)
 	for _, group := range podGroups {
 		if len(group) > 0 {
 			// Only add pods to the eviction queue that haven't been evicted yet
@@ -116,9 +126,12 @@ func (t *Terminator) Drain(ctx context.Context, node *corev1.Node, nodeGracePeri
 	return nil
 }
 
-func (t *Terminator) groupPodsByPriority(pods []*corev1.Pod) [][]*corev1.Pod {
+func groupPodsByPriority(ctx context.Context, pods []*corev1.Pod, order drainOrdering) [][]*corev1.Pod {
 	// 1. Prioritize noncritical pods, non-daemon pods https://kubernetes.io/docs/concepts/architecture/nodes/#graceful-node-shutdown
-	var nonCriticalNonDaemon, nonCriticalDaemon, criticalNonDaemon, criticalDaemon []*corev1.Pod
+	//    These are drained in the drain ordering that the operator is configured with
+	// 2. Noncritical pods in namespaces that are drained last (e.g. observability agents) follow the other noncritical pods
+	drainLastNamespaces := options.FromContext(ctx).DrainLastNamespaces.Namespaces
+	var nonCriticalNonDaemon, nonCriticalDaemon, drainLast, criticalNonDaemon, criticalDaemon []*corev1.Pod
 	for _, pod := range pods {
 		if pod.Spec.PriorityClassName == "system-cluster-critical" || pod.Spec.PriorityClassName == "system-node-critical" {
 			if podutil.IsOwnedByDaemonSet(pod) {
@@ -126,6 +139,8 @@ func (t *Terminator) groupPodsByPriority(pods []*corev1.Pod) [][]*corev1.Pod {
 			} else {
 				criticalNonDaemon = append(criticalNonDaemon, pod)
 			}
+		} else if drainLastNamespaces.Has(pod.Namespace) {
+			drainLast = append(drainLast, pod)
 		} else {
 			if podutil.IsOwnedByDaemonSet(pod) {
 				nonCriticalDaemon = append(nonCriticalDaemon, pod)
@@ -134,7 +149,7 @@ func (t *Terminator) groupPodsByPriority(pods []*corev1.Pod) [][]*corev1.Pod {
 			}
 		}
 	}
-	return [][]*corev1.Pod{nonCriticalNonDaemon, nonCriticalDaemon, criticalNonDaemon, criticalDaemon}
+	return append(order(nonCriticalNonDaemon), nonCriticalDaemon, drainLast, criticalNonDaemon, criticalDaemon)
 }
 
 func (t *Terminator) DeleteExpiringPods(ctx context.Context, pods []*corev1.Pod, nodeGracePeriodTerminationTime *time.Time) error {
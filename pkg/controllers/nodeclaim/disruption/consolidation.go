@@ -18,6 +18,9 @@ package disruption
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	"k8s.io/utils/clock"
@@ -26,12 +29,29 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/pdb"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
+// blockedRecheckPeriod is how often we re-check a nodeclaim that's blocked from consolidation since PDB and
+// nomination changes don't trigger a reconcile of the nodeclaim
+const blockedRecheckPeriod = time.Minute
+
+// pdbLimitsTTL is how long the PDB limits are cached for so that every nodeclaim reconcile doesn't list the PDBs
+const pdbLimitsTTL = 15 * time.Second
+
 // Consolidation is a nodeclaim sub-controller that adds or removes status conditions on empty nodeclaims based on consolidateAfter
 type Consolidation struct {
 	kubeClient client.Client
 	clock      clock.Clock
+	cluster    *state.Cluster
+
+	mu               sync.Mutex
+	pdbLimits        pdb.Limits
+	pdbLimitsUpdated time.Time
 }
 
 //nolint:gocyclo
@@ -69,6 +89,19 @@ func (c *Consolidation) Reconcile(ctx context.Context, nodePool *v1.NodePool, no
 		return reconcile.Result{RequeueAfter: consolidatableTime.Sub(c.clock.Now())}, nil
 	}
 
+	// 4. If something on the node blocks consolidation, mark the nodeclaim as not consolidatable with the reason
+	reason, message, err := c.blockingReason(ctx, nodeClaim)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if reason != "" {
+		if !nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsFalse() {
+			log.FromContext(ctx).V(1).Info("marking not consolidatable", "reason", reason)
+		}
+		nodeClaim.StatusConditions().SetFalse(v1.ConditionTypeConsolidatable, reason, message)
+		return reconcile.Result{RequeueAfter: blockedRecheckPeriod}, nil
+	}
+
 	// 6. Otherwise, add the consolidatable status condition
 	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
 	if !hasConsolidatableCondition {
@@ -76,3 +109,53 @@ func (c *Consolidation) Reconcile(ctx context.Context, nodePool *v1.NodePool, no
 	}
 	return reconcile.Result{}, nil
 }
+
+// blockingReason returns the reason and message describing why the nodeclaim can't be consolidated, or an empty reason
+// if nothing on the node blocks consolidation
+func (c *Consolidation) blockingReason(ctx context.Context, nodeClaim *v1.NodeClaim) (string, string, error) {
+	if nodeclaimutils.IsManuallyOwned(nodeClaim) {
+		return v1.ConsolidatableReasonManuallyOwned, fmt.Sprintf("NodeClaim has %q annotation", v1.OwnerAnnotationKey+"="+v1.OwnerManual), nil
+	}
+	if c.cluster.IsNodeNominated(nodeClaim.Status.ProviderID) {
+		return v1.ConsolidatableReasonNominated, "Node was recently nominated for a pending pod", nil
+	}
+	node, err := nodeclaimutils.NodeForNodeClaim(ctx, c.kubeClient, nodeClaim)
+	if err != nil {
+		if nodeclaimutils.IsNodeNotFoundError(err) || nodeclaimutils.IsDuplicateNodeError(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	pods, err := nodeutils.GetPods(ctx, c.kubeClient, node)
+	if err != nil {
+		return "", "", err
+	}
+	for _, po := range pods {
+		if !podutils.IsDisruptable(po) {
+			return v1.ConsolidatableReasonDoNotDisruptPod, fmt.Sprintf("Pod %q has %q annotation", client.ObjectKeyFromObject(po), v1.DoNotDisruptAnnotationKey), nil
+		}
+	}
+	limits, err := c.getPDBLimits(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("tracking PodDisruptionBudgets, %w", err)
+	}
+	if pdbKey, ok := limits.CanEvictPods(pods); !ok {
+		return v1.ConsolidatableReasonPodDisruptionBudget, fmt.Sprintf("PDB %q prevents pod evictions", pdbKey), nil
+	}
+	return "", "", nil
+}
+
+// getPDBLimits returns the PDB limits, listing the PDBs only if the cached limits have expired
+func (c *Consolidation) getPDBLimits(ctx context.Context) (pdb.Limits, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pdbLimits != nil && c.clock.Since(c.pdbLimitsUpdated) < pdbLimitsTTL {
+		return c.pdbLimits, nil
+	}
+	limits, err := pdb.NewLimits(ctx, c.clock, c.kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	c.pdbLimits, c.pdbLimitsUpdated = limits, c.clock.Now()
+	return limits, nil
+}
@@ -21,8 +21,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -149,4 +151,91 @@ var _ = Describe("Underutilized", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable)).To(BeNil())
 	})
+	Context("Blocked", func() {
+		var node *corev1.Node
+		BeforeEach(func() {
+			nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: it.Name,
+					},
+				},
+			})
+			nodeClaim.Status.LastPodEventTime.Time = fakeClock.Now().Add(-5 * time.Minute)
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+			ExpectApplied(ctx, env.Client, nodeClaim, node)
+		})
+		It("should mark NodeClaims as not consolidatable when the node was recently nominated", func() {
+			cluster.UpdateNodeClaim(nodeClaim)
+			cluster.NominateNodeForPod(ctx, nodeClaim.Status.ProviderID)
+
+			result := ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsFalse()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).Reason).To(Equal(v1.ConsolidatableReasonNominated))
+		})
+		It("should mark NodeClaims as not consolidatable when they're manually owned", func() {
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.OwnerAnnotationKey: v1.OwnerManual})
+			ExpectApplied(ctx, env.Client, nodeClaim)
+
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsFalse()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).Reason).To(Equal(v1.ConsolidatableReasonManuallyOwned))
+		})
+		It("should mark NodeClaims as not consolidatable when a pod has the do-not-disrupt annotation", func() {
+			pod := test.Pod(test.PodOptions{
+				NodeName:   node.Name,
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1.DoNotDisruptAnnotationKey: "true"}},
+				Phase:      corev1.PodRunning,
+			})
+			ExpectApplied(ctx, env.Client, pod)
+
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			condition := nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable)
+			Expect(condition.IsFalse()).To(BeTrue())
+			Expect(condition.Reason).To(Equal(v1.ConsolidatableReasonDoNotDisruptPod))
+			Expect(condition.Message).To(ContainSubstring(pod.Name))
+		})
+		It("should mark NodeClaims as not consolidatable when a PDB blocks evicting a pod", func() {
+			podLabels := map[string]string{"app": "test"}
+			pod := test.Pod(test.PodOptions{
+				NodeName:   node.Name,
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Phase:      corev1.PodRunning,
+			})
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: lo.ToPtr(intstr.FromInt32(0)),
+			})
+			ExpectApplied(ctx, env.Client, pod, pdb)
+			// expire the PDB limits that were cached by earlier reconciles
+			fakeClock.Step(time.Minute)
+
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			condition := nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable)
+			Expect(condition.IsFalse()).To(BeTrue())
+			Expect(condition.Reason).To(Equal(v1.ConsolidatableReasonPodDisruptionBudget))
+		})
+		It("should mark NodeClaims as consolidatable once the blocking pod is removed", func() {
+			pod := test.Pod(test.PodOptions{
+				NodeName:   node.Name,
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1.DoNotDisruptAnnotationKey: "true"}},
+				Phase:      corev1.PodRunning,
+			})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsFalse()).To(BeTrue())
+
+			ExpectDeleted(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsTrue()).To(BeTrue())
+		})
+	})
 })
@@ -26,6 +26,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
@@ -36,10 +38,12 @@ const (
 	NodePoolDrifted      cloudprovider.DriftReason = "NodePoolDrifted"
 	RequirementsDrifted  cloudprovider.DriftReason = "RequirementsDrifted"
 	InstanceTypeNotFound cloudprovider.DriftReason = "InstanceTypeNotFound"
+	RestartRequested     cloudprovider.DriftReason = "RestartRequested"
 )
 
 // Drift is a nodeclaim sub-controller that adds or removes status conditions on drifted nodeclaims
 type Drift struct {
+	clock         clock.Clock
 	cloudProvider cloudprovider.CloudProvider
 }
 
@@ -79,7 +83,7 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1.NodePool, nodeClaim
 // isDrifted will check if a NodeClaim is drifted from the fields in the NodePool Spec and the CloudProvider
 func (d *Drift) isDrifted(ctx context.Context, nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) (cloudprovider.DriftReason, error) {
 	// First check for static drift or node requirements have drifted to save on API calls.
-	if reason := lo.FindOrElse([]cloudprovider.DriftReason{areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, "", func(i cloudprovider.DriftReason) bool {
+	if reason := lo.FindOrElse([]cloudprovider.DriftReason{d.isRestartRequested(ctx, nodePool, nodeClaim), areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, "", func(i cloudprovider.DriftReason) bool {
 		return i != ""
 	}); reason != "" {
 		return reason, nil
@@ -123,6 +127,26 @@ func instanceTypeNotFound(its []*cloudprovider.InstanceType, nodeClaim *v1.NodeC
 	return ""
 }
 
+// isRestartRequested returns whether the NodePool requested that its nodes be restarted after the NodeClaim was created
+// through the karpenter.sh/restart-nodes annotation. Replacements are created after the requested time, so they aren't
+// restarted again.
+func (d *Drift) isRestartRequested(ctx context.Context, nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) cloudprovider.DriftReason {
+	value, ok := nodePool.Annotations[v1.RestartNodesAnnotationKey]
+	if !ok {
+		return ""
+	}
+	restartTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.FromContext(ctx).WithValues("NodePool", klog.KRef("", nodePool.Name)).Error(err, "failed parsing restart-nodes annotation")
+		return ""
+	}
+	// A restart that's requested in the future would otherwise drift every NodeClaim that's launched until then
+	if restartTime.After(d.clock.Now()) {
+		return ""
+	}
+	return lo.Ternary(nodeClaim.CreationTimestamp.Time.Before(restartTime), RestartRequested, "")
+}
+
 // Eligible fields for drift are described in the docs
 // https://karpenter.sh/docs/concepts/deprovisioning/#drift
 func areStaticFieldsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) cloudprovider.DriftReason {
@@ -138,7 +162,18 @@ func areStaticFieldsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) clou
 	if nodePoolHashVersion != nodeClaimHashVersion {
 		return ""
 	}
-	return lo.Ternary(nodePoolHash != nodeClaimHash, NodePoolDrifted, "")
+	if nodePoolHash == nodeClaimHash {
+		return ""
+	}
+	// Changes to the template fields that are propagated in place don't drift the NodeClaim
+	if len(nodePool.Spec.TemplatePropagation.InPlace()) > 0 {
+		nodePoolHashes, foundNodePoolHashes := nodePool.Annotations[v1.NodePoolTemplateHashesAnnotationKey]
+		nodeClaimHashes, foundNodeClaimHashes := nodeClaim.Annotations[v1.NodePoolTemplateHashesAnnotationKey]
+		if foundNodePoolHashes && foundNodeClaimHashes && !nodePool.TemplateHashesDrifted(nodePoolHashes, nodeClaimHashes) {
+			return ""
+		}
+	}
+	return NodePoolDrifted
 }
 
 func areRequirementsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) cloudprovider.DriftReason {
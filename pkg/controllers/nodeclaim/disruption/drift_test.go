@@ -478,6 +478,83 @@ var _ = Describe("Drift", func() {
 			Entry("ExpireAfter", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{ExpireAfter: v1.MustParseNillableDuration("100m")}}}}),
 			Entry("TerminationGracePeriod", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{TerminationGracePeriod: &metav1.Duration{Duration: 100 * time.Minute}}}}}),
 		)
+		DescribeTable("should not detect drift on changes to the fields that are propagated in place",
+			func(propagation v1.TemplatePropagation, changes v1.NodePool) {
+				nodePool.Spec.TemplatePropagation = propagation
+				nodeClaim.ObjectMeta.Annotations[v1.NodePoolHashAnnotationKey] = nodePool.Hash()
+				nodeClaim.ObjectMeta.Annotations[v1.NodePoolTemplateHashesAnnotationKey] = nodePool.TemplateHashes()
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+				ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+
+				nodePool = ExpectExists(ctx, env.Client, nodePool)
+				Expect(mergo.Merge(nodePool, changes, mergo.WithOverride)).To(Succeed())
+				ExpectApplied(ctx, env.Client, nodePool)
+
+				ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+				ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+			},
+			Entry("Annotations", v1.TemplatePropagation{Annotations: v1.PropagationPolicyInPlace}, v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{"keyAnnotationTest": "valueAnnotationTest"}}}}}),
+			Entry("Labels", v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace}, v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"keyLabelTest": "valueLabelTest"}}}}}),
+			Entry("Taints", v1.TemplatePropagation{Taints: v1.PropagationPolicyInPlace}, v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{Taints: []corev1.Taint{{Key: "keytest2taint", Effect: corev1.TaintEffectNoExecute}}}}}}),
+		)
+		It("should detect drift on changes to the fields that are replaced when other fields are propagated in place", func() {
+			nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace, Annotations: v1.PropagationPolicyInPlace}
+			nodeClaim.ObjectMeta.Annotations[v1.NodePoolHashAnnotationKey] = nodePool.Hash()
+			nodeClaim.ObjectMeta.Annotations[v1.NodePoolTemplateHashesAnnotationKey] = nodePool.TemplateHashes()
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "keytest2taint", Effect: corev1.TaintEffectNoExecute}}
+			ExpectApplied(ctx, env.Client, nodePool)
+
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
+		It("should detect drift when the template propagation and other fields change together", func() {
+			nodeClaim.ObjectMeta.Annotations[v1.NodePoolHashAnnotationKey] = nodePool.Hash()
+			nodeClaim.ObjectMeta.Annotations[v1.NodePoolTemplateHashesAnnotationKey] = nodePool.TemplateHashes()
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace}
+			nodePool.Spec.Template.Labels = map[string]string{"keyLabelTest": "valueLabelTest"}
+			nodePool.Spec.Template.Spec.ExpireAfter = v1.MustParseNillableDuration("100m")
+			ExpectApplied(ctx, env.Client, nodePool)
+
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
+		It("should detect drift when fields that were changed in place are no longer propagated in place", func() {
+			nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace}
+			nodeClaim.ObjectMeta.Annotations[v1.NodePoolHashAnnotationKey] = nodePool.Hash()
+			nodeClaim.ObjectMeta.Annotations[v1.NodePoolTemplateHashesAnnotationKey] = nodePool.TemplateHashes()
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			nodePool.Spec.Template.Labels = map[string]string{"keyLabelTest": "valueLabelTest"}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
 		It("should not return drifted if karpenter.sh/nodepool-hash annotation is not present on the NodePool", func() {
 			nodePool.ObjectMeta.Annotations = map[string]string{}
 			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
@@ -518,4 +595,48 @@ var _ = Describe("Drift", func() {
 			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
 		})
 	})
+	Context("Restart Requested", func() {
+		BeforeEach(func() {
+			cp.Drifted = ""
+		})
+		It("should detect drift if the NodeClaim was created before the restart was requested", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim)
+			fakeClock.Step(time.Minute)
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1.RestartNodesAnnotationKey: fakeClock.Now().Format(time.RFC3339),
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.RestartRequested)))
+		})
+		It("should not detect drift if the NodeClaim was created after the restart was requested", func() {
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1.RestartNodesAnnotationKey: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+		It("should not detect drift if the restart is requested in the future", func() {
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1.RestartNodesAnnotationKey: fakeClock.Now().Add(time.Hour).Format(time.RFC3339),
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+		It("should not detect drift if the restart annotation isn't a valid timestamp", func() {
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1.RestartNodesAnnotationKey: "now",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+	})
 })
@@ -34,6 +34,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	nodeclaimdisruption "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/disruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -47,6 +48,7 @@ var nodeClaimDisruptionController *nodeclaimdisruption.Controller
 var env *test.Environment
 var fakeClock *clock.FakeClock
 var cp *fake.CloudProvider
+var cluster *state.Cluster
 var it *cloudprovider.InstanceType
 
 func TestAPIs(t *testing.T) {
@@ -60,7 +62,8 @@ var _ = BeforeSuite(func() {
 	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx)))
 	ctx = options.ToContext(ctx, test.Options())
 	cp = fake.NewCloudProvider()
-	nodeClaimDisruptionController = nodeclaimdisruption.NewController(fakeClock, env.Client, cp)
+	cluster = state.NewCluster(fakeClock, env.Client, cp)
+	nodeClaimDisruptionController = nodeclaimdisruption.NewController(fakeClock, env.Client, cp, cluster)
 })
 
 var _ = AfterSuite(func() {
@@ -103,6 +106,7 @@ var _ = BeforeEach(func() {
 
 var _ = AfterEach(func() {
 	cp.Reset()
+	cluster.Reset()
 	ExpectCleanedUp(ctx, env.Client)
 })
 
@@ -0,0 +1,95 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// utilizationChangeThreshold is the smallest change in the utilization of a resource that's recorded. Smaller changes
+// aren't worth the cost of patching the nodeclaim every time a pod comes or goes.
+const utilizationChangeThreshold = 0.01
+
+// Utilization is a nodeclaim sub-controller that records the ratio of requested to allocatable resources on the nodeclaim
+type Utilization struct {
+	kubeClient client.Client
+}
+
+func (u *Utilization) Reconcile(ctx context.Context, _ *v1.NodePool, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
+	node, err := nodeclaimutils.NodeForNodeClaim(ctx, u.kubeClient, nodeClaim)
+	if err != nil {
+		if nodeclaimutils.IsNodeNotFoundError(err) || nodeclaimutils.IsDuplicateNodeError(err) {
+			nodeClaim.Status.Utilization = nil
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	pods, err := nodeutils.GetPods(ctx, u.kubeClient, node)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	requests := resources.RequestsForPods(lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return !podutils.IsTerminal(p) })...)
+	// Prefer the node's allocatable since it reflects what the kubelet actually reports
+	allocatable := lo.Ternary(len(node.Status.Allocatable) > 0, node.Status.Allocatable, nodeClaim.Status.Allocatable)
+	utilization := map[corev1.ResourceName]float64{}
+	for name, quantity := range allocatable {
+		if resources.IsZero(quantity) {
+			continue
+		}
+		requested := requests[name]
+		utilization[name] = requested.AsApproximateFloat64() / quantity.AsApproximateFloat64()
+	}
+	if !utilizationChanged(nodeClaim.Status.Utilization, utilization) {
+		return reconcile.Result{}, nil
+	}
+	nodeClaim.Status.Utilization = lo.Ternary(len(utilization) > 0, lo.MapValues(utilization, func(u float64, _ corev1.ResourceName) string {
+		return strconv.FormatFloat(u, 'f', 2, 64)
+	}), nil)
+	return reconcile.Result{}, nil
+}
+
+// utilizationChanged returns true if the set of resources changed or the utilization of any resource changed by at
+// least the utilizationChangeThreshold
+func utilizationChanged(recorded map[corev1.ResourceName]string, utilization map[corev1.ResourceName]float64) bool {
+	if len(recorded) != len(utilization) {
+		return true
+	}
+	for name, u := range utilization {
+		r, ok := recorded[name]
+		if !ok {
+			return true
+		}
+		previous, err := strconv.ParseFloat(r, 64)
+		if err != nil || math.Abs(u-previous) >= utilizationChangeThreshold {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("Utilization", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: it.Name,
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+					corev1.ResourcePods:   resource.MustParse("10"),
+				},
+			},
+		})
+		nodeClaim.Status.LastPodEventTime.Time = fakeClock.Now().Add(-5 * time.Minute)
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+	})
+	It("should record the ratio of requested to allocatable resources", func() {
+		pod := test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+			Phase: corev1.PodRunning,
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Utilization).To(Equal(map[corev1.ResourceName]string{
+			corev1.ResourceCPU:    "0.25",
+			corev1.ResourceMemory: "0.25",
+			corev1.ResourcePods:   "0.10",
+		}))
+	})
+	It("should only update the utilization when it changes by at least 1%", func() {
+		nodeClaim.Status.Allocatable[corev1.ResourcePods] = resource.MustParse("1000")
+		node.Status.Allocatable[corev1.ResourcePods] = resource.MustParse("1000")
+		pod := test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+			Phase: corev1.PodRunning,
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Utilization).To(HaveKeyWithValue(corev1.ResourceCPU, "0.25"))
+
+		// 10m of 4 CPUs is a change of 0.25%
+		ExpectApplied(ctx, env.Client, test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+			},
+			Phase: corev1.PodRunning,
+		}))
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Utilization).To(HaveKeyWithValue(corev1.ResourceCPU, "0.25"))
+
+		// another 100m of 4 CPUs brings the change to 2.75%
+		ExpectApplied(ctx, env.Client, test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+			Phase: corev1.PodRunning,
+		}))
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Utilization).To(HaveKeyWithValue(corev1.ResourceCPU, "0.28"))
+	})
+	It("should ignore terminal pods", func() {
+		pod := test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+			Phase: corev1.PodSucceeded,
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Utilization).To(HaveKeyWithValue(corev1.ResourceCPU, "0.00"))
+	})
+	It("should not record utilization when the nodeclaim doesn't have a node", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Utilization).To(BeNil())
+	})
+})
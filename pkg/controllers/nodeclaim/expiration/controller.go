@@ -31,23 +31,29 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 )
 
+// changeFreezeRecheckPeriod is how often expired NodeClaims are rechecked while a change freeze is enabled
+const changeFreezeRecheckPeriod = time.Minute
+
 // Expiration is a nodeclaim controller that deletes expired nodeclaims based on expireAfter
 type Controller struct {
 	clock         clock.Clock
 	kubeClient    client.Client
 	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
 }
 
 // NewController constructs a nodeclaim disruption controller
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Controller {
 	return &Controller{
 		clock:         clk,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
+		cluster:       cluster,
 	}
 }
 
@@ -58,7 +64,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 	if !nodeClaim.DeletionTimestamp.IsZero() {
 		return reconcile.Result{}, nil
 	}
-	// From here there are three scenarios to handle:
+	// From here there are four scenarios to handle:
 	// 1. If ExpireAfter is not configured, exit expiration loop
 	if nodeClaim.Spec.ExpireAfter.Duration == nil {
 		return reconcile.Result{}, nil
@@ -69,11 +75,15 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 		// Use t.Sub(clock.Now()) instead of time.Until() to ensure we're using the injected clock.
 		return reconcile.Result{RequeueAfter: expirationTime.Sub(c.clock.Now())}, nil
 	}
-	// 3. Otherwise, if the NodeClaim is expired we can forcefully expire the nodeclaim (by deleting it)
-	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+	// 3. If voluntary disruption is paused by a change freeze, wait for the freeze to be lifted
+	if c.cluster.ChangeFrozen() {
+		return reconcile.Result{RequeueAfter: changeFreezeRecheckPeriod}, nil
+	}
+	// 4. Otherwise, if the NodeClaim is expired we can forcefully expire the nodeclaim (by deleting it)
+	if err := nodeclaimutils.Delete(ctx, c.kubeClient, nodeClaim, v1.TerminationReasonExpiration); err != nil {
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
-	// 4. The deletion timestamp has successfully been set for the NodeClaim, update relevant metrics.
+	// 5. The deletion timestamp has successfully been set for the NodeClaim, update relevant metrics.
 	log.FromContext(ctx).V(1).Info("deleting expired nodeclaim")
 	metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 		metrics.ReasonLabel:       strings.ToLower(metrics.ExpiredReason),
@@ -31,6 +31,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/expiration"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -44,6 +45,7 @@ var expirationController *expiration.Controller
 var env *test.Environment
 var cp *fake.CloudProvider
 var fakeClock *clock.FakeClock
+var cluster *state.Cluster
 
 func TestAPIs(t *testing.T) {
 	ctx = TestContextWithLogger(t)
@@ -56,7 +58,8 @@ var _ = BeforeSuite(func() {
 	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx)))
 	ctx = options.ToContext(ctx, test.Options())
 	cp = fake.NewCloudProvider()
-	expirationController = expiration.NewController(fakeClock, env.Client, cp)
+	cluster = state.NewCluster(fakeClock, env.Client, cp)
+	expirationController = expiration.NewController(fakeClock, env.Client, cp, cluster)
 })
 
 var _ = AfterSuite(func() {
@@ -66,6 +69,7 @@ var _ = AfterSuite(func() {
 var _ = BeforeEach(func() {
 	ctx = options.ToContext(ctx, test.Options())
 	fakeClock.SetTime(time.Now())
+	cluster.Reset()
 })
 
 var _ = AfterEach(func() {
@@ -169,6 +173,20 @@ var _ = Describe("Expiration", func() {
 
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should not remove expired NodeClaims while a change freeze is enabled", func() {
+		nodeClaim.Spec.ExpireAfter = v1.MustParseNillableDuration("30s")
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		cluster.SetChangeFrozen(true)
+
+		fakeClock.Step(60 * time.Second)
+		result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		cluster.SetChangeFrozen(false)
+		ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
 	It("should return the requeue interval for the time between now and when the nodeClaim expires", func() {
 		nodeClaim.Spec.ExpireAfter = v1.MustParseNillableDuration("200s")
 		ExpectApplied(ctx, env.Client, nodeClaim, node)
@@ -178,6 +196,16 @@ var _ = Describe("Expiration", func() {
 		result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
 		Expect(result.RequeueAfter).To(BeNumerically("~", time.Second*100, time.Second))
 	})
+	It("should record expiration as the termination reason", func() {
+		nodeClaim.ObjectMeta.Finalizers = append(nodeClaim.ObjectMeta.Finalizers, "test-finalizer")
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		// step forward to make the node expired
+		fakeClock.Step(60 * time.Second)
+		ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.TerminationReason).To(Equal(v1.TerminationReasonExpiration))
+	})
 	It("shouldn't expire the same NodeClaim multiple times", func() {
 		nodeClaim.ObjectMeta.Finalizers = append(nodeClaim.ObjectMeta.Finalizers, "test-finalizer")
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
@@ -95,7 +95,7 @@ func (c *Controller) Reconcile(ctx context.Context) (reconcile.Result, error) {
 		if node != nil && nodeutils.GetCondition(node, corev1.NodeReady).Status == corev1.ConditionTrue {
 			return
 		}
-		if err := c.kubeClient.Delete(ctx, nodeClaims[i]); err != nil {
+		if err := nodeclaimutils.Delete(ctx, c.kubeClient, nodeClaims[i], v1.TerminationReasonInterruption); err != nil {
 			errs[i] = client.IgnoreNotFound(err)
 			return
 		}
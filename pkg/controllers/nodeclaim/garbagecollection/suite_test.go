@@ -33,14 +33,19 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	nodeclaimgarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/garbagecollection"
 	nodeclaimlifcycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -63,7 +68,12 @@ var _ = BeforeSuite(func() {
 	ctx = options.ToContext(ctx, test.Options())
 	cloudProvider = fake.NewCloudProvider()
 	garbageCollectionController = nodeclaimgarbagecollection.NewController(fakeClock, env.Client, cloudProvider)
-	nodeClaimController = nodeclaimlifcycle.NewController(fakeClock, env.Client, cloudProvider, events.NewRecorder(&record.FakeRecorder{}))
+	recorder := events.NewRecorder(&record.FakeRecorder{})
+	cluster := state.NewCluster(fakeClock, env.Client, cloudProvider)
+	unhealthyOfferings := cloudprovider.NewUnhealthyOfferings()
+	unavailableOfferings := cloudprovider.NewUnavailableOfferings(env.Client, fakeClock)
+	prov := provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock, unhealthyOfferings, unavailableOfferings, scheduling.NewLabelAliases())
+	nodeClaimController = nodeclaimlifcycle.NewController(fakeClock, env.Client, cloudProvider, recorder, cluster, prov, unhealthyOfferings, unavailableOfferings, providerid.NewMapping())
 })
 
 var _ = AfterSuite(func() {
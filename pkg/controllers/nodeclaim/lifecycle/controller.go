@@ -40,14 +40,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
-
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
 	"sigs.k8s.io/karpenter/pkg/utils/result"
 	terminationutil "sigs.k8s.io/karpenter/pkg/utils/termination"
 )
@@ -71,20 +74,20 @@ type Controller struct {
 	liveness       *Liveness
 }
 
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, cluster *state.Cluster, provisioner *provisioning.Provisioner, unhealthyOfferings *cloudprovider.UnhealthyOfferings, unavailableOfferings *cloudprovider.UnavailableOfferings, providerIDs *providerid.Mapping, initializationChecks ...InitializationCheck) *Controller {
 	return &Controller{
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
 		recorder:      recorder,
 
-		launch:         &Launch{kubeClient: kubeClient, cloudProvider: cloudProvider, cache: cache.New(time.Minute, time.Second*10), recorder: recorder},
-		registration:   &Registration{kubeClient: kubeClient},
-		initialization: &Initialization{kubeClient: kubeClient},
-		liveness:       &Liveness{clock: clk, kubeClient: kubeClient},
+		launch:         &Launch{kubeClient: kubeClient, cloudProvider: cloudProvider, cache: cache.New(time.Minute, time.Second*10), recorder: recorder, cluster: cluster, unavailableOfferings: unavailableOfferings},
+		registration:   &Registration{kubeClient: kubeClient, providerIDs: providerIDs},
+		initialization: &Initialization{kubeClient: kubeClient, checks: initializationChecks},
+		liveness:       &Liveness{clock: clk, kubeClient: kubeClient, provisioner: provisioner, unhealthyOfferings: unhealthyOfferings},
 	}
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named(c.Name()).
 		For(&v1.NodeClaim{}, builder.WithPredicates(nodeclaimutils.IsManagedPredicateFuncs(c.cloudProvider))).
@@ -96,10 +99,10 @@ func (c *Controller) Register(_ context.Context, m manager.Manager) error {
 			RateLimiter: workqueue.NewTypedMaxOfRateLimiter[reconcile.Request](
 				// back off until last attempt occurs ~90 seconds before nodeclaim expiration
 				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, time.Minute),
-				// 10 qps, 100 bucket size
-				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+				// 10 qps, 100 bucket size by default
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(options.FromContext(ctx).ControllerQPS), options.FromContext(ctx).ControllerBurst)},
 			),
-			MaxConcurrentReconciles: 1000, // higher concurrency limit since we want fast reaction to node syncing and launch
+			MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For(c.Name(), 1000), // higher concurrency limit since we want fast reaction to node syncing and launch
 		}).
 		Complete(reconcile.AsReconciler(m.GetClient(), c))
 }
@@ -156,6 +159,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 		if err := c.kubeClient.Status().Patch(ctx, statusCopy, client.MergeFrom(stored)); err != nil {
 			return reconcile.Result{}, client.IgnoreNotFound(multierr.Append(errs, err))
 		}
+		c.launch.updateClusterState(stored, statusCopy)
 		// We sleep here after a patch operation since we want to ensure that we are able to read our own writes
 		// so that we avoid duplicating metrics and log lines due to quick re-queues from our node watcher
 		// USE CAUTION when determining whether to increase this timeout or remove this line
@@ -179,6 +183,9 @@ func (c *Controller) finalize(ctx context.Context, nodeClaim *v1.NodeClaim) (rec
 		}
 		return reconcile.Result{}, fmt.Errorf("adding nodeclaim terminationGracePeriod annotation, %w", err)
 	}
+	if err := c.ensureTerminationReason(ctx, nodeClaim); err != nil {
+		return reconcile.Result{}, fmt.Errorf("recording nodeclaim termination reason, %w", err)
+	}
 
 	// Only delete Nodes if the NodeClaim has not been registered. Deleting Node's without the termination finalizer
 	// may result in leaked leases due to a kubelet bug until k8s 1.29. The Node should be garbage collected after the
@@ -238,11 +245,13 @@ func (c *Controller) finalize(ctx context.Context, nodeClaim *v1.NodeClaim) (rec
 			}
 			return reconcile.Result{}, client.IgnoreNotFound(fmt.Errorf("removing termination finalizer, %w", err))
 		}
-		log.FromContext(ctx).Info("deleted nodeclaim")
+		log.FromContext(ctx).WithValues("reason", nodeclaimutils.TerminationReason(stored)).Info("deleted nodeclaim")
+		c.recorder.Publish(TerminatedEvent(stored, nodeclaimutils.TerminationReason(stored)))
 		NodeClaimTerminationDurationSeconds.Observe(time.Since(stored.DeletionTimestamp.Time).Seconds(), map[string]string{
 			metrics.NodePoolLabel: nodeClaim.Labels[v1.NodePoolLabelKey],
 		})
 		metrics.NodeClaimsTerminatedTotal.Inc(map[string]string{
+			metrics.ReasonLabel:       string(nodeclaimutils.TerminationReason(stored)),
 			metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
 			metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
 		})
@@ -268,6 +277,16 @@ func (c *Controller) ensureTerminationGracePeriodTerminationTimeAnnotation(ctx c
 	return nil
 }
 
+// ensureTerminationReason records that the NodeClaim was deleted manually if it was deleted without a reason being recorded
+func (c *Controller) ensureTerminationReason(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	if nodeClaim.Status.TerminationReason != "" {
+		return nil
+	}
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.Status.TerminationReason = v1.TerminationReasonManual
+	return client.IgnoreNotFound(c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)))
+}
+
 func (c *Controller) annotateTerminationGracePeriodTerminationTime(ctx context.Context, nodeClaim *v1.NodeClaim, terminationTime string) error {
 	stored := nodeClaim.DeepCopy()
 	nodeClaim.ObjectMeta.Annotations = lo.Assign(nodeClaim.ObjectMeta.Annotations, map[string]string{v1.NodeClaimTerminationTimestampAnnotationKey: terminationTime})
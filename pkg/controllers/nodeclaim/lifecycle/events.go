@@ -29,7 +29,7 @@ func InsufficientCapacityErrorEvent(nodeClaim *v1.NodeClaim, err error) events.E
 	return events.Event{
 		InvolvedObject: nodeClaim,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "InsufficientCapacityError",
+		Reason:         events.InsufficientCapacityError,
 		Message:        fmt.Sprintf("NodeClaim %s event: %s", nodeClaim.Name, truncateMessage(err.Error())),
 		DedupeValues:   []string{string(nodeClaim.UID)},
 	}
@@ -39,8 +39,28 @@ func NodeClassNotReadyEvent(nodeClaim *v1.NodeClaim, err error) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "NodeClassNotReady",
+		Reason:         events.NodeClassNotReady,
 		Message:        fmt.Sprintf("NodeClaim %s event: %s", nodeClaim.Name, truncateMessage(err.Error())),
 		DedupeValues:   []string{string(nodeClaim.UID)},
 	}
 }
+
+func TerminatedEvent(nodeClaim *v1.NodeClaim, reason v1.TerminationReason) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Terminated,
+		Message:        fmt.Sprintf("Terminated NodeClaim due to %s", reason),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+func InstanceTypeMismatchEvent(nodeClaim *v1.NodeClaim, predicted, launched string) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.InstanceTypeMismatch,
+		Message:        fmt.Sprintf("Launched instance type %s instead of the predicted instance type %s", launched, predicted),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
@@ -35,14 +35,27 @@ import (
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
+// InitializationCheck is checked in addition to the built-in checks before a NodeClaim is initialized. Checks let cloud
+// providers whose nodes don't follow the kubelet's lifecycle, e.g. virtual nodes, define when their nodes are ready
+// for pods.
+type InitializationCheck interface {
+	// Name identifies the check in the Initialized status condition
+	Name() string
+	// Initialized returns false and a message describing what the node is waiting on if it isn't initialized
+	Initialized(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node) (bool, string)
+}
+
 type Initialization struct {
 	kubeClient client.Client
+	checks     []InitializationCheck
 }
 
 // Reconcile checks for initialization based on if:
 // a) its current status is set to Ready
 // b) all the startup taints have been removed from the node
 // c) all extended resources have been registered
+// d) all of the initialization checks that the controller was constructed with pass
+// Virtual nodes don't need to be Ready since they don't heartbeat.
 // This method handles both nil nodepools and nodes without extended resources gracefully.
 func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
 	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized); !cond.IsUnknown() {
@@ -60,7 +73,7 @@ func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim)
 		return reconcile.Result{}, nil //nolint:nilerr
 	}
 	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Node", klog.KRef("", node.Name)))
-	if nodeutils.GetCondition(node, corev1.NodeReady).Status != corev1.ConditionTrue {
+	if nodeClaim.Annotations[v1.VirtualNodeAnnotationKey] != "true" && nodeutils.GetCondition(node, corev1.NodeReady).Status != corev1.ConditionTrue {
 		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeInitialized, "NodeNotReady", "Node status is NotReady")
 		return reconcile.Result{}, nil
 	}
@@ -76,6 +89,12 @@ func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim)
 		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeInitialized, "ResourceNotRegistered", fmt.Sprintf("Resource %q was requested but not registered", name))
 		return reconcile.Result{}, nil
 	}
+	for _, check := range i.checks {
+		if ok, msg := check.Initialized(ctx, nodeClaim, node); !ok {
+			nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeInitialized, "InitializationCheckPending", fmt.Sprintf("Initialization check %q is pending, %s", check.Name(), msg))
+			return reconcile.Result{}, nil
+		}
+	}
 	stored := node.DeepCopy()
 	node.Labels = lo.Assign(node.Labels, map[string]string{v1.NodeInitializedLabelKey: "true"})
 	if !equality.Semantic.DeepEqual(stored, node) {
@@ -20,19 +20,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/patrickmn/go-cache"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 type Launch struct {
@@ -40,6 +46,9 @@ type Launch struct {
 	cloudProvider cloudprovider.CloudProvider
 	cache         *cache.Cache // exists due to eventual consistency on the cache
 	recorder      events.Recorder
+	cluster       *state.Cluster
+
+	unavailableOfferings *cloudprovider.UnavailableOfferings
 }
 
 func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
@@ -57,8 +66,13 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconc
 	//     patching failed on the status. In this case, we use the in-memory cached value for the created NodeClaim.
 	//  2. It is a standard NodeClaim launch where we should call CloudProvider Create() and fill in details of the launched
 	//     NodeClaim into the NodeClaim CR.
+	//  3. It was created to adopt a Node whose instance already exists, in which case we resolve that instance.
 	if ret, ok := l.cache.Get(string(nodeClaim.UID)); ok {
 		created = ret.(*v1.NodeClaim)
+	} else if providerID, ok := nodeClaim.Annotations[v1.AdoptedProviderIDAnnotationKey]; ok {
+		if created, err = l.cloudProvider.Get(ctx, providerID); err != nil {
+			return reconcile.Result{}, fmt.Errorf("getting adopted instance, %w", err)
+		}
 	} else {
 		created, err = l.launchNodeClaim(ctx, nodeClaim)
 	}
@@ -68,10 +82,46 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconc
 	}
 	l.cache.SetDefault(string(nodeClaim.UID), created)
 	nodeClaim = PopulateNodeClaimDetails(nodeClaim, created)
+	if err = l.populateNodePoolResources(ctx, nodeClaim); err != nil {
+		return reconcile.Result{}, err
+	}
 	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+	l.reconcileInstanceTypePrediction(ctx, nodeClaim)
 	return reconcile.Result{}, nil
 }
 
+// reconcileInstanceTypePrediction records whether the cloud provider launched the instance type that the scheduler
+// predicted
+func (l *Launch) reconcileInstanceTypePrediction(ctx context.Context, nodeClaim *v1.NodeClaim) {
+	predicted, ok := nodeClaim.Annotations[v1.PredictedInstanceTypeAnnotationKey]
+	launched := nodeClaim.Labels[corev1.LabelInstanceTypeStable]
+	if !ok || launched == "" {
+		return
+	}
+	InstanceTypePredictionsTotal.Inc(map[string]string{
+		metrics.NodePoolLabel: nodeClaim.Labels[v1.NodePoolLabelKey],
+		matchLabel:            strconv.FormatBool(predicted == launched),
+	})
+	if predicted != launched {
+		log.FromContext(ctx).WithValues("predicted-instance-type", predicted, "instance-type", launched).V(1).Info("launched a different instance type than predicted")
+		l.recorder.Publish(InstanceTypeMismatchEvent(nodeClaim, predicted, launched))
+	}
+}
+
+// updateClusterState updates cluster state as soon as a NodeClaim that launched with a different instance type than
+// predicted is persisted, so that in-flight capacity reflects the launched instance type rather than waiting for the
+// update to come back through the informer. This is only done after the patch succeeds so that cluster state never
+// reflects a launch that wasn't persisted.
+func (l *Launch) updateClusterState(stored, persisted *v1.NodeClaim) {
+	if stored.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue() || !persisted.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue() {
+		return
+	}
+	if predicted, ok := persisted.Annotations[v1.PredictedInstanceTypeAnnotationKey]; !ok || predicted == persisted.Labels[corev1.LabelInstanceTypeStable] {
+		return
+	}
+	l.cluster.UpdateNodeClaim(persisted.DeepCopy())
+}
+
 func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
 	created, err := l.cloudProvider.Create(ctx, nodeClaim)
 	if err != nil {
@@ -79,8 +129,11 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (
 		case cloudprovider.IsInsufficientCapacityError(err):
 			l.recorder.Publish(InsufficientCapacityErrorEvent(nodeClaim, err))
 			log.FromContext(ctx).Error(err, "failed launching nodeclaim")
+			if offerings := unavailableOfferings(nodeClaim, err); len(offerings) > 0 {
+				l.unavailableOfferings.MarkUnavailable(offerings...)
+			}
 
-			if err = l.kubeClient.Delete(ctx, nodeClaim); err != nil {
+			if err = nodeclaimutils.Delete(ctx, l.kubeClient, nodeClaim, v1.TerminationReasonInsufficientCapacity); err != nil {
 				return nil, client.IgnoreNotFound(err)
 			}
 			metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
@@ -91,7 +144,7 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (
 			return nil, nil
 		case cloudprovider.IsNodeClassNotReadyError(err):
 			log.FromContext(ctx).Error(err, "failed launching nodeclaim")
-			if err = l.kubeClient.Delete(ctx, nodeClaim); err != nil {
+			if err = nodeclaimutils.Delete(ctx, l.kubeClient, nodeClaim, v1.TerminationReasonNodeClassNotReady); err != nil {
 				return nil, client.IgnoreNotFound(err)
 			}
 			metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
@@ -101,12 +154,7 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (
 			})
 			return nil, nil
 		default:
-			var createError *cloudprovider.CreateError
-			if errors.As(err, &createError) {
-				nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeLaunched, "LaunchFailed", createError.ConditionMessage)
-			} else {
-				nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeLaunched, "LaunchFailed", truncateMessage(err.Error()))
-			}
+			nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeLaunched, launchFailedReason(err), launchFailedMessage(err))
 			return nil, fmt.Errorf("launching nodeclaim, %w", err)
 		}
 	}
@@ -119,6 +167,44 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (
 	return created, nil
 }
 
+// populateNodePoolResources advertises the extended resources that the cluster operator defined on the NodePool
+// on the NodeClaim since the cloud provider doesn't know about them. Like the kubelet, huge pages are subtracted from
+// the allocatable memory. The pods are lowered to the NodePool's maxPodsPerNode, matching the instance types that the
+// NodeClaim was scheduled against.
+func (l *Launch) populateNodePoolResources(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	nodePool, err := nodePoolForNodeClaim(ctx, l.kubeClient, nodeClaim)
+	if err != nil || nodePool == nil {
+		return err
+	}
+	additional := cloudprovider.AdditionalResources(nodePool, scheduling.NewLabelRequirements(nodeClaim.Labels))
+	nodeClaim.Status.Capacity = cloudprovider.MaxPods(nodePool, lo.Assign(nodeClaim.Status.Capacity, additional))
+	nodeClaim.Status.Allocatable = cloudprovider.MaxPods(nodePool, lo.Assign(nodeClaim.Status.Allocatable, additional))
+	nodeClaim.Status.Allocatable = resources.Subtract(nodeClaim.Status.Allocatable, corev1.ResourceList{corev1.ResourceMemory: cloudprovider.HugePages(additional)})
+	return nil
+}
+
+// additionalResources returns the extended resources that the NodeClaim's NodePool advertises for the NodeClaim
+func additionalResources(ctx context.Context, kubeClient client.Client, nodeClaim *v1.NodeClaim) (corev1.ResourceList, error) {
+	nodePool, err := nodePoolForNodeClaim(ctx, kubeClient, nodeClaim)
+	if err != nil || nodePool == nil {
+		return nil, err
+	}
+	return cloudprovider.AdditionalResources(nodePool, scheduling.NewLabelRequirements(nodeClaim.Labels)), nil
+}
+
+// nodePoolForNodeClaim returns the NodeClaim's NodePool, or nil if the NodeClaim was created without a NodePool or the
+// NodePool no longer exists
+func nodePoolForNodeClaim(ctx context.Context, kubeClient client.Client, nodeClaim *v1.NodeClaim) (*v1.NodePool, error) {
+	if _, ok := nodeClaim.Labels[v1.NodePoolLabelKey]; !ok {
+		return nil, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: nodeClaim.Labels[v1.NodePoolLabelKey]}, nodePool); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return nodePool, nil
+}
+
 func PopulateNodeClaimDetails(nodeClaim, retrieved *v1.NodeClaim) *v1.NodeClaim {
 	// These are ordered in priority order so that user-defined nodeClaim labels and requirements trump retrieved labels
 	// or the static nodeClaim labels
@@ -135,9 +221,43 @@ func PopulateNodeClaimDetails(nodeClaim, retrieved *v1.NodeClaim) *v1.NodeClaim
 	return nodeClaim
 }
 
+// launchFailedReason returns the typed reason that the CloudProvider classified the launch failure with, falling back
+// to a generic reason for errors that aren't classified
+func launchFailedReason(err error) string {
+	if providerErr := cloudprovider.ProviderErrorFor(err); providerErr != nil {
+		return string(providerErr.Reason)
+	}
+	return "LaunchFailed"
+}
+
+func launchFailedMessage(err error) string {
+	var createError *cloudprovider.CreateError
+	if errors.As(err, &createError) {
+		return createError.ConditionMessage
+	}
+	if providerErr := cloudprovider.ProviderErrorFor(err); providerErr != nil && providerErr.Code != "" {
+		return truncateMessage(fmt.Sprintf("%s (code: %s)", err.Error(), providerErr.Code))
+	}
+	return truncateMessage(err.Error())
+}
+
 func truncateMessage(msg string) string {
-	if len(msg) < 300 {
-		return msg
+	return pretty.Truncate(msg, 300)
+}
+
+// unavailableOfferings returns the offerings that were out of capacity when launching the NodeClaim. If the cloud
+// provider doesn't report the offerings, the offering is only known when the NodeClaim requires a single instance type,
+// zone and capacity type.
+func unavailableOfferings(nodeClaim *v1.NodeClaim, err error) []cloudprovider.OfferingID {
+	if offerings := cloudprovider.InsufficientCapacityOfferings(err); len(offerings) > 0 {
+		return offerings
+	}
+	requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...)
+	instanceTypes := requirements.Get(corev1.LabelInstanceTypeStable)
+	zones := requirements.Get(corev1.LabelTopologyZone)
+	capacityTypes := requirements.Get(v1.CapacityTypeLabelKey)
+	if instanceTypes.Len() != 1 || zones.Len() != 1 || capacityTypes.Len() != 1 {
+		return nil
 	}
-	return msg[:300] + "..."
+	return []cloudprovider.OfferingID{{InstanceType: instanceTypes.Any(), Zone: zones.Any(), CapacityType: capacityTypes.Any()}}
 }
@@ -22,10 +22,15 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -86,6 +91,115 @@ var _ = Describe("Launch", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
 	})
+	It("should resolve the instance of an adopted NodeClaim rather than launching another one", func() {
+		providerID := test.RandomProviderID()
+		cloudProvider.CreatedNodeClaims[providerID] = test.NodeClaim(v1.NodeClaim{Status: v1.NodeClaimStatus{ProviderID: providerID}})
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+				Annotations: map[string]string{v1.AdoptedProviderIDAnnotationKey: providerID},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		Expect(nodeClaim.Status.ProviderID).To(Equal(providerID))
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue()).To(BeTrue())
+	})
+	It("should launch manually owned NodeClaims that were created without a NodePool", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1.OwnerAnnotationKey: v1.OwnerManual,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
+	})
+	It("should lower the pods of the NodeClaim to the nodepool's max pods", func() {
+		nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](2)
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Capacity.Pods().Value()).To(BeNumerically("==", 2))
+		Expect(nodeClaim.Status.Allocatable.Pods().Value()).To(BeNumerically("==", 2))
+	})
+	It("should record when the cloudprovider launches a different instance type than predicted", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+				Annotations: map[string]string{
+					v1.PredictedInstanceTypeAnnotationKey: "predicted-instance-type",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		launched := nodeClaim.Labels[corev1.LabelInstanceTypeStable]
+		Expect(launched).ToNot(BeEmpty())
+		ExpectMetricCounterValue(nodeclaimlifecycle.InstanceTypePredictionsTotal, 1, map[string]string{
+			"nodepool": nodePool.Name,
+			"match":    "false",
+		})
+		// Cluster state should reflect the launched instance type without waiting on the informer
+		stateNode, ok := lo.Find(cluster.Nodes(), func(n *state.StateNode) bool { return n.NodeClaim.Name == nodeClaim.Name })
+		Expect(ok).To(BeTrue())
+		Expect(stateNode.Labels()).To(HaveKeyWithValue(corev1.LabelInstanceTypeStable, launched))
+	})
+	It("should record a match when the cloudprovider launches the predicted instance type", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		launched := nodeClaim.Labels[corev1.LabelInstanceTypeStable]
+
+		nodeClaim = test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+				Annotations: map[string]string{
+					v1.PredictedInstanceTypeAnnotationKey: launched,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectMetricCounterValue(nodeclaimlifecycle.InstanceTypePredictionsTotal, 1, map[string]string{
+			"nodepool": nodePool.Name,
+			"match":    "true",
+		})
+		_, found := FindMetricWithLabelValues("karpenter_nodeclaims_instance_type_predictions_total", map[string]string{
+			"nodepool": nodePool.Name,
+			"match":    "false",
+		})
+		Expect(found).To(BeFalse())
+	})
 	It("should delete the nodeclaim if InsufficientCapacity is returned from the cloudprovider", func() {
 		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
 		nodeClaim := test.NodeClaim()
@@ -94,11 +208,42 @@ var _ = Describe("Launch", func() {
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should mark the offerings that are out of capacity as unavailable", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"),
+			cloudprovider.OfferingID{InstanceType: "default-instance-type", Zone: "test-zone-1", CapacityType: v1.CapacityTypeSpot})
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-1", v1.CapacityTypeSpot)).To(BeTrue())
+
+		// The offerings are persisted in the background once the launch requested a sync
+		Expect(unavailableOfferings.Pending()).To(Receive())
+		Expect(unavailableOfferings.Sync(ctx)).To(Succeed())
+		persisted := &v1alpha1.UnavailableOfferings{}
+		Expect(env.Client.Get(ctx, client.ObjectKey{Name: v1alpha1.UnavailableOfferingsName}, persisted)).To(Succeed())
+		Expect(persisted.Status.Offerings).To(ContainElement(HaveField("InstanceType", "default-instance-type")))
+	})
+	It("should mark the offering that the nodeclaim requires as unavailable when the cloudprovider doesn't report offerings", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			Spec: v1.NodeClaimSpec{
+				Requirements: []v1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"small-instance-type"}}},
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeOnDemand}}},
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		Expect(unavailableOfferings.IsUnavailable("small-instance-type", "test-zone-2", v1.CapacityTypeOnDemand)).To(BeTrue())
+	})
 	It("should delete the nodeclaim if NodeClassNotReady is returned from the cloudprovider", func() {
 		cloudProvider.NextCreateErr = cloudprovider.NewNodeClassNotReadyError(fmt.Errorf("nodeClass isn't ready"))
 		nodeClaim := test.NodeClaim()
 		ExpectApplied(ctx, env.Client, nodeClaim)
 		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).Status.TerminationReason).To(Equal(v1.TerminationReasonNodeClassNotReady))
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
@@ -113,4 +258,26 @@ var _ = Describe("Launch", func() {
 		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
 		Expect(condition.Message).To(Equal(conditionMessage))
 	})
+	It("should set the typed reason on the nodeClaim status condition if the error is classified by the cloudprovider", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewProviderError(fmt.Errorf("vcpu limit exceeded"), cloudprovider.ProviderErrorReasonQuotaExceeded, "VcpuLimitExceeded")
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		_ = ExpectObjectReconcileFailed(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		condition := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched)
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(string(cloudprovider.ProviderErrorReasonQuotaExceeded)))
+		Expect(condition.Message).To(ContainSubstring("VcpuLimitExceeded"))
+	})
+	It("should prefer the condition message of a CreateError when the error is classified by the cloudprovider", func() {
+		conditionMessage := "subnet not found"
+		cloudProvider.NextCreateErr = cloudprovider.NewProviderError(cloudprovider.NewCreateError(fmt.Errorf("error launching instance"), conditionMessage), cloudprovider.ProviderErrorReasonInvalidSubnet, "InvalidSubnetID.NotFound")
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		_ = ExpectObjectReconcileFailed(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		condition := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched)
+		Expect(condition.Reason).To(Equal(string(cloudprovider.ProviderErrorReasonInvalidSubnet)))
+		Expect(condition.Message).To(Equal(conditionMessage))
+	})
 })
@@ -20,47 +20,124 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 )
 
 type Liveness struct {
-	clock      clock.Clock
-	kubeClient client.Client
+	clock              clock.Clock
+	kubeClient         client.Client
+	provisioner        *provisioning.Provisioner
+	unhealthyOfferings *cloudprovider.UnhealthyOfferings
 }
 
 // registrationTTL is a heuristic time that we expect the node to register within
 // If we don't see the node within this time, then we should delete the NodeClaim and try again
+// NodePools can override this with spec.template.spec.registrationTTL
 const registrationTTL = time.Minute * 15
 
 func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
 	registered := nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered)
 	if registered.IsTrue() {
-		return reconcile.Result{}, nil
+		return l.reconcileReadiness(ctx, nodeClaim, registered.LastTransitionTime.Time)
 	}
 	if registered == nil {
 		return reconcile.Result{Requeue: true}, nil
 	}
+	ttl := l.nodeRegistrationTTL(ctx, nodeClaim)
 	// If the Registered statusCondition hasn't gone True during the TTL since we first updated it, we should terminate the NodeClaim
 	// NOTE: ttl has to be stored and checked in the same place since l.clock can advance after the check causing a race
-	if ttl := registrationTTL - l.clock.Since(registered.LastTransitionTime.Time); ttl > 0 {
-		return reconcile.Result{RequeueAfter: ttl}, nil
+	if remaining := ttl - l.clock.Since(registered.LastTransitionTime.Time); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
 	}
-	// Delete the NodeClaim if we believe the NodeClaim won't register since we haven't seen the node
-	if err := l.kubeClient.Delete(ctx, nodeClaim); err != nil {
+	// If the instance launched but its Node never joined, the offering is likely unable to produce working capacity
+	// right now, so we avoid it while the replacement capacity is launched
+	if nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue() {
+		l.unhealthyOfferings.MarkUnhealthy(nodeClaim.Labels[corev1.LabelInstanceTypeStable], nodeClaim.Labels[corev1.LabelTopologyZone], nodeClaim.Labels[v1.CapacityTypeLabelKey])
+	}
+	// Delete the NodeClaim if we believe the NodeClaim won't register since we haven't seen the node. There was never a
+	// node to repair, so this is recorded as the NodeClaim failing to provide capacity
+	if err := nodeclaimutils.Delete(ctx, l.kubeClient, nodeClaim, v1.TerminationReasonInsufficientCapacity); err != nil {
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
-	log.FromContext(ctx).V(1).WithValues("ttl", registrationTTL).Info("terminating due to registration ttl")
+	log.FromContext(ctx).V(1).WithValues("ttl", ttl).Info("terminating due to registration ttl")
 	metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 		metrics.ReasonLabel:       "liveness",
 		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
 		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
 	})
+	// The pods that were waiting on this NodeClaim are still pending, so we retry them immediately rather than
+	// waiting for the next pod event to trigger a scheduling simulation
+	l.provisioner.Trigger(nodeClaim.UID, provisioning.TriggerSourceNodeClaim)
+	return reconcile.Result{}, nil
+}
+
+// nodeRegistrationTTL returns the registration TTL of the NodeClaim's NodePool, falling back to the default if the
+// NodePool doesn't set one
+func (l *Liveness) nodeRegistrationTTL(ctx context.Context, nodeClaim *v1.NodeClaim) time.Duration {
+	nodePoolName, ok := nodeClaim.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return registrationTTL
+	}
+	nodePool := &v1.NodePool{}
+	if err := l.kubeClient.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return registrationTTL
+	}
+	if nodePool.Spec.Template.Spec.RegistrationTTL != nil {
+		return nodePool.Spec.Template.Spec.RegistrationTTL.Duration
+	}
+	return registrationTTL
+}
 
+// reconcileReadiness deletes NodeClaims whose Node registered but never went Ready within the readiness TTL
+// configured on the NodePool. The offering that the NodeClaim launched with is marked as unhealthy so that the
+// provisioner launches the replacement capacity with a different offering.
+func (l *Liveness) reconcileReadiness(ctx context.Context, nodeClaim *v1.NodeClaim, registeredTime time.Time) (reconcile.Result, error) {
+	// NodeClaims that were created without a NodePool don't have a readiness TTL
+	if _, ok := nodeClaim.Labels[v1.NodePoolLabelKey]; !ok || nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue() {
+		return reconcile.Result{}, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := l.kubeClient.Get(ctx, types.NamespacedName{Name: nodeClaim.Labels[v1.NodePoolLabelKey]}, nodePool); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if nodePool.Spec.Template.Spec.ReadinessTTL == nil {
+		return reconcile.Result{}, nil
+	}
+	readinessTTL := nodePool.Spec.Template.Spec.ReadinessTTL.Duration
+	node, err := nodeclaimutils.NodeForNodeClaim(ctx, l.kubeClient, nodeClaim)
+	if err != nil {
+		return reconcile.Result{}, nil //nolint:nilerr
+	}
+	if nodeutils.GetCondition(node, corev1.NodeReady).Status == corev1.ConditionTrue {
+		return reconcile.Result{}, nil
+	}
+	// NOTE: ttl has to be stored and checked in the same place since l.clock can advance after the check causing a race
+	if ttl := readinessTTL - l.clock.Since(registeredTime); ttl > 0 {
+		return reconcile.Result{RequeueAfter: ttl}, nil
+	}
+	l.unhealthyOfferings.MarkUnhealthy(nodeClaim.Labels[corev1.LabelInstanceTypeStable], nodeClaim.Labels[corev1.LabelTopologyZone], nodeClaim.Labels[v1.CapacityTypeLabelKey])
+	if err := nodeclaimutils.Delete(ctx, l.kubeClient, nodeClaim, v1.TerminationReasonRepair); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).V(1).WithValues("Node", klog.KRef("", node.Name), "ttl", readinessTTL).Info("terminating due to readiness ttl")
+	metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
+		metrics.ReasonLabel:       "readiness",
+		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
+	})
+	l.provisioner.Trigger(nodeClaim.UID, provisioning.TriggerSourceNodeClaim)
 	return reconcile.Result{}, nil
 }
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -144,4 +145,143 @@ var _ = Describe("Liveness", func() {
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should mark the offering as unhealthy when a launched NodeClaim hasn't registered past the registration ttl", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue()).To(BeTrue())
+
+		fakeClock.Step(time.Minute * 20)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).Status.TerminationReason).To(Equal(v1.TerminationReasonInsufficientCapacity))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+		Expect(unhealthyOfferings.IsUnhealthy(
+			nodeClaim.Labels[corev1.LabelInstanceTypeStable],
+			nodeClaim.Labels[corev1.LabelTopologyZone],
+			nodeClaim.Labels[v1.CapacityTypeLabelKey],
+		)).To(BeTrue())
+	})
+	It("should use the registration ttl from the NodePool when it is set", func() {
+		nodePool.Spec.Template.Spec.RegistrationTTL = &metav1.Duration{Duration: time.Minute * 5}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		// The NodeClaim should still exist before the NodePool's registration ttl has passed
+		fakeClock.Step(time.Minute * 3)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 3)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should fall back to the default registration ttl when the NodePool doesn't set one", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 10)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 10)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	Context("Readiness", func() {
+		var nodeClaim *v1.NodeClaim
+		BeforeEach(func() {
+			nodePool.Spec.Template.Spec.ReadinessTTL = &metav1.Duration{Duration: time.Minute * 10}
+			nodeClaim = test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey: nodePool.Name,
+					},
+				},
+			})
+		})
+		It("should delete the NodeClaim and mark the offering as unhealthy when the node hasn't gone ready past the readiness ttl", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			node := test.NodeClaimLinkedNode(nodeClaim)
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesNotReady(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()).To(BeTrue())
+
+			// The NodeClaim should still exist before the readiness ttl has passed
+			fakeClock.Step(time.Minute * 5)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectExists(ctx, env.Client, nodeClaim)
+
+			fakeClock.Step(time.Minute * 10)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).Status.TerminationReason).To(Equal(v1.TerminationReasonRepair))
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+			Expect(unhealthyOfferings.IsUnhealthy(
+				nodeClaim.Labels[corev1.LabelInstanceTypeStable],
+				nodeClaim.Labels[corev1.LabelTopologyZone],
+				nodeClaim.Labels[v1.CapacityTypeLabelKey],
+			)).To(BeTrue())
+		})
+		It("shouldn't delete the NodeClaim when the node is ready", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			node := test.NodeClaimLinkedNode(nodeClaim)
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			fakeClock.Step(time.Minute * 20)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("shouldn't delete the NodeClaim when the NodePool doesn't set a readiness ttl", func() {
+			nodePool.Spec.Template.Spec.ReadinessTTL = nil
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			node := test.NodeClaimLinkedNode(nodeClaim)
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesNotReady(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			fakeClock.Step(time.Hour)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+			Expect(unhealthyOfferings.IsUnhealthy(
+				nodeClaim.Labels[corev1.LabelInstanceTypeStable],
+				nodeClaim.Labels[corev1.LabelTopologyZone],
+				nodeClaim.Labels[v1.CapacityTypeLabelKey],
+			)).To(BeFalse())
+		})
+	})
 })
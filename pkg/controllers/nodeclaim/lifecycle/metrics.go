@@ -24,6 +24,10 @@ import (
 	"sigs.k8s.io/karpenter/pkg/metrics"
 )
 
+const (
+	matchLabel = "match"
+)
+
 var InstanceTerminationDurationSeconds = opmetrics.NewPrometheusHistogram(
 	crmetrics.Registry,
 	prometheus.HistogramOpts{
@@ -46,3 +50,14 @@ var NodeClaimTerminationDurationSeconds = opmetrics.NewPrometheusHistogram(
 		Buckets:   prometheus.ExponentialBuckets(1, 2, 12)}, //The threshold values generated here are 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024. 2048
 	[]string{metrics.NodePoolLabel},
 )
+
+var InstanceTypePredictionsTotal = opmetrics.NewPrometheusCounter(
+	crmetrics.Registry,
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeClaimSubsystem,
+		Name:      "instance_type_predictions_total",
+		Help:      "Number of launched nodeclaims that the scheduler predicted an instance type for. Labeled by the owning nodepool and whether the cloud provider launched the predicted instance type.",
+	},
+	[]string{metrics.NodePoolLabel, matchLabel},
+)
@@ -19,6 +19,7 @@ package lifecycle
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
@@ -34,10 +35,12 @@ import (
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
 )
 
 type Registration struct {
-	kubeClient client.Client
+	kubeClient  client.Client
+	providerIDs *providerid.Mapping
 }
 
 func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
@@ -47,7 +50,7 @@ func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (
 		return reconcile.Result{}, nil
 	}
 	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("provider-id", nodeClaim.Status.ProviderID))
-	node, err := nodeclaimutils.NodeForNodeClaim(ctx, r.kubeClient, nodeClaim)
+	node, err := r.providerIDs.NodeForNodeClaim(ctx, r.kubeClient, nodeClaim)
 	if err != nil {
 		if nodeclaimutils.IsNodeNotFoundError(err) {
 			nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeRegistered, "NodeNotFound", "Node not registered with cluster")
@@ -75,6 +78,9 @@ func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (
 		}
 		return reconcile.Result{}, err
 	}
+	if err = r.syncNodeStatus(ctx, nodeClaim, node); err != nil {
+		return reconcile.Result{}, err
+	}
 	log.FromContext(ctx).Info("registered nodeclaim")
 	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
 	nodeClaim.Status.NodeName = node.Name
@@ -90,6 +96,16 @@ func (r *Registration) syncNode(ctx context.Context, nodeClaim *v1.NodeClaim, no
 	controllerutil.AddFinalizer(node, v1.TerminationFinalizer)
 
 	node = nodeclaimutils.UpdateNodeOwnerReferences(nodeClaim, node)
+	// The NodePool's node metadata is only applied to the Node, so it's applied first to let the NodeClaim's metadata
+	// take precedence
+	nodePool, err := nodePoolForNodeClaim(ctx, r.kubeClient, nodeClaim)
+	if err != nil {
+		return fmt.Errorf("getting nodepool, %w", err)
+	}
+	if nodePool != nil {
+		node.Labels = lo.Assign(node.Labels, nodePool.Spec.Template.NodeMetadata.Labels)
+		node.Annotations = lo.Assign(node.Annotations, nodePool.Spec.Template.NodeMetadata.Annotations)
+	}
 	node.Labels = lo.Assign(node.Labels, nodeClaim.Labels)
 	node.Annotations = lo.Assign(node.Annotations, nodeClaim.Annotations)
 	// Sync all taints inside NodeClaim into the Node taints
@@ -112,3 +128,29 @@ func (r *Registration) syncNode(ctx context.Context, nodeClaim *v1.NodeClaim, no
 	}
 	return nil
 }
+
+// syncNodeStatus advertises the extended resources that the cluster operator defined on the NodePool on the Node.
+// Since there's no device plugin for these resources, kubelet preserves them once they're set. Huge pages are
+// reported by the kubelet from the node's configuration, so they're never set on the Node.
+func (r *Registration) syncNodeStatus(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node) error {
+	additional, err := additionalResources(ctx, r.kubeClient, nodeClaim)
+	if err != nil {
+		return err
+	}
+	stored := node.DeepCopy()
+	for name, quantity := range additional {
+		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			continue
+		}
+		if _, ok := node.Status.Capacity[name]; !ok {
+			node.Status.Capacity = lo.Assign(node.Status.Capacity, corev1.ResourceList{name: quantity})
+			node.Status.Allocatable = lo.Assign(node.Status.Allocatable, corev1.ResourceList{name: quantity})
+		}
+	}
+	if !equality.Semantic.DeepEqual(stored, node) {
+		if err := r.kubeClient.Status().Patch(ctx, node, client.MergeFrom(stored)); err != nil {
+			return fmt.Errorf("syncing node status, %w", err)
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -184,6 +185,36 @@ var _ = Describe("Registration", func() {
 			Expect(node.Annotations).To(HaveKeyWithValue(k, v))
 		}
 	})
+	It("should sync the nodepool's node metadata to the Node without setting it on the NodeClaim", func() {
+		nodePool.Spec.Template.NodeMetadata = v1.ObjectMeta{
+			Labels:      map[string]string{"node-only-label": "value", "custom-label": "node-value"},
+			Annotations: map[string]string{"node-agent/config": "value"},
+		}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+					"custom-label":      "custom-value",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("node-only-label", "value"))
+		Expect(node.Annotations).To(HaveKeyWithValue("node-agent/config", "value"))
+		// The NodeClaim's labels take precedence over the node metadata
+		Expect(node.Labels).To(HaveKeyWithValue("custom-label", "custom-value"))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Labels).ToNot(HaveKey("node-only-label"))
+		Expect(nodeClaim.Annotations).ToNot(HaveKey("node-agent/config"))
+	})
 	It("should sync the taints to the Node when the Node comes online", func() {
 		nodeClaim := test.NodeClaim(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -368,4 +399,50 @@ var _ = Describe("Registration", func() {
 		node = ExpectExists(ctx, env.Client, node)
 		Expect(node.Spec.Taints).To(HaveLen(0))
 	})
+	It("should advertise the nodepool's additional resources on the NodeClaim and the Node", func() {
+		nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: "example.com/licenses", Quantity: resource.MustParse("2")}}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Capacity.Name("example.com/licenses", resource.DecimalSI).Value()).To(BeEquivalentTo(2))
+		Expect(nodeClaim.Status.Allocatable.Name("example.com/licenses", resource.DecimalSI).Value()).To(BeEquivalentTo(2))
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Status.Capacity.Name("example.com/licenses", resource.DecimalSI).Value()).To(BeEquivalentTo(2))
+		Expect(node.Status.Allocatable.Name("example.com/licenses", resource.DecimalSI).Value()).To(BeEquivalentTo(2))
+	})
+	It("should subtract huge pages from the NodeClaim's allocatable memory without setting them on the Node", func() {
+		nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: "hugepages-2Mi", Quantity: resource.MustParse("1Gi")}}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		launched := cloudProvider.CreatedNodeClaims[ExpectExists(ctx, env.Client, nodeClaim).Status.ProviderID]
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.Capacity).To(HaveKeyWithValue(corev1.ResourceName("hugepages-2Mi"), resource.MustParse("1Gi")))
+		expected := launched.Status.Allocatable.Memory().DeepCopy()
+		expected.Sub(resource.MustParse("1Gi"))
+		Expect(nodeClaim.Status.Allocatable.Memory().Cmp(expected)).To(Equal(0))
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Status.Capacity).ToNot(HaveKey(corev1.ResourceName("hugepages-2Mi")))
+	})
 })
@@ -34,13 +34,18 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -49,6 +54,10 @@ var nodeClaimController *nodeclaimlifecycle.Controller
 var env *test.Environment
 var fakeClock *clock.FakeClock
 var cloudProvider *fake.CloudProvider
+var unhealthyOfferings *cloudprovider.UnhealthyOfferings
+var unavailableOfferings *cloudprovider.UnavailableOfferings
+var cluster *state.Cluster
+var initializationCheck *fakeInitializationCheck
 
 func TestAPIs(t *testing.T) {
 	ctx = TestContextWithLogger(t)
@@ -74,7 +83,13 @@ var _ = BeforeSuite(func() {
 	ctx = options.ToContext(ctx, test.Options())
 
 	cloudProvider = fake.NewCloudProvider()
-	nodeClaimController = nodeclaimlifecycle.NewController(fakeClock, env.Client, cloudProvider, events.NewRecorder(&record.FakeRecorder{}))
+	unhealthyOfferings = cloudprovider.NewUnhealthyOfferings()
+	unavailableOfferings = cloudprovider.NewUnavailableOfferings(env.Client, fakeClock)
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	recorder := events.NewRecorder(&record.FakeRecorder{})
+	prov := provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock, unhealthyOfferings, unavailableOfferings, scheduling.NewLabelAliases())
+	initializationCheck = &fakeInitializationCheck{initialized: true}
+	nodeClaimController = nodeclaimlifecycle.NewController(fakeClock, env.Client, cloudProvider, recorder, cluster, prov, unhealthyOfferings, unavailableOfferings, providerid.NewMapping(), initializationCheck)
 })
 
 var _ = AfterSuite(func() {
@@ -85,6 +100,10 @@ var _ = AfterEach(func() {
 	fakeClock.SetTime(time.Now())
 	ExpectCleanedUp(ctx, env.Client)
 	cloudProvider.Reset()
+	cluster.Reset()
+	unhealthyOfferings.Flush()
+	unavailableOfferings.Flush()
+	initializationCheck.initialized = true
 })
 
 var _ = Describe("Finalizer", func() {
@@ -35,8 +35,10 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 )
 
 var _ = Describe("Termination", func() {
@@ -66,6 +68,7 @@ var _ = Describe("Termination", func() {
 			},
 		})
 		lifecycle.InstanceTerminationDurationSeconds.Reset()
+		metrics.NodeClaimsTerminatedTotal.Reset()
 	})
 	DescribeTable(
 		"Termination",
@@ -260,6 +263,34 @@ var _ = Describe("Termination", func() {
 		Expect(cloudProvider.DeleteCalls).To(HaveLen(0))
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should record a manual termination reason when the NodeClaim is deleted without a reason", func() {
+		nodeClaim.Status.ProviderID = ""
+		nodeClaim.Finalizers = append(nodeClaim.Finalizers, "test-finalizer")
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.TerminationReason).To(Equal(v1.TerminationReasonManual))
+		ExpectMetricCounterValue(metrics.NodeClaimsTerminatedTotal, 1, map[string]string{
+			metrics.ReasonLabel:   string(v1.TerminationReasonManual),
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
+	It("should keep the termination reason that was recorded when the NodeClaim was deleted", func() {
+		nodeClaim.Status.ProviderID = ""
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		Expect(nodeclaimutils.Delete(ctx, env.Client, nodeClaim, v1.TerminationReasonExpiration)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+		ExpectMetricCounterValue(metrics.NodeClaimsTerminatedTotal, 1, map[string]string{
+			metrics.ReasonLabel:   string(v1.TerminationReasonExpiration),
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
 	It("should not delete nodes without provider ids if the NodeClaim hasn't been launched yet", func() {
 		// Generate 10 nodes, none of which have a provider id
 		var nodes []*corev1.Node
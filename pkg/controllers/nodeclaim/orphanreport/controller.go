@@ -0,0 +1,154 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphanreport
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+)
+
+// launchGracePeriod is the amount of time that an instance can exist without a NodeClaim before it's reported,
+// since the NodeClaim only learns the provider id of its instance once the launch returns
+const launchGracePeriod = time.Minute
+
+// Controller periodically compares NodeClaims, cloud provider instances and Nodes and records any that are
+// out of sync in the OrphanReport and the orphan metrics. Unlike garbage collection, it never deletes anything.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+func NewController(c clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+	return &Controller{
+		clock:         c,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodeclaim.orphanreport")
+
+	nodeClaims, err := nodeclaimutils.ListManaged(ctx, c.kubeClient, c.cloudProvider)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	instances, err := c.cloudProvider.List(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	nodeList := &corev1.NodeList{}
+	if err = c.kubeClient.List(ctx, nodeList); err != nil {
+		return reconcile.Result{}, err
+	}
+	instances = lo.Filter(instances, func(i *v1.NodeClaim, _ int) bool { return i.DeletionTimestamp.IsZero() })
+	instanceProviderIDs := sets.New(lo.Map(instances, func(i *v1.NodeClaim, _ int) string { return i.Status.ProviderID })...)
+	nodeClaimProviderIDs := sets.New(lo.Map(nodeClaims, func(nc *v1.NodeClaim, _ int) string { return nc.Status.ProviderID })...)
+
+	status := v1alpha1.OrphanReportStatus{
+		// Only consider NodeClaims that are Registered, since a NodeClaim that hasn't registered yet is handled by the
+		// registration timeout rather than relying on the CloudProvider API
+		NodeClaimsWithoutInstances: sorted(lo.FilterMap(nodeClaims, func(nc *v1.NodeClaim, _ int) (string, bool) {
+			return nc.Name, nc.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue() &&
+				nc.DeletionTimestamp.IsZero() &&
+				!instanceProviderIDs.Has(nc.Status.ProviderID)
+		})),
+		InstancesWithoutNodeClaims: sorted(lo.FilterMap(instances, func(i *v1.NodeClaim, _ int) (string, bool) {
+			return i.Status.ProviderID, i.Status.ProviderID != "" &&
+				c.clock.Since(i.CreationTimestamp.Time) >= launchGracePeriod &&
+				!nodeClaimProviderIDs.Has(i.Status.ProviderID)
+		})),
+		NodesWithoutNodeClaimsOrInstances: sorted(lo.FilterMap(nodeList.Items, func(n corev1.Node, _ int) (string, bool) {
+			return n.Name, nodeutils.IsManaged(&n, c.cloudProvider) &&
+				!nodeClaimProviderIDs.Has(n.Spec.ProviderID) &&
+				!instanceProviderIDs.Has(n.Spec.ProviderID)
+		})),
+	}
+	Orphans.Set(float64(len(status.NodeClaimsWithoutInstances)), map[string]string{orphanTypeLabel: orphanTypeNodeClaimWithoutInstance})
+	Orphans.Set(float64(len(status.InstancesWithoutNodeClaims)), map[string]string{orphanTypeLabel: orphanTypeInstanceWithoutNodeClaim})
+	Orphans.Set(float64(len(status.NodesWithoutNodeClaimsOrInstances)), map[string]string{orphanTypeLabel: orphanTypeNodeWithoutEither})
+
+	// the metrics count every orphan, but the report only lists the first of each type so that it stays within the
+	// object size limit
+	status.NodeClaimsWithoutInstances = truncate(status.NodeClaimsWithoutInstances)
+	status.InstancesWithoutNodeClaims = truncate(status.InstancesWithoutNodeClaims)
+	status.NodesWithoutNodeClaimsOrInstances = truncate(status.NodesWithoutNodeClaimsOrInstances)
+
+	if err = c.updateReport(ctx, status); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: time.Minute * 5}, nil
+}
+
+// updateReport writes the status to the singleton OrphanReport, creating the report if it doesn't exist yet
+func (c *Controller) updateReport(ctx context.Context, status v1alpha1.OrphanReportStatus) error {
+	report := &v1alpha1.OrphanReport{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: v1alpha1.OrphanReportName}, report); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		report = &v1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.OrphanReportName}}
+		if err = c.kubeClient.Create(ctx, report); err != nil {
+			return err
+		}
+	}
+	stored := report.DeepCopy()
+	status.LastReportTime = lo.ToPtr(metav1.NewTime(c.clock.Now()))
+	report.Status = status
+	if equality.Semantic.DeepEqual(stored, report) {
+		return nil
+	}
+	return c.kubeClient.Status().Patch(ctx, report, client.MergeFrom(stored))
+}
+
+func sorted(s []string) []string {
+	sort.Strings(s)
+	return s
+}
+
+func truncate(s []string) []string {
+	return s[:min(len(s), v1alpha1.MaxReportedOrphans)]
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodeclaim.orphanreport").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
@@ -0,0 +1,44 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphanreport
+
+import (
+	opmetrics "github.com/awslabs/operatorpkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/karpenter/pkg/metrics"
+)
+
+const (
+	orphanTypeLabel = "type"
+
+	orphanTypeNodeClaimWithoutInstance = "nodeclaim_without_instance"
+	orphanTypeInstanceWithoutNodeClaim = "instance_without_nodeclaim"
+	orphanTypeNodeWithoutEither        = "node_without_nodeclaim_or_instance"
+)
+
+var Orphans = opmetrics.NewPrometheusGauge(
+	crmetrics.Registry,
+	prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orphan_report",
+		Name:      "orphans",
+		Help:      "The number of orphaned resources observed by the last orphan report. Labeled by the type of orphan.",
+	},
+	[]string{orphanTypeLabel},
+)
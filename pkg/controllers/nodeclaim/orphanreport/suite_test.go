@@ -0,0 +1,163 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphanreport_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	karpv1alpha1 "sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/orphanreport"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var orphanReportController *orphanreport.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OrphanReport")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx)))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	orphanReportController = orphanreport.NewController(fakeClock, env.Client, cloudProvider)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	fakeClock.SetTime(time.Now())
+	ExpectCleanedUp(ctx, env.Client)
+	Expect(client.IgnoreNotFound(env.Client.Delete(ctx, &karpv1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.OrphanReportName}}))).To(Succeed())
+	cloudProvider.Reset()
+})
+
+var _ = Describe("OrphanReport", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaim = test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+	})
+	It("should create an empty report when nothing is orphaned", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		_, _, err := ExpectNodeClaimDeployed(ctx, env.Client, cloudProvider, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+
+		ExpectSingletonReconciled(ctx, orphanReportController)
+		report := ExpectExists(ctx, env.Client, &karpv1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.OrphanReportName}})
+		Expect(report.Status.LastReportTime).ToNot(BeNil())
+		Expect(report.Status.NodeClaimsWithoutInstances).To(BeEmpty())
+		Expect(report.Status.InstancesWithoutNodeClaims).To(BeEmpty())
+		Expect(report.Status.NodesWithoutNodeClaimsOrInstances).To(BeEmpty())
+		ExpectMetricGaugeValue(orphanreport.Orphans, 0, map[string]string{"type": "nodeclaim_without_instance"})
+	})
+	It("should report a registered NodeClaim without an instance and not delete it", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		nodeClaim, _, err := ExpectNodeClaimDeployed(ctx, env.Client, cloudProvider, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.Delete(ctx, nodeClaim)).To(Succeed())
+
+		ExpectSingletonReconciled(ctx, orphanReportController)
+		report := ExpectExists(ctx, env.Client, &karpv1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.OrphanReportName}})
+		Expect(report.Status.NodeClaimsWithoutInstances).To(ConsistOf(nodeClaim.Name))
+		ExpectMetricGaugeValue(orphanreport.Orphans, 1, map[string]string{"type": "nodeclaim_without_instance"})
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should report an instance without a NodeClaim once the launch grace period has passed", func() {
+		instance := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(fakeClock.Now())},
+			Status:     v1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+		})
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+
+		ExpectSingletonReconciled(ctx, orphanReportController)
+		report := ExpectExists(ctx, env.Client, &karpv1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.OrphanReportName}})
+		Expect(report.Status.InstancesWithoutNodeClaims).To(BeEmpty())
+
+		fakeClock.Step(time.Minute * 2)
+		ExpectSingletonReconciled(ctx, orphanReportController)
+		report = ExpectExists(ctx, env.Client, report)
+		Expect(report.Status.InstancesWithoutNodeClaims).To(ConsistOf(instance.Status.ProviderID))
+		ExpectMetricGaugeValue(orphanreport.Orphans, 1, map[string]string{"type": "instance_without_nodeclaim"})
+	})
+	It("should only list the first orphans of each type but count all of them in the metrics", func() {
+		for range karpv1alpha1.MaxReportedOrphans + 10 {
+			instance := test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(fakeClock.Now())},
+				Status:     v1.NodeClaimStatus{ProviderID: test.RandomProviderID()},
+			})
+			cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+		}
+		fakeClock.Step(time.Minute * 2)
+
+		ExpectSingletonReconciled(ctx, orphanReportController)
+		report := ExpectExists(ctx, env.Client, &karpv1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.OrphanReportName}})
+		Expect(report.Status.InstancesWithoutNodeClaims).To(HaveLen(karpv1alpha1.MaxReportedOrphans))
+		ExpectMetricGaugeValue(orphanreport.Orphans, float64(karpv1alpha1.MaxReportedOrphans+10), map[string]string{"type": "instance_without_nodeclaim"})
+	})
+	It("should report a managed Node that has neither a NodeClaim nor an instance", func() {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+					v1.NodeClassLabelKey(nodePool.Spec.Template.Spec.NodeClassRef.GroupKind()): nodePool.Spec.Template.Spec.NodeClassRef.Name,
+				},
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		unmanagedNode := test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()})
+		ExpectApplied(ctx, env.Client, nodePool, node, unmanagedNode)
+
+		ExpectSingletonReconciled(ctx, orphanReportController)
+		report := ExpectExists(ctx, env.Client, &karpv1alpha1.OrphanReport{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.OrphanReportName}})
+		Expect(report.Status.NodesWithoutNodeClaimsOrInstances).To(ConsistOf(node.Name))
+		ExpectMetricGaugeValue(orphanreport.Orphans, 1, map[string]string{"type": "node_without_nodeclaim_or_instance"})
+		ExpectExists(ctx, env.Client, node)
+	})
+})
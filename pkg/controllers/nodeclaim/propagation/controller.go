@@ -0,0 +1,156 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+)
+
+// Controller patches the labels, annotations and taints of a NodePool's template onto the NodeClaims and Nodes that it
+// has already launched, for the classes of template fields that the NodePool propagates in place. Changes to these
+// fields are excluded from the NodePool hash, so the existing nodes aren't considered drifted.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("NodeClaim", klog.KRef(nodeClaim.Namespace, nodeClaim.Name)))
+	if !nodeclaimutils.IsManaged(nodeClaim, c.cloudProvider) {
+		return reconcile.Result{}, nil
+	}
+	if !nodeClaim.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	nodePoolName, ok := nodeClaim.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if len(nodePool.Spec.TemplatePropagation.InPlace()) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	stored := nodeClaim.DeepCopy()
+	propagateMetadata(nodePool, &nodeClaim.ObjectMeta)
+	if !equality.Semantic.DeepEqual(stored, nodeClaim) {
+		if err := c.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		log.FromContext(ctx).V(1).Info("propagated nodepool template to nodeclaim")
+	}
+
+	// Nodes that haven't registered yet have the NodeClaim's labels and annotations synced to them during registration
+	node, err := nodeclaimutils.NodeForNodeClaim(ctx, c.kubeClient, nodeClaim)
+	if err != nil {
+		return reconcile.Result{}, nodeclaimutils.IgnoreNodeNotFoundError(nodeclaimutils.IgnoreDuplicateNodeError(err))
+	}
+	storedNode := node.DeepCopy()
+	propagateNodeMetadata(nodePool, &node.ObjectMeta)
+	propagateMetadata(nodePool, &node.ObjectMeta)
+	if nodePool.Spec.TemplatePropagation.Taints == v1.PropagationPolicyInPlace {
+		node.Spec.Taints = propagateTaints(nodePool.Spec.Template.Spec.Taints, nodeClaim.Spec.Taints, node.Spec.Taints)
+	}
+	if !equality.Semantic.DeepEqual(storedNode, node) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the taint list
+		if err := c.kubeClient.Patch(ctx, node, client.MergeFromWithOptions(storedNode, client.MergeFromWithOptimisticLock{})); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		log.FromContext(ctx).WithValues("Node", klog.KObj(node)).V(1).Info("propagated nodepool template to node")
+	}
+	return reconcile.Result{}, nil
+}
+
+// propagateMetadata adds or updates the labels and annotations of the NodePool's template that are propagated in place
+func propagateMetadata(nodePool *v1.NodePool, meta *metav1.ObjectMeta) {
+	if nodePool.Spec.TemplatePropagation.Labels == v1.PropagationPolicyInPlace && len(nodePool.Spec.Template.Labels) > 0 {
+		meta.Labels = lo.Assign(meta.Labels, nodePool.Spec.Template.Labels)
+	}
+	if nodePool.Spec.TemplatePropagation.Annotations == v1.PropagationPolicyInPlace && len(nodePool.Spec.Template.Annotations) > 0 {
+		meta.Annotations = lo.Assign(meta.Annotations, nodePool.Spec.Template.Annotations)
+	}
+}
+
+// propagateNodeMetadata adds or updates the labels and annotations of the NodePool's node metadata that are propagated
+// in place. The node metadata is only propagated to Nodes, never to NodeClaims.
+func propagateNodeMetadata(nodePool *v1.NodePool, meta *metav1.ObjectMeta) {
+	if nodePool.Spec.TemplatePropagation.Labels == v1.PropagationPolicyInPlace && len(nodePool.Spec.Template.NodeMetadata.Labels) > 0 {
+		meta.Labels = lo.Assign(meta.Labels, nodePool.Spec.Template.NodeMetadata.Labels)
+	}
+	if nodePool.Spec.TemplatePropagation.Annotations == v1.PropagationPolicyInPlace && len(nodePool.Spec.Template.NodeMetadata.Annotations) > 0 {
+		meta.Annotations = lo.Assign(meta.Annotations, nodePool.Spec.Template.NodeMetadata.Annotations)
+	}
+}
+
+// propagateTaints replaces the taints that the node was launched with by the current taints of the template. Taints
+// that were launched with the node, but have since been removed from the template, are removed from the node.
+func propagateTaints(templateTaints, launchedTaints, nodeTaints []corev1.Taint) []corev1.Taint {
+	matches := func(t corev1.Taint) func(corev1.Taint) bool {
+		return func(taint corev1.Taint) bool { return taint.MatchTaint(&t) }
+	}
+	taints := lo.Reject(nodeTaints, func(t corev1.Taint, _ int) bool {
+		return lo.ContainsBy(templateTaints, matches(t)) || lo.ContainsBy(launchedTaints, matches(t))
+	})
+	return append(taints, templateTaints...)
+}
+
+func (c *Controller) Name() string {
+	return "nodeclaim.propagation"
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		For(&v1.NodeClaim{}, builder.WithPredicates(nodeclaimutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Watches(&v1.NodePool{}, nodeclaimutils.NodePoolEventHandler(c.kubeClient, c.cloudProvider)).
+		Watches(&corev1.Node{}, nodeclaimutils.NodeEventHandler(c.kubeClient, c.cloudProvider)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For(c.Name(), 10)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,198 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/propagation"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var propagationController *propagation.Controller
+var env *test.Environment
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Propagation")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx)))
+	ctx = options.ToContext(ctx, test.Options())
+
+	cloudProvider = fake.NewCloudProvider()
+	propagationController = propagation.NewController(env.Client, cloudProvider)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+})
+
+var _ = Describe("Propagation", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					ObjectMeta: v1.ObjectMeta{
+						Labels:      map[string]string{"team": "a"},
+						Annotations: map[string]string{"owner": "a"},
+					},
+					Spec: v1.NodeClaimTemplateSpec{
+						Taints: []corev1.Taint{{Key: "dedicated", Value: "a", Effect: corev1.TaintEffectNoSchedule}},
+					},
+				},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{v1.NodePoolLabelKey: nodePool.Name, "team": "a"},
+				Annotations: map[string]string{"owner": "a"},
+			},
+			Spec: v1.NodeClaimSpec{
+				Taints: []corev1.Taint{{Key: "dedicated", Value: "a", Effect: corev1.TaintEffectNoSchedule}},
+			},
+		})
+		node.Spec.Taints = []corev1.Taint{
+			{Key: "dedicated", Value: "a", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "custom", Effect: corev1.TaintEffectNoSchedule},
+		}
+	})
+	It("should patch the labels and annotations onto the NodeClaim and Node when they're propagated in place", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace, Annotations: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.Labels = map[string]string{"team": "b", "cost-center": "1234"}
+		nodePool.Spec.Template.Annotations = map[string]string{"owner": "b"}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue("team", "b"))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue("owner", "b"))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("team", "b"))
+		Expect(node.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+		Expect(node.Annotations).To(HaveKeyWithValue("owner", "b"))
+	})
+	It("should only patch the node metadata onto the Node when it's propagated in place", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace, Annotations: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.NodeMetadata = v1.ObjectMeta{
+			Labels:      map[string]string{"node-only-label": "value"},
+			Annotations: map[string]string{"node-agent/config": "value"},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Labels).ToNot(HaveKey("node-only-label"))
+		Expect(nodeClaim.Annotations).ToNot(HaveKey("node-agent/config"))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("node-only-label", "value"))
+		Expect(node.Annotations).To(HaveKeyWithValue("node-agent/config", "value"))
+	})
+	It("should not remove labels that were removed from the template", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.Labels = map[string]string{"cost-center": "1234"}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("team", "a"))
+		Expect(node.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+	})
+	It("should only patch the classes of fields that are propagated in place", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Annotations: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.Labels = map[string]string{"team": "b"}
+		nodePool.Spec.Template.Annotations = map[string]string{"owner": "b"}
+		nodePool.Spec.Template.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("team", "a"))
+		Expect(node.Annotations).To(HaveKeyWithValue("owner", "b"))
+		Expect(node.Spec.Taints).To(ContainElement(corev1.Taint{Key: "dedicated", Value: "a", Effect: corev1.TaintEffectNoSchedule}))
+	})
+	It("should replace the taints that the Node was launched with when taints are propagated in place", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Taints: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{
+			{Key: "dedicated", Value: "b", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "gpu", Effect: corev1.TaintEffectNoExecute},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ConsistOf(
+			corev1.Taint{Key: "custom", Effect: corev1.TaintEffectNoSchedule},
+			corev1.Taint{Key: "dedicated", Value: "b", Effect: corev1.TaintEffectNoSchedule},
+			corev1.Taint{Key: "gpu", Effect: corev1.TaintEffectNoExecute},
+		))
+	})
+	It("should remove taints that were removed from the template when taints are propagated in place", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Taints: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.Spec.Taints = nil
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).To(ConsistOf(corev1.Taint{Key: "custom", Effect: corev1.TaintEffectNoSchedule}))
+	})
+	It("should not patch NodeClaims when nothing is propagated in place", func() {
+		nodePool.Spec.Template.Labels = map[string]string{"team": "b"}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue("team", "a"))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Labels).To(HaveKeyWithValue("team", "a"))
+	})
+	It("should patch the NodeClaim when its Node hasn't registered", func() {
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Labels: v1.PropagationPolicyInPlace}
+		nodePool.Spec.Template.Labels = map[string]string{"team": "b"}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, propagationController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue("team", "b"))
+	})
+})
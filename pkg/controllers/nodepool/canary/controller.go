@@ -0,0 +1,201 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+const canaryImage = "registry.k8s.io/pause:3.10"
+
+// Controller periodically creates a canary pod for each NodePool that can only schedule to the NodePool's nodes and
+// verifies that it's running within the canary timeout. This surfaces cloud provider, quota and image breakages that
+// prevent a NodePool from provisioning capacity before real workloads depend on it.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+// NewController is a constructor
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.canary")
+
+	if !nodepoolutils.IsManaged(nodePool, c.cloudProvider) || !nodePool.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	pod := &corev1.Pod{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: options.FromContext(ctx).CanaryNamespace, Name: podName(nodePool)}, pod); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, fmt.Errorf("getting canary pod, %w", err)
+		}
+		return c.create(ctx, nodePool)
+	}
+	timeout := options.FromContext(ctx).CanaryTimeout
+	elapsed := c.clock.Since(pod.CreationTimestamp.Time)
+	running := pod.Spec.NodeName != "" && pod.Status.Phase == corev1.PodRunning
+	if !running && elapsed < timeout {
+		// The pod watch triggers a reconcile once the canary is running, so we only need to check back at the timeout
+		return reconcile.Result{RequeueAfter: timeout - elapsed}, nil
+	}
+
+	stored := nodePool.DeepCopy()
+	if running {
+		CanaryDurationSeconds.Observe(elapsed.Seconds(), map[string]string{nodePoolLabel: nodePool.Name})
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeCanaryFailed, v1.ConditionReasonCanaryRunning, fmt.Sprintf("Canary pod was running after %s", elapsed.Truncate(time.Second)))
+	} else {
+		CanaryFailuresTotal.Inc(map[string]string{nodePoolLabel: nodePool.Name})
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeCanaryFailed, v1.ConditionReasonCanaryTimedOut, fmt.Sprintf("Canary pod wasn't running within %s", timeout))
+	}
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the status condition list
+		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); client.IgnoreNotFound(err) != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	if err := c.kubeClient.Delete(ctx, pod, client.GracePeriodSeconds(0)); client.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("deleting canary pod, %w", err)
+	}
+	// The time that the canary finished is stored on the NodePool so that the canary interval is kept across restarts
+	stored = nodePool.DeepCopy()
+	nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{v1.CanaryTimestampAnnotationKey: c.clock.Now().UTC().Format(time.RFC3339)})
+	if err := c.kubeClient.Patch(ctx, nodePool, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("patching nodepool, %w", err)
+	}
+	return reconcile.Result{RequeueAfter: options.FromContext(ctx).CanaryInterval}, nil
+}
+
+// create creates the NodePool's canary pod once the canary interval has passed since its last canary finished
+func (c *Controller) create(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	if lastRun, err := time.Parse(time.RFC3339, nodePool.Annotations[v1.CanaryTimestampAnnotationKey]); err == nil {
+		if remaining := options.FromContext(ctx).CanaryInterval - c.clock.Since(lastRun); remaining > 0 {
+			return reconcile.Result{RequeueAfter: remaining}, nil
+		}
+	}
+	nodeList := &corev1.NodeList{}
+	if err := c.kubeClient.List(ctx, nodeList, client.MatchingLabels{v1.NodePoolLabelKey: nodePool.Name}); err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing nodes, %w", err)
+	}
+	pod := canaryPod(nodePool, options.FromContext(ctx).CanaryNamespace, lo.Map(nodeList.Items, func(n corev1.Node, _ int) string {
+		return n.Labels[corev1.LabelHostname]
+	}))
+	// The canary pod is garbage collected with its NodePool
+	if err := controllerutil.SetOwnerReference(nodePool, pod, c.kubeClient.Scheme()); err != nil {
+		return reconcile.Result{}, fmt.Errorf("setting canary pod owner, %w", err)
+	}
+	if err := c.kubeClient.Create(ctx, pod); client.IgnoreAlreadyExists(err) != nil {
+		return reconcile.Result{}, fmt.Errorf("creating canary pod, %w", err)
+	}
+	return reconcile.Result{RequeueAfter: options.FromContext(ctx).CanaryTimeout}, nil
+}
+
+func podName(nodePool *v1.NodePool) string {
+	return fmt.Sprintf("karpenter-canary-%s", nodePool.Name)
+}
+
+// canaryPod returns a pod that can only schedule to nodes that are launched from the NodePool. It requires the
+// NodePool's requirements and tolerates the NodePool's taints, so it's compatible with any node that the NodePool launches.
+// It can't schedule to the NodePool's existing nodes, so the NodePool has to launch a node for it.
+func canaryPod(nodePool *v1.NodePool, namespace string, existingHostnames []string) *corev1.Pod {
+	requirements := append(lo.Map(nodePool.Spec.Template.Spec.Requirements, func(r v1.NodeSelectorRequirementWithMinValues, _ int) corev1.NodeSelectorRequirement {
+		return r.NodeSelectorRequirement
+	}), corev1.NodeSelectorRequirement{Key: v1.NodePoolLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{nodePool.Name}})
+	if hostnames := lo.Compact(existingHostnames); len(hostnames) > 0 {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpNotIn, Values: hostnames})
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName(nodePool),
+			Namespace: namespace,
+			Labels:    map[string]string{v1.CanaryLabelKey: nodePool.Name},
+		},
+		Spec: corev1.PodSpec{
+			Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: requirements}},
+				},
+			}},
+			Tolerations: lo.Map(nodePool.Spec.Template.Spec.Taints, func(t corev1.Taint, _ int) corev1.Toleration {
+				return corev1.Toleration{Key: t.Key, Operator: corev1.TolerationOpEqual, Value: t.Value, Effect: t.Effect}
+			}),
+			TerminationGracePeriodSeconds: lo.ToPtr[int64](0),
+			Containers: []corev1.Container{{
+				Name:  "canary",
+				Image: canaryImage,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10m"),
+						corev1.ResourceMemory: resource.MustParse("16Mi"),
+					},
+				},
+			}},
+		},
+	}
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.canary").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: o.GetLabels()[v1.CanaryLabelKey]}}}
+		}), builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := o.GetLabels()[v1.CanaryLabelKey]
+			return ok
+		}))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.canary", 10)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
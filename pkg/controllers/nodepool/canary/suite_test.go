@@ -0,0 +1,185 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/canary"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *canary.Controller
+	ctx           context.Context
+	env           *test.Environment
+	fakeClock     *clock.FakeClock
+	cloudProvider *fake.CloudProvider
+	nodePool      *v1.NodePool
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Canary")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Canary", func() {
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{CanaryInterval: lo.ToPtr(time.Hour), CanaryTimeout: lo.ToPtr(15 * time.Minute)}))
+		fakeClock.SetTime(time.Now())
+		controller = canary.NewController(fakeClock, env.Client, cloudProvider)
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					Spec: v1.NodeClaimTemplateSpec{
+						Requirements: []v1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeOnDemand}}},
+						},
+						Taints: []corev1.Taint{{Key: "dedicated", Value: "canary", Effect: corev1.TaintEffectNoSchedule}},
+					},
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool)
+	})
+
+	// expectCanaryPod returns the NodePool's canary pod
+	expectCanaryPod := func() *corev1.Pod {
+		GinkgoHelper()
+		return ExpectExists(ctx, env.Client, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "karpenter-canary-" + nodePool.Name}})
+	}
+
+	It("should create a canary pod that only schedules to the nodepool", func() {
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		pod := expectCanaryPod()
+		Expect(pod.Labels).To(HaveKeyWithValue(v1.CanaryLabelKey, nodePool.Name))
+		Expect(pod.OwnerReferences).To(HaveLen(1))
+		Expect(pod.OwnerReferences[0].UID).To(Equal(nodePool.UID))
+		Expect(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions).To(ConsistOf(
+			corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeOnDemand}},
+			corev1.NodeSelectorRequirement{Key: v1.NodePoolLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{nodePool.Name}},
+		))
+		Expect(pod.Spec.Tolerations).To(ContainElement(corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "canary", Effect: corev1.TaintEffectNoSchedule}))
+	})
+	It("should create a canary pod that can't schedule to the nodepool's existing nodes", func() {
+		node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+		other := test.Node()
+		ExpectApplied(ctx, env.Client, node, other)
+
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		pod := expectCanaryPod()
+		Expect(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions).To(ContainElement(
+			corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpNotIn, Values: []string{node.Labels[corev1.LabelHostname]}},
+		))
+	})
+	It("should mark the canary as succeeded and delete the pod once it's running", func() {
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		pod := expectCanaryPod()
+		node := test.Node()
+		ExpectApplied(ctx, env.Client, node)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		pod = expectCanaryPod()
+		pod.Status.Phase = corev1.PodRunning
+		ExpectApplied(ctx, env.Client, pod)
+
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeCanaryFailed).IsFalse()).To(BeTrue())
+		ExpectNotFound(ctx, env.Client, pod)
+		ExpectMetricHistogramSampleCountValue("karpenter_nodepools_canary_duration_seconds", 1, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should wait for the canary pod to run until the timeout", func() {
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		fakeClock.Step(10 * time.Minute)
+		result := ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 5*time.Minute))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeCanaryFailed)).To(BeNil())
+		expectCanaryPod()
+	})
+	It("should mark the canary as failed and delete the pod when it isn't running within the timeout", func() {
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		pod := expectCanaryPod()
+		fakeClock.Step(16 * time.Minute)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeCanaryFailed).IsTrue()).To(BeTrue())
+		ExpectNotFound(ctx, env.Client, pod)
+		ExpectMetricCounterValue(canary.CanaryFailuresTotal, 1, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should only create the next canary pod once the interval has passed", func() {
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		pod := expectCanaryPod()
+		fakeClock.Step(16 * time.Minute)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, pod)
+
+		fakeClock.Step(30 * time.Minute)
+		result := ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Minute, time.Second))
+		ExpectNotFound(ctx, env.Client, pod)
+
+		fakeClock.Step(30 * time.Minute)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		expectCanaryPod()
+	})
+	It("should keep the canary interval across restarts", func() {
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		pod := expectCanaryPod()
+		fakeClock.Step(16 * time.Minute)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, pod)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1.CanaryTimestampAnnotationKey, fakeClock.Now().UTC().Format(time.RFC3339)))
+
+		controller = canary.NewController(fakeClock, env.Client, cloudProvider)
+		fakeClock.Step(30 * time.Minute)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, pod)
+	})
+})
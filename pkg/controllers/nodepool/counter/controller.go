@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
@@ -81,6 +82,10 @@ func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reco
 	stored := nodePool.DeepCopy()
 	// Determine resource usage and update nodepool.status.resources
 	nodePool.Status.Resources = c.resourceCountsFor(v1.NodePoolLabelKey, nodePool.Name)
+	nodePool.Status.ResourcesByCapacityType = c.capacityTypeResourceCountsFor(v1.NodePoolLabelKey, nodePool.Name)
+	nodePool.Status.RemainingLimits = nodePool.Spec.Limits.Remaining(nodePool.Status.Resources, nodePool.Status.ResourcesByCapacityType)
+	nodePool.Status.NodeClaims = c.nodeClaimPhasesFor(v1.NodePoolLabelKey, nodePool.Name)
+	nodePool.Status.NodesByGeneration = c.generationCountsFor(v1.NodePoolLabelKey, nodePool.Name)
 	if !equality.Semantic.DeepEqual(stored, nodePool) {
 		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFrom(stored)); err != nil {
 			return reconcile.Result{}, client.IgnoreNotFound(err)
@@ -111,13 +116,79 @@ func (c *Controller) resourceCountsFor(ownerLabel string, ownerName string) core
 	return res
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+// capacityTypeResourceCountsFor groups the resources provisioned by the nodepool by capacity type so that they can be
+// compared against the limits that are scoped to a capacity type
+func (c *Controller) capacityTypeResourceCountsFor(ownerLabel string, ownerName string) map[string]corev1.ResourceList {
+	res := map[string]corev1.ResourceList{}
+	c.cluster.ForEachNode(func(n *state.StateNode) bool {
+		if n.MarkedForDeletion() || n.Labels()[ownerLabel] != ownerName {
+			return true
+		}
+		capacityType, ok := n.Labels()[v1.CapacityTypeLabelKey]
+		if !ok {
+			return true
+		}
+		res[capacityType] = resources.MergeInto(res[capacityType], n.Capacity())
+		return true
+	})
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+// nodeClaimPhasesFor groups the nodes by their lifecycle phase. The launching, registered and initialized phases
+// add up to the resources that are counted against the NodePool's limits.
+func (c *Controller) nodeClaimPhasesFor(ownerLabel string, ownerName string) *v1.NodeClaimPhases {
+	phases := &v1.NodeClaimPhases{}
+	c.cluster.ForEachNode(func(n *state.StateNode) bool {
+		if n.Labels()[ownerLabel] != ownerName {
+			return true
+		}
+		var phase *v1.NodeClaimPhase
+		switch {
+		case n.MarkedForDeletion():
+			phase = &phases.Deleting
+		case n.Initialized():
+			phase = &phases.Initialized
+		case n.Registered():
+			phase = &phases.Registered
+		default:
+			phase = &phases.Launching
+		}
+		phase.Count++
+		phase.Resources = resources.MergeInto(phase.Resources, n.Capacity())
+		return true
+	})
+	return phases
+}
+
+// generationCountsFor groups the nodes by the NodePool generation that they were launched from so that rollouts of
+// NodePool changes can be tracked
+func (c *Controller) generationCountsFor(ownerLabel string, ownerName string) map[string]int64 {
+	res := map[string]int64{}
+	c.cluster.ForEachNode(func(n *state.StateNode) bool {
+		if n.MarkedForDeletion() || n.Labels()[ownerLabel] != ownerName {
+			return true
+		}
+		if generation, ok := n.Labels()[v1.NodePoolGenerationLabelKey]; ok {
+			res[generation]++
+		}
+		return true
+	})
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("nodepool.counter").
 		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
 		Watches(&v1.NodeClaim{}, nodepoolutils.NodeClaimEventHandler()).
 		Watches(&corev1.Node{}, nodepoolutils.NodeEventHandler()).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.counter", 10)}).
 		Complete(reconcile.AsReconciler(m.GetClient(), c))
 
 }
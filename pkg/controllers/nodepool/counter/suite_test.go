@@ -205,6 +205,98 @@ var _ = Describe("Counter", func() {
 		expected[corev1.ResourceName("nodes")] = resource.MustParse("1")
 		Expect(nodePool.Status.Resources).To(BeComparableTo(expected))
 	})
+	It("should group the counter by capacity type", func() {
+		nodeClaim.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeOnDemand
+		node.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeOnDemand
+		nodeClaim2.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeSpot
+		node2.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeSpot
+		ExpectApplied(ctx, env.Client, node, nodeClaim, node2, nodeClaim2)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+
+		Expect(nodePool.Status.ResourcesByCapacityType).To(HaveLen(2))
+		Expect(nodePool.Status.ResourcesByCapacityType[v1.CapacityTypeOnDemand]).To(BeComparableTo(node.Status.Capacity))
+		Expect(nodePool.Status.ResourcesByCapacityType[v1.CapacityTypeSpot]).To(BeComparableTo(node2.Status.Capacity))
+	})
+	It("should set the remaining limits from the provisioned resources", func() {
+		nodePool.Spec.Limits = v1.Limits{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+			"spot/cpu":            resource.MustParse("2"),
+			"nodes":               resource.MustParse("5"),
+		}
+		nodeClaim.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeOnDemand
+		node.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeOnDemand
+		nodeClaim2.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeSpot
+		node2.Labels[v1.CapacityTypeLabelKey] = v1.CapacityTypeSpot
+		ExpectApplied(ctx, env.Client, nodePool, node, nodeClaim, node2, nodeClaim2)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+
+		Expect(nodePool.Status.RemainingLimits).To(BeComparableTo(corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("400m"),
+			corev1.ResourceMemory: resource.MustParse("-2Gi"),
+			"spot/cpu":            resource.MustParse("1500m"),
+			"nodes":               resource.MustParse("3"),
+		}))
+	})
+	It("should not set the remaining limits when the NodePool has no limits", func() {
+		nodePool.Spec.Limits = nil
+		ExpectApplied(ctx, env.Client, nodePool, node, nodeClaim)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.RemainingLimits).To(BeNil())
+	})
+	It("should group the nodes by the NodePool generation that they were launched from", func() {
+		nodeClaim.Labels[v1.NodePoolGenerationLabelKey] = "1"
+		node.Labels[v1.NodePoolGenerationLabelKey] = "1"
+		nodeClaim2.Labels[v1.NodePoolGenerationLabelKey] = "2"
+		node2.Labels[v1.NodePoolGenerationLabelKey] = "2"
+		ExpectApplied(ctx, env.Client, node, nodeClaim, node2, nodeClaim2)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.NodesByGeneration).To(Equal(map[string]int64{"1": 1, "2": 1}))
+
+		// Nodes that are being deleted aren't counted
+		cluster.MarkForDeletion(nodeClaim.Status.ProviderID)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.NodesByGeneration).To(Equal(map[string]int64{"2": 1}))
+	})
+	It("should summarize nodeClaims by lifecycle phase", func() {
+		ExpectApplied(ctx, env.Client, node, nodeClaim, nodeClaim2)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		// nodeClaim2 has launched but its node hasn't registered
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim2))
+
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+
+		Expect(nodePool.Status.NodeClaims).ToNot(BeNil())
+		Expect(nodePool.Status.NodeClaims.Initialized.Count).To(BeNumerically("==", 1))
+		Expect(nodePool.Status.NodeClaims.Initialized.Resources).To(BeComparableTo(node.Status.Capacity))
+		Expect(nodePool.Status.NodeClaims.Launching.Count).To(BeNumerically("==", 1))
+		Expect(nodePool.Status.NodeClaims.Launching.Resources).To(BeComparableTo(nodeClaim2.Status.Capacity))
+		Expect(nodePool.Status.NodeClaims.Registered.Count).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.NodeClaims.Deleting.Count).To(BeNumerically("==", 0))
+
+		// Mark the initialized node for deletion, which moves it to the deleting phase
+		cluster.MarkForDeletion(nodeClaim.Status.ProviderID)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+
+		Expect(nodePool.Status.NodeClaims.Initialized.Count).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.NodeClaims.Deleting.Count).To(BeNumerically("==", 1))
+		Expect(nodePool.Status.NodeClaims.Deleting.Resources).To(BeComparableTo(node.Status.Capacity))
+	})
 	It("should decrease the counter when an existing node is deleted", func() {
 		ExpectApplied(ctx, env.Client, node, nodeClaim, node2, nodeClaim2)
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeController, nodeClaimController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
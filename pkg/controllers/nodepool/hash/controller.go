@@ -32,6 +32,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 )
@@ -65,8 +66,9 @@ func (c *Controller) Reconcile(ctx context.Context, np *v1.NodePool) (reconcile.
 		}
 	}
 	np.Annotations = lo.Assign(np.Annotations, map[string]string{
-		v1.NodePoolHashAnnotationKey:        np.Hash(),
-		v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+		v1.NodePoolHashAnnotationKey:           np.Hash(),
+		v1.NodePoolHashVersionAnnotationKey:    v1.NodePoolHashVersion,
+		v1.NodePoolTemplateHashesAnnotationKey: np.TemplateHashes(),
 	})
 
 	if !equality.Semantic.DeepEqual(stored, np) {
@@ -77,11 +79,11 @@ func (c *Controller) Reconcile(ctx context.Context, np *v1.NodePool) (reconcile.
 	return reconcile.Result{}, nil
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("nodepool.hash").
 		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.hash", 10)}).
 		Complete(reconcile.AsReconciler(m.GetClient(), c))
 }
 
@@ -108,7 +110,8 @@ func (c *Controller) updateNodeClaimHash(ctx context.Context, np *v1.NodePool) e
 			// Since the hashing mechanism has changed we will not be able to determine if the drifted status of the NodeClaim has changed
 			if nc.StatusConditions().Get(v1.ConditionTypeDrifted) == nil {
 				nc.Annotations = lo.Assign(nc.Annotations, map[string]string{
-					v1.NodePoolHashAnnotationKey: np.Hash(),
+					v1.NodePoolHashAnnotationKey:           np.Hash(),
+					v1.NodePoolTemplateHashesAnnotationKey: np.TemplateHashes(),
 				})
 			}
 
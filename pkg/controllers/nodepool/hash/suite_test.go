@@ -250,4 +250,40 @@ var _ = Describe("Static Drift Hash", func() {
 		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, "123456"))
 		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodePoolHashVersionAnnotationKey, v1.NodePoolHashVersion))
 	})
+	It("should set the template hashes on the nodepool", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1.NodePoolTemplateHashesAnnotationKey, nodePool.TemplateHashes()))
+		templateHashes := nodePool.TemplateHashes()
+
+		nodePool.Spec.Template.Labels = map[string]string{"keyLabeltest": "valueLabeltest"}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Annotations[v1.NodePoolTemplateHashesAnnotationKey]).ToNot(Equal(templateHashes))
+	})
+	It("should not update the nodepool hash on nodeclaims when the template propagation changes", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+				Annotations: map[string]string{
+					v1.NodePoolHashAnnotationKey:        "123456",
+					v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		nodePool.Spec.TemplatePropagation = v1.TemplatePropagation{Annotations: v1.PropagationPolicyInPlace, Taints: v1.PropagationPolicyInPlace}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1.NodePoolHashVersionAnnotationKey, v1.NodePoolHashVersion))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, "123456"))
+	})
 })
@@ -0,0 +1,187 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package launchslo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// evaluationWindow is how far back NodeClaim launches are considered when evaluating the launch SLO so that
+// regressions surface quickly and the NodePool recovers once launches are healthy again
+const evaluationWindow = time.Hour
+
+// Controller tracks the percentage of a NodePool's NodeClaims that initialize within the launch SLO target and
+// marks the NodePool as LaunchDegraded when the error budget is exhausted
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+
+	mu       sync.Mutex
+	outcomes map[string]map[types.UID]outcome // NodePool name -> NodeClaim UID -> launch outcome
+}
+
+// outcome records whether a NodeClaim met the launch SLO. Outcomes are kept for the evaluation window even after
+// their NodeClaims are deleted, since the NodeClaims that fail to launch or register are deleted by liveness and
+// would otherwise never count against the NodePool.
+type outcome struct {
+	created time.Time
+	met     bool
+}
+
+// NewController is a constructor
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		outcomes:      map[string]map[types.UID]outcome{},
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.launchslo")
+
+	nodePool := &v1.NodePool{}
+	if err := c.kubeClient.Get(ctx, req.NamespacedName, nodePool); err != nil {
+		if errors.IsNotFound(err) {
+			deleteMetrics(req.Name)
+			c.deleteOutcomes(req.Name)
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if !nodepoolutils.IsManaged(nodePool, c.cloudProvider) {
+		return reconcile.Result{}, nil
+	}
+	stored := nodePool.DeepCopy()
+	target := options.FromContext(ctx).NodeLaunchSLOTarget
+	if target == 0 {
+		deleteMetrics(nodePool.Name)
+		c.deleteOutcomes(nodePool.Name)
+		_ = nodePool.StatusConditions().Clear(v1.ConditionTypeLaunchDegraded)
+	} else {
+		nodeClaims, err := nodeclaimutils.ListManaged(ctx, c.kubeClient, c.cloudProvider, nodeclaimutils.ForNodePool(nodePool.Name))
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
+		}
+		c.evaluate(ctx, nodePool, nodeClaims, target)
+	}
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the status condition list
+		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); client.IgnoreNotFound(err) != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	// NodeClaims breach the target and age out of the evaluation window without any events, so we re-evaluate periodically
+	return reconcile.Result{RequeueAfter: time.Minute}, nil
+}
+
+// evaluate records the outcome of each NodeClaim that was launched within the evaluation window and has either
+// initialized, exceeded the target or been deleted before initializing, and then counts the outcomes in the window.
+// NodeClaims that are still within the target haven't met or missed the SLO yet so they're ignored.
+func (c *Controller) evaluate(ctx context.Context, nodePool *v1.NodePool, nodeClaims []*v1.NodeClaim, target time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	outcomes, ok := c.outcomes[nodePool.Name]
+	if !ok {
+		outcomes = map[types.UID]outcome{}
+		c.outcomes[nodePool.Name] = outcomes
+	}
+	for _, nodeClaim := range nodeClaims {
+		if _, ok := outcomes[nodeClaim.UID]; ok || c.clock.Since(nodeClaim.CreationTimestamp.Time) > evaluationWindow {
+			continue
+		}
+		if initialized := nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized); initialized.IsTrue() {
+			outcomes[nodeClaim.UID] = outcome{created: nodeClaim.CreationTimestamp.Time, met: initialized.LastTransitionTime.Sub(nodeClaim.CreationTimestamp.Time) <= target}
+			continue
+		}
+		if c.clock.Since(nodeClaim.CreationTimestamp.Time) > target || !nodeClaim.DeletionTimestamp.IsZero() {
+			outcomes[nodeClaim.UID] = outcome{created: nodeClaim.CreationTimestamp.Time}
+		}
+	}
+	var met, missed int
+	for uid, o := range outcomes {
+		if c.clock.Since(o.created) > evaluationWindow {
+			delete(outcomes, uid)
+			continue
+		}
+		if o.met {
+			met++
+		} else {
+			missed++
+		}
+	}
+	objective := options.FromContext(ctx).NodeLaunchSLOObjective
+	attainment := lo.Ternary(met+missed == 0, 100, float64(met)/float64(met+missed)*100)
+	// the burn rate is the fraction of the error budget that has been consumed, where a burn rate above one means
+	// that the NodePool has missed its objective
+	burnRate := (100 - attainment) / float64(100-objective)
+
+	LaunchSLOAttainment.Set(attainment, map[string]string{nodePoolLabel: nodePool.Name})
+	LaunchSLOBurnRate.Set(burnRate, map[string]string{nodePoolLabel: nodePool.Name})
+	if burnRate > 1 {
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeLaunchDegraded, v1.ConditionReasonErrorBudgetExhausted,
+			fmt.Sprintf("%d of %d NodeClaims launched in the last %s didn't initialize within %s, breaching the %d%% objective", missed, met+missed, evaluationWindow, target, objective))
+	} else {
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeLaunchDegraded, v1.ConditionReasonWithinErrorBudget,
+			fmt.Sprintf("%d of %d NodeClaims launched in the last %s didn't initialize within %s", missed, met+missed, evaluationWindow, target))
+	}
+}
+
+func (c *Controller) deleteOutcomes(nodePoolName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.outcomes, nodePoolName)
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.launchslo").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Watches(&v1.NodeClaim{}, nodepoolutils.NodeClaimEventHandler()).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.launchslo", 10)}).
+		Complete(c)
+}
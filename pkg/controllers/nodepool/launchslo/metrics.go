@@ -0,0 +1,55 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package launchslo
+
+import (
+	opmetrics "github.com/awslabs/operatorpkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/karpenter/pkg/metrics"
+)
+
+const nodePoolLabel = "nodepool"
+
+var (
+	LaunchSLOAttainment = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodePoolSubsystem,
+			Name:      "launch_slo_attainment_percent",
+			Help:      "The percentage of NodeClaims launched by the nodepool in the last hour that initialized within the launch SLO target. Labeled by nodepool name.",
+		},
+		[]string{nodePoolLabel},
+	)
+	LaunchSLOBurnRate = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodePoolSubsystem,
+			Name:      "launch_slo_burn_rate",
+			Help:      "The fraction of the nodepool's launch SLO error budget that has been consumed in the last hour. A value above one means that the nodepool is LaunchDegraded. Labeled by nodepool name.",
+		},
+		[]string{nodePoolLabel},
+	)
+)
+
+func deleteMetrics(nodePoolName string) {
+	LaunchSLOAttainment.Delete(map[string]string{nodePoolLabel: nodePoolName})
+	LaunchSLOBurnRate.Delete(map[string]string{nodePoolLabel: nodePoolName})
+}
@@ -0,0 +1,198 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package launchslo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/awslabs/operatorpkg/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/launchslo"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *launchslo.Controller
+	ctx           context.Context
+	env           *test.Environment
+	fakeClock     *clock.FakeClock
+	cloudProvider *fake.CloudProvider
+	nodePool      *v1.NodePool
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LaunchSLO")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	controller = launchslo.NewController(fakeClock, env.Client, cloudProvider)
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("LaunchSLO", func() {
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeLaunchSLOTarget: lo.ToPtr(15 * time.Minute), NodeLaunchSLOObjective: lo.ToPtr(75)}))
+		fakeClock.SetTime(time.Now())
+		nodePool = test.NodePool()
+	})
+
+	// launchNodeClaim creates a NodeClaim for the NodePool and, if initializedAfter is set, marks it as initialized
+	// that long after it was created
+	launchNodeClaim := func(initializedAfter *time.Duration) *v1.NodeClaim {
+		GinkgoHelper()
+		nodeClaim := test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		if initializedAfter != nil {
+			nodeClaim.StatusConditions().Set(status.Condition{
+				Type:               v1.ConditionTypeInitialized,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1.ConditionTypeInitialized,
+				LastTransitionTime: metav1.NewTime(nodeClaim.CreationTimestamp.Add(*initializedAfter)),
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim)
+		}
+		return nodeClaim
+	}
+
+	It("should not be degraded when there are no nodeclaims", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsFalse()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 100, map[string]string{"nodepool": nodePool.Name})
+		ExpectMetricGaugeValue(launchslo.LaunchSLOBurnRate, 0, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should not be degraded when nodeclaims initialize within the target", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		for range 4 {
+			launchNodeClaim(lo.ToPtr(5 * time.Minute))
+		}
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsFalse()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 100, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should not be degraded when the missed nodeclaims are within the error budget", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		for range 3 {
+			launchNodeClaim(lo.ToPtr(5 * time.Minute))
+		}
+		launchNodeClaim(lo.ToPtr(20 * time.Minute))
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsFalse()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 75, map[string]string{"nodepool": nodePool.Name})
+		ExpectMetricGaugeValue(launchslo.LaunchSLOBurnRate, 1, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should be degraded when nodeclaims initialize after the target", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		launchNodeClaim(lo.ToPtr(5 * time.Minute))
+		launchNodeClaim(lo.ToPtr(20 * time.Minute))
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 50, map[string]string{"nodepool": nodePool.Name})
+		ExpectMetricGaugeValue(launchslo.LaunchSLOBurnRate, 2, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should be degraded when nodeclaims haven't initialized after the target", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		launchNodeClaim(nil)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		// the nodeclaim is still within the target, so it hasn't missed the SLO yet
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsFalse()).To(BeTrue())
+
+		fakeClock.Step(20 * time.Minute)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 0, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should keep counting missed nodeclaims after they're deleted", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		nodeClaim := launchNodeClaim(nil)
+		fakeClock.Step(20 * time.Minute)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+
+		// liveness deletes the nodeclaim since it never registered
+		ExpectDeleted(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 0, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should count nodeclaims that are deleted before they initialize as missed", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		nodeClaim := launchNodeClaim(nil)
+		ExpectDeletionTimestampSet(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue()).To(BeTrue())
+		ExpectMetricGaugeValue(launchslo.LaunchSLOAttainment, 0, map[string]string{"nodepool": nodePool.Name})
+	})
+	It("should recover once the missed nodeclaims age out of the evaluation window", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		launchNodeClaim(lo.ToPtr(20 * time.Minute))
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue()).To(BeTrue())
+
+		fakeClock.Step(2 * time.Hour)
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsFalse()).To(BeTrue())
+	})
+	It("should clear the condition when the launch SLO is disabled", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		launchNodeClaim(lo.ToPtr(20 * time.Minute))
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue()).To(BeTrue())
+
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeLaunchSLOTarget: lo.ToPtr(time.Duration(0))}))
+		ExpectReconcileSucceeded(ctx, controller, client.ObjectKeyFromObject(nodePool))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded)).To(BeNil())
+	})
+})
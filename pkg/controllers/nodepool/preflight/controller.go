@@ -0,0 +1,215 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	provisioningscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// maxReportedPods is the number of incompatible pods that are listed in the condition message
+const maxReportedPods = 5
+
+// Controller checks each generation of a NodePool against the pods that are running on the NodePool's nodes and the
+// pending pods that were nominated to its NodeClaims, so that changes to the template that would leave workloads
+// unschedulable (e.g. adding a taint or narrowing the requirements) are reported before drift starts replacing the nodes.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.preflight")
+
+	// The check only runs once per generation of the NodePool since it simulates the template that drift replaces
+	// nodes with, rather than tracking the pods as they come and go
+	if cond := nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible); cond != nil && cond.ObservedGeneration == nodePool.Generation {
+		return reconcile.Result{}, nil
+	}
+	stored := nodePool.DeepCopy()
+	nodeList := &corev1.NodeList{}
+	if err := c.kubeClient.List(ctx, nodeList, client.MatchingLabels{v1.NodePoolLabelKey: nodePool.Name}); err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing nodes, %w", err)
+	}
+	pods, err := nodeutils.GetReschedulablePods(ctx, c.kubeClient, lo.ToSlicePtr(nodeList.Items)...)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	pendingPods, err := c.nominatedPods(ctx, nodePool)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	daemonSetPods, err := simulation.DaemonSetPods(ctx, c.kubeClient, c.cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	instanceTypes, err := c.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("getting instance types, %w", err)
+	}
+	incompatible := Check(nodePool, instanceTypes, append(daemonSetPods, simulation.OverheadPods(ctx)...), append(pods, pendingPods...)...)
+	if len(incompatible) == 0 {
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeWorkloadsCompatible)
+	} else {
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeWorkloadsCompatible, v1.ConditionReasonIncompatibleWorkloads, message(incompatible))
+	}
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the status condition list
+		if err = c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); client.IgnoreNotFound(err) != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// nominatedPods returns the pending pods that the provisioner nominated to the NodePool's NodeClaims, which are waiting
+// for the NodeClaims' nodes to become ready
+func (c *Controller) nominatedPods(ctx context.Context, nodePool *v1.NodePool) ([]*corev1.Pod, error) {
+	nodeClaimList := &v1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList, nodeclaimutils.ForNodePool(nodePool.Name)); err != nil {
+		return nil, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	var pods []*corev1.Pod
+	for _, nodeClaim := range nodeClaimList.Items {
+		for _, key := range c.cluster.NominatedPods(nodeClaim.Name) {
+			pod := &corev1.Pod{}
+			if err := c.kubeClient.Get(ctx, key, pod); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("getting pod, %w", err)
+			}
+			// Pods that bound to a node are checked with the pods on the NodePool's nodes
+			if podutils.IsScheduled(pod) || !podutils.IsActive(pod) {
+				continue
+			}
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.preflight").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.preflight", 10)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
+
+// Check returns the reason that each of the pods couldn't schedule to a node launched from the NodePool's template,
+// alongside the daemon pods that would schedule to the node. Pods are checked independently of each other, so this
+// doesn't account for pods that could only schedule by launching more nodes than the NodePool's limits allow.
+func Check(nodePool *v1.NodePool, instanceTypes []*cloudprovider.InstanceType, daemonPods []*corev1.Pod, pods ...*corev1.Pod) map[*corev1.Pod]error {
+	nodeClaimTemplate := provisioningscheduling.NewNodeClaimTemplate(nodePool)
+	daemonOverhead := provisioningscheduling.DaemonOverhead(nodeClaimTemplate, daemonPods...)
+	incompatible := map[*corev1.Pod]error{}
+	for _, pod := range pods {
+		if err := compatible(nodeClaimTemplate, instanceTypes, daemonOverhead, pod); err != nil {
+			incompatible[pod] = err
+		}
+	}
+	return incompatible
+}
+
+func compatible(nodeClaimTemplate *provisioningscheduling.NodeClaimTemplate, instanceTypes []*cloudprovider.InstanceType, daemonOverhead corev1.ResourceList, pod *corev1.Pod) error {
+	if err := scheduling.Taints(nodeClaimTemplate.Spec.Taints).Tolerates(pod); err != nil {
+		return err
+	}
+	requests := resources.Merge(daemonOverhead, resources.RequestsForPods(pod))
+	// Preferred node affinities are relaxed by the scheduler, so only the required terms can leave the pod unschedulable.
+	// The required terms are ORed, so the pod can schedule if any of them is satisfied.
+	var errs error
+	for _, podRequirements := range scheduling.NewStrictPodRequirementsPerTerm(pod) {
+		err := compatibleTerm(nodeClaimTemplate, instanceTypes, requests, podRequirements)
+		if err == nil {
+			return nil
+		}
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
+
+func compatibleTerm(nodeClaimTemplate *provisioningscheduling.NodeClaimTemplate, instanceTypes []*cloudprovider.InstanceType, requests corev1.ResourceList, podRequirements scheduling.Requirements) error {
+	requirements := scheduling.NewRequirements(nodeClaimTemplate.Requirements.Values()...)
+	if err := requirements.Compatible(podRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
+		return fmt.Errorf("incompatible requirements, %w", err)
+	}
+	requirements.Add(podRequirements.Values()...)
+	if !lo.ContainsBy(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+		return it.Requirements.Intersects(requirements) == nil && it.Offerings.Available().HasCompatible(requirements) && resources.Fits(requests, it.Allocatable())
+	}) {
+		return fmt.Errorf("no instance type satisfied resources %s (including daemonset overhead) and requirements %s", resources.String(requests), requirements)
+	}
+	return nil
+}
+
+// message lists the incompatible pods in a stable order, truncated to keep the condition readable
+func message(incompatible map[*corev1.Pod]error) string {
+	reasons := lo.MapToSlice(incompatible, func(p *corev1.Pod, err error) string {
+		return fmt.Sprintf("pod %s: %s", klog.KObj(p), err)
+	})
+	slices.Sort(reasons)
+	if len(reasons) > maxReportedPods {
+		reasons = append(reasons[:maxReportedPods], fmt.Sprintf("and %d other pod(s)", len(reasons)-maxReportedPods))
+	}
+	return fmt.Sprintf("%d pod(s) on or nominated to the NodePool's nodes couldn't schedule to replacement nodes; %s", len(incompatible), strings.Join(reasons, "; "))
+}
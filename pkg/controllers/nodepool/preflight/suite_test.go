@@ -0,0 +1,228 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/preflight"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *preflight.Controller
+	ctx           context.Context
+	env           *test.Environment
+	cloudProvider *fake.CloudProvider
+	cluster       *state.Cluster
+	nodePool      *v1.NodePool
+	node          *corev1.Node
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Preflight")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cluster = state.NewCluster(clock.NewFakeClock(time.Now()), env.Client, cloudProvider)
+	controller = preflight.NewController(env.Client, cloudProvider, cluster)
+})
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+	cluster.Reset()
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Preflight", func() {
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		node = test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+	})
+	It("should mark workloads as compatible when there are no pods on the NodePool's nodes", func() {
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+	})
+	It("should mark workloads as compatible when the pods can schedule to replacement nodes", func() {
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-1"}})
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+	})
+	It("should mark workloads as incompatible when the pods don't tolerate a taint on the template", func() {
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "example.com/dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		cond := nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible)
+		Expect(cond.IsFalse()).To(BeTrue())
+		Expect(cond.Reason).To(Equal("IncompatibleWorkloads"))
+		Expect(cond.Message).To(ContainSubstring(pod.Name))
+	})
+	It("should mark workloads as incompatible when the pods' required affinity doesn't match the template requirements", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{{
+			NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}},
+		}}
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-1"}})
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		cond := nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible)
+		Expect(cond.IsFalse()).To(BeTrue())
+		Expect(cond.Message).To(ContainSubstring(pod.Name))
+	})
+	It("should mark workloads as incompatible when no instance type fits the pods", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("10000"),
+		}}})
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsFalse()).To(BeTrue())
+	})
+	It("should mark workloads as compatible when the pods satisfy any of their required node affinity terms", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{{
+			NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}},
+		}}
+		pod := test.Pod()
+		pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}},
+			},
+		}}}
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+	})
+	It("should mark workloads as incompatible when the pods don't fit alongside the daemonset overhead", func() {
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{fake.NewInstanceType(fake.InstanceTypeOptions{
+			Name: "test-instance-type",
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("4"),
+				corev1.ResourcePods: resource.MustParse("10"),
+			},
+		})}
+		daemonSet := test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("3"),
+		}}}})
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("2"),
+		}}})
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(ExpectExists(ctx, env.Client, nodePool).StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+
+		// Adding the DaemonSet and updating the NodePool creates a new generation that's checked with the overhead
+		ExpectApplied(ctx, env.Client, daemonSet)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		nodePool.Spec.Template.Labels = map[string]string{"test": "test"}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		cond := ExpectExists(ctx, env.Client, nodePool).StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible)
+		Expect(cond.IsFalse()).To(BeTrue())
+		Expect(cond.Message).To(ContainSubstring(pod.Name))
+	})
+	It("should mark workloads as incompatible when pending pods nominated to the NodePool's nodeclaims can't schedule", func() {
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "example.com/dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, pod)
+		cluster.NominatePodsToNodeClaim(nodeClaim.Name, pod)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		cond := ExpectExists(ctx, env.Client, nodePool).StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible)
+		Expect(cond.IsFalse()).To(BeTrue())
+		Expect(cond.Message).To(ContainSubstring(pod.Name))
+	})
+	It("should ignore pods on nodes that belong to other NodePools", func() {
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "example.com/dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+		node.Labels[v1.NodePoolLabelKey] = "other"
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, nodePool, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+	})
+	It("should only check each generation of the NodePool once", func() {
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+
+		// Pods that are added to the NodePool's nodes don't invalidate the existing result
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-1"}})
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{{
+			NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}},
+		}}
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(ExpectExists(ctx, env.Client, nodePool).StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsTrue()).To(BeTrue())
+
+		// Updating the NodePool creates a new generation that's checked again
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(ExpectExists(ctx, env.Client, nodePool).StatusConditions().Get(v1.ConditionTypeWorkloadsCompatible).IsFalse()).To(BeTrue())
+	})
+})
@@ -34,6 +34,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 )
 
@@ -69,9 +70,9 @@ func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reco
 	}
 	switch {
 	case errors.IsNotFound(err):
-		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, "NodeClassNotFound", "NodeClass not found on cluster")
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, v1.ConditionReasonNodeClassNotFound, "NodeClass not found on cluster")
 	case !nodeClass.GetDeletionTimestamp().IsZero():
-		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, "NodeClassTerminating", "NodeClass is Terminating")
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, v1.ConditionReasonNodeClassTerminating, "NodeClass is Terminating")
 	default:
 		c.setReadyCondition(nodePool, nodeClass)
 	}
@@ -93,7 +94,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reco
 func (c *Controller) setReadyCondition(nodePool *v1.NodePool, nodeClass status.Object) {
 	ready := nodeClass.StatusConditions().Get(status.ConditionReady)
 	if ready.IsUnknown() {
-		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, "NodeClassReadinessUnknown", "Node Class Readiness Unknown")
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, v1.ConditionReasonNodeClassReadinessUnknown, "Node Class Readiness Unknown")
 	} else if ready.IsFalse() {
 		nodePool.StatusConditions().SetFalse(v1.ConditionTypeNodeClassReady, ready.Reason, ready.Message)
 	} else {
@@ -101,11 +102,11 @@ func (c *Controller) setReadyCondition(nodePool *v1.NodePool, nodeClass status.O
 	}
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
 	b := controllerruntime.NewControllerManagedBy(m).
 		Named("nodepool.readiness").
 		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 10})
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.readiness", 10)})
 	for _, nodeClass := range c.cloudProvider.GetSupportedNodeClasses() {
 		b.Watches(nodeClass, nodepoolutils.NodeClassEventHandler(c.kubeClient))
 	}
@@ -0,0 +1,136 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// Controller maintains the LimitsExceeded, Quarantined and Degraded conditions of NodePools so that automation can
+// tell whether a NodePool is usable from its status, alongside the Ready condition
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+// NewController is a constructor
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.status")
+	stored := nodePool.DeepCopy()
+
+	setLimitsExceeded(nodePool)
+	setDegraded(nodePool)
+	if err := c.setQuarantined(ctx, nodePool); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the status condition list
+		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); client.IgnoreNotFound(err) != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+	// Offerings become unavailable and available again without any events on the NodePool, so we re-evaluate periodically
+	return reconcile.Result{RequeueAfter: time.Minute}, nil
+}
+
+// setLimitsExceeded marks the NodePool as LimitsExceeded when there's none left of one of the resources in its limits
+func setLimitsExceeded(nodePool *v1.NodePool) {
+	exceeded := lo.Keys(lo.PickBy(nodePool.Status.RemainingLimits, func(_ corev1.ResourceName, q resource.Quantity) bool { return q.Sign() <= 0 }))
+	if len(exceeded) == 0 {
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeLimitsExceeded, v1.ConditionReasonWithinLimits, "NodePool is within its limits")
+		return
+	}
+	names := lo.Map(exceeded, func(r corev1.ResourceName, _ int) string { return string(r) })
+	sort.Strings(names)
+	nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeLimitsExceeded, v1.ConditionReasonLimitsExceeded,
+		fmt.Sprintf("NodePool has reached its limits for %s", strings.Join(names, ", ")))
+}
+
+// setDegraded marks the NodePool as Degraded when its launches are breaching the launch SLO or its canary failed
+func setDegraded(nodePool *v1.NodePool) {
+	switch {
+	case nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).IsTrue():
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDegraded, v1.ConditionReasonLaunchDegraded, nodePool.StatusConditions().Get(v1.ConditionTypeLaunchDegraded).Message)
+	case nodePool.StatusConditions().Get(v1.ConditionTypeCanaryFailed).IsTrue():
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDegraded, v1.ConditionReasonCanaryFailed, nodePool.StatusConditions().Get(v1.ConditionTypeCanaryFailed).Message)
+	default:
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeDegraded, v1.ConditionReasonHealthy, "NodePool is launching capacity successfully")
+	}
+}
+
+// setQuarantined marks the NodePool as Quarantined when none of the offerings that its requirements allow are available
+func (c *Controller) setQuarantined(ctx context.Context, nodePool *v1.NodePool) error {
+	instanceTypes, err := c.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return fmt.Errorf("getting instance types, %w", err)
+	}
+	requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
+	requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
+	if lo.ContainsBy(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+		return it.Requirements.Intersects(requirements) == nil && it.Offerings.Available().HasCompatible(requirements)
+	}) {
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeQuarantined, v1.ConditionReasonOfferingsAvailable, "NodePool has available offerings")
+		return nil
+	}
+	nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeQuarantined, v1.ConditionReasonNoAvailableOfferings,
+		"None of the offerings that the NodePool's requirements allow are available")
+	return nil
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.status").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.status", 10)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/status"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *status.Controller
+	ctx           context.Context
+	env           *test.Environment
+	cloudProvider *fake.CloudProvider
+	nodePool      *v1.NodePool
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Status")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	controller = status.NewController(env.Client, cloudProvider)
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Status", func() {
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+	})
+	Context("LimitsExceeded", func() {
+		It("should not mark NodePools within their limits", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			nodePool.Status.RemainingLimits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLimitsExceeded).IsFalse()).To(BeTrue())
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeLimitsExceeded).Reason).To(Equal(v1.ConditionReasonWithinLimits))
+		})
+		It("should mark NodePools that have none of a resource left", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			nodePool.Status.RemainingLimits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10"), corev1.ResourceMemory: resource.MustParse("0")}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			cond := nodePool.StatusConditions().Get(v1.ConditionTypeLimitsExceeded)
+			Expect(cond.IsTrue()).To(BeTrue())
+			Expect(cond.Reason).To(Equal(v1.ConditionReasonLimitsExceeded))
+			Expect(cond.Message).To(ContainSubstring("memory"))
+			Expect(cond.Message).ToNot(ContainSubstring("cpu"))
+		})
+	})
+	Context("Degraded", func() {
+		It("should not mark healthy NodePools", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDegraded).IsFalse()).To(BeTrue())
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDegraded).Reason).To(Equal(v1.ConditionReasonHealthy))
+		})
+		It("should mark NodePools that are breaching the launch SLO", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeLaunchDegraded, v1.ConditionReasonErrorBudgetExhausted, "launches are failing")
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			cond := nodePool.StatusConditions().Get(v1.ConditionTypeDegraded)
+			Expect(cond.IsTrue()).To(BeTrue())
+			Expect(cond.Reason).To(Equal(v1.ConditionReasonLaunchDegraded))
+			Expect(cond.Message).To(Equal("launches are failing"))
+		})
+		It("should mark NodePools whose canary failed", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeCanaryFailed, v1.ConditionReasonCanaryTimedOut, "canary timed out")
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDegraded).IsTrue()).To(BeTrue())
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDegraded).Reason).To(Equal(v1.ConditionReasonCanaryFailed))
+		})
+	})
+	Context("Quarantined", func() {
+		It("should not mark NodePools with available offerings", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeQuarantined).IsFalse()).To(BeTrue())
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeQuarantined).Reason).To(Equal(v1.ConditionReasonOfferingsAvailable))
+		})
+		It("should mark NodePools without any available offerings", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "unavailable",
+					Offerings: []cloudprovider.Offering{
+						{
+							Requirements: scheduling.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-1"}),
+							Price:        1.0,
+							Available:    false,
+						},
+					},
+				}),
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeQuarantined).IsTrue()).To(BeTrue())
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeQuarantined).Reason).To(Equal(v1.ConditionReasonNoAvailableOfferings))
+		})
+		It("should mark NodePools whose requirements only allow unavailable offerings", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+			}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "partially-available",
+					Offerings: []cloudprovider.Offering{
+						{
+							Requirements: scheduling.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-1"}),
+							Price:        1.0,
+							Available:    true,
+						},
+						{
+							Requirements: scheduling.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-2"}),
+							Price:        1.0,
+							Available:    false,
+						},
+					},
+				}),
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeQuarantined).IsTrue()).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,170 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package termination
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// changeFreezeRecheckPeriod is how often deleting NodePools are rechecked while a change freeze is enabled
+const changeFreezeRecheckPeriod = time.Minute
+
+// Controller adds a finalizer to NodePools so that, when a NodePool is deleted, its NodeClaims are drained and removed
+// within the NodePool's disruption budgets before the NodePool is removed. Without it, the garbage collector deletes
+// every NodeClaim that the NodePool owns at once. Draining is only enabled with a NodePool drain timeout, after which
+// the NodePool is removed regardless of its budgets, so a budget that never allows disruptions can't block it forever.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+}
+
+// NewController is a constructor
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.termination")
+	if !nodePool.DeletionTimestamp.IsZero() {
+		return c.finalize(ctx, nodePool)
+	}
+	stored := nodePool.DeepCopy()
+	// The finalizer is removed when draining is disabled so that NodePools aren't left with it
+	if options.FromContext(ctx).NodePoolDrainTimeout > 0 {
+		controllerutil.AddFinalizer(nodePool, v1.TerminationFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(nodePool, v1.TerminationFinalizer)
+	}
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the finalizer list
+		if err := c.kubeClient.Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// finalize deletes as many of the NodePool's NodeClaims as its budgets allow, oldest first, and removes the finalizer
+// once all of them are gone or the drain timeout has passed. The garbage collector deletes any NodeClaims that remain
+// once the NodePool is removed. Draining a NodePool is voluntary disruption, so while a change freeze is enabled its
+// NodeClaims aren't deleted and the finalizer is kept, even past the drain timeout, since removing the NodePool would
+// leave the garbage collector to delete all of them at once.
+func (c *Controller) finalize(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(nodePool, v1.TerminationFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	nodeClaims, err := nodeclaimutils.ListManaged(ctx, c.kubeClient, c.cloudProvider, nodeclaimutils.ForNodePool(nodePool.Name))
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	if len(nodeClaims) > 0 && c.cluster.ChangeFrozen() {
+		return reconcile.Result{RequeueAfter: changeFreezeRecheckPeriod}, nil
+	}
+	timeout := options.FromContext(ctx).NodePoolDrainTimeout
+	remaining := timeout - c.clock.Since(nodePool.DeletionTimestamp.Time)
+	if len(nodeClaims) > 0 && remaining > 0 {
+		if err := c.deleteNodeClaims(ctx, nodePool, nodeClaims); err != nil {
+			return reconcile.Result{}, err
+		}
+		// NodeClaim deletions requeue the NodePool, but budgets that are scheduled to open and the timeout don't
+		return reconcile.Result{RequeueAfter: lo.Min([]time.Duration{time.Minute, remaining})}, nil
+	}
+	if len(nodeClaims) > 0 {
+		log.FromContext(ctx).WithValues("count", len(nodeClaims), "timeout", timeout).Info("nodepool drain timed out, deleting remaining nodeclaims")
+	}
+	stored := nodePool.DeepCopy()
+	controllerutil.RemoveFinalizer(nodePool, v1.TerminationFinalizer)
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the finalizer list
+		if err := c.kubeClient.Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, client.IgnoreNotFound(fmt.Errorf("removing termination finalizer, %w", err))
+		}
+		log.FromContext(ctx).Info("deleted nodepool")
+	}
+	return reconcile.Result{}, nil
+}
+
+// deleteNodeClaims deletes the oldest NodeClaims of the NodePool that its budgets allow. Deleting a NodePool isn't one
+// of the disruption reasons, so only the budgets that apply to every reason are considered. NodeClaims that are already
+// deleting, e.g. because they're being disrupted, count against the budgets.
+func (c *Controller) deleteNodeClaims(ctx context.Context, nodePool *v1.NodePool, nodeClaims []*v1.NodeClaim) error {
+	deleting, active := lo.FilterReject(nodeClaims, func(nc *v1.NodeClaim, _ int) bool { return !nc.DeletionTimestamp.IsZero() })
+	allowed := nodePool.MustGetAllowedDisruptions(c.clock, len(nodeClaims), "") - len(deleting)
+	if allowed <= 0 || len(active) == 0 {
+		return nil
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].CreationTimestamp.Before(&active[j].CreationTimestamp)
+	})
+	for _, nodeClaim := range lo.Subset(active, 0, uint(allowed)) {
+		if err := nodeclaimutils.Delete(ctx, c.kubeClient, nodeClaim, v1.TerminationReasonManual); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting nodeclaim, %w", err)
+		}
+		log.FromContext(ctx).WithValues("NodeClaim", client.ObjectKeyFromObject(nodeClaim)).Info("deleting nodeclaim for nodepool deletion")
+	}
+	return nil
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.termination").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Watches(&v1.NodeClaim{}, nodepoolutils.NodeClaimEventHandler()).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("nodepool.termination", 10)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
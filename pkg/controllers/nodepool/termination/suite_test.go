@@ -0,0 +1,205 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package termination_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/termination"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *termination.Controller
+	ctx           context.Context
+	env           *test.Environment
+	fakeClock     *clock.FakeClock
+	cloudProvider *fake.CloudProvider
+	cluster       *state.Cluster
+	nodePool      *v1.NodePool
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Termination")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	fakeClock = clock.NewFakeClock(time.Now())
+	cloudProvider = fake.NewCloudProvider()
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	controller = termination.NewController(fakeClock, env.Client, cloudProvider, cluster)
+})
+
+var _ = AfterEach(func() {
+	cluster.Reset()
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Termination", func() {
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolDrainTimeout: lo.ToPtr(time.Hour)}))
+		fakeClock.SetTime(time.Now())
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Disruption: v1.Disruption{
+					Budgets: []v1.Budget{{Nodes: "1"}},
+				},
+			},
+		})
+	})
+	It("should add the termination finalizer to NodePools", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Finalizers).To(ContainElement(v1.TerminationFinalizer))
+	})
+	It("should remove the termination finalizer from NodePools when draining is disabled", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Finalizers).To(ContainElement(v1.TerminationFinalizer))
+
+		ctx = options.ToContext(ctx, test.Options())
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Finalizers).ToNot(ContainElement(v1.TerminationFinalizer))
+	})
+	It("should remove the finalizer from a deleted NodePool without NodeClaims", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(env.Client.Delete(ctx, nodePool)).To(Succeed())
+
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, nodePool)
+	})
+	It("should delete the NodeClaims of a deleted NodePool within its budgets", func() {
+		nodeClaims := lo.Times(3, func(_ int) *v1.NodeClaim {
+			return test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:     map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+					Finalizers: []string{v1.TerminationFinalizer},
+				},
+			})
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(env.Client.Delete(ctx, nodePool)).To(Succeed())
+
+		for remaining := 3; remaining > 0; remaining-- {
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			// The budget only allows a single NodeClaim to be deleting at a time
+			ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+			deleting := lo.Filter(ExpectNodeClaims(ctx, env.Client), func(nc *v1.NodeClaim, _ int) bool { return !nc.DeletionTimestamp.IsZero() })
+			Expect(deleting).To(HaveLen(1))
+			Expect(deleting[0].Status.TerminationReason).To(Equal(v1.TerminationReasonManual))
+			ExpectExists(ctx, env.Client, nodePool)
+
+			// Complete the termination of the NodeClaim
+			ExpectFinalizersRemoved(ctx, env.Client, deleting[0])
+			ExpectNotFound(ctx, env.Client, deleting[0])
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(remaining - 1))
+		}
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, nodePool)
+	})
+	It("should not delete the NodeClaims of a deleted NodePool while a change freeze is enabled", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:     map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+				Finalizers: []string{v1.TerminationFinalizer},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(env.Client.Delete(ctx, nodePool)).To(Succeed())
+		cluster.SetChangeFrozen(true)
+
+		// The NodePool is kept even past the drain timeout so that its NodeClaims aren't garbage collected
+		fakeClock.Step(2 * time.Hour)
+		result := ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+		ExpectExists(ctx, env.Client, nodePool)
+
+		cluster.SetChangeFrozen(false)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, nodePool)
+	})
+	It("should remove the finalizer once the drain timeout has passed even if the budgets don't allow disruptions", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0"}}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:     map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+				Finalizers: []string{v1.TerminationFinalizer},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(env.Client.Delete(ctx, nodePool)).To(Succeed())
+
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectExists(ctx, env.Client, nodePool)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+
+		fakeClock.Step(2 * time.Hour)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, nodePool)
+	})
+	It("should not delete the NodeClaims of other NodePools", func() {
+		otherNodePool := test.NodePool()
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:     map[string]string{v1.NodePoolLabelKey: otherNodePool.Name},
+				Finalizers: []string{v1.TerminationFinalizer},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, otherNodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		Expect(env.Client.Delete(ctx, nodePool)).To(Succeed())
+
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+		ExpectNotFound(ctx, env.Client, nodePool)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+	})
+})
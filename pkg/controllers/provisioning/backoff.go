@@ -0,0 +1,100 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// Backoff tracks consecutive failures of the provisioning loop so that retries are spaced out exponentially rather
+// than hammering the api-server and cloud provider while an outage persists.
+type Backoff struct {
+	mu       sync.Mutex
+	failures int
+}
+
+func NewBackoff() *Backoff {
+	return &Backoff{}
+}
+
+// Failure records a failed provisioning loop and returns how long to wait before retrying. The wait starts at the
+// configured base and doubles for each consecutive failure up to the configured max. Jitter is applied so that at least
+// half of the wait is always observed.
+func (b *Backoff) Failure(ctx context.Context) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	wait := options.FromContext(ctx).ProvisioningBackoffBase
+	for i := 1; i < b.failures && wait < options.FromContext(ctx).ProvisioningBackoffMax; i++ {
+		wait *= 2
+	}
+	wait = min(wait, options.FromContext(ctx).ProvisioningBackoffMax)
+	wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)) //nolint:gosec
+
+	ConsecutiveFailures.Set(float64(b.failures), map[string]string{})
+	BackoffDurationSeconds.Set(wait.Seconds(), map[string]string{})
+	return wait
+}
+
+// Success resets the backoff after a provisioning loop completes without error
+func (b *Backoff) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	ConsecutiveFailures.Set(0, map[string]string{})
+	BackoffDurationSeconds.Set(0, map[string]string{})
+}
+
+// Failures returns the number of consecutive failed provisioning loops
+func (b *Backoff) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// RateLimiter returns a rate limiter for the provisioning controller that's backed by the Backoff, so that failed
+// provisioning loops return their error to controller-runtime and are still retried after the backoff
+func (b *Backoff) RateLimiter(ctx context.Context) workqueue.TypedRateLimiter[reconcile.Request] {
+	return &rateLimiter{ctx: ctx, backoff: b}
+}
+
+type rateLimiter struct {
+	ctx     context.Context
+	backoff *Backoff
+}
+
+func (r *rateLimiter) When(reconcile.Request) time.Duration {
+	return r.backoff.Failure(r.ctx)
+}
+
+// Forget is a no-op since the provisioning loop requeues itself after every run, so the Backoff is only reset once a
+// provisioning loop succeeds
+func (r *rateLimiter) Forget(reconcile.Request) {}
+
+func (r *rateLimiter) NumRequeues(reconcile.Request) int {
+	return r.backoff.Failures()
+}
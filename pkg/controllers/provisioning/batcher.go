@@ -24,15 +24,18 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
 // Batcher separates a stream of Trigger() calls into windowed slices. The
 // window is dynamic and will be extended if additional items are added up to a
-// maximum batch duration.
+// maximum batch duration. Elements that are triggered with TriggerImmediately()
+// close the window without waiting for it to be idle.
 type Batcher[T comparable] struct {
-	trigger chan struct{}
-	clk     clock.Clock
+	trigger   chan struct{}
+	immediate chan struct{}
+	clk       clock.Clock
 
 	mu    sync.RWMutex
 	elems sets.Set[T]
@@ -41,51 +44,108 @@ type Batcher[T comparable] struct {
 // NewBatcher is a constructor for the Batcher
 func NewBatcher[T comparable](clk clock.Clock) *Batcher[T] {
 	return &Batcher[T]{
-		trigger: make(chan struct{}, 1),
-		clk:     clk,
-		elems:   sets.New[T](),
+		trigger:   make(chan struct{}, 1),
+		immediate: make(chan struct{}, 1),
+		clk:       clk,
+		elems:     sets.New[T](),
 	}
 }
 
 // Trigger causes the batcher to start a batching window, or extend the current batching window if it hasn't reached the
-// maximum length.
-func (b *Batcher[T]) Trigger(elem T) {
-	// Don't trigger if we've already triggered for this element
-	b.mu.RLock()
-	if b.elems.Has(elem) {
-		b.mu.RUnlock()
+// maximum length. The source of the trigger, e.g. a pod or a node, is recorded in the batcher's metrics.
+func (b *Batcher[T]) Trigger(elem T, source string) {
+	if !b.insert(elem) {
 		return
 	}
-	b.mu.RUnlock()
 	// The trigger is idempotently armed. This statement never blocks
 	select {
 	case b.trigger <- struct{}{}:
 	default:
 	}
+	BatchTriggersTotal.Inc(map[string]string{sourceLabel: source, pathLabel: batchedPath})
+}
+
+// TriggerImmediately causes the batcher to close the current batching window, or to skip the next batching window,
+// so that the element is processed without waiting for other elements to be batched with it.
+func (b *Batcher[T]) TriggerImmediately(elem T, source string) {
+	if !b.insert(elem) {
+		return
+	}
+	select {
+	case b.immediate <- struct{}{}:
+	default:
+	}
+	BatchTriggersTotal.Inc(map[string]string{sourceLabel: source, pathLabel: bypassPath})
+}
+
+// insert tracks the element in the current batch, returning false if we've already triggered for it
+func (b *Batcher[T]) insert(elem T) bool {
+	b.mu.RLock()
+	if b.elems.Has(elem) {
+		b.mu.RUnlock()
+		return false
+	}
+	b.mu.RUnlock()
 	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.elems.Has(elem) {
+		return false
+	}
 	b.elems.Insert(elem)
-	b.mu.Unlock()
+	return true
+}
+
+// Len returns the number of unique elements that have triggered the batcher and are waiting to be processed
+func (b *Batcher[T]) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.elems.Len()
+}
+
+// Window describes a batching window that was closed by Wait
+type Window struct {
+	// Size is the number of unique elements that were collected in the window
+	Size int
+	// Reason is why the window was closed
+	Reason string
+	// Duration is how long the window was open for
+	Duration time.Duration
+	// Extensions is the number of times that the window was extended by a new element before it was closed
+	Extensions int
 }
 
 // Wait starts a batching window and continues waiting as long as it continues receiving triggers within
-// the idleDuration, up to the maxDuration
-func (b *Batcher[T]) Wait(ctx context.Context) bool {
+// the idleDuration, up to the maxDuration. It returns whether a batch was triggered and the window that
+// collected it.
+func (b *Batcher[T]) Wait(ctx context.Context) (triggered bool, window Window) {
 	// Ensure that we always reset our tracked elements at the end of a Wait() statement
 	defer func() {
+		// The elements that armed the triggers are part of this batch, so they mustn't start the next window
+		if triggered {
+			b.drain()
+		}
 		b.mu.Lock()
+		window.Size = b.elems.Len()
 		b.elems.Clear()
 		b.mu.Unlock()
+		if triggered && window.Size > 0 {
+			BatchSize.Observe(float64(window.Size), map[string]string{})
+		}
 	}()
 
 	timeout := b.clk.NewTimer(time.Second)
 	select {
+	case <-b.immediate:
+		timeout.Stop()
+		return true, b.closeWindow(b.clk.Now(), batchBypassReason, 0)
 	case <-b.trigger:
 		// start the batching window after the first item is received
 		timeout.Stop()
 	case <-timeout.C():
 		// If no pods, bail to the outer controller framework to refresh the context
-		return false
+		return false, Window{}
 	}
+	start := b.clk.Now()
 	timeout = b.clk.NewTimer(options.FromContext(ctx).BatchMaxDuration)
 	idle := b.clk.NewTimer(options.FromContext(ctx).BatchIdleDuration)
 	defer func() {
@@ -93,6 +153,7 @@ func (b *Batcher[T]) Wait(ctx context.Context) bool {
 		idle.Stop()
 	}()
 
+	extensions := 0
 	for {
 		select {
 		case <-b.trigger:
@@ -101,10 +162,32 @@ func (b *Batcher[T]) Wait(ctx context.Context) bool {
 				<-idle.C()
 			}
 			idle.Reset(options.FromContext(ctx).BatchIdleDuration)
+			extensions++
+		case <-b.immediate:
+			return true, b.closeWindow(start, batchBypassReason, extensions)
 		case <-timeout.C():
-			return true
+			return true, b.closeWindow(start, batchMaxDurationReason, extensions)
 		case <-idle.C():
-			return true
+			return true, b.closeWindow(start, batchIdleDurationReason, extensions)
 		}
 	}
 }
+
+// drain disarms the triggers, which are only armed by elements of the batch that's being closed
+func (b *Batcher[T]) drain() {
+	select {
+	case <-b.trigger:
+	default:
+	}
+	select {
+	case <-b.immediate:
+	default:
+	}
+}
+
+func (b *Batcher[T]) closeWindow(start time.Time, reason string, extensions int) Window {
+	duration := b.clk.Since(start)
+	BatchWindowDurationSeconds.Observe(duration.Seconds(), map[string]string{metrics.ReasonLabel: reason})
+	BatchesTotal.Inc(map[string]string{metrics.ReasonLabel: reason})
+	return Window{Reason: reason, Duration: duration, Extensions: extensions}
+}
@@ -31,6 +31,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/utils/pod"
 )
 
@@ -52,12 +53,18 @@ func NewPodController(kubeClient client.Client, provisioner *Provisioner, cluste
 
 // Reconcile the resource
 func (c *PodController) Reconcile(ctx context.Context, p *corev1.Pod) (reconcile.Result, error) {
-	ctx = injection.WithControllerName(ctx, "provisioner.trigger.pod") //nolint:ineffassign,staticcheck
+	ctx = injection.WithControllerName(ctx, "provisioner.trigger.pod")
 
-	if !pod.IsProvisionable(p) {
+	if !pod.IsProvisionable(p) || !TriggersProvisioning(ctx, p) {
 		return reconcile.Result{}, nil
 	}
-	c.provisioner.Trigger(p.UID)
+	// Pods that bypass batching only close the batching window when they're first observed. Pods that are still
+	// pending are requeued, and closing a window each time would stop other pods from being batched.
+	if BypassesBatching(ctx, p) && c.cluster.PodAckTime(client.ObjectKeyFromObject(p)).IsZero() {
+		c.provisioner.TriggerImmediately(p.UID, TriggerSourcePod)
+	} else {
+		c.provisioner.Trigger(p.UID, TriggerSourcePod)
+	}
 	// ACK the pending pod when first observed so that total time spent pending due to Karpenter is tracked.
 	c.cluster.AckPods(p)
 	// Continue to requeue until the pod is no longer provisionable. Pods may
@@ -67,11 +74,11 @@ func (c *PodController) Reconcile(ctx context.Context, p *corev1.Pod) (reconcile
 	return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
 }
 
-func (c *PodController) Register(_ context.Context, m manager.Manager) error {
+func (c *PodController) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("provisioner.trigger.pod").
 		For(&corev1.Pod{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("provisioner.trigger.pod", 10)}).
 		Complete(reconcile.AsReconciler(m.GetClient(), c))
 }
 
@@ -102,7 +109,7 @@ func (c *NodeController) Reconcile(ctx context.Context, n *corev1.Node) (reconci
 	}) {
 		return reconcile.Result{}, nil
 	}
-	c.provisioner.Trigger(n.UID)
+	c.provisioner.Trigger(n.UID, TriggerSourceNode)
 	// Continue to requeue until the node is no longer provisionable. Pods may
 	// not be scheduled as expected if new pods are created while nodes are
 	// coming online. Even if a provisioning loop is successful, the pod may
@@ -110,10 +117,10 @@ func (c *NodeController) Reconcile(ctx context.Context, n *corev1.Node) (reconci
 	return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
 }
 
-func (c *NodeController) Register(_ context.Context, m manager.Manager) error {
+func (c *NodeController) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("provisioner.trigger.node").
 		For(&corev1.Node{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 10}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: options.FromContext(ctx).ControllerConcurrency.For("provisioner.trigger.node", 10)}).
 		Complete(reconcile.AsReconciler(m.GetClient(), c))
 }
@@ -0,0 +1,52 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+// BatchEventRateLimiter is a pointer so it rate-limits across events
+var BatchEventRateLimiter = flowcontrol.NewTokenBucketRateLimiter(5, 10)
+
+func BatchWindowExtendedEvent(pod *corev1.Pod, window Window) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.BatchWindowExtended,
+		Message:        fmt.Sprintf("Provisioning waited %s for the batching window, which other pods extended %d times", window.Duration.Round(time.Millisecond), window.Extensions),
+		DedupeValues:   []string{string(pod.UID)},
+		RateLimiter:    BatchEventRateLimiter,
+	}
+}
+
+func BatchMaxDurationReachedEvent(pod *corev1.Pod, window Window) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.BatchMaxDurationReached,
+		Message:        fmt.Sprintf("Provisioning waited the maximum batch duration of %s for the batching window", window.Duration.Round(time.Millisecond)),
+		DedupeValues:   []string{string(pod.UID)},
+		RateLimiter:    BatchEventRateLimiter,
+	}
+}
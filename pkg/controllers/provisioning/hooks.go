@@ -0,0 +1,108 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// NodeClaimHookTimeout is the maximum amount of time that a single NodeClaimHook may take to mutate a NodeClaim
+// before the launch is vetoed. This is a var so that it can be shortened in testing.
+var NodeClaimHookTimeout = 10 * time.Second
+
+// NodeClaimHook is invoked with each NodeClaim that the provisioner computes, after scheduling and before the
+// NodeClaim is created and launched. Hooks may mutate the NodeClaim, for example to inject labels or adjust resource
+// requests, or return an error to veto the launch.
+type NodeClaimHook interface {
+	// Name identifies the hook in logs and metrics
+	Name() string
+	// Mutate is passed a copy of the NodeClaim, which replaces the NodeClaim if no error is returned
+	Mutate(ctx context.Context, nodeClaim *v1.NodeClaim) error
+}
+
+// NodeClaimVetoedError is returned when a NodeClaimHook fails or times out, preventing the NodeClaim from launching
+type NodeClaimVetoedError struct {
+	error
+	Hook string
+}
+
+func NewNodeClaimVetoedError(hook string, err error) *NodeClaimVetoedError {
+	return &NodeClaimVetoedError{
+		error: fmt.Errorf("nodeclaim vetoed by hook %s, %w", hook, err),
+		Hook:  hook,
+	}
+}
+
+func IsNodeClaimVetoedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var vetoedErr *NodeClaimVetoedError
+	return errors.As(err, &vetoedErr)
+}
+
+// RegisterNodeClaimHooks adds hooks that are invoked in the order that they're registered
+func (p *Provisioner) RegisterNodeClaimHooks(hooks ...NodeClaimHook) {
+	p.hooks = append(p.hooks, hooks...)
+}
+
+// runNodeClaimHooks passes the NodeClaim through each of the registered hooks in order, returning the mutated NodeClaim.
+// Each hook is bounded by the NodeClaimHookTimeout. Since a hook that times out may still be running, hooks operate on
+// a copy of the NodeClaim that's only adopted once the hook returns successfully.
+func (p *Provisioner) runNodeClaimHooks(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
+	for _, hook := range p.hooks {
+		mutated, err := runNodeClaimHook(ctx, hook, nodeClaim)
+		if err != nil {
+			return nil, NewNodeClaimVetoedError(hook.Name(), err)
+		}
+		nodeClaim = mutated
+	}
+	return nodeClaim, nil
+}
+
+func runNodeClaimHook(ctx context.Context, hook NodeClaimHook, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
+	ctx, cancel := context.WithTimeout(ctx, NodeClaimHookTimeout)
+	defer cancel()
+
+	start := time.Now()
+	mutated := nodeClaim.DeepCopy()
+	errs := make(chan error, 1)
+	go func() { errs <- hook.Mutate(ctx, mutated) }()
+
+	var err error
+	result := nodeClaimHookSuccessResult
+	select {
+	case err = <-errs:
+		if err != nil {
+			result = nodeClaimHookVetoedResult
+		}
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s, %w", NodeClaimHookTimeout, ctx.Err())
+		result = nodeClaimHookTimeoutResult
+	}
+	NodeClaimHookDurationSeconds.Observe(time.Since(start).Seconds(), map[string]string{hookLabel: hook.Name()})
+	NodeClaimHookInvocationsTotal.Inc(map[string]string{hookLabel: hook.Name(), resultLabel: result})
+	if err != nil {
+		return nil, err
+	}
+	return mutated, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	opmetrics "github.com/awslabs/operatorpkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/karpenter/pkg/metrics"
+)
+
+const (
+	provisionerSubsystem = "provisioner"
+
+	// Reasons that a batching window was closed
+	batchMaxDurationReason  = "max_duration"
+	batchIdleDurationReason = "idle_duration"
+	batchBypassReason       = "bypass"
+
+	sourceLabel = "source"
+	pathLabel   = "path"
+
+	// Paths that a trigger takes through the batcher
+	batchedPath = "batched"
+	bypassPath  = "bypass"
+
+	hookLabel   = "hook"
+	resultLabel = "result"
+
+	// Results of invoking a NodeClaimHook
+	nodeClaimHookSuccessResult = "success"
+	nodeClaimHookVetoedResult  = "vetoed"
+	nodeClaimHookTimeoutResult = "timeout"
+)
+
+func init() {
+	BatchesTotal.Add(0, map[string]string{metrics.ReasonLabel: batchMaxDurationReason})
+	BatchesTotal.Add(0, map[string]string{metrics.ReasonLabel: batchIdleDurationReason})
+	BatchesTotal.Add(0, map[string]string{metrics.ReasonLabel: batchBypassReason})
+}
+
+var (
+	BatchSize = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "batch_size",
+			Help:      "Number of unique triggers (pending pods and disrupted nodes) collected in a single batching window.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+		},
+		[]string{},
+	)
+	BatchWindowDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "batch_window_duration_seconds",
+			Help:      "Duration of a batching window in seconds, measured from the first trigger until the window is closed. Labeled by the reason that the window was closed.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{metrics.ReasonLabel},
+	)
+	BatchesTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "batches_total",
+			Help:      "Number of batching windows that were closed. Labeled by whether the window reached the batch max duration or the batch idle duration, or was bypassed by a pod that is configured to skip batching.",
+		},
+		[]string{metrics.ReasonLabel},
+	)
+	BatchTriggersTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "batch_triggers_total",
+			Help:      "Number of unique triggers that were added to a batching window. Labeled by the source of the trigger and whether it was batched or bypassed batching.",
+		},
+		[]string{sourceLabel, pathLabel},
+	)
+	BatchDeferredTriggersTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "batch_deferred_triggers_total",
+			Help:      "Number of batched triggers (pending pods and disrupted nodes) that were deferred to a later batch because cluster state was not synced.",
+		},
+		[]string{},
+	)
+	NodeClaimHookDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "nodeclaim_hook_duration_seconds",
+			Help:      "Duration of a NodeClaim hook invocation in seconds. Labeled by the hook.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{hookLabel},
+	)
+	NodeClaimHookInvocationsTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "nodeclaim_hook_invocations_total",
+			Help:      "Number of NodeClaim hook invocations. Labeled by the hook and whether it succeeded, vetoed the launch or timed out.",
+		},
+		[]string{hookLabel, resultLabel},
+	)
+	ConsecutiveFailures = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "consecutive_failures",
+			Help:      "Number of consecutive provisioning loops that failed. Reset to zero when a provisioning loop succeeds.",
+		},
+		[]string{},
+	)
+	BackoffDurationSeconds = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: provisionerSubsystem,
+			Name:      "backoff_duration_seconds",
+			Help:      "Duration in seconds that the provisioner is waiting before retrying after consecutive failed provisioning loops. Zero when the last provisioning loop succeeded.",
+		},
+		[]string{},
+	)
+)
@@ -0,0 +1,165 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/validation"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// NamingData are the fields that the NodeClaim naming and tagging templates are rendered with
+type NamingData struct {
+	// NodePool is the name of the NodePool that the NodeClaim is launched for
+	NodePool string
+	// Shard is the name of the shard that this Karpenter deployment manages
+	Shard string
+	// Environment is the name of the environment that this Karpenter deployment runs in
+	Environment string
+	// Batch identifies the scheduling run that computed the NodeClaim
+	Batch string
+	// Index is the position of the NodeClaim within its scheduling run
+	Index int
+}
+
+// NamingPolicy names, labels and tags NodeClaims from operator-configured templates before they're created. Templates
+// that aren't configured leave the NodeClaim unchanged.
+type NamingPolicy struct {
+	source namingSource
+	name   *template.Template
+	labels map[string]*template.Template
+	tags   map[string]*template.Template
+}
+
+// namingSource is the options that a NamingPolicy's templates are parsed from
+type namingSource struct {
+	name, labels, tags string
+}
+
+func namingSourceFor(opts *options.Options) namingSource {
+	return namingSource{name: opts.NodeClaimNameTemplate, labels: opts.NodeClaimLabelTemplates, tags: opts.NodeClaimTagTemplates}
+}
+
+// NewNamingPolicy parses the NodeClaim naming and tagging templates from the options
+func NewNamingPolicy(opts *options.Options) (*NamingPolicy, error) {
+	p := &NamingPolicy{source: namingSourceFor(opts)}
+	var err error
+	if opts.NodeClaimNameTemplate != "" {
+		if p.name, err = template.New("name").Option("missingkey=error").Parse(opts.NodeClaimNameTemplate); err != nil {
+			return nil, fmt.Errorf("parsing nodeclaim name template, %w", err)
+		}
+	}
+	if p.labels, err = parseTemplates(opts.NodeClaimLabelTemplates); err != nil {
+		return nil, fmt.Errorf("parsing nodeclaim label templates, %w", err)
+	}
+	if p.tags, err = parseTemplates(opts.NodeClaimTagTemplates); err != nil {
+		return nil, fmt.Errorf("parsing nodeclaim tag templates, %w", err)
+	}
+	return p, nil
+}
+
+// ParsedFrom returns true if the policy's templates were parsed from the options
+func (p *NamingPolicy) ParsedFrom(opts *options.Options) bool {
+	return p.source == namingSourceFor(opts)
+}
+
+func parseTemplates(str string) (map[string]*template.Template, error) {
+	templateMap := map[string]string{}
+	if err := cliflag.NewMapStringString(&templateMap).Set(str); err != nil {
+		return nil, err
+	}
+	templates := map[string]*template.Template{}
+	for key, text := range templateMap {
+		t, err := template.New(key).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		templates[key] = t
+	}
+	return templates, nil
+}
+
+// NamingDataFor returns the fields that the templates are rendered with for the NodeClaim
+func NamingDataFor(opts *options.Options, n *scheduler.NodeClaim) NamingData {
+	return NamingData{
+		NodePool:    n.NodePoolName,
+		Shard:       opts.ShardName,
+		Environment: opts.EnvironmentName,
+		Batch:       strings.Split(n.Annotations[v1.SchedulingRunAnnotationKey], "-")[0],
+		Index:       n.Index,
+	}
+}
+
+// Apply renders the templates with the data and applies the results to the NodeClaim. A rendered name that ends
+// with a "-" is used as the prefix for a generated name.
+func (p *NamingPolicy) Apply(nodeClaim *v1.NodeClaim, data NamingData) error {
+	if p.name != nil {
+		name, err := render(p.name, data)
+		if err != nil {
+			return fmt.Errorf("rendering nodeclaim name, %w", err)
+		}
+		if errs := validation.IsDNS1123Subdomain(strings.TrimSuffix(name, "-")); len(errs) != 0 {
+			return fmt.Errorf("rendered nodeclaim name %q is invalid, %s", name, strings.Join(errs, ", "))
+		}
+		if strings.HasSuffix(name, "-") {
+			nodeClaim.Name, nodeClaim.GenerateName = "", name
+		} else {
+			nodeClaim.Name, nodeClaim.GenerateName = name, ""
+		}
+	}
+	for key, t := range p.labels {
+		value, err := render(t, data)
+		if err != nil {
+			return fmt.Errorf("rendering label %q, %w", key, err)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return fmt.Errorf("label key %q is invalid, %s", key, strings.Join(errs, ", "))
+		}
+		if err = v1.IsRestrictedLabel(key); err != nil {
+			return err
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return fmt.Errorf("rendered value %q of label %q is invalid, %s", value, key, strings.Join(errs, ", "))
+		}
+		nodeClaim.Labels = lo.Assign(nodeClaim.Labels, map[string]string{key: value})
+	}
+	for key, t := range p.tags {
+		value, err := render(t, data)
+		if err != nil {
+			return fmt.Errorf("rendering tag %q, %w", key, err)
+		}
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.TagAnnotationKeyPrefix + key: value})
+	}
+	return nil
+}
+
+func render(t *template.Template, data NamingData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
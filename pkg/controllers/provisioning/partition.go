@@ -0,0 +1,241 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// partition is a set of NodePools along with the pods that can only be scheduled against those NodePools, and the
+// existing nodes that only the partition's pods are scheduled to. Partitions don't share any pods or nodes, so they can
+// be solved by independent schedulers.
+type partition struct {
+	nodePools []*v1.NodePool
+	pods      []*corev1.Pod
+	nodes     []*state.StateNode
+}
+
+// partitionByNodePool groups the NodePools so that every pod is only compatible with the NodePools of a single partition.
+// A pod is compatible with a NodePool if any of its required node affinity terms is, regardless of its preferences, so
+// that preferences are still relaxed by the scheduler of the partition. Pods that aren't compatible with any NodePool
+// are kept together in a shared partition without any NodePools, so that they can be placed on existing nodes but
+// never launch capacity. Pods with pod affinities, pod anti-affinities or topology spread constraints and the pods that
+// they select are kept in a single partition, so that their topology is solved by a single scheduler. If some of them
+// aren't compatible with any NodePool, every pod is kept in a single partition. The passed NodePools are expected to
+// be ordered by weight and the order is preserved within each partition.
+func partitionByNodePool(pods []*corev1.Pod, nodePools []*v1.NodePool, stateNodes []*state.StateNode) []*partition {
+	templates := lo.Map(nodePools, func(np *v1.NodePool, _ int) *scheduler.NodeClaimTemplate {
+		return scheduler.NewNodeClaimTemplate(np)
+	})
+	// parents is a disjoint-set forest over the NodePool indices
+	parents := lo.Range(len(nodePools))
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	podNodePools := make([][]int, len(pods))
+	for i, pod := range pods {
+		// The required node affinity terms are ORed, so the pod is compatible with the NodePool if any of them is
+		podRequirements := scheduling.NewStrictPodRequirementsPerTerm(pod)
+		for j, nct := range templates {
+			if scheduling.Taints(nct.Spec.Taints).Tolerates(pod) != nil {
+				continue
+			}
+			if !lo.ContainsBy(podRequirements, func(requirements scheduling.Requirements) bool {
+				return nct.Requirements.IsCompatible(requirements, scheduling.AllowUndefinedWellKnownLabels)
+			}) {
+				continue
+			}
+			podNodePools[i] = append(podNodePools[i], j)
+		}
+		for _, j := range lo.Drop(podNodePools[i], 1) {
+			parents[find(j)] = find(podNodePools[i][0])
+		}
+	}
+
+	// Pods whose topology depends on each other are joined into a single partition
+	var topologyRoot *int
+	for i, related := range topologyRelatedPods(pods) {
+		if !related {
+			continue
+		}
+		if len(podNodePools[i]) == 0 {
+			return []*partition{{nodePools: nodePools, pods: pods, nodes: stateNodes}}
+		}
+		if topologyRoot == nil {
+			topologyRoot = lo.ToPtr(podNodePools[i][0])
+		}
+		parents[find(podNodePools[i][0])] = find(*topologyRoot)
+	}
+
+	shared := &partition{}
+	partitions := []*partition{shared}
+	byRoot := map[int]*partition{}
+	for i, np := range nodePools {
+		p, ok := byRoot[find(i)]
+		if !ok {
+			p = &partition{}
+			byRoot[find(i)] = p
+			partitions = append(partitions, p)
+		}
+		p.nodePools = append(p.nodePools, np)
+	}
+	for i, pod := range pods {
+		p := shared
+		if len(podNodePools[i]) > 0 {
+			p = byRoot[find(podNodePools[i][0])]
+		}
+		p.pods = append(p.pods, pod)
+	}
+	// Partitions without any pods don't need a scheduler
+	partitions = lo.Filter(partitions, func(p *partition, _ int) bool { return len(p.pods) > 0 })
+	assignNodes(partitions, stateNodes)
+	return partitions
+}
+
+// assignNodes gives each existing node to a single partition, so that partitions never overcommit the same node. Nodes
+// owned by a NodePool go to the NodePool's partition. Other nodes, and nodes whose NodePool's partition has no pods,
+// go to the first partition with a pod that the node is compatible with.
+func assignNodes(partitions []*partition, stateNodes []*state.StateNode) {
+	byNodePool := map[string]*partition{}
+	for _, p := range partitions {
+		for _, np := range p.nodePools {
+			byNodePool[np.Name] = p
+		}
+	}
+	for _, n := range stateNodes {
+		p, ok := byNodePool[n.Labels()[v1.NodePoolLabelKey]]
+		if !ok {
+			requirements := scheduling.NewLabelRequirements(n.Labels())
+			p, ok = lo.Find(partitions, func(p *partition) bool {
+				return lo.ContainsBy(p.pods, func(pod *corev1.Pod) bool {
+					return scheduling.Taints(n.Taints()).Tolerates(pod) == nil && lo.ContainsBy(scheduling.NewStrictPodRequirementsPerTerm(pod), func(r scheduling.Requirements) bool {
+						return requirements.IsCompatible(r)
+					})
+				})
+			})
+		}
+		if ok {
+			p.nodes = append(p.nodes, n)
+		}
+	}
+}
+
+// topologyRelatedPods returns whether each pod has pod affinities, pod anti-affinities or topology spread constraints,
+// or is selected by those of another pod. Namespace selectors are treated as selecting every namespace.
+func topologyRelatedPods(pods []*corev1.Pod) []bool {
+	related := make([]bool, len(pods))
+	for i, pod := range pods {
+		selectors := topologySelectors(pod)
+		if len(selectors) == 0 {
+			continue
+		}
+		related[i] = true
+		for j, other := range pods {
+			if lo.ContainsBy(selectors, func(s podSelector) bool { return s.matches(other) }) {
+				related[j] = true
+			}
+		}
+	}
+	return related
+}
+
+// podSelector selects the pods in a set of namespaces by their labels. An empty set of namespaces selects every
+// namespace.
+type podSelector struct {
+	namespaces []string
+	selector   labels.Selector
+}
+
+func (s podSelector) matches(pod *corev1.Pod) bool {
+	return (len(s.namespaces) == 0 || lo.Contains(s.namespaces, pod.Namespace)) && s.selector.Matches(labels.Set(pod.Labels))
+}
+
+// topologySelectors returns the selectors of the pod's affinities, anti-affinities and topology spread constraints
+func topologySelectors(pod *corev1.Pod) []podSelector {
+	var terms []corev1.PodAffinityTerm
+	if affinity := pod.Spec.Affinity; affinity != nil {
+		if affinity.PodAffinity != nil {
+			terms = append(terms, affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+			terms = append(terms, lo.Map(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, func(t corev1.WeightedPodAffinityTerm, _ int) corev1.PodAffinityTerm {
+				return t.PodAffinityTerm
+			})...)
+		}
+		if affinity.PodAntiAffinity != nil {
+			terms = append(terms, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+			terms = append(terms, lo.Map(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, func(t corev1.WeightedPodAffinityTerm, _ int) corev1.PodAffinityTerm {
+				return t.PodAffinityTerm
+			})...)
+		}
+	}
+	selectors := lo.Map(terms, func(t corev1.PodAffinityTerm, _ int) podSelector {
+		namespaces := lo.Ternary(len(t.Namespaces) == 0, []string{pod.Namespace}, t.Namespaces)
+		if t.NamespaceSelector != nil {
+			namespaces = nil
+		}
+		return podSelector{namespaces: namespaces, selector: labelSelector(t.LabelSelector)}
+	})
+	for _, tsc := range pod.Spec.TopologySpreadConstraints {
+		selectors = append(selectors, podSelector{namespaces: []string{pod.Namespace}, selector: labelSelector(tsc.LabelSelector)})
+	}
+	return selectors
+}
+
+// labelSelector converts the label selector, matching nothing if it's missing or invalid
+func labelSelector(selector *metav1.LabelSelector) labels.Selector {
+	if selector == nil {
+		return labels.Nothing()
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return s
+}
+
+// mergeResults combines the results of schedulers that were run against disjoint partitions
+func mergeResults(results ...scheduler.Results) scheduler.Results {
+	merged := scheduler.Results{PodErrors: map[*corev1.Pod]error{}}
+	for _, r := range results {
+		merged.NewNodeClaims = append(merged.NewNodeClaims, r.NewNodeClaims...)
+		merged.ExistingNodes = append(merged.ExistingNodes, r.ExistingNodes...)
+		for pod, err := range r.PodErrors {
+			merged.PodErrors[pod] = err
+		}
+	}
+	return merged
+}
+
+// clusterLimitsEnabled returns true if any of the cluster-wide limits are configured
+func clusterLimitsEnabled(ctx context.Context) bool {
+	opts := options.FromContext(ctx)
+	return opts.MaxNodes >= 0 || opts.MaxCPU >= 0 || opts.MaxHourlyCost >= 0
+}
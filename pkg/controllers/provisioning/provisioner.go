@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/awslabs/operatorpkg/option"
@@ -28,15 +29,15 @@ import (
 	"github.com/awslabs/operatorpkg/status"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -47,10 +48,14 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/health"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
@@ -75,70 +80,155 @@ type Provisioner struct {
 	cloudProvider  cloudprovider.CloudProvider
 	kubeClient     client.Client
 	batcher        *Batcher[types.UID]
+	backoff        *Backoff
 	volumeTopology *scheduler.VolumeTopology
+	claimTopology  *scheduler.ResourceClaimTopology
+	simulator      *simulation.Simulator
 	cluster        *state.Cluster
 	recorder       events.Recorder
 	cm             *pretty.ChangeMonitor
 	clock          clock.Clock
+
+	unhealthyOfferings   *cloudprovider.UnhealthyOfferings
+	unavailableOfferings *cloudprovider.UnavailableOfferings
+	explanations         *scheduler.Explanations
+	hooks                []NodeClaimHook
+	heartbeat            *health.Heartbeat
+
+	namingMu sync.Mutex
+	naming   *NamingPolicy
 }
 
+// heartbeatTimeout is how long the provisioning loop can go without completing a batch before it is considered stuck
+const heartbeatTimeout = 5 * time.Minute
+
 func NewProvisioner(kubeClient client.Client, recorder events.Recorder,
 	cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster,
-	clock clock.Clock,
+	clock clock.Clock, unhealthyOfferings *cloudprovider.UnhealthyOfferings, unavailableOfferings *cloudprovider.UnavailableOfferings,
+	labelAliases *scheduling.LabelAliases,
 ) *Provisioner {
+	volumeTopology := scheduler.NewVolumeTopology(kubeClient)
+	claimTopology := scheduler.NewResourceClaimTopology(kubeClient)
 	p := &Provisioner{
 		batcher:        NewBatcher[types.UID](clock),
+		backoff:        NewBackoff(),
 		cloudProvider:  cloudProvider,
 		kubeClient:     kubeClient,
-		volumeTopology: scheduler.NewVolumeTopology(kubeClient),
+		volumeTopology: volumeTopology,
+		claimTopology:  claimTopology,
+		simulator:      simulation.NewSimulator(kubeClient, cloudProvider, cluster, clock, labelAliases, volumeTopology, claimTopology),
 		cluster:        cluster,
 		recorder:       recorder,
 		cm:             pretty.NewChangeMonitor(),
 		clock:          clock,
+
+		unhealthyOfferings:   unhealthyOfferings,
+		unavailableOfferings: unavailableOfferings,
+		explanations:         scheduler.NewExplanations(),
 	}
+	p.heartbeat = health.NewHeartbeat(clock, "provisioner", heartbeatTimeout, p.batcher.Len)
 	return p
 }
 
-func (p *Provisioner) Trigger(uid types.UID) {
-	p.batcher.Trigger(uid)
+// Explanations returns the breakdown of why pods failed to schedule in the most recent provisioning run
+func (p *Provisioner) Explanations() *scheduler.Explanations {
+	return p.explanations
 }
 
-func (p *Provisioner) Register(_ context.Context, m manager.Manager) error {
+// Heartbeat returns the Heartbeat that tracks whether the provisioning loop is making progress
+func (p *Provisioner) Heartbeat() *health.Heartbeat {
+	return p.heartbeat
+}
+
+// Sources of provisioning triggers, recorded in the batcher's metrics
+const (
+	TriggerSourcePod         = "pod"
+	TriggerSourceNode        = "node"
+	TriggerSourceNodeClaim   = "nodeclaim"
+	TriggerSourceDeferredPod = "deferred_pod"
+	// TriggerSourceNominationConflict is used for pods whose nomination to an existing node conflicted with an earlier batch
+	TriggerSourceNominationConflict = "nomination_conflict"
+)
+
+// Trigger adds the object to the current batching window, starting a new window if there isn't one
+func (p *Provisioner) Trigger(uid types.UID, source string) {
+	p.batcher.Trigger(uid, source)
+}
+
+// TriggerImmediately starts a provisioning loop for the object without waiting for the batching window to close
+func (p *Provisioner) TriggerImmediately(uid types.UID, source string) {
+	p.batcher.TriggerImmediately(uid, source)
+}
+
+func (p *Provisioner) Register(ctx context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("provisioner").
+		WithOptions(controller.Options{RateLimiter: p.backoff.RateLimiter(ctx)}).
 		WatchesRawSource(singleton.Source()).
 		Complete(singleton.AsReconciler(p))
 }
 
 func (p *Provisioner) Reconcile(ctx context.Context) (result reconcile.Result, err error) {
 	ctx = injection.WithControllerName(ctx, "provisioner")
+	p.heartbeat.Start()
 
 	// Batch pods
-	if triggered := p.batcher.Wait(ctx); !triggered {
+	triggered, window := p.batcher.Wait(ctx)
+	if !triggered {
+		p.heartbeat.Beat()
 		return reconcile.Result{RequeueAfter: singleton.RequeueImmediately}, nil
 	}
 	// We need to ensure that our internal cluster state mechanism is synced before we proceed
 	// with making any scheduling decision off of our state nodes. Otherwise, we have the potential to make
 	// a scheduling decision based on a smaller subset of nodes in our cluster state than actually exist.
-	if !p.cluster.Synced(ctx) {
-		log.FromContext(ctx).V(1).Info("waiting on cluster sync")
+	// If a max staleness is configured, we tolerate a small number of recently created objects being untracked.
+	if synced, status := p.cluster.SyncedWithinStaleness(ctx); !synced {
+		log.FromContext(ctx).WithValues("batch-size", window.Size).WithValues(status.LogValues()...).V(1).Info("waiting on cluster sync")
+		BatchDeferredTriggersTotal.Add(float64(window.Size), map[string]string{})
 		return reconcile.Result{RequeueAfter: singleton.RequeueImmediately}, nil
 	}
+	// Beat once the batch has been processed so that a stalled cluster sync or a hung scheduling run stops the heartbeat
+	defer p.heartbeat.Beat()
 
 	// Schedule pods to potential nodes, exit if nothing to do
 	results, err := p.Schedule(ctx)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, fmt.Errorf("scheduling pods, %w", err)
 	}
+	p.publishBatchEvents(window, results)
 	if len(results.NewNodeClaims) == 0 {
+		p.backoff.Success()
 		return reconcile.Result{RequeueAfter: singleton.RequeueImmediately}, nil
 	}
 	if _, err = p.CreateNodeClaims(ctx, results.NewNodeClaims, WithReason(metrics.ProvisionedReason), RecordPodNomination); err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, fmt.Errorf("creating nodeclaims, %w", err)
 	}
+	p.backoff.Success()
 	return reconcile.Result{RequeueAfter: singleton.RequeueImmediately}, nil
 }
 
+// publishBatchEvents tells the pods of the batch when their provisioning was delayed by the batching window, either
+// because other pods kept extending it or because it was only closed by the batch max duration
+func (p *Provisioner) publishBatchEvents(window Window, results scheduler.Results) {
+	if window.Reason != batchMaxDurationReason && (window.Reason != batchIdleDurationReason || window.Extensions == 0) {
+		return
+	}
+	pods := lo.Keys(results.PodErrors)
+	for _, n := range results.NewNodeClaims {
+		pods = append(pods, n.Pods...)
+	}
+	for _, n := range results.ExistingNodes {
+		pods = append(pods, n.Pods...)
+	}
+	for _, pod := range pods {
+		if window.Reason == batchMaxDurationReason {
+			p.recorder.Publish(BatchMaxDurationReachedEvent(pod, window))
+		} else {
+			p.recorder.Publish(BatchWindowExtendedEvent(pod, window))
+		}
+	}
+}
+
 // CreateNodeClaims launches nodes passed into the function in parallel. It returns a slice of the successfully created node
 // names as well as a multierr of any errors that occurred while launching nodes
 func (p *Provisioner) CreateNodeClaims(ctx context.Context, nodeClaims []*scheduler.NodeClaim, opts ...option.Function[LaunchOptions]) ([]string, error) {
@@ -163,6 +253,14 @@ func (p *Provisioner) GetPendingPods(ctx context.Context) ([]*corev1.Pod, error)
 	if err != nil {
 		return nil, fmt.Errorf("listing pods, %w", err)
 	}
+	// Pods that a quota prevents from scheduling won't schedule on new capacity either
+	quotaBlockedPods, pods := lo.FilterReject(pods, func(po *corev1.Pod, _ int) bool {
+		return podutils.IsQuotaBlocked(po)
+	})
+	for _, po := range quotaBlockedPods {
+		log.FromContext(ctx).WithValues("Pod", klog.KRef(po.Namespace, po.Name)).V(1).Info("ignoring pod, blocked by quota")
+	}
+	scheduler.QuotaBlockedPodCount.Set(float64(len(quotaBlockedPods)), nil)
 	rejectedPods, pods := lo.FilterReject(pods, func(po *corev1.Pod, _ int) bool {
 		if err := p.Validate(ctx, po); err != nil {
 			log.FromContext(ctx).WithValues("Pod", klog.KRef(po.Namespace, po.Name)).V(1).Info(fmt.Sprintf("ignoring pod, %s", err))
@@ -211,8 +309,16 @@ func (p *Provisioner) consolidationWarnings(ctx context.Context, pods []*corev1.
 
 var ErrNodePoolsNotFound = errors.New("no nodepools found")
 
-//nolint:gocyclo
-func (p *Provisioner) NewScheduler(ctx context.Context, pods []*corev1.Pod, stateNodes []*state.StateNode) (*scheduler.Scheduler, error) {
+func (p *Provisioner) NewScheduler(ctx context.Context, pods []*corev1.Pod, stateNodes []*state.StateNode, opts ...option.Function[simulation.Options]) (*scheduler.Scheduler, error) {
+	nodePools, err := p.schedulableNodePools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.newScheduler(ctx, pods, stateNodes, nodePools, opts...)
+}
+
+// schedulableNodePools returns the managed NodePools that are ready and not being deleted
+func (p *Provisioner) schedulableNodePools(ctx context.Context) ([]*v1.NodePool, error) {
 	nodePools, err := nodepoolutils.ListManaged(ctx, p.kubeClient, p.cloudProvider)
 	if err != nil {
 		return nil, fmt.Errorf("listing nodepools, %w", err)
@@ -227,75 +333,19 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*corev1.Pod, stat
 	if len(nodePools) == 0 {
 		return nil, ErrNodePoolsNotFound
 	}
+	return nodePools, nil
+}
 
-	// nodeTemplates generated from NodePools are ordered by weight
-	// since they are stored within a slice and scheduling
-	// will always attempt to schedule on the first nodeTemplate
-	nodepoolutils.OrderByWeight(nodePools)
-
-	instanceTypes := map[string][]*cloudprovider.InstanceType{}
-	domains := map[string]sets.Set[string]{}
-	for _, np := range nodePools {
-		its, err := p.cloudProvider.GetInstanceTypes(ctx, np)
-		if err != nil {
-			log.FromContext(ctx).WithValues("NodePool", klog.KRef("", np.Name)).Error(err, "skipping, unable to resolve instance types")
-			continue
-		}
-		if len(its) == 0 {
-			log.FromContext(ctx).WithValues("NodePool", klog.KRef("", np.Name)).Info("skipping, no resolved instance types found")
-			continue
-		}
-
-		instanceTypes[np.Name] = its
-
-		// Construct Topology Domains
-		for _, it := range its {
-			// We need to intersect the instance type requirements with the current nodePool requirements.  This
-			// ensures that something like zones from an instance type don't expand the universe of valid domains.
-			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Spec.Template.Spec.Requirements...)
-			requirements.Add(scheduling.NewLabelRequirements(np.Spec.Template.Labels).Values()...)
-			requirements.Add(it.Requirements.Values()...)
-
-			for key, requirement := range requirements {
-				// This code used to execute a Union between domains[key] and requirement.Values().
-				// The downside of this is that Union is immutable and takes a copy of the set it is executed upon.
-				// This resulted in a lot of memory pressure on the heap and poor performance
-				// https://github.com/aws/karpenter/issues/3565
-				if domains[key] == nil {
-					domains[key] = sets.New(requirement.Values()...)
-				} else {
-					domains[key].Insert(requirement.Values()...)
-				}
-			}
-		}
-
-		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Spec.Template.Spec.Requirements...)
-		requirements.Add(scheduling.NewLabelRequirements(np.Spec.Template.Labels).Values()...)
-		for key, requirement := range requirements {
-			if requirement.Operator() == corev1.NodeSelectorOpIn {
-				// The following is a performance optimisation, for the explanation see the comment above
-				if domains[key] == nil {
-					domains[key] = sets.New(requirement.Values()...)
-				} else {
-					domains[key].Insert(requirement.Values()...)
-				}
-			}
-		}
-	}
-
-	// inject topology constraints
-	pods = p.injectVolumeTopologyRequirements(ctx, pods)
-
-	// Calculate cluster topology
-	topology, err := scheduler.NewTopology(ctx, p.kubeClient, p.cluster, domains, pods)
+func (p *Provisioner) newScheduler(ctx context.Context, pods []*corev1.Pod, stateNodes []*state.StateNode, nodePools []*v1.NodePool, opts ...option.Function[simulation.Options]) (*scheduler.Scheduler, error) {
+	// Offerings that launched nodes which never went Ready are treated as unavailable so that we retry
+	// with a different instance type, zone or capacity type
+	input, err := p.simulator.Build(ctx, pods, nodePools, append([]option.Function[simulation.Options]{simulation.WithInstanceTypeFilter(func(instanceTypes []*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+		return p.unavailableOfferings.Apply(p.unhealthyOfferings.Apply(instanceTypes))
+	})}, opts...)...)
 	if err != nil {
-		return nil, fmt.Errorf("tracking topology counts, %w", err)
+		return nil, err
 	}
-	daemonSetPods, err := p.getDaemonSetPods(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("getting daemon pods, %w", err)
-	}
-	return scheduler.NewScheduler(ctx, p.kubeClient, nodePools, p.cluster, stateNodes, topology, instanceTypes, daemonSetPods, p.recorder, p.clock), nil
+	return scheduler.NewSimulationScheduler(ctx, p.kubeClient, p.cluster, stateNodes, input, p.recorder, p.clock)
 }
 
 func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
@@ -327,12 +377,14 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 	if err != nil {
 		return scheduler.Results{}, err
 	}
+	deletingNodePods = lo.Filter(deletingNodePods, func(po *corev1.Pod, _ int) bool { return TriggersProvisioning(ctx, po) })
 	pods := append(pendingPods, deletingNodePods...)
 	// nothing to schedule, so just return success
 	if len(pods) == 0 {
+		p.explanations.Update(scheduler.Results{})
 		return scheduler.Results{}, nil
 	}
-	s, err := p.NewScheduler(ctx, pods, nodes.Active())
+	nodePools, err := p.schedulableNodePools(ctx)
 	if err != nil {
 		if errors.Is(err, ErrNodePoolsNotFound) {
 			log.FromContext(ctx).Info("no nodepools found")
@@ -340,20 +392,105 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 		}
 		return scheduler.Results{}, fmt.Errorf("creating scheduler, %w", err)
 	}
-	results := s.Solve(ctx, pods).TruncateInstanceTypes(scheduler.MaxInstanceTypes)
+	results, err := p.solve(ctx, pods, nodes.Active(), nodePools)
+	if err != nil {
+		return scheduler.Results{}, err
+	}
+	results = results.TruncateInstanceTypes(scheduler.MaxInstanceTypes)
+	// Pods that were deferred because the instance types went stale are retried in a new batch with fresh instance types
+	if stale := results.StalePods(); len(stale) > 0 {
+		log.FromContext(ctx).WithValues("pods", len(stale)).Info("instance types exceeded their maximum age, deferring pods to a new batch")
+		scheduler.StaleInstanceTypesDeferredPodsTotal.Add(float64(len(stale)), map[string]string{scheduler.ControllerLabel: injection.GetControllerName(ctx)})
+		for _, pod := range stale {
+			p.Trigger(pod.UID, TriggerSourceDeferredPod)
+		}
+	}
 	scheduler.UnschedulablePodsCount.Set(float64(len(results.PodErrors)), map[string]string{scheduler.ControllerLabel: injection.GetControllerName(ctx)})
 	if len(results.NewNodeClaims) > 0 {
 		log.FromContext(ctx).WithValues("Pods", pretty.Slice(lo.Map(pods, func(p *corev1.Pod, _ int) string { return klog.KRef(p.Namespace, p.Name).String() }), 5), "duration", time.Since(start)).Info("found provisionable pod(s)")
 	}
 	// Mark in memory when these pods were marked as schedulable or when we made a decision on the pods
 	p.cluster.MarkPodSchedulingDecisions(results.PodErrors, pendingPods...)
-	results.Record(ctx, p.recorder, p.cluster)
+	p.explanations.Update(results)
+	// Pods whose nominations conflicted with an earlier batch are scheduled again right away, rather than waiting for the
+	// earlier nominations to expire
+	for _, pod := range results.Record(ctx, p.recorder, p.cluster) {
+		p.TriggerImmediately(pod.UID, TriggerSourceNominationConflict)
+	}
 	return results, nil
 }
 
+// solve schedules the pods against the NodePools. When provisioning parallelism is enabled, NodePools that don't share
+// any pods are solved by independent schedulers running concurrently and their results are merged. Each existing node
+// is only considered by a single scheduler, so that the schedulers never overcommit the same node.
+func (p *Provisioner) solve(ctx context.Context, pods []*corev1.Pod, stateNodes []*state.StateNode, nodePools []*v1.NodePool) (scheduler.Results, error) {
+	parallelism := options.FromContext(ctx).ProvisioningParallelism
+	// Cluster-wide limits are tracked per scheduler, so we can only enforce them when a single scheduler is used
+	if parallelism <= 1 || clusterLimitsEnabled(ctx) {
+		s, err := p.newScheduler(ctx, pods, stateNodes, nodePools)
+		if err != nil {
+			return scheduler.Results{}, fmt.Errorf("creating scheduler, %w", err)
+		}
+		return s.Solve(ctx, pods), nil
+	}
+	partitions := partitionByNodePool(pods, nodePools, stateNodes)
+	results := make([]scheduler.Results, len(partitions))
+	errs := make([]error, len(partitions))
+	workqueue.ParallelizeUntil(ctx, parallelism, len(partitions), func(i int) {
+		s, err := p.newScheduler(ctx, partitions[i].pods, partitions[i].nodes, partitions[i].nodePools)
+		if err != nil {
+			errs[i] = fmt.Errorf("creating scheduler, %w", err)
+			return
+		}
+		results[i] = s.Solve(ctx, partitions[i].pods)
+	})
+	if err := multierr.Combine(errs...); err != nil {
+		return scheduler.Results{}, err
+	}
+	return mergeResults(results...), nil
+}
+
+// restrictExceededCapacityTypes prevents the NodeClaim from launching with capacity types whose scoped limits have
+// already been exceeded by the NodePool, returning an error if none of the NodeClaim's capacity types are left
+func restrictExceededCapacityTypes(nodePool *v1.NodePool, n *scheduler.NodeClaim) error {
+	var exceeded []string
+	var errs error
+	for capacityType, limits := range nodePool.Spec.Limits.ByCapacityType() {
+		if err := v1.Limits(limits).ExceededBy(nodePool.Status.ResourcesByCapacityType[capacityType]); err != nil {
+			exceeded = append(exceeded, capacityType)
+			errs = multierr.Append(errs, fmt.Errorf("%s capacity type, %w", capacityType, err))
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	requirement := scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpNotIn, exceeded...)
+	if err := n.Requirements.Compatible(scheduling.NewRequirements(requirement), scheduling.AllowUndefinedWellKnownLabels); err != nil {
+		return errs
+	}
+	n.Requirements.Add(requirement)
+	return nil
+}
+
+// namingPolicy returns the NamingPolicy parsed from the options. The templates are only parsed again when the options
+// change, which only happens in tests since the options are fixed for the lifetime of the process.
+func (p *Provisioner) namingPolicy(opts *options.Options) (*NamingPolicy, error) {
+	p.namingMu.Lock()
+	defer p.namingMu.Unlock()
+
+	if p.naming == nil || !p.naming.ParsedFrom(opts) {
+		naming, err := NewNamingPolicy(opts)
+		if err != nil {
+			return nil, err
+		}
+		p.naming = naming
+	}
+	return p.naming, nil
+}
+
 func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts ...option.Function[LaunchOptions]) (string, error) {
 	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("NodePool", klog.KRef("", n.NodePoolName)))
-	options := option.Resolve(opts...)
+	launchOpts := option.Resolve(opts...)
 	latest := &v1.NodePool{}
 	if err := p.kubeClient.Get(ctx, types.NamespacedName{Name: n.NodePoolName}, latest); err != nil {
 		return "", fmt.Errorf("getting current resource usage, %w", err)
@@ -361,9 +498,23 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 	if err := latest.Spec.Limits.ExceededBy(latest.Status.Resources); err != nil {
 		return "", err
 	}
+	if err := restrictExceededCapacityTypes(latest, n); err != nil {
+		return "", err
+	}
+	naming, err := p.namingPolicy(options.FromContext(ctx))
+	if err != nil {
+		return "", err
+	}
 	nodeClaim := n.ToNodeClaim()
+	if err = naming.Apply(nodeClaim, NamingDataFor(options.FromContext(ctx), n)); err != nil {
+		return "", fmt.Errorf("applying nodeclaim naming policy, %w", err)
+	}
+	nodeClaim, err = p.runNodeClaimHooks(ctx, nodeClaim)
+	if err != nil {
+		return "", err
+	}
 
-	if err := p.kubeClient.Create(ctx, nodeClaim); err != nil {
+	if err = p.kubeClient.Create(ctx, nodeClaim); err != nil {
 		return "", err
 	}
 	instanceTypeRequirement, _ := lo.Find(nodeClaim.Spec.Requirements, func(req v1.NodeSelectorRequirementWithMinValues) bool {
@@ -373,7 +524,7 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 	log.FromContext(ctx).WithValues("NodeClaim", klog.KRef("", nodeClaim.Name), "requests", nodeClaim.Spec.Resources.Requests, "instance-types", instanceTypeList(instanceTypeRequirement.Values)).
 		Info("created nodeclaim")
 	metrics.NodeClaimsCreatedTotal.Inc(map[string]string{
-		metrics.ReasonLabel:       options.Reason,
+		metrics.ReasonLabel:       launchOpts.Reason,
 		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
 		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
 	})
@@ -387,6 +538,9 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 		for _, pod := range n.Pods {
 			p.recorder.Publish(scheduler.NominatePodEvent(pod, nil, nodeClaim))
 		}
+		if options.FromContext(ctx).FeatureGates.PodBinding {
+			p.cluster.NominatePodsToNodeClaim(nodeClaim.Name, n.Pods...)
+		}
 	}
 	return nodeClaim.Name, nil
 }
@@ -406,42 +560,55 @@ func instanceTypeList(names []string) string {
 	return itSb.String()
 }
 
-func (p *Provisioner) getDaemonSetPods(ctx context.Context) ([]*corev1.Pod, error) {
-	daemonSetList := &appsv1.DaemonSetList{}
-	if err := p.kubeClient.List(ctx, daemonSetList); err != nil {
-		return nil, fmt.Errorf("listing daemonsets, %w", err)
-	}
-
-	return lo.Map(daemonSetList.Items, func(d appsv1.DaemonSet, _ int) *corev1.Pod {
-		pod := p.cluster.GetDaemonSetPod(&d)
-		if pod == nil {
-			pod = &corev1.Pod{Spec: d.Spec.Template.Spec}
-		}
-		// Replacing retrieved pod affinity with daemonset pod template required node affinity since this is overridden
-		// by the daemonset controller during pod creation
-		// https://github.com/kubernetes/kubernetes/blob/c5cf0ac1889f55ab51749798bec684aed876709d/pkg/controller/daemon/util/daemonset_util.go#L176
-		if d.Spec.Template.Spec.Affinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
-			if pod.Spec.Affinity == nil {
-				pod.Spec.Affinity = &corev1.Affinity{}
-			}
-			if pod.Spec.Affinity.NodeAffinity == nil {
-				pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
-			}
-			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
-		}
-		return pod
-	}), nil
-}
-
 func (p *Provisioner) Validate(ctx context.Context, pod *corev1.Pod) error {
 	return multierr.Combine(
+		validatePriorityClass(ctx, pod),
 		validateKarpenterManagedLabelCanExist(pod),
 		validateNodeSelector(pod),
 		validateAffinity(pod),
 		p.volumeTopology.ValidatePersistentVolumeClaims(ctx, pod),
+		p.claimTopology.Validate(ctx, pod),
 	)
 }
 
+// TriggersProvisioning returns false for pods with a PriorityClass that is configured to never trigger provisioning.
+// These pods can still be scheduled to existing capacity by the kube-scheduler.
+func TriggersProvisioning(ctx context.Context, pod *corev1.Pod) bool {
+	if pod.Spec.PriorityClassName == "" {
+		return true
+	}
+	return !lo.ContainsBy(strings.Split(options.FromContext(ctx).NonProvisioningPriorityClasses, ","), func(name string) bool {
+		return strings.TrimSpace(name) == pod.Spec.PriorityClassName
+	})
+}
+
+// BypassesBatching returns true if the pod is configured to start a provisioning loop without waiting for a batching
+// window, i.e. it's in one of the bypass namespaces and matches the bypass pod selector. Pods never bypass batching
+// when neither is configured.
+func BypassesBatching(ctx context.Context, pod *corev1.Pod) bool {
+	namespaces, selector := options.FromContext(ctx).BatchBypassNamespaces, options.FromContext(ctx).BatchBypassPodSelector
+	if namespaces == "" && selector == "" {
+		return false
+	}
+	if namespaces != "" && !lo.ContainsBy(strings.Split(namespaces, ","), func(namespace string) bool {
+		return strings.TrimSpace(namespace) == pod.Namespace
+	}) {
+		return false
+	}
+	podSelector, err := labels.Parse(selector)
+	if err != nil {
+		return false
+	}
+	return podSelector.Matches(labels.Set(pod.Labels))
+}
+
+func validatePriorityClass(ctx context.Context, p *corev1.Pod) error {
+	if !TriggersProvisioning(ctx, p) {
+		return fmt.Errorf("priority class %q is configured to not trigger provisioning", p.Spec.PriorityClassName)
+	}
+	return nil
+}
+
 // validateKarpenterManagedLabelCanExist provides a more clear error message in the event of scheduling a pod that specifically doesn't
 // want to run on a Karpenter node (e.g. a Karpenter controller replica).
 func validateKarpenterManagedLabelCanExist(p *corev1.Pod) error {
@@ -454,18 +621,6 @@ func validateKarpenterManagedLabelCanExist(p *corev1.Pod) error {
 	return nil
 }
 
-func (p *Provisioner) injectVolumeTopologyRequirements(ctx context.Context, pods []*corev1.Pod) []*corev1.Pod {
-	var schedulablePods []*corev1.Pod
-	for _, pod := range pods {
-		if err := p.volumeTopology.Inject(ctx, pod); err != nil {
-			log.FromContext(ctx).WithValues("Pod", klog.KRef(pod.Namespace, pod.Name)).Error(err, "failed getting volume topology requirements")
-		} else {
-			schedulablePods = append(schedulablePods, pod)
-		}
-	}
-	return schedulablePods
-}
-
 func validateNodeSelector(p *corev1.Pod) (errs error) {
 	terms := lo.MapToSlice(p.Spec.NodeSelector, func(k string, v string) corev1.NodeSelectorTerm {
 		return corev1.NodeSelectorTerm{
@@ -0,0 +1,166 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+const (
+	clusterLimitNodes      = "nodes"
+	clusterLimitCPU        = "cpu"
+	clusterLimitHourlyCost = "hourly_cost"
+)
+
+// ClusterLimitsExceededError is returned when launching a new NodeClaim would exceed one of the limits that
+// are configured across all NodePools in the cluster
+type ClusterLimitsExceededError struct {
+	limit string
+}
+
+func NewClusterLimitsExceededError(limit string) ClusterLimitsExceededError {
+	return ClusterLimitsExceededError{limit: limit}
+}
+
+func (e ClusterLimitsExceededError) Error() string {
+	return fmt.Sprintf("launching a nodeclaim would exceed the cluster %s limit", e.limit)
+}
+
+func IsClusterLimitsExceededError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.As(err, &ClusterLimitsExceededError{})
+}
+
+// ClusterLimits tracks the capacity that remains under the cluster-wide node, cpu and hourly cost limits during a
+// scheduling simulation. A nil remaining value means that the limit isn't configured.
+type ClusterLimits struct {
+	remainingNodes      *int
+	remainingCPU        *resource.Quantity
+	remainingHourlyCost *float64
+}
+
+// NewClusterLimits computes the remaining capacity under the cluster-wide limits after accounting for the
+// nodes that are already part of the cluster. Nodes that are being deleted aren't passed to the scheduler
+// so their capacity doesn't count against the limits.
+func NewClusterLimits(ctx context.Context, stateNodes []*state.StateNode, instanceTypes map[string][]*cloudprovider.InstanceType) *ClusterLimits {
+	opts := options.FromContext(ctx)
+	l := &ClusterLimits{}
+	if opts.MaxNodes >= 0 {
+		l.remainingNodes = lo.ToPtr(opts.MaxNodes - len(stateNodes))
+	}
+	if opts.MaxCPU >= 0 {
+		cpu := resource.NewQuantity(opts.MaxCPU, resource.DecimalSI)
+		for _, n := range stateNodes {
+			cpu.Sub(n.Capacity()[corev1.ResourceCPU])
+		}
+		l.remainingCPU = cpu
+	}
+	if opts.MaxHourlyCost >= 0 {
+		cost := opts.MaxHourlyCost
+		for _, n := range stateNodes {
+			cost -= nodePrice(n, instanceTypes[n.Labels()[v1.NodePoolLabelKey]])
+		}
+		l.remainingHourlyCost = &cost
+	}
+	return l
+}
+
+// Filter removes the instance types that would exceed the cluster-wide limits if they were launched, returning
+// an error if none of the instance types can be launched
+func (l *ClusterLimits) Filter(instanceTypes []*cloudprovider.InstanceType) ([]*cloudprovider.InstanceType, error) {
+	if l.remainingNodes != nil && *l.remainingNodes <= 0 {
+		return nil, NewClusterLimitsExceededError(clusterLimitNodes)
+	}
+	if l.remainingCPU != nil {
+		instanceTypes = lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+			return resources.Cmp(it.Capacity[corev1.ResourceCPU], *l.remainingCPU) <= 0
+		})
+		if len(instanceTypes) == 0 {
+			return nil, NewClusterLimitsExceededError(clusterLimitCPU)
+		}
+	}
+	if l.remainingHourlyCost != nil {
+		instanceTypes = lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+			return launchPrice(it) <= *l.remainingHourlyCost
+		})
+		if len(instanceTypes) == 0 {
+			return nil, NewClusterLimitsExceededError(clusterLimitHourlyCost)
+		}
+	}
+	return instanceTypes, nil
+}
+
+// Subtract pessimistically assumes that the largest and most expensive of the instance type options will be
+// launched for the NodeClaim, similar to how NodePool limits are tracked
+func (l *ClusterLimits) Subtract(instanceTypes []*cloudprovider.InstanceType) {
+	if len(instanceTypes) == 0 {
+		return
+	}
+	if l.remainingNodes != nil {
+		*l.remainingNodes--
+	}
+	if l.remainingCPU != nil {
+		l.remainingCPU.Sub(resources.MaxResources(lo.Map(instanceTypes, func(it *cloudprovider.InstanceType, _ int) corev1.ResourceList {
+			return it.Capacity
+		})...)[corev1.ResourceCPU])
+	}
+	if l.remainingHourlyCost != nil {
+		*l.remainingHourlyCost -= lo.Max(lo.Map(instanceTypes, func(it *cloudprovider.InstanceType, _ int) float64 {
+			return launchPrice(it)
+		}))
+	}
+}
+
+// launchPrice is the price of the cheapest available offering for the instance type
+func launchPrice(it *cloudprovider.InstanceType) float64 {
+	offerings := it.Offerings.Available()
+	if len(offerings) == 0 {
+		return 0
+	}
+	return offerings.Cheapest().Price
+}
+
+// nodePrice is the price of the offering that the node was launched with, or zero if the offering
+// can't be resolved (e.g. the node isn't managed by Karpenter)
+func nodePrice(n *state.StateNode, instanceTypes []*cloudprovider.InstanceType) float64 {
+	it, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+		return it.Name == n.Labels()[corev1.LabelInstanceTypeStable]
+	})
+	if !ok {
+		return 0
+	}
+	offerings := it.Offerings.Compatible(scheduling.NewLabelRequirements(n.Labels()))
+	if len(offerings) == 0 {
+		return 0
+	}
+	return offerings.Cheapest().Price
+}
@@ -42,7 +42,7 @@ func NominatePodEvent(pod *corev1.Pod, node *corev1.Node, nodeClaim *v1.NodeClai
 	return events.Event{
 		InvolvedObject: pod,
 		Type:           corev1.EventTypeNormal,
-		Reason:         "Nominated",
+		Reason:         events.Nominated,
 		Message:        fmt.Sprintf("Pod should schedule on: %s", strings.Join(info, ", ")),
 		DedupeValues:   []string{string(pod.UID)},
 		RateLimiter:    PodNominationRateLimiter,
@@ -53,7 +53,7 @@ func NoCompatibleInstanceTypes(np *v1.NodePool) events.Event {
 	return events.Event{
 		InvolvedObject: np,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "NoCompatibleInstanceTypes",
+		Reason:         events.NoCompatibleInstanceTypes,
 		Message:        "NodePool requirements filtered out all compatible available instance types",
 		DedupeValues:   []string{string(np.UID)},
 		DedupeTimeout:  1 * time.Minute,
@@ -64,9 +64,20 @@ func PodFailedToScheduleEvent(pod *corev1.Pod, err error) events.Event {
 	return events.Event{
 		InvolvedObject: pod,
 		Type:           corev1.EventTypeWarning,
-		Reason:         "FailedScheduling",
+		Reason:         events.FailedScheduling,
 		Message:        fmt.Sprintf("Failed to schedule pod, %s", err),
 		DedupeValues:   []string{string(pod.UID)},
 		DedupeTimeout:  5 * time.Minute,
 	}
 }
+
+func ClusterLimitsExceededEvent(pod *corev1.Pod, err error) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.ClusterLimitsExceeded,
+		Message:        fmt.Sprintf("Provisioning blocked by cluster limits, %s", err),
+		DedupeValues:   []string{string(pod.UID)},
+		DedupeTimeout:  5 * time.Minute,
+	}
+}
@@ -54,7 +54,7 @@ func NewExistingNode(n *state.StateNode, topology *Topology, taints []v1.Taint,
 	}
 	node := &ExistingNode{
 		StateNode:       n,
-		cachedAvailable: n.Available(),
+		cachedAvailable: resources.Subtract(n.Available(), n.ReservedResources()),
 		cachedTaints:    taints,
 		topology:        topology,
 		requests:        remainingDaemonResources,
@@ -65,23 +65,42 @@ func NewExistingNode(n *state.StateNode, topology *Topology, taints []v1.Taint,
 	return node
 }
 
+// existingNodeUpdate is the state that an ExistingNode takes on once a pod is added to it
+type existingNodeUpdate struct {
+	requests     v1.ResourceList
+	requirements scheduling.Requirements
+	hostPorts    []scheduling.HostPort
+	volumes      scheduling.Volumes
+}
+
 func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v1.Pod, podRequests v1.ResourceList) error {
+	update, err := n.canAdd(ctx, kubeClient, pod, podRequests)
+	if err != nil {
+		return err
+	}
+	n.add(pod, update)
+	return nil
+}
+
+// canAdd checks whether the pod can be added to the node without modifying the node, returning the state that the
+// node takes on if it is
+func (n *ExistingNode) canAdd(ctx context.Context, kubeClient client.Client, pod *v1.Pod, podRequests v1.ResourceList) (*existingNodeUpdate, error) {
 	// Check Taints
 	if err := scheduling.Taints(n.cachedTaints).Tolerates(pod); err != nil {
-		return err
+		return nil, err
 	}
 	// determine the volumes that will be mounted if the pod schedules
 	volumes, err := scheduling.GetVolumes(ctx, kubeClient, pod)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// determine the host ports that will be used if the pod schedules
 	hostPorts := scheduling.GetHostPorts(pod)
 	if err = n.VolumeUsage().ExceedsLimits(volumes); err != nil {
-		return fmt.Errorf("checking volume usage, %w", err)
+		return nil, fmt.Errorf("checking volume usage, %w", err)
 	}
 	if err = n.HostPortUsage().Conflicts(pod, hostPorts); err != nil {
-		return fmt.Errorf("checking host port usage, %w", err)
+		return nil, fmt.Errorf("checking host port usage, %w", err)
 	}
 
 	// check resource requests first since that's a pretty likely reason the pod won't schedule on an in-flight
@@ -89,14 +108,14 @@ func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v
 	requests := resources.Merge(n.requests, podRequests)
 
 	if !resources.Fits(requests, n.cachedAvailable) {
-		return fmt.Errorf("exceeds node resources")
+		return nil, fmt.Errorf("exceeds node resources")
 	}
 
 	nodeRequirements := scheduling.NewRequirements(n.requirements.Values()...)
 	podRequirements := scheduling.NewPodRequirements(pod)
 	// Check NodeClaim Affinity Requirements
 	if err = nodeRequirements.Compatible(podRequirements); err != nil {
-		return err
+		return nil, err
 	}
 	nodeRequirements.Add(podRequirements.Values()...)
 
@@ -110,19 +129,22 @@ func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v
 	// Check Topology Requirements
 	topologyRequirements, err := n.topology.AddRequirements(strictPodRequirements, nodeRequirements, pod)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err = nodeRequirements.Compatible(topologyRequirements); err != nil {
-		return err
+		return nil, err
 	}
 	nodeRequirements.Add(topologyRequirements.Values()...)
 
-	// Update node
+	return &existingNodeUpdate{requests: requests, requirements: nodeRequirements, hostPorts: hostPorts, volumes: volumes}, nil
+}
+
+// add updates the node with a pod that canAdd has accepted
+func (n *ExistingNode) add(pod *v1.Pod, update *existingNodeUpdate) {
 	n.Pods = append(n.Pods, pod)
-	n.requests = requests
-	n.requirements = nodeRequirements
-	n.topology.Record(pod, nodeRequirements)
-	n.HostPortUsage().Add(pod, hostPorts)
-	n.VolumeUsage().Add(pod, volumes)
-	return nil
+	n.requests = update.requests
+	n.requirements = update.requirements
+	n.topology.Record(pod, update.requirements)
+	n.HostPortUsage().Add(pod, update.hostPorts)
+	n.VolumeUsage().Add(pod, update.volumes)
 }
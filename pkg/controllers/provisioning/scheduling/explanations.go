@@ -0,0 +1,179 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// RequirementIncompatibility describes a single requirement key on which a pod and a NodePool disagree
+type RequirementIncompatibility struct {
+	Key string `json:"key"`
+	// Existing is the requirement that the NodePool places on the key, empty if the NodePool doesn't define the key
+	Existing string `json:"existing,omitempty"`
+	// Incoming is the requirement that the pod places on the key
+	Incoming string `json:"incoming"`
+}
+
+func (r RequirementIncompatibility) String() string {
+	if r.Existing == "" {
+		return fmt.Sprintf("key %q: pod requires %q, but the nodepool doesn't define the key", r.Key, r.Incoming)
+	}
+	return fmt.Sprintf("key %q: pod requires %q, but the nodepool requires %q", r.Key, r.Incoming, r.Existing)
+}
+
+// NodePoolIncompatibleError is returned when a pod can't schedule against a NodePool. When the failure was caused by
+// the pod's requirements, Requirements breaks the failure down by the requirement keys that didn't match.
+type NodePoolIncompatibleError struct {
+	NodePool       string                       `json:"nodePool"`
+	DaemonOverhead string                       `json:"daemonSetOverhead"`
+	Requirements   []RequirementIncompatibility `json:"requirements,omitempty"`
+	err            error
+}
+
+func NewNodePoolIncompatibleError(nodePool string, daemonOverhead corev1.ResourceList, err error) NodePoolIncompatibleError {
+	return NodePoolIncompatibleError{
+		NodePool:       nodePool,
+		DaemonOverhead: resources.String(daemonOverhead),
+		Requirements: lo.Map(scheduling.IncompatibleKeys(err), func(e scheduling.IncompatibleKeyError, _ int) RequirementIncompatibility {
+			r := RequirementIncompatibility{Key: e.Key, Incoming: e.Incoming.String()}
+			if e.Existing != nil {
+				r.Existing = e.Existing.String()
+			}
+			return r
+		}),
+		err: err,
+	}
+}
+
+func (e NodePoolIncompatibleError) Error() string {
+	if len(e.Requirements) == 0 {
+		return fmt.Sprintf("incompatible with nodepool %q, daemonset overhead=%s, %s", e.NodePool, e.DaemonOverhead, e.err)
+	}
+	return fmt.Sprintf("incompatible with nodepool %q, daemonset overhead=%s, incompatible requirements: %s", e.NodePool, e.DaemonOverhead,
+		strings.Join(lo.Map(e.Requirements, func(r RequirementIncompatibility, _ int) string { return r.String() }), "; "))
+}
+
+func (e NodePoolIncompatibleError) Unwrap() error {
+	return e.err
+}
+
+// MarshalJSON includes the underlying error so that failures that aren't caused by requirements are still explained
+func (e NodePoolIncompatibleError) MarshalJSON() ([]byte, error) {
+	type explanation NodePoolIncompatibleError
+	return json.Marshal(struct {
+		explanation
+		Error string `json:"error"`
+	}{explanation(e), e.err.Error()})
+}
+
+// PodExplanation explains why a pod couldn't be scheduled, broken down by the NodePools that rejected it
+type PodExplanation struct {
+	Pod       string                      `json:"pod"`
+	Error     string                      `json:"error"`
+	NodePools []NodePoolIncompatibleError `json:"nodePools,omitempty"`
+}
+
+// NewPodExplanation collects the NodePoolIncompatibleErrors from a pod's scheduling error
+func NewPodExplanation(pod *corev1.Pod, err error) PodExplanation {
+	return PodExplanation{
+		Pod:       types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}.String(),
+		Error:     err.Error(),
+		NodePools: nodePoolIncompatibleErrors(err),
+	}
+}
+
+func nodePoolIncompatibleErrors(err error) []NodePoolIncompatibleError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case NodePoolIncompatibleError:
+		return []NodePoolIncompatibleError{e}
+	case interface{ Unwrap() []error }:
+		return lo.FlatMap(e.Unwrap(), func(err error, _ int) []NodePoolIncompatibleError { return nodePoolIncompatibleErrors(err) })
+	default:
+		return nodePoolIncompatibleErrors(errors.Unwrap(err))
+	}
+}
+
+// Explanations stores the explanations for the pods that failed to schedule in the most recent scheduling run and
+// serves them over HTTP for introspection
+type Explanations struct {
+	mu   sync.RWMutex
+	pods map[string]PodExplanation
+}
+
+func NewExplanations() *Explanations {
+	return &Explanations{pods: map[string]PodExplanation{}}
+}
+
+// Update replaces the stored explanations with those for the pod errors of the given scheduling results
+func (e *Explanations) Update(results Results) {
+	pods := make(map[string]PodExplanation, len(results.PodErrors))
+	for pod, err := range results.PodErrors {
+		explanation := NewPodExplanation(pod, err)
+		pods[explanation.Pod] = explanation
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pods = pods
+}
+
+// Get returns the explanation for the pod with the given namespace/name, if it failed to schedule
+func (e *Explanations) Get(pod string) (PodExplanation, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	explanation, ok := e.pods[pod]
+	return explanation, ok
+}
+
+// ServeHTTP responds with all stored explanations, or only the explanation for the pod passed through the "pod"
+// query parameter in namespace/name form
+func (e *Explanations) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body any
+	if pod := r.URL.Query().Get("pod"); pod != "" {
+		explanation, ok := e.Get(pod)
+		if !ok {
+			http.Error(w, fmt.Sprintf("no scheduling explanation for pod %q", pod), http.StatusNotFound)
+			return
+		}
+		body = explanation
+	} else {
+		e.mu.RLock()
+		explanations := lo.Values(e.pods)
+		e.mu.RUnlock()
+		sort.Slice(explanations, func(i, j int) bool { return explanations[i].Pod < explanations[j].Pod })
+		body = explanations
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -642,6 +643,100 @@ var _ = Describe("Instance Type Selection", func() {
 		node := ExpectScheduled(ctx, env.Client, pod)
 		Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("test-instance1"))
 	})
+	It("should annotate the NodeClaim with the cheapest instance type as the predicted instance type", func() {
+		cloudProvider.InstanceTypes = lo.Map([]string{"test-instance1", "test-instance2"}, func(name string, i int) *cloudprovider.InstanceType {
+			return fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: name,
+				Offerings: []cloudprovider.Offering{
+					{Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-1"}), Price: float64(2 - i), Available: true},
+				},
+			})
+		})
+		nodePool.Spec.Template.Spec.Requirements = nil
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		ExpectScheduled(ctx, env.Client, pod)
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		Expect(cloudProvider.CreateCalls[0].Annotations).To(HaveKeyWithValue(v1.PredictedInstanceTypeAnnotationKey, "test-instance2"))
+	})
+	It("should prefer instance types with reserved offerings over cheaper spot and on-demand offerings", func() {
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "test-instance1",
+				Offerings: []cloudprovider.Offering{
+					{Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeSpot, corev1.LabelTopologyZone: "test-zone-1"}), Price: 1, Available: true},
+					{Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-1"}), Price: 2, Available: true},
+				},
+			}),
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "test-instance2",
+				Offerings: []cloudprovider.Offering{
+					{Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-1"}), Price: 4, Available: true},
+					{Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeReserved, corev1.LabelTopologyZone: "test-zone-1"}), Price: 3, Available: true},
+				},
+			}),
+		}
+		nodePool.Spec.Template.Spec.Requirements = nil
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		node := ExpectScheduled(ctx, env.Client, pod)
+		Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("test-instance2"))
+		Expect(node.Labels[v1.CapacityTypeLabelKey]).To(Equal(v1.CapacityTypeReserved))
+	})
+	Context("Instance Family Preference", func() {
+		BeforeEach(func() {
+			cloudProvider.InstanceTypes = lo.Map([]string{"c7i.large", "m6i.large", "m7i.large"}, func(name string, i int) *cloudprovider.InstanceType {
+				return fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: name,
+					Offerings: []cloudprovider.Offering{
+						{Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand, corev1.LabelTopologyZone: "test-zone-1"}), Price: float64(i + 1), Available: true},
+					},
+				})
+			})
+			nodePool.Spec.Template.Spec.Requirements = nil
+			ExpectApplied(ctx, env.Client, nodePool)
+		})
+		It("should launch an instance type in the preferred families", func() {
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.InstanceFamilyPreferenceAnnotationKey: "m7i, m6i"},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("m6i.large"))
+			Expect(supportedInstanceTypes(cloudProvider.CreateCalls[0])).To(HaveLen(3))
+			Expect(cloudProvider.CreateCalls[0].Annotations).To(HaveKeyWithValue(v1.InstanceFamilyPreferenceAnnotationKey, "m7i,m6i"))
+		})
+		It("should fall back to the cheapest instance type when no preferred family is compatible", func() {
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.InstanceFamilyPreferenceAnnotationKey: "r7i"},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("c7i.large"))
+			Expect(supportedInstanceTypes(cloudProvider.CreateCalls[0])).To(HaveLen(3))
+		})
+		It("should keep the instance types outside the preferred families to satisfy minValues", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+						Key:      corev1.LabelInstanceTypeStable,
+						Operator: corev1.NodeSelectorOpExists,
+					},
+					MinValues: lo.ToPtr(2),
+				},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.InstanceFamilyPreferenceAnnotationKey: "m7i"},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("m7i.large"))
+			Expect(supportedInstanceTypes(cloudProvider.CreateCalls[0])).To(HaveLen(3))
+		})
+	})
 	Context("MinValues", func() {
 		It("should schedule respecting the minValues from instance-type requirements", func() {
 			var instanceTypes []*cloudprovider.InstanceType
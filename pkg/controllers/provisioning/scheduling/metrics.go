@@ -28,8 +28,15 @@ const (
 	ControllerLabel    = "controller"
 	schedulingIDLabel  = "scheduling_id"
 	schedulerSubsystem = "scheduler"
+	limitLabel         = "limit"
 )
 
+func init() {
+	for _, limit := range []string{clusterLimitNodes, clusterLimitCPU, clusterLimitHourlyCost} {
+		ClusterLimitsBlockedPodsTotal.Add(0, map[string]string{limitLabel: limit})
+	}
+}
+
 var (
 	DurationSeconds = opmetrics.NewPrometheusHistogram(
 		crmetrics.Registry,
@@ -79,6 +86,15 @@ var (
 		},
 		[]string{},
 	)
+	QuotaBlockedPodCount = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "quota_blocked_pod_count",
+			Help:      "Number of pending pods ignored during scheduling by Karpenter because a quota prevents them from scheduling",
+		},
+		[]string{},
+	)
 	UnschedulablePodsCount = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -91,4 +107,28 @@ var (
 			ControllerLabel,
 		},
 	)
+	ClusterLimitsBlockedPodsTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: schedulerSubsystem,
+			Name:      "cluster_limits_blocked_pods_total",
+			Help:      "The number of pods that failed to schedule because launching capacity for them would exceed a cluster-wide limit. Labeled by the limit that was exceeded.",
+		},
+		[]string{
+			limitLabel,
+		},
+	)
+	StaleInstanceTypesDeferredPodsTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: schedulerSubsystem,
+			Name:      "stale_instance_types_deferred_pods_total",
+			Help:      "The number of pods that were deferred to a new batch because the instance types used by the scheduling simulation exceeded their maximum age. Labeled by the controller.",
+		},
+		[]string{
+			ControllerLabel,
+		},
+	)
 )
@@ -27,6 +27,7 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
@@ -40,13 +41,18 @@ type NodeClaim struct {
 	hostPortUsage   *scheduling.HostPortUsage
 	daemonResources v1.ResourceList
 	hostname        string
+	// sequence orders the NodeClaim by when it was created during the scheduling simulation
+	sequence int64
+	// Index is the position of the NodeClaim within the NodeClaims computed by its scheduling run
+	Index int
 }
 
 var nodeID int64
 
-func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daemonResources v1.ResourceList, instanceTypes []*cloudprovider.InstanceType) *NodeClaim {
+func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daemonResources v1.ResourceList, daemonHostPortUsage *scheduling.HostPortUsage, instanceTypes []*cloudprovider.InstanceType) *NodeClaim {
 	// Copy the template, and add hostname
-	hostname := fmt.Sprintf("hostname-placeholder-%04d", atomic.AddInt64(&nodeID, 1))
+	sequence := atomic.AddInt64(&nodeID, 1)
+	hostname := fmt.Sprintf("hostname-placeholder-%04d", sequence)
 	topology.Register(v1.LabelHostname, hostname)
 	template := *nodeClaimTemplate
 	template.Requirements = scheduling.NewRequirements()
@@ -57,30 +63,50 @@ func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daem
 
 	return &NodeClaim{
 		NodeClaimTemplate: template,
-		hostPortUsage:     scheduling.NewHostPortUsage(),
+		hostPortUsage:     daemonHostPortUsage.DeepCopy(),
 		topology:          topology,
 		daemonResources:   daemonResources,
 		hostname:          hostname,
+		sequence:          sequence,
 	}
 }
 
+// nodeClaimUpdate is the state that a NodeClaim takes on once a pod is added to it
+type nodeClaimUpdate struct {
+	requests      v1.ResourceList
+	requirements  scheduling.Requirements
+	hostPorts     []scheduling.HostPort
+	instanceTypes []*cloudprovider.InstanceType
+}
+
 func (n *NodeClaim) Add(pod *v1.Pod, podRequests v1.ResourceList) error {
+	update, err := n.canAdd(pod, podRequests)
+	if err != nil {
+		return err
+	}
+	n.add(pod, update)
+	return nil
+}
+
+// canAdd checks whether the pod can be added to the NodeClaim without modifying the NodeClaim, returning the state
+// that the NodeClaim takes on if it is
+func (n *NodeClaim) canAdd(pod *v1.Pod, podRequests v1.ResourceList) (*nodeClaimUpdate, error) {
 	// Check Taints
 	if err := scheduling.Taints(n.Spec.Taints).Tolerates(pod); err != nil {
-		return err
+		return nil, err
 	}
 
 	// exposed host ports on the node
 	hostPorts := scheduling.GetHostPorts(pod)
 	if err := n.hostPortUsage.Conflicts(pod, hostPorts); err != nil {
-		return fmt.Errorf("checking host port usage, %w", err)
+		return nil, fmt.Errorf("checking host port usage, %w", err)
 	}
 	nodeClaimRequirements := scheduling.NewRequirements(n.Requirements.Values()...)
 	podRequirements := scheduling.NewPodRequirements(pod)
 
 	// Check NodeClaim Affinity Requirements
 	if err := nodeClaimRequirements.Compatible(podRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
-		return fmt.Errorf("incompatible requirements, %w", err)
+		return nil, fmt.Errorf("incompatible requirements, %w", err)
 	}
 	nodeClaimRequirements.Add(podRequirements.Values()...)
 
@@ -93,10 +119,10 @@ func (n *NodeClaim) Add(pod *v1.Pod, podRequests v1.ResourceList) error {
 	// Check Topology Requirements
 	topologyRequirements, err := n.topology.AddRequirements(strictPodRequirements, nodeClaimRequirements, pod, scheduling.AllowUndefinedWellKnownLabels)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err = nodeClaimRequirements.Compatible(topologyRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
-		return err
+		return nil, err
 	}
 	nodeClaimRequirements.Add(topologyRequirements.Values()...)
 
@@ -108,17 +134,45 @@ func (n *NodeClaim) Add(pod *v1.Pod, podRequests v1.ResourceList) error {
 	if len(filtered.remaining) == 0 {
 		// log the total resources being requested (daemonset + the pod)
 		cumulativeResources := resources.Merge(n.daemonResources, podRequests)
-		return fmt.Errorf("no instance type satisfied resources %s and requirements %s (%s)", resources.String(cumulativeResources), nodeClaimRequirements, filtered.FailureReason())
+		return nil, fmt.Errorf("no instance type satisfied resources %s and requirements %s (%s)", resources.String(cumulativeResources), nodeClaimRequirements, filtered.FailureReason())
+	}
+	// Pods that would grow the NodeClaim past the soft max node size go to another NodeClaim instead. Only the first
+	// pod on a NodeClaim can exceed it, since there's no smaller node that it could go to.
+	instanceTypes := filtered.remaining
+	if len(n.SoftMaxNodeSize) > 0 {
+		within := filterBySoftMaxNodeSize(instanceTypes, nodeClaimRequirements, n.SoftMaxNodeSize)
+		if len(within) == 0 && len(n.Pods) > 0 {
+			return nil, fmt.Errorf("no instance type satisfied resources %s within the soft max node size %s", resources.String(requests), resources.String(n.SoftMaxNodeSize))
+		}
+		if len(within) > 0 {
+			instanceTypes = within
+		}
 	}
 
-	// Update node
+	return &nodeClaimUpdate{requests: requests, requirements: nodeClaimRequirements, hostPorts: hostPorts, instanceTypes: instanceTypes}, nil
+}
+
+// filterBySoftMaxNodeSize returns the instance types whose capacity is within the soft max node size, or nothing if
+// they wouldn't satisfy minValues
+func filterBySoftMaxNodeSize(instanceTypes []*cloudprovider.InstanceType, reqs scheduling.Requirements, softMaxNodeSize v1.ResourceList) cloudprovider.InstanceTypes {
+	within := cloudprovider.InstanceTypes(lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+		return resources.Fits(lo.PickByKeys(it.Capacity, lo.Keys(softMaxNodeSize)), softMaxNodeSize)
+	}))
+	if _, err := within.SatisfiesMinValues(reqs); err != nil {
+		return nil
+	}
+	return within
+}
+
+// add updates the NodeClaim with a pod that canAdd has accepted
+func (n *NodeClaim) add(pod *v1.Pod, update *nodeClaimUpdate) {
 	n.Pods = append(n.Pods, pod)
-	n.InstanceTypeOptions = filtered.remaining
-	n.Spec.Resources.Requests = requests
-	n.Requirements = nodeClaimRequirements
-	n.topology.Record(pod, nodeClaimRequirements, scheduling.AllowUndefinedWellKnownLabels)
-	n.hostPortUsage.Add(pod, hostPorts)
-	return nil
+	n.InstanceTypeOptions = update.instanceTypes
+	n.InstanceFamilyPreferences = lo.Uniq(append(n.InstanceFamilyPreferences, podutils.InstanceFamilyPreferences(pod)...))
+	n.Spec.Resources.Requests = update.requests
+	n.Requirements = update.requirements
+	n.topology.Record(pod, update.requirements, scheduling.AllowUndefinedWellKnownLabels)
+	n.hostPortUsage.Add(pod, update.hostPorts)
 }
 
 func (n *NodeClaim) Destroy() {
@@ -18,6 +18,8 @@ package scheduling
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/awslabs/operatorpkg/object"
 	"github.com/samber/lo"
@@ -42,20 +44,34 @@ type NodeClaimTemplate struct {
 
 	NodePoolName        string
 	NodePoolUUID        types.UID
+	NodePoolGeneration  int64
+	ZoneBalancing       v1.ZoneBalancing
+	SoftMaxNodeSize     corev1.ResourceList
 	InstanceTypeOptions cloudprovider.InstanceTypes
 	Requirements        scheduling.Requirements
+
+	// ConditionalStartupTaints are added to the NodeClaim's startup taints if every instance type option matches them
+	ConditionalStartupTaints []v1.ConditionalStartupTaints
+	// InstanceFamilyPreferences are the instance families that the NodeClaim's pods prefer. The instance type options
+	// in these families are ordered ahead of the others, and the cloud provider is passed the preference to launch them.
+	InstanceFamilyPreferences []string
 }
 
 func NewNodeClaimTemplate(nodePool *v1.NodePool) *NodeClaimTemplate {
 	nct := &NodeClaimTemplate{
-		NodeClaim:    *nodePool.Spec.Template.ToNodeClaim(),
-		NodePoolName: nodePool.Name,
-		NodePoolUUID: nodePool.UID,
-		Requirements: scheduling.NewRequirements(),
+		NodeClaim:                *nodePool.Spec.Template.ToNodeClaim(),
+		NodePoolName:             nodePool.Name,
+		NodePoolUUID:             nodePool.UID,
+		NodePoolGeneration:       nodePool.Generation,
+		ZoneBalancing:            nodePool.Spec.ZoneBalancing,
+		SoftMaxNodeSize:          nodePool.Spec.SoftMaxNodeSize,
+		ConditionalStartupTaints: nodePool.Spec.Template.Spec.ConditionalStartupTaints,
+		Requirements:             scheduling.NewRequirements(),
 	}
 	nct.Annotations = lo.Assign(nct.Annotations, map[string]string{
-		v1.NodePoolHashAnnotationKey:        nodePool.Hash(),
-		v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+		v1.NodePoolHashAnnotationKey:           nodePool.Hash(),
+		v1.NodePoolHashVersionAnnotationKey:    v1.NodePoolHashVersion,
+		v1.NodePoolTemplateHashesAnnotationKey: nodePool.TemplateHashes(),
 	})
 	nct.Labels = lo.Assign(nct.Labels, map[string]string{
 		v1.NodePoolLabelKey: nodePool.Name,
@@ -63,21 +79,32 @@ func NewNodeClaimTemplate(nodePool *v1.NodePool) *NodeClaimTemplate {
 	})
 	nct.Requirements.Add(scheduling.NewNodeSelectorRequirementsWithMinValues(nct.Spec.Requirements...).Values()...)
 	nct.Requirements.Add(scheduling.NewLabelRequirements(nct.Labels).Values()...)
+	nct.Requirements.Add(scheduling.NewTopologyDomainRequirements(nodePool.Spec.TopologyDomains).Values()...)
 	return nct
 }
 
 func (i *NodeClaimTemplate) ToNodeClaim() *v1.NodeClaim {
 	// Order the instance types by price and only take the first 100 of them to decrease the instance type size in the requirements
-	instanceTypes := lo.Slice(i.InstanceTypeOptions.OrderByPrice(i.Requirements), 0, MaxInstanceTypes)
+	instanceTypes := lo.Slice(i.InstanceTypeOptions.OrderByPrice(i.Requirements).OrderByFamilyPreference(i.InstanceFamilyPreferences), 0, MaxInstanceTypes)
 	i.Requirements.Add(scheduling.NewRequirementWithFlexibility(corev1.LabelInstanceTypeStable, corev1.NodeSelectorOpIn, i.Requirements.Get(corev1.LabelInstanceTypeStable).MinValues, lo.Map(instanceTypes, func(i *cloudprovider.InstanceType, _ int) string {
 		return i.Name
 	})...))
 
+	annotations := i.Annotations
+	// The cheapest instance type is the one that we expect the cloud provider to launch
+	if len(instanceTypes) > 0 {
+		annotations = lo.Assign(annotations, map[string]string{v1.PredictedInstanceTypeAnnotationKey: instanceTypes[0].Name})
+	}
+	if len(i.InstanceFamilyPreferences) > 0 {
+		annotations = lo.Assign(annotations, map[string]string{v1.InstanceFamilyPreferenceAnnotationKey: strings.Join(i.InstanceFamilyPreferences, ",")})
+	}
 	nc := &v1.NodeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: fmt.Sprintf("%s-", i.NodePoolName),
-			Annotations:  i.Annotations,
-			Labels:       i.Labels,
+			Annotations:  annotations,
+			// The generation label isn't part of the template's requirements since NodeClaims can't require labels
+			// in the karpenter.sh domain that aren't well known
+			Labels: lo.Assign(i.Labels, map[string]string{v1.NodePoolGenerationLabelKey: strconv.FormatInt(i.NodePoolGeneration, 10)}),
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion:         object.GVK(&v1.NodePool{}).GroupVersion().String(),
@@ -90,6 +117,33 @@ func (i *NodeClaimTemplate) ToNodeClaim() *v1.NodeClaim {
 		},
 		Spec: i.Spec,
 	}
+	// The scheduler assigns a name when deterministic NodeClaim names are enabled
+	if i.Name != "" {
+		nc.GenerateName = ""
+		nc.Name = i.Name
+	}
 	nc.Spec.Requirements = i.Requirements.NodeSelectorRequirements()
+	if taints := i.conditionalStartupTaints(instanceTypes); len(taints) > 0 {
+		nc.Spec.StartupTaints = scheduling.Taints(nc.Spec.StartupTaints).Merge(taints)
+	}
 	return nc
 }
+
+// conditionalStartupTaints returns the conditional startup taints with requirements that are matched by every instance
+// type that the NodeClaim can launch with. Since we don't know which instance type the cloud provider will launch,
+// taints that only match some of the instance types aren't applied.
+func (i *NodeClaimTemplate) conditionalStartupTaints(instanceTypes []*cloudprovider.InstanceType) []corev1.Taint {
+	if len(instanceTypes) == 0 {
+		return nil
+	}
+	var taints []corev1.Taint
+	for _, conditional := range i.ConditionalStartupTaints {
+		requirements := scheduling.NewNodeSelectorRequirements(conditional.Requirements...)
+		if lo.EveryBy(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+			return it.Requirements.IsCompatible(requirements)
+		}) {
+			taints = scheduling.Taints(taints).Merge(conditional.Taints)
+		}
+	}
+	return taints
+}
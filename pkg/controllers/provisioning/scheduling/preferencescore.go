@@ -0,0 +1,225 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// preferredTerms are the preferred affinity terms of a pod as they were before any of them were relaxed. kube-scheduler
+// scores every feasible node against all of these terms when it binds the pod, so we score our candidates the same way.
+type preferredTerms struct {
+	nodeAffinity    []preferredNodeTerm
+	podAffinity     []preferredPodTerm
+	podAntiAffinity []preferredPodTerm
+}
+
+type preferredNodeTerm struct {
+	weight       int32
+	requirements scheduling.Requirements
+}
+
+// preferredPodTerm is a preferred pod (anti-)affinity term along with the topology group that tracks how many of the
+// pods that it selects are in each domain
+type preferredPodTerm struct {
+	weight int32
+	group  *TopologyGroup
+}
+
+// newPreferredTerms returns the preferred terms of the pod, or nil if the pod doesn't have any
+func newPreferredTerms(ctx context.Context, topology *Topology, pod *corev1.Pod) (*preferredTerms, error) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil {
+		return nil, nil
+	}
+	terms := &preferredTerms{}
+	if affinity.NodeAffinity != nil {
+		for _, term := range affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			terms.nodeAffinity = append(terms.nodeAffinity, preferredNodeTerm{
+				weight:       term.Weight,
+				requirements: scheduling.NewNodeSelectorRequirements(term.Preference.MatchExpressions...),
+			})
+		}
+	}
+	var err error
+	if affinity.PodAffinity != nil {
+		if terms.podAffinity, err = newPreferredPodTerms(ctx, topology, pod, TopologyTypePodAffinity, affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution); err != nil {
+			return nil, err
+		}
+	}
+	if affinity.PodAntiAffinity != nil {
+		if terms.podAntiAffinity, err = newPreferredPodTerms(ctx, topology, pod, TopologyTypePodAntiAffinity, affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution); err != nil {
+			return nil, err
+		}
+	}
+	if len(terms.nodeAffinity) == 0 && len(terms.podAffinity) == 0 && len(terms.podAntiAffinity) == 0 {
+		return nil, nil
+	}
+	return terms, nil
+}
+
+// newPreferredPodTerms resolves the topology groups of the preferred terms, which the topology already tracks since it
+// counts the domains of both the required and the preferred terms of each pod that it's updated with
+func newPreferredPodTerms(ctx context.Context, topology *Topology, pod *corev1.Pod, topologyType TopologyType, weightedTerms []corev1.WeightedPodAffinityTerm) ([]preferredPodTerm, error) {
+	var terms []preferredPodTerm
+	for _, term := range weightedTerms {
+		group, err := topology.affinityGroup(ctx, topologyType, pod, term.PodAffinityTerm)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil {
+			continue
+		}
+		terms = append(terms, preferredPodTerm{weight: term.Weight, group: group})
+	}
+	return terms, nil
+}
+
+// candidate is an existing node or in-flight NodeClaim that a pod can be added to, along with the state it takes on
+// once the pod is added
+type candidate struct {
+	existingNode       *ExistingNode
+	existingNodeUpdate *existingNodeUpdate
+	nodeClaim          *NodeClaim
+	nodeClaimUpdate    *nodeClaimUpdate
+}
+
+func (c candidate) requirements() scheduling.Requirements {
+	if c.existingNode != nil {
+		return c.existingNodeUpdate.requirements
+	}
+	return c.nodeClaimUpdate.requirements
+}
+
+// addPreferred adds the pod to whichever feasible existing node or in-flight NodeClaim best satisfies its preferred
+// terms so that our choice matches where kube-scheduler will actually bind it. Ties go to the candidate that we would
+// have picked without scoring. Candidates that can't take the pod are recorded as rejections so that the first-fit
+// pass that follows skips them. Scoring a candidate doesn't depend on the number of nodes, since the domain counts
+// come from the topology.
+func (s *Scheduler) addPreferred(ctx context.Context, pod *corev1.Pod, terms *preferredTerms, rejections *shapeRejections) bool {
+	var candidates []candidate
+	for _, node := range s.existingNodes {
+		if rejections.existingNodes.Has(node) {
+			continue
+		}
+		update, err := node.canAdd(ctx, s.kubeClient, pod, s.cachedPodRequests[pod.UID])
+		if err != nil {
+			rejections.existingNodes.Insert(node)
+			continue
+		}
+		candidates = append(candidates, candidate{existingNode: node, existingNodeUpdate: update})
+	}
+	sortNodeClaimsByPodCount(s.newNodeClaims)
+	for _, nodeClaim := range s.newNodeClaims {
+		if rejections.nodeClaims.Has(nodeClaim) {
+			continue
+		}
+		update, err := nodeClaim.canAdd(pod, s.cachedPodRequests[pod.UID])
+		if err != nil {
+			rejections.nodeClaims.Insert(nodeClaim)
+			continue
+		}
+		candidates = append(candidates, candidate{nodeClaim: nodeClaim, nodeClaimUpdate: update})
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	best, bestScore := candidates[0], int64(0)
+	if len(candidates) > 1 {
+		bestScore = preferenceScore(terms, best.requirements())
+		for _, c := range candidates[1:] {
+			if score := preferenceScore(terms, c.requirements()); score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+	}
+	if best.existingNode != nil {
+		best.existingNode.add(pod, best.existingNodeUpdate)
+	} else {
+		best.nodeClaim.add(pod, best.nodeClaimUpdate)
+	}
+	return true
+}
+
+// preferenceScore weighs a candidate with the given requirements the way kube-scheduler's NodeAffinity and
+// InterPodAffinity plugins do. Each preferred node affinity term that the candidate satisfies adds its weight, and each
+// pod in the candidate's topology domain that a preferred pod affinity (anti-affinity) term selects adds (subtracts) its
+// weight.
+func preferenceScore(terms *preferredTerms, requirements scheduling.Requirements) int64 {
+	var score int64
+	for _, term := range terms.nodeAffinity {
+		if satisfies(requirements, term.requirements) {
+			score += int64(term.weight)
+		}
+	}
+	for _, term := range terms.podAffinity {
+		score += int64(term.weight) * int64(countPodsInDomain(term, requirements))
+	}
+	for _, term := range terms.podAntiAffinity {
+		score -= int64(term.weight) * int64(countPodsInDomain(term, requirements))
+	}
+	return score
+}
+
+// satisfies returns true if every node with the candidate's requirements matches the preferred node affinity term. A
+// candidate that's only compatible with the term, e.g. a NodeClaim that can still launch into any zone when the term
+// prefers one of them, may not end up matching it, so it doesn't get the term's weight.
+func satisfies(requirements, preference scheduling.Requirements) bool {
+	for key := range preference.Keys() {
+		preferred := preference.Get(key)
+		if preferred.Operator() == corev1.NodeSelectorOpDoesNotExist {
+			if requirements.Has(key) && requirements.Get(key).Operator() != corev1.NodeSelectorOpDoesNotExist {
+				return false
+			}
+			continue
+		}
+		// Only a requirement with a known set of values can guarantee that the label has a value the term prefers
+		if !requirements.Has(key) || requirements.Get(key).Operator() != corev1.NodeSelectorOpIn {
+			return false
+		}
+		if !lo.EveryBy(requirements.Get(key).Values(), preferred.Has) {
+			return false
+		}
+	}
+	return true
+}
+
+// countPodsInDomain returns the number of pods selected by the term in the candidate's domain for the term's topology
+// key. Candidates that could still land in more than one domain don't belong to any domain yet.
+func countPodsInDomain(term preferredPodTerm, requirements scheduling.Requirements) int32 {
+	domain, ok := singleDomain(requirements, term.group.Key)
+	if !ok {
+		return 0
+	}
+	return term.group.domains[domain]
+}
+
+func singleDomain(requirements scheduling.Requirements, topologyKey string) (string, bool) {
+	if !requirements.Has(topologyKey) {
+		return "", false
+	}
+	requirement := requirements.Get(topologyKey)
+	if requirement.Operator() != corev1.NodeSelectorOpIn || requirement.Len() != 1 {
+		return "", false
+	}
+	return requirement.Any(), true
+}
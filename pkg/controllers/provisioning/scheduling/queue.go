@@ -19,6 +19,7 @@ package scheduling
 import (
 	"sort"
 
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -33,9 +34,15 @@ type Queue struct {
 	lastLen map[types.UID]int
 }
 
-// NewQueue constructs a new queue given the input pods, sorting them to optimize for bin-packing into nodes.
-func NewQueue(pods []*v1.Pod, podRequests map[types.UID]v1.ResourceList) *Queue {
-	sort.Slice(pods, byCPUAndMemoryDescending(pods, podRequests))
+// NewQueue constructs a new queue given the input pods, sorting them to optimize for bin-packing into nodes. If
+// limitsContended is set, the pods are sorted by priority and then creation time first, so that critical pods claim the
+// capacity that's left under the limits before lower priority pods do.
+func NewQueue(pods []*v1.Pod, podRequests map[types.UID]v1.ResourceList, limitsContended bool) *Queue {
+	if limitsContended {
+		sort.Slice(pods, byPriorityAndCreationTime(pods, byCPUAndMemoryDescending(pods, podRequests)))
+	} else {
+		sort.Slice(pods, byCPUAndMemoryDescending(pods, podRequests))
+	}
 	return &Queue{
 		pods:    pods,
 		lastLen: map[types.UID]int{},
@@ -73,6 +80,23 @@ func (q *Queue) List() []*v1.Pod {
 	return q.pods
 }
 
+// byPriorityAndCreationTime orders higher priority pods first, and then pods of the same priority by the order they
+// were created in, falling back to the passed ordering
+func byPriorityAndCreationTime(pods []*v1.Pod, fallback func(i int, j int) bool) func(i int, j int) bool {
+	return func(i, j int) bool {
+		lhsPod := pods[i]
+		rhsPod := pods[j]
+
+		if lhsPriority, rhsPriority := lo.FromPtr(lhsPod.Spec.Priority), lo.FromPtr(rhsPod.Spec.Priority); lhsPriority != rhsPriority {
+			return lhsPriority > rhsPriority
+		}
+		if !lhsPod.CreationTimestamp.Equal(&rhsPod.CreationTimestamp) {
+			return lhsPod.CreationTimestamp.Before(&rhsPod.CreationTimestamp)
+		}
+		return fallback(i, j)
+	}
+}
+
 func byCPUAndMemoryDescending(pods []*v1.Pod, podRequests map[types.UID]v1.ResourceList) func(i int, j int) bool {
 	return func(i, j int) bool {
 		lhsPod := pods[i]
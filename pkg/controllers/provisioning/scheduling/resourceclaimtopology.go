@@ -0,0 +1,148 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ResourceClaimError is returned for pods that reference Dynamic Resource Allocation ResourceClaims that can't be used
+// to constrain provisioning
+type ResourceClaimError struct {
+	claim  string
+	reason string
+}
+
+func (e ResourceClaimError) Error() string {
+	return fmt.Sprintf("resource claim %q %s", e.claim, e.reason)
+}
+
+func IsResourceClaimError(err error) bool {
+	return errors.As(err, &ResourceClaimError{})
+}
+
+func NewResourceClaimTopology(kubeClient client.Client) *ResourceClaimTopology {
+	return &ResourceClaimTopology{kubeClient: kubeClient}
+}
+
+// ResourceClaimTopology constrains pods that use Dynamic Resource Allocation to the nodes that their ResourceClaims
+// were allocated for
+type ResourceClaimTopology struct {
+	kubeClient client.Client
+}
+
+// Inject adds the node selectors of the pod's allocated ResourceClaims to the pod's required node affinity
+func (r *ResourceClaimTopology) Inject(ctx context.Context, pod *v1.Pod) error {
+	requirements, err := r.getRequirements(ctx, pod)
+	if err != nil {
+		return err
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+	injectRequiredNodeAffinity(pod, requirements)
+
+	log.FromContext(ctx).
+		WithValues("Pod", klog.KRef(pod.Namespace, pod.Name)).
+		V(1).Info(fmt.Sprintf("adding requirements derived from pod resource claims, %s", requirements))
+	return nil
+}
+
+// Validate returns a ResourceClaimError if any of the pod's ResourceClaims haven't been allocated. The devices for a
+// claim are allocated from the ResourceSlices that drivers publish for nodes that already exist, so we have no way of
+// knowing whether new capacity would satisfy the claim.
+func (r *ResourceClaimTopology) Validate(ctx context.Context, pod *v1.Pod) error {
+	_, err := r.getRequirements(ctx, pod)
+	return err
+}
+
+func (r *ResourceClaimTopology) getRequirements(ctx context.Context, pod *v1.Pod) ([]v1.NodeSelectorRequirement, error) {
+	var requirements []v1.NodeSelectorRequirement
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		name, ok := resourceClaimName(pod, podClaim)
+		if !ok {
+			return nil, ResourceClaimError{claim: podClaim.Name, reason: "hasn't been created from its template"}
+		}
+		// The claim controller didn't need to generate a claim from the template
+		if name == "" {
+			continue
+		}
+		claim := &resourcev1beta1.ResourceClaim{}
+		if err := r.kubeClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: name}, claim); err != nil {
+			return nil, fmt.Errorf("getting resource claim %q, %w", name, err)
+		}
+		if claim.Status.Allocation == nil {
+			return nil, ResourceClaimError{claim: name, reason: "isn't allocated"}
+		}
+		// Devices that aren't local to a node, e.g. network attached devices, don't constrain where the pod can go
+		nodeSelector := claim.Status.Allocation.NodeSelector
+		if nodeSelector == nil || len(nodeSelector.NodeSelectorTerms) == 0 {
+			continue
+		}
+		// Terms are ORed, only use the first term
+		term := nodeSelector.NodeSelectorTerms[0]
+		requirements = append(requirements, term.MatchExpressions...)
+		for _, field := range term.MatchFields {
+			requirement, err := r.getNodeNameRequirement(ctx, name, field)
+			if err != nil {
+				return nil, err
+			}
+			requirements = append(requirements, requirement)
+		}
+	}
+	return requirements, nil
+}
+
+// getNodeNameRequirement translates a node name field selector into a requirement on the hostname label since we
+// schedule against node labels. This pins the pod to the existing nodes that the claim's devices are attached to.
+func (r *ResourceClaimTopology) getNodeNameRequirement(ctx context.Context, claimName string, field v1.NodeSelectorRequirement) (v1.NodeSelectorRequirement, error) {
+	if field.Key != "metadata.name" {
+		return v1.NodeSelectorRequirement{}, ResourceClaimError{claim: claimName, reason: fmt.Sprintf("selects nodes by unsupported field %q", field.Key)}
+	}
+	hostnames := make([]string, 0, len(field.Values))
+	for _, nodeName := range field.Values {
+		node := &v1.Node{}
+		if err := r.kubeClient.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+			return v1.NodeSelectorRequirement{}, fmt.Errorf("getting node %q for resource claim %q, %w", nodeName, claimName, err)
+		}
+		hostnames = append(hostnames, lo.CoalesceOrEmpty(node.Labels[v1.LabelHostname], nodeName))
+	}
+	return v1.NodeSelectorRequirement{Key: v1.LabelHostname, Operator: field.Operator, Values: hostnames}, nil
+}
+
+// resourceClaimName returns the name of the ResourceClaim that the pod references, which is only known for claims
+// generated from a template once the pod's status records it. The name is empty if no claim had to be generated.
+func resourceClaimName(pod *v1.Pod, podClaim v1.PodResourceClaim) (string, bool) {
+	if podClaim.ResourceClaimName != nil {
+		return *podClaim.ResourceClaimName, true
+	}
+	status, ok := lo.Find(pod.Status.ResourceClaimStatuses, func(s v1.PodResourceClaimStatus) bool { return s.Name == podClaim.Name })
+	if !ok {
+		return "", false
+	}
+	return lo.FromPtr(status.ResourceClaimName), true
+}
@@ -19,14 +19,17 @@ package scheduling
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
@@ -39,15 +42,17 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
 	"sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 func NewScheduler(ctx context.Context, kubeClient client.Client, nodePools []*v1.NodePool,
 	cluster *state.Cluster, stateNodes []*state.StateNode, topology *Topology,
-	instanceTypes map[string][]*cloudprovider.InstanceType, daemonSetPods []*corev1.Pod,
-	recorder events.Recorder, clock clock.Clock) *Scheduler {
+	instanceTypes map[string][]*cloudprovider.InstanceType, instanceTypesFetchedAt time.Time, daemonSetPods []*corev1.Pod,
+	overheadPods []*corev1.Pod, recorder events.Recorder, clock clock.Clock) *Scheduler {
 
 	// if any of the nodePools add a taint with a prefer no schedule effect, we add a toleration for the taint
 	// during preference relaxation
@@ -62,6 +67,9 @@ func NewScheduler(ctx context.Context, kubeClient client.Client, nodePools []*v1
 	// Pre-filter instance types eligible for NodePools to reduce work done during scheduling loops for pods
 	templates := lo.FilterMap(nodePools, func(np *v1.NodePool, _ int) (*NodeClaimTemplate, bool) {
 		nct := NewNodeClaimTemplate(np)
+		// Compliance requirements are enforced on every NodePool so that they hold cluster-wide, regardless of how the
+		// NodePool is configured
+		nct.Requirements.Add(scheduling.NewNodeSelectorRequirements(options.FromContext(ctx).ComplianceRequirements.Requirements...).Values()...)
 		nct.InstanceTypeOptions = filterInstanceTypesByRequirements(instanceTypes[np.Name], nct.Requirements, corev1.ResourceList{}).remaining
 		if len(nct.InstanceTypeOptions) == 0 {
 			recorder.Publish(NoCompatibleInstanceTypes(np))
@@ -75,34 +83,62 @@ func NewScheduler(ctx context.Context, kubeClient client.Client, nodePools []*v1
 		kubeClient:         kubeClient,
 		nodeClaimTemplates: templates,
 		topology:           topology,
+		shapes:             newShapeCache(),
 		cluster:            cluster,
-		daemonOverhead:     getDaemonOverhead(templates, daemonSetPods),
+		daemonOverhead:     getDaemonOverhead(templates, daemonSetPods, overheadPods),
+		daemonHostPorts:    getDaemonHostPortUsage(templates, lo.Flatten([][]*corev1.Pod{daemonSetPods, overheadPods})),
 		cachedPodRequests:  map[types.UID]corev1.ResourceList{}, // cache pod requests to avoid having to continually recompute this total
 		recorder:           recorder,
 		preferences:        &Preferences{ToleratePreferNoSchedule: toleratePreferNoSchedule},
+		preferredTerms:     map[types.UID]*preferredTerms{},
 		remainingResources: lo.SliceToMap(nodePools, func(np *v1.NodePool) (string, corev1.ResourceList) {
 			return np.Name, corev1.ResourceList(np.Spec.Limits)
 		}),
-		clock: clock,
+		remainingCapacityTypeResources: lo.SliceToMap(nodePools, func(np *v1.NodePool) (string, map[string]corev1.ResourceList) {
+			return np.Name, np.Spec.Limits.ByCapacityType()
+		}),
+		clusterLimits:          NewClusterLimits(ctx, stateNodes, instanceTypes),
+		clock:                  clock,
+		instanceTypesFetchedAt: instanceTypesFetchedAt,
 	}
-	s.calculateExistingNodeClaims(stateNodes, daemonSetPods)
+	// Overhead pods aren't reserved on existing nodes since they're already running there and counted in the node's requests
+	s.calculateExistingNodeClaims(stateNodes, daemonSetPods, nodePools)
 	return s
 }
 
+// NewSimulationScheduler returns a scheduler that schedules the pods of a simulation against the existing nodes and the
+// NodePools, instance types and daemon overhead that the simulation resolved
+func NewSimulationScheduler(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, stateNodes []*state.StateNode,
+	input *simulation.Input, recorder events.Recorder, clock clock.Clock) (*Scheduler, error) {
+	topology, err := NewTopology(ctx, kubeClient, cluster, input.Domains, input.Pods)
+	if err != nil {
+		return nil, fmt.Errorf("tracking topology counts, %w", err)
+	}
+	return NewScheduler(ctx, kubeClient, input.NodePools, cluster, stateNodes, topology, input.InstanceTypes, input.InstanceTypesFetchedAt,
+		input.DaemonSetPods, input.OverheadPods, recorder, clock), nil
+}
+
 type Scheduler struct {
 	id                 types.UID // Unique UUID attached to this scheduling loop
 	newNodeClaims      []*NodeClaim
 	existingNodes      []*ExistingNode
 	nodeClaimTemplates []*NodeClaimTemplate
 	remainingResources map[string]corev1.ResourceList // (NodePool name) -> remaining resources for that NodePool
-	daemonOverhead     map[*NodeClaimTemplate]corev1.ResourceList
-	cachedPodRequests  map[types.UID]corev1.ResourceList // (Pod Namespace/Name) -> calculated resource requests for the pod
-	preferences        *Preferences
-	topology           *Topology
-	cluster            *state.Cluster
-	recorder           events.Recorder
-	kubeClient         client.Client
-	clock              clock.Clock
+	// (NodePool name) -> (capacity type) -> remaining resources for the capacity type in that NodePool
+	remainingCapacityTypeResources map[string]map[string]corev1.ResourceList
+	clusterLimits                  *ClusterLimits
+	daemonOverhead                 map[*NodeClaimTemplate]corev1.ResourceList
+	daemonHostPorts                map[*NodeClaimTemplate]*scheduling.HostPortUsage
+	cachedPodRequests              map[types.UID]corev1.ResourceList // (Pod Namespace/Name) -> calculated resource requests for the pod
+	preferences                    *Preferences
+	preferredTerms                 map[types.UID]*preferredTerms // pod UID -> the pod's preferred terms before relaxation
+	topology                       *Topology
+	shapes                         *shapeCache
+	cluster                        *state.Cluster
+	recorder                       events.Recorder
+	kubeClient                     client.Client
+	clock                          clock.Clock
+	instanceTypesFetchedAt         time.Time // the zero time disables the instance types maximum age check
 }
 
 // Results contains the results of the scheduling operation
@@ -114,20 +150,43 @@ type Results struct {
 
 // Record sends eventing and log messages back for the results that were produced from a scheduling run
 // It also nominates nodes in the cluster state based on the scheduling run to signal to other components
-// leveraging the cluster state that a previous scheduling run that was recorded is relying on these nodes
-func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *state.Cluster) {
+// leveraging the cluster state that a previous scheduling run that was recorded is relying on these nodes. It returns
+// the pods whose nominations conflicted with the nominations of an earlier scheduling run.
+func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *state.Cluster) []*corev1.Pod {
+	// The decisions of this scheduling run replace the earlier nominations of its pods
+	cluster.ClearNodeNominations(lo.Keys(r.PodErrors)...)
+	for _, existing := range r.ExistingNodes {
+		cluster.ClearNodeNominations(existing.Pods...)
+	}
+	for _, nodeClaim := range r.NewNodeClaims {
+		cluster.ClearNodeNominations(nodeClaim.Pods...)
+	}
 	// Report failures and nominations
 	for p, err := range r.PodErrors {
+		if IsInstanceTypesStaleError(err) {
+			log.FromContext(ctx).WithValues("Pod", klog.KRef(p.Namespace, p.Name)).V(1).Info("deferring pod to a new batch, instance types are stale")
+			continue
+		}
 		log.FromContext(ctx).WithValues("Pod", klog.KRef(p.Namespace, p.Name)).Error(err, "could not schedule pod")
 		recorder.Publish(PodFailedToScheduleEvent(p, err))
+		limitsErr := ClusterLimitsExceededError{}
+		if errors.As(err, &limitsErr) {
+			recorder.Publish(ClusterLimitsExceededEvent(p, limitsErr))
+			ClusterLimitsBlockedPodsTotal.Inc(map[string]string{limitLabel: limitsErr.limit})
+		}
 	}
+	var conflicts []*corev1.Pod
 	for _, existing := range r.ExistingNodes {
-		if len(existing.Pods) > 0 {
-			cluster.NominateNodeForPod(ctx, existing.ProviderID())
+		if len(existing.Pods) == 0 {
+			continue
 		}
+		nodeConflicts := cluster.NominateNodeForPod(ctx, existing.ProviderID(), existing.Pods...)
 		for _, p := range existing.Pods {
-			recorder.Publish(NominatePodEvent(p, existing.Node, existing.NodeClaim))
+			if !lo.Contains(nodeConflicts, p) {
+				recorder.Publish(NominatePodEvent(p, existing.Node, existing.NodeClaim))
+			}
 		}
+		conflicts = append(conflicts, nodeConflicts...)
 	}
 	// Report new nodes, or exit to avoid log spam
 	newCount := 0
@@ -135,7 +194,7 @@ func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *
 		newCount += len(nodeClaim.Pods)
 	}
 	if newCount == 0 {
-		return
+		return conflicts
 	}
 	log.FromContext(ctx).WithValues("nodeclaims", len(r.NewNodeClaims), "pods", newCount).Info("computed new nodeclaim(s) to fit pod(s)")
 	// Report in flight newNodes, or exit to avoid log spam
@@ -146,9 +205,15 @@ func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *
 		existingCount += len(node.Pods)
 	}
 	if existingCount == 0 {
-		return
+		return conflicts
 	}
 	log.FromContext(ctx).Info(fmt.Sprintf("computed %d unready node(s) will fit %d pod(s)", inflightCount, existingCount))
+	return conflicts
+}
+
+// StalePods returns the pods that weren't considered because the instance types exceeded their maximum age
+func (r Results) StalePods() []*corev1.Pod {
+	return lo.Keys(lo.PickBy(r.PodErrors, func(_ *corev1.Pod, err error) bool { return IsInstanceTypesStaleError(err) }))
 }
 
 // AllNonPendingPodsScheduled returns true if all pods scheduled.
@@ -190,7 +255,7 @@ func (r Results) TruncateInstanceTypes(maxInstanceTypes int) Results {
 	for _, newNodeClaim := range r.NewNodeClaims {
 		// The InstanceTypeOptions are truncated due to limitations in sending the number of instances to launch API.
 		var err error
-		newNodeClaim.InstanceTypeOptions, err = newNodeClaim.InstanceTypeOptions.Truncate(newNodeClaim.Requirements, maxInstanceTypes)
+		newNodeClaim.InstanceTypeOptions, err = newNodeClaim.InstanceTypeOptions.Truncate(newNodeClaim.Requirements, maxInstanceTypes, newNodeClaim.InstanceFamilyPreferences...)
 		if err != nil {
 			// Check if the truncated InstanceTypeOptions in each NewNodeClaim from the results still satisfy the minimum requirements
 			// If number of InstanceTypes in the NodeClaim cannot satisfy the minimum requirements, add its Pods to error map with reason.
@@ -218,8 +283,20 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) Results {
 	QueueDepth.DeletePartialMatch(map[string]string{ControllerLabel: injection.GetControllerName(ctx)})
 	for _, p := range pods {
 		s.cachedPodRequests[p.UID] = resources.RequestsForPods(p)
+		terms, err := newPreferredTerms(ctx, s.topology, p)
+		if err != nil {
+			log.FromContext(ctx).WithValues("Pod", klog.KRef(p.Namespace, p.Name)).Error(err, "failed resolving preferred terms")
+		} else if terms != nil {
+			s.preferredTerms[p.UID] = terms
+		}
 	}
-	q := NewQueue(pods, s.cachedPodRequests)
+	// Capacity on existing nodes that an earlier scheduling run nominated to pods outside of this run stays reserved for
+	// those pods until they bind or their nominations expire
+	podKeys := sets.New(lo.Map(pods, func(p *corev1.Pod, _ int) types.NamespacedName { return client.ObjectKeyFromObject(p) })...)
+	for _, node := range s.existingNodes {
+		node.cachedAvailable = resources.Subtract(node.cachedAvailable, s.cluster.NominatedRequests(node.ProviderID(), podKeys))
+	}
+	q := NewQueue(pods, s.cachedPodRequests, s.limitsContended(pods))
 
 	startTime := s.clock.Now()
 	lastLogTime := s.clock.Now()
@@ -232,6 +309,14 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) Results {
 			log.FromContext(ctx).WithValues("pods-scheduled", batchSize-len(q.pods), "pods-remaining", len(q.pods), "duration", s.clock.Since(startTime).Truncate(time.Second), "scheduling-id", string(s.id)).Info("computing pod scheduling...")
 			lastLogTime = s.clock.Now()
 		}
+		// Pricing and offerings change underneath long scheduling loops, so rather than launching capacity off of
+		// stale instance types, the pods that are left are deferred to a new batch that fetches them again
+		if maxAge := options.FromContext(ctx).InstanceTypesMaxAge; maxAge > 0 && !s.instanceTypesFetchedAt.IsZero() && s.clock.Since(s.instanceTypesFetchedAt) > maxAge {
+			for _, p := range q.List() {
+				errors[p] = InstanceTypesStaleError{age: s.clock.Since(s.instanceTypesFetchedAt)}
+			}
+			break
+		}
 		// Try the next pod
 		pod, ok := q.Pop()
 		if !ok {
@@ -254,9 +339,11 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) Results {
 		}
 	}
 	UnfinishedWorkSeconds.Delete(map[string]string{ControllerLabel: injection.GetControllerName(ctx), schedulingIDLabel: string(s.id)})
+	s.balanceZones()
 	for _, m := range s.newNodeClaims {
 		m.FinalizeScheduling()
 	}
+	s.nameNodeClaims(ctx)
 
 	return Results{
 		NewNodeClaims: s.newNodeClaims,
@@ -265,57 +352,143 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) Results {
 	}
 }
 
+// limitsContended returns true if the pods request more of a resource than a NodePool has remaining under its limits,
+// in which case not every pod may get capacity and the order that pods are scheduled in decides which ones do
+func (s *Scheduler) limitsContended(pods []*corev1.Pod) bool {
+	requests := resources.Merge(lo.Map(pods, func(p *corev1.Pod, _ int) corev1.ResourceList { return s.cachedPodRequests[p.UID] })...)
+	remaining := lo.Values(s.remainingResources)
+	for _, byCapacityType := range s.remainingCapacityTypeResources {
+		remaining = append(remaining, lo.Values(byCapacityType)...)
+	}
+	for _, limits := range remaining {
+		for name, quantity := range limits {
+			if requested := requests[name]; requested.Cmp(quantity) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameNodeClaims annotates the NodeClaims with the scheduling run that computed them. If deterministic names or the
+// NodeClaim naming templates are enabled, the NodeClaims are also ordered by NodePool and by the order in which they
+// were created during the simulation so that each gets a stable index within the scheduling run. With deterministic
+// names, the NodeClaims are named after their NodePool, the scheduling run and their index so that repeated creates
+// of the same NodeClaim are idempotent.
+func (s *Scheduler) nameNodeClaims(ctx context.Context) {
+	for _, m := range s.newNodeClaims {
+		// The annotations map is shared with the NodeClaimTemplate so it's copied rather than mutated
+		m.Annotations = lo.Assign(m.Annotations, map[string]string{v1.SchedulingRunAnnotationKey: string(s.id)})
+	}
+	opts := options.FromContext(ctx)
+	if !opts.DeterministicNodeClaimNames && opts.NodeClaimNameTemplate == "" && opts.NodeClaimLabelTemplates == "" && opts.NodeClaimTagTemplates == "" {
+		return
+	}
+	sort.SliceStable(s.newNodeClaims, func(a, b int) bool {
+		if s.newNodeClaims[a].NodePoolName != s.newNodeClaims[b].NodePoolName {
+			return s.newNodeClaims[a].NodePoolName < s.newNodeClaims[b].NodePoolName
+		}
+		return s.newNodeClaims[a].sequence < s.newNodeClaims[b].sequence
+	})
+	for i, m := range s.newNodeClaims {
+		m.Index = i
+		if opts.DeterministicNodeClaimNames {
+			m.Name = fmt.Sprintf("%s-%s-%d", m.NodePoolName, strings.Split(string(s.id), "-")[0], m.Index)
+		}
+	}
+}
+
 func (s *Scheduler) add(ctx context.Context, pod *corev1.Pod) error {
+	// identical pods are rejected by the same nodes, nodeclaims and templates until the topology changes
+	rejections := s.shapes.For(pod, s.cachedPodRequests[pod.UID], s.topology.Version())
+	// pods with preferred terms go to the feasible node or nodeclaim that best satisfies them rather than the first one
+	if terms, ok := s.preferredTerms[pod.UID]; ok && s.addPreferred(ctx, pod, terms, rejections) {
+		return nil
+	}
 	// first try to schedule against an in-flight real node
 	for _, node := range s.existingNodes {
+		if rejections.existingNodes.Has(node) {
+			continue
+		}
 		if err := node.Add(ctx, s.kubeClient, pod, s.cachedPodRequests[pod.UID]); err == nil {
 			return nil
 		}
+		rejections.existingNodes.Insert(node)
 	}
 
 	// Consider using https://pkg.go.dev/container/heap
-	sort.Slice(s.newNodeClaims, func(a, b int) bool { return len(s.newNodeClaims[a].Pods) < len(s.newNodeClaims[b].Pods) })
+	sortNodeClaimsByPodCount(s.newNodeClaims)
 
 	// Pick existing node that we are about to create
 	for _, nodeClaim := range s.newNodeClaims {
+		if rejections.nodeClaims.Has(nodeClaim) {
+			continue
+		}
 		if err := nodeClaim.Add(pod, s.cachedPodRequests[pod.UID]); err == nil {
 			return nil
 		}
+		rejections.nodeClaims.Insert(nodeClaim)
 	}
 
 	// Create new node
+	// Without any NodePools, e.g. for pods that aren't compatible with any of them, there's no capacity to launch
+	if len(s.nodeClaimTemplates) == 0 {
+		return fmt.Errorf("no compatible nodepools found")
+	}
 	var errs error
 	for _, nodeClaimTemplate := range s.nodeClaimTemplates {
+		if err, ok := rejections.templates[nodeClaimTemplate]; ok {
+			errs = multierr.Append(errs, err)
+			continue
+		}
 		instanceTypes := nodeClaimTemplate.InstanceTypeOptions
 		// if limits have been applied to the nodepool, ensure we filter instance types to avoid violating those limits
 		if remaining, ok := s.remainingResources[nodeClaimTemplate.NodePoolName]; ok {
 			instanceTypes = filterByRemainingResources(instanceTypes, remaining)
 			if len(instanceTypes) == 0 {
-				errs = multierr.Append(errs, fmt.Errorf("all available instance types exceed limits for nodepool: %q", nodeClaimTemplate.NodePoolName))
+				rejections.templates[nodeClaimTemplate] = fmt.Errorf("all available instance types exceed limits for nodepool: %q", nodeClaimTemplate.NodePoolName)
+				errs = multierr.Append(errs, rejections.templates[nodeClaimTemplate])
 				continue
 			} else if len(nodeClaimTemplate.InstanceTypeOptions) != len(instanceTypes) {
 				log.FromContext(ctx).V(1).WithValues("NodePool", klog.KRef("", nodeClaimTemplate.NodePoolName)).Info(fmt.Sprintf("%d out of %d instance types were excluded because they would breach limits",
 					len(nodeClaimTemplate.InstanceTypeOptions)-len(instanceTypes), len(nodeClaimTemplate.InstanceTypeOptions)))
 			}
 		}
-		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], instanceTypes)
+		// offerings for capacity types that would breach the limits scoped to that capacity type are treated as unavailable
+		instanceTypes = filterByRemainingCapacityTypeResources(instanceTypes, s.remainingCapacityTypeResources[nodeClaimTemplate.NodePoolName])
+		// ensure that launching the nodeclaim won't breach the limits that apply across all nodepools
+		instanceTypes, err := s.clusterLimits.Filter(instanceTypes)
+		if err != nil {
+			rejections.templates[nodeClaimTemplate] = err
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], s.daemonHostPorts[nodeClaimTemplate], instanceTypes)
 		if err := nodeClaim.Add(pod, s.cachedPodRequests[pod.UID]); err != nil {
 			nodeClaim.Destroy() // Ensure we cleanup any changes that we made while mocking out a NodeClaim
-			errs = multierr.Append(errs, fmt.Errorf("incompatible with nodepool %q, daemonset overhead=%s, %w",
-				nodeClaimTemplate.NodePoolName,
-				resources.String(s.daemonOverhead[nodeClaimTemplate]),
-				err))
+			rejections.templates[nodeClaimTemplate] = NewNodePoolIncompatibleError(nodeClaimTemplate.NodePoolName, s.daemonOverhead[nodeClaimTemplate], err)
+			errs = multierr.Append(errs, rejections.templates[nodeClaimTemplate])
 			continue
 		}
 		// we will launch this nodeClaim and need to track its maximum possible resource usage against our remaining resources
 		s.newNodeClaims = append(s.newNodeClaims, nodeClaim)
 		s.remainingResources[nodeClaimTemplate.NodePoolName] = subtractMax(s.remainingResources[nodeClaimTemplate.NodePoolName], nodeClaim.InstanceTypeOptions)
+		for capacityType, remaining := range s.remainingCapacityTypeResources[nodeClaimTemplate.NodePoolName] {
+			// we pessimistically assume that the nodeclaim launches with any of the capacity types that it's compatible with
+			compatible := lo.Filter(nodeClaim.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) bool {
+				return it.Offerings.Available().HasCompatible(scheduling.NewRequirements(scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, capacityType))) &&
+					nodeClaim.Requirements.Get(v1.CapacityTypeLabelKey).Has(capacityType)
+			})
+			s.remainingCapacityTypeResources[nodeClaimTemplate.NodePoolName][capacityType] = subtractMax(remaining, compatible)
+		}
+		s.clusterLimits.Subtract(nodeClaim.InstanceTypeOptions)
 		return nil
 	}
 	return errs
 }
 
-func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, daemonSetPods []*corev1.Pod) {
+func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, daemonSetPods []*corev1.Pod, nodePools []*v1.NodePool) {
+	nodePoolsByName := lo.SliceToMap(nodePools, func(np *v1.NodePool) (string, *v1.NodePool) { return np.Name, np })
 	// create our existing nodes
 	for _, node := range stateNodes {
 		// Calculate any daemonsets that should schedule to the inflight node
@@ -330,7 +503,17 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 			}
 			daemons = append(daemons, p)
 		}
-		s.existingNodes = append(s.existingNodes, NewExistingNode(node, s.topology, taints, resources.RequestsForPods(daemons...)))
+		existingNode := NewExistingNode(node, s.topology, taints, resources.RequestsForPods(daemons...))
+		// Nodes report the pods that their kubelet supports, so they're lowered to the NodePool's maxPodsPerNode
+		if nodePool, ok := nodePoolsByName[node.Labels()[v1.NodePoolLabelKey]]; ok {
+			excess := resources.Subtract(node.Allocatable(), cloudprovider.MaxPods(nodePool, node.Allocatable()))
+			existingNode.cachedAvailable = resources.Subtract(existingNode.cachedAvailable, excess)
+		}
+		// Reserve the host ports of the daemons so that we don't schedule pods that would block them from starting
+		for _, p := range daemons {
+			existingNode.HostPortUsage().Add(p, scheduling.GetHostPorts(p))
+		}
+		s.existingNodes = append(s.existingNodes, existingNode)
 
 		// We don't use the status field and instead recompute the remaining resources to ensure we have a consistent view
 		// of the cluster during scheduling.  Depending on how node creation falls out, this will also work for cases where
@@ -338,6 +521,9 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 		if _, ok := s.remainingResources[node.Labels()[v1.NodePoolLabelKey]]; ok {
 			s.remainingResources[node.Labels()[v1.NodePoolLabelKey]] = resources.Subtract(s.remainingResources[node.Labels()[v1.NodePoolLabelKey]], node.Capacity())
 		}
+		if remaining, ok := s.remainingCapacityTypeResources[node.Labels()[v1.NodePoolLabelKey]][node.Labels()[v1.CapacityTypeLabelKey]]; ok {
+			s.remainingCapacityTypeResources[node.Labels()[v1.NodePoolLabelKey]][node.Labels()[v1.CapacityTypeLabelKey]] = resources.Subtract(remaining, node.Capacity())
+		}
 	}
 	// Order the existing nodes for scheduling with initialized nodes first
 	// This is done specifically for consolidation where we want to make sure we schedule to initialized nodes
@@ -353,10 +539,29 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 	})
 }
 
-// getDaemonOverhead determines the overhead for each NodeClaimTemplate required for daemons to schedule for any node provisioned by the NodeClaimTemplate
-func getDaemonOverhead(nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*corev1.Pod) map[*NodeClaimTemplate]corev1.ResourceList {
+// getDaemonOverhead determines the overhead for each NodeClaimTemplate required for daemons to schedule for any node provisioned by the NodeClaimTemplate.
+// Overhead pods come from the operator's manifests rather than DaemonSets, but are reserved in the same way.
+func getDaemonOverhead(nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*corev1.Pod, overheadPods []*corev1.Pod) map[*NodeClaimTemplate]corev1.ResourceList {
+	daemons := lo.Flatten([][]*corev1.Pod{daemonSetPods, overheadPods})
 	return lo.SliceToMap(nodeClaimTemplates, func(nct *NodeClaimTemplate) (*NodeClaimTemplate, corev1.ResourceList) {
-		return nct, resources.RequestsForPods(lo.Filter(daemonSetPods, func(p *corev1.Pod, _ int) bool { return isDaemonPodCompatible(nct, p) })...)
+		return nct, DaemonOverhead(nct, daemons...)
+	})
+}
+
+// DaemonOverhead returns the requests of the daemon pods that will schedule to any node provisioned by the NodeClaimTemplate
+func DaemonOverhead(nodeClaimTemplate *NodeClaimTemplate, daemonPods ...*corev1.Pod) corev1.ResourceList {
+	return resources.RequestsForPods(lo.Filter(daemonPods, func(p *corev1.Pod, _ int) bool { return isDaemonPodCompatible(nodeClaimTemplate, p) })...)
+}
+
+// getDaemonHostPortUsage determines the host ports for each NodeClaimTemplate that are reserved by daemons which will schedule
+// to any node provisioned by the NodeClaimTemplate
+func getDaemonHostPortUsage(nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*corev1.Pod) map[*NodeClaimTemplate]*scheduling.HostPortUsage {
+	return lo.SliceToMap(nodeClaimTemplates, func(nct *NodeClaimTemplate) (*NodeClaimTemplate, *scheduling.HostPortUsage) {
+		usage := scheduling.NewHostPortUsage()
+		for _, p := range lo.Filter(daemonSetPods, func(p *corev1.Pod, _ int) bool { return isDaemonPodCompatible(nct, p) }) {
+			usage.Add(p, scheduling.GetHostPorts(p))
+		}
+		return nct, usage
 	})
 }
 
@@ -405,6 +610,11 @@ func subtractMax(remaining corev1.ResourceList, instanceTypes []*cloudprovider.I
 	return result
 }
 
+// sortNodeClaimsByPodCount orders the in-flight NodeClaims so that the ones with the fewest pods are tried first
+func sortNodeClaimsByPodCount(nodeClaims []*NodeClaim) {
+	sort.Slice(nodeClaims, func(a, b int) bool { return len(nodeClaims[a].Pods) < len(nodeClaims[b].Pods) })
+}
+
 // filterByRemainingResources is used to filter out instance types that if launched would exceed the nodepool limits
 func filterByRemainingResources(instanceTypes []*cloudprovider.InstanceType, remaining corev1.ResourceList) []*cloudprovider.InstanceType {
 	var filtered []*cloudprovider.InstanceType
@@ -423,3 +633,49 @@ func filterByRemainingResources(instanceTypes []*cloudprovider.InstanceType, rem
 	}
 	return filtered
 }
+
+// filterByRemainingCapacityTypeResources marks the offerings of an instance type as unavailable if launching the
+// instance type with the offering's capacity type would exceed the limits scoped to that capacity type. Instance types
+// that have offerings marked as unavailable are copied so that the instance types cached by the cloud provider aren't
+// mutated.
+func filterByRemainingCapacityTypeResources(instanceTypes []*cloudprovider.InstanceType, remaining map[string]corev1.ResourceList) []*cloudprovider.InstanceType {
+	if len(remaining) == 0 {
+		return instanceTypes
+	}
+	return lo.Map(instanceTypes, func(it *cloudprovider.InstanceType, _ int) *cloudprovider.InstanceType {
+		exceeded := sets.New[string]()
+		for capacityType, r := range remaining {
+			if len(filterByRemainingResources([]*cloudprovider.InstanceType{it}, r)) == 0 {
+				exceeded.Insert(capacityType)
+			}
+		}
+		if len(exceeded) == 0 {
+			return it
+		}
+		return &cloudprovider.InstanceType{
+			Name:         it.Name,
+			Family:       it.Family,
+			Requirements: it.Requirements,
+			Offerings: lo.Map(it.Offerings, func(o cloudprovider.Offering, _ int) cloudprovider.Offering {
+				o.Available = o.Available && !exceeded.Has(o.Requirements.Get(v1.CapacityTypeLabelKey).Any())
+				return o
+			}),
+			Capacity: it.Capacity,
+			Overhead: it.Overhead,
+		}
+	})
+}
+
+// InstanceTypesStaleError is returned for the pods that were left in the queue when the instance types that the
+// scheduler was created with exceeded their maximum age
+type InstanceTypesStaleError struct {
+	age time.Duration
+}
+
+func (e InstanceTypesStaleError) Error() string {
+	return fmt.Sprintf("instance types are stale after %s, deferring to a new batch", e.age.Truncate(time.Second))
+}
+
+func IsInstanceTypesStaleError(err error) bool {
+	return errors.As(err, &InstanceTypesStaleError{})
+}
@@ -47,6 +47,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 )
 
@@ -140,6 +141,7 @@ func TestSchedulingProfile(t *testing.T) {
 func benchmarkScheduler(b *testing.B, instanceCount, podCount int) {
 	// disable logging
 	ctx = ctrl.IntoContext(context.Background(), operatorlogging.NopLogger)
+	ctx = options.ToContext(ctx, test.Options())
 	nodePoolWithMinValues := test.NodePool(v1.NodePool{
 		Spec: v1.NodePoolSpec{
 			Template: v1.NodeClaimTemplate{
@@ -175,7 +177,7 @@ func benchmarkScheduler(b *testing.B, instanceCount, podCount int) {
 
 	scheduler := scheduling.NewScheduler(ctx, client, []*v1.NodePool{nodePool},
 		cluster, nil, topology,
-		map[string][]*cloudprovider.InstanceType{nodePool.Name: instanceTypes}, nil,
+		map[string][]*cloudprovider.InstanceType{nodePool.Name: instanceTypes}, time.Time{}, nil, nil,
 		events.NewRecorder(&record.FakeRecorder{}), clock)
 
 	b.ResetTimer()
@@ -0,0 +1,233 @@
+//go:build test_performance
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+// The scale thresholds are deliberately loose so that they only catch regressions that change how the scheduler
+// scales, e.g. an accidental quadratic in the Requirements or Topology code, rather than noise between machines.
+const (
+	MaxSolveTimePerPod = 15 * time.Millisecond
+	MaxAllocsPerPod    = 20_000
+)
+
+// scaleScenario is a synthetic cluster of existing nodes, pending pods and nodepools with varied constraints that
+// scheduler.Solve is measured against
+type scaleScenario struct {
+	nodes     int
+	pods      int
+	nodePools int
+}
+
+func (s scaleScenario) String() string {
+	return fmt.Sprintf("%dNodes-%dPods-%dNodePools", s.nodes, s.pods, s.nodePools)
+}
+
+var scaleScenarios = []scaleScenario{
+	{nodes: 0, pods: 500, nodePools: 1},
+	{nodes: 100, pods: 1000, nodePools: 5},
+	{nodes: 500, pods: 2000, nodePools: 10},
+	{nodes: 1000, pods: 5000, nodePools: 20},
+}
+
+// To run the scale benchmarks use:
+// `go test -tags=test_performance -run=XXX -bench=SchedulingScale -benchmem`
+func BenchmarkSchedulingScale(b *testing.B) {
+	for _, s := range scaleScenarios {
+		b.Run(s.String(), func(b *testing.B) { benchmarkScale(b, s) })
+	}
+}
+
+// TestSchedulingScale reports the solve time and allocations of each scale scenario and fails when a scenario
+// exceeds the scale thresholds. This is what CI runs to catch performance regressions.
+// go test -tags=test_performance -run=SchedulingScale
+func TestSchedulingScale(t *testing.T) {
+	tw := tabwriter.NewWriter(os.Stdout, 8, 8, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "scenario\tnodeclaims\tper solve\tper pod\tallocs per pod\tbytes per pod\n")
+	for _, s := range scaleScenarios {
+		res := testing.Benchmark(func(b *testing.B) { benchmarkScale(b, s) })
+		if res.N == 0 {
+			t.Fatalf("benchmarking %s failed", s)
+		}
+		perPod := time.Duration(res.NsPerOp() / int64(s.pods))
+		allocsPerPod := res.AllocsPerOp() / int64(s.pods)
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\n", s, int(res.Extra["nodeclaims"]), time.Duration(res.NsPerOp()), perPod, allocsPerPod, res.AllocedBytesPerOp()/int64(s.pods))
+		if perPod > MaxSolveTimePerPod {
+			t.Errorf("%s took %s per pod, expected at most %s", s, perPod, MaxSolveTimePerPod)
+		}
+		if allocsPerPod > MaxAllocsPerPod {
+			t.Errorf("%s made %d allocations per pod, expected at most %d", s, allocsPerPod, MaxAllocsPerPod)
+		}
+	}
+}
+
+func benchmarkScale(b *testing.B, s scaleScenario) {
+	b.ReportAllocs()
+	// disable logging
+	ctx = ctrl.IntoContext(context.Background(), operatorlogging.NopLogger)
+	ctx = options.ToContext(ctx, test.Options())
+
+	nodePools := makeScaleNodePools(s.nodePools)
+	allInstanceTypes := fake.InstanceTypesAssorted()
+	instanceTypes := lo.SliceToMap(nodePools, func(np *v1.NodePool) (string, []*cloudprovider.InstanceType) {
+		return np.Name, allInstanceTypes
+	})
+	// Cluster state lists the pods on each node with the same index that the operator registers
+	kubeClient := fakecr.NewClientBuilder().WithIndex(&corev1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+		return []string{o.(*corev1.Pod).Spec.NodeName}
+	}).Build()
+	clk := &clock.RealClock{}
+	cluster = state.NewCluster(clk, kubeClient, fake.NewCloudProvider())
+	stateNodes := makeScaleNodes(b, kubeClient, nodePools, allInstanceTypes, s.nodes)
+	pods := append(makeDiversePods(s.pods/2), makeNodePoolConstrainedPods(s.pods-s.pods/2, nodePools)...)
+	domains := simulation.Domains(ctx, nodePools, instanceTypes)
+
+	var nodeClaims int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// The scheduler and topology are consumed by a solve, so they're rebuilt outside the timer for each run
+		b.StopTimer()
+		topology, err := scheduling.NewTopology(ctx, kubeClient, cluster, domains, pods)
+		if err != nil {
+			b.Fatalf("creating topology, %s", err)
+		}
+		b.StartTimer()
+		scheduler := scheduling.NewScheduler(ctx, kubeClient, nodePools, cluster, stateNodes, topology, instanceTypes, time.Time{}, nil, nil,
+			events.NewRecorder(&record.FakeRecorder{}), clk)
+		results := scheduler.Solve(ctx, pods)
+		nodeClaims = len(results.NewNodeClaims)
+	}
+	b.ReportMetric(float64(nodeClaims), "nodeclaims")
+}
+
+// makeScaleNodePools creates nodepools that constrain zones, capacity types and architectures differently, with every
+// fifth nodepool tainted so that only the pods that tolerate it schedule to it
+func makeScaleNodePools(count int) []*v1.NodePool {
+	zones := []string{"test-zone-1", "test-zone-2", "test-zone-3"}
+	capacityTypes := [][]string{{v1.CapacityTypeSpot}, {v1.CapacityTypeOnDemand}, {v1.CapacityTypeSpot, v1.CapacityTypeOnDemand}}
+	return lo.Times(count, func(i int) *v1.NodePool {
+		nodePool := test.NodePool(v1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("nodepool-%d", i)},
+			Spec: v1.NodePoolSpec{
+				Weight: lo.Ternary(i%2 == 0, lo.ToPtr(int32(i%100+1)), nil),
+				Template: v1.NodeClaimTemplate{
+					ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"pool-group": fmt.Sprintf("group-%d", i%4)}},
+					Spec: v1.NodeClaimTemplateSpec{
+						Requirements: []v1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: zones[:i%len(zones)+1]}},
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: capacityTypes[i%len(capacityTypes)]}},
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{lo.Ternary(i%4 == 3, v1.ArchitectureArm64, v1.ArchitectureAmd64)}}},
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelOSStable, Operator: corev1.NodeSelectorOpIn, Values: []string{string(corev1.Linux)}}},
+						},
+					},
+				},
+			},
+		})
+		if i%5 == 4 {
+			nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: nodePool.Name, Effect: corev1.TaintEffectNoSchedule}}
+		}
+		return nodePool
+	})
+}
+
+// makeScaleNodes creates initialized nodes that are spread across the nodepools and instance types
+func makeScaleNodes(b *testing.B, kubeClient client.Client, nodePools []*v1.NodePool, instanceTypes []*cloudprovider.InstanceType, count int) []*state.StateNode {
+	for i := 0; i < count; i++ {
+		nodePool := nodePools[i%len(nodePools)]
+		it := instanceTypes[r.Intn(len(instanceTypes))]
+		offering := it.Offerings[0]
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("node-%d", i),
+				Labels: lo.Assign(nodePool.Spec.Template.Labels, map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					v1.NodeInitializedLabelKey:     "true",
+					corev1.LabelInstanceTypeStable: it.Name,
+					corev1.LabelTopologyZone:       offering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					v1.CapacityTypeLabelKey:        offering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelArchStable:         it.Requirements.Get(corev1.LabelArchStable).Any(),
+					corev1.LabelOSStable:           it.Requirements.Get(corev1.LabelOSStable).Any(),
+				}),
+			},
+			ProviderID:  fmt.Sprintf("fake:///node-%d", i),
+			Taints:      nodePool.Spec.Template.Spec.Taints,
+			Allocatable: it.Allocatable(),
+		})
+		if err := kubeClient.Create(ctx, node); err != nil {
+			b.Fatalf("creating node, %s", err)
+		}
+		if err := cluster.UpdateNode(ctx, node); err != nil {
+			b.Fatalf("updating cluster state, %s", err)
+		}
+	}
+	return cluster.Nodes()
+}
+
+// makeNodePoolConstrainedPods creates pods that select a group of nodepools, some of which tolerate the taint of a
+// nodepool or constrain their zone with a node affinity
+func makeNodePoolConstrainedPods(count int, nodePools []*v1.NodePool) []*corev1.Pod {
+	return lo.Times(count, func(i int) *corev1.Pod {
+		nodePool := nodePools[r.Intn(len(nodePools))]
+		opts := test.PodOptions{
+			ObjectMeta:   metav1.ObjectMeta{Labels: randomLabels()},
+			NodeSelector: map[string]string{"pool-group": nodePool.Spec.Template.Labels["pool-group"]},
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    randomCPU(),
+					corev1.ResourceMemory: randomMemory(),
+				},
+			},
+		}
+		if len(nodePool.Spec.Template.Spec.Taints) > 0 {
+			opts.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: nodePool.Name, Effect: corev1.TaintEffectNoSchedule}}
+		}
+		if i%3 == 0 {
+			opts.NodeRequirements = []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}
+		}
+		return test.Pod(opts)
+	})
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/hashstructure/v2"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// shapeCache records the existing nodes, in-flight NodeClaims and NodeClaimTemplates that rejected a pod shape so that
+// identical pods in the same batch skip the compatibility checks that are known to fail. Existing nodes and in-flight
+// NodeClaims only become more constrained as pods are added to them and the remaining limits only decrease, so a
+// rejection stays valid until the topology counts change.
+type shapeCache struct {
+	topologyVersion int64
+	rejections      map[uint64]*shapeRejections
+}
+
+type shapeRejections struct {
+	existingNodes sets.Set[*ExistingNode]
+	nodeClaims    sets.Set[*NodeClaim]
+	templates     map[*NodeClaimTemplate]error
+}
+
+func newShapeRejections() *shapeRejections {
+	return &shapeRejections{
+		existingNodes: sets.New[*ExistingNode](),
+		nodeClaims:    sets.New[*NodeClaim](),
+		templates:     map[*NodeClaimTemplate]error{},
+	}
+}
+
+func newShapeCache() *shapeCache {
+	return &shapeCache{rejections: map[uint64]*shapeRejections{}}
+}
+
+// For returns the rejections recorded for the pod's shape. Pods that can't be cached get an empty set of rejections
+// that isn't stored.
+func (c *shapeCache) For(pod *corev1.Pod, podRequests corev1.ResourceList, topologyVersion int64) *shapeRejections {
+	if topologyVersion != c.topologyVersion {
+		c.topologyVersion = topologyVersion
+		c.rejections = map[uint64]*shapeRejections{}
+	}
+	shape, ok := podShape(pod, podRequests)
+	if !ok {
+		return newShapeRejections()
+	}
+	if _, ok := c.rejections[shape]; !ok {
+		c.rejections[shape] = newShapeRejections()
+	}
+	return c.rejections[shape]
+}
+
+// podShape hashes the parts of the pod that are relevant to scheduling. Pods with persistent volumes aren't cached
+// since their volume usage and volume topology are specific to the pod. The ResourceClaims are hashed by the claims that
+// they resolve to, since claims generated from a template are specific to the pod and can be allocated to different
+// nodes.
+func podShape(pod *corev1.Pod, podRequests corev1.ResourceList) (uint64, bool) {
+	if lo.ContainsBy(pod.Spec.Volumes, func(v corev1.Volume) bool { return v.PersistentVolumeClaim != nil || v.Ephemeral != nil }) {
+		return 0, false
+	}
+	shape, err := hashstructure.Hash(struct {
+		Namespace                 string
+		Labels                    map[string]string
+		InstanceFamilyPreference  string
+		Requests                  map[corev1.ResourceName]string
+		NodeSelector              map[string]string
+		Affinity                  *corev1.Affinity
+		Tolerations               []corev1.Toleration
+		TopologySpreadConstraints []corev1.TopologySpreadConstraint
+		HostPorts                 []string
+		ResourceClaims            []string
+		RuntimeClassName          string
+		PriorityClassName         string
+	}{
+		Namespace:                pod.Namespace,
+		Labels:                   pod.Labels,
+		InstanceFamilyPreference: pod.Annotations[v1.InstanceFamilyPreferenceAnnotationKey],
+		// Quantities only have unexported fields, so they're hashed by their string representation
+		Requests: lo.MapValues(podRequests, func(q resource.Quantity, _ corev1.ResourceName) string {
+			return q.String()
+		}),
+		NodeSelector:              pod.Spec.NodeSelector,
+		Affinity:                  pod.Spec.Affinity,
+		Tolerations:               pod.Spec.Tolerations,
+		TopologySpreadConstraints: pod.Spec.TopologySpreadConstraints,
+		HostPorts: lo.Map(scheduling.GetHostPorts(pod), func(p scheduling.HostPort, _ int) string {
+			return fmt.Sprintf("%s:%d/%s", p.IP, p.Port, p.Protocol)
+		}),
+		ResourceClaims: lo.Map(pod.Spec.ResourceClaims, func(c corev1.PodResourceClaim, _ int) string {
+			name, _ := resourceClaimName(pod, c)
+			return fmt.Sprintf("%s=%s", c.Name, name)
+		}),
+		RuntimeClassName:  lo.FromPtr(pod.Spec.RuntimeClassName),
+		PriorityClassName: pod.Spec.PriorityClassName,
+	}, hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true})
+	if err != nil {
+		return 0, false
+	}
+	return shape, true
+}
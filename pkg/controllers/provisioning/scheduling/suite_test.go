@@ -19,9 +19,13 @@ package scheduling_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -33,15 +37,19 @@ import (
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/tools/record"
 	cloudproviderapi "k8s.io/cloud-provider/api"
 	"k8s.io/csi-translation-lib/plugins"
 	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -55,9 +63,11 @@ import (
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	pscheduling "sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -93,7 +103,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster)
 	podStateController = informer.NewPodController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock)
+	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock, cloudprovider.NewUnhealthyOfferings(), cloudprovider.NewUnavailableOfferings(env.Client, fakeClock), pscheduling.NewLabelAliases())
 	podController = provisioning.NewPodController(env.Client, prov, cluster)
 })
 
@@ -1089,6 +1099,58 @@ var _ = Context("Scheduling", func() {
 		})
 	})
 
+	Describe("Compliance Requirements", func() {
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ComplianceRequirements: []corev1.NodeSelectorRequirement{
+				{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpNotIn, Values: []string{v1.CapacityTypeSpot}},
+				{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"test-zone-3"}},
+			}}))
+		})
+		It("should only launch capacity that satisfies the compliance requirements", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.CapacityTypeLabelKey, v1.CapacityTypeOnDemand))
+			Expect(node.Labels[corev1.LabelTopologyZone]).ToNot(Equal("test-zone-3"))
+		})
+		It("should not schedule pods that require capacity that the compliance requirements forbid", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := []*corev1.Pod{
+				test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeSpot}}),
+				test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-3"}}),
+			}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectNotScheduled(ctx, env.Client, pod)
+			}
+		})
+		It("should enforce the compliance requirements regardless of the nodepool's requirements", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeSpot}}},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should not spread pods into topology domains that the compliance requirements forbid", func() {
+			labels := map[string]string{"test": "test"}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}}, 4)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels[corev1.LabelTopologyZone]).To(BeElementOf("test-zone-1", "test-zone-2"))
+			}
+		})
+	})
+
 	Describe("Preferential Fallback", func() {
 		Context("Required", func() {
 			It("should not relax the final term", func() {
@@ -1815,6 +1877,41 @@ var _ = Context("Scheduling", func() {
 		})
 	})
 
+	Describe("Queue", func() {
+		var large, small, critical *corev1.Pod
+		var podRequests map[types.UID]corev1.ResourceList
+
+		BeforeEach(func() {
+			large = test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			}})
+			small = test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			critical = test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			critical.Spec.Priority = lo.ToPtr[int32](1000)
+			// the small pod was created first
+			small.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+			large.CreationTimestamp = metav1.NewTime(time.Now())
+			critical.CreationTimestamp = metav1.NewTime(time.Now())
+			podRequests = map[types.UID]corev1.ResourceList{}
+			for _, p := range []*corev1.Pod{large, small, critical} {
+				p.UID = uuid.NewUUID()
+				podRequests[p.UID] = resources.RequestsForPods(p)
+			}
+		})
+		It("should order pods for bin-packing when limits aren't contended", func() {
+			q := scheduling.NewQueue([]*corev1.Pod{small, critical, large}, podRequests, false)
+			Expect(q.List()[0]).To(Equal(large))
+		})
+		It("should order pods by priority and then creation time when limits are contended", func() {
+			q := scheduling.NewQueue([]*corev1.Pod{large, small, critical}, podRequests, true)
+			Expect(q.List()).To(Equal([]*corev1.Pod{critical, small, large}))
+		})
+	})
+
 	Describe("In-Flight Nodes", func() {
 		It("should not launch a second node if there is an in-flight node that can support the pod", func() {
 			opts := test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
@@ -2451,6 +2548,41 @@ var _ = Context("Scheduling", func() {
 			scheduledNode := ExpectScheduled(ctx, env.Client, pod)
 			Expect(node.Name).To(Equal(scheduledNode.Name))
 		})
+		It("should consider pod overhead when scheduling a pod to an existing node", func() {
+			node := test.Node(test.NodeOptions{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			// the pod has overhead of 2 CPUs
+			runtimeClass := &nodev1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "my-runtime-class",
+				},
+				Handler: "default",
+				Overhead: &nodev1.Overhead{
+					PodFixed: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("2"),
+					},
+				},
+			}
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				},
+			}})
+			pod.Spec.RuntimeClassName = &runtimeClass.Name
+			ExpectApplied(ctx, env.Client, nodePool, runtimeClass)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			// overhead of 2 + request of 1 = 3 CPUs, which doesn't fit on the existing node that only has 2 CPUs
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+		})
 		It("should schedule multiple pods to an existing node unowned by Karpenter", func() {
 			node := test.Node(test.NodeOptions{
 				Allocatable: corev1.ResourceList{
@@ -2480,6 +2612,37 @@ var _ = Context("Scheduling", func() {
 				Expect(node.Name).To(Equal(scheduledNode.Name))
 			}
 		})
+		It("should not schedule a pod into the headroom reserved on an existing node", func() {
+			node := test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.ReservedResourceAnnotationKeyPrefix + "cpu": "9",
+					},
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			fits := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, fits)
+			Expect(ExpectScheduled(ctx, env.Client, fits).Name).To(Equal(node.Name))
+
+			// Only 500m of unreserved cpu remains on the node, so this pod must go to a new node
+			exceedsHeadroom := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, exceedsHeadroom)
+			Expect(ExpectScheduled(ctx, env.Client, exceedsHeadroom).Name).ToNot(Equal(node.Name))
+		})
 		It("should order initialized nodes for scheduling uninitialized nodes", func() {
 			ExpectApplied(ctx, env.Client, nodePool)
 
@@ -2546,6 +2709,99 @@ var _ = Context("Scheduling", func() {
 			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
 			ExpectScheduled(ctx, env.Client, pod)
 		})
+		Context("Preferred Term Scoring", func() {
+			var allocatable corev1.ResourceList
+			BeforeEach(func() {
+				allocatable = corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				}
+			})
+			It("should schedule to the existing node that matches the most preferred node affinity weight", func() {
+				node1 := test.Node(test.NodeOptions{
+					ObjectMeta:  metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: "test-zone-1", corev1.LabelInstanceTypeStable: "small-instance-type"}},
+					Allocatable: allocatable,
+				})
+				node2 := test.Node(test.NodeOptions{
+					ObjectMeta:  metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: "test-zone-2", corev1.LabelInstanceTypeStable: "default-instance-type"}},
+					Allocatable: allocatable,
+				})
+				ExpectApplied(ctx, env.Client, nodePool, node1, node2)
+				ExpectMakeNodesInitialized(ctx, env.Client, node1, node2)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node1))
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node2))
+
+				pod := test.UnschedulablePod()
+				pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+					{
+						Weight: 30, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}},
+						}},
+					},
+					{
+						Weight: 20, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"default-instance-type"}},
+						}},
+					},
+				}}}
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(node2.Name))
+			})
+			It("should only score a preferred node affinity term for nodes that are guaranteed to match it", func() {
+				// node1 doesn't have a zone label, so it's compatible with the preferred zone without being in it
+				node1 := test.Node(test.NodeOptions{Allocatable: allocatable})
+				node2 := test.Node(test.NodeOptions{
+					ObjectMeta:  metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: "test-zone-2"}},
+					Allocatable: allocatable,
+				})
+				ExpectApplied(ctx, env.Client, nodePool, node1, node2)
+				ExpectMakeNodesInitialized(ctx, env.Client, node1, node2)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node1))
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node2))
+
+				pod := test.UnschedulablePod()
+				pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+					{
+						Weight: 10, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}},
+						}},
+					},
+				}}}
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(node2.Name))
+			})
+			It("should schedule to the existing node with the fewest pods that a relaxed preferred anti-affinity term selects", func() {
+				// prevent new nodes from being launched so that the anti-affinity term has to be relaxed
+				nodePool.Spec.Limits = v1.Limits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")})
+				node1 := test.Node(test.NodeOptions{Allocatable: allocatable})
+				node2 := test.Node(test.NodeOptions{Allocatable: allocatable})
+				ExpectApplied(ctx, env.Client, nodePool, node1, node2)
+				ExpectMakeNodesInitialized(ctx, env.Client, node1, node2)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node1))
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node2))
+
+				labels := map[string]string{"app": "foo"}
+				for _, node := range []*corev1.Node{node1, node1, node2} {
+					existingPod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}})
+					ExpectApplied(ctx, env.Client, existingPod)
+					ExpectManualBinding(ctx, env.Client, existingPod, node)
+					ExpectReconcileSucceeded(ctx, podStateController, client.ObjectKeyFromObject(existingPod))
+				}
+
+				pod := test.UnschedulablePod(test.PodOptions{PodAntiPreferences: []corev1.WeightedPodAffinityTerm{
+					{
+						Weight: 10,
+						PodAffinityTerm: corev1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+							TopologyKey:   corev1.LabelHostname,
+						},
+					},
+				}})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(node2.Name))
+			})
+		})
 		Context("Daemonsets", func() {
 			It("should not subtract daemonset overhead that is not strictly compatible with an existing node", func() {
 				nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
@@ -2709,6 +2965,60 @@ var _ = Context("Scheduling", func() {
 		})
 	})
 
+	Describe("Resource Claims", func() {
+		var pod *corev1.Pod
+		var claim *resourcev1beta1.ResourceClaim
+		BeforeEach(func() {
+			claim = &resourcev1beta1.ResourceClaim{ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: "default"}}
+			pod = test.UnschedulablePod()
+			pod.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimName: lo.ToPtr(claim.Name)}}
+		})
+		It("should reject pods with resource claims that aren't allocated", func() {
+			claimTopology := scheduling.NewResourceClaimTopology(fakecr.NewClientBuilder().WithObjects(claim).Build())
+			err := claimTopology.Validate(ctx, pod)
+			Expect(err).To(HaveOccurred())
+			Expect(scheduling.IsResourceClaimError(err)).To(BeTrue())
+		})
+		It("should reject pods with resource claims that haven't been created from their template", func() {
+			pod.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: lo.ToPtr("gpu-template")}}
+			claimTopology := scheduling.NewResourceClaimTopology(fakecr.NewClientBuilder().Build())
+			Expect(scheduling.IsResourceClaimError(claimTopology.Validate(ctx, pod))).To(BeTrue())
+		})
+		It("should ignore resource claims that didn't need to be generated from their template", func() {
+			pod.Spec.ResourceClaims = []corev1.PodResourceClaim{{Name: "gpu", ResourceClaimTemplateName: lo.ToPtr("gpu-template")}}
+			pod.Status.ResourceClaimStatuses = []corev1.PodResourceClaimStatus{{Name: "gpu"}}
+			claimTopology := scheduling.NewResourceClaimTopology(fakecr.NewClientBuilder().Build())
+			Expect(claimTopology.Validate(ctx, pod)).To(Succeed())
+		})
+		It("should constrain pods to the node selector of their allocated resource claims", func() {
+			claim.Status.Allocation = &resourcev1beta1.AllocationResult{NodeSelector: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+			}}}}
+			claimTopology := scheduling.NewResourceClaimTopology(fakecr.NewClientBuilder().WithObjects(claim).Build())
+			Expect(claimTopology.Validate(ctx, pod)).To(Succeed())
+			Expect(claimTopology.Inject(ctx, pod)).To(Succeed())
+			Expect(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+			}))
+		})
+		It("should constrain pods to the hostname of the nodes that their resource claims are allocated on", func() {
+			node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelHostname: "gpu-host"}}})
+			claim.Status.Allocation = &resourcev1beta1.AllocationResult{NodeSelector: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{node.Name}}},
+			}}}}
+			claimTopology := scheduling.NewResourceClaimTopology(fakecr.NewClientBuilder().WithObjects(claim, node).Build())
+			Expect(claimTopology.Inject(ctx, pod)).To(Succeed())
+			Expect(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu-host"}}},
+			}))
+		})
+		It("should not constrain pods with resource claims that aren't local to a node", func() {
+			claim.Status.Allocation = &resourcev1beta1.AllocationResult{}
+			claimTopology := scheduling.NewResourceClaimTopology(fakecr.NewClientBuilder().WithObjects(claim).Build())
+			Expect(claimTopology.Inject(ctx, pod)).To(Succeed())
+			Expect(pod.Spec.Affinity).To(BeNil())
+		})
+	})
 	Describe("VolumeUsage", func() {
 		BeforeEach(func() {
 			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
@@ -3466,6 +3776,27 @@ var _ = Context("Scheduling", func() {
 				Expect(n.Labels[corev1.LabelInstanceTypeStable]).To(Equal("small-instance-type"))
 			}
 		})
+		It("should re-schedule pods from a deleting node into the zone of their volumes", func() {
+			persistentVolume := test.PersistentVolume(test.PersistentVolumeOptions{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{corev1.LabelTopologyZone: "test-zone-3"},
+			}})
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{VolumeName: persistentVolume.Name})
+			ExpectApplied(ctx, env.Client, nodePool, persistentVolumeClaim, persistentVolume)
+			pod := test.UnschedulablePod(test.PodOptions{PersistentVolumeClaims: []string{persistentVolumeClaim.Name}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-3"))
+
+			// Mark for deletion as if the node was interrupted so that its pods are rescheduled
+			cluster.MarkForDeletion(node.Spec.ProviderID)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+
+			nodes := ExpectNodes(ctx, env.Client)
+			Expect(nodes).To(HaveLen(2))
+			for _, n := range nodes {
+				Expect(n.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-3"))
+			}
+		})
 		It("should not re-schedule pods from a deleting node when pods are not active", func() {
 			ExpectApplied(ctx, env.Client, nodePool)
 			pod := test.UnschedulablePod(
@@ -3643,6 +3974,444 @@ var _ = Context("Scheduling", func() {
 		})
 	})
 
+	Describe("Soft Max Node Size", func() {
+		BeforeEach(func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "small",
+					Resources: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+						corev1.ResourcePods:   resource.MustParse("100"),
+					},
+				}),
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "large",
+					Resources: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("32"),
+						corev1.ResourceMemory: resource.MustParse("64Gi"),
+						corev1.ResourcePods:   resource.MustParse("100"),
+					},
+				}),
+			}
+		})
+		It("should pack pods onto a single large node without a soft max node size", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}}, 6)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodeNames := sets.New[string]()
+			for _, p := range pods {
+				nodeNames.Insert(ExpectScheduled(ctx, env.Client, p).Name)
+			}
+			Expect(nodeNames).To(HaveLen(1))
+		})
+		It("should launch additional nodes rather than growing a node past the soft max node size", func() {
+			nodePool.Spec.SoftMaxNodeSize = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}}, 6)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodeNames := sets.New[string]()
+			for _, p := range pods {
+				node := ExpectScheduled(ctx, env.Client, p)
+				Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("small"))
+				nodeNames.Insert(node.Name)
+			}
+			Expect(nodeNames).To(HaveLen(2))
+		})
+		It("should exceed the soft max node size for a pod that can't fit within it on its own", func() {
+			nodePool.Spec.SoftMaxNodeSize = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}
+			ExpectApplied(ctx, env.Client, nodePool)
+			large := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+			}})
+			small := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, large, small)
+			largeNode := ExpectScheduled(ctx, env.Client, large)
+			Expect(largeNode.Labels[corev1.LabelInstanceTypeStable]).To(Equal("large"))
+			// the oversized node doesn't take on any more pods
+			Expect(ExpectScheduled(ctx, env.Client, small).Name).ToNot(Equal(largeNode.Name))
+		})
+	})
+
+	Describe("Zone Balancing", func() {
+		var labels map[string]string
+		var antiAffinity []corev1.PodAffinityTerm
+		BeforeEach(func() {
+			labels = map[string]string{"test": "test"}
+			antiAffinity = []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+				TopologyKey:   corev1.LabelHostname,
+			}}
+		})
+		It("should leave the zone to the cloud provider when zone balancing isn't configured", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			zones := pscheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(corev1.LabelTopologyZone)
+			Expect(zones.Len()).To(BeNumerically(">", 1))
+		})
+		It("should spread new nodeclaims across zones when balanced", func() {
+			nodePool.Spec.ZoneBalancing = v1.ZoneBalancingBalanced
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, PodAntiRequirements: antiAffinity}, 3)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			zones := sets.New[string]()
+			for _, p := range pods {
+				zones.Insert(ExpectScheduled(ctx, env.Client, p).Labels[corev1.LabelTopologyZone])
+			}
+			Expect(sets.List(zones)).To(ConsistOf("test-zone-1", "test-zone-2", "test-zone-3"))
+		})
+		It("should balance new nodeclaims into the zones with the fewest existing nodes", func() {
+			nodePool.Spec.ZoneBalancing = v1.ZoneBalancingBalanced
+			ExpectApplied(ctx, env.Client, nodePool)
+			for _, zone := range []string{"test-zone-1", "test-zone-2"} {
+				nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+						v1.NodePoolLabelKey:      nodePool.Name,
+						corev1.LabelTopologyZone: zone,
+					}},
+					Status: v1.NodeClaimStatus{Allocatable: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0")}},
+				})
+				ExpectApplied(ctx, env.Client, nodeClaim, node)
+				ExpectMakeNodeClaimsInitialized(ctx, env.Client, nodeClaim)
+				ExpectMakeNodesInitialized(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaim))
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			}
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels[corev1.LabelTopologyZone]).To(Equal("test-zone-3"))
+		})
+		It("should pack new nodeclaims into the zone with the cheapest offering when packed", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "default-instance-type",
+					Offerings: lo.Map([]string{"test-zone-1", "test-zone-2", "test-zone-3"}, func(zone string, i int) cloudprovider.Offering {
+						return cloudprovider.Offering{
+							Requirements: pscheduling.NewLabelRequirements(map[string]string{
+								v1.CapacityTypeLabelKey:  v1.CapacityTypeOnDemand,
+								corev1.LabelTopologyZone: zone,
+							}),
+							// test-zone-2 is the cheapest zone
+							Price:     []float64{2.00, 1.00, 3.00}[i],
+							Available: true,
+						}
+					}),
+				}),
+			}
+			nodePool.Spec.ZoneBalancing = v1.ZoneBalancingPacked
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, PodAntiRequirements: antiAffinity}, 3)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, p := range pods {
+				Expect(ExpectScheduled(ctx, env.Client, p).Labels[corev1.LabelTopologyZone]).To(Equal("test-zone-2"))
+			}
+		})
+		It("should not narrow new nodeclaims into a zone that can't satisfy minValues", func() {
+			offering := func(zone string, price float64) cloudprovider.Offering {
+				return cloudprovider.Offering{
+					Requirements: pscheduling.NewLabelRequirements(map[string]string{
+						v1.CapacityTypeLabelKey:  v1.CapacityTypeOnDemand,
+						corev1.LabelTopologyZone: zone,
+					}),
+					Price:     price,
+					Available: true,
+				}
+			}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				// test-zone-2 is the cheapest zone, but only offers one instance type
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:      "instance-type-1",
+					Offerings: []cloudprovider.Offering{offering("test-zone-1", 2.00), offering("test-zone-2", 1.00)},
+				}),
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:      "instance-type-2",
+					Offerings: []cloudprovider.Offering{offering("test-zone-1", 3.00)},
+				}),
+			}
+			nodePool.Spec.ZoneBalancing = v1.ZoneBalancingPacked
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+						Key:      corev1.LabelInstanceTypeStable,
+						Operator: corev1.NodeSelectorOpExists,
+					},
+					MinValues: lo.ToPtr(2),
+				},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels[corev1.LabelTopologyZone]).To(Equal("test-zone-1"))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(pscheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(corev1.LabelInstanceTypeStable).Len()).To(Equal(2))
+		})
+		It("should not override the zone that pods are constrained to", func() {
+			nodePool.Spec.ZoneBalancing = v1.ZoneBalancingBalanced
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{
+				ObjectMeta:          metav1.ObjectMeta{Labels: labels},
+				PodAntiRequirements: antiAffinity,
+				NodeSelector:        map[string]string{corev1.LabelTopologyZone: "test-zone-1"},
+			}, 2)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, p := range pods {
+				Expect(ExpectScheduled(ctx, env.Client, p).Labels[corev1.LabelTopologyZone]).To(Equal("test-zone-1"))
+			}
+		})
+	})
+
+	Describe("Host Ports", func() {
+		It("should schedule pods with different host ports to the same node", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod1 := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{80}})
+			pod2 := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{443}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod1, pod2)
+			node1 := ExpectScheduled(ctx, env.Client, pod1)
+			node2 := ExpectScheduled(ctx, env.Client, pod2)
+			Expect(node1.Name).To(Equal(node2.Name))
+		})
+		It("should schedule pods with the same host port but different protocols to the same node", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod1 := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{53}})
+			pod2 := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{53}})
+			pod2.Spec.Containers[0].Ports[0].Protocol = corev1.ProtocolUDP
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod1, pod2)
+			node1 := ExpectScheduled(ctx, env.Client, pod1)
+			node2 := ExpectScheduled(ctx, env.Client, pod2)
+			Expect(node1.Name).To(Equal(node2.Name))
+		})
+		It("should schedule pods with conflicting host ports to different nodes", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod1 := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{80}})
+			pod2 := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{80}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod1, pod2)
+			node1 := ExpectScheduled(ctx, env.Client, pod1)
+			node2 := ExpectScheduled(ctx, env.Client, pod2)
+			Expect(node1.Name).ToNot(Equal(node2.Name))
+		})
+		It("should not schedule a pod to an existing node where its host port is already in use", func() {
+			node := test.Node(test.NodeOptions{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			existingPod := test.Pod(test.PodOptions{HostPorts: []int32{80}})
+			ExpectApplied(ctx, env.Client, nodePool, node, existingPod)
+			ExpectManualBinding(ctx, env.Client, existingPod, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			pod := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{80}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+		})
+		It("should not schedule a pod whose host port conflicts with a daemonset on every new node", func() {
+			ExpectApplied(ctx, env.Client, nodePool, test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{HostPorts: []int32{9100}}}))
+			pod := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{9100}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should not schedule a pod to an existing node where a daemonset will use its host port", func() {
+			node := test.Node(test.NodeOptions{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ds := test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{HostPorts: []int32{9100}}})
+			ExpectApplied(ctx, env.Client, node, ds)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			pod := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{9100}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should schedule a pod with a host port that doesn't conflict with a daemonset", func() {
+			ExpectApplied(ctx, env.Client, nodePool, test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{HostPorts: []int32{9100}}}))
+			pod := test.UnschedulablePod(test.PodOptions{HostPorts: []int32{8080}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+	})
+
+	Describe("Pod Shape Caching", func() {
+		It("should not reuse nodeclaims that rejected an identical pod", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{HostPorts: []int32{80}}, 5)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodeNames := sets.New[string]()
+			for _, p := range pods {
+				nodeNames.Insert(ExpectScheduled(ctx, env.Client, p).Name)
+			}
+			Expect(nodeNames).To(HaveLen(5))
+		})
+		It("should invalidate cached rejections when topology spread counts change", func() {
+			labels := map[string]string{"test": "test"}
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 9)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(3, 3, 3))
+		})
+		It("should invalidate cached rejections when identical pods have anti-affinity to each other", func() {
+			labels := map[string]string{"test": "test"}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				PodAntiRequirements: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+					TopologyKey:   corev1.LabelHostname,
+				}},
+			}, 4)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodeNames := sets.New[string]()
+			for _, p := range pods {
+				nodeNames.Insert(ExpectScheduled(ctx, env.Client, p).Name)
+			}
+			Expect(nodeNames).To(HaveLen(4))
+		})
+	})
+
+	Describe("Instance Type Staleness", func() {
+		It("should defer the remaining pods when the instance types exceed their maximum age", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{}, 3)
+			s, err := prov.NewScheduler(ctx, pods, nil)
+			Expect(err).To(BeNil())
+			fakeClock.Step(10 * time.Minute)
+			results := s.Solve(ctx, pods)
+			Expect(results.NewNodeClaims).To(BeEmpty())
+			Expect(results.StalePods()).To(HaveLen(len(pods)))
+			for _, p := range pods {
+				Expect(scheduling.IsInstanceTypesStaleError(results.PodErrors[p])).To(BeTrue())
+			}
+		})
+		It("should schedule pods when the instance types are within their maximum age", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{}, 3)
+			s, err := prov.NewScheduler(ctx, pods, nil)
+			Expect(err).To(BeNil())
+			fakeClock.Step(time.Minute)
+			results := s.Solve(ctx, pods)
+			Expect(results.NewNodeClaims).ToNot(BeEmpty())
+			Expect(results.StalePods()).To(BeEmpty())
+		})
+		It("should not defer pods for simulations that ignore the maximum age", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{}, 3)
+			s, err := prov.NewScheduler(ctx, pods, nil, simulation.WithoutInstanceTypesMaxAge())
+			Expect(err).To(BeNil())
+			fakeClock.Step(10 * time.Minute)
+			results := s.Solve(ctx, pods)
+			Expect(results.NewNodeClaims).ToNot(BeEmpty())
+			Expect(results.PodErrors).To(BeEmpty())
+		})
+		It("should not defer pods when the maximum age is disabled", func() {
+			ctx := options.ToContext(ctx, test.Options(test.OptionsFields{InstanceTypesMaxAge: lo.ToPtr(time.Duration(0))}))
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{}, 3)
+			s, err := prov.NewScheduler(ctx, pods, nil)
+			Expect(err).To(BeNil())
+			fakeClock.Step(time.Hour)
+			results := s.Solve(ctx, pods)
+			Expect(results.NewNodeClaims).ToNot(BeEmpty())
+			Expect(results.PodErrors).To(BeEmpty())
+		})
+	})
+
+	Describe("Explanations", func() {
+		BeforeEach(func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+		})
+		It("should break down the pod error by the requirement keys that didn't match", func() {
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-2"}})
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(injection.WithControllerName(ctx, "provisioner"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(HaveLen(1))
+			podErr := lo.Values(results.PodErrors)[0]
+			Expect(podErr.Error()).To(ContainSubstring(fmt.Sprintf(`incompatible with nodepool %q`, nodePool.Name)))
+			Expect(podErr.Error()).To(ContainSubstring(fmt.Sprintf(`key %q: pod requires`, corev1.LabelTopologyZone)))
+
+			incompatibleErr := scheduling.NodePoolIncompatibleError{}
+			Expect(errors.As(podErr, &incompatibleErr)).To(BeTrue())
+			Expect(incompatibleErr.NodePool).To(Equal(nodePool.Name))
+			Expect(incompatibleErr.Requirements).To(HaveLen(1))
+			Expect(incompatibleErr.Requirements[0].Key).To(Equal(corev1.LabelTopologyZone))
+			Expect(incompatibleErr.Requirements[0].Existing).To(ContainSubstring("test-zone-1"))
+			Expect(incompatibleErr.Requirements[0].Incoming).To(ContainSubstring("test-zone-2"))
+		})
+		It("should explain undefined requirement keys", func() {
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"test-key": "test-value"}})
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(injection.WithControllerName(ctx, "provisioner"))
+			Expect(err).ToNot(HaveOccurred())
+			incompatibleErr := scheduling.NodePoolIncompatibleError{}
+			Expect(errors.As(lo.Values(results.PodErrors)[0], &incompatibleErr)).To(BeTrue())
+			Expect(incompatibleErr.Requirements).To(HaveLen(1))
+			Expect(incompatibleErr.Requirements[0].Key).To(Equal("test-key"))
+			Expect(incompatibleErr.Requirements[0].Existing).To(BeEmpty())
+			Expect(incompatibleErr.Error()).To(ContainSubstring(`but the nodepool doesn't define the key`))
+		})
+		It("should serve the explanations from the most recent scheduling run", func() {
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-2"}})
+			ExpectApplied(ctx, env.Client, pod)
+			_, err := prov.Schedule(injection.WithControllerName(ctx, "provisioner"))
+			Expect(err).ToNot(HaveOccurred())
+
+			w := httptest.NewRecorder()
+			prov.Explanations().ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/?pod=%s/%s", pod.Namespace, pod.Name), nil))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			explanation := map[string]any{}
+			Expect(json.Unmarshal(w.Body.Bytes(), &explanation)).To(Succeed())
+			Expect(explanation["pod"]).To(Equal(client.ObjectKeyFromObject(pod).String()))
+			nodePools := explanation["nodePools"].([]any)
+			Expect(nodePools).To(HaveLen(1))
+			Expect(nodePools[0]).To(HaveKeyWithValue("nodePool", nodePool.Name))
+			Expect(nodePools[0]).To(HaveKey("requirements"))
+			Expect(nodePools[0]).To(HaveKey("error"))
+
+			w = httptest.NewRecorder()
+			prov.Explanations().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?pod=default/missing", nil))
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+		It("should clear explanations once pods schedule", func() {
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-2"}})
+			ExpectApplied(ctx, env.Client, pod)
+			_, err := prov.Schedule(injection.WithControllerName(ctx, "provisioner"))
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := prov.Explanations().Get(client.ObjectKeyFromObject(pod).String())
+			Expect(ok).To(BeTrue())
+
+			ExpectDeleted(ctx, env.Client, pod)
+			_, err = prov.Schedule(injection.WithControllerName(ctx, "provisioner"))
+			Expect(err).ToNot(HaveOccurred())
+			_, ok = prov.Explanations().Get(client.ObjectKeyFromObject(pod).String())
+			Expect(ok).To(BeFalse())
+		})
+	})
 	Describe("Metrics", func() {
 		It("should surface the queueDepth metric while executing the scheduling loop", func() {
 			nodePool = test.NodePool()
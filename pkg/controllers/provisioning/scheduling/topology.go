@@ -55,6 +55,9 @@ type Topology struct {
 	// moving pods to prevent them from being double counted.
 	excludedPods sets.Set[string]
 	cluster      *state.Cluster
+	// version is incremented whenever the topology counts or the pods that own topology groups change so that
+	// scheduling decisions that depend on the topology can be invalidated
+	version int64
 }
 
 func NewTopology(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, domains map[string]sets.Set[string], pods []*corev1.Pod) (*Topology, error) {
@@ -101,6 +104,7 @@ func (t topologyError) Error() string {
 // relaxation of a preference to properly break the topology <-> owner relationship so that the preferred topology will
 // no longer influence scheduling.
 func (t *Topology) Update(ctx context.Context, p *corev1.Pod) error {
+	t.version++
 	for _, topology := range t.topologies {
 		topology.RemoveOwner(p.UID)
 	}
@@ -138,6 +142,7 @@ func (t *Topology) Record(p *corev1.Pod, requirements scheduling.Requirements, c
 	// once we've committed to a domain, we record the usage in every topology that cares about it
 	for _, tc := range t.topologies {
 		if tc.Counts(p, requirements, compatabilityOptions...) {
+			t.version++
 			domains := requirements.Get(tc.Key)
 			if tc.Type == TopologyTypePodAntiAffinity {
 				// for anti-affinity topologies we need to block out all possible domains that the pod could land in
@@ -154,11 +159,18 @@ func (t *Topology) Record(p *corev1.Pod, requirements scheduling.Requirements, c
 	// requirements haven't collapsed to a single value.
 	for _, tc := range t.inverseTopologies {
 		if tc.IsOwnedBy(p.UID) {
+			t.version++
 			tc.Record(requirements.Get(tc.Key).Values()...)
 		}
 	}
 }
 
+// Version returns a value that changes whenever the topology counts change. Registering domains doesn't change the
+// version since it only tightens the requirements that the topology enforces.
+func (t *Topology) Version() int64 {
+	return t.version
+}
+
 // AddRequirements tightens the input requirements by adding additional requirements that are being enforced by topology spreads
 // affinities, anti-affinities or inverse anti-affinities.  The nodeHostname is the hostname that we are currently considering
 // placing the pod on.  It returns these newly tightened requirements, or an error in the case of a set of requirements that
@@ -366,6 +378,16 @@ func (t *Topology) newForAffinities(ctx context.Context, p *corev1.Pod) ([]*Topo
 	return topologyGroups, nil
 }
 
+// affinityGroup returns the topology group that tracks the domain counts of the pod's pod (anti-)affinity term, or nil
+// if the topology hasn't been updated with the pod
+func (t *Topology) affinityGroup(ctx context.Context, topologyType TopologyType, p *corev1.Pod, term corev1.PodAffinityTerm) (*TopologyGroup, error) {
+	namespaces, err := t.buildNamespaceList(ctx, p.Namespace, term.Namespaces, term.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	return t.topologies[NewTopologyGroup(topologyType, term.TopologyKey, p, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey]).Hash()], nil
+}
+
 // buildNamespaceList constructs a unique list of namespaces consisting of the pod's namespace and the optional list of
 // namespaces and those selected by the namespace selector
 func (t *Topology) buildNamespaceList(ctx context.Context, namespace string, namespaces []string, selector *metav1.LabelSelector) (sets.Set[string], error) {
@@ -923,6 +923,107 @@ var _ = Describe("Topology", func() {
 		})
 	})
 
+	Context("NodePool", func() {
+		It("should balance pods across NodePools", func() {
+			spotNodePool := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{
+				Requirements: []v1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeSpot}}},
+				},
+			}}}})
+			onDemandNodePool := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{
+				Requirements: []v1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeOnDemand}}},
+				},
+			}}}})
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       v1.NodePoolLabelKey,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, spotNodePool, onDemandNodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 4)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(2, 2))
+		})
+		It("should only spread across NodePools that are compatible with the pod", func() {
+			spotNodePool := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{
+				Requirements: []v1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeSpot}}},
+				},
+			}}}})
+			onDemandNodePool := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{
+				Requirements: []v1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeOnDemand}}},
+				},
+			}}}})
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       v1.NodePoolLabelKey,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, spotNodePool, onDemandNodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{
+					ObjectMeta:                metav1.ObjectMeta{Labels: labels},
+					NodeSelector:              map[string]string{v1.NodePoolLabelKey: spotNodePool.Name},
+					TopologySpreadConstraints: topology,
+				}, 4)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(4))
+		})
+	})
+	Context("Custom Topology Domains", func() {
+		const rackLabelKey = "example.com/rack"
+		It("should balance pods across the topology domains declared on the NodePool", func() {
+			nodePool.Spec.TopologyDomains = []v1.TopologyDomain{{Key: rackLabelKey, Values: []string{"rack-1", "rack-2", "rack-3"}}}
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       rackLabelKey,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 6)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(2, 2, 2))
+		})
+		It("should only spread across the declared domains that are allowed by the NodePool's requirements", func() {
+			nodePool.Spec.TopologyDomains = []v1.TopologyDomain{{Key: rackLabelKey, Values: []string{"rack-1", "rack-2", "rack-3"}}}
+			nodePool.Spec.Template.Spec.Requirements = append(nodePool.Spec.Template.Spec.Requirements, v1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: rackLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"rack-1", "rack-2"}},
+			})
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       rackLabelKey,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+				MaxSkew:           1,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov,
+				test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}, TopologySpreadConstraints: topology}, 4)...,
+			)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(2, 2))
+		})
+		It("should schedule pods that select a declared domain", func() {
+			nodePool.Spec.TopologyDomains = []v1.TopologyDomain{{Key: rackLabelKey, Values: []string{"rack-1", "rack-2"}}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{rackLabelKey: "rack-2"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(rackLabelKey, "rack-2"))
+		})
+		It("should not schedule pods that select an undeclared domain", func() {
+			nodePool.Spec.TopologyDomains = []v1.TopologyDomain{{Key: rackLabelKey, Values: []string{"rack-1", "rack-2"}}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{rackLabelKey: "rack-3"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+	})
 	Context("Combined Hostname and Zonal Topology", func() {
 		It("should spread pods while respecting both constraints (hostname and zonal)", func() {
 			topology := []corev1.TopologySpreadConstraint{{
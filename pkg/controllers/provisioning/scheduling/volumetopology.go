@@ -19,6 +19,7 @@ package scheduling
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
@@ -31,6 +32,9 @@ import (
 	volumeutil "sigs.k8s.io/karpenter/pkg/utils/volume"
 )
 
+// zoneLabelDelimiter separates the zones in the zone label of a PersistentVolume that's replicated across zones
+const zoneLabelDelimiter = "__"
+
 func NewVolumeTopology(kubeClient client.Client) *VolumeTopology {
 	return &VolumeTopology{kubeClient: kubeClient}
 }
@@ -51,6 +55,18 @@ func (v *VolumeTopology) Inject(ctx context.Context, pod *v1.Pod) error {
 	if len(requirements) == 0 {
 		return nil
 	}
+	// We add our volume topology zonal requirement to every node selector term.  This causes it to be AND'd with every existing
+	// requirement so that relaxation won't remove our volume requirement.
+	injectRequiredNodeAffinity(pod, requirements)
+
+	log.FromContext(ctx).
+		WithValues("Pod", klog.KRef(pod.Namespace, pod.Name)).
+		V(1).Info(fmt.Sprintf("adding requirements derived from pod volumes, %s", requirements))
+	return nil
+}
+
+// injectRequiredNodeAffinity adds the requirements to every required node affinity term of the pod
+func injectRequiredNodeAffinity(pod *v1.Pod, requirements []v1.NodeSelectorRequirement) {
 	if pod.Spec.Affinity == nil {
 		pod.Spec.Affinity = &v1.Affinity{}
 	}
@@ -64,17 +80,10 @@ func (v *VolumeTopology) Inject(ctx context.Context, pod *v1.Pod) error {
 		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = []v1.NodeSelectorTerm{{}}
 	}
 
-	// We add our volume topology zonal requirement to every node selector term.  This causes it to be AND'd with every existing
-	// requirement so that relaxation won't remove our volume requirement.
 	for i := 0; i < len(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms); i++ {
 		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[i].MatchExpressions = append(
 			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[i].MatchExpressions, requirements...)
 	}
-
-	log.FromContext(ctx).
-		WithValues("Pod", klog.KRef(pod.Namespace, pod.Name)).
-		V(1).Info(fmt.Sprintf("adding requirements derived from pod volumes, %s", requirements))
-	return nil
 }
 
 func (v *VolumeTopology) getRequirements(ctx context.Context, pod *v1.Pod, volume v1.Volume) ([]v1.NodeSelectorRequirement, error) {
@@ -126,11 +135,8 @@ func (v *VolumeTopology) getPersistentVolumeRequirements(ctx context.Context, po
 	if err := v.kubeClient.Get(ctx, types.NamespacedName{Name: volumeName, Namespace: pod.Namespace}, pv); err != nil {
 		return nil, fmt.Errorf("getting persistent volume %q, %w", volumeName, err)
 	}
-	if pv.Spec.NodeAffinity == nil {
-		return nil, nil
-	}
-	if pv.Spec.NodeAffinity.Required == nil {
-		return nil, nil
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return getPersistentVolumeZoneRequirements(pv), nil
 	}
 	var requirements []v1.NodeSelectorRequirement
 	if len(pv.Spec.NodeAffinity.Required.NodeSelectorTerms) > 0 {
@@ -147,6 +153,19 @@ func (v *VolumeTopology) getPersistentVolumeRequirements(ctx context.Context, po
 	return requirements, nil
 }
 
+// getPersistentVolumeZoneRequirements returns the zone requirements of a PersistentVolume that doesn't have a node
+// affinity. Volumes from in-tree provisioners only record their zone in their labels, and pods that are rescheduled
+// off of a node, e.g. when it's interrupted, must land in that zone for the volume to attach.
+func getPersistentVolumeZoneRequirements(pv *v1.PersistentVolume) []v1.NodeSelectorRequirement {
+	for _, key := range []string{v1.LabelTopologyZone, v1.LabelFailureDomainBetaZone} {
+		if zones, ok := pv.Labels[key]; ok && zones != "" {
+			// Volumes that are replicated across zones join the zones with a delimiter
+			return []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: strings.Split(zones, zoneLabelDelimiter)}}
+		}
+	}
+	return nil
+}
+
 // ValidatePersistentVolumeClaims returns an error if the pod doesn't appear to be valid with respect to
 // PVCs (e.g. the PVC is not found or references an unknown storage class).
 func (v *VolumeTopology) ValidatePersistentVolumeClaims(ctx context.Context, pod *v1.Pod) error {
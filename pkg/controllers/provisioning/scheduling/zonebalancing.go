@@ -0,0 +1,126 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"sort"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// balanceZones narrows the zone of the new NodeClaims that can launch into more than one zone according to the zone
+// balancing policy of their NodePool. Only the zones that every pod on the NodeClaim can already schedule to are
+// considered, so the policy only breaks ties between zones that scheduling has no preference between.
+func (s *Scheduler) balanceZones() {
+	// (NodePool name) -> (zone) -> number of nodes
+	nodeCounts := map[string]map[string]int{}
+	countNode := func(nodePoolName, zone string) {
+		if _, ok := nodeCounts[nodePoolName]; !ok {
+			nodeCounts[nodePoolName] = map[string]int{}
+		}
+		nodeCounts[nodePoolName][zone]++
+	}
+	for _, n := range s.existingNodes {
+		if zone, ok := n.Labels()[corev1.LabelTopologyZone]; ok {
+			countNode(n.Labels()[v1.NodePoolLabelKey], zone)
+		}
+	}
+	for _, nodeClaim := range s.newNodeClaims {
+		if zone, ok := nodeClaim.balanceZone(nodeCounts[nodeClaim.NodePoolName]); ok {
+			countNode(nodeClaim.NodePoolName, zone)
+		}
+	}
+}
+
+// balanceZone restricts the NodeClaim to a single zone using its zone balancing policy, returning the zone that the
+// NodeClaim will launch into if it's known
+func (n *NodeClaim) balanceZone(nodeCounts map[string]int) (string, bool) {
+	zones := n.offeredZones()
+	if len(zones) == 1 {
+		return zones[0], true
+	}
+	if n.ZoneBalancing != v1.ZoneBalancingBalanced && n.ZoneBalancing != v1.ZoneBalancingPacked {
+		return "", false
+	}
+	type option struct {
+		zone          string
+		requirements  scheduling.Requirements
+		instanceTypes []*cloudprovider.InstanceType
+		price         float64
+	}
+	var options []option
+	for _, zone := range zones {
+		requirements := scheduling.NewRequirements(n.Requirements.Values()...)
+		requirements.Add(scheduling.NewRequirement(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, zone))
+		filtered := filterInstanceTypesByRequirements(n.InstanceTypeOptions, requirements, n.Spec.Resources.Requests)
+		if len(filtered.remaining) == 0 {
+			continue
+		}
+		// narrowing to a zone can't drop the NodeClaim below the minimum number of instance types it needs
+		if requirements.HasMinValues() {
+			if _, err := cloudprovider.InstanceTypes(filtered.remaining).SatisfiesMinValues(requirements); err != nil {
+				continue
+			}
+		}
+		options = append(options, option{
+			zone:          zone,
+			requirements:  requirements,
+			instanceTypes: filtered.remaining,
+			price: lo.Min(lo.Map(filtered.remaining, func(it *cloudprovider.InstanceType, _ int) float64 {
+				return it.Offerings.Available().Compatible(requirements).Cheapest().Price
+			})),
+		})
+	}
+	if len(options) == 0 {
+		return "", false
+	}
+	// zones are already sorted by name so a stable sort keeps the choice deterministic when the policy is tied
+	sort.SliceStable(options, func(i, j int) bool {
+		if n.ZoneBalancing == v1.ZoneBalancingBalanced {
+			if nodeCounts[options[i].zone] != nodeCounts[options[j].zone] {
+				return nodeCounts[options[i].zone] < nodeCounts[options[j].zone]
+			}
+			return options[i].price < options[j].price
+		}
+		if options[i].price != options[j].price {
+			return options[i].price < options[j].price
+		}
+		return nodeCounts[options[i].zone] > nodeCounts[options[j].zone]
+	})
+	n.Requirements = options[0].requirements
+	n.InstanceTypeOptions = options[0].instanceTypes
+	return options[0].zone, true
+}
+
+// offeredZones returns the sorted zones that the NodeClaim's instance types have available offerings in
+func (n *NodeClaim) offeredZones() []string {
+	zones := sets.New[string]()
+	for _, it := range n.InstanceTypeOptions {
+		for _, o := range it.Offerings.Available().Compatible(n.Requirements) {
+			if zone := o.Requirements.Get(corev1.LabelTopologyZone).Any(); zone != "" {
+				zones.Insert(zone)
+			}
+		}
+	}
+	return sets.List(zones)
+}
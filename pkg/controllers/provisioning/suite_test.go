@@ -19,16 +19,16 @@ package provisioning_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
-	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
-
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,12 +37,15 @@ import (
 	"k8s.io/client-go/tools/record"
 	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	karpv1alpha1 "sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
 	"sigs.k8s.io/karpenter/pkg/events"
@@ -55,15 +58,18 @@ import (
 )
 
 var (
-	ctx                 context.Context
-	fakeClock           *clock.FakeClock
-	cluster             *state.Cluster
-	nodeController      *informer.NodeController
-	daemonsetController *informer.DaemonSetController
-	cloudProvider       *fake.CloudProvider
-	prov                *provisioning.Provisioner
-	env                 *test.Environment
-	instanceTypeMap     map[string]*cloudprovider.InstanceType
+	ctx                  context.Context
+	fakeClock            *clock.FakeClock
+	cluster              *state.Cluster
+	nodeController       *informer.NodeController
+	daemonsetController  *informer.DaemonSetController
+	cloudProvider        *fake.CloudProvider
+	prov                 *provisioning.Provisioner
+	env                  *test.Environment
+	instanceTypeMap      map[string]*cloudprovider.InstanceType
+	unhealthyOfferings   *cloudprovider.UnhealthyOfferings
+	unavailableOfferings *cloudprovider.UnavailableOfferings
+	recorder             *test.EventRecorder
 )
 
 func TestAPIs(t *testing.T) {
@@ -79,7 +85,10 @@ var _ = BeforeSuite(func() {
 	fakeClock = clock.NewFakeClock(time.Now())
 	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
 	nodeController = informer.NewNodeController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock)
+	unhealthyOfferings = cloudprovider.NewUnhealthyOfferings()
+	unavailableOfferings = cloudprovider.NewUnavailableOfferings(env.Client, fakeClock)
+	recorder = test.NewEventRecorder()
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock, unhealthyOfferings, unavailableOfferings, scheduling.NewLabelAliases())
 	daemonsetController = informer.NewDaemonSetController(env.Client, cluster)
 	instanceTypes, _ := cloudProvider.GetInstanceTypes(ctx, nil)
 	instanceTypeMap = map[string]*cloudprovider.InstanceType{}
@@ -107,7 +116,11 @@ var _ = AfterEach(func() {
 	ExpectCleanedUp(ctx, env.Client)
 	cloudProvider.Reset()
 	cluster.Reset()
+	unhealthyOfferings.Flush()
+	unavailableOfferings.Flush()
+	recorder.Reset()
 	pscheduling.IgnoredPodCount.Set(0, nil)
+	pscheduling.QuotaBlockedPodCount.Set(0, nil)
 })
 
 var _ = Describe("Provisioning", func() {
@@ -115,13 +128,32 @@ var _ = Describe("Provisioning", func() {
 		It("should provision single pod if no other pod is received within the batch idle duration", func() {
 			pod := test.UnschedulablePod()
 			ExpectApplied(ctx, env.Client, test.NodePool(), pod)
-			prov.Trigger(pod.UID)
+			prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
 
 			wg := sync.WaitGroup{}
 			ExpectToWait(fakeClock, &wg)
 			result := ExpectSingletonReconciled(ctx, prov)
 			Expect(result.RequeueAfter).ToNot(BeNil())
 		})
+		It("should record the batch size and the reason that the batching window closed", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				BatchMaxDuration:  lo.ToPtr(time.Minute * 5),
+				BatchIdleDuration: lo.ToPtr(time.Second),
+			}))
+			provisioning.BatchesTotal.Reset()
+			provisioning.BatchSize.Reset()
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod)
+			prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
+
+			wg := sync.WaitGroup{}
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, prov)
+			wg.Wait()
+
+			ExpectMetricCounterValue(provisioning.BatchesTotal, 1, map[string]string{"reason": "idle_duration"})
+			ExpectMetricHistogramSampleCountValue("karpenter_provisioner_batch_size", 1, map[string]string{})
+		})
 		It("should not extend the timeout if we receive the same pod within the batch idle duration", func() {
 			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
 				BatchMaxDuration:  lo.ToPtr(10 * time.Second),
@@ -139,7 +171,7 @@ var _ = Describe("Provisioning", func() {
 
 				// Have a waiter on the first trigger and trigger the batcher
 				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
-				prov.Trigger(pod.UID)
+				prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
 
 				time.Sleep(time.Second) // give the process time to make it to the next batching section
 
@@ -150,7 +182,7 @@ var _ = Describe("Provisioning", func() {
 				fakeClock.Step(3 * time.Second)
 				// We expect to have waiters on the fakeClock since this is still within the batch idle duration of 5s.
 				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
-				prov.Trigger(pod.UID)
+				prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
 				// Step the clock again by 3s to just cross the batch idle duration. We should be able to get out of the
 				// provisioning loop because the same pod will not cause the idle duration to reset.
 				fakeClock.Step(3 * time.Second)
@@ -179,7 +211,7 @@ var _ = Describe("Provisioning", func() {
 
 				// Have a waiter on the first trigger and trigger the batcher
 				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
-				prov.Trigger(pod.UID)
+				prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
 
 				time.Sleep(time.Second) // give the process time to make it to the next batching section
 
@@ -190,7 +222,7 @@ var _ = Describe("Provisioning", func() {
 				fakeClock.Step(3 * time.Second)
 				// We expect to have waiters on the fakeClock since this is still within the batch idle duration of 5s.
 				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
-				prov.Trigger(pod2.UID)
+				prov.Trigger(pod2.UID, provisioning.TriggerSourcePod)
 				// Step the clock by 5s as we expect provisioning to not happen until another 5s because the
 				// batch idle duration was reset due to a new pod being added.
 				fakeClock.Step(5 * time.Second)
@@ -203,6 +235,217 @@ var _ = Describe("Provisioning", func() {
 			ExpectSingletonReconciled(ctx, prov)
 			wg.Wait()
 		})
+		It("should publish events for the pods of a batching window that was extended", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				BatchMaxDuration:  lo.ToPtr(10 * time.Second),
+				BatchIdleDuration: lo.ToPtr(5 * time.Second),
+			}))
+			pod := test.UnschedulablePod()
+			pod2 := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod, pod2)
+
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
+				prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
+
+				time.Sleep(time.Second) // give the process time to make it to the next batching section
+
+				// Extend the batching window with a new pod and let it close once it's idle
+				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
+				fakeClock.Step(3 * time.Second)
+				prov.Trigger(pod2.UID, provisioning.TriggerSourcePod)
+				time.Sleep(time.Second) // give the process time to reset the idle timer
+				fakeClock.Step(5 * time.Second)
+			}()
+			ExpectSingletonReconciled(ctx, prov)
+			wg.Wait()
+
+			Expect(recorder.Calls(events.BatchWindowExtended)).To(Equal(2))
+			Expect(recorder.Calls(events.BatchMaxDurationReached)).To(BeZero())
+		})
+		It("should publish events for the pods of a batching window that reached the max duration", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				BatchMaxDuration:  lo.ToPtr(time.Second),
+				BatchIdleDuration: lo.ToPtr(time.Minute),
+			}))
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod)
+			prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
+
+			wg := sync.WaitGroup{}
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, prov)
+			wg.Wait()
+
+			Expect(recorder.Calls(events.BatchMaxDurationReached)).To(Equal(1))
+			Expect(recorder.Calls(events.BatchWindowExtended)).To(BeZero())
+		})
+		It("should not publish batch events for a batching window that closed without being extended", func() {
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod)
+			prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
+
+			wg := sync.WaitGroup{}
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, prov)
+			wg.Wait()
+
+			Expect(recorder.Calls(events.BatchWindowExtended)).To(BeZero())
+			Expect(recorder.Calls(events.BatchMaxDurationReached)).To(BeZero())
+		})
+	})
+	Context("Batch Bypass", func() {
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				BatchMaxDuration:  lo.ToPtr(time.Minute * 5),
+				BatchIdleDuration: lo.ToPtr(time.Minute),
+			}))
+			provisioning.BatchesTotal.Reset()
+			provisioning.BatchTriggersTotal.Reset()
+		})
+		It("should skip the batching window for pods that bypass batching", func() {
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod)
+			prov.TriggerImmediately(pod.UID, provisioning.TriggerSourcePod)
+
+			ExpectSingletonReconciled(ctx, prov)
+			ExpectMetricCounterValue(provisioning.BatchesTotal, 1, map[string]string{"reason": "bypass"})
+			ExpectMetricCounterValue(provisioning.BatchTriggersTotal, 1, map[string]string{"source": provisioning.TriggerSourcePod, "path": "bypass"})
+		})
+		It("should close an open batching window when a pod bypasses batching", func() {
+			pod := test.UnschedulablePod()
+			critical := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod, critical)
+
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
+				prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
+
+				time.Sleep(time.Second) // give the process time to make it to the next batching section
+
+				// The batching window stays open until the pod that bypasses batching is triggered
+				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second).Should(BeTrue())
+				prov.TriggerImmediately(critical.UID, provisioning.TriggerSourcePod)
+			}()
+			ExpectSingletonReconciled(ctx, prov)
+			wg.Wait()
+
+			ExpectMetricCounterValue(provisioning.BatchesTotal, 1, map[string]string{"reason": "bypass"})
+			ExpectMetricCounterValue(provisioning.BatchTriggersTotal, 1, map[string]string{"source": provisioning.TriggerSourcePod, "path": "batched"})
+			ExpectMetricCounterValue(provisioning.BatchTriggersTotal, 1, map[string]string{"source": provisioning.TriggerSourcePod, "path": "bypass"})
+		})
+		It("should not start a batching window for pods that were part of a bypassed batch", func() {
+			pod := test.UnschedulablePod()
+			critical := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, test.NodePool(), pod, critical)
+			prov.Trigger(pod.UID, provisioning.TriggerSourcePod)
+			prov.TriggerImmediately(critical.UID, provisioning.TriggerSourcePod)
+			ExpectSingletonReconciled(ctx, prov)
+			ExpectMetricCounterValue(provisioning.BatchesTotal, 1, map[string]string{"reason": "bypass"})
+
+			// Nothing triggered the batcher since the last batch, so the next wait times out without a batching window
+			wg := sync.WaitGroup{}
+			ExpectToWait(fakeClock, &wg)
+			ExpectSingletonReconciled(ctx, prov)
+			wg.Wait()
+			for _, reason := range []string{"idle_duration", "max_duration"} {
+				_, ok := FindMetricWithLabelValues("karpenter_provisioner_batches_total", map[string]string{"reason": reason})
+				Expect(ok).To(BeFalse())
+			}
+			ExpectMetricCounterValue(provisioning.BatchesTotal, 1, map[string]string{"reason": "bypass"})
+		})
+		It("should only bypass batching the first time a pending pod is seen", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				BatchMaxDuration:      lo.ToPtr(time.Minute * 5),
+				BatchIdleDuration:     lo.ToPtr(time.Minute),
+				BatchBypassNamespaces: lo.ToPtr("default"),
+			}))
+			podController := provisioning.NewPodController(env.Client, prov, cluster)
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, podController, pod)
+			ExpectSingletonReconciled(ctx, prov)
+			ExpectMetricCounterValue(provisioning.BatchTriggersTotal, 1, map[string]string{"source": provisioning.TriggerSourcePod, "path": "bypass"})
+
+			// The pod is still pending when it's requeued, so it waits for the batching window like any other pod
+			ExpectObjectReconciled(ctx, env.Client, podController, pod)
+			ExpectMetricCounterValue(provisioning.BatchTriggersTotal, 1, map[string]string{"source": provisioning.TriggerSourcePod, "path": "bypass"})
+			ExpectMetricCounterValue(provisioning.BatchTriggersTotal, 1, map[string]string{"source": provisioning.TriggerSourcePod, "path": "batched"})
+		})
+		DescribeTable("should only bypass batching for pods in the bypass namespaces that match the bypass pod selector",
+			func(namespaces string, selector string, expected bool) {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+					BatchBypassNamespaces:  lo.ToPtr(namespaces),
+					BatchBypassPodSelector: lo.ToPtr(selector),
+				}))
+				pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Labels: map[string]string{"tier": "critical"}}})
+				Expect(provisioning.BypassesBatching(ctx, pod)).To(Equal(expected))
+			},
+			Entry("nothing configured", "", "", false),
+			Entry("matching namespace", "default, kube-system", "", true),
+			Entry("other namespace", "default", "", false),
+			Entry("matching selector", "", "tier=critical", true),
+			Entry("other selector", "", "tier in (batch)", false),
+			Entry("matching namespace and selector", "kube-system", "tier=critical", true),
+			Entry("matching namespace and other selector", "kube-system", "tier!=critical", false),
+		)
+	})
+	Context("Backoff", func() {
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				ProvisioningBackoffBase: lo.ToPtr(time.Second),
+				ProvisioningBackoffMax:  lo.ToPtr(8 * time.Second),
+			}))
+		})
+		It("should double the backoff with jitter for each consecutive failure up to the max", func() {
+			backoff := provisioning.NewBackoff()
+			for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+				wait := backoff.Failure(ctx)
+				Expect(wait).To(BeNumerically(">=", expected/2))
+				Expect(wait).To(BeNumerically("<=", expected))
+				ExpectMetricGaugeValue(provisioning.BackoffDurationSeconds, wait.Seconds(), map[string]string{})
+			}
+			ExpectMetricGaugeValue(provisioning.ConsecutiveFailures, 5, map[string]string{})
+		})
+		It("should reset the backoff after a success", func() {
+			backoff := provisioning.NewBackoff()
+			backoff.Failure(ctx)
+			backoff.Failure(ctx)
+			backoff.Success()
+			Expect(backoff.Failures()).To(Equal(0))
+			ExpectMetricGaugeValue(provisioning.ConsecutiveFailures, 0, map[string]string{})
+			ExpectMetricGaugeValue(provisioning.BackoffDurationSeconds, 0, map[string]string{})
+
+			wait := backoff.Failure(ctx)
+			Expect(wait).To(BeNumerically(">=", time.Second/2))
+			Expect(wait).To(BeNumerically("<=", time.Second))
+		})
+		It("should space out the retries of failed provisioning loops through the rate limiter", func() {
+			backoff := provisioning.NewBackoff()
+			rateLimiter := backoff.RateLimiter(ctx)
+			for _, expected := range []time.Duration{time.Second, 2 * time.Second} {
+				wait := rateLimiter.When(reconcile.Request{})
+				Expect(wait).To(BeNumerically(">=", expected/2))
+				Expect(wait).To(BeNumerically("<=", expected))
+			}
+			Expect(rateLimiter.NumRequeues(reconcile.Request{})).To(Equal(2))
+
+			// Requeues don't reset the backoff, only successful provisioning loops do
+			rateLimiter.Forget(reconcile.Request{})
+			Expect(backoff.Failures()).To(Equal(2))
+			backoff.Success()
+			Expect(rateLimiter.NumRequeues(reconcile.Request{})).To(Equal(0))
+		})
 	})
 	It("should provision nodes", func() {
 		ExpectApplied(ctx, env.Client, test.NodePool())
@@ -381,6 +624,61 @@ var _ = Describe("Provisioning", func() {
 
 		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, hash))
 	})
+	It("should label the NodeClaim with the generation of the NodePool that it was launched from", func() {
+		nodePool := test.NodePool()
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, nodePool, pod)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		generation := fmt.Sprint(nodePool.Generation)
+
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		node := ExpectScheduled(ctx, env.Client, pod)
+		Expect(node.Labels).To(HaveKeyWithValue(v1.NodePoolGenerationLabelKey, generation))
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Labels).To(HaveKeyWithValue(v1.NodePoolGenerationLabelKey, generation))
+		// the label is only stamped on the NodeClaim, it isn't a requirement
+		Expect(nodeClaims[0].Spec.Requirements).ToNot(ContainElement(HaveField("Key", v1.NodePoolGenerationLabelKey)))
+	})
+	Context("Nomination Conflicts", func() {
+		var node *corev1.Node
+		var podOptions test.PodOptions
+		BeforeEach(func() {
+			nodePool := test.NodePool()
+			its, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+			Expect(err).To(BeNil())
+			node = test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: its[0].Name,
+					},
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:  resource.MustParse("4"),
+					corev1.ResourcePods: resource.MustParse("10"),
+				},
+				ProviderID: test.RandomProviderID(),
+			})
+			podOptions = test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+			}}
+			ExpectApplied(ctx, env.Client, node, nodePool)
+			ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		})
+		It("should not schedule pods to capacity that an earlier batch nominated to other pods", func() {
+			Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, test.UnschedulablePod(podOptions))).To(BeEmpty())
+			pod := test.UnschedulablePod(podOptions)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+		})
+		It("should schedule pods to capacity that an earlier batch nominated to them", func() {
+			pod := test.UnschedulablePod(podOptions)
+			Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pod)).To(BeEmpty())
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(node.Name))
+		})
+	})
 	It("should schedule all pods on one inflight node when node is in deleting state", func() {
 		nodePool := test.NodePool()
 		its, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
@@ -658,6 +956,45 @@ var _ = Describe("Provisioning", func() {
 			Expect(scheduledPodCount).To(Equal(1))
 			Expect(unscheduledPodCount).To(Equal(1))
 		})
+		It("should give the capacity under the limits to higher priority pods first", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Limits: v1.Limits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")}),
+				},
+			}))
+			priorityClass := &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "critical"},
+				Value:      1000,
+			}
+			ExpectApplied(ctx, env.Client, priorityClass)
+
+			// prevent these pods from scheduling on the same node
+			antiAffinity := []corev1.PodAffinityTerm{
+				{
+					TopologyKey:   corev1.LabelHostname,
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+				},
+			}
+			// the batch pod requests more CPU, so it would be scheduled first if only bin-packing was considered
+			batchPod := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:          metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+				PodAntiRequirements: antiAffinity,
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")},
+				},
+			})
+			criticalPod := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:          metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+				PodAntiRequirements: antiAffinity,
+				PriorityClassName:   priorityClass.Name,
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.4")},
+				},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, batchPod, criticalPod)
+			ExpectScheduled(ctx, env.Client, criticalPod)
+			ExpectNotScheduled(ctx, env.Client, batchPod)
+		})
 		It("should not schedule if limits would be exceeded", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
 				Spec: v1.NodePoolSpec{
@@ -706,16 +1043,417 @@ var _ = Describe("Provisioning", func() {
 			// A 2 CPU node can be launched
 			ExpectScheduled(ctx, env.Client, pod)
 
-			// This pod requests over the existing limit (would add to 3.5 CPUs) so this should fail
-			pod = test.UnschedulablePod(
-				test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
-					Requests: corev1.ResourceList{
-						// requires a 2 CPU node, but leaves room for overhead
-						corev1.ResourceCPU: resource.MustParse("1.75"),
-					},
-				}})
-			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			// This pod requests over the existing limit (would add to 3.5 CPUs) so this should fail
+			pod = test.UnschedulablePod(
+				test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						// requires a 2 CPU node, but leaves room for overhead
+						corev1.ResourceCPU: resource.MustParse("1.75"),
+					},
+				}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		Context("Capacity Type Limits", func() {
+			It("should not launch capacity types whose scoped limits are exceeded", func() {
+				ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
+					Spec: v1.NodePoolSpec{
+						Limits: v1.Limits(corev1.ResourceList{"on-demand/cpu": resource.MustParse("20")}),
+					},
+					Status: v1.NodePoolStatus{
+						ResourcesByCapacityType: map[string]corev1.ResourceList{
+							v1.CapacityTypeOnDemand: {corev1.ResourceCPU: resource.MustParse("100")},
+						},
+					},
+				}))
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels).To(HaveKeyWithValue(v1.CapacityTypeLabelKey, v1.CapacityTypeSpot))
+
+				Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+				capacityTypes := scheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(v1.CapacityTypeLabelKey)
+				Expect(capacityTypes.Has(v1.CapacityTypeOnDemand)).To(BeFalse())
+			})
+			It("should not schedule when every allowed capacity type exceeds its scoped limits", func() {
+				ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
+					Spec: v1.NodePoolSpec{
+						Template: v1.NodeClaimTemplate{
+							Spec: v1.NodeClaimTemplateSpec{
+								Requirements: []v1.NodeSelectorRequirementWithMinValues{{
+									NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+										Key:      v1.CapacityTypeLabelKey,
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   []string{v1.CapacityTypeOnDemand},
+									},
+								}},
+							},
+						},
+						Limits: v1.Limits(corev1.ResourceList{"on-demand/cpu": resource.MustParse("20")}),
+					},
+					Status: v1.NodePoolStatus{
+						ResourcesByCapacityType: map[string]corev1.ResourceList{
+							v1.CapacityTypeOnDemand: {corev1.ResourceCPU: resource.MustParse("100")},
+						},
+					},
+				}))
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				ExpectNotScheduled(ctx, env.Client, pod)
+			})
+			It("should not schedule when the offerings would exceed the remaining scoped limits", func() {
+				ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
+					Spec: v1.NodePoolSpec{
+						Limits: v1.Limits(corev1.ResourceList{"on-demand/cpu": resource.MustParse("0")}),
+					},
+				}))
+				pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand}})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				ExpectNotScheduled(ctx, env.Client, pod)
+			})
+			It("should not count the resources of other capacity types against the scoped limits", func() {
+				ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
+					Spec: v1.NodePoolSpec{
+						Limits: v1.Limits(corev1.ResourceList{"spot/cpu": resource.MustParse("20")}),
+					},
+					Status: v1.NodePoolStatus{
+						ResourcesByCapacityType: map[string]corev1.ResourceList{
+							v1.CapacityTypeOnDemand: {corev1.ResourceCPU: resource.MustParse("100")},
+						},
+					},
+				}))
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				ExpectScheduled(ctx, env.Client, pod)
+			})
+		})
+	})
+	Context("Cluster Limits", func() {
+		BeforeEach(func() {
+			pscheduling.ClusterLimitsBlockedPodsTotal.Reset()
+		})
+		It("should not launch more nodes than the cluster node limit", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MaxNodes: lo.ToPtr(1)}))
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			// prevent these pods from scheduling on the same node
+			opts := test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "foo"},
+				},
+				PodAntiRequirements: []corev1.PodAffinityTerm{
+					{
+						TopologyKey: corev1.LabelHostname,
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "foo"},
+						},
+					},
+				},
+			}
+			pods := []*corev1.Pod{test.UnschedulablePod(opts), test.UnschedulablePod(opts)}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			ExpectMetricCounterValue(pscheduling.ClusterLimitsBlockedPodsTotal, 1, map[string]string{"limit": "nodes"})
+		})
+		It("should not schedule if the cluster cpu limit would be exceeded", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MaxCPU: lo.ToPtr[int64](1)}))
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(
+				test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						// requires a 2 CPU node
+						corev1.ResourceCPU: resource.MustParse("1.75"),
+					},
+				}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			ExpectMetricCounterValue(pscheduling.ClusterLimitsBlockedPodsTotal, 1, map[string]string{"limit": "cpu"})
+		})
+		It("should not schedule if the cluster hourly cost limit would be exceeded", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MaxHourlyCost: lo.ToPtr(0.0)}))
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			ExpectMetricCounterValue(pscheduling.ClusterLimitsBlockedPodsTotal, 1, map[string]string{"limit": "hourly_cost"})
+		})
+		It("should schedule if the cluster limits would be met", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MaxNodes: lo.ToPtr(1), MaxCPU: lo.ToPtr[int64](2), MaxHourlyCost: lo.ToPtr(100.0)}))
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(
+				test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1.75"),
+					},
+				}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+	})
+	Context("Provisioning Parallelism", func() {
+		var nodePoolA, nodePoolB *v1.NodePool
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ProvisioningParallelism: lo.ToPtr(2)}))
+			nodePoolA = test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Template: v1.NodeClaimTemplate{
+						ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"team": "a"}},
+					},
+				},
+			})
+			nodePoolB = test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Template: v1.NodeClaimTemplate{
+						ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"team": "b"}},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePoolA, nodePoolB)
+		})
+		It("should schedule pods against independent nodepools", func() {
+			podA := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"team": "a"}})
+			podB := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"team": "b"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, podA, podB)
+			Expect(ExpectScheduled(ctx, env.Client, podA).Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolA.Name))
+			Expect(ExpectScheduled(ctx, env.Client, podB).Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolB.Name))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(2))
+		})
+		It("should only launch a single node for pods that are compatible with multiple nodepools", func() {
+			pods := []*corev1.Pod{test.UnschedulablePod(), test.UnschedulablePod()}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(ExpectScheduled(ctx, env.Client, pods[0]).Name).To(Equal(ExpectScheduled(ctx, env.Client, pods[1]).Name))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		})
+		It("should not launch capacity for pods that no nodepool matches", func() {
+			podA := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"team": "a"}})
+			podC := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"team": "c"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, podA, podC)
+			Expect(ExpectScheduled(ctx, env.Client, podA).Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolA.Name))
+			ExpectNotScheduled(ctx, env.Client, podC)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		})
+		It("should relax preferences that no nodepool matches", func() {
+			pod := test.UnschedulablePod(test.PodOptions{NodePreferences: []corev1.NodeSelectorRequirement{
+				{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"c"}},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+		It("should schedule pods whose first required node affinity term no nodepool matches", func() {
+			pod := test.UnschedulablePod(test.PodOptions{NodeRequirements: []corev1.NodeSelectorRequirement{
+				{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"c"}},
+			}})
+			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = append(
+				pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+				corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolB.Name))
+		})
+		It("should schedule pods to existing nodes that aren't owned by the nodepools of their partition", func() {
+			node := test.Node(test.NodeOptions{
+				ObjectMeta:  metav1.ObjectMeta{Labels: map[string]string{"team": "a"}},
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10"), corev1.ResourcePods: resource.MustParse("10")},
+				ProviderID:  test.RandomProviderID(),
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+			podA := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"team": "a"}})
+			podB := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{"team": "b"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, podA, podB)
+			Expect(ExpectScheduled(ctx, env.Client, podA).Name).To(Equal(node.Name))
+			Expect(ExpectScheduled(ctx, env.Client, podB).Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolB.Name))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		})
+		It("should respect pod anti-affinity across nodepools", func() {
+			antiAffinity := []corev1.PodAffinityTerm{{
+				TopologyKey:   corev1.LabelTopologyZone,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			}}
+			podA := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:          metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				NodeSelector:        map[string]string{"team": "a"},
+				PodAntiRequirements: antiAffinity,
+			})
+			podB := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:          metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				NodeSelector:        map[string]string{"team": "b"},
+				PodAntiRequirements: antiAffinity,
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, podA, podB)
+			nodeA := ExpectScheduled(ctx, env.Client, podA)
+			nodeB := ExpectScheduled(ctx, env.Client, podB)
+			Expect(nodeA.Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolA.Name))
+			Expect(nodeB.Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePoolB.Name))
+			Expect(nodeA.Labels[corev1.LabelTopologyZone]).ToNot(Equal(nodeB.Labels[corev1.LabelTopologyZone]))
+		})
+		It("should respect zonal topology spread across nodepools", func() {
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				MaxSkew:           1,
+			}}
+			pods := []*corev1.Pod{test.UnschedulablePod(test.PodOptions{
+				ObjectMeta:                metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				NodeSelector:              map[string]string{"team": "a"},
+				TopologySpreadConstraints: topology,
+			})}
+			pods = append(pods, test.UnschedulablePods(test.PodOptions{
+				ObjectMeta:                metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				NodeSelector:              map[string]string{"team": "b"},
+				TopologySpreadConstraints: topology,
+			}, 2)...)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(1, 1, 1))
+		})
+	})
+	Context("Deterministic NodeClaim Names", func() {
+		// spreadPods returns pods with hostname anti-affinity so that each of them is scheduled to a different NodeClaim
+		spreadPods := func(count int) []*corev1.Pod {
+			return test.UnschedulablePods(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				PodAntiRequirements: []corev1.PodAffinityTerm{{
+					TopologyKey:   corev1.LabelHostname,
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			}, count)
+		}
+		It("should annotate NodeClaims with the scheduling run that computed them", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pods := spreadPods(2)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(2))
+			Expect(nodeClaims[0].Annotations).To(HaveKey(v1.SchedulingRunAnnotationKey))
+			Expect(nodeClaims[1].Annotations).To(HaveKeyWithValue(v1.SchedulingRunAnnotationKey, nodeClaims[0].Annotations[v1.SchedulingRunAnnotationKey]))
+		})
+		It("should name NodeClaims after their NodePool, scheduling run and index when enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DeterministicNodeClaimNames: lo.ToPtr(true)}))
+			nodePool := test.NodePool()
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := spreadPods(2)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(2))
+			runID := strings.Split(nodeClaims[0].Annotations[v1.SchedulingRunAnnotationKey], "-")[0]
+			Expect(lo.Map(nodeClaims, func(nc *v1.NodeClaim, _ int) string { return nc.Name })).To(ConsistOf(
+				fmt.Sprintf("%s-%s-0", nodePool.Name, runID),
+				fmt.Sprintf("%s-%s-1", nodePool.Name, runID),
+			))
+		})
+	})
+	Context("NodeClaim Naming Policy", func() {
+		It("should name, label and tag NodeClaims from the configured templates", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				NodeClaimNameTemplate:   lo.ToPtr("{{.Environment}}-{{.NodePool}}-{{.Batch}}-{{.Index}}"),
+				NodeClaimLabelTemplates: lo.ToPtr("example.com/shard={{.Shard}}"),
+				NodeClaimTagTemplates:   lo.ToPtr("environment={{.Environment}},nodepool={{.NodePool}}"),
+				ShardName:               lo.ToPtr("shard-a"),
+				EnvironmentName:         lo.ToPtr("prod"),
+			}))
+			nodePool := test.NodePool()
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			runID := strings.Split(nodeClaims[0].Annotations[v1.SchedulingRunAnnotationKey], "-")[0]
+			Expect(nodeClaims[0].Name).To(Equal(fmt.Sprintf("prod-%s-%s-0", nodePool.Name, runID)))
+			Expect(nodeClaims[0].Labels).To(HaveKeyWithValue("example.com/shard", "shard-a"))
+			Expect(nodeClaims[0].Annotations).To(HaveKeyWithValue(v1.TagAnnotationKeyPrefix+"environment", "prod"))
+			Expect(nodeClaims[0].Annotations).To(HaveKeyWithValue(v1.TagAnnotationKeyPrefix+"nodepool", nodePool.Name))
+			Expect(node.Labels).To(HaveKeyWithValue("example.com/shard", "shard-a"))
+		})
+		It("should generate a name from a rendered name that ends with a dash", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				NodeClaimNameTemplate: lo.ToPtr("{{.NodePool}}-{{.Environment}}-"),
+				EnvironmentName:       lo.ToPtr("prod"),
+			}))
+			nodePool := test.NodePool()
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(nodeClaims[0].Name).To(HavePrefix(fmt.Sprintf("%s-prod-", nodePool.Name)))
+			Expect(len(nodeClaims[0].Name)).To(BeNumerically(">", len(fmt.Sprintf("%s-prod-", nodePool.Name))))
+		})
+		It("should not launch NodeClaims when a rendered label is invalid", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				NodeClaimLabelTemplates: lo.ToPtr("example.com/shard={{.Shard}}!"),
+			}))
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		})
+	})
+	Context("NodeClaim Hooks", func() {
+		var hookedProv *provisioning.Provisioner
+		BeforeEach(func() {
+			hookedProv = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, fakeClock, unhealthyOfferings, unavailableOfferings, scheduling.NewLabelAliases())
+			provisioning.NodeClaimHookDurationSeconds.Reset()
+			provisioning.NodeClaimHookInvocationsTotal.Reset()
+		})
+		AfterEach(func() {
+			provisioning.NodeClaimHookTimeout = 10 * time.Second
+		})
+		It("should launch NodeClaims that were mutated by the hooks in order", func() {
+			hookedProv.RegisterNodeClaimHooks(
+				&testHook{name: "first", mutate: func(_ context.Context, nodeClaim *v1.NodeClaim) error {
+					nodeClaim.Labels = lo.Assign(nodeClaim.Labels, map[string]string{"injected": "first"})
+					return nil
+				}},
+				&testHook{name: "second", mutate: func(_ context.Context, nodeClaim *v1.NodeClaim) error {
+					nodeClaim.Labels["injected"] += "-second"
+					return nil
+				}},
+			)
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, hookedProv, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(nodeClaims[0].Labels).To(HaveKeyWithValue("injected", "first-second"))
+			ExpectMetricCounterValue(provisioning.NodeClaimHookInvocationsTotal, 1, map[string]string{"hook": "first", "result": "success"})
+			ExpectMetricCounterValue(provisioning.NodeClaimHookInvocationsTotal, 1, map[string]string{"hook": "second", "result": "success"})
+			ExpectMetricHistogramSampleCountValue("karpenter_provisioner_nodeclaim_hook_duration_seconds", 1, map[string]string{"hook": "first"})
+		})
+		It("should not launch NodeClaims that were vetoed by a hook", func() {
+			hookedProv.RegisterNodeClaimHooks(&testHook{name: "veto", mutate: func(context.Context, *v1.NodeClaim) error {
+				return fmt.Errorf("denied")
+			}})
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, hookedProv, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+			ExpectMetricCounterValue(provisioning.NodeClaimHookInvocationsTotal, 1, map[string]string{"hook": "veto", "result": "vetoed"})
+		})
+		It("should not launch NodeClaims when a hook times out", func() {
+			provisioning.NodeClaimHookTimeout = 100 * time.Millisecond
+			hookedProv.RegisterNodeClaimHooks(&testHook{name: "slow", mutate: func(_ context.Context, nodeClaim *v1.NodeClaim) error {
+				time.Sleep(time.Second)
+				nodeClaim.Labels = lo.Assign(nodeClaim.Labels, map[string]string{"injected": "slow"})
+				return nil
+			}})
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, hookedProv, pod)
 			ExpectNotScheduled(ctx, env.Client, pod)
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+			ExpectMetricCounterValue(provisioning.NodeClaimHookInvocationsTotal, 1, map[string]string{"hook": "slow", "result": "timeout"})
 		})
 	})
 	Context("Daemonsets", func() {
@@ -770,6 +1508,59 @@ var _ = Describe("Provisioning", func() {
 			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
 			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
 		})
+		It("should account for the pods in the daemon overhead manifests", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DaemonOverheadPods: []*corev1.Pod{
+				test.Pod(test.PodOptions{
+					ObjectMeta:           metav1.ObjectMeta{Name: "injected-agent"},
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")}},
+				}),
+			}}))
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(
+				test.PodOptions{
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}},
+				},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+
+			// The injected pod isn't backed by a DaemonSet, so launching with 4Gi means that its manifest was respected
+			allocatable := instanceTypeMap[node.Labels[corev1.LabelInstanceTypeStable]].Capacity
+			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
+			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
+		})
+		It("should account for daemonsets that tolerate the nodepool's taints through the cluster's default tolerations", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DaemonDefaultTolerations: []corev1.Toleration{
+				{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists},
+			}}))
+			nodePool := test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Template: v1.NodeClaimTemplate{
+						Spec: v1.NodeClaimTemplateSpec{
+							Taints: []corev1.Taint{{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoSchedule}},
+						},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, test.DaemonSet(
+				test.DaemonSetOptions{PodOptions: test.PodOptions{
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")}},
+				}},
+			))
+			pod := test.UnschedulablePod(
+				test.PodOptions{
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}},
+					Tolerations:          []corev1.Toleration{{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}},
+				},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+
+			// The daemonset doesn't tolerate the taint itself, so launching with 4Gi means that the default toleration was respected
+			allocatable := instanceTypeMap[node.Labels[corev1.LabelInstanceTypeStable]].Capacity
+			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
+			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
+		})
 		It("should not schedule if daemonset overhead is too large", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(), test.DaemonSet(
 				test.DaemonSetOptions{PodOptions: test.PodOptions{
@@ -1605,6 +2396,358 @@ var _ = Describe("Provisioning", func() {
 			ExpectScheduled(ctx, env.Client, pod)
 		})
 	})
+	Context("Unhealthy Offerings", func() {
+		BeforeEach(func() {
+			for _, of := range instanceTypeMap["default-instance-type"].Offerings {
+				unhealthyOfferings.MarkUnhealthy("default-instance-type", of.Requirements.Get(corev1.LabelTopologyZone).Any(), of.Requirements.Get(v1.CapacityTypeLabelKey).Any())
+			}
+		})
+		It("should not schedule pods that can only use unhealthy offerings", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector: map[string]string{corev1.LabelInstanceTypeStable: "default-instance-type"},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should launch with instance types that have healthy offerings", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			instanceTypes := scheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(corev1.LabelInstanceTypeStable)
+			Expect(instanceTypes.Has("default-instance-type")).To(BeFalse())
+			Expect(instanceTypes.Len()).To(BeNumerically(">", 0))
+		})
+	})
+	Context("Unavailable Offerings", func() {
+		markUnavailable := func(u *cloudprovider.UnavailableOfferings) {
+			for _, of := range instanceTypeMap["default-instance-type"].Offerings {
+				u.MarkUnavailable(cloudprovider.OfferingID{
+					InstanceType: "default-instance-type",
+					Zone:         of.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					CapacityType: of.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+				})
+			}
+			Expect(u.Sync(ctx)).To(Succeed())
+		}
+		AfterEach(func() {
+			ExpectDeleted(ctx, env.Client, &karpv1alpha1.UnavailableOfferings{ObjectMeta: metav1.ObjectMeta{Name: karpv1alpha1.UnavailableOfferingsName}})
+		})
+		It("should not schedule pods that can only use unavailable offerings", func() {
+			markUnavailable(unavailableOfferings)
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector: map[string]string{corev1.LabelInstanceTypeStable: "default-instance-type"},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should not schedule pods to offerings that another replica marked as unavailable", func() {
+			markUnavailable(cloudprovider.NewUnavailableOfferings(env.Client, fakeClock))
+			Expect(unavailableOfferings.Sync(ctx)).To(Succeed())
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector: map[string]string{corev1.LabelInstanceTypeStable: "default-instance-type"},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should schedule pods to offerings that are no longer unavailable", func() {
+			markUnavailable(unavailableOfferings)
+			fakeClock.Step(cloudprovider.UnavailableOfferingsTTL)
+			Expect(unavailableOfferings.Sync(ctx)).To(Succeed())
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector: map[string]string{corev1.LabelInstanceTypeStable: "default-instance-type"},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			persisted := &karpv1alpha1.UnavailableOfferings{}
+			Expect(env.Client.Get(ctx, client.ObjectKey{Name: karpv1alpha1.UnavailableOfferingsName}, persisted)).To(Succeed())
+			Expect(persisted.Status.Offerings).To(BeEmpty())
+		})
+	})
+	Context("Additional Resources", func() {
+		licenses := corev1.ResourceName("example.com/licenses")
+		var pod *corev1.Pod
+		BeforeEach(func() {
+			pod = test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{licenses: resource.MustParse("1")}},
+			})
+		})
+		It("should not schedule pods requesting resources that aren't advertised", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should schedule pods requesting resources that are advertised by the nodepool", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: string(licenses), Quantity: resource.MustParse("2")}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+		It("should only launch the instance types that advertise the resource", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{
+				Name:     string(licenses),
+				Quantity: resource.MustParse("2"),
+				Requirements: []corev1.NodeSelectorRequirement{{
+					Key:      corev1.LabelInstanceTypeStable,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"default-instance-type"},
+				}},
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("default-instance-type"))
+
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			instanceTypes := scheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(corev1.LabelInstanceTypeStable)
+			Expect(instanceTypes.Values()).To(ConsistOf("default-instance-type"))
+		})
+		It("should not schedule more pods than the advertised quantity onto a node", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: string(licenses), Quantity: resource.MustParse("1")}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := []*corev1.Pod{pod, test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{licenses: resource.MustParse("1")}},
+			})}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodes := sets.New[string]()
+			for _, p := range pods {
+				nodes.Insert(ExpectScheduled(ctx, env.Client, p).Name)
+			}
+			Expect(nodes.Len()).To(Equal(2))
+		})
+		It("should schedule pods requesting huge pages onto instance types with enough memory to back them", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: "hugepages-1Gi", Quantity: resource.MustParse("64Gi")}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			hugePagesPod := test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+					"hugepages-1Gi":       resource.MustParse("2Gi"),
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, hugePagesPod)
+			node := ExpectScheduled(ctx, env.Client, hugePagesPod)
+			Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("arm-instance-type"))
+		})
+		It("should subtract huge pages from the memory that's available to pods", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.AdditionalResources = []v1.AdditionalResource{{Name: "hugepages-1Gi", Quantity: resource.MustParse("64Gi")}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			memoryPod := test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("100Gi"),
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, memoryPod)
+			ExpectNotScheduled(ctx, env.Client, memoryPod)
+		})
+	})
+	Context("Conditional Startup Taints", func() {
+		var nodePool *v1.NodePool
+		BeforeEach(func() {
+			nodePool = test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Template: v1.NodeClaimTemplate{
+						Spec: v1.NodeClaimTemplateSpec{
+							StartupTaints: []corev1.Taint{{Key: "foo.com/taint", Effect: corev1.TaintEffectNoSchedule}},
+							ConditionalStartupTaints: []v1.ConditionalStartupTaints{
+								{
+									Requirements: []corev1.NodeSelectorRequirement{{
+										Key:      corev1.LabelInstanceTypeStable,
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   []string{"gpu-vendor-instance-type", "gpu-vendor-b-instance-type"},
+									}},
+									Taints: []corev1.Taint{{Key: "example.com/device-plugin", Effect: corev1.TaintEffectNoSchedule}},
+								},
+							},
+						},
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+		})
+		It("should add conditional startup taints when every instance type matches their requirements", func() {
+			pod := test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Limits: corev1.ResourceList{fake.ResourceGPUVendorA: resource.MustParse("1")}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(nodeClaims[0].Spec.StartupTaints).To(ConsistOf(
+				corev1.Taint{Key: "foo.com/taint", Effect: corev1.TaintEffectNoSchedule},
+				corev1.Taint{Key: "example.com/device-plugin", Effect: corev1.TaintEffectNoSchedule},
+			))
+		})
+		It("should not add conditional startup taints when only some instance types match their requirements", func() {
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(nodeClaims[0].Spec.StartupTaints).To(ConsistOf(corev1.Taint{Key: "foo.com/taint", Effect: corev1.TaintEffectNoSchedule}))
+		})
+		It("should schedule pods that don't tolerate conditional startup taints", func() {
+			pods := []*corev1.Pod{
+				test.UnschedulablePod(test.PodOptions{
+					ResourceRequirements: corev1.ResourceRequirements{Limits: corev1.ResourceList{fake.ResourceGPUVendorA: resource.MustParse("1")}},
+				}),
+				test.UnschedulablePod(test.PodOptions{
+					ResourceRequirements: corev1.ResourceRequirements{Limits: corev1.ResourceList{fake.ResourceGPUVendorB: resource.MustParse("1")}},
+				}),
+			}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectScheduled(ctx, env.Client, pod)
+			}
+		})
+	})
+	Context("Max Pods Per Node", func() {
+		It("should not schedule more pods than the nodepool's max pods onto a node", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](2)
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{}, 5)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodes := sets.New[string]()
+			for _, p := range pods {
+				nodes.Insert(ExpectScheduled(ctx, env.Client, p).Name)
+			}
+			Expect(nodes.Len()).To(Equal(3))
+		})
+		It("should not raise the pods of instance types that support fewer pods", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](100)
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := test.UnschedulablePods(test.PodOptions{NodeSelector: map[string]string{corev1.LabelInstanceTypeStable: "default-instance-type"}}, 10)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodes := sets.New[string]()
+			for _, p := range pods {
+				nodes.Insert(ExpectScheduled(ctx, env.Client, p).Name)
+			}
+			// The default instance type supports 5 pods
+			Expect(nodes.Len()).To(Equal(2))
+		})
+		It("should not schedule more pods than the nodepool's max pods onto an existing node", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.MaxPodsPerNode = lo.ToPtr[int32](1)
+			node := test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, node)
+			ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+			pods := test.UnschedulablePods(test.PodOptions{}, 2)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			scheduled := lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return ExpectScheduled(ctx, env.Client, p).Name == node.Name })
+			Expect(scheduled).To(HaveLen(1))
+		})
+	})
+	Context("Quota", func() {
+		DescribeTable("should not launch capacity for pods that are blocked by a quota",
+			func(message string) {
+				ExpectApplied(ctx, env.Client, test.NodePool())
+				pod := test.UnschedulablePod(test.PodOptions{
+					Conditions: []corev1.PodCondition{{
+						Type:    corev1.PodScheduled,
+						Reason:  corev1.PodReasonUnschedulable,
+						Status:  corev1.ConditionFalse,
+						Message: message,
+					}},
+				})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				ExpectNotScheduled(ctx, env.Client, pod)
+				ExpectMetricGaugeValue(pscheduling.QuotaBlockedPodCount, 1, nil)
+				Expect(cloudProvider.CreateCalls).To(BeEmpty())
+			},
+			Entry("ElasticQuota max", "Pod default/test is rejected in Prefilter because ElasticQuota default is more than Max"),
+			Entry("ElasticQuota min", "Pod default/test is rejected in Prefilter because total ElasticQuota used is more than min"),
+			Entry("ResourceQuota", "exceeded quota: compute-resources, requested: cpu=2, used: cpu=8, limited: cpu=8"),
+		)
+		It("should launch capacity for unschedulable pods whose message mentions a quota for another reason", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Reason:  corev1.PodReasonUnschedulable,
+					Status:  corev1.ConditionFalse,
+					Message: "0/3 nodes are available: 3 node(s) didn't match Pod's node affinity/selector for label example.com/quota-pool",
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			ExpectMetricGaugeValue(pscheduling.QuotaBlockedPodCount, 0, nil)
+		})
+		It("should launch capacity for unschedulable pods that aren't blocked by a quota", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Reason:  corev1.PodReasonUnschedulable,
+					Status:  corev1.ConditionFalse,
+					Message: "0/3 nodes are available: 3 Insufficient cpu",
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			ExpectMetricGaugeValue(pscheduling.QuotaBlockedPodCount, 0, nil)
+		})
+	})
+	Context("Non-Provisioning Priority Classes", func() {
+		var priorityClass *schedulingv1.PriorityClass
+		BeforeEach(func() {
+			priorityClass = &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "best-effort"},
+				Value:      -10,
+			}
+			ExpectApplied(ctx, env.Client, priorityClass)
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NonProvisioningPriorityClasses: lo.ToPtr("preemptible, best-effort")}))
+		})
+		AfterEach(func() {
+			ExpectDeleted(ctx, env.Client, priorityClass)
+		})
+		It("should not launch capacity for pods with a non-provisioning priority class", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			pod := test.UnschedulablePod(test.PodOptions{PriorityClassName: priorityClass.Name})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+			ExpectMetricGaugeValue(pscheduling.IgnoredPodCount, 1, nil)
+			Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		})
+		It("should launch capacity for pods with other priority classes", func() {
+			critical := &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "critical"},
+				Value:      1000,
+			}
+			ExpectApplied(ctx, env.Client, test.NodePool(), critical)
+			pod := test.UnschedulablePod(test.PodOptions{PriorityClassName: critical.Name})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			ExpectDeleted(ctx, env.Client, critical)
+		})
+		It("should not trigger provisioning for pods with a non-provisioning priority class", func() {
+			pod := test.UnschedulablePod(test.PodOptions{PriorityClassName: priorityClass.Name})
+			Expect(provisioning.TriggersProvisioning(ctx, pod)).To(BeFalse())
+			Expect(provisioning.TriggersProvisioning(ctx, test.UnschedulablePod())).To(BeTrue())
+		})
+	})
 	Context("Volume Topology Requirements", func() {
 		var storageClass *storagev1.StorageClass
 		BeforeEach(func() {
@@ -1818,6 +2961,32 @@ var _ = Describe("Provisioning", func() {
 			node := ExpectScheduled(ctx, env.Client, pod)
 			Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-3"))
 		})
+		It("should schedule to volume zones if volume already bound and only has zone labels", func() {
+			persistentVolume := test.PersistentVolume(test.PersistentVolumeOptions{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{corev1.LabelTopologyZone: "test-zone-3"},
+			}})
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{VolumeName: persistentVolume.Name, StorageClassName: &storageClass.Name})
+			ExpectApplied(ctx, env.Client, test.NodePool(), storageClass, persistentVolumeClaim, persistentVolume)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{persistentVolumeClaim.Name},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-3"))
+		})
+		It("should schedule to volume zones if volume already bound and only has legacy multi-zone labels", func() {
+			persistentVolume := test.PersistentVolume(test.PersistentVolumeOptions{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{corev1.LabelFailureDomainBetaZone: "test-zone-1__test-zone-3"},
+			}})
+			persistentVolumeClaim := test.PersistentVolumeClaim(test.PersistentVolumeClaimOptions{VolumeName: persistentVolume.Name, StorageClassName: &storageClass.Name})
+			ExpectApplied(ctx, env.Client, test.NodePool(), storageClass, persistentVolumeClaim, persistentVolume)
+			pod := test.UnschedulablePod(test.PodOptions{
+				PersistentVolumeClaims: []string{persistentVolumeClaim.Name},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[corev1.LabelTopologyZone]).To(Or(Equal("test-zone-1"), Equal("test-zone-3")))
+		})
 		DescribeTable("should ignore hostname affinity scheduling when using local path volumes",
 			func(volumeOptions test.PersistentVolumeOptions) {
 				// StorageClass that references "no-provisioner" and is used for local volume storage
@@ -2251,3 +3420,16 @@ func AddInstanceResources(instanceTypes []*cloudprovider.InstanceType, resources
 
 	return instanceTypes
 }
+
+type testHook struct {
+	name   string
+	mutate func(context.Context, *v1.NodeClaim) error
+}
+
+func (h *testHook) Name() string {
+	return h.name
+}
+
+func (h *testHook) Mutate(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	return h.mutate(ctx, nodeClaim)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/samber/lo"
@@ -29,6 +30,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -39,9 +41,12 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 // Cluster maintains cluster state that is often needed but expensive to compute.
@@ -59,6 +64,11 @@ type Cluster struct {
 	podAcks                 sync.Map // pod namespaced name -> time when Karpenter first saw the pod as pending
 	podsSchedulingAttempted sync.Map // pod namespaced name -> time when Karpenter tried to schedule a pod
 	podsSchedulableTimes    sync.Map // pod namespaced name -> time when it was first marked as able to fit to a node
+	podNominations          sync.Map // pod namespaced name -> name of the NodeClaim that the pod was nominated to
+
+	nodeNominationsMu  sync.Mutex
+	nodeNominations    map[string]map[types.NamespacedName]nodeNomination // provider id -> pod namespaced name -> nomination of the pod to the existing node
+	podNodeNominations map[types.NamespacedName]string                    // pod namespaced name -> provider id of the existing node that the pod was nominated to
 
 	clusterStateMu sync.RWMutex // Separate mutex as this is called in some places that mu is held
 	// A monotonically increasing timestamp representing the time state of the
@@ -68,7 +78,8 @@ type Cluster struct {
 	// optimize and not try to disrupt if nothing about the cluster has changed.
 	clusterState      time.Time
 	unsyncedStartTime time.Time
-	antiAffinityPods  sync.Map // pod namespaced name -> *corev1.Pod of pods that have required anti affinities
+	antiAffinityPods  sync.Map    // pod namespaced name -> *corev1.Pod of pods that have required anti affinities
+	changeFrozen      atomic.Bool // true while voluntary disruption is paused cluster-wide by the change freeze switch
 }
 
 func NewCluster(clk clock.Clock, client client.Client, cloudProvider cloudprovider.CloudProvider) *Cluster {
@@ -84,69 +95,166 @@ func NewCluster(clk clock.Clock, client client.Client, cloudProvider cloudprovid
 		podAcks:                   sync.Map{},
 		podsSchedulableTimes:      sync.Map{},
 		podsSchedulingAttempted:   sync.Map{},
+		podNominations:            sync.Map{},
+		nodeNominations:           map[string]map[types.NamespacedName]nodeNomination{},
+		podNodeNominations:        map[types.NamespacedName]string{},
 	}
 }
 
-// Synced validates that the NodeClaims and the Nodes that are stored in the apiserver
-// have the same representation in the cluster state. This is to ensure that our view
-// of the cluster is as close to correct as it can be when we begin to perform operations
-// utilizing the cluster state as our source of truth
-//
-//nolint:gocyclo
-func (c *Cluster) Synced(ctx context.Context) (synced bool) {
-	// Set the metric depending on the result of the Synced() call
-	defer func() {
-		if synced {
-			c.unsyncedStartTime = time.Time{}
-			ClusterStateUnsyncedTimeSeconds.Set(0, nil)
-		} else {
-			if c.unsyncedStartTime.IsZero() {
-				c.unsyncedStartTime = c.clock.Now()
-			}
-			ClusterStateUnsyncedTimeSeconds.Set(c.clock.Since(c.unsyncedStartTime).Seconds(), nil)
-		}
-	}()
-	// Set the metric to whatever the result of the Synced() call is
-	defer func() {
-		ClusterStateSynced.Set(lo.Ternary[float64](synced, 1, 0), nil)
-	}()
+// nodeNomination is the capacity on an existing node that a scheduling loop expects a pending pod to bind to
+type nodeNomination struct {
+	requests corev1.ResourceList
+	until    time.Time
+}
+
+// SyncStatus describes the NodeClaims and Nodes in the apiserver that aren't yet represented in the cluster state
+type SyncStatus struct {
+	// NotLaunchedNodeClaims are tracked NodeClaims that haven't resolved a provider id yet
+	NotLaunchedNodeClaims []string
+	// UntrackedNodeClaims are NodeClaims in the apiserver that aren't tracked by the cluster state
+	UntrackedNodeClaims []string
+	// UntrackedNodes are Nodes in the apiserver that aren't tracked by the cluster state
+	UntrackedNodes []string
+
+	total  int
+	oldest time.Time
+}
+
+// Synced returns true if every NodeClaim and Node in the apiserver is represented in the cluster state
+func (s SyncStatus) Synced() bool {
+	return len(s.NotLaunchedNodeClaims) == 0 && len(s.UntrackedNodeClaims) == 0 && len(s.UntrackedNodes) == 0
+}
+
+// SyncedPercent returns the percentage of NodeClaims and Nodes in the apiserver that are represented in the cluster state
+func (s SyncStatus) SyncedPercent() float64 {
+	if s.total == 0 {
+		return 100
+	}
+	unsynced := len(s.NotLaunchedNodeClaims) + len(s.UntrackedNodeClaims) + len(s.UntrackedNodes)
+	return float64(max(s.total-unsynced, 0)) / float64(s.total) * 100
+}
+
+// OldestUnsynced returns the creation time of the oldest NodeClaim or Node that isn't represented in the cluster state
+func (s SyncStatus) OldestUnsynced() time.Time {
+	return s.oldest
+}
+
+// LogValues returns the structured reasons that the cluster state isn't synced
+func (s SyncStatus) LogValues() []any {
+	return []any{
+		"not-launched-nodeclaims", len(s.NotLaunchedNodeClaims),
+		"untracked-nodeclaims", len(s.UntrackedNodeClaims),
+		"untracked-nodes", len(s.UntrackedNodes),
+	}
+}
+
+// SyncStatus compares the NodeClaims and the Nodes that are stored in the apiserver to their representation in the
+// cluster state
+func (c *Cluster) SyncStatus(ctx context.Context) (SyncStatus, error) {
 	nodeClaims, err := nodeclaimutils.ListManaged(ctx, c.kubeClient, c.cloudProvider)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "failed checking cluster state sync")
-		return false
+		return SyncStatus{}, err
 	}
 	nodeList := &corev1.NodeList{}
 	if err := c.kubeClient.List(ctx, nodeList); err != nil {
-		log.FromContext(ctx).Error(err, "failed checking cluster state sync")
-		return false
+		return SyncStatus{}, err
 	}
 	c.mu.RLock()
-	stateNodeClaimNames := sets.New[string]()
-	for name, providerID := range c.nodeClaimNameToProviderID {
-		// Check to see if any node claim doesn't have a provider ID. If it doesn't, then the nodeclaim hasn't been
-		// launched, and we need to wait to see what the resolved values are before continuing.
-		if providerID == "" {
-			c.mu.RUnlock()
-			return false
-		}
-		stateNodeClaimNames.Insert(name)
-	}
+	stateNodeClaims := lo.Assign(c.nodeClaimNameToProviderID)
 	stateNodeNames := sets.New(lo.Keys(c.nodeNameToProviderID)...)
 	c.mu.RUnlock()
 
-	nodeClaimNames := sets.New[string]()
-	for _, nodeClaim := range nodeClaims {
-		nodeClaimNames.Insert(nodeClaim.Name)
-	}
-	nodeNames := sets.New[string]()
-	for _, node := range nodeList.Items {
-		nodeNames.Insert(node.Name)
+	status := SyncStatus{total: len(nodeClaims) + len(nodeList.Items)}
+	observe := func(creationTimestamp metav1.Time) {
+		if status.oldest.IsZero() || creationTimestamp.Time.Before(status.oldest) {
+			status.oldest = creationTimestamp.Time
+		}
 	}
 	// The names tracked in-memory should at least have all the data that is in the api-server
 	// This doesn't ensure that the two states are exactly aligned (we could still not be tracking a node
 	// that exists in the cluster state but not in the apiserver) but it ensures that we have a state
 	// representation for every node/nodeClaim that exists on the apiserver
-	return stateNodeClaimNames.IsSuperset(nodeClaimNames) && stateNodeNames.IsSuperset(nodeNames)
+	nodeClaimsByName := lo.SliceToMap(nodeClaims, func(nc *v1.NodeClaim) (string, *v1.NodeClaim) { return nc.Name, nc })
+	for name, providerID := range stateNodeClaims {
+		// If a node claim doesn't have a provider ID, then the nodeclaim hasn't been launched, and we need to wait
+		// to see what the resolved values are before continuing.
+		if providerID == "" {
+			status.NotLaunchedNodeClaims = append(status.NotLaunchedNodeClaims, name)
+			if nodeClaim, ok := nodeClaimsByName[name]; ok {
+				observe(nodeClaim.CreationTimestamp)
+			}
+		}
+	}
+	for _, nodeClaim := range nodeClaims {
+		if _, ok := stateNodeClaims[nodeClaim.Name]; !ok {
+			status.UntrackedNodeClaims = append(status.UntrackedNodeClaims, nodeClaim.Name)
+			observe(nodeClaim.CreationTimestamp)
+		}
+	}
+	for _, node := range nodeList.Items {
+		if !stateNodeNames.Has(node.Name) {
+			status.UntrackedNodes = append(status.UntrackedNodes, node.Name)
+			observe(node.CreationTimestamp)
+		}
+	}
+	return status, nil
+}
+
+// Synced validates that the NodeClaims and the Nodes that are stored in the apiserver
+// have the same representation in the cluster state. This is to ensure that our view
+// of the cluster is as close to correct as it can be when we begin to perform operations
+// utilizing the cluster state as our source of truth
+func (c *Cluster) Synced(ctx context.Context) bool {
+	status, err := c.SyncStatus(ctx)
+	c.recordSyncStatus(status, err)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed checking cluster state sync")
+		return false
+	}
+	return status.Synced()
+}
+
+// SyncedWithinStaleness is a relaxed form of Synced that tolerates a bounded amount of staleness. If a max staleness is
+// configured, the cluster state is considered synced when enough of the NodeClaims and Nodes are represented in it
+// and none of the ones that aren't were created longer ago than the max staleness. This prevents a single node that's
+// slow to register from blocking decisions that can tolerate a slightly stale view of the cluster.
+func (c *Cluster) SyncedWithinStaleness(ctx context.Context) (bool, SyncStatus) {
+	status, err := c.SyncStatus(ctx)
+	c.recordSyncStatus(status, err)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed checking cluster state sync")
+		return false, SyncStatus{}
+	}
+	if status.Synced() {
+		return true, status
+	}
+	opts := options.FromContext(ctx)
+	if opts.SyncMaxStaleness <= 0 {
+		return false, status
+	}
+	return status.SyncedPercent() >= float64(opts.SyncMinPercent) && c.clock.Since(status.OldestUnsynced()) <= opts.SyncMaxStaleness, status
+}
+
+// recordSyncStatus records the sync metrics. The cluster state is considered unsynced if its sync status couldn't be
+// checked, in which case the unsynced object counts are left as they were.
+func (c *Cluster) recordSyncStatus(status SyncStatus, err error) {
+	synced := err == nil && status.Synced()
+	if synced {
+		c.unsyncedStartTime = time.Time{}
+		ClusterStateUnsyncedTimeSeconds.Set(0, nil)
+	} else {
+		if c.unsyncedStartTime.IsZero() {
+			c.unsyncedStartTime = c.clock.Now()
+		}
+		ClusterStateUnsyncedTimeSeconds.Set(c.clock.Since(c.unsyncedStartTime).Seconds(), nil)
+	}
+	ClusterStateSynced.Set(lo.Ternary[float64](synced, 1, 0), nil)
+	if err != nil {
+		return
+	}
+	ClusterStateUnsyncedObjects.Set(float64(len(status.NotLaunchedNodeClaims)), map[string]string{metrics.ReasonLabel: "nodeclaim_not_launched"})
+	ClusterStateUnsyncedObjects.Set(float64(len(status.UntrackedNodeClaims)), map[string]string{metrics.ReasonLabel: "nodeclaim_untracked"})
+	ClusterStateUnsyncedObjects.Set(float64(len(status.UntrackedNodes)), map[string]string{metrics.ReasonLabel: "node_untracked"})
 }
 
 // ForPodsWithAntiAffinity calls the supplied function once for each pod with required anti affinity terms that is
@@ -206,13 +314,110 @@ func (c *Cluster) IsNodeNominated(providerID string) bool {
 	return false
 }
 
-// NominateNodeForPod records that a node was the target of a pending pod during a scheduling batch
-func (c *Cluster) NominateNodeForPod(ctx context.Context, providerID string) {
+// NominateNodeForPod records that a node was the target of the pending pods during a scheduling batch. Capacity on the
+// node that an earlier scheduling batch nominated to other pods is kept for those pods, so the pods that no longer fit
+// on the node conflict with the earlier nominations. These pods aren't nominated to the node and are returned so that
+// they can be scheduled again.
+func (c *Cluster) NominateNodeForPod(ctx context.Context, providerID string, pods ...*corev1.Pod) []*corev1.Pod {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if n, ok := c.nodes[providerID]; ok {
-		n.Nominate(ctx) // extends nomination window if already nominated
+	n, ok := c.nodes[providerID]
+	if !ok {
+		return nil
+	}
+	n.Nominate(ctx) // extends nomination window if already nominated
+	podKeys := sets.New(lo.Map(pods, func(p *corev1.Pod, _ int) types.NamespacedName { return client.ObjectKeyFromObject(p) })...)
+	nominated := c.nominatedRequests(n, podKeys)
+	var conflicts []*corev1.Pod
+	for _, pod := range pods {
+		requests := resources.RequestsForPods(pod)
+		if !resources.Fits(resources.Merge(nominated, requests), n.Available()) {
+			conflicts = append(conflicts, pod)
+			continue
+		}
+		nominated = resources.Merge(nominated, requests)
+		c.storeNodeNomination(providerID, client.ObjectKeyFromObject(pod), nodeNomination{
+			requests: requests,
+			until:    c.clock.Now().Add(nominationWindow(ctx)),
+		})
+	}
+	if len(conflicts) > 0 {
+		PodNominationConflictsTotal.Add(float64(len(conflicts)), map[string]string{})
+		log.FromContext(ctx).WithValues("node", n.Name(), "pods", lo.Map(conflicts, func(p *corev1.Pod, _ int) string {
+			return client.ObjectKeyFromObject(p).String()
+		})).V(1).Info("pod nominations conflict with an earlier scheduling batch")
+	}
+	return conflicts
+}
+
+// NominatedRequests returns the requests of the pending pods that were nominated to the node, other than the passed pods
+func (c *Cluster) NominatedRequests(providerID string, excluded sets.Set[types.NamespacedName]) corev1.ResourceList {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	n, ok := c.nodes[providerID]
+	if !ok {
+		return corev1.ResourceList{}
+	}
+	return c.nominatedRequests(n, excluded)
+}
+
+func (c *Cluster) nominatedRequests(n *StateNode, excluded sets.Set[types.NamespacedName]) corev1.ResourceList {
+	c.nodeNominationsMu.Lock()
+	defer c.nodeNominationsMu.Unlock()
+
+	var requests []corev1.ResourceList
+	for podKey, nomination := range c.nodeNominations[n.ProviderID()] {
+		if excluded.Has(podKey) {
+			continue
+		}
+		if !c.clock.Now().Before(nomination.until) {
+			c.deleteNodeNomination(podKey)
+			continue
+		}
+		// Pods that bound to the node are already counted in its requests
+		if n.Node != nil && c.bindings[podKey] == n.Node.Name {
+			continue
+		}
+		requests = append(requests, nomination.requests)
+	}
+	return resources.Merge(requests...)
+}
+
+func (c *Cluster) storeNodeNomination(providerID string, podKey types.NamespacedName, nomination nodeNomination) {
+	c.nodeNominationsMu.Lock()
+	defer c.nodeNominationsMu.Unlock()
+
+	c.deleteNodeNomination(podKey)
+	if _, ok := c.nodeNominations[providerID]; !ok {
+		c.nodeNominations[providerID] = map[types.NamespacedName]nodeNomination{}
+	}
+	c.nodeNominations[providerID][podKey] = nomination
+	c.podNodeNominations[podKey] = providerID
+}
+
+// deleteNodeNomination removes the nomination of the pod to an existing node. The caller must hold nodeNominationsMu.
+func (c *Cluster) deleteNodeNomination(podKey types.NamespacedName) {
+	providerID, ok := c.podNodeNominations[podKey]
+	if !ok {
+		return
+	}
+	delete(c.podNodeNominations, podKey)
+	delete(c.nodeNominations[providerID], podKey)
+	if len(c.nodeNominations[providerID]) == 0 {
+		delete(c.nodeNominations, providerID)
+	}
+}
+
+// ClearNodeNominations removes the nominations of the pods to existing nodes, e.g. when a new scheduling batch makes a
+// new decision for them
+func (c *Cluster) ClearNodeNominations(pods ...*corev1.Pod) {
+	c.nodeNominationsMu.Lock()
+	defer c.nodeNominationsMu.Unlock()
+
+	for _, pod := range pods {
+		c.deleteNodeNomination(client.ObjectKeyFromObject(pod))
 	}
 }
 
@@ -309,7 +514,7 @@ func (c *Cluster) UpdatePod(ctx context.Context, pod *corev1.Pod) error {
 	defer c.mu.Unlock()
 
 	var err error
-	if podutils.IsTerminal(pod) {
+	if podutils.IsTerminal(pod) || c.isIgnoredTerminatingPod(ctx, pod) {
 		c.updateNodeUsageFromPodCompletion(client.ObjectKeyFromObject(pod))
 	} else {
 		err = c.updateNodeUsageFromPod(ctx, pod)
@@ -375,6 +580,35 @@ func (c *Cluster) PodSchedulingSuccessTime(podKey types.NamespacedName) time.Tim
 	return time.Time{}
 }
 
+// NominatePodsToNodeClaim records that the pods are expected to schedule to the NodeClaim once its node is ready
+func (c *Cluster) NominatePodsToNodeClaim(nodeClaimName string, pods ...*corev1.Pod) {
+	for _, pod := range pods {
+		c.podNominations.Store(client.ObjectKeyFromObject(pod), nodeClaimName)
+	}
+}
+
+// NominatedPods returns the pods that were nominated to the NodeClaim and haven't been cleared since
+func (c *Cluster) NominatedPods(nodeClaimName string) []types.NamespacedName {
+	var pods []types.NamespacedName
+	c.podNominations.Range(func(k, v any) bool {
+		if v.(string) == nodeClaimName {
+			pods = append(pods, k.(types.NamespacedName))
+		}
+		return true
+	})
+	return pods
+}
+
+// ClearNominatedPods removes the nominations of all pods that were nominated to the NodeClaim
+func (c *Cluster) ClearNominatedPods(nodeClaimName string) {
+	c.podNominations.Range(func(k, v any) bool {
+		if v.(string) == nodeClaimName {
+			c.podNominations.Delete(k)
+		}
+		return true
+	})
+}
+
 func (c *Cluster) DeletePod(podKey types.NamespacedName) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -389,6 +623,20 @@ func (c *Cluster) ClearPodSchedulingMappings(podKey types.NamespacedName) {
 	c.podAcks.Delete(podKey)
 	c.podsSchedulableTimes.Delete(podKey)
 	c.podsSchedulingAttempted.Delete(podKey)
+	c.podNominations.Delete(podKey)
+	c.nodeNominationsMu.Lock()
+	c.deleteNodeNomination(podKey)
+	c.nodeNominationsMu.Unlock()
+}
+
+// SetChangeFrozen pauses or resumes voluntary disruption cluster-wide, returning true if the state changed
+func (c *Cluster) SetChangeFrozen(frozen bool) bool {
+	return c.changeFrozen.Swap(frozen) != frozen
+}
+
+// ChangeFrozen returns true if voluntary disruption is paused cluster-wide by the change freeze switch
+func (c *Cluster) ChangeFrozen() bool {
+	return c.changeFrozen.Load()
 }
 
 // MarkUnconsolidated marks the cluster state as being unconsolidated.  This should be called in any situation where
@@ -432,6 +680,12 @@ func (c *Cluster) Reset() {
 	c.bindings = map[types.NamespacedName]string{}
 	c.antiAffinityPods = sync.Map{}
 	c.daemonSetPods = sync.Map{}
+	c.podNominations = sync.Map{}
+	c.nodeNominationsMu.Lock()
+	c.nodeNominations = map[string]map[types.NamespacedName]nodeNomination{}
+	c.podNodeNominations = map[types.NamespacedName]string{}
+	c.nodeNominationsMu.Unlock()
+	c.changeFrozen.Store(false)
 }
 
 func (c *Cluster) GetDaemonSetPod(daemonset *appsv1.DaemonSet) *corev1.Pod {
@@ -483,6 +737,7 @@ func (c *Cluster) newStateFromNodeClaim(nodeClaim *v1.NodeClaim, oldNode *StateN
 		daemonSetLimits:   oldNode.daemonSetLimits,
 		podRequests:       oldNode.podRequests,
 		podLimits:         oldNode.podLimits,
+		podLocalData:      oldNode.podLocalData,
 		hostPortUsage:     oldNode.hostPortUsage,
 		volumeUsage:       oldNode.volumeUsage,
 		markedForDeletion: oldNode.markedForDeletion,
@@ -511,6 +766,7 @@ func (c *Cluster) cleanupNodeClaim(name string) {
 	// yet. This ensures that if a nodeClaim is created and then deleted before it was able to launch that
 	// this is cleaned up.
 	delete(c.nodeClaimNameToProviderID, name)
+	c.ClearNominatedPods(name)
 }
 
 func (c *Cluster) newStateFromNode(ctx context.Context, node *corev1.Node, oldNode *StateNode) (*StateNode, error) {
@@ -524,6 +780,7 @@ func (c *Cluster) newStateFromNode(ctx context.Context, node *corev1.Node, oldNo
 		daemonSetLimits:   map[types.NamespacedName]corev1.ResourceList{},
 		podRequests:       map[types.NamespacedName]corev1.ResourceList{},
 		podLimits:         map[types.NamespacedName]corev1.ResourceList{},
+		podLocalData:      map[types.NamespacedName]resource.Quantity{},
 		hostPortUsage:     scheduling.NewHostPortUsage(),
 		volumeUsage:       scheduling.NewVolumeUsage(),
 		markedForDeletion: oldNode.markedForDeletion,
@@ -578,7 +835,7 @@ func (c *Cluster) populateResourceRequests(ctx context.Context, n *StateNode) er
 	}
 	for i := range pods.Items {
 		pod := &pods.Items[i]
-		if podutils.IsTerminal(pod) {
+		if podutils.IsTerminal(pod) || c.isIgnoredTerminatingPod(ctx, pod) {
 			continue
 		}
 		if err := n.updateForPod(ctx, c.kubeClient, pod); err != nil {
@@ -611,6 +868,13 @@ func (c *Cluster) updateNodeUsageFromPod(ctx context.Context, pod *corev1.Pod) e
 	return nil
 }
 
+// isIgnoredTerminatingPod returns true if the pod has been terminating for longer than the configured duration, in
+// which case its resource requests are no longer counted against the node that it's bound to
+func (c *Cluster) isIgnoredTerminatingPod(ctx context.Context, pod *corev1.Pod) bool {
+	d := options.FromContext(ctx).IgnoreTerminatingPodsAfter
+	return d > 0 && podutils.IsTerminatingLongerThan(pod, c.clock, d)
+}
+
 func (c *Cluster) updateNodeUsageFromPodCompletion(podKey types.NamespacedName) {
 	nodeName, bindingKnown := c.bindings[podKey]
 	if !bindingKnown {
@@ -59,6 +59,26 @@ var (
 		},
 		[]string{},
 	)
+	ClusterStateUnsyncedObjects = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: stateSubsystem,
+			Name:      "unsynced_objects",
+			Help:      "The number of nodeclaims and nodes in the APIServer that aren't represented in cluster state, labeled by the reason that they aren't synced",
+		},
+		[]string{metrics.ReasonLabel},
+	)
+	PodNominationConflictsTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: stateSubsystem,
+			Name:      "pod_nomination_conflicts_total",
+			Help:      "Number of pods whose nomination to an existing node conflicted with the pods that an earlier scheduling batch nominated to the node. These pods are scheduled again without waiting for the earlier nominations to expire.",
+		},
+		[]string{},
+	)
 	PodSchedulingDecisionSeconds = opmetrics.NewPrometheusHistogram(
 		crmetrics.Registry,
 		prometheus.HistogramOpts{
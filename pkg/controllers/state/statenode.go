@@ -20,12 +20,14 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -123,6 +125,8 @@ type StateNode struct {
 
 	podRequests map[types.NamespacedName]corev1.ResourceList
 	podLimits   map[types.NamespacedName]corev1.ResourceList
+	// podLocalData is the amount of data that each pod keeps in disk-backed emptyDir volumes on the node
+	podLocalData map[types.NamespacedName]resource.Quantity
 
 	hostPortUsage *scheduling.HostPortUsage
 	volumeUsage   *scheduling.VolumeUsage
@@ -139,6 +143,7 @@ func NewNode() *StateNode {
 		daemonSetLimits:   map[types.NamespacedName]corev1.ResourceList{},
 		podRequests:       map[types.NamespacedName]corev1.ResourceList{},
 		podLimits:         map[types.NamespacedName]corev1.ResourceList{},
+		podLocalData:      map[types.NamespacedName]resource.Quantity{},
 		hostPortUsage:     scheduling.NewHostPortUsage(),
 		volumeUsage:       scheduling.NewVolumeUsage(),
 	}
@@ -223,6 +228,13 @@ func (in *StateNode) ValidatePodsDisruptable(ctx context.Context, kubeClient cli
 		if !podutils.IsDisruptable(po) {
 			return pods, NewPodBlockEvictionError(fmt.Errorf(`pod %q has "karpenter.sh/do-not-disrupt" annotation`, client.ObjectKeyFromObject(po)))
 		}
+		// Pods that the Cluster Autoscaler wouldn't evict block disruption when migrating from the Cluster Autoscaler
+		if options.FromContext(ctx).HonorClusterAutoscalerAnnotations && !podutils.IsDisruptableByClusterAutoscaler(po) {
+			if po.Annotations[v1.ClusterAutoscalerSafeToEvictAnnotationKey] == "false" {
+				return pods, NewPodBlockEvictionError(fmt.Errorf(`pod %q has "%s=false" annotation`, client.ObjectKeyFromObject(po), v1.ClusterAutoscalerSafeToEvictAnnotationKey))
+			}
+			return pods, NewPodBlockEvictionError(fmt.Errorf("pod %q has local storage volumes %v that aren't safe to evict", client.ObjectKeyFromObject(po), podutils.UnsafeLocalVolumes(po)))
+		}
 	}
 	if pdbKey, ok := pdbs.CanEvictPods(pods); !ok {
 		return pods, NewPodBlockEvictionError(fmt.Errorf("pdb %q prevents pod evictions", pdbKey))
@@ -286,6 +298,11 @@ func (in *StateNode) Taints() []corev1.Taint {
 	} else {
 		taints = in.Node.Spec.Taints
 	}
+	// The disruption candidate taint only steers the kube-scheduler while a consolidation decision is validated, so it
+	// shouldn't prevent us from scheduling pods against the node
+	taints = lo.Reject(taints, func(taint corev1.Taint, _ int) bool {
+		return taint.MatchTaint(&v1.DisruptionCandidatePreferNoScheduleTaint)
+	})
 	if !in.Initialized() && in.Managed() {
 		// We reject any well-known ephemeral taints and startup taints attached to this node until
 		// the node is initialized. Without this, if the taint is generic and re-appears on the node for a
@@ -365,6 +382,24 @@ func (in *StateNode) Available() corev1.ResourceList {
 	return resources.Subtract(in.Allocatable(), in.PodRequests())
 }
 
+// ReservedResources returns the headroom that has been reserved on the node through karpenter.sh/reserved-<resource>
+// annotations. The scheduler won't pack pods into this headroom, keeping slack on the node for bursting.
+func (in *StateNode) ReservedResources() corev1.ResourceList {
+	reserved := corev1.ResourceList{}
+	for key, value := range in.Annotations() {
+		name, ok := strings.CutPrefix(key, v1.ReservedResourceAnnotationKeyPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			continue
+		}
+		reserved[corev1.ResourceName(name)] = quantity
+	}
+	return reserved
+}
+
 func (in *StateNode) DaemonSetRequests() corev1.ResourceList {
 	return resources.Merge(lo.Values(in.daemonSetRequests)...)
 }
@@ -393,6 +428,21 @@ func (in *StateNode) PodLimits() corev1.ResourceList {
 	return resources.Merge(lo.Values(in.podLimits)...)
 }
 
+// LocalData returns the amount of data that the pods on the node keep in disk-backed emptyDir volumes, which is lost
+// when the pods are moved to another node
+func (in *StateNode) LocalData() resource.Quantity {
+	var total resource.Quantity
+	for _, q := range in.podLocalData {
+		total.Add(q)
+	}
+	return total
+}
+
+// PodLocalData returns the amount of data that the pod keeps in disk-backed emptyDir volumes on the node
+func (in *StateNode) PodLocalData(podKey types.NamespacedName) resource.Quantity {
+	return in.podLocalData[podKey]
+}
+
 func (in *StateNode) MarkedForDeletion() bool {
 	// The Node is marked for deletion if:
 	//  1. The Node has MarkedForDeletion set
@@ -427,6 +477,9 @@ func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client,
 	}
 	in.podRequests[podKey] = resources.RequestsForPods(pod)
 	in.podLimits[podKey] = resources.LimitsForPods(pod)
+	if localData := podutils.LocalData(pod); !localData.IsZero() {
+		in.podLocalData[podKey] = localData
+	}
 	// if it's a daemonset, we track what it has requested separately
 	if podutils.IsOwnedByDaemonSet(pod) {
 		in.daemonSetRequests[podKey] = resources.RequestsForPods(pod)
@@ -442,6 +495,7 @@ func (in *StateNode) cleanupForPod(podKey types.NamespacedName) {
 	in.volumeUsage.DeletePod(podKey)
 	delete(in.podRequests, podKey)
 	delete(in.podLimits, podKey)
+	delete(in.podLocalData, podKey)
 	delete(in.daemonSetRequests, podKey)
 	delete(in.daemonSetLimits, podKey)
 }
@@ -457,8 +511,18 @@ func nominationWindow(ctx context.Context) time.Duration {
 // RequireNoScheduleTaint will add/remove the karpenter.sh/disruption:NoSchedule taint from the candidates.
 // This is used to enforce no taints at the beginning of disruption, and
 // to add/remove taints while executing a disruption action.
-// nolint:gocyclo
 func RequireNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTaint bool, nodes ...*StateNode) error {
+	return requireTaint(ctx, kubeClient, v1.DisruptedNoScheduleTaint, addTaint, nodes...)
+}
+
+// RequireDisruptionCandidateTaint will add/remove the karpenter.sh/disruption-candidate:PreferNoSchedule taint from
+// the candidates. This is used to steer pods away from consolidation candidates while the decision is validated.
+func RequireDisruptionCandidateTaint(ctx context.Context, kubeClient client.Client, addTaint bool, nodes ...*StateNode) error {
+	return requireTaint(ctx, kubeClient, v1.DisruptionCandidatePreferNoScheduleTaint, addTaint, nodes...)
+}
+
+// nolint:gocyclo
+func requireTaint(ctx context.Context, kubeClient client.Client, taintToRequire corev1.Taint, addTaint bool, nodes ...*StateNode) error {
 	var multiErr error
 	for _, n := range nodes {
 		// If the StateNode is Karpenter owned and only has a nodeclaim, or is not owned by
@@ -472,7 +536,7 @@ func RequireNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTa
 		}
 		// If the node already has the taint, continue to the next
 		_, hasTaint := lo.Find(node.Spec.Taints, func(taint corev1.Taint) bool {
-			return taint.MatchTaint(&v1.DisruptedNoScheduleTaint)
+			return taint.MatchTaint(&taintToRequire)
 		})
 		// Node is being deleted, so no need to remove taint as the node will be gone soon.
 		// This ensures that the disruption controller doesn't modify taints that the Termination
@@ -484,15 +548,15 @@ func RequireNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTa
 		// If the taint is present and we want to remove the taint, remove it.
 		if !addTaint {
 			node.Spec.Taints = lo.Reject(node.Spec.Taints, func(taint corev1.Taint, _ int) bool {
-				return taint.MatchTaint(&v1.DisruptedNoScheduleTaint)
+				return taint.MatchTaint(&taintToRequire)
 			})
 			// otherwise, add it.
 		} else if addTaint && !hasTaint {
 			// If the taint key is present (but with a different value or effect), remove it.
 			node.Spec.Taints = lo.Reject(node.Spec.Taints, func(taint corev1.Taint, _ int) bool {
-				return taint.MatchTaint(&v1.DisruptedNoScheduleTaint)
+				return taint.MatchTaint(&taintToRequire)
 			})
-			node.Spec.Taints = append(node.Spec.Taints, v1.DisruptedNoScheduleTaint)
+			node.Spec.Taints = append(node.Spec.Taints, taintToRequire)
 		}
 		if !equality.Semantic.DeepEqual(stored, node) {
 			// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
@@ -41,6 +41,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -436,6 +437,58 @@ var _ = Describe("Node Resource Level", func() {
 		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod2))
 		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3.5")}, ExpectStateNodeExists(cluster, node).PodRequests())
 	})
+	It("should track the local data of pods with disk-backed emptyDir volumes", func() {
+		// the pod's ephemeral-storage requests are used rather than the sizeLimit of its volumes
+		pod1 := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+				}},
+		})
+		pod1.Spec.Volumes = []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: lo.ToPtr(resource.MustParse("20Gi"))}}},
+			{Name: "tmpfs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: lo.ToPtr(resource.MustParse("1Gi"))}}},
+		}
+		pod2 := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceEphemeralStorage: resource.MustParse("5Gi"),
+				}},
+		})
+		pod2.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1.NodePoolLabelKey:            nodePool.Name,
+				corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			ProviderID: test.RandomProviderID(),
+		})
+		// pods without disk-backed emptyDir volumes don't keep local data
+		pod3 := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				}},
+		})
+		ExpectApplied(ctx, env.Client, pod1, pod2, pod3, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		ExpectManualBinding(ctx, env.Client, pod1, node)
+		ExpectManualBinding(ctx, env.Client, pod2, node)
+		ExpectManualBinding(ctx, env.Client, pod3, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod1))
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod2))
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod3))
+
+		stateNode := ExpectStateNodeExists(cluster, node)
+		Expect(stateNode.PodLocalData(client.ObjectKeyFromObject(pod1)).Equal(resource.MustParse("10Gi"))).To(BeTrue())
+		Expect(stateNode.PodLocalData(client.ObjectKeyFromObject(pod2)).Equal(resource.MustParse("5Gi"))).To(BeTrue())
+		Expect(stateNode.LocalData().Equal(resource.MustParse("15Gi"))).To(BeTrue())
+
+		ExpectDeleted(ctx, env.Client, pod1)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod1))
+		Expect(ExpectStateNodeExists(cluster, node).LocalData().Equal(resource.MustParse("5Gi"))).To(BeTrue())
+	})
 	It("should count existing pods bound to nodes", func() {
 		pod1 := test.UnschedulablePod(test.PodOptions{
 			ResourceRequirements: corev1.ResourceRequirements{
@@ -555,6 +608,70 @@ var _ = Describe("Node Resource Level", func() {
 
 		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")}, ExpectStateNodeExists(cluster, node).PodRequests())
 	})
+	It("should continue to add requests for terminating pods by default", func() {
+		pod := test.UnschedulablePod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Finalizers: []string{"test/finalizer"}},
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU: resource.MustParse("1.5"),
+				}},
+		})
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1.NodePoolLabelKey:            nodePool.Name,
+				corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+
+		Expect(env.Client.Delete(ctx, pod)).To(Succeed())
+		fakeClock.Step(time.Hour)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")}, ExpectStateNodeExists(cluster, node).PodRequests())
+		ExpectFinalizersRemoved(ctx, env.Client, pod)
+	})
+	It("should stop adding requests for pods that have been terminating longer than the configured duration", func() {
+		terminatingCtx := options.ToContext(ctx, test.Options(test.OptionsFields{IgnoreTerminatingPodsAfter: lo.ToPtr(time.Minute)}))
+		pod := test.UnschedulablePod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Finalizers: []string{"test/finalizer"}},
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU: resource.MustParse("1.5"),
+				}},
+		})
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1.NodePoolLabelKey:            nodePool.Name,
+				corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(terminatingCtx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(terminatingCtx, podController, client.ObjectKeyFromObject(pod))
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")}, ExpectStateNodeExists(cluster, node).PodRequests())
+
+		// the pod still counts against the node until it's been terminating for longer than a minute
+		Expect(env.Client.Delete(ctx, pod)).To(Succeed())
+		ExpectReconcileSucceeded(terminatingCtx, podController, client.ObjectKeyFromObject(pod))
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")}, ExpectStateNodeExists(cluster, node).PodRequests())
+
+		fakeClock.Step(2 * time.Minute)
+		ExpectReconcileSucceeded(terminatingCtx, podController, client.ObjectKeyFromObject(pod))
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")}, ExpectStateNodeExists(cluster, node).PodRequests())
+		ExpectFinalizersRemoved(ctx, env.Client, pod)
+	})
 	It("should stop tracking nodes that are deleted", func() {
 		pod1 := test.UnschedulablePod(test.PodOptions{
 			ResourceRequirements: corev1.ResourceRequirements{
@@ -943,6 +1060,70 @@ var _ = Describe("Node Resource Level", func() {
 	})
 })
 
+var _ = Describe("Pod Nominations", func() {
+	var node *corev1.Node
+	var pods []*corev1.Pod
+	BeforeEach(func() {
+		state.PodNominationConflictsTotal.Reset()
+		node = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+				},
+			},
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:  resource.MustParse("4"),
+				corev1.ResourcePods: resource.MustParse("10"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		pods = test.UnschedulablePods(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("3")},
+			},
+		}, 2)
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+	})
+	It("should return the pods that conflict with the pods that were nominated to the node earlier", func() {
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[0])).To(BeEmpty())
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[1])).To(ConsistOf(pods[1]))
+		ExpectMetricCounterValue(state.PodNominationConflictsTotal, 1, map[string]string{})
+		requests := cluster.NominatedRequests(node.Spec.ProviderID, nil)
+		Expect(requests.Cpu().String()).To(Equal("3"))
+	})
+	It("should not conflict with the pod's own nomination", func() {
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[0])).To(BeEmpty())
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[0])).To(BeEmpty())
+	})
+	It("should not conflict with nominations that were cleared", func() {
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[0])).To(BeEmpty())
+		cluster.ClearNodeNominations(pods[0])
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[1])).To(BeEmpty())
+	})
+	It("should not conflict with nominations that expired", func() {
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[0])).To(BeEmpty())
+		fakeClock.Step(time.Minute)
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[1])).To(BeEmpty())
+	})
+	It("should not count nominated pods that bound to the node twice", func() {
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[0])).To(BeEmpty())
+		ExpectApplied(ctx, env.Client, pods[0])
+		ExpectManualBinding(ctx, env.Client, pods[0], node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pods[0]))
+		Expect(cluster.NominatedRequests(node.Spec.ProviderID, nil)).To(BeEmpty())
+
+		small := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+		})
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, small)).To(BeEmpty())
+		Expect(cluster.NominateNodeForPod(ctx, node.Spec.ProviderID, pods[1])).To(ConsistOf(pods[1]))
+	})
+})
+
 var _ = Describe("Pod Anti-Affinity", func() {
 	It("should track pods with required anti-affinity", func() {
 		pod := test.UnschedulablePod(test.PodOptions{
@@ -1337,6 +1518,76 @@ var _ = Describe("Cluster State Sync", func() {
 		Expect(cluster.Synced(ctx)).To(BeTrue())
 		ExpectMetricGaugeValue(state.ClusterStateSynced, 1, nil)
 	})
+	It("should report the reasons that the cluster state isn't synced", func() {
+		nodeClaim := test.NodeClaim()
+		nodeClaim.Status.ProviderID = ""
+		untrackedNodeClaim := test.NodeClaim()
+		node := test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()})
+		ExpectApplied(ctx, env.Client, nodeClaim, untrackedNodeClaim, node)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		status, err := cluster.SyncStatus(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Synced()).To(BeFalse())
+		Expect(status.NotLaunchedNodeClaims).To(ConsistOf(nodeClaim.Name))
+		Expect(status.UntrackedNodeClaims).To(ConsistOf(untrackedNodeClaim.Name))
+		Expect(status.UntrackedNodes).To(ConsistOf(node.Name))
+
+		Expect(cluster.Synced(ctx)).To(BeFalse())
+		ExpectMetricGaugeValue(state.ClusterStateUnsyncedObjects, 1, map[string]string{metrics.ReasonLabel: "nodeclaim_not_launched"})
+		ExpectMetricGaugeValue(state.ClusterStateUnsyncedObjects, 1, map[string]string{metrics.ReasonLabel: "nodeclaim_untracked"})
+		ExpectMetricGaugeValue(state.ClusterStateUnsyncedObjects, 1, map[string]string{metrics.ReasonLabel: "node_untracked"})
+	})
+	Context("Bounded Staleness", func() {
+		BeforeEach(func() {
+			for i := 0; i < 9; i++ {
+				node := test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()})
+				ExpectApplied(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+			}
+		})
+		It("should only consider the cluster state synced when fully synced if no max staleness is configured", func() {
+			ExpectApplied(ctx, env.Client, test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()}))
+
+			synced, status := cluster.SyncedWithinStaleness(ctx)
+			Expect(synced).To(BeFalse())
+			Expect(status.UntrackedNodes).To(HaveLen(1))
+		})
+		It("should consider the cluster state synced when untracked objects are within the max staleness", func() {
+			staleCtx := options.ToContext(ctx, test.Options(test.OptionsFields{SyncMinPercent: lo.ToPtr(90), SyncMaxStaleness: lo.ToPtr(time.Minute)}))
+			ExpectApplied(ctx, env.Client, test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()}))
+
+			synced, _ := cluster.SyncedWithinStaleness(staleCtx)
+			Expect(synced).To(BeTrue())
+			Expect(cluster.Synced(ctx)).To(BeFalse())
+		})
+		It("shouldn't consider the cluster state synced when untracked objects are older than the max staleness", func() {
+			staleCtx := options.ToContext(ctx, test.Options(test.OptionsFields{SyncMinPercent: lo.ToPtr(90), SyncMaxStaleness: lo.ToPtr(time.Minute)}))
+			ExpectApplied(ctx, env.Client, test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()}))
+
+			fakeClock.Step(2 * time.Minute)
+			synced, _ := cluster.SyncedWithinStaleness(staleCtx)
+			Expect(synced).To(BeFalse())
+		})
+		It("shouldn't consider the cluster state synced when too few objects are tracked", func() {
+			staleCtx := options.ToContext(ctx, test.Options(test.OptionsFields{SyncMinPercent: lo.ToPtr(95), SyncMaxStaleness: lo.ToPtr(time.Minute)}))
+			ExpectApplied(ctx, env.Client, test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()}))
+
+			synced, _ := cluster.SyncedWithinStaleness(staleCtx)
+			Expect(synced).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Metrics", func() {
+	It("should publish the cluster state metrics from standby replicas", func() {
+		// Standby replicas track the cluster state with their own cluster, which is never tied to the leader election
+		standby := state.NewCluster(fakeClock, env.Client, cloudProvider)
+		state.ClusterStateNodesCount.Set(0, nil)
+
+		Expect(standby.UpdateNode(ctx, test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()}))).To(Succeed())
+		ExpectMetricGaugeValue(state.ClusterStateNodesCount, 1, nil)
+	})
 })
 
 var _ = Describe("DaemonSet Controller", func() {
@@ -113,6 +113,13 @@ func (in *StateNode) DeepCopyInto(out *StateNode) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.podLocalData != nil {
+		in, out := &in.podLocalData, &out.podLocalData
+		*out = make(map[types.NamespacedName]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	if in.hostPortUsage != nil {
 		in, out := &in.hostPortUsage, &out.hostPortUsage
 		*out = new(scheduling.HostPortUsage)
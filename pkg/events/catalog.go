@@ -0,0 +1,316 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons that Karpenter publishes events with. These are part of Karpenter's API so that alerting can be built on
+// top of them, a reason is never renamed or reused for a different condition once it's released.
+const (
+	// Pod events
+	Evicted                 = "Evicted"
+	Disrupted               = "Disrupted"
+	Bound                   = "Bound"
+	Rescheduled             = "Rescheduled"
+	Nominated               = "Nominated"
+	FailedScheduling        = "FailedScheduling"
+	ClusterLimitsExceeded   = "ClusterLimitsExceeded"
+	BatchWindowExtended     = "BatchWindowExtended"
+	BatchMaxDurationReached = "BatchMaxDurationReached"
+
+	// Node events
+	StartupTaintsRemoved              = "StartupTaintsRemoved"
+	FailedDraining                    = "FailedDraining"
+	Expired                           = "Expired"
+	Adopted                           = "Adopted"
+	AdoptionSkipped                   = "AdoptionSkipped"
+	NodeRepairBlocked                 = "NodeRepairBlocked"
+	DisruptionWaitingVolumeDetachment = "DisruptionWaitingVolumeDetachment"
+	DeletionBlocked                   = "DeletionBlocked"
+	DrainSnapshot                     = "DrainSnapshot"
+
+	// NodeClaim events
+	DisruptionLaunching        = "DisruptionLaunching"
+	DisruptionWaitingReadiness = "DisruptionWaitingReadiness"
+	FailedConsistencyCheck     = "FailedConsistencyCheck"
+	InsufficientCapacityError  = "InsufficientCapacityError"
+	NodeClassNotReady          = "NodeClassNotReady"
+	Terminated                 = "Terminated"
+	InstanceTypeMismatch       = "InstanceTypeMismatch"
+
+	// NodePool events
+	NoCompatibleInstanceTypes  = "NoCompatibleInstanceTypes"
+	DisruptionTerminatingEmpty = "DisruptionTerminatingEmpty"
+
+	// ConfigMap events
+	ChangeFreezeEnabled = "ChangeFreezeEnabled"
+	ChangeFreezeLifted  = "ChangeFreezeLifted"
+
+	// Events published for more than one kind of object
+	TerminationGracePeriodExpiring = "TerminationGracePeriodExpiring"
+	DisruptionTerminating          = "DisruptionTerminating"
+	Unconsolidatable               = "Unconsolidatable"
+	DisruptionBlocked              = "DisruptionBlocked"
+)
+
+// Schema describes an event that Karpenter publishes. Message is the template of the event's message, the values
+// that are filled in are written in braces.
+type Schema struct {
+	Reason          string
+	Type            string
+	InvolvedObjects []string
+	Message         string
+	Description     string
+}
+
+// Catalog is the list of every event that Karpenter publishes. The events documentation is generated from it, so it
+// must be updated whenever an event is added or its message changes.
+var Catalog = []Schema{
+	{
+		Reason:          Evicted,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Evicted pod: {message}",
+		Description:     "The pod was evicted while its node was draining.",
+	},
+	{
+		Reason:          Disrupted,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Deleting the pod to accommodate the terminationTime {terminationTime} of the node. The pod was granted {gracePeriodSeconds} seconds of grace-period of its {terminationGracePeriodSeconds} terminationGracePeriodSeconds. This bypasses the PDB of the pod and the do-not-disrupt annotation.",
+		Description:     "The pod was deleted ahead of its node's termination grace period expiring.",
+	},
+	{
+		Reason:          Bound,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Bound pod to node {node}",
+		Description:     "The pod was bound to the node that it was nominated to once the node became ready.",
+	},
+	{
+		Reason:          Rescheduled,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Rescheduled to node {node} after node {drained node} was drained for {termination reason}",
+		Description:     "The pod replaced a pod of the same owner that was drained from a terminating node.",
+	},
+	{
+		Reason:          Nominated,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Pod should schedule on: {nodeclaim/name}, {node/name}",
+		Description:     "The pod was nominated to a node or nodeclaim that is expected to fit it.",
+	},
+	{
+		Reason:          FailedScheduling,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Failed to schedule pod, {error}",
+		Description:     "The pod couldn't be scheduled against any existing node or NodePool.",
+	},
+	{
+		Reason:          ClusterLimitsExceeded,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Provisioning blocked by cluster limits, {error}",
+		Description:     "Launching capacity for the pod would exceed a cluster-wide limit.",
+	},
+	{
+		Reason:          BatchWindowExtended,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Provisioning waited {duration} for the batching window, which other pods extended {extensions} times",
+		Description:     "The batching window that the pod was provisioned in was kept open by other pods until it was idle for the batch idle duration.",
+	},
+	{
+		Reason:          BatchMaxDurationReached,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Pod"},
+		Message:         "Provisioning waited the maximum batch duration of {duration} for the batching window",
+		Description:     "The batching window that the pod was provisioned in was closed by the batch max duration before it was idle.",
+	},
+	{
+		Reason:          StartupTaintsRemoved,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Removed startup taints {taints} that remained longer than {timeout}",
+		Description:     "The startup taints weren't removed by their owners before the timeout.",
+	},
+	{
+		Reason:          FailedDraining,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Failed to drain node, {error}",
+		Description:     "Pods on the node couldn't be evicted, the drain is retried.",
+	},
+	{
+		Reason:          Expired,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Node expired, draining it before its owner deletes it",
+		Description:     "An unmanaged node exceeded its expiration and was cordoned to be drained.",
+	},
+	{
+		Reason:          Adopted,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Adopted Node with NodeClaim {nodeclaim} after its NodeClaim was deleted",
+		Description:     "A new NodeClaim was created for a node whose NodeClaim was deleted.",
+	},
+	{
+		Reason:          AdoptionSkipped,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Skipped adopting Node after its NodeClaim was deleted, its instance no longer exists",
+		Description:     "A node whose NodeClaim was deleted wasn't adopted because the cloud provider no longer has its instance.",
+	},
+	{
+		Reason:          NodeRepairBlocked,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Node"},
+		Message:         "{reason}",
+		Description:     "An unhealthy node can't be repaired, e.g. because too many nodes in its NodePool are unhealthy.",
+	},
+	{
+		Reason:          DisruptionWaitingVolumeDetachment,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Waiting on volumes to detach to continue disruption",
+		Description:     "Termination of the node is waiting on its volume attachments to be removed.",
+	},
+	{
+		Reason:          DrainSnapshot,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Draining {count} pods for {termination reason}: {pods}",
+		Description:     "The pods on the node were recorded before it was drained. Up to 50 of them are kept in the node's karpenter.sh/drain-snapshot annotation.",
+	},
+	{
+		Reason:          DeletionBlocked,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node"},
+		Message:         "Deletion blocked by {blockers} until {expiration time}",
+		Description:     "Termination of the node is waiting on external systems that registered deletion blocks on it.",
+	},
+	{
+		Reason:          DisruptionLaunching,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "Launching NodeClaim: {disruption reason}",
+		Description:     "A replacement NodeClaim was launched for a disruption.",
+	},
+	{
+		Reason:          DisruptionWaitingReadiness,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "Waiting on readiness to continue disruption",
+		Description:     "A disruption is waiting on its replacement NodeClaim to become ready.",
+	},
+	{
+		Reason:          FailedConsistencyCheck,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "{message}",
+		Description:     "The NodeClaim and its node don't agree, e.g. the node has less capacity than the NodeClaim expected.",
+	},
+	{
+		Reason:          InsufficientCapacityError,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "NodeClaim {nodeclaim} event: {error}",
+		Description:     "The cloud provider didn't have capacity to launch the NodeClaim, the NodeClaim is deleted.",
+	},
+	{
+		Reason:          NodeClassNotReady,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "NodeClaim {nodeclaim} event: {error}",
+		Description:     "The NodeClaim's NodeClass wasn't ready to launch with, the NodeClaim is deleted.",
+	},
+	{
+		Reason:          Terminated,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "Terminated NodeClaim due to {termination reason}",
+		Description:     "The NodeClaim's instance was terminated.",
+	},
+	{
+		Reason:          InstanceTypeMismatch,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"NodeClaim"},
+		Message:         "Launched instance type {instance type} instead of the predicted instance type {predicted instance type}",
+		Description:     "The cloud provider launched a different instance type than the scheduler predicted.",
+	},
+	{
+		Reason:          NoCompatibleInstanceTypes,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"NodePool"},
+		Message:         "NodePool requirements filtered out all compatible available instance types",
+		Description:     "None of the cloud provider's instance types are compatible with the NodePool's requirements.",
+	},
+	{
+		Reason:          DisruptionTerminatingEmpty,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"NodePool"},
+		Message:         "Disrupting {count} empty NodeClaims: {NodeClaim names}",
+		Description:     "The NodePool's empty nodes are being deleted together by empty node consolidation.",
+	},
+	{
+		Reason:          ChangeFreezeEnabled,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"ConfigMap"},
+		Message:         "Change freeze enabled, voluntary disruption is paused",
+		Description:     "The change freeze ConfigMap paused voluntary disruption.",
+	},
+	{
+		Reason:          ChangeFreezeLifted,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"ConfigMap"},
+		Message:         "Change freeze lifted, voluntary disruption resumed",
+		Description:     "The change freeze ConfigMap no longer pauses voluntary disruption.",
+	},
+	{
+		Reason:          TerminationGracePeriodExpiring,
+		Type:            corev1.EventTypeWarning,
+		InvolvedObjects: []string{"Node", "NodeClaim"},
+		Message:         "All pods will be deleted by {termination time}",
+		Description:     "The node is draining and its termination grace period will expire.",
+	},
+	{
+		Reason:          DisruptionTerminating,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node", "NodeClaim"},
+		Message:         "Disrupting {Node|NodeClaim}: {disruption reason}",
+		Description:     "The node is being disrupted.",
+	},
+	{
+		Reason:          Unconsolidatable,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node", "NodeClaim"},
+		Message:         "{reason}",
+		Description:     "The node can't be consolidated due to its state or the state of the pods on it.",
+	},
+	{
+		Reason:          DisruptionBlocked,
+		Type:            corev1.EventTypeNormal,
+		InvolvedObjects: []string{"Node", "NodeClaim", "NodePool"},
+		Message:         "{reason}",
+		Description:     "Disruption of the node, or of any node in the NodePool, is blocked, e.g. by a disruption budget.",
+	},
+}
@@ -24,13 +24,16 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/util/flowcontrol"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	schedulingevents "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -101,6 +104,44 @@ var _ = Describe("Event Creation", func() {
 	})
 })
 
+var _ = Describe("Catalog", func() {
+	It("should have a single schema for each reason", func() {
+		reasons := map[string]int{}
+		for _, s := range events.Catalog {
+			reasons[s.Reason]++
+		}
+		for reason, count := range reasons {
+			Expect(count).To(Equal(1), reason)
+		}
+	})
+	It("should describe each schema", func() {
+		for _, s := range events.Catalog {
+			Expect(s.Type).To(BeElementOf(corev1.EventTypeNormal, corev1.EventTypeWarning), s.Reason)
+			Expect(s.InvolvedObjects).ToNot(BeEmpty(), s.Reason)
+			Expect(s.Message).ToNot(BeEmpty(), s.Reason)
+			Expect(s.Description).ToNot(BeEmpty(), s.Reason)
+		}
+	})
+	It("should include the published events", func() {
+		for _, evt := range []events.Event{
+			schedulingevents.NominatePodEvent(PodWithUID(), NodeWithUID(), NodeClaimWithUID()),
+			schedulingevents.PodFailedToScheduleEvent(PodWithUID(), fmt.Errorf("")),
+			terminatorevents.EvictPod(PodWithUID(), ""),
+			terminatorevents.NodeFailedToDrain(NodeWithUID(), fmt.Errorf("")),
+			disruptionevents.TerminatingEmpty(NodePoolWithUID(), []*v1.NodeClaim{NodeClaimWithUID()}),
+			terminatorevents.NodeDeletionBlocked(NodeWithUID(), []string{"checkpointer"}, ""),
+			terminatorevents.NodeDrainSnapshot(NodeWithUID(), "", []string{""}),
+			terminatorevents.PodRescheduled(PodWithUID(), "", ""),
+			provisioning.BatchWindowExtendedEvent(PodWithUID(), provisioning.Window{}),
+			provisioning.BatchMaxDurationReachedEvent(PodWithUID(), provisioning.Window{}),
+		} {
+			s, ok := lo.Find(events.Catalog, func(s events.Schema) bool { return s.Reason == evt.Reason })
+			Expect(ok).To(BeTrue(), evt.Reason)
+			Expect(s.Type).To(Equal(evt.Type))
+		}
+	})
+})
+
 var _ = Describe("Dedupe", func() {
 	It("should only create a single event when many events are created quickly", func() {
 		pod := PodWithUID()
@@ -168,3 +209,9 @@ func NodeClaimWithUID() *v1.NodeClaim {
 	nc.UID = uuid.NewUUID()
 	return nc
 }
+
+func NodePoolWithUID() *v1.NodePool {
+	np := test.NodePool()
+	np.UID = uuid.NewUUID()
+	return np
+}
@@ -50,9 +50,10 @@ var (
 			Namespace: Namespace,
 			Subsystem: NodeClaimSubsystem,
 			Name:      "terminated_total",
-			Help:      "Number of nodeclaims terminated in total by Karpenter. Labeled by the owning nodepool.",
+			Help:      "Number of nodeclaims terminated in total by Karpenter. Labeled by reason the nodeclaim was terminated and the owning nodepool.",
 		},
 		[]string{
+			ReasonLabel,
 			NodePoolLabel,
 			CapacityTypeLabel,
 		},
@@ -89,9 +90,10 @@ var (
 			Namespace: Namespace,
 			Subsystem: NodeSubsystem,
 			Name:      "terminated_total",
-			Help:      "Number of nodes terminated in total by Karpenter. Labeled by owning nodepool.",
+			Help:      "Number of nodes terminated in total by Karpenter. Labeled by reason the node was terminated and owning nodepool.",
 		},
 		[]string{
+			ReasonLabel,
 			NodePoolLabel,
 		},
 	)
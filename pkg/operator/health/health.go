@@ -0,0 +1,166 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// livenessTimeoutMultiplier scales a Heartbeat's timeout for the liveness check. Readiness flips first so that a stuck
+// loop is alerted on quickly, and the pod is only restarted if the loop still hasn't recovered after the longer timeout.
+const livenessTimeoutMultiplier = 3
+
+// Heartbeat tracks the progress of a long-running singleton loop, like the provisioner or the disruption controller.
+// Singleton loops only run on the elected leader, so the timeout isn't enforced until the loop has started.
+type Heartbeat struct {
+	name       string
+	clock      clock.Clock
+	timeout    time.Duration
+	queueDepth func() int
+
+	mu      sync.RWMutex
+	started bool
+	last    time.Time
+}
+
+// Status is the point-in-time health of a single Heartbeat
+type Status struct {
+	Name               string    `json:"name"`
+	Started            bool      `json:"started"`
+	Healthy            bool      `json:"healthy"`
+	LastHeartbeat      time.Time `json:"lastHeartbeat"`
+	SinceLastHeartbeat string    `json:"sinceLastHeartbeat"`
+	Timeout            string    `json:"timeout"`
+	QueueDepth         int       `json:"queueDepth"`
+}
+
+// NewHeartbeat creates a Heartbeat that is unhealthy when it hasn't beaten within the timeout. The queueDepth func
+// reports how much work is waiting on the loop, which is included in the health detail.
+func NewHeartbeat(clk clock.Clock, name string, timeout time.Duration, queueDepth func() int) *Heartbeat {
+	return &Heartbeat{
+		name:       name,
+		clock:      clk,
+		timeout:    timeout,
+		queueDepth: queueDepth,
+	}
+}
+
+// Start marks the loop as running so that the timeout is enforced from now on. It is a no-op if the loop has already
+// started.
+func (h *Heartbeat) Start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.started {
+		h.started = true
+		h.last = h.clock.Now()
+	}
+}
+
+// Beat records that the loop has made progress
+func (h *Heartbeat) Beat() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.started = true
+	h.last = h.clock.Now()
+}
+
+func (h *Heartbeat) Status() Status {
+	h.mu.RLock()
+	started, last := h.started, h.last
+	h.mu.RUnlock()
+	s := Status{
+		Name:       h.name,
+		Started:    started,
+		Healthy:    true,
+		Timeout:    h.timeout.String(),
+		QueueDepth: h.queueDepth(),
+	}
+	if started {
+		since := h.clock.Since(last)
+		s.LastHeartbeat = last
+		s.SinceLastHeartbeat = since.String()
+		s.Healthy = since <= h.timeout
+	}
+	return s
+}
+
+// ReadinessCheck fails when the loop hasn't beaten within the timeout
+func (h *Heartbeat) ReadinessCheck(_ *http.Request) error {
+	return h.check(h.timeout)
+}
+
+// LivenessCheck fails when the loop hasn't beaten within a multiple of the timeout
+func (h *Heartbeat) LivenessCheck(_ *http.Request) error {
+	return h.check(h.timeout * livenessTimeoutMultiplier)
+}
+
+func (h *Heartbeat) check(timeout time.Duration) error {
+	h.mu.RLock()
+	started, last := h.started, h.last
+	h.mu.RUnlock()
+	if !started {
+		return nil
+	}
+	if since := h.clock.Since(last); since > timeout {
+		return fmt.Errorf("%s hasn't made progress in %s (timeout %s), queue depth %d", h.name, since, timeout, h.queueDepth())
+	}
+	return nil
+}
+
+// Handler serves the Status of each Heartbeat as JSON. It responds with a 503 if any Heartbeat is unhealthy.
+type Handler []*Heartbeat
+
+func (hs Handler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	statuses := make([]Status, 0, len(hs))
+	code := http.StatusOK
+	for _, h := range hs {
+		s := h.Status()
+		if !s.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+		statuses = append(statuses, s)
+	}
+	body, err := json.Marshal(statuses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// Register adds a readiness and liveness check named after each Heartbeat to the manager. The probe endpoints withhold
+// the reason that a check failed, so the detail for every Heartbeat is also served at /debug/health on the metrics server.
+func Register(mgr manager.Manager, heartbeats ...*Heartbeat) error {
+	for _, h := range heartbeats {
+		if err := mgr.AddReadyzCheck(h.name, h.ReadinessCheck); err != nil {
+			return fmt.Errorf("adding readiness check for %s, %w", h.name, err)
+		}
+		if err := mgr.AddHealthzCheck(h.name, h.LivenessCheck); err != nil {
+			return fmt.Errorf("adding liveness check for %s, %w", h.name, err)
+		}
+	}
+	return mgr.AddMetricsServerExtraHandler("/debug/health", Handler(heartbeats))
+}
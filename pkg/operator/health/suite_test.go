@@ -0,0 +1,120 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/operator/health"
+)
+
+var fakeClock *clock.FakeClock
+var queueDepth int
+var heartbeat *health.Heartbeat
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health")
+}
+
+var _ = BeforeEach(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	queueDepth = 0
+	heartbeat = health.NewHeartbeat(fakeClock, "provisioner", time.Minute, func() int { return queueDepth })
+})
+
+var _ = Describe("Heartbeat", func() {
+	It("should be healthy before the loop has started", func() {
+		fakeClock.Step(time.Hour)
+		Expect(heartbeat.ReadinessCheck(nil)).To(Succeed())
+		Expect(heartbeat.LivenessCheck(nil)).To(Succeed())
+		Expect(heartbeat.Status().Started).To(BeFalse())
+	})
+	It("should fail readiness when the loop hasn't beaten within the timeout", func() {
+		heartbeat.Start()
+		fakeClock.Step(30 * time.Second)
+		Expect(heartbeat.ReadinessCheck(nil)).To(Succeed())
+
+		queueDepth = 12
+		fakeClock.Step(time.Minute)
+		err := heartbeat.ReadinessCheck(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("provisioner"))
+		Expect(err.Error()).To(ContainSubstring("queue depth 12"))
+		Expect(heartbeat.LivenessCheck(nil)).To(Succeed())
+	})
+	It("should fail liveness when the loop hasn't beaten within a multiple of the timeout", func() {
+		heartbeat.Start()
+		fakeClock.Step(2 * time.Minute)
+		Expect(heartbeat.LivenessCheck(nil)).To(Succeed())
+		fakeClock.Step(2 * time.Minute)
+		Expect(heartbeat.LivenessCheck(nil)).ToNot(Succeed())
+	})
+	It("should recover once the loop beats", func() {
+		heartbeat.Start()
+		fakeClock.Step(5 * time.Minute)
+		Expect(heartbeat.ReadinessCheck(nil)).ToNot(Succeed())
+		heartbeat.Beat()
+		Expect(heartbeat.ReadinessCheck(nil)).To(Succeed())
+		Expect(heartbeat.LivenessCheck(nil)).To(Succeed())
+	})
+	It("should not reset the heartbeat when started again", func() {
+		heartbeat.Start()
+		fakeClock.Step(5 * time.Minute)
+		heartbeat.Start()
+		Expect(heartbeat.ReadinessCheck(nil)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("Handler", func() {
+	It("should serve the status of each heartbeat", func() {
+		disruption := health.NewHeartbeat(fakeClock, "disruption", 10*time.Minute, func() int { return 1 })
+		heartbeat.Start()
+		disruption.Start()
+		queueDepth = 3
+		fakeClock.Step(2 * time.Minute)
+
+		recorder := httptest.NewRecorder()
+		health.Handler{heartbeat, disruption}.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/health", nil))
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+
+		var statuses []health.Status
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &statuses)).To(Succeed())
+		Expect(statuses).To(HaveLen(2))
+		Expect(statuses[0].Name).To(Equal("provisioner"))
+		Expect(statuses[0].Healthy).To(BeFalse())
+		Expect(statuses[0].QueueDepth).To(Equal(3))
+		Expect(statuses[0].SinceLastHeartbeat).To(Equal("2m0s"))
+		Expect(statuses[1].Name).To(Equal("disruption"))
+		Expect(statuses[1].Healthy).To(BeTrue())
+		Expect(statuses[1].QueueDepth).To(Equal(1))
+	})
+	It("should respond with OK when every heartbeat is healthy", func() {
+		heartbeat.Start()
+		recorder := httptest.NewRecorder()
+		health.Handler{heartbeat}.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/health", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+})
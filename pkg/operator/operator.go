@@ -38,6 +38,8 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
@@ -51,6 +53,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/events"
@@ -58,6 +61,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	"sigs.k8s.io/karpenter/pkg/operator/logging"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/operator/workloadcluster"
 	"sigs.k8s.io/karpenter/pkg/utils/env"
 )
 
@@ -129,6 +133,17 @@ func NewOperator() (context.Context, *Operator) {
 	// Client
 	kubernetesInterface := kubernetes.NewForConfigOrDie(config)
 
+	// Workload Client Config
+	// In hosted control-plane topologies, the Pods and Nodes live in a different cluster than the one that Karpenter and its CRDs run in
+	var workloadConfig *rest.Config
+	if path := options.FromContext(ctx).WorkloadKubeconfig; path != "" {
+		cfg, err := clientcmd.BuildConfigFromFlags("", path)
+		workloadConfig = lo.Must(cfg, err, "failed to load workload kubeconfig")
+		workloadConfig.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(float32(options.FromContext(ctx).KubeClientQPS), options.FromContext(ctx).KubeClientBurst)
+		workloadConfig.UserAgent = config.UserAgent
+		kubernetesInterface = kubernetes.NewForConfigOrDie(workloadConfig)
+	}
+
 	log.FromContext(ctx).WithValues("version", Version).V(1).Info("discovered karpenter version")
 
 	// Manager
@@ -143,6 +158,10 @@ func NewOperator() (context.Context, *Operator) {
 			BindAddress: fmt.Sprintf(":%d", options.FromContext(ctx).MetricsPort),
 		},
 		HealthProbeBindAddress: fmt.Sprintf(":%d", options.FromContext(ctx).HealthProbePort),
+		// The webhook server is only started once a webhook is registered with it
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: options.FromContext(ctx).WebhookPort,
+		}),
 		BaseContext: func() context.Context {
 			ctx := log.IntoContext(context.Background(), logger)
 			ctx = injection.WithOptionsOrDie(ctx, options.Injectables...)
@@ -156,6 +175,18 @@ func NewOperator() (context.Context, *Operator) {
 			},
 		},
 	}
+	// Only cache the change freeze ConfigMap rather than every ConfigMap in the cluster
+	if options.FromContext(ctx).ChangeFreezeConfigMap != "" {
+		key := options.FromContext(ctx).ChangeFreezeConfigMapKey()
+		mgrOpts.Cache.ByObject[&corev1.ConfigMap{}] = cache.ByObject{
+			Namespaces: map[string]cache.Config{key.Namespace: {}},
+			Field:      fields.SelectorFromSet(fields.Set{"metadata.name": key.Name}),
+		}
+	}
+	if workloadConfig != nil {
+		mgrOpts.NewCache = workloadcluster.NewCacheFunc(workloadConfig)
+		mgrOpts.NewClient = workloadcluster.NewClientFunc(workloadConfig)
+	}
 	if options.FromContext(ctx).EnableProfiling {
 		// TODO @joinnis: Investigate the mgrOpts.PprofBindAddress that would allow native support for pprof
 		// On initial look, it seems like this native pprof doesn't support some of the routes that we have here
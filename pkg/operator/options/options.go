@@ -18,20 +18,50 @@ package options
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
 	"time"
 
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"sigs.k8s.io/karpenter/pkg/utils/env"
 )
 
+const (
+	EvictionAPIVersionAuto    = "Auto"
+	EvictionAPIVersionV1      = "policy/v1"
+	EvictionAPIVersionV1Beta1 = "policy/v1beta1"
+)
+
+const (
+	DrainOrderingDefault       = "Default"
+	DrainOrderingPriorityClass = "PriorityClass"
+	DrainOrderingPDBTightness  = "PDBTightness"
+	DrainOrderingOwnerKind     = "OwnerKind"
+	DrainOrderingAnnotation    = "Annotation"
+)
+
 var (
-	validLogLevels = []string{"", "debug", "info", "error"}
+	validLogLevels               = []string{"", "debug", "info", "error"}
+	validPodAdvisoryWebhookModes = []string{"Disabled", "Warn", "Deny"}
+	validEvictionAPIVersions     = []string{EvictionAPIVersionAuto, EvictionAPIVersionV1, EvictionAPIVersionV1Beta1}
+	validDrainOrderings          = []string{DrainOrderingDefault, DrainOrderingPriorityClass, DrainOrderingPDBTightness, DrainOrderingOwnerKind, DrainOrderingAnnotation}
+	validComplianceOperators     = []corev1.NodeSelectorOperator{corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn, corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist}
 
 	Injectables = []Injectable{&Options{}}
 )
@@ -43,26 +73,132 @@ type FeatureGates struct {
 
 	SpotToSpotConsolidation bool
 	NodeRepair              bool
+	ZonalRebalance          bool
+	PodBinding              bool
+	PartialDrain            bool
+	NodeAdoption            bool
+}
+
+// ControllerConcurrency overrides the maximum number of concurrent reconciles of controllers, keyed by controller name
+type ControllerConcurrency struct {
+	inputStr string
+
+	MaxConcurrentReconciles map[string]int
+}
+
+// For returns the maximum number of concurrent reconciles for the named controller, falling back to the passed
+// default when it isn't overridden
+func (c ControllerConcurrency) For(controller string, defaultValue int) int {
+	if val, ok := c.MaxConcurrentReconciles[controller]; ok {
+		return val
+	}
+	return defaultValue
+}
+
+// ComplianceRequirements are node selector requirements that every NodeClaim must satisfy regardless of the
+// requirements of its NodePool, e.g. to disallow spot capacity or instance families in regulated environments
+type ComplianceRequirements struct {
+	inputStr string
+
+	Requirements []corev1.NodeSelectorRequirement
+}
+
+// NodeProblems are the node condition types and taint keys, e.g. reported by node-problem-detector, that cause a node
+// to be replaced
+type NodeProblems struct {
+	conditionsStr string
+	taintsStr     string
+
+	ConditionTypes []string
+	TaintKeys      []string
+}
+
+// DrainLastNamespaces are the namespaces whose pods are drained after all other non-critical pods when draining nodes
+type DrainLastNamespaces struct {
+	inputStr string
+
+	Namespaces sets.Set[string]
+}
+
+// DaemonOverhead are the pods from the operator's daemon overhead manifests, which run on every node in addition to the
+// cluster's DaemonSets, and the tolerations that the cluster adds to every pod by default, e.g. through the
+// PodTolerationRestriction admission plugin
+type DaemonOverhead struct {
+	manifestsPath  string
+	tolerationsStr string
+
+	Pods               []*corev1.Pod
+	DefaultTolerations []corev1.Toleration
 }
 
 // Options contains all CLI flags / env vars for karpenter-core. It adheres to the options.Injectable interface.
 type Options struct {
-	ServiceName             string
-	MetricsPort             int
-	HealthProbePort         int
-	KubeClientQPS           int
-	KubeClientBurst         int
-	EnableProfiling         bool
-	DisableLeaderElection   bool
-	LeaderElectionName      string
-	LeaderElectionNamespace string
-	MemoryLimit             int64
-	LogLevel                string
-	LogOutputPaths          string
-	LogErrorOutputPaths     string
-	BatchMaxDuration        time.Duration
-	BatchIdleDuration       time.Duration
-	FeatureGates            FeatureGates
+	ServiceName                       string
+	MetricsPort                       int
+	HealthProbePort                   int
+	KubeClientQPS                     int
+	KubeClientBurst                   int
+	EnableProfiling                   bool
+	DisableLeaderElection             bool
+	LeaderElectionName                string
+	LeaderElectionNamespace           string
+	MemoryLimit                       int64
+	LogLevel                          string
+	LogOutputPaths                    string
+	LogErrorOutputPaths               string
+	BatchMaxDuration                  time.Duration
+	BatchIdleDuration                 time.Duration
+	BatchBypassNamespaces             string
+	BatchBypassPodSelector            string
+	ProvisioningBackoffBase           time.Duration
+	ProvisioningBackoffMax            time.Duration
+	MaxNodes                          int
+	MaxCPU                            int64
+	MaxHourlyCost                     float64
+	ProvisioningParallelism           int
+	IgnoreTerminatingPodsAfter        time.Duration
+	DeterministicNodeClaimNames       bool
+	NodeClaimNameTemplate             string
+	NodeClaimLabelTemplates           string
+	NodeClaimTagTemplates             string
+	ShardName                         string
+	EnvironmentName                   string
+	UnmanagedNodeExpiration           bool
+	ControllerConcurrency             ControllerConcurrency
+	ControllerQPS                     int
+	ControllerBurst                   int
+	ReadOnlyStandby                   bool
+	SyncMinPercent                    int
+	SyncMaxStaleness                  time.Duration
+	NodeLaunchSLOTarget               time.Duration
+	NodeLaunchSLOObjective            int
+	LocalDataMoveThreshold            float64
+	NodeProblems                      NodeProblems
+	PodAdvisoryWebhookMode            string
+	WebhookPort                       int
+	HonorClusterAutoscalerAnnotations bool
+	WorkloadKubeconfig                string
+	NonProvisioningPriorityClasses    string
+	EvictionAPIVersion                string
+	DeleteEvictionNamespaces          string
+	DrainLastNamespaces               DrainLastNamespaces
+	DisruptionWebhookURLs             string
+	DisruptionWebhookSigningKey       string
+	DisruptionWebhookRequireAck       bool
+	ChangeFreezeConfigMap             string
+	MultiNodeConsolidationTimeout     time.Duration
+	SingleNodeConsolidationTimeout    time.Duration
+	InstanceTypesMaxAge               time.Duration
+	EvictionQueueWorkers              int
+	CanaryInterval                    time.Duration
+	CanaryTimeout                     time.Duration
+	CanaryNamespace                   string
+	DaemonOverhead                    DaemonOverhead
+	DrainOrdering                     string
+	MaxDeletionBlockTTL               time.Duration
+	NodePoolDrainTimeout              time.Duration
+	ComplianceRequirements            ComplianceRequirements
+	FeatureGates                      FeatureGates
 }
 
 type FlagSet struct {
@@ -98,7 +234,59 @@ func (o *Options) AddFlags(fs *FlagSet) {
 	fs.StringVar(&o.LogErrorOutputPaths, "log-error-output-paths", env.WithDefaultString("LOG_ERROR_OUTPUT_PATHS", "stderr"), "Optional comma separated paths for logging error output")
 	fs.DurationVar(&o.BatchMaxDuration, "batch-max-duration", env.WithDefaultDuration("BATCH_MAX_DURATION", 10*time.Second), "The maximum length of a batch window. The longer this is, the more pods we can consider for provisioning at one time which usually results in fewer but larger nodes.")
 	fs.DurationVar(&o.BatchIdleDuration, "batch-idle-duration", env.WithDefaultDuration("BATCH_IDLE_DURATION", time.Second), "The maximum amount of time with no new pending pods that if exceeded ends the current batching window. If pods arrive faster than this time, the batching window will be extended up to the maxDuration. If they arrive slower, the pods will be batched separately.")
-	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "NodeRepair=false,SpotToSpotConsolidation=false"), "Optional features can be enabled / disabled using feature gates. Current options are: SpotToSpotConsolidation")
+	fs.StringVar(&o.BatchBypassNamespaces, "batch-bypass-namespaces", env.WithDefaultString("BATCH_BYPASS_NAMESPACES", ""), "Optional comma separated list of namespaces whose pending pods bypass batching and immediately start a provisioning loop, e.g. for critical controllers. Combined with --batch-bypass-pod-selector when both are set.")
+	fs.StringVar(&o.BatchBypassPodSelector, "batch-bypass-pod-selector", env.WithDefaultString("BATCH_BYPASS_POD_SELECTOR", ""), "Optional label selector for pending pods that bypass batching and immediately start a provisioning loop, e.g. tier=critical. Combined with --batch-bypass-namespaces when both are set.")
+	fs.DurationVar(&o.ProvisioningBackoffBase, "provisioning-backoff-base", env.WithDefaultDuration("PROVISIONING_BACKOFF_BASE", time.Second), "The initial amount of time that the provisioner waits before retrying after a failed provisioning loop. The wait doubles, with jitter, for each consecutive failure.")
+	fs.DurationVar(&o.ProvisioningBackoffMax, "provisioning-backoff-max", env.WithDefaultDuration("PROVISIONING_BACKOFF_MAX", time.Minute), "The maximum amount of time that the provisioner waits before retrying after consecutive failed provisioning loops.")
+	fs.IntVar(&o.MaxNodes, "max-nodes", env.WithDefaultInt("MAX_NODES", -1), "The maximum number of nodes that may exist in the cluster across all NodePools. The provisioner won't launch nodes beyond this limit. A negative value disables the limit.")
+	fs.Int64Var(&o.MaxCPU, "max-cpu", env.WithDefaultInt64("MAX_CPU", -1), "The maximum number of vCPUs that may exist in the cluster across all NodePools. The provisioner won't launch nodes beyond this limit. A negative value disables the limit.")
+	fs.Float64Var(&o.MaxHourlyCost, "max-hourly-cost", env.WithDefaultFloat64("MAX_HOURLY_COST", -1), "The maximum estimated hourly cost of the nodes in the cluster across all NodePools, based on the offering prices reported by the cloud provider. The provisioner won't launch nodes beyond this limit. A negative value disables the limit.")
+	fs.IntVar(&o.ProvisioningParallelism, "provisioning-parallelism", env.WithDefaultInt("PROVISIONING_PARALLELISM", 1), "The maximum number of NodePool partitions that the provisioner schedules concurrently. NodePools are partitioned so that no pending pod is compatible with NodePools in different partitions. Values greater than one are ignored when cluster-wide limits are configured.")
+	fs.DurationVar(&o.IgnoreTerminatingPodsAfter, "ignore-terminating-pods-after", env.WithDefaultDuration("IGNORE_TERMINATING_PODS_AFTER", 0), "The duration after which the resource requests of terminating pods are no longer counted against their node when computing available capacity. A value of zero counts terminating pods until they are removed from the node.")
+	fs.BoolVarWithEnv(&o.DeterministicNodeClaimNames, "deterministic-nodeclaim-names", "DETERMINISTIC_NODECLAIM_NAMES", false, "If true, NodeClaims are named after their NodePool, the scheduling run that computed them and their index within that run rather than with a random suffix.")
+	fs.StringVar(&o.NodeClaimNameTemplate, "nodeclaim-name-template", env.WithDefaultString("NODECLAIM_NAME_TEMPLATE", ""), "Optional Go template for NodeClaim names, e.g. {{.NodePool}}-{{.Batch}}-{{.Index}}. The template is passed the NodePool, Shard, Environment, Batch and Index of the NodeClaim, and must reference both Batch and Index unless it ends with '-'. A name that ends with '-' is used as a prefix for a generated name. If unset, NodeClaims are named after their NodePool with a random suffix.")
+	fs.StringVar(&o.NodeClaimLabelTemplates, "nodeclaim-label-templates", env.WithDefaultString("NODECLAIM_LABEL_TEMPLATES", ""), "Optional comma separated label keys and Go templates, e.g. example.com/shard={{.Shard}}, that are rendered with the same fields as nodeclaim-name-template and added to NodeClaims and their nodes")
+	fs.StringVar(&o.NodeClaimTagTemplates, "nodeclaim-tag-templates", env.WithDefaultString("NODECLAIM_TAG_TEMPLATES", ""), "Optional comma separated tag keys and Go templates, e.g. environment={{.Environment}}, that are rendered with the same fields as nodeclaim-name-template and passed to the cloud provider through karpenter.sh/tag- annotations on NodeClaims")
+	fs.StringVar(&o.ShardName, "shard-name", env.WithDefaultString("SHARD_NAME", ""), "Optional name of the shard that this Karpenter deployment manages, exposed to the NodeClaim naming and tagging templates as Shard")
+	fs.StringVar(&o.EnvironmentName, "environment-name", env.WithDefaultString("ENVIRONMENT_NAME", ""), "Optional name of the environment that this Karpenter deployment runs in, exposed to the NodeClaim naming and tagging templates as Environment")
+	fs.BoolVarWithEnv(&o.UnmanagedNodeExpiration, "unmanaged-node-expiration", "UNMANAGED_NODE_EXPIRATION", false, "If true, nodes that aren't owned by Karpenter and that have the karpenter.sh/expire-after annotation are cordoned and drained once they expire. Deleting the drained node is left to its owner.")
+	fs.StringVar(&o.ControllerConcurrency.inputStr, "controller-concurrency", env.WithDefaultString("CONTROLLER_CONCURRENCY", ""), "Optional comma separated overrides of the maximum number of concurrent reconciles of controllers by name, e.g. node.termination=100,nodeclaim.lifecycle=1000")
+	fs.IntVar(&o.ControllerQPS, "controller-qps", env.WithDefaultInt("CONTROLLER_QPS", 10), "The smoothed rate of reconciles per second allowed by the rate limited controller workqueues, such as node termination and the nodeclaim lifecycle")
+	fs.IntVar(&o.ControllerBurst, "controller-burst", env.WithDefaultInt("CONTROLLER_BURST", 100), "The maximum allowed burst of reconciles allowed by the rate limited controller workqueues")
+	fs.BoolVarWithEnv(&o.ReadOnlyStandby, "read-only-standby", "READ_ONLY_STANDBY", false, "If true, replicas that aren't the leader run the controllers that only observe the cluster, such as the cluster state informers and the metrics controllers, so that metrics and the cluster state stay available and warm during leader failover.")
+	fs.IntVar(&o.SyncMinPercent, "sync-min-percent", env.WithDefaultInt("SYNC_MIN_PERCENT", 95), "The minimum percentage of NodeClaims and Nodes that must be tracked in cluster state for provisioning to proceed when sync-max-staleness is set")
+	fs.DurationVar(&o.SyncMaxStaleness, "sync-max-staleness", env.WithDefaultDuration("SYNC_MAX_STALENESS", 0), "If set, provisioning proceeds while cluster state isn't fully synced as long as sync-min-percent of NodeClaims and Nodes are tracked and none of the untracked ones are older than this duration. A value of zero requires cluster state to be fully synced.")
+	fs.DurationVar(&o.NodeLaunchSLOTarget, "node-launch-slo-target", env.WithDefaultDuration("NODE_LAUNCH_SLO_TARGET", 10*time.Minute), "The duration within which a NodeClaim is expected to initialize. This should be shorter than the registration TTL, after which NodeClaims that haven't registered are deleted. NodePools that launch too many NodeClaims which don't initialize within this duration are marked as LaunchDegraded. A value of zero disables the launch SLO.")
+	fs.IntVar(&o.NodeLaunchSLOObjective, "node-launch-slo-objective", env.WithDefaultInt("NODE_LAUNCH_SLO_OBJECTIVE", 95), "The percentage of a NodePool's NodeClaims that must initialize within node-launch-slo-target. The remaining percentage is the error budget for the NodePool.")
+	fs.Float64Var(&o.LocalDataMoveThreshold, "local-data-move-threshold", env.WithDefaultFloat64("LOCAL_DATA_MOVE_THRESHOLD", 0.01), "The hourly savings that multi-node consolidation must achieve for each GiB of local data it moves, scaled by the karpenter.sh/costly-to-move-weight annotation of the pods that own the data.")
+	fs.StringVar(&o.NodeProblems.conditionsStr, "node-problem-conditions", env.WithDefaultString("NODE_PROBLEM_CONDITIONS", ""), "Optional comma separated node condition types, e.g. KernelDeadlock,ReadonlyFilesystem as reported by node-problem-detector, that cause a node to be replaced when their status is True")
+	fs.StringVar(&o.NodeProblems.taintsStr, "node-problem-taints", env.WithDefaultString("NODE_PROBLEM_TAINTS", ""), "Comma separated taint keys, e.g. applied by node-problem-detector, that cause a node to be replaced")
+	fs.StringVar(&o.PodAdvisoryWebhookMode, "pod-advisory-webhook-mode", env.WithDefaultString("POD_ADVISORY_WEBHOOK_MODE", "Disabled"), "Mode of the pod advisory webhook, which checks pods at admission against the NodePools in the cluster. Can be one of 'Disabled', 'Warn' to return an admission warning for pods that no NodePool could ever schedule, or 'Deny' to reject them.")
+	fs.IntVar(&o.WebhookPort, "webhook-port", env.WithDefaultInt("WEBHOOK_PORT", 8443), "The port the webhook endpoint binds to for admission webhooks")
+	fs.BoolVarWithEnv(&o.HonorClusterAutoscalerAnnotations, "honor-cluster-autoscaler-annotations", "HONOR_CLUSTER_AUTOSCALER_ANNOTATIONS", false, "If true, disruption honors the cluster-autoscaler.kubernetes.io/safe-to-evict and safe-to-evict-local-volumes pod annotations the same way as the Cluster Autoscaler, so that pods which the Cluster Autoscaler wouldn't evict block voluntary disruption of their node.")
+	fs.StringVar(&o.WorkloadKubeconfig, "workload-kubeconfig", env.WithDefaultString("WORKLOAD_KUBECONFIG", ""), "Optional path to a kubeconfig for the workload cluster in hosted control-plane topologies. If set, Pods, Nodes and the resources that they're scheduled and drained against (e.g. PodDisruptionBudgets, DaemonSets and volumes) are read from and written to the workload cluster while NodePools, NodeClaims, NodeClasses and every other resource, such as Leases, Events and ConfigMaps, stay in the cluster that Karpenter runs in.")
+	fs.StringVar(&o.NonProvisioningPriorityClasses, "non-provisioning-priority-classes", env.WithDefaultString("NON_PROVISIONING_PRIORITY_CLASSES", ""), "Comma separated PriorityClass names whose pods never trigger provisioning. These pods can still schedule to existing capacity but are ignored when simulating disruption, which lets best-effort workloads soak up spare capacity without causing scale-up.")
+	fs.StringVar(&o.EvictionAPIVersion, "eviction-api-version", env.WithDefaultString("EVICTION_API_VERSION", "Auto"), "The Eviction API version used when draining nodes. One of 'Auto', 'policy/v1' or 'policy/v1beta1'. 'Auto' uses policy/v1 unless discovery shows that the cluster only serves policy/v1beta1, which is the case for clusters older than 1.22.")
+	fs.StringVar(&o.DeleteEvictionNamespaces, "delete-eviction-namespaces", env.WithDefaultString("DELETE_EVICTION_NAMESPACES", ""), "Comma separated namespaces that opt out of PDB enforcement when draining nodes. Pods in these namespaces are deleted with their termination grace period instead of being evicted through the Eviction API.")
+	fs.StringVar(&o.DrainLastNamespaces.inputStr, "drain-last-namespaces", env.WithDefaultString("DRAIN_LAST_NAMESPACES", ""), "Comma separated namespaces whose pods are drained after all other non-critical pods when draining nodes, e.g. for observability agents that should keep running while the node drains.")
+	fs.StringVar(&o.DisruptionWebhookURLs, "disruption-webhook-urls", env.WithDefaultString("DISRUPTION_WEBHOOK_URLS", ""), "Comma separated URLs that are sent a JSON description of each disruption before it's performed, e.g. to integrate with change management tooling.")
+	fs.StringVar(&o.DisruptionWebhookSigningKey, "disruption-webhook-signing-key", env.WithDefaultString("DISRUPTION_WEBHOOK_SIGNING_KEY", ""), "The key used to sign disruption webhook requests with HMAC-SHA256. The signature is sent in the X-Karpenter-Signature header. Requests aren't signed if the key is empty.")
+	fs.BoolVarWithEnv(&o.DisruptionWebhookRequireAck, "disruption-webhook-require-ack", "DISRUPTION_WEBHOOK_REQUIRE_ACK", false, "If true, disruptions are only performed once every disruption webhook responds with a 2xx status code. Otherwise, webhooks are notified in the background, failures are logged and the disruption proceeds.")
+	fs.StringVar(&o.ChangeFreezeConfigMap, "change-freeze-configmap", env.WithDefaultString("CHANGE_FREEZE_CONFIGMAP", ""), "The namespace/name of a ConfigMap that pauses all voluntary disruption, i.e. consolidation, drift and expiration, while its freeze key is set to true. Provisioning and involuntary disruption are unaffected.")
+	fs.DurationVar(&o.MultiNodeConsolidationTimeout, "multi-node-consolidation-timeout", env.WithDefaultDuration("MULTI_NODE_CONSOLIDATION_TIMEOUT", time.Minute), "The time budget for a multi-node consolidation pass. If the budget runs out before a multi-node consolidation is found, consolidation falls back to replacing or deleting the first candidate on its own.")
+	fs.DurationVar(&o.SingleNodeConsolidationTimeout, "single-node-consolidation-timeout", env.WithDefaultDuration("SINGLE_NODE_CONSOLIDATION_TIMEOUT", 3*time.Minute), "The time budget for a single-node consolidation pass. Candidates that haven't been evaluated when the budget runs out are evaluated in a later pass.")
+	fs.DurationVar(&o.InstanceTypesMaxAge, "instance-types-max-age", env.WithDefaultDuration("INSTANCE_TYPES_MAX_AGE", 5*time.Minute), "The maximum age of the instance types, pricing and offerings that a scheduling simulation uses. Pods that haven't been scheduled when the instance types exceed this age are deferred to a new batch that fetches fresh instance types. A value of zero disables the check.")
+	fs.IntVar(&o.EvictionQueueWorkers, "eviction-queue-workers", env.WithDefaultInt("EVICTION_QUEUE_WORKERS", 10), "The number of workers that concurrently evict pods from the eviction queue when draining nodes.")
+	fs.DurationVar(&o.CanaryInterval, "canary-interval", env.WithDefaultDuration("CANARY_INTERVAL", 0), "The interval at which a canary pod is created for each NodePool to verify that the NodePool can provision capacity. A value of zero disables the canaries.")
+	fs.DurationVar(&o.CanaryTimeout, "canary-timeout", env.WithDefaultDuration("CANARY_TIMEOUT", 15*time.Minute), "The duration within which a NodePool's canary pod is expected to be running. NodePools whose canary pod isn't running within this duration are marked as CanaryFailed.")
+	fs.StringVar(&o.CanaryNamespace, "canary-namespace", env.WithDefaultString("CANARY_NAMESPACE", "default"), "The namespace that the NodePool canary pods are created in.")
+	fs.StringVar(&o.DaemonOverhead.manifestsPath, "daemon-overhead-manifests", env.WithDefaultString("DAEMON_OVERHEAD_MANIFESTS", ""), "Optional path to a file of YAML pod manifests, separated by '---', for pods that run on every node in addition to the cluster's DaemonSets, e.g. pods that external systems inject after a node joins. Their requests are reserved on the nodes that Karpenter launches in the same way as a DaemonSet's.")
+	fs.StringVar(&o.DaemonOverhead.tolerationsStr, "daemon-default-tolerations", env.WithDefaultString("DAEMON_DEFAULT_TOLERATIONS", ""), "Optional JSON list of tolerations that the cluster adds to every pod by default, e.g. [{\"key\":\"example.com/dedicated\",\"operator\":\"Exists\"}] when it's configured as a cluster-wide default of the PodTolerationRestriction admission plugin. They're added to the DaemonSet and daemon overhead manifest pods when computing which nodes the daemons schedule to.")
+	fs.StringVar(&o.DrainOrdering, "drain-ordering", env.WithDefaultString("DRAIN_ORDERING", DrainOrderingDefault), "The order that the non-critical pods on a node are drained in, ahead of the daemon and critical pods. One of Default, PriorityClass (higher priority pods first), PDBTightness (pods whose PodDisruptionBudgets allow the most disruptions first), OwnerKind (Job pods last) or Annotation (ascending by the karpenter.sh/drain-order annotation).")
+	fs.DurationVar(&o.MaxDeletionBlockTTL, "max-deletion-block-ttl", env.WithDefaultDuration("MAX_DELETION_BLOCK_TTL", time.Hour), "The maximum amount of time that deletion blocks registered on a node with the deletion-block.karpenter.sh/ annotation prefix can delay its termination, measured from when the node started deleting.")
+	fs.DurationVar(&o.NodePoolDrainTimeout, "nodepool-drain-timeout", env.WithDefaultDuration("NODEPOOL_DRAIN_TIMEOUT", 0), "The maximum amount of time that a deleted NodePool drains its NodeClaims within its disruption budgets before it's removed and its remaining NodeClaims are deleted at once. A value of zero disables draining, so a deleted NodePool's NodeClaims are all deleted at once.")
+	fs.StringVar(&o.ComplianceRequirements.inputStr, "compliance-requirements", env.WithDefaultString("COMPLIANCE_REQUIREMENTS", ""), "A JSON list of node selector requirements that every NodeClaim must satisfy regardless of its NodePool, e.g. [{\"key\":\"karpenter.sh/capacity-type\",\"operator\":\"NotIn\",\"values\":[\"spot\"]}]. The scheduler only launches capacity that satisfies them.")
+	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "NodeRepair=false,SpotToSpotConsolidation=false,ZonalRebalance=false,PodBinding=false,PartialDrain=false,NodeAdoption=false"), "Optional features can be enabled / disabled using feature gates. Current options are: NodeRepair, SpotToSpotConsolidation, ZonalRebalance, PodBinding, PartialDrain and NodeAdoption")
 }
 
 func (o *Options) Parse(fs *FlagSet, args ...string) error {
@@ -112,6 +300,78 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	if !lo.Contains(validLogLevels, o.LogLevel) {
 		return fmt.Errorf("validating cli flags / env vars, invalid LOG_LEVEL %q", o.LogLevel)
 	}
+	if !lo.Contains(validPodAdvisoryWebhookModes, o.PodAdvisoryWebhookMode) {
+		return fmt.Errorf("validating cli flags / env vars, invalid POD_ADVISORY_WEBHOOK_MODE %q", o.PodAdvisoryWebhookMode)
+	}
+	if !lo.Contains(validEvictionAPIVersions, o.EvictionAPIVersion) {
+		return fmt.Errorf("validating cli flags / env vars, invalid EVICTION_API_VERSION %q", o.EvictionAPIVersion)
+	}
+	if !lo.Contains(validDrainOrderings, o.DrainOrdering) {
+		return fmt.Errorf("validating cli flags / env vars, invalid DRAIN_ORDERING %q", o.DrainOrdering)
+	}
+	if o.SyncMinPercent < 0 || o.SyncMinPercent > 100 {
+		return fmt.Errorf("validating cli flags / env vars, invalid SYNC_MIN_PERCENT %d, must be between 0 and 100", o.SyncMinPercent)
+	}
+	if o.NodeLaunchSLOObjective <= 0 || o.NodeLaunchSLOObjective >= 100 {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODE_LAUNCH_SLO_OBJECTIVE %d, must be between 0 and 100 exclusive", o.NodeLaunchSLOObjective)
+	}
+	if o.ProvisioningParallelism < 1 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PROVISIONING_PARALLELISM %d, must be at least 1", o.ProvisioningParallelism)
+	}
+	if o.ProvisioningBackoffBase <= 0 || o.ProvisioningBackoffMax < o.ProvisioningBackoffBase {
+		return fmt.Errorf("validating cli flags / env vars, invalid PROVISIONING_BACKOFF_BASE %s and PROVISIONING_BACKOFF_MAX %s, base must be positive and no greater than max", o.ProvisioningBackoffBase, o.ProvisioningBackoffMax)
+	}
+	if o.MultiNodeConsolidationTimeout <= 0 || o.SingleNodeConsolidationTimeout <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid MULTI_NODE_CONSOLIDATION_TIMEOUT %s and SINGLE_NODE_CONSOLIDATION_TIMEOUT %s, must be positive", o.MultiNodeConsolidationTimeout, o.SingleNodeConsolidationTimeout)
+	}
+	if o.InstanceTypesMaxAge < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid INSTANCE_TYPES_MAX_AGE %s, must be non-negative", o.InstanceTypesMaxAge)
+	}
+	if o.EvictionQueueWorkers <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid EVICTION_QUEUE_WORKERS %d, must be positive", o.EvictionQueueWorkers)
+	}
+	if o.ControllerQPS <= 0 || o.ControllerBurst <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid CONTROLLER_QPS %d and CONTROLLER_BURST %d, must be positive", o.ControllerQPS, o.ControllerBurst)
+	}
+	if o.NodePoolDrainTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODEPOOL_DRAIN_TIMEOUT %s, must be non-negative", o.NodePoolDrainTimeout)
+	}
+	if o.CanaryInterval < 0 || o.CanaryTimeout <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid CANARY_INTERVAL %s and CANARY_TIMEOUT %s, interval must be non-negative and timeout must be positive", o.CanaryInterval, o.CanaryTimeout)
+	}
+	if o.ChangeFreezeConfigMap != "" && len(lo.Compact(strings.Split(o.ChangeFreezeConfigMap, "/"))) != 2 {
+		return fmt.Errorf("validating cli flags / env vars, invalid CHANGE_FREEZE_CONFIGMAP %q, must be namespace/name", o.ChangeFreezeConfigMap)
+	}
+	if _, err := ParseNamespaces(o.DeleteEvictionNamespaces); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid DELETE_EVICTION_NAMESPACES %q, %w", o.DeleteEvictionNamespaces, err)
+	}
+	drainLastNamespaces, err := ParseNamespaces(o.DrainLastNamespaces.inputStr)
+	if err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid DRAIN_LAST_NAMESPACES %q, %w", o.DrainLastNamespaces.inputStr, err)
+	}
+	o.DrainLastNamespaces = DrainLastNamespaces{inputStr: o.DrainLastNamespaces.inputStr, Namespaces: drainLastNamespaces}
+	if _, err := labels.Parse(o.BatchBypassPodSelector); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid BATCH_BYPASS_POD_SELECTOR %q, %w", o.BatchBypassPodSelector, err)
+	}
+	if err := validateNodeClaimTemplates(o); err != nil {
+		return fmt.Errorf("validating cli flags / env vars, %w", err)
+	}
+	concurrency, err := ParseControllerConcurrency(o.ControllerConcurrency.inputStr)
+	if err != nil {
+		return fmt.Errorf("parsing controller concurrency, %w", err)
+	}
+	o.ControllerConcurrency = concurrency
+	compliance, err := ParseComplianceRequirements(o.ComplianceRequirements.inputStr)
+	if err != nil {
+		return fmt.Errorf("parsing compliance requirements, %w", err)
+	}
+	o.ComplianceRequirements = compliance
+	daemonOverhead, err := ParseDaemonOverhead(o.DaemonOverhead.manifestsPath, o.DaemonOverhead.tolerationsStr)
+	if err != nil {
+		return fmt.Errorf("parsing daemon overhead, %w", err)
+	}
+	o.DaemonOverhead = daemonOverhead
+	o.NodeProblems = ParseNodeProblems(o.NodeProblems.conditionsStr, o.NodeProblems.taintsStr)
 	gates, err := ParseFeatureGates(o.FeatureGates.inputStr)
 	if err != nil {
 		return fmt.Errorf("parsing feature gates, %w", err)
@@ -124,6 +384,180 @@ func (o *Options) ToContext(ctx context.Context) context.Context {
 	return ToContext(ctx, o)
 }
 
+// ChangeFreezeConfigMapKey returns the namespace and name of the change freeze ConfigMap, so that the manager's cache
+// and the change freeze controller only ever select the same ConfigMap
+func (o *Options) ChangeFreezeConfigMapKey() types.NamespacedName {
+	namespace, name, _ := strings.Cut(o.ChangeFreezeConfigMap, "/")
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
+
+func ParseControllerConcurrency(concurrencyStr string) (ControllerConcurrency, error) {
+	concurrencyMap := map[string]string{}
+	concurrency := ControllerConcurrency{MaxConcurrentReconciles: map[string]int{}}
+
+	if err := cliflag.NewMapStringString(&concurrencyMap).Set(concurrencyStr); err != nil {
+		return concurrency, err
+	}
+	for controller, val := range concurrencyMap {
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return concurrency, fmt.Errorf("invalid concurrency %q for controller %q, must be a positive integer", val, controller)
+		}
+		concurrency.MaxConcurrentReconciles[controller] = n
+	}
+	return concurrency, nil
+}
+
+func ParseComplianceRequirements(requirementsStr string) (ComplianceRequirements, error) {
+	compliance := ComplianceRequirements{inputStr: requirementsStr}
+	if requirementsStr == "" {
+		return compliance, nil
+	}
+	if err := json.Unmarshal([]byte(requirementsStr), &compliance.Requirements); err != nil {
+		return compliance, err
+	}
+	for _, requirement := range compliance.Requirements {
+		if requirement.Key == "" {
+			return compliance, fmt.Errorf("invalid requirement %v, key must be set", requirement)
+		}
+		if !lo.Contains(validComplianceOperators, requirement.Operator) {
+			return compliance, fmt.Errorf("invalid operator %q for key %q, must be one of %v", requirement.Operator, requirement.Key, validComplianceOperators)
+		}
+		if requirement.Operator == corev1.NodeSelectorOpIn && len(requirement.Values) == 0 {
+			return compliance, fmt.Errorf("invalid requirement for key %q, operator In must have a value defined", requirement.Key)
+		}
+	}
+	return compliance, nil
+}
+
+// ParseDaemonOverhead reads the pods from the daemon overhead manifests at the passed path, which are YAML pod manifests
+// separated by '---', and parses the default tolerations from a JSON list
+func ParseDaemonOverhead(manifestsPath, tolerationsStr string) (DaemonOverhead, error) {
+	overhead := DaemonOverhead{manifestsPath: manifestsPath, tolerationsStr: tolerationsStr}
+	if tolerationsStr != "" {
+		if err := json.Unmarshal([]byte(tolerationsStr), &overhead.DefaultTolerations); err != nil {
+			return overhead, fmt.Errorf("invalid default tolerations, %w", err)
+		}
+	}
+	if manifestsPath == "" {
+		return overhead, nil
+	}
+	f, err := os.Open(manifestsPath)
+	if err != nil {
+		return overhead, fmt.Errorf("opening manifests, %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		pod := &corev1.Pod{}
+		if err := decoder.Decode(pod); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return overhead, fmt.Errorf("decoding manifests, %w", err)
+		}
+		// Skip empty documents, e.g. from a leading or trailing separator
+		if len(pod.Spec.Containers) == 0 {
+			continue
+		}
+		// Name the pod so that resources tracked per-pod, like host ports, don't collide
+		if pod.Name == "" {
+			pod.Name = fmt.Sprintf("daemon-overhead-%d", len(overhead.Pods))
+		}
+		overhead.Pods = append(overhead.Pods, pod)
+	}
+	return overhead, nil
+}
+
+func ParseNodeProblems(conditionsStr, taintsStr string) NodeProblems {
+	return NodeProblems{
+		conditionsStr:  conditionsStr,
+		taintsStr:      taintsStr,
+		ConditionTypes: splitList(conditionsStr),
+		TaintKeys:      splitList(taintsStr),
+	}
+}
+
+// ParseNamespaces parses a comma separated list of namespaces, returning an error if any of them isn't a valid namespace
+// name
+func ParseNamespaces(namespacesStr string) (sets.Set[string], error) {
+	namespaces := sets.New(splitList(namespacesStr)...)
+	for _, namespace := range sets.List(namespaces) {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) != 0 {
+			return namespaces, fmt.Errorf("invalid namespace %q, %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+	return namespaces, nil
+}
+
+// splitList splits a comma separated list, ignoring whitespace and empty values
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// validateNodeClaimTemplates checks that the NodeClaim naming and tagging templates parse, and that the name template
+// renders a unique name for every NodeClaim
+func validateNodeClaimTemplates(o *Options) error {
+	t, err := template.New("name").Parse(o.NodeClaimNameTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid NODECLAIM_NAME_TEMPLATE, %w", err)
+	}
+	if o.NodeClaimNameTemplate != "" && !strings.HasSuffix(o.NodeClaimNameTemplate, "-") {
+		fields := map[string]bool{}
+		referencedFields(t.Tree.Root, fields)
+		if !fields["Batch"] || !fields["Index"] {
+			return fmt.Errorf("invalid NODECLAIM_NAME_TEMPLATE %q, must reference both {{.Batch}} and {{.Index}} or end with '-' so that NodeClaim names are unique", o.NodeClaimNameTemplate)
+		}
+	}
+	for env, str := range map[string]string{"NODECLAIM_LABEL_TEMPLATES": o.NodeClaimLabelTemplates, "NODECLAIM_TAG_TEMPLATES": o.NodeClaimTagTemplates} {
+		templateMap := map[string]string{}
+		if err := cliflag.NewMapStringString(&templateMap).Set(str); err != nil {
+			return fmt.Errorf("invalid %s, %w", env, err)
+		}
+		for key, text := range templateMap {
+			if _, err := template.New(key).Parse(text); err != nil {
+				return fmt.Errorf("invalid %s, %w", env, err)
+			}
+		}
+	}
+	return nil
+}
+
+// referencedFields records the names of the fields that are rendered by the template node
+func referencedFields(node parse.Node, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			referencedFields(child, fields)
+		}
+	case *parse.ActionNode:
+		for _, cmd := range n.Pipe.Cmds {
+			for _, arg := range cmd.Args {
+				referencedFields(arg, fields)
+			}
+		}
+	case *parse.FieldNode:
+		fields[n.Ident[0]] = true
+	case *parse.IfNode:
+		referencedFields(n.List, fields)
+		if n.ElseList != nil {
+			referencedFields(n.ElseList, fields)
+		}
+	case *parse.WithNode:
+		referencedFields(n.List, fields)
+		if n.ElseList != nil {
+			referencedFields(n.ElseList, fields)
+		}
+	}
+}
+
 func ParseFeatureGates(gateStr string) (FeatureGates, error) {
 	gateMap := map[string]bool{}
 	gates := FeatureGates{}
@@ -139,6 +573,18 @@ func ParseFeatureGates(gateStr string) (FeatureGates, error) {
 	if val, ok := gateMap["SpotToSpotConsolidation"]; ok {
 		gates.SpotToSpotConsolidation = val
 	}
+	if val, ok := gateMap["ZonalRebalance"]; ok {
+		gates.ZonalRebalance = val
+	}
+	if val, ok := gateMap["PodBinding"]; ok {
+		gates.PodBinding = val
+	}
+	if val, ok := gateMap["PartialDrain"]; ok {
+		gates.PartialDrain = val
+	}
+	if val, ok := gateMap["NodeAdoption"]; ok {
+		gates.NodeAdoption = val
+	}
 
 	return gates, nil
 }
@@ -20,12 +20,16 @@ import (
 	"context"
 	"flag"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -58,15 +62,85 @@ var _ = Describe("Options", func() {
 		"LOG_ERROR_OUTPUT_PATHS",
 		"BATCH_MAX_DURATION",
 		"BATCH_IDLE_DURATION",
+		"BATCH_BYPASS_NAMESPACES",
+		"BATCH_BYPASS_POD_SELECTOR",
+		"PROVISIONING_BACKOFF_BASE",
+		"PROVISIONING_BACKOFF_MAX",
+		"MAX_NODES",
+		"MAX_CPU",
+		"MAX_HOURLY_COST",
+		"PROVISIONING_PARALLELISM",
+		"IGNORE_TERMINATING_PODS_AFTER",
+		"DETERMINISTIC_NODECLAIM_NAMES",
+		"NODECLAIM_NAME_TEMPLATE",
+		"NODECLAIM_LABEL_TEMPLATES",
+		"NODECLAIM_TAG_TEMPLATES",
+		"SHARD_NAME",
+		"ENVIRONMENT_NAME",
+		"UNMANAGED_NODE_EXPIRATION",
+		"CONTROLLER_CONCURRENCY",
+		"CONTROLLER_QPS",
+		"CONTROLLER_BURST",
+		"READ_ONLY_STANDBY",
+		"SYNC_MIN_PERCENT",
+		"SYNC_MAX_STALENESS",
+		"NODE_LAUNCH_SLO_TARGET",
+		"NODE_LAUNCH_SLO_OBJECTIVE",
+		"LOCAL_DATA_MOVE_THRESHOLD",
+		"NODE_PROBLEM_CONDITIONS",
+		"NODE_PROBLEM_TAINTS",
+		"POD_ADVISORY_WEBHOOK_MODE",
+		"WEBHOOK_PORT",
+		"HONOR_CLUSTER_AUTOSCALER_ANNOTATIONS",
+		"WORKLOAD_KUBECONFIG",
+		"NON_PROVISIONING_PRIORITY_CLASSES",
+		"EVICTION_API_VERSION",
+		"DELETE_EVICTION_NAMESPACES",
+		"DRAIN_LAST_NAMESPACES",
+		"DISRUPTION_WEBHOOK_URLS",
+		"DISRUPTION_WEBHOOK_SIGNING_KEY",
+		"DISRUPTION_WEBHOOK_REQUIRE_ACK",
+		"CHANGE_FREEZE_CONFIGMAP",
+		"MULTI_NODE_CONSOLIDATION_TIMEOUT",
+		"SINGLE_NODE_CONSOLIDATION_TIMEOUT",
+		"INSTANCE_TYPES_MAX_AGE",
+		"EVICTION_QUEUE_WORKERS",
+		"CANARY_INTERVAL",
+		"CANARY_TIMEOUT",
+		"CANARY_NAMESPACE",
+		"DAEMON_OVERHEAD_MANIFESTS",
+		"DAEMON_DEFAULT_TOLERATIONS",
+		"DRAIN_ORDERING",
+		"MAX_DELETION_BLOCK_TTL",
+		"NODEPOOL_DRAIN_TIMEOUT",
+		"COMPLIANCE_REQUIREMENTS",
 		"FEATURE_GATES",
 	}
 
+	var overheadManifests string
+	var overheadPods []*corev1.Pod
+
 	BeforeEach(func() {
 		fs = &options.FlagSet{
 			FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 		}
 		opts = &options.Options{}
 		opts.AddFlags(fs)
+
+		overheadManifests = filepath.Join(GinkgoT().TempDir(), "overhead.yaml")
+		Expect(os.WriteFile(overheadManifests, []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: log-agent
+spec:
+  containers:
+  - name: agent
+`), 0600)).To(Succeed())
+		overheadPods = []*corev1.Pod{{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "log-agent"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent"}}},
+		}}
 	})
 
 	AfterEach(func() {
@@ -90,29 +164,182 @@ var _ = Describe("Options", func() {
 		)
 	})
 
+	Context("ControllerConcurrency", func() {
+		It("should fall back to the default for controllers that aren't overridden", func() {
+			concurrency, err := options.ParseControllerConcurrency("node.termination=50")
+			Expect(err).To(BeNil())
+			Expect(concurrency.For("node.termination", 100)).To(Equal(50))
+			Expect(concurrency.For("nodeclaim.lifecycle", 1000)).To(Equal(1000))
+		})
+		DescribeTable(
+			"should error with malformed controller concurrency strings",
+			func(str string) {
+				_, err := options.ParseControllerConcurrency(str)
+				Expect(err).ToNot(BeNil())
+			},
+			Entry("missing value", "node.termination"),
+			Entry("non-integer value", "node.termination=fast"),
+			Entry("zero value", "node.termination=0"),
+		)
+	})
+
+	Context("ComplianceRequirements", func() {
+		It("should parse node selector requirements", func() {
+			compliance, err := options.ParseComplianceRequirements(`[{"key":"karpenter.sh/capacity-type","operator":"In","values":["on-demand","reserved"]},{"key":"example.com/instance-family","operator":"NotIn","values":["t3"]}]`)
+			Expect(err).To(BeNil())
+			Expect(compliance.Requirements).To(Equal([]corev1.NodeSelectorRequirement{
+				{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpIn, Values: []string{"on-demand", "reserved"}},
+				{Key: "example.com/instance-family", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"t3"}},
+			}))
+		})
+		DescribeTable(
+			"should error with malformed compliance requirements",
+			func(str string) {
+				_, err := options.ParseComplianceRequirements(str)
+				Expect(err).ToNot(BeNil())
+			},
+			Entry("invalid json", "karpenter.sh/capacity-type=on-demand"),
+			Entry("missing key", `[{"operator":"Exists"}]`),
+			Entry("unsupported operator", `[{"key":"example.com/generation","operator":"Gt","values":["5"]}]`),
+			Entry("In without values", `[{"key":"karpenter.sh/capacity-type","operator":"In"}]`),
+		)
+	})
+
+	Context("DaemonOverhead", func() {
+		It("should return a pod for each manifest", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "overhead.yaml")
+			Expect(os.WriteFile(path, []byte(`---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: log-agent
+spec:
+  containers:
+  - name: agent
+    resources:
+      requests:
+        cpu: "1"
+---
+apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: proxy
+    resources:
+      requests:
+        memory: 128Mi
+`), 0600)).To(Succeed())
+			overhead, err := options.ParseDaemonOverhead(path, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overhead.Pods).To(HaveLen(2))
+			Expect(overhead.Pods[0].Name).To(Equal("log-agent"))
+			Expect(overhead.Pods[0].Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("1"))
+			Expect(overhead.Pods[1].Name).ToNot(BeEmpty())
+			Expect(overhead.Pods[1].Spec.Containers[0].Resources.Requests.Memory().String()).To(Equal("128Mi"))
+		})
+		It("should parse the default tolerations", func() {
+			overhead, err := options.ParseDaemonOverhead("", `[{"key":"example.com/dedicated","operator":"Exists","effect":"NoSchedule"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overhead.Pods).To(BeEmpty())
+			Expect(overhead.DefaultTolerations).To(Equal([]corev1.Toleration{{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}))
+		})
+		It("should error when the manifests can't be read", func() {
+			_, err := options.ParseDaemonOverhead(filepath.Join(GinkgoT().TempDir(), "missing.yaml"), "")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should error when the default tolerations aren't a JSON list", func() {
+			_, err := options.ParseDaemonOverhead("", "example.com/dedicated")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("NodeProblems", func() {
+		It("should parse the condition types and taint keys", func() {
+			problems := options.ParseNodeProblems(" KernelDeadlock, ReadonlyFilesystem,", "example.com/kernel-deadlock")
+			Expect(problems.ConditionTypes).To(Equal([]string{"KernelDeadlock", "ReadonlyFilesystem"}))
+			Expect(problems.TaintKeys).To(Equal([]string{"example.com/kernel-deadlock"}))
+		})
+		It("should default to no problems", func() {
+			problems := options.ParseNodeProblems("", "")
+			Expect(problems.ConditionTypes).To(BeEmpty())
+			Expect(problems.TaintKeys).To(BeEmpty())
+		})
+	})
+
 	Context("Parse", func() {
 		It("should use the correct default values", func() {
 			err := opts.Parse(fs)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:             lo.ToPtr(""),
-				MetricsPort:             lo.ToPtr(8080),
-				HealthProbePort:         lo.ToPtr(8081),
-				KubeClientQPS:           lo.ToPtr(200),
-				KubeClientBurst:         lo.ToPtr(300),
-				EnableProfiling:         lo.ToPtr(false),
-				DisableLeaderElection:   lo.ToPtr(false),
-				LeaderElectionName:      lo.ToPtr("karpenter-leader-election"),
-				LeaderElectionNamespace: lo.ToPtr(""),
-				MemoryLimit:             lo.ToPtr[int64](-1),
-				LogLevel:                lo.ToPtr("info"),
-				LogOutputPaths:          lo.ToPtr("stdout"),
-				LogErrorOutputPaths:     lo.ToPtr("stderr"),
-				BatchMaxDuration:        lo.ToPtr(10 * time.Second),
-				BatchIdleDuration:       lo.ToPtr(time.Second),
+				ServiceName:                       lo.ToPtr(""),
+				MetricsPort:                       lo.ToPtr(8080),
+				HealthProbePort:                   lo.ToPtr(8081),
+				KubeClientQPS:                     lo.ToPtr(200),
+				KubeClientBurst:                   lo.ToPtr(300),
+				EnableProfiling:                   lo.ToPtr(false),
+				DisableLeaderElection:             lo.ToPtr(false),
+				LeaderElectionName:                lo.ToPtr("karpenter-leader-election"),
+				LeaderElectionNamespace:           lo.ToPtr(""),
+				MemoryLimit:                       lo.ToPtr[int64](-1),
+				LogLevel:                          lo.ToPtr("info"),
+				LogOutputPaths:                    lo.ToPtr("stdout"),
+				LogErrorOutputPaths:               lo.ToPtr("stderr"),
+				BatchMaxDuration:                  lo.ToPtr(10 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(time.Second),
+				BatchBypassNamespaces:             lo.ToPtr(""),
+				BatchBypassPodSelector:            lo.ToPtr(""),
+				ProvisioningBackoffBase:           lo.ToPtr(time.Second),
+				ProvisioningBackoffMax:            lo.ToPtr(time.Minute),
+				MaxNodes:                          lo.ToPtr(-1),
+				MaxCPU:                            lo.ToPtr[int64](-1),
+				MaxHourlyCost:                     lo.ToPtr[float64](-1),
+				ProvisioningParallelism:           lo.ToPtr(1),
+				IgnoreTerminatingPodsAfter:        lo.ToPtr(time.Duration(0)),
+				DeterministicNodeClaimNames:       lo.ToPtr(false),
+				NodeClaimNameTemplate:             lo.ToPtr(""),
+				NodeClaimLabelTemplates:           lo.ToPtr(""),
+				NodeClaimTagTemplates:             lo.ToPtr(""),
+				ShardName:                         lo.ToPtr(""),
+				EnvironmentName:                   lo.ToPtr(""),
+				UnmanagedNodeExpiration:           lo.ToPtr(false),
+				ControllerConcurrency:             map[string]int{},
+				ControllerQPS:                     lo.ToPtr(10),
+				ControllerBurst:                   lo.ToPtr(100),
+				ReadOnlyStandby:                   lo.ToPtr(false),
+				SyncMinPercent:                    lo.ToPtr(95),
+				SyncMaxStaleness:                  lo.ToPtr(time.Duration(0)),
+				NodeLaunchSLOTarget:               lo.ToPtr(10 * time.Minute),
+				NodeLaunchSLOObjective:            lo.ToPtr(95),
+				LocalDataMoveThreshold:            lo.ToPtr(0.01),
+				NodeProblemConditions:             []string{},
+				NodeProblemTaints:                 []string{},
+				PodAdvisoryWebhookMode:            lo.ToPtr("Disabled"),
+				WebhookPort:                       lo.ToPtr(8443),
+				HonorClusterAutoscalerAnnotations: lo.ToPtr(false),
+				WorkloadKubeconfig:                lo.ToPtr(""),
+				NonProvisioningPriorityClasses:    lo.ToPtr(""),
+				EvictionAPIVersion:                lo.ToPtr("Auto"),
+				DeleteEvictionNamespaces:          lo.ToPtr(""),
+				DisruptionWebhookURLs:             lo.ToPtr(""),
+				DisruptionWebhookSigningKey:       lo.ToPtr(""),
+				DisruptionWebhookRequireAck:       lo.ToPtr(false),
+				ChangeFreezeConfigMap:             lo.ToPtr(""),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(time.Minute),
+				SingleNodeConsolidationTimeout:    lo.ToPtr(3 * time.Minute),
+				InstanceTypesMaxAge:               lo.ToPtr(5 * time.Minute),
+				EvictionQueueWorkers:              lo.ToPtr(10),
+				CanaryInterval:                    lo.ToPtr(time.Duration(0)),
+				CanaryTimeout:                     lo.ToPtr(15 * time.Minute),
+				CanaryNamespace:                   lo.ToPtr("default"),
+				DrainOrdering:                     lo.ToPtr("Default"),
+				MaxDeletionBlockTTL:               lo.ToPtr(time.Hour),
+				NodePoolDrainTimeout:              lo.ToPtr(time.Duration(0)),
 				FeatureGates: test.FeatureGates{
 					NodeRepair:              lo.ToPtr(false),
 					SpotToSpotConsolidation: lo.ToPtr(false),
+					ZonalRebalance:          lo.ToPtr(false),
+					PodBinding:              lo.ToPtr(false),
+					PartialDrain:            lo.ToPtr(false),
+					NodeAdoption:            lo.ToPtr(false),
 				},
 			}))
 		})
@@ -137,28 +364,136 @@ var _ = Describe("Options", func() {
 				"--log-error-output-paths", "/etc/k8s/testerror",
 				"--batch-max-duration", "5s",
 				"--batch-idle-duration", "5s",
-				"--feature-gates", "SpotToSpotConsolidation=true,NodeRepair=true",
+				"--batch-bypass-namespaces", "kube-system",
+				"--batch-bypass-pod-selector", "tier=critical",
+				"--provisioning-backoff-base", "5s",
+				"--provisioning-backoff-max", "5m",
+				"--max-nodes", "10",
+				"--max-cpu", "100",
+				"--max-hourly-cost", "12.5",
+				"--provisioning-parallelism", "4",
+				"--ignore-terminating-pods-after", "5m",
+				"--deterministic-nodeclaim-names",
+				"--nodeclaim-name-template", "{{.NodePool}}-{{.Environment}}-",
+				"--nodeclaim-label-templates", "example.com/shard={{.Shard}}",
+				"--nodeclaim-tag-templates", "environment={{.Environment}}",
+				"--shard-name", "shard-a",
+				"--environment-name", "prod",
+				"--unmanaged-node-expiration",
+				"--controller-concurrency", "node.termination=50,nodeclaim.lifecycle=500",
+				"--controller-qps", "20",
+				"--controller-burst", "200",
+				"--read-only-standby",
+				"--sync-min-percent", "90",
+				"--sync-max-staleness", "30s",
+				"--node-launch-slo-target", "5m",
+				"--node-launch-slo-objective", "90",
+				"--local-data-move-threshold", "0.5",
+				"--node-problem-conditions", "KernelDeadlock",
+				"--node-problem-taints", "example.com/kernel-deadlock",
+				"--pod-advisory-webhook-mode", "Warn",
+				"--webhook-port", "9443",
+				"--honor-cluster-autoscaler-annotations",
+				"--workload-kubeconfig", "/etc/karpenter/workload/kubeconfig",
+				"--non-provisioning-priority-classes", "best-effort,preemptible",
+				"--eviction-api-version", "policy/v1beta1",
+				"--delete-eviction-namespaces", "batch,scratch",
+				"--drain-last-namespaces", "monitoring,logging",
+				"--disruption-webhook-urls", "https://example.com/disruptions",
+				"--disruption-webhook-signing-key", "test-signing-key",
+				"--disruption-webhook-require-ack",
+				"--change-freeze-configmap", "karpenter/change-freeze",
+				"--multi-node-consolidation-timeout", "2m",
+				"--single-node-consolidation-timeout", "5m",
+				"--instance-types-max-age", "10m",
+				"--eviction-queue-workers", "20",
+				"--canary-interval", "1h",
+				"--canary-timeout", "10m",
+				"--canary-namespace", "canaries",
+				"--daemon-overhead-manifests", overheadManifests,
+				"--daemon-default-tolerations", "[{\"key\":\"example.com/dedicated\",\"operator\":\"Exists\"}]",
+				"--drain-ordering", "OwnerKind",
+				"--max-deletion-block-ttl", "30m",
+				"--nodepool-drain-timeout", "2h",
+				"--compliance-requirements", "[{\"key\":\"karpenter.sh/capacity-type\",\"operator\":\"NotIn\",\"values\":[\"spot\"]}]",
+				"--feature-gates", "SpotToSpotConsolidation=true,NodeRepair=true,ZonalRebalance=true,PodBinding=true,PartialDrain=true,NodeAdoption=true",
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:             lo.ToPtr("cli"),
-				MetricsPort:             lo.ToPtr(0),
-				HealthProbePort:         lo.ToPtr(0),
-				KubeClientQPS:           lo.ToPtr(0),
-				KubeClientBurst:         lo.ToPtr(0),
-				EnableProfiling:         lo.ToPtr(true),
-				DisableLeaderElection:   lo.ToPtr(true),
-				LeaderElectionName:      lo.ToPtr("karpenter-controller"),
-				LeaderElectionNamespace: lo.ToPtr("karpenter"),
-				MemoryLimit:             lo.ToPtr[int64](0),
-				LogLevel:                lo.ToPtr("debug"),
-				LogOutputPaths:          lo.ToPtr("/etc/k8s/test"),
-				LogErrorOutputPaths:     lo.ToPtr("/etc/k8s/testerror"),
-				BatchMaxDuration:        lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:       lo.ToPtr(5 * time.Second),
+				ServiceName:                       lo.ToPtr("cli"),
+				MetricsPort:                       lo.ToPtr(0),
+				HealthProbePort:                   lo.ToPtr(0),
+				KubeClientQPS:                     lo.ToPtr(0),
+				KubeClientBurst:                   lo.ToPtr(0),
+				EnableProfiling:                   lo.ToPtr(true),
+				DisableLeaderElection:             lo.ToPtr(true),
+				LeaderElectionName:                lo.ToPtr("karpenter-controller"),
+				LeaderElectionNamespace:           lo.ToPtr("karpenter"),
+				MemoryLimit:                       lo.ToPtr[int64](0),
+				LogLevel:                          lo.ToPtr("debug"),
+				LogOutputPaths:                    lo.ToPtr("/etc/k8s/test"),
+				LogErrorOutputPaths:               lo.ToPtr("/etc/k8s/testerror"),
+				BatchMaxDuration:                  lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(5 * time.Second),
+				BatchBypassNamespaces:             lo.ToPtr("kube-system"),
+				BatchBypassPodSelector:            lo.ToPtr("tier=critical"),
+				ProvisioningBackoffBase:           lo.ToPtr(5 * time.Second),
+				ProvisioningBackoffMax:            lo.ToPtr(5 * time.Minute),
+				MaxNodes:                          lo.ToPtr(10),
+				MaxCPU:                            lo.ToPtr[int64](100),
+				MaxHourlyCost:                     lo.ToPtr(12.5),
+				ProvisioningParallelism:           lo.ToPtr(4),
+				IgnoreTerminatingPodsAfter:        lo.ToPtr(5 * time.Minute),
+				DeterministicNodeClaimNames:       lo.ToPtr(true),
+				NodeClaimNameTemplate:             lo.ToPtr("{{.NodePool}}-{{.Environment}}-"),
+				NodeClaimLabelTemplates:           lo.ToPtr("example.com/shard={{.Shard}}"),
+				NodeClaimTagTemplates:             lo.ToPtr("environment={{.Environment}}"),
+				ShardName:                         lo.ToPtr("shard-a"),
+				EnvironmentName:                   lo.ToPtr("prod"),
+				UnmanagedNodeExpiration:           lo.ToPtr(true),
+				ControllerConcurrency:             map[string]int{"node.termination": 50, "nodeclaim.lifecycle": 500},
+				ControllerQPS:                     lo.ToPtr(20),
+				ControllerBurst:                   lo.ToPtr(200),
+				ReadOnlyStandby:                   lo.ToPtr(true),
+				SyncMinPercent:                    lo.ToPtr(90),
+				SyncMaxStaleness:                  lo.ToPtr(30 * time.Second),
+				NodeLaunchSLOTarget:               lo.ToPtr(5 * time.Minute),
+				NodeLaunchSLOObjective:            lo.ToPtr(90),
+				LocalDataMoveThreshold:            lo.ToPtr(0.5),
+				NodeProblemConditions:             []string{"KernelDeadlock"},
+				NodeProblemTaints:                 []string{"example.com/kernel-deadlock"},
+				PodAdvisoryWebhookMode:            lo.ToPtr("Warn"),
+				WebhookPort:                       lo.ToPtr(9443),
+				HonorClusterAutoscalerAnnotations: lo.ToPtr(true),
+				WorkloadKubeconfig:                lo.ToPtr("/etc/karpenter/workload/kubeconfig"),
+				NonProvisioningPriorityClasses:    lo.ToPtr("best-effort,preemptible"),
+				EvictionAPIVersion:                lo.ToPtr("policy/v1beta1"),
+				DeleteEvictionNamespaces:          lo.ToPtr("batch,scratch"),
+				DrainLastNamespaces:               []string{"monitoring", "logging"},
+				DisruptionWebhookURLs:             lo.ToPtr("https://example.com/disruptions"),
+				DisruptionWebhookSigningKey:       lo.ToPtr("test-signing-key"),
+				DisruptionWebhookRequireAck:       lo.ToPtr(true),
+				ChangeFreezeConfigMap:             lo.ToPtr("karpenter/change-freeze"),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(2 * time.Minute),
+				SingleNodeConsolidationTimeout:    lo.ToPtr(5 * time.Minute),
+				InstanceTypesMaxAge:               lo.ToPtr(10 * time.Minute),
+				EvictionQueueWorkers:              lo.ToPtr(20),
+				CanaryInterval:                    lo.ToPtr(time.Hour),
+				CanaryTimeout:                     lo.ToPtr(10 * time.Minute),
+				CanaryNamespace:                   lo.ToPtr("canaries"),
+				DaemonOverheadPods:                overheadPods,
+				DaemonDefaultTolerations:          []corev1.Toleration{{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}},
+				DrainOrdering:                     lo.ToPtr("OwnerKind"),
+				MaxDeletionBlockTTL:               lo.ToPtr(30 * time.Minute),
+				NodePoolDrainTimeout:              lo.ToPtr(2 * time.Hour),
+				ComplianceRequirements:            []corev1.NodeSelectorRequirement{{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"spot"}}},
 				FeatureGates: test.FeatureGates{
 					NodeRepair:              lo.ToPtr(true),
 					SpotToSpotConsolidation: lo.ToPtr(true),
+					ZonalRebalance:          lo.ToPtr(true),
+					PodBinding:              lo.ToPtr(true),
+					PartialDrain:            lo.ToPtr(true),
+					NodeAdoption:            lo.ToPtr(true),
 				},
 			}))
 		})
@@ -179,7 +514,59 @@ var _ = Describe("Options", func() {
 			os.Setenv("LOG_ERROR_OUTPUT_PATHS", "/etc/k8s/testerror")
 			os.Setenv("BATCH_MAX_DURATION", "5s")
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
-			os.Setenv("FEATURE_GATES", "SpotToSpotConsolidation=true,NodeRepair=true")
+			os.Setenv("BATCH_BYPASS_NAMESPACES", "kube-system")
+			os.Setenv("BATCH_BYPASS_POD_SELECTOR", "tier=critical")
+			os.Setenv("PROVISIONING_BACKOFF_BASE", "5s")
+			os.Setenv("PROVISIONING_BACKOFF_MAX", "5m")
+			os.Setenv("MAX_NODES", "10")
+			os.Setenv("MAX_CPU", "100")
+			os.Setenv("MAX_HOURLY_COST", "12.5")
+			os.Setenv("PROVISIONING_PARALLELISM", "4")
+			os.Setenv("IGNORE_TERMINATING_PODS_AFTER", "5m")
+			os.Setenv("DETERMINISTIC_NODECLAIM_NAMES", "true")
+			os.Setenv("NODECLAIM_NAME_TEMPLATE", "{{.NodePool}}-{{.Environment}}-")
+			os.Setenv("NODECLAIM_LABEL_TEMPLATES", "example.com/shard={{.Shard}}")
+			os.Setenv("NODECLAIM_TAG_TEMPLATES", "environment={{.Environment}}")
+			os.Setenv("SHARD_NAME", "shard-a")
+			os.Setenv("ENVIRONMENT_NAME", "prod")
+			os.Setenv("UNMANAGED_NODE_EXPIRATION", "true")
+			os.Setenv("CONTROLLER_CONCURRENCY", "node.termination=50,nodeclaim.lifecycle=500")
+			os.Setenv("CONTROLLER_QPS", "20")
+			os.Setenv("CONTROLLER_BURST", "200")
+			os.Setenv("READ_ONLY_STANDBY", "true")
+			os.Setenv("SYNC_MIN_PERCENT", "90")
+			os.Setenv("SYNC_MAX_STALENESS", "30s")
+			os.Setenv("NODE_LAUNCH_SLO_TARGET", "5m")
+			os.Setenv("NODE_LAUNCH_SLO_OBJECTIVE", "90")
+			os.Setenv("LOCAL_DATA_MOVE_THRESHOLD", "0.5")
+			os.Setenv("NODE_PROBLEM_CONDITIONS", "KernelDeadlock")
+			os.Setenv("NODE_PROBLEM_TAINTS", "example.com/kernel-deadlock")
+			os.Setenv("POD_ADVISORY_WEBHOOK_MODE", "Warn")
+			os.Setenv("WEBHOOK_PORT", "9443")
+			os.Setenv("HONOR_CLUSTER_AUTOSCALER_ANNOTATIONS", "true")
+			os.Setenv("WORKLOAD_KUBECONFIG", "/etc/karpenter/workload/kubeconfig")
+			os.Setenv("NON_PROVISIONING_PRIORITY_CLASSES", "best-effort,preemptible")
+			os.Setenv("EVICTION_API_VERSION", "policy/v1beta1")
+			os.Setenv("DELETE_EVICTION_NAMESPACES", "batch,scratch")
+			os.Setenv("DRAIN_LAST_NAMESPACES", "monitoring,logging")
+			os.Setenv("DISRUPTION_WEBHOOK_URLS", "https://example.com/disruptions")
+			os.Setenv("DISRUPTION_WEBHOOK_SIGNING_KEY", "test-signing-key")
+			os.Setenv("DISRUPTION_WEBHOOK_REQUIRE_ACK", "true")
+			os.Setenv("CHANGE_FREEZE_CONFIGMAP", "karpenter/change-freeze")
+			os.Setenv("MULTI_NODE_CONSOLIDATION_TIMEOUT", "2m")
+			os.Setenv("SINGLE_NODE_CONSOLIDATION_TIMEOUT", "5m")
+			os.Setenv("INSTANCE_TYPES_MAX_AGE", "10m")
+			os.Setenv("EVICTION_QUEUE_WORKERS", "20")
+			os.Setenv("CANARY_INTERVAL", "1h")
+			os.Setenv("CANARY_TIMEOUT", "10m")
+			os.Setenv("CANARY_NAMESPACE", "canaries")
+			os.Setenv("DAEMON_OVERHEAD_MANIFESTS", overheadManifests)
+			os.Setenv("DAEMON_DEFAULT_TOLERATIONS", "[{\"key\":\"example.com/dedicated\",\"operator\":\"Exists\"}]")
+			os.Setenv("DRAIN_ORDERING", "OwnerKind")
+			os.Setenv("MAX_DELETION_BLOCK_TTL", "30m")
+			os.Setenv("NODEPOOL_DRAIN_TIMEOUT", "2h")
+			os.Setenv("COMPLIANCE_REQUIREMENTS", "[{\"key\":\"karpenter.sh/capacity-type\",\"operator\":\"NotIn\",\"values\":[\"spot\"]}]")
+			os.Setenv("FEATURE_GATES", "SpotToSpotConsolidation=true,NodeRepair=true,ZonalRebalance=true,PodBinding=true,PartialDrain=true,NodeAdoption=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 			}
@@ -187,24 +574,80 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:             lo.ToPtr("env"),
-				MetricsPort:             lo.ToPtr(0),
-				HealthProbePort:         lo.ToPtr(0),
-				KubeClientQPS:           lo.ToPtr(0),
-				KubeClientBurst:         lo.ToPtr(0),
-				EnableProfiling:         lo.ToPtr(true),
-				DisableLeaderElection:   lo.ToPtr(true),
-				LeaderElectionName:      lo.ToPtr("karpenter-controller"),
-				LeaderElectionNamespace: lo.ToPtr("karpenter"),
-				MemoryLimit:             lo.ToPtr[int64](0),
-				LogLevel:                lo.ToPtr("debug"),
-				LogOutputPaths:          lo.ToPtr("/etc/k8s/test"),
-				LogErrorOutputPaths:     lo.ToPtr("/etc/k8s/testerror"),
-				BatchMaxDuration:        lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:       lo.ToPtr(5 * time.Second),
+				ServiceName:                       lo.ToPtr("env"),
+				MetricsPort:                       lo.ToPtr(0),
+				HealthProbePort:                   lo.ToPtr(0),
+				KubeClientQPS:                     lo.ToPtr(0),
+				KubeClientBurst:                   lo.ToPtr(0),
+				EnableProfiling:                   lo.ToPtr(true),
+				DisableLeaderElection:             lo.ToPtr(true),
+				LeaderElectionName:                lo.ToPtr("karpenter-controller"),
+				LeaderElectionNamespace:           lo.ToPtr("karpenter"),
+				MemoryLimit:                       lo.ToPtr[int64](0),
+				LogLevel:                          lo.ToPtr("debug"),
+				LogOutputPaths:                    lo.ToPtr("/etc/k8s/test"),
+				LogErrorOutputPaths:               lo.ToPtr("/etc/k8s/testerror"),
+				BatchMaxDuration:                  lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(5 * time.Second),
+				BatchBypassNamespaces:             lo.ToPtr("kube-system"),
+				BatchBypassPodSelector:            lo.ToPtr("tier=critical"),
+				ProvisioningBackoffBase:           lo.ToPtr(5 * time.Second),
+				ProvisioningBackoffMax:            lo.ToPtr(5 * time.Minute),
+				MaxNodes:                          lo.ToPtr(10),
+				MaxCPU:                            lo.ToPtr[int64](100),
+				MaxHourlyCost:                     lo.ToPtr(12.5),
+				ProvisioningParallelism:           lo.ToPtr(4),
+				IgnoreTerminatingPodsAfter:        lo.ToPtr(5 * time.Minute),
+				DeterministicNodeClaimNames:       lo.ToPtr(true),
+				NodeClaimNameTemplate:             lo.ToPtr("{{.NodePool}}-{{.Environment}}-"),
+				NodeClaimLabelTemplates:           lo.ToPtr("example.com/shard={{.Shard}}"),
+				NodeClaimTagTemplates:             lo.ToPtr("environment={{.Environment}}"),
+				ShardName:                         lo.ToPtr("shard-a"),
+				EnvironmentName:                   lo.ToPtr("prod"),
+				UnmanagedNodeExpiration:           lo.ToPtr(true),
+				ControllerConcurrency:             map[string]int{"node.termination": 50, "nodeclaim.lifecycle": 500},
+				ControllerQPS:                     lo.ToPtr(20),
+				ControllerBurst:                   lo.ToPtr(200),
+				ReadOnlyStandby:                   lo.ToPtr(true),
+				SyncMinPercent:                    lo.ToPtr(90),
+				SyncMaxStaleness:                  lo.ToPtr(30 * time.Second),
+				NodeLaunchSLOTarget:               lo.ToPtr(5 * time.Minute),
+				NodeLaunchSLOObjective:            lo.ToPtr(90),
+				LocalDataMoveThreshold:            lo.ToPtr(0.5),
+				NodeProblemConditions:             []string{"KernelDeadlock"},
+				NodeProblemTaints:                 []string{"example.com/kernel-deadlock"},
+				PodAdvisoryWebhookMode:            lo.ToPtr("Warn"),
+				WebhookPort:                       lo.ToPtr(9443),
+				HonorClusterAutoscalerAnnotations: lo.ToPtr(true),
+				WorkloadKubeconfig:                lo.ToPtr("/etc/karpenter/workload/kubeconfig"),
+				NonProvisioningPriorityClasses:    lo.ToPtr("best-effort,preemptible"),
+				EvictionAPIVersion:                lo.ToPtr("policy/v1beta1"),
+				DeleteEvictionNamespaces:          lo.ToPtr("batch,scratch"),
+				DrainLastNamespaces:               []string{"monitoring", "logging"},
+				DisruptionWebhookURLs:             lo.ToPtr("https://example.com/disruptions"),
+				DisruptionWebhookSigningKey:       lo.ToPtr("test-signing-key"),
+				DisruptionWebhookRequireAck:       lo.ToPtr(true),
+				ChangeFreezeConfigMap:             lo.ToPtr("karpenter/change-freeze"),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(2 * time.Minute),
+				SingleNodeConsolidationTimeout:    lo.ToPtr(5 * time.Minute),
+				InstanceTypesMaxAge:               lo.ToPtr(10 * time.Minute),
+				EvictionQueueWorkers:              lo.ToPtr(20),
+				CanaryInterval:                    lo.ToPtr(time.Hour),
+				CanaryTimeout:                     lo.ToPtr(10 * time.Minute),
+				CanaryNamespace:                   lo.ToPtr("canaries"),
+				DaemonOverheadPods:                overheadPods,
+				DaemonDefaultTolerations:          []corev1.Toleration{{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}},
+				DrainOrdering:                     lo.ToPtr("OwnerKind"),
+				MaxDeletionBlockTTL:               lo.ToPtr(30 * time.Minute),
+				NodePoolDrainTimeout:              lo.ToPtr(2 * time.Hour),
+				ComplianceRequirements:            []corev1.NodeSelectorRequirement{{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"spot"}}},
 				FeatureGates: test.FeatureGates{
 					NodeRepair:              lo.ToPtr(true),
 					SpotToSpotConsolidation: lo.ToPtr(true),
+					ZonalRebalance:          lo.ToPtr(true),
+					PodBinding:              lo.ToPtr(true),
+					PartialDrain:            lo.ToPtr(true),
+					NodeAdoption:            lo.ToPtr(true),
 				},
 			}))
 		})
@@ -220,7 +663,59 @@ var _ = Describe("Options", func() {
 			os.Setenv("LOG_LEVEL", "debug")
 			os.Setenv("BATCH_MAX_DURATION", "5s")
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
-			os.Setenv("FEATURE_GATES", "SpotToSpotConsolidation=true,NodeRepair=true")
+			os.Setenv("BATCH_BYPASS_NAMESPACES", "kube-system")
+			os.Setenv("BATCH_BYPASS_POD_SELECTOR", "tier=critical")
+			os.Setenv("PROVISIONING_BACKOFF_BASE", "5s")
+			os.Setenv("PROVISIONING_BACKOFF_MAX", "5m")
+			os.Setenv("MAX_NODES", "10")
+			os.Setenv("MAX_CPU", "100")
+			os.Setenv("MAX_HOURLY_COST", "12.5")
+			os.Setenv("PROVISIONING_PARALLELISM", "4")
+			os.Setenv("IGNORE_TERMINATING_PODS_AFTER", "5m")
+			os.Setenv("DETERMINISTIC_NODECLAIM_NAMES", "true")
+			os.Setenv("NODECLAIM_NAME_TEMPLATE", "{{.NodePool}}-{{.Environment}}-")
+			os.Setenv("NODECLAIM_LABEL_TEMPLATES", "example.com/shard={{.Shard}}")
+			os.Setenv("NODECLAIM_TAG_TEMPLATES", "environment={{.Environment}}")
+			os.Setenv("SHARD_NAME", "shard-a")
+			os.Setenv("ENVIRONMENT_NAME", "prod")
+			os.Setenv("UNMANAGED_NODE_EXPIRATION", "true")
+			os.Setenv("CONTROLLER_CONCURRENCY", "node.termination=50,nodeclaim.lifecycle=500")
+			os.Setenv("CONTROLLER_QPS", "20")
+			os.Setenv("CONTROLLER_BURST", "200")
+			os.Setenv("READ_ONLY_STANDBY", "true")
+			os.Setenv("SYNC_MIN_PERCENT", "90")
+			os.Setenv("SYNC_MAX_STALENESS", "30s")
+			os.Setenv("NODE_LAUNCH_SLO_TARGET", "5m")
+			os.Setenv("NODE_LAUNCH_SLO_OBJECTIVE", "90")
+			os.Setenv("LOCAL_DATA_MOVE_THRESHOLD", "0.5")
+			os.Setenv("NODE_PROBLEM_CONDITIONS", "KernelDeadlock")
+			os.Setenv("NODE_PROBLEM_TAINTS", "example.com/kernel-deadlock")
+			os.Setenv("POD_ADVISORY_WEBHOOK_MODE", "Warn")
+			os.Setenv("WEBHOOK_PORT", "9443")
+			os.Setenv("HONOR_CLUSTER_AUTOSCALER_ANNOTATIONS", "true")
+			os.Setenv("WORKLOAD_KUBECONFIG", "/etc/karpenter/workload/kubeconfig")
+			os.Setenv("NON_PROVISIONING_PRIORITY_CLASSES", "best-effort,preemptible")
+			os.Setenv("EVICTION_API_VERSION", "policy/v1beta1")
+			os.Setenv("DELETE_EVICTION_NAMESPACES", "batch,scratch")
+			os.Setenv("DRAIN_LAST_NAMESPACES", "monitoring,logging")
+			os.Setenv("DISRUPTION_WEBHOOK_URLS", "https://example.com/disruptions")
+			os.Setenv("DISRUPTION_WEBHOOK_SIGNING_KEY", "test-signing-key")
+			os.Setenv("DISRUPTION_WEBHOOK_REQUIRE_ACK", "true")
+			os.Setenv("CHANGE_FREEZE_CONFIGMAP", "karpenter/change-freeze")
+			os.Setenv("MULTI_NODE_CONSOLIDATION_TIMEOUT", "2m")
+			os.Setenv("SINGLE_NODE_CONSOLIDATION_TIMEOUT", "5m")
+			os.Setenv("INSTANCE_TYPES_MAX_AGE", "10m")
+			os.Setenv("EVICTION_QUEUE_WORKERS", "20")
+			os.Setenv("CANARY_INTERVAL", "1h")
+			os.Setenv("CANARY_TIMEOUT", "10m")
+			os.Setenv("CANARY_NAMESPACE", "canaries")
+			os.Setenv("DAEMON_OVERHEAD_MANIFESTS", overheadManifests)
+			os.Setenv("DAEMON_DEFAULT_TOLERATIONS", "[{\"key\":\"example.com/dedicated\",\"operator\":\"Exists\"}]")
+			os.Setenv("DRAIN_ORDERING", "OwnerKind")
+			os.Setenv("MAX_DELETION_BLOCK_TTL", "30m")
+			os.Setenv("NODEPOOL_DRAIN_TIMEOUT", "2h")
+			os.Setenv("COMPLIANCE_REQUIREMENTS", "[{\"key\":\"karpenter.sh/capacity-type\",\"operator\":\"NotIn\",\"values\":[\"spot\"]}]")
+			os.Setenv("FEATURE_GATES", "SpotToSpotConsolidation=true,NodeRepair=true,ZonalRebalance=true,PodBinding=true,PartialDrain=true,NodeAdoption=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 			}
@@ -233,24 +728,80 @@ var _ = Describe("Options", func() {
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:             lo.ToPtr("cli"),
-				MetricsPort:             lo.ToPtr(0),
-				HealthProbePort:         lo.ToPtr(0),
-				KubeClientQPS:           lo.ToPtr(0),
-				KubeClientBurst:         lo.ToPtr(0),
-				EnableProfiling:         lo.ToPtr(true),
-				DisableLeaderElection:   lo.ToPtr(true),
-				LeaderElectionName:      lo.ToPtr("karpenter-leader-election"),
-				LeaderElectionNamespace: lo.ToPtr(""),
-				MemoryLimit:             lo.ToPtr[int64](0),
-				LogLevel:                lo.ToPtr("debug"),
-				LogOutputPaths:          lo.ToPtr("/etc/k8s/test"),
-				LogErrorOutputPaths:     lo.ToPtr("/etc/k8s/testerror"),
-				BatchMaxDuration:        lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:       lo.ToPtr(5 * time.Second),
+				ServiceName:                       lo.ToPtr("cli"),
+				MetricsPort:                       lo.ToPtr(0),
+				HealthProbePort:                   lo.ToPtr(0),
+				KubeClientQPS:                     lo.ToPtr(0),
+				KubeClientBurst:                   lo.ToPtr(0),
+				EnableProfiling:                   lo.ToPtr(true),
+				DisableLeaderElection:             lo.ToPtr(true),
+				LeaderElectionName:                lo.ToPtr("karpenter-leader-election"),
+				LeaderElectionNamespace:           lo.ToPtr(""),
+				MemoryLimit:                       lo.ToPtr[int64](0),
+				LogLevel:                          lo.ToPtr("debug"),
+				LogOutputPaths:                    lo.ToPtr("/etc/k8s/test"),
+				LogErrorOutputPaths:               lo.ToPtr("/etc/k8s/testerror"),
+				BatchMaxDuration:                  lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:                 lo.ToPtr(5 * time.Second),
+				BatchBypassNamespaces:             lo.ToPtr("kube-system"),
+				BatchBypassPodSelector:            lo.ToPtr("tier=critical"),
+				ProvisioningBackoffBase:           lo.ToPtr(5 * time.Second),
+				ProvisioningBackoffMax:            lo.ToPtr(5 * time.Minute),
+				MaxNodes:                          lo.ToPtr(10),
+				MaxCPU:                            lo.ToPtr[int64](100),
+				MaxHourlyCost:                     lo.ToPtr(12.5),
+				ProvisioningParallelism:           lo.ToPtr(4),
+				IgnoreTerminatingPodsAfter:        lo.ToPtr(5 * time.Minute),
+				DeterministicNodeClaimNames:       lo.ToPtr(true),
+				NodeClaimNameTemplate:             lo.ToPtr("{{.NodePool}}-{{.Environment}}-"),
+				NodeClaimLabelTemplates:           lo.ToPtr("example.com/shard={{.Shard}}"),
+				NodeClaimTagTemplates:             lo.ToPtr("environment={{.Environment}}"),
+				ShardName:                         lo.ToPtr("shard-a"),
+				EnvironmentName:                   lo.ToPtr("prod"),
+				UnmanagedNodeExpiration:           lo.ToPtr(true),
+				ControllerConcurrency:             map[string]int{"node.termination": 50, "nodeclaim.lifecycle": 500},
+				ControllerQPS:                     lo.ToPtr(20),
+				ControllerBurst:                   lo.ToPtr(200),
+				ReadOnlyStandby:                   lo.ToPtr(true),
+				SyncMinPercent:                    lo.ToPtr(90),
+				SyncMaxStaleness:                  lo.ToPtr(30 * time.Second),
+				NodeLaunchSLOTarget:               lo.ToPtr(5 * time.Minute),
+				NodeLaunchSLOObjective:            lo.ToPtr(90),
+				LocalDataMoveThreshold:            lo.ToPtr(0.5),
+				NodeProblemConditions:             []string{"KernelDeadlock"},
+				NodeProblemTaints:                 []string{"example.com/kernel-deadlock"},
+				PodAdvisoryWebhookMode:            lo.ToPtr("Warn"),
+				WebhookPort:                       lo.ToPtr(9443),
+				HonorClusterAutoscalerAnnotations: lo.ToPtr(true),
+				WorkloadKubeconfig:                lo.ToPtr("/etc/karpenter/workload/kubeconfig"),
+				NonProvisioningPriorityClasses:    lo.ToPtr("best-effort,preemptible"),
+				EvictionAPIVersion:                lo.ToPtr("policy/v1beta1"),
+				DeleteEvictionNamespaces:          lo.ToPtr("batch,scratch"),
+				DrainLastNamespaces:               []string{"monitoring", "logging"},
+				DisruptionWebhookURLs:             lo.ToPtr("https://example.com/disruptions"),
+				DisruptionWebhookSigningKey:       lo.ToPtr("test-signing-key"),
+				DisruptionWebhookRequireAck:       lo.ToPtr(true),
+				ChangeFreezeConfigMap:             lo.ToPtr("karpenter/change-freeze"),
+				MultiNodeConsolidationTimeout:     lo.ToPtr(2 * time.Minute),
+				SingleNodeConsolidationTimeout:    lo.ToPtr(5 * time.Minute),
+				InstanceTypesMaxAge:               lo.ToPtr(10 * time.Minute),
+				EvictionQueueWorkers:              lo.ToPtr(20),
+				CanaryInterval:                    lo.ToPtr(time.Hour),
+				CanaryTimeout:                     lo.ToPtr(10 * time.Minute),
+				CanaryNamespace:                   lo.ToPtr("canaries"),
+				DaemonOverheadPods:                overheadPods,
+				DaemonDefaultTolerations:          []corev1.Toleration{{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}},
+				DrainOrdering:                     lo.ToPtr("OwnerKind"),
+				MaxDeletionBlockTTL:               lo.ToPtr(30 * time.Minute),
+				NodePoolDrainTimeout:              lo.ToPtr(2 * time.Hour),
+				ComplianceRequirements:            []corev1.NodeSelectorRequirement{{Key: "karpenter.sh/capacity-type", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"spot"}}},
 				FeatureGates: test.FeatureGates{
 					NodeRepair:              lo.ToPtr(true),
 					SpotToSpotConsolidation: lo.ToPtr(true),
+					ZonalRebalance:          lo.ToPtr(true),
+					PodBinding:              lo.ToPtr(true),
+					PartialDrain:            lo.ToPtr(true),
+					NodeAdoption:            lo.ToPtr(true),
 				},
 			}))
 		})
@@ -281,6 +832,107 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs, "--log-level", "hello")
 			Expect(err).ToNot(BeNil())
 		})
+		It("should error with an invalid pod advisory webhook mode", func() {
+			err := opts.Parse(fs, "--pod-advisory-webhook-mode", "Block")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid eviction API version", func() {
+			err := opts.Parse(fs, "--eviction-api-version", "policy/v2")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid drain ordering", func() {
+			err := opts.Parse(fs, "--drain-ordering", "Random")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a change freeze configmap that isn't namespace/name", func() {
+			err := opts.Parse(fs, "--change-freeze-configmap", "change-freeze")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should parse the change freeze configmap into its namespace and name", func() {
+			err := opts.Parse(fs, "--change-freeze-configmap", "karpenter/change-freeze")
+			Expect(err).To(BeNil())
+			Expect(opts.ChangeFreezeConfigMapKey()).To(Equal(types.NamespacedName{Namespace: "karpenter", Name: "change-freeze"}))
+		})
+		It("should error with a delete eviction namespace that isn't a valid namespace name", func() {
+			err := opts.Parse(fs, "--delete-eviction-namespaces", "kube-system, Monitoring")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a drain last namespace that isn't a valid namespace name", func() {
+			err := opts.Parse(fs, "--drain-last-namespaces", "kube-system, Monitoring")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a batch bypass pod selector that doesn't parse", func() {
+			err := opts.Parse(fs, "--batch-bypass-pod-selector", "tier in (critical")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a sync min percent above 100", func() {
+			err := opts.Parse(fs, "--sync-min-percent", "101")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a node launch SLO objective of 100", func() {
+			err := opts.Parse(fs, "--node-launch-slo-objective", "100")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a provisioning parallelism less than 1", func() {
+			err := opts.Parse(fs, "--provisioning-parallelism", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a provisioning backoff base greater than the max", func() {
+			err := opts.Parse(fs, "--provisioning-backoff-base", "2m", "--provisioning-backoff-max", "1m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a consolidation timeout that isn't positive", func() {
+			err := opts.Parse(fs, "--multi-node-consolidation-timeout", "0s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative instance types max age", func() {
+			err := opts.Parse(fs, "--instance-types-max-age", "-1m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with eviction queue workers that aren't positive", func() {
+			err := opts.Parse(fs, "--eviction-queue-workers", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a controller qps that isn't positive", func() {
+			err := opts.Parse(fs, "--controller-qps", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a controller burst that isn't positive", func() {
+			err := opts.Parse(fs, "--controller-burst", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a nodepool drain timeout that is negative", func() {
+			err := opts.Parse(fs, "--nodepool-drain-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a canary timeout that isn't positive", func() {
+			err := opts.Parse(fs, "--canary-timeout", "0s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a nodeclaim name template that doesn't parse", func() {
+			err := opts.Parse(fs, "--nodeclaim-name-template", "{{.NodePool")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a nodeclaim name template that doesn't render unique names", func() {
+			err := opts.Parse(fs, "--nodeclaim-name-template", "{{.NodePool}}-{{.Index}}")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should succeed with a nodeclaim name template that references the batch and index", func() {
+			err := opts.Parse(fs, "--nodeclaim-name-template", "{{.NodePool}}-{{.Batch}}-{{.Index}}")
+			Expect(err).To(BeNil())
+		})
+		It("should succeed with a nodeclaim name template that is used as a generated name prefix", func() {
+			err := opts.Parse(fs, "--nodeclaim-name-template", "{{.NodePool}}-")
+			Expect(err).To(BeNil())
+		})
+		It("should error with a nodeclaim label template that doesn't parse", func() {
+			err := opts.Parse(fs, "--nodeclaim-label-templates", "example.com/shard={{.Shard")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with daemon overhead manifests that can't be read", func() {
+			err := opts.Parse(fs, "--daemon-overhead-manifests", filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).ToNot(BeNil())
+		})
 	})
 })
 
@@ -304,5 +956,61 @@ func expectOptionsMatch(optsA, optsB *options.Options) {
 	Expect(optsA.LogErrorOutputPaths).To(Equal(optsB.LogErrorOutputPaths))
 	Expect(optsA.BatchMaxDuration).To(Equal(optsB.BatchMaxDuration))
 	Expect(optsA.BatchIdleDuration).To(Equal(optsB.BatchIdleDuration))
+	Expect(optsA.BatchBypassNamespaces).To(Equal(optsB.BatchBypassNamespaces))
+	Expect(optsA.BatchBypassPodSelector).To(Equal(optsB.BatchBypassPodSelector))
+	Expect(optsA.ProvisioningBackoffBase).To(Equal(optsB.ProvisioningBackoffBase))
+	Expect(optsA.ProvisioningBackoffMax).To(Equal(optsB.ProvisioningBackoffMax))
+	Expect(optsA.MaxNodes).To(Equal(optsB.MaxNodes))
+	Expect(optsA.MaxCPU).To(Equal(optsB.MaxCPU))
+	Expect(optsA.MaxHourlyCost).To(Equal(optsB.MaxHourlyCost))
+	Expect(optsA.ProvisioningParallelism).To(Equal(optsB.ProvisioningParallelism))
+	Expect(optsA.IgnoreTerminatingPodsAfter).To(Equal(optsB.IgnoreTerminatingPodsAfter))
+	Expect(optsA.DeterministicNodeClaimNames).To(Equal(optsB.DeterministicNodeClaimNames))
+	Expect(optsA.NodeClaimNameTemplate).To(Equal(optsB.NodeClaimNameTemplate))
+	Expect(optsA.NodeClaimLabelTemplates).To(Equal(optsB.NodeClaimLabelTemplates))
+	Expect(optsA.NodeClaimTagTemplates).To(Equal(optsB.NodeClaimTagTemplates))
+	Expect(optsA.ShardName).To(Equal(optsB.ShardName))
+	Expect(optsA.EnvironmentName).To(Equal(optsB.EnvironmentName))
+	Expect(optsA.UnmanagedNodeExpiration).To(Equal(optsB.UnmanagedNodeExpiration))
+	Expect(optsA.ControllerConcurrency.MaxConcurrentReconciles).To(Equal(optsB.ControllerConcurrency.MaxConcurrentReconciles))
+	Expect(optsA.ControllerQPS).To(Equal(optsB.ControllerQPS))
+	Expect(optsA.ControllerBurst).To(Equal(optsB.ControllerBurst))
+	Expect(optsA.ReadOnlyStandby).To(Equal(optsB.ReadOnlyStandby))
+	Expect(optsA.SyncMinPercent).To(Equal(optsB.SyncMinPercent))
+	Expect(optsA.SyncMaxStaleness).To(Equal(optsB.SyncMaxStaleness))
+	Expect(optsA.NodeLaunchSLOTarget).To(Equal(optsB.NodeLaunchSLOTarget))
+	Expect(optsA.NodeLaunchSLOObjective).To(Equal(optsB.NodeLaunchSLOObjective))
+	Expect(optsA.LocalDataMoveThreshold).To(Equal(optsB.LocalDataMoveThreshold))
+	Expect(optsA.NodeProblems.ConditionTypes).To(Equal(optsB.NodeProblems.ConditionTypes))
+	Expect(optsA.NodeProblems.TaintKeys).To(Equal(optsB.NodeProblems.TaintKeys))
+	Expect(optsA.PodAdvisoryWebhookMode).To(Equal(optsB.PodAdvisoryWebhookMode))
+	Expect(optsA.WebhookPort).To(Equal(optsB.WebhookPort))
+	Expect(optsA.HonorClusterAutoscalerAnnotations).To(Equal(optsB.HonorClusterAutoscalerAnnotations))
+	Expect(optsA.WorkloadKubeconfig).To(Equal(optsB.WorkloadKubeconfig))
+	Expect(optsA.NonProvisioningPriorityClasses).To(Equal(optsB.NonProvisioningPriorityClasses))
+	Expect(optsA.EvictionAPIVersion).To(Equal(optsB.EvictionAPIVersion))
+	Expect(optsA.DeleteEvictionNamespaces).To(Equal(optsB.DeleteEvictionNamespaces))
+	Expect(optsA.DrainLastNamespaces.Namespaces).To(Equal(optsB.DrainLastNamespaces.Namespaces))
+	Expect(optsA.DisruptionWebhookURLs).To(Equal(optsB.DisruptionWebhookURLs))
+	Expect(optsA.DisruptionWebhookSigningKey).To(Equal(optsB.DisruptionWebhookSigningKey))
+	Expect(optsA.DisruptionWebhookRequireAck).To(Equal(optsB.DisruptionWebhookRequireAck))
+	Expect(optsA.ChangeFreezeConfigMap).To(Equal(optsB.ChangeFreezeConfigMap))
+	Expect(optsA.MultiNodeConsolidationTimeout).To(Equal(optsB.MultiNodeConsolidationTimeout))
+	Expect(optsA.SingleNodeConsolidationTimeout).To(Equal(optsB.SingleNodeConsolidationTimeout))
+	Expect(optsA.InstanceTypesMaxAge).To(Equal(optsB.InstanceTypesMaxAge))
+	Expect(optsA.EvictionQueueWorkers).To(Equal(optsB.EvictionQueueWorkers))
+	Expect(optsA.CanaryInterval).To(Equal(optsB.CanaryInterval))
+	Expect(optsA.CanaryTimeout).To(Equal(optsB.CanaryTimeout))
+	Expect(optsA.CanaryNamespace).To(Equal(optsB.CanaryNamespace))
+	Expect(optsA.DaemonOverhead.Pods).To(Equal(optsB.DaemonOverhead.Pods))
+	Expect(optsA.DaemonOverhead.DefaultTolerations).To(Equal(optsB.DaemonOverhead.DefaultTolerations))
+	Expect(optsA.DrainOrdering).To(Equal(optsB.DrainOrdering))
+	Expect(optsA.MaxDeletionBlockTTL).To(Equal(optsB.MaxDeletionBlockTTL))
+	Expect(optsA.NodePoolDrainTimeout).To(Equal(optsB.NodePoolDrainTimeout))
+	Expect(optsA.ComplianceRequirements.Requirements).To(Equal(optsB.ComplianceRequirements.Requirements))
 	Expect(optsA.FeatureGates.SpotToSpotConsolidation).To(Equal(optsB.FeatureGates.SpotToSpotConsolidation))
+	Expect(optsA.FeatureGates.ZonalRebalance).To(Equal(optsB.FeatureGates.ZonalRebalance))
+	Expect(optsA.FeatureGates.PodBinding).To(Equal(optsB.FeatureGates.PodBinding))
+	Expect(optsA.FeatureGates.PartialDrain).To(Equal(optsB.FeatureGates.PartialDrain))
+	Expect(optsA.FeatureGates.NodeAdoption).To(Equal(optsB.FeatureGates.NodeAdoption))
 }
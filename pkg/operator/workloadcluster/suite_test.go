@@ -0,0 +1,136 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcluster_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/workloadcluster"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var management client.Client
+var workload client.Client
+var kubeClient client.Client
+
+func TestWorkloadCluster(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "WorkloadCluster")
+}
+
+var _ = BeforeEach(func() {
+	management = fake.NewClientBuilder().WithStatusSubresource(&v1.NodeClaim{}).Build()
+	workload = fake.NewClientBuilder().WithStatusSubresource(&corev1.Node{}).Build()
+	kubeClient = workloadcluster.NewClient(management, workload)
+})
+
+var _ = Describe("WorkloadCluster", func() {
+	It("should only consider the workload kinds to be in the workload cluster", func() {
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("Node"))).To(BeTrue())
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("Pod"))).To(BeTrue())
+		Expect(workloadcluster.InWorkloadCluster(policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudgetList"))).To(BeTrue())
+		Expect(workloadcluster.InWorkloadCluster(appsv1.SchemeGroupVersion.WithKind("DaemonSet"))).To(BeTrue())
+		Expect(workloadcluster.InWorkloadCluster(storagev1.SchemeGroupVersion.WithKind("VolumeAttachment"))).To(BeTrue())
+		Expect(workloadcluster.InWorkloadCluster(v1.SchemeGroupVersion.WithKind("NodeClaim"))).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(v1.SchemeGroupVersion.WithKind("NodePool"))).To(BeFalse())
+	})
+	It("should keep configmaps and secrets in the management cluster", func() {
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("ConfigMap"))).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("Secret"))).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("SecretList"))).To(BeFalse())
+	})
+	It("should read configmaps from the management cluster", func() {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "change-freeze", Namespace: "kube-system"}}
+		Expect(management.Create(ctx, configMap)).To(Succeed())
+
+		Expect(kubeClient.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{})).To(Succeed())
+	})
+	It("should keep leases and events in the management cluster", func() {
+		Expect(workloadcluster.InWorkloadCluster(schema.GroupVersionKind{Group: "coordination.k8s.io", Version: "v1", Kind: "Lease"})).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(schema.GroupVersionKind{Group: "coordination.k8s.io", Version: "v1", Kind: "LeaseList"})).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("Event"))).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(schema.GroupVersionKind{Group: "events.k8s.io", Version: "v1", Kind: "Event"})).To(BeFalse())
+		Expect(workloadcluster.InWorkloadCluster(corev1.SchemeGroupVersion.WithKind("PodList"))).To(BeTrue())
+	})
+	It("should create leases in the management cluster", func() {
+		lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "karpenter-leader-election", Namespace: "kube-system"}}
+		Expect(kubeClient.Create(ctx, lease)).To(Succeed())
+
+		Expect(management.Get(ctx, client.ObjectKeyFromObject(lease), &coordinationv1.Lease{})).To(Succeed())
+		Expect(errors.IsNotFound(workload.Get(ctx, client.ObjectKeyFromObject(lease), &coordinationv1.Lease{}))).To(BeTrue())
+	})
+	It("should create custom resources in the management cluster", func() {
+		nodeClaim := test.NodeClaim()
+		Expect(kubeClient.Create(ctx, nodeClaim)).To(Succeed())
+
+		Expect(management.Get(ctx, client.ObjectKeyFromObject(nodeClaim), &v1.NodeClaim{})).To(Succeed())
+		Expect(errors.IsNotFound(workload.Get(ctx, client.ObjectKeyFromObject(nodeClaim), &v1.NodeClaim{}))).To(BeTrue())
+	})
+	It("should create built-in kinds in the workload cluster", func() {
+		node := test.Node()
+		Expect(kubeClient.Create(ctx, node)).To(Succeed())
+
+		Expect(workload.Get(ctx, client.ObjectKeyFromObject(node), &corev1.Node{})).To(Succeed())
+		Expect(errors.IsNotFound(management.Get(ctx, client.ObjectKeyFromObject(node), &corev1.Node{}))).To(BeTrue())
+	})
+	It("should list objects from the cluster that they live in", func() {
+		Expect(management.Create(ctx, test.NodeClaim())).To(Succeed())
+		Expect(workload.Create(ctx, test.Node())).To(Succeed())
+		Expect(workload.Create(ctx, test.Node())).To(Succeed())
+
+		nodeClaims := &v1.NodeClaimList{}
+		Expect(kubeClient.List(ctx, nodeClaims)).To(Succeed())
+		Expect(nodeClaims.Items).To(HaveLen(1))
+		nodes := &corev1.NodeList{}
+		Expect(kubeClient.List(ctx, nodes)).To(Succeed())
+		Expect(nodes.Items).To(HaveLen(2))
+	})
+	It("should update the status of objects in the cluster that they live in", func() {
+		nodeClaim := test.NodeClaim()
+		node := test.Node()
+		Expect(management.Create(ctx, nodeClaim)).To(Succeed())
+		Expect(workload.Create(ctx, node)).To(Succeed())
+
+		nodeClaim.Status.ProviderID = "fake://provider-id"
+		Expect(kubeClient.Status().Update(ctx, nodeClaim)).To(Succeed())
+		node.Status.Phase = corev1.NodeRunning
+		Expect(kubeClient.Status().Update(ctx, node)).To(Succeed())
+
+		Expect(management.Get(ctx, client.ObjectKeyFromObject(nodeClaim), nodeClaim)).To(Succeed())
+		Expect(nodeClaim.Status.ProviderID).To(Equal("fake://provider-id"))
+		Expect(workload.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Status.Phase).To(Equal(corev1.NodeRunning))
+	})
+})
@@ -0,0 +1,349 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workloadcluster supports hosted control-plane topologies where Karpenter's CRDs (NodePools, NodeClaims and
+// the cloud provider's NodeClasses) live in a management cluster while the Pods and Nodes that Karpenter manages live
+// in a separate workload cluster. The client and cache in this package route every object to the cluster that it
+// lives in so that controllers and the cluster state can keep using a single client and cache. Only the kinds that
+// Karpenter schedules and drains against live in the workload cluster, while everything else, such as Leases, Events,
+// ConfigMaps and Secrets, stays in the management cluster with the rest of Karpenter's own resources.
+package workloadcluster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	resourcev1beta1 "k8s.io/api/resource/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// workload are the kinds that live in the workload cluster, which are the Pods and Nodes that Karpenter manages along
+// with the kinds that it reads to schedule pods to nodes and to drain them
+var workload = sets.New(
+	schema.GroupKind{Group: corev1.GroupName, Kind: "Node"},
+	schema.GroupKind{Group: corev1.GroupName, Kind: "Pod"},
+	schema.GroupKind{Group: corev1.GroupName, Kind: "Binding"},
+	schema.GroupKind{Group: corev1.GroupName, Kind: "PersistentVolume"},
+	schema.GroupKind{Group: corev1.GroupName, Kind: "PersistentVolumeClaim"},
+	schema.GroupKind{Group: policyv1.GroupName, Kind: "PodDisruptionBudget"},
+	schema.GroupKind{Group: appsv1.GroupName, Kind: "DaemonSet"},
+	schema.GroupKind{Group: storagev1.GroupName, Kind: "VolumeAttachment"},
+	schema.GroupKind{Group: storagev1.GroupName, Kind: "StorageClass"},
+	schema.GroupKind{Group: storagev1.GroupName, Kind: "CSINode"},
+	schema.GroupKind{Group: resourcev1beta1.GroupName, Kind: "ResourceClaim"},
+)
+
+// InWorkloadCluster returns true if objects of the kind live in the workload cluster. Pods, Nodes and the kinds that
+// Karpenter schedules and drains them against live in the workload cluster while every other kind lives in the
+// management cluster.
+func InWorkloadCluster(gvk schema.GroupVersionKind) bool {
+	return workload.Has(schema.GroupKind{Group: gvk.Group, Kind: strings.TrimSuffix(gvk.Kind, "List")})
+}
+
+// Config returns the config of the cluster that the workload kinds live in, which is the workload cluster if the
+// manager's client routes them there and the manager's cluster otherwise
+func Config(m manager.Manager) *rest.Config {
+	if c, ok := m.GetClient().(*Client); ok && c.workloadConfig != nil {
+		return c.workloadConfig
+	}
+	return m.GetConfig()
+}
+
+// NewCacheFunc returns a cache.NewCacheFunc that creates a cache that reads the workload kinds from the workload cluster
+// and every other kind from the cluster of the passed config
+func NewCacheFunc(workloadConfig *rest.Config) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		management, err := cache.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		// The mapper and http client are built for the management cluster, so let the workload cache build its own
+		workloadOpts := opts
+		workloadOpts.Mapper = nil
+		workloadOpts.HTTPClient = nil
+		workload, err := cache.New(workloadConfig, workloadOpts)
+		if err != nil {
+			return nil, err
+		}
+		return NewCache(management, workload, opts.Scheme), nil
+	}
+}
+
+// NewClientFunc returns a client.NewClientFunc that creates a client that reads and writes the workload kinds in the
+// workload cluster and every other kind in the cluster of the passed config
+func NewClientFunc(workloadConfig *rest.Config) client.NewClientFunc {
+	return func(config *rest.Config, opts client.Options) (client.Client, error) {
+		management, err := client.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		workloadOpts := opts
+		workloadOpts.Mapper = nil
+		workloadOpts.HTTPClient = nil
+		workload, err := client.New(workloadConfig, workloadOpts)
+		if err != nil {
+			return nil, err
+		}
+		c := NewClient(management, workload)
+		c.workloadConfig = workloadConfig
+		return c, nil
+	}
+}
+
+var _ client.Client = &Client{}
+var _ cache.Cache = &Cache{}
+
+// router picks the cluster that an object lives in
+type router[T any] struct {
+	scheme     *runtime.Scheme
+	management T
+	workload   T
+}
+
+func (r router[T]) For(obj runtime.Object) (T, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return lo.Empty[T](), err
+	}
+	return lo.Ternary(InWorkloadCluster(gvk), r.workload, r.management), nil
+}
+
+func (r router[T]) ForKind(gvk schema.GroupVersionKind) T {
+	return lo.Ternary(InWorkloadCluster(gvk), r.workload, r.management)
+}
+
+// Client routes requests for the workload kinds to the workload cluster and requests for every other kind to the
+// management cluster
+type Client struct {
+	router[client.Client]
+
+	// workloadConfig is the config that the workload client was built from, if it's known
+	workloadConfig *rest.Config
+}
+
+func NewClient(management, workload client.Client) *Client {
+	return &Client{router: router[client.Client]{scheme: management.Scheme(), management: management, workload: workload}}
+}
+
+func (c *Client) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Get(ctx, key, obj, opts...)
+}
+
+func (c *Client) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	cl, err := c.For(list)
+	if err != nil {
+		return err
+	}
+	return cl.List(ctx, list, opts...)
+}
+
+func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Create(ctx, obj, opts...)
+}
+
+func (c *Client) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Delete(ctx, obj, opts...)
+}
+
+func (c *Client) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Update(ctx, obj, opts...)
+}
+
+func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *Client) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *Client) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *Client) SubResource(subResource string) client.SubResourceClient {
+	return &subResourceClient{router: router[client.SubResourceClient]{
+		scheme:     c.scheme,
+		management: c.management.SubResource(subResource),
+		workload:   c.workload.SubResource(subResource),
+	}}
+}
+
+func (c *Client) Scheme() *runtime.Scheme {
+	return c.scheme
+}
+
+// RESTMapper returns the mapper of the management cluster, which is the cluster that the manager runs against
+func (c *Client) RESTMapper() meta.RESTMapper {
+	return c.management.RESTMapper()
+}
+
+func (c *Client) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return apiutil.GVKForObject(obj, c.scheme)
+}
+
+func (c *Client) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	cl, err := c.For(obj)
+	if err != nil {
+		return false, err
+	}
+	return cl.IsObjectNamespaced(obj)
+}
+
+type subResourceClient struct {
+	router[client.SubResourceClient]
+}
+
+func (c *subResourceClient) Get(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Get(ctx, obj, subResource, opts...)
+}
+
+func (c *subResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *subResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Update(ctx, obj, opts...)
+}
+
+func (c *subResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	cl, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return cl.Patch(ctx, obj, patch, opts...)
+}
+
+// Cache serves informers and reads for the workload kinds from the workload cluster and for every other kind from the
+// management cluster
+type Cache struct {
+	router[cache.Cache]
+}
+
+func NewCache(management, workload cache.Cache, scheme *runtime.Scheme) *Cache {
+	return &Cache{router: router[cache.Cache]{scheme: scheme, management: management, workload: workload}}
+}
+
+func (c *Cache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	ca, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return ca.Get(ctx, key, obj, opts...)
+}
+
+func (c *Cache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ca, err := c.For(list)
+	if err != nil {
+		return err
+	}
+	return ca.List(ctx, list, opts...)
+}
+
+func (c *Cache) GetInformer(ctx context.Context, obj client.Object, opts ...cache.InformerGetOption) (cache.Informer, error) {
+	ca, err := c.For(obj)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetInformer(ctx, obj, opts...)
+}
+
+func (c *Cache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind, opts ...cache.InformerGetOption) (cache.Informer, error) {
+	return c.ForKind(gvk).GetInformerForKind(ctx, gvk, opts...)
+}
+
+func (c *Cache) RemoveInformer(ctx context.Context, obj client.Object) error {
+	ca, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return ca.RemoveInformer(ctx, obj)
+}
+
+func (c *Cache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	ca, err := c.For(obj)
+	if err != nil {
+		return err
+	}
+	return ca.IndexField(ctx, obj, field, extractValue)
+}
+
+// Start starts both caches and blocks until the context is cancelled or either cache fails to start
+func (c *Cache) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 2)
+	go func() { errs <- c.management.Start(ctx) }()
+	go func() { errs <- c.workload.Start(ctx) }()
+	// The deferred cancel stops the other cache if either cache fails to start
+	if err := <-errs; err != nil {
+		return err
+	}
+	return <-errs
+}
+
+func (c *Cache) WaitForCacheSync(ctx context.Context) bool {
+	return c.management.WaitForCacheSync(ctx) && c.workload.WaitForCacheSync(ctx)
+}
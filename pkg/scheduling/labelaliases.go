@@ -0,0 +1,97 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"sync/atomic"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// LabelAliases are label keys that are translated into another label key in pod and NodePool requirements, in the same
+// way as the NormalizedLabels. They're registered at runtime so that aliases can be added without changes to the
+// controller.
+type LabelAliases struct {
+	aliases atomic.Pointer[map[string]string]
+}
+
+func NewLabelAliases() *LabelAliases {
+	return &LabelAliases{}
+}
+
+// Set replaces the registered aliases with the passed aliases
+func (l *LabelAliases) Set(aliases map[string]string) {
+	l.aliases.Store(&aliases)
+}
+
+// Normalize returns the label key that the passed key is an alias for, or the key itself if it isn't an alias
+func (l *LabelAliases) Normalize(key string) string {
+	if aliases := l.aliases.Load(); aliases != nil {
+		if normalized, ok := (*aliases)[key]; ok {
+			return normalized
+		}
+	}
+	return key
+}
+
+// InjectPod translates the aliases in the node selector and node affinity of the pod. Node selector aliases are left in
+// place if the pod also selects on the key that they're an alias for.
+func (l *LabelAliases) InjectPod(pod *corev1.Pod) {
+	for key, value := range pod.Spec.NodeSelector {
+		if normalized := l.Normalize(key); normalized != key {
+			if _, ok := pod.Spec.NodeSelector[normalized]; !ok {
+				pod.Spec.NodeSelector[normalized] = value
+				delete(pod.Spec.NodeSelector, key)
+			}
+		}
+	}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
+		return
+	}
+	if required := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		for i := range required.NodeSelectorTerms {
+			l.normalizeRequirements(required.NodeSelectorTerms[i].MatchExpressions)
+		}
+	}
+	for i := range pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		l.normalizeRequirements(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].Preference.MatchExpressions)
+	}
+}
+
+// InjectNodePool translates the aliases in the requirements of the NodePool's template. The NodePool is copied if any of
+// its requirements are translated so that the passed NodePool isn't modified.
+func (l *LabelAliases) InjectNodePool(nodePool *v1.NodePool) *v1.NodePool {
+	if !lo.ContainsBy(nodePool.Spec.Template.Spec.Requirements, func(r v1.NodeSelectorRequirementWithMinValues) bool {
+		return l.Normalize(r.Key) != r.Key
+	}) {
+		return nodePool
+	}
+	nodePool = nodePool.DeepCopy()
+	for i := range nodePool.Spec.Template.Spec.Requirements {
+		nodePool.Spec.Template.Spec.Requirements[i].Key = l.Normalize(nodePool.Spec.Template.Spec.Requirements[i].Key)
+	}
+	return nodePool
+}
+
+func (l *LabelAliases) normalizeRequirements(requirements []corev1.NodeSelectorRequirement) {
+	for i := range requirements {
+		requirements[i].Key = l.Normalize(requirements[i].Key)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+var _ = Describe("LabelAliases", func() {
+	const csiZoneLabel = "topology.csi.example.com/zone"
+	var labelAliases *LabelAliases
+
+	BeforeEach(func() {
+		labelAliases = NewLabelAliases()
+		labelAliases.Set(map[string]string{csiZoneLabel: corev1.LabelTopologyZone})
+	})
+	It("should only normalize registered aliases", func() {
+		Expect(labelAliases.Normalize(csiZoneLabel)).To(Equal(corev1.LabelTopologyZone))
+		Expect(labelAliases.Normalize(corev1.LabelInstanceTypeStable)).To(Equal(corev1.LabelInstanceTypeStable))
+		Expect(NewLabelAliases().Normalize(csiZoneLabel)).To(Equal(csiZoneLabel))
+	})
+	It("should translate aliases in the node selector and node affinity of pods", func() {
+		pod := &corev1.Pod{Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{csiZoneLabel: "test-zone-1"},
+			Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: csiZoneLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}},
+				}},
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+					{Weight: 1, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: csiZoneLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}}},
+				},
+			}},
+		}}
+		labelAliases.InjectPod(pod)
+		Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{corev1.LabelTopologyZone: "test-zone-1"}))
+		Expect(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Key).To(Equal(corev1.LabelTopologyZone))
+		Expect(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Preference.MatchExpressions[0].Key).To(Equal(corev1.LabelTopologyZone))
+	})
+	It("should keep node selector aliases when the pod also selects on their key", func() {
+		pod := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{csiZoneLabel: "test-zone-1", corev1.LabelTopologyZone: "test-zone-2"}}}
+		labelAliases.InjectPod(pod)
+		Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{csiZoneLabel: "test-zone-1", corev1.LabelTopologyZone: "test-zone-2"}))
+	})
+	It("should translate aliases in NodePool requirements without modifying the NodePool", func() {
+		nodePool := &v1.NodePool{}
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: csiZoneLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+		}
+		injected := labelAliases.InjectNodePool(nodePool)
+		Expect(injected.Spec.Template.Spec.Requirements[0].Key).To(Equal(corev1.LabelTopologyZone))
+		Expect(nodePool.Spec.Template.Spec.Requirements[0].Key).To(Equal(csiZoneLabel))
+	})
+	It("shouldn't copy NodePools without aliases", func() {
+		nodePool := &v1.NodePool{}
+		Expect(labelAliases.InjectNodePool(nodePool)).To(BeIdenticalTo(nodePool))
+	})
+})
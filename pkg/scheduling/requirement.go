@@ -265,6 +265,36 @@ func (r *Requirement) String() string {
 	return s
 }
 
+// Fingerprint hashes the requirement, so that requirements with the same fingerprint are equal. The hashes of the values
+// are summed rather than sorting the values, which keeps fingerprinting cheap enough to do on every simulation.
+func (r *Requirement) Fingerprint() uint64 {
+	var values uint64
+	for value := range r.values {
+		values += hashString(value)
+	}
+	h := combineHash(hashString(r.Key), values)
+	h = combineHash(h, lo.Ternary[uint64](r.complement, 1, 0))
+	for _, bound := range []*int{r.greaterThan, r.lessThan, r.MinValues} {
+		h = combineHash(h, lo.Ternary[uint64](bound == nil, 0, 1))
+		h = combineHash(h, uint64(lo.FromPtr(bound)))
+	}
+	return h
+}
+
+// hashString is the 64-bit FNV-1a hash of the string, computed without allocating
+func hashString(s string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+func combineHash(h, v uint64) uint64 {
+	return h ^ (v + 0x9e3779b97f4a7c15 + (h << 6) + (h >> 2))
+}
+
 func withinIntPtrs(valueAsString string, greaterThan, lessThan *int) bool {
 	if greaterThan == nil && lessThan == nil {
 		return true
@@ -17,6 +17,7 @@ limitations under the License.
 package scheduling
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"sort"
@@ -70,6 +71,15 @@ func NewLabelRequirements(labels map[string]string) Requirements {
 	return requirements
 }
 
+// NewTopologyDomainRequirements constructs requirements that allow any of the declared domains of each topology key
+func NewTopologyDomainRequirements(domains []v1.TopologyDomain) Requirements {
+	requirements := NewRequirements()
+	for _, domain := range domains {
+		requirements.Add(NewRequirement(domain.Key, corev1.NodeSelectorOpIn, domain.Values...))
+	}
+	return requirements
+}
+
 // NewPodRequirements constructs requirements from a pod and treats any preferred requirements as required.
 func NewPodRequirements(pod *corev1.Pod) Requirements {
 	return newPodRequirements(pod, podRequirementTypeAll)
@@ -80,6 +90,22 @@ func NewStrictPodRequirements(pod *corev1.Pod) Requirements {
 	return newPodRequirements(pod, podRequirementTypeRequiredOnly)
 }
 
+// NewStrictPodRequirementsPerTerm constructs the strict requirements of a pod for each of its required node affinity
+// terms. The terms are ORed, so a node only has to be compatible with the requirements of one of them.
+func NewStrictPodRequirementsPerTerm(pod *corev1.Pod) []Requirements {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil || pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil ||
+		len(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
+		return []Requirements{NewStrictPodRequirements(pod)}
+	}
+	var perTerm []Requirements
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		requirements := NewLabelRequirements(pod.Spec.NodeSelector)
+		requirements.Add(NewNodeSelectorRequirements(term.MatchExpressions...).Values()...)
+		perTerm = append(perTerm, requirements)
+	}
+	return perTerm
+}
+
 type podRequirementType byte
 
 const (
@@ -123,6 +149,15 @@ func (r Requirements) NodeSelectorRequirements() []v1.NodeSelectorRequirementWit
 	})
 }
 
+// Fingerprint hashes the requirements, so that requirements with the same fingerprint are equal
+func (r Requirements) Fingerprint() uint64 {
+	var h uint64
+	for _, requirement := range r {
+		h += requirement.Fingerprint()
+	}
+	return h
+}
+
 // Add requirements to provided requirements. Mutates existing requirements
 func (r Requirements) Add(requirements ...*Requirement) {
 	for _, requirement := range requirements {
@@ -180,7 +215,11 @@ func (r Requirements) Compatible(requirements Requirements, options ...option.Fu
 		if operator := requirements.Get(key).Operator(); r.Has(key) || operator == corev1.NodeSelectorOpNotIn || operator == corev1.NodeSelectorOpDoesNotExist {
 			continue
 		}
-		errs = multierr.Append(errs, fmt.Errorf("label %q does not have known values%s", key, labelHint(r, key, opts.AllowUndefined)))
+		errs = multierr.Append(errs, IncompatibleKeyError{
+			Key:      key,
+			Incoming: requirements.Get(key),
+			hint:     labelHint(r, key, opts.AllowUndefined),
+		})
 	}
 	// Well Known Labels must intersect, but if not defined, are allowed.
 	return multierr.Append(errs, r.Intersects(requirements))
@@ -250,16 +289,35 @@ func labelHint(r Requirements, key string, allowedUndefined sets.Set[string]) st
 	return ""
 }
 
-// badKeyError allows lazily generating the error string in the case of a bad key error. When requirements fail
-// to match, we are most often interested in the failure and not why it fails.
-type badKeyError struct {
-	key      string
-	incoming *Requirement
-	existing *Requirement
+// IncompatibleKeyError describes a single requirement key that prevents two sets of requirements from being compatible.
+// Existing is nil when the key isn't defined by the existing requirements at all. The error string is generated lazily
+// since, when requirements fail to match, we are most often interested in the failure and not why it fails.
+type IncompatibleKeyError struct {
+	Key      string
+	Existing *Requirement
+	Incoming *Requirement
+	hint     string
 }
 
-func (b badKeyError) Error() string {
-	return fmt.Sprintf("key %s, %s not in %s", b.key, b.incoming, b.existing)
+func (e IncompatibleKeyError) Error() string {
+	if e.Existing == nil {
+		return fmt.Sprintf("label %q does not have known values%s", e.Key, e.hint)
+	}
+	return fmt.Sprintf("key %s, %s not in %s", e.Key, e.Incoming, e.Existing)
+}
+
+// IncompatibleKeys returns every IncompatibleKeyError contained in err, looking through wrapped and aggregated errors
+func IncompatibleKeys(err error) []IncompatibleKeyError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case IncompatibleKeyError:
+		return []IncompatibleKeyError{e}
+	case interface{ Unwrap() []error }:
+		return lo.FlatMap(e.Unwrap(), func(err error, _ int) []IncompatibleKeyError { return IncompatibleKeys(err) })
+	default:
+		return IncompatibleKeys(errors.Unwrap(err))
+	}
 }
 
 // intersectKeys is much faster and allocates less han getting the two key sets separately and intersecting them
@@ -293,10 +351,10 @@ func (r Requirements) Intersects(requirements Requirements) (errs error) {
 					continue
 				}
 			}
-			errs = multierr.Append(errs, badKeyError{
-				key:      key,
-				incoming: incoming,
-				existing: existing,
+			errs = multierr.Append(errs, IncompatibleKeyError{
+				Key:      key,
+				Existing: existing,
+				Incoming: incoming,
 			})
 		}
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package scheduling
 
 import (
+	"fmt"
 	"os"
 	"runtime/pprof"
 	"testing"
@@ -572,6 +573,31 @@ var _ = Describe("Requirements", func() {
 			req := NewRequirements(NewRequirement("deployment", corev1.NodeSelectorOpExists))
 			Expect(unconstrained.Compatible(req).Error()).To(Equal(`label "deployment" does not have known values`))
 		})
+		It("should break down incompatible requirements by key", func() {
+			existing := NewRequirements(
+				NewRequirement(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, "test-zone-1", "test-zone-2"),
+				NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeSpot),
+			)
+			incoming := NewRequirements(
+				NewRequirement(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, "test-zone-3"),
+				NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, v1.CapacityTypeOnDemand),
+				NewRequirement("deployment", corev1.NodeSelectorOpIn, "test"),
+			)
+			err := fmt.Errorf("wrapped, %w", existing.Compatible(incoming, AllowUndefinedWellKnownLabels))
+			keys := IncompatibleKeys(err)
+			Expect(keys).To(HaveLen(3))
+			byKey := lo.KeyBy(keys, func(e IncompatibleKeyError) string { return e.Key })
+			Expect(byKey[corev1.LabelTopologyZone].Existing.Values()).To(ConsistOf("test-zone-1", "test-zone-2"))
+			Expect(byKey[corev1.LabelTopologyZone].Incoming.Values()).To(ConsistOf("test-zone-3"))
+			Expect(byKey[v1.CapacityTypeLabelKey].Existing.Values()).To(ConsistOf(v1.CapacityTypeSpot))
+			Expect(byKey[v1.CapacityTypeLabelKey].Incoming.Values()).To(ConsistOf(v1.CapacityTypeOnDemand))
+			Expect(byKey["deployment"].Existing).To(BeNil())
+			Expect(byKey["deployment"].Incoming.Values()).To(ConsistOf("test"))
+		})
+		It("should return no incompatible keys for unrelated errors", func() {
+			Expect(IncompatibleKeys(nil)).To(BeEmpty())
+			Expect(IncompatibleKeys(fmt.Errorf("unrelated"))).To(BeEmpty())
+		})
 	})
 	Context("NodeSelectorRequirements Conversion", func() {
 		It("should convert combinations of labels to expected NodeSelectorRequirements", func() {
@@ -675,6 +701,35 @@ var _ = Describe("Requirements", func() {
 			Expect(reqs.NodeSelectorRequirements()).To(HaveLen(14))
 		})
 	})
+	Context("Pod Requirements", func() {
+		It("should construct the strict requirements of each required node affinity term", func() {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"team": "a"},
+				Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+						{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}},
+					}},
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+						{Weight: 1, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}}}},
+					},
+				}},
+			}}
+			perTerm := NewStrictPodRequirementsPerTerm(pod)
+			Expect(perTerm).To(HaveLen(2))
+			for i, zone := range []string{"a", "b"} {
+				Expect(perTerm[i].Get("team").Values()).To(ConsistOf("a"))
+				Expect(perTerm[i].Get("zone").Values()).To(ConsistOf(zone))
+				Expect(perTerm[i].Has("arch")).To(BeFalse())
+			}
+		})
+		It("should construct a single set of requirements for pods without required node affinity", func() {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{"team": "a"}}}
+			perTerm := NewStrictPodRequirementsPerTerm(pod)
+			Expect(perTerm).To(HaveLen(1))
+			Expect(perTerm[0].Get("team").Values()).To(ConsistOf("a"))
+		})
+	})
 	Context("Stringify Requirements", func() {
 		It("should print Requirements in the same order", func() {
 			reqs := NewRequirements(
@@ -697,6 +752,27 @@ var _ = Describe("Requirements", func() {
 			Expect(reqs.String()).To(Equal("doesNotExist DoesNotExist, exists Exists, greaterThan1 Exists >1, greaterThan9 Exists >9, in1 In [1], in19 In [1 9], in9 In [9], inA In [A], inAB In [A B], inB In [B], lessThan1 Exists <1, lessThan9 Exists <9, notIn12 NotIn [1 2], notInA NotIn [A]"))
 		})
 	})
+	Context("Fingerprint", func() {
+		It("should fingerprint equal requirements the same regardless of their order", func() {
+			lhs := NewRequirements(NewRequirement("a", corev1.NodeSelectorOpIn, "1", "2"), NewRequirement("b", corev1.NodeSelectorOpGt, "3"))
+			rhs := NewRequirements(NewRequirement("b", corev1.NodeSelectorOpGt, "3"), NewRequirement("a", corev1.NodeSelectorOpIn, "2", "1"))
+			Expect(lhs.Fingerprint()).To(Equal(rhs.Fingerprint()))
+		})
+		It("should fingerprint requirements that differ differently", func() {
+			base := NewRequirements(NewRequirement("a", corev1.NodeSelectorOpIn, "1"))
+			for _, other := range []Requirements{
+				NewRequirements(NewRequirement("a", corev1.NodeSelectorOpIn, "2")),
+				NewRequirements(NewRequirement("a", corev1.NodeSelectorOpNotIn, "1")),
+				NewRequirements(NewRequirement("b", corev1.NodeSelectorOpIn, "1")),
+				NewRequirements(NewRequirement("a", corev1.NodeSelectorOpIn, "1", "2")),
+				NewRequirements(NewRequirementWithFlexibility("a", corev1.NodeSelectorOpIn, lo.ToPtr(1), "1")),
+				NewRequirements(NewRequirement("a", corev1.NodeSelectorOpGt, "1")),
+				NewRequirements(NewRequirement("a", corev1.NodeSelectorOpLt, "1")),
+			} {
+				Expect(other.Fingerprint()).ToNot(Equal(base.Fingerprint()), other.String())
+			}
+		})
+	})
 })
 
 // Keeping this in case we need it, I ran for 1m+ samples and had no issues
@@ -0,0 +1,299 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulation resolves the inputs of scheduling simulations the same way that the provisioner does when it
+// schedules pending pods. It lets cloud providers and tools, like cost estimators, find out which NodePools, instance
+// types, topology domains and daemon overhead Karpenter would schedule a set of pods against without depending on the
+// controllers.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awslabs/operatorpkg/option"
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// Options are the set of options that change how a simulation is built
+type Options struct {
+	// InstanceTypeFilter is applied to the instance types of each NodePool after they are resolved from the cloud
+	// provider and before they are used to schedule
+	InstanceTypeFilter func([]*cloudprovider.InstanceType) []*cloudprovider.InstanceType
+	// IgnoreInstanceTypesMaxAge disables deferring pods once the instance types exceed their maximum age
+	IgnoreInstanceTypesMaxAge bool
+}
+
+// WithInstanceTypeFilter filters the instance types of each NodePool, e.g. to remove offerings that are known to be
+// unavailable
+func WithInstanceTypeFilter(filter func([]*cloudprovider.InstanceType) []*cloudprovider.InstanceType) func(*Options) {
+	return func(o *Options) { o.InstanceTypeFilter = filter }
+}
+
+// WithoutInstanceTypesMaxAge schedules every pod regardless of how old the instance types get, e.g. for simulations
+// whose results aren't launched
+func WithoutInstanceTypesMaxAge() func(*Options) {
+	return func(o *Options) { o.IgnoreInstanceTypesMaxAge = true }
+}
+
+// DaemonSetPodSource returns the most recent pod that a daemonset created, or nil if it hasn't created one
+type DaemonSetPodSource interface {
+	GetDaemonSetPod(*appsv1.DaemonSet) *corev1.Pod
+}
+
+// RequirementInjector adds the scheduling requirements that a pod gets from other objects, like the zones that its
+// volumes are bound to, to the pod
+type RequirementInjector interface {
+	Inject(context.Context, *corev1.Pod) error
+}
+
+// Input is what a scheduler needs, other than the capacity that already exists in the cluster, to schedule pods
+type Input struct {
+	// NodePools are ordered by weight, with the label aliases injected
+	NodePools []*v1.NodePool
+	// InstanceTypes are the instance types of each NodePool, keyed by NodePool name
+	InstanceTypes map[string][]*cloudprovider.InstanceType
+	// InstanceTypesFetchedAt is when the instance types were resolved, or zero if their age doesn't matter
+	InstanceTypesFetchedAt time.Time
+	// Domains are the topology domains by topology key that the NodePools can launch capacity into
+	Domains map[string]sets.Set[string]
+	// Pods are the pods with their requirements injected. Pods whose requirements can't be resolved are left out.
+	Pods []*corev1.Pod
+	// DaemonSetPods represent the overhead that each daemonset adds to the nodes that it schedules to
+	DaemonSetPods []*corev1.Pod
+	// OverheadPods represent the overhead from the operator's daemon overhead manifests
+	OverheadPods []*corev1.Pod
+}
+
+// Simulator resolves the NodePools, instance types, daemonsets and topology domains that a scheduling simulation runs
+// against
+type Simulator struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	daemonSetPods DaemonSetPodSource
+	injectors     []RequirementInjector
+	clock         clock.Clock
+	catalog       *cloudprovider.InstanceTypeCatalog
+	labelAliases  *scheduling.LabelAliases
+}
+
+func NewSimulator(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, daemonSetPods DaemonSetPodSource, clock clock.Clock, labelAliases *scheduling.LabelAliases, injectors ...RequirementInjector) *Simulator {
+	return &Simulator{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		daemonSetPods: daemonSetPods,
+		injectors:     injectors,
+		clock:         clock,
+		catalog:       cloudprovider.NewInstanceTypeCatalog(),
+		labelAliases:  labelAliases,
+	}
+}
+
+// Build resolves the input that a scheduler needs to schedule the pods against the NodePools. The NodePools are ordered
+// by weight in place and the requirements of the pods are injected in place.
+func (s *Simulator) Build(ctx context.Context, pods []*corev1.Pod, nodePools []*v1.NodePool, opts ...option.Function[Options]) (*Input, error) {
+	// nodeTemplates generated from NodePools are ordered by weight
+	// since they are stored within a slice and scheduling
+	// will always attempt to schedule on the first nodeTemplate
+	nodepoolutils.OrderByWeight(nodePools)
+	nodePools = lo.Map(nodePools, func(np *v1.NodePool, _ int) *v1.NodePool { return s.labelAliases.InjectNodePool(np) })
+
+	// The age of the instance types is measured from when they're fetched, not from when the scheduler is created
+	fetchedAt := lo.Ternary(option.Resolve(opts...).IgnoreInstanceTypesMaxAge, time.Time{}, s.clock.Now())
+	instanceTypes := s.InstanceTypes(ctx, nodePools, opts...)
+
+	for _, pod := range pods {
+		s.labelAliases.InjectPod(pod)
+	}
+	daemonSetPods, err := DaemonSetPods(ctx, s.kubeClient, s.daemonSetPods)
+	if err != nil {
+		return nil, fmt.Errorf("getting daemon pods, %w", err)
+	}
+	return &Input{
+		NodePools:              nodePools,
+		InstanceTypes:          instanceTypes,
+		InstanceTypesFetchedAt: fetchedAt,
+		Domains:                Domains(ctx, nodePools, instanceTypes),
+		Pods:                   s.injectRequirements(ctx, pods),
+		DaemonSetPods:          daemonSetPods,
+		OverheadPods:           OverheadPods(ctx),
+	}, nil
+}
+
+// InstanceTypes resolves the instance types of each NodePool, keyed by NodePool name. NodePools whose instance types
+// can't be resolved are left out.
+func (s *Simulator) InstanceTypes(ctx context.Context, nodePools []*v1.NodePool, opts ...option.Function[Options]) map[string][]*cloudprovider.InstanceType {
+	o := option.Resolve(opts...)
+	// Instance types that the cloud provider stopped returning are forgotten once every NodePool is resolved
+	defer s.catalog.Sweep()
+	instanceTypes := map[string][]*cloudprovider.InstanceType{}
+	for _, np := range nodePools {
+		its, err := s.cloudProvider.GetInstanceTypes(ctx, np)
+		if err != nil {
+			log.FromContext(ctx).WithValues("NodePool", klog.KRef("", np.Name)).Error(err, "skipping, unable to resolve instance types")
+			continue
+		}
+		if len(its) == 0 {
+			log.FromContext(ctx).WithValues("NodePool", klog.KRef("", np.Name)).Info("skipping, no resolved instance types found")
+			continue
+		}
+		// NodePools with overlapping instance types share a single copy of each. Extended resources defined by the
+		// cluster operator aren't known to the cloud provider, so the NodePool's view of the instance types adds them to
+		// the capacity of the instance types that advertise them
+		its = s.catalog.NodePoolView(np, s.catalog.Intern(its))
+		if o.InstanceTypeFilter != nil {
+			its = o.InstanceTypeFilter(its)
+		}
+		instanceTypes[np.Name] = its
+	}
+	return instanceTypes
+}
+
+// Domains returns the universe of topology domains by topology key that the NodePools can launch capacity into
+func Domains(ctx context.Context, nodePools []*v1.NodePool, instanceTypes map[string][]*cloudprovider.InstanceType) map[string]sets.Set[string] {
+	domains := map[string]sets.Set[string]{}
+	compliance := scheduling.NewNodeSelectorRequirements(options.FromContext(ctx).ComplianceRequirements.Requirements...)
+	for _, np := range nodePools {
+		its, ok := instanceTypes[np.Name]
+		if !ok {
+			continue
+		}
+		for _, it := range its {
+			// We need to intersect the instance type requirements with the current nodePool requirements.  This
+			// ensures that something like zones from an instance type don't expand the universe of valid domains.
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Spec.Template.Spec.Requirements...)
+			requirements.Add(scheduling.NewLabelRequirements(np.Spec.Template.Labels).Values()...)
+			requirements.Add(compliance.Values()...)
+			requirements.Add(it.Requirements.Values()...)
+
+			for key, requirement := range requirements {
+				// This code used to execute a Union between domains[key] and requirement.Values().
+				// The downside of this is that Union is immutable and takes a copy of the set it is executed upon.
+				// This resulted in a lot of memory pressure on the heap and poor performance
+				// https://github.com/aws/karpenter/issues/3565
+				if domains[key] == nil {
+					domains[key] = sets.New(requirement.Values()...)
+				} else {
+					domains[key].Insert(requirement.Values()...)
+				}
+			}
+		}
+
+		// NodePools are a topology domain of their own so that pods can spread across them
+		if domains[v1.NodePoolLabelKey] == nil {
+			domains[v1.NodePoolLabelKey] = sets.New(np.Name)
+		} else {
+			domains[v1.NodePoolLabelKey].Insert(np.Name)
+		}
+
+		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Spec.Template.Spec.Requirements...)
+		requirements.Add(scheduling.NewLabelRequirements(np.Spec.Template.Labels).Values()...)
+		// Custom topology domains aren't advertised by instance types, so they're only discovered from the NodePool
+		requirements.Add(scheduling.NewTopologyDomainRequirements(np.Spec.TopologyDomains).Values()...)
+		requirements.Add(compliance.Values()...)
+		for key, requirement := range requirements {
+			if requirement.Operator() == corev1.NodeSelectorOpIn {
+				// The following is a performance optimisation, for the explanation see the comment above
+				if domains[key] == nil {
+					domains[key] = sets.New(requirement.Values()...)
+				} else {
+					domains[key].Insert(requirement.Values()...)
+				}
+			}
+		}
+	}
+	return domains
+}
+
+// DaemonSetPods returns a pod for each daemonset in the cluster that represents the overhead that the daemonset adds
+// to every node that it schedules to
+func DaemonSetPods(ctx context.Context, kubeClient client.Client, source DaemonSetPodSource) ([]*corev1.Pod, error) {
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := kubeClient.List(ctx, daemonSetList); err != nil {
+		return nil, fmt.Errorf("listing daemonsets, %w", err)
+	}
+
+	return lo.Map(daemonSetList.Items, func(d appsv1.DaemonSet, _ int) *corev1.Pod {
+		pod := source.GetDaemonSetPod(&d)
+		if pod == nil {
+			// Name the pod after the daemonset so that resources tracked per-pod, like host ports, don't collide. The pod
+			// hasn't been admitted, so it doesn't have the cluster's default tolerations yet.
+			pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: d.Namespace, Name: d.Name}, Spec: *d.Spec.Template.Spec.DeepCopy()}
+			pod = withDefaultTolerations(pod, options.FromContext(ctx).DaemonOverhead.DefaultTolerations)
+		}
+		// Replacing retrieved pod affinity with daemonset pod template required node affinity since this is overridden
+		// by the daemonset controller during pod creation
+		// https://github.com/kubernetes/kubernetes/blob/c5cf0ac1889f55ab51749798bec684aed876709d/pkg/controller/daemon/util/daemonset_util.go#L176
+		if d.Spec.Template.Spec.Affinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity != nil && d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+			if pod.Spec.Affinity == nil {
+				pod.Spec.Affinity = &corev1.Affinity{}
+			}
+			if pod.Spec.Affinity.NodeAffinity == nil {
+				pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+			}
+			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		}
+		return pod
+	}), nil
+}
+
+// OverheadPods returns the pods from the operator's daemon overhead manifests, which run on every node in addition to the
+// cluster's DaemonSets, with the cluster's default tolerations
+func OverheadPods(ctx context.Context) []*corev1.Pod {
+	overhead := options.FromContext(ctx).DaemonOverhead
+	return lo.Map(overhead.Pods, func(p *corev1.Pod, _ int) *corev1.Pod {
+		return withDefaultTolerations(p.DeepCopy(), overhead.DefaultTolerations)
+	})
+}
+
+// withDefaultTolerations adds the tolerations that the cluster adds to every pod at admission to a pod that wasn't
+// admitted yet
+func withDefaultTolerations(pod *corev1.Pod, tolerations []corev1.Toleration) *corev1.Pod {
+	for _, t := range tolerations {
+		if !lo.ContainsBy(pod.Spec.Tolerations, func(existing corev1.Toleration) bool { return existing.MatchToleration(&t) }) {
+			pod.Spec.Tolerations = append(pod.Spec.Tolerations, t)
+		}
+	}
+	return pod
+}
+
+// injectRequirements injects the requirements of each pod, leaving out the pods whose requirements can't be resolved
+func (s *Simulator) injectRequirements(ctx context.Context, pods []*corev1.Pod) []*corev1.Pod {
+	return lo.Filter(pods, func(pod *corev1.Pod, _ int) bool {
+		for _, injector := range s.injectors {
+			if err := injector.Inject(ctx, pod); err != nil {
+				log.FromContext(ctx).WithValues("Pod", klog.KRef(pod.Namespace, pod.Name)).Error(err, "failed getting pod requirements")
+				return false
+			}
+		}
+		return true
+	})
+}
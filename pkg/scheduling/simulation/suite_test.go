@@ -0,0 +1,229 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling/simulation"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+var daemonSetPods *fakeDaemonSetPodSource
+var injector *fakeRequirementInjector
+var simulator *simulation.Simulator
+
+// fakeDaemonSetPodSource returns the pods that the daemonsets created by daemonset name
+type fakeDaemonSetPodSource struct {
+	pods map[string]*corev1.Pod
+}
+
+func (f *fakeDaemonSetPodSource) GetDaemonSetPod(d *appsv1.DaemonSet) *corev1.Pod {
+	if pod, ok := f.pods[d.Name]; ok {
+		return pod.DeepCopy()
+	}
+	return nil
+}
+
+// fakeRequirementInjector fails to inject the requirements of the pods in failed
+type fakeRequirementInjector struct {
+	failed sets.Set[string]
+}
+
+func (f *fakeRequirementInjector) Inject(_ context.Context, pod *corev1.Pod) error {
+	if f.failed.Has(pod.Name) {
+		return fmt.Errorf("resolving requirements of %s", pod.Name)
+	}
+	return nil
+}
+
+func TestSimulation(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simulation")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	fakeClock = clock.NewFakeClock(time.Now())
+	daemonSetPods = &fakeDaemonSetPodSource{pods: map[string]*corev1.Pod{}}
+	injector = &fakeRequirementInjector{failed: sets.New[string]()}
+	simulator = simulation.NewSimulator(env.Client, cloudProvider, daemonSetPods, fakeClock, scheduling.NewLabelAliases(), injector)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+	daemonSetPods.pods = map[string]*corev1.Pod{}
+	injector.failed = sets.New[string]()
+})
+
+var _ = Describe("Simulation", func() {
+	var nodePool *v1.NodePool
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+	})
+	Context("Build", func() {
+		It("should resolve the instance types and domains of the NodePools without launching capacity", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := []*corev1.Pod{test.UnschedulablePod(), test.UnschedulablePod()}
+			input, err := simulator.Build(ctx, pods, []*v1.NodePool{nodePool})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(input.NodePools).To(HaveLen(1))
+			Expect(input.InstanceTypes[nodePool.Name]).ToNot(BeEmpty())
+			Expect(input.InstanceTypesFetchedAt).To(Equal(fakeClock.Now()))
+			Expect(sets.List(input.Domains[v1.NodePoolLabelKey])).To(ConsistOf(nodePool.Name))
+			Expect(input.Pods).To(ConsistOf(pods[0], pods[1]))
+
+			nodeClaims := &v1.NodeClaimList{}
+			Expect(env.Client.List(ctx, nodeClaims)).To(Succeed())
+			Expect(nodeClaims.Items).To(BeEmpty())
+			Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		})
+		It("should order the NodePools by weight", func() {
+			heavyNodePool := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Weight: lo.ToPtr[int32](100)}})
+			input, err := simulator.Build(ctx, nil, []*v1.NodePool{nodePool, heavyNodePool})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lo.Map(input.NodePools, func(np *v1.NodePool, _ int) string { return np.Name })).To(Equal([]string{heavyNodePool.Name, nodePool.Name}))
+		})
+		It("should only use the instance types that pass the filter", func() {
+			input, err := simulator.Build(ctx, nil, []*v1.NodePool{nodePool}, simulation.WithInstanceTypeFilter(func([]*cloudprovider.InstanceType) []*cloudprovider.InstanceType {
+				return nil
+			}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(input.InstanceTypes[nodePool.Name]).To(BeEmpty())
+		})
+		It("should not record when the instance types were fetched when their age is ignored", func() {
+			input, err := simulator.Build(ctx, nil, []*v1.NodePool{nodePool}, simulation.WithoutInstanceTypesMaxAge())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(input.InstanceTypesFetchedAt.IsZero()).To(BeTrue())
+		})
+		It("should leave out the pods whose requirements can't be injected", func() {
+			pods := []*corev1.Pod{test.UnschedulablePod(), test.UnschedulablePod()}
+			injector.failed.Insert(pods[0].Name)
+			input, err := simulator.Build(ctx, pods, []*v1.NodePool{nodePool})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(input.Pods).To(ConsistOf(pods[1]))
+		})
+		It("should include the daemonset and overhead pods", func() {
+			ExpectApplied(ctx, env.Client, test.DaemonSet())
+			overheadPod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Name: "log-agent"}})
+			input, err := simulator.Build(options.ToContext(ctx, test.Options(test.OptionsFields{DaemonOverheadPods: []*corev1.Pod{overheadPod}})), nil, []*v1.NodePool{nodePool})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(input.DaemonSetPods).To(HaveLen(1))
+			Expect(input.OverheadPods).To(HaveLen(1))
+		})
+	})
+	Context("Domains", func() {
+		It("should limit the instance type domains to the NodePool requirements", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}},
+			}
+			instanceTypes := simulator.InstanceTypes(ctx, []*v1.NodePool{nodePool})
+			Expect(instanceTypes).To(HaveKey(nodePool.Name))
+			domains := simulation.Domains(ctx, []*v1.NodePool{nodePool}, instanceTypes)
+			Expect(sets.List(domains[corev1.LabelTopologyZone])).To(ConsistOf("test-zone-1", "test-zone-2"))
+		})
+		It("should include each NodePool with resolved instance types as a domain", func() {
+			otherNodePool := test.NodePool()
+			instanceTypes := simulator.InstanceTypes(ctx, []*v1.NodePool{nodePool, otherNodePool})
+			domains := simulation.Domains(ctx, []*v1.NodePool{nodePool, otherNodePool}, instanceTypes)
+			Expect(sets.List(domains[v1.NodePoolLabelKey])).To(ConsistOf(nodePool.Name, otherNodePool.Name))
+		})
+	})
+	Context("DaemonSetPods", func() {
+		It("should return a pod for each daemonset", func() {
+			daemonSet := test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+			}})
+			ExpectApplied(ctx, env.Client, daemonSet)
+			pods, err := simulation.DaemonSetPods(ctx, env.Client, daemonSetPods)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pods).To(HaveLen(1))
+			Expect(pods[0].Name).To(Equal(daemonSet.Name))
+			Expect(pods[0].Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("1"))
+		})
+		It("should use the pod that the daemonset created", func() {
+			daemonSet := test.DaemonSet()
+			ExpectApplied(ctx, env.Client, daemonSet)
+			daemonSetPods.pods[daemonSet.Name] = test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Name: "created"}})
+			pods, err := simulation.DaemonSetPods(ctx, env.Client, daemonSetPods)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pods).To(HaveLen(1))
+			Expect(pods[0].Name).To(Equal("created"))
+		})
+		It("should add the cluster's default tolerations to the daemonset pods that haven't been created", func() {
+			toleration := corev1.Toleration{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}
+			daemonSet := test.DaemonSet()
+			ExpectApplied(ctx, env.Client, daemonSet)
+			pods, err := simulation.DaemonSetPods(options.ToContext(ctx, test.Options(test.OptionsFields{DaemonDefaultTolerations: []corev1.Toleration{toleration}})), env.Client, daemonSetPods)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pods).To(HaveLen(1))
+			Expect(pods[0].Spec.Tolerations).To(ContainElement(toleration))
+		})
+	})
+	Context("OverheadPods", func() {
+		It("should return no pods when no manifests are configured", func() {
+			Expect(simulation.OverheadPods(ctx)).To(BeEmpty())
+		})
+		It("should add the cluster's default tolerations to the pods", func() {
+			toleration := corev1.Toleration{Key: "example.com/dedicated", Operator: corev1.TolerationOpExists}
+			overheadPod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Name: "log-agent"}})
+			pods := simulation.OverheadPods(options.ToContext(ctx, test.Options(test.OptionsFields{
+				DaemonOverheadPods:       []*corev1.Pod{overheadPod},
+				DaemonDefaultTolerations: []corev1.Toleration{toleration},
+			})))
+			Expect(pods).To(HaveLen(1))
+			Expect(pods[0].Name).To(Equal("log-agent"))
+			Expect(pods[0].Spec.Tolerations).To(ContainElement(toleration))
+			// The configured pods are shared across simulations, so they shouldn't be modified
+			Expect(overheadPod.Spec.Tolerations).ToNot(ContainElement(toleration))
+		})
+	})
+})
@@ -55,6 +55,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	karpv1alpha1 "sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
@@ -240,7 +241,7 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 	wg := sync.WaitGroup{}
 	namespaces := &corev1.NamespaceList{}
 	Expect(c.List(ctx, namespaces)).To(Succeed())
-	ExpectFinalizersRemovedFromList(ctx, c, &corev1.NodeList{}, &v1.NodeClaimList{}, &corev1.PersistentVolumeClaimList{})
+	ExpectFinalizersRemovedFromList(ctx, c, &corev1.NodeList{}, &v1.NodeClaimList{}, &v1.NodePoolList{}, &corev1.PersistentVolumeClaimList{})
 	for _, object := range []client.Object{
 		&corev1.Pod{},
 		&corev1.Node{},
@@ -253,6 +254,7 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 		&v1.NodePool{},
 		&v1alpha1.TestNodeClass{},
 		&v1.NodeClaim{},
+		&karpv1alpha1.NodeDisruptionBudget{},
 	} {
 		for _, namespace := range namespaces.Items {
 			wg.Add(1)
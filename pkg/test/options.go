@@ -22,33 +22,91 @@ import (
 
 	"github.com/imdario/mergo"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
 type OptionsFields struct {
 	// Vendor Neutral
-	ServiceName             *string
-	MetricsPort             *int
-	HealthProbePort         *int
-	KubeClientQPS           *int
-	KubeClientBurst         *int
-	EnableProfiling         *bool
-	DisableLeaderElection   *bool
-	LeaderElectionName      *string
-	LeaderElectionNamespace *string
-	MemoryLimit             *int64
-	LogLevel                *string
-	LogOutputPaths          *string
-	LogErrorOutputPaths     *string
-	BatchMaxDuration        *time.Duration
-	BatchIdleDuration       *time.Duration
-	FeatureGates            FeatureGates
+	ServiceName                       *string
+	MetricsPort                       *int
+	HealthProbePort                   *int
+	KubeClientQPS                     *int
+	KubeClientBurst                   *int
+	EnableProfiling                   *bool
+	DisableLeaderElection             *bool
+	LeaderElectionName                *string
+	LeaderElectionNamespace           *string
+	MemoryLimit                       *int64
+	LogLevel                          *string
+	LogOutputPaths                    *string
+	LogErrorOutputPaths               *string
+	BatchMaxDuration                  *time.Duration
+	BatchIdleDuration                 *time.Duration
+	BatchBypassNamespaces             *string
+	BatchBypassPodSelector            *string
+	ProvisioningBackoffBase           *time.Duration
+	ProvisioningBackoffMax            *time.Duration
+	MaxNodes                          *int
+	MaxCPU                            *int64
+	MaxHourlyCost                     *float64
+	ProvisioningParallelism           *int
+	IgnoreTerminatingPodsAfter        *time.Duration
+	DeterministicNodeClaimNames       *bool
+	NodeClaimNameTemplate             *string
+	NodeClaimLabelTemplates           *string
+	NodeClaimTagTemplates             *string
+	ShardName                         *string
+	EnvironmentName                   *string
+	UnmanagedNodeExpiration           *bool
+	ControllerConcurrency             map[string]int
+	ControllerQPS                     *int
+	ControllerBurst                   *int
+	ReadOnlyStandby                   *bool
+	SyncMinPercent                    *int
+	SyncMaxStaleness                  *time.Duration
+	NodeLaunchSLOTarget               *time.Duration
+	NodeLaunchSLOObjective            *int
+	LocalDataMoveThreshold            *float64
+	NodeProblemConditions             []string
+	NodeProblemTaints                 []string
+	PodAdvisoryWebhookMode            *string
+	WebhookPort                       *int
+	HonorClusterAutoscalerAnnotations *bool
+	WorkloadKubeconfig                *string
+	NonProvisioningPriorityClasses    *string
+	EvictionAPIVersion                *string
+	DeleteEvictionNamespaces          *string
+	DrainLastNamespaces               []string
+	DisruptionWebhookURLs             *string
+	DisruptionWebhookSigningKey       *string
+	DisruptionWebhookRequireAck       *bool
+	ChangeFreezeConfigMap             *string
+	MultiNodeConsolidationTimeout     *time.Duration
+	SingleNodeConsolidationTimeout    *time.Duration
+	InstanceTypesMaxAge               *time.Duration
+	EvictionQueueWorkers              *int
+	CanaryInterval                    *time.Duration
+	CanaryTimeout                     *time.Duration
+	CanaryNamespace                   *string
+	DaemonOverheadPods                []*corev1.Pod
+	DaemonDefaultTolerations          []corev1.Toleration
+	DrainOrdering                     *string
+	MaxDeletionBlockTTL               *time.Duration
+	NodePoolDrainTimeout              *time.Duration
+	ComplianceRequirements            []corev1.NodeSelectorRequirement
+	FeatureGates                      FeatureGates
 }
 
 type FeatureGates struct {
 	NodeRepair              *bool
 	SpotToSpotConsolidation *bool
+	ZonalRebalance          *bool
+	PodBinding              *bool
+	PartialDrain            *bool
+	NodeAdoption            *bool
 }
 
 func Options(overrides ...OptionsFields) *options.Options {
@@ -60,22 +118,76 @@ func Options(overrides ...OptionsFields) *options.Options {
 	}
 
 	return &options.Options{
-		ServiceName:           lo.FromPtrOr(opts.ServiceName, ""),
-		MetricsPort:           lo.FromPtrOr(opts.MetricsPort, 8080),
-		HealthProbePort:       lo.FromPtrOr(opts.HealthProbePort, 8081),
-		KubeClientQPS:         lo.FromPtrOr(opts.KubeClientQPS, 200),
-		KubeClientBurst:       lo.FromPtrOr(opts.KubeClientBurst, 300),
-		EnableProfiling:       lo.FromPtrOr(opts.EnableProfiling, false),
-		DisableLeaderElection: lo.FromPtrOr(opts.DisableLeaderElection, false),
-		MemoryLimit:           lo.FromPtrOr(opts.MemoryLimit, -1),
-		LogLevel:              lo.FromPtrOr(opts.LogLevel, ""),
-		LogOutputPaths:        lo.FromPtrOr(opts.LogOutputPaths, "stdout"),
-		LogErrorOutputPaths:   lo.FromPtrOr(opts.LogErrorOutputPaths, "stderr"),
-		BatchMaxDuration:      lo.FromPtrOr(opts.BatchMaxDuration, 10*time.Second),
-		BatchIdleDuration:     lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
+		ServiceName:                       lo.FromPtrOr(opts.ServiceName, ""),
+		MetricsPort:                       lo.FromPtrOr(opts.MetricsPort, 8080),
+		HealthProbePort:                   lo.FromPtrOr(opts.HealthProbePort, 8081),
+		KubeClientQPS:                     lo.FromPtrOr(opts.KubeClientQPS, 200),
+		KubeClientBurst:                   lo.FromPtrOr(opts.KubeClientBurst, 300),
+		EnableProfiling:                   lo.FromPtrOr(opts.EnableProfiling, false),
+		DisableLeaderElection:             lo.FromPtrOr(opts.DisableLeaderElection, false),
+		MemoryLimit:                       lo.FromPtrOr(opts.MemoryLimit, -1),
+		LogLevel:                          lo.FromPtrOr(opts.LogLevel, ""),
+		LogOutputPaths:                    lo.FromPtrOr(opts.LogOutputPaths, "stdout"),
+		LogErrorOutputPaths:               lo.FromPtrOr(opts.LogErrorOutputPaths, "stderr"),
+		BatchMaxDuration:                  lo.FromPtrOr(opts.BatchMaxDuration, 10*time.Second),
+		BatchIdleDuration:                 lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
+		BatchBypassNamespaces:             lo.FromPtrOr(opts.BatchBypassNamespaces, ""),
+		BatchBypassPodSelector:            lo.FromPtrOr(opts.BatchBypassPodSelector, ""),
+		ProvisioningBackoffBase:           lo.FromPtrOr(opts.ProvisioningBackoffBase, time.Second),
+		ProvisioningBackoffMax:            lo.FromPtrOr(opts.ProvisioningBackoffMax, time.Minute),
+		MaxNodes:                          lo.FromPtrOr(opts.MaxNodes, -1),
+		MaxCPU:                            lo.FromPtrOr(opts.MaxCPU, -1),
+		MaxHourlyCost:                     lo.FromPtrOr(opts.MaxHourlyCost, -1),
+		ProvisioningParallelism:           lo.FromPtrOr(opts.ProvisioningParallelism, 1),
+		IgnoreTerminatingPodsAfter:        lo.FromPtrOr(opts.IgnoreTerminatingPodsAfter, 0),
+		DeterministicNodeClaimNames:       lo.FromPtrOr(opts.DeterministicNodeClaimNames, false),
+		NodeClaimNameTemplate:             lo.FromPtrOr(opts.NodeClaimNameTemplate, ""),
+		NodeClaimLabelTemplates:           lo.FromPtrOr(opts.NodeClaimLabelTemplates, ""),
+		NodeClaimTagTemplates:             lo.FromPtrOr(opts.NodeClaimTagTemplates, ""),
+		ShardName:                         lo.FromPtrOr(opts.ShardName, ""),
+		EnvironmentName:                   lo.FromPtrOr(opts.EnvironmentName, ""),
+		UnmanagedNodeExpiration:           lo.FromPtrOr(opts.UnmanagedNodeExpiration, false),
+		ControllerConcurrency:             options.ControllerConcurrency{MaxConcurrentReconciles: lo.Assign(opts.ControllerConcurrency)},
+		ControllerQPS:                     lo.FromPtrOr(opts.ControllerQPS, 10),
+		ControllerBurst:                   lo.FromPtrOr(opts.ControllerBurst, 100),
+		ReadOnlyStandby:                   lo.FromPtrOr(opts.ReadOnlyStandby, false),
+		SyncMinPercent:                    lo.FromPtrOr(opts.SyncMinPercent, 95),
+		SyncMaxStaleness:                  lo.FromPtrOr(opts.SyncMaxStaleness, 0),
+		NodeLaunchSLOTarget:               lo.FromPtrOr(opts.NodeLaunchSLOTarget, 10*time.Minute),
+		NodeLaunchSLOObjective:            lo.FromPtrOr(opts.NodeLaunchSLOObjective, 95),
+		LocalDataMoveThreshold:            lo.FromPtrOr(opts.LocalDataMoveThreshold, 0.01),
+		NodeProblems:                      options.NodeProblems{ConditionTypes: opts.NodeProblemConditions, TaintKeys: opts.NodeProblemTaints},
+		PodAdvisoryWebhookMode:            lo.FromPtrOr(opts.PodAdvisoryWebhookMode, "Disabled"),
+		WebhookPort:                       lo.FromPtrOr(opts.WebhookPort, 8443),
+		HonorClusterAutoscalerAnnotations: lo.FromPtrOr(opts.HonorClusterAutoscalerAnnotations, false),
+		WorkloadKubeconfig:                lo.FromPtrOr(opts.WorkloadKubeconfig, ""),
+		NonProvisioningPriorityClasses:    lo.FromPtrOr(opts.NonProvisioningPriorityClasses, ""),
+		EvictionAPIVersion:                lo.FromPtrOr(opts.EvictionAPIVersion, "Auto"),
+		DeleteEvictionNamespaces:          lo.FromPtrOr(opts.DeleteEvictionNamespaces, ""),
+		DrainLastNamespaces:               options.DrainLastNamespaces{Namespaces: sets.New(opts.DrainLastNamespaces...)},
+		DisruptionWebhookURLs:             lo.FromPtrOr(opts.DisruptionWebhookURLs, ""),
+		DisruptionWebhookSigningKey:       lo.FromPtrOr(opts.DisruptionWebhookSigningKey, ""),
+		DisruptionWebhookRequireAck:       lo.FromPtrOr(opts.DisruptionWebhookRequireAck, false),
+		ChangeFreezeConfigMap:             lo.FromPtrOr(opts.ChangeFreezeConfigMap, ""),
+		MultiNodeConsolidationTimeout:     lo.FromPtrOr(opts.MultiNodeConsolidationTimeout, time.Minute),
+		SingleNodeConsolidationTimeout:    lo.FromPtrOr(opts.SingleNodeConsolidationTimeout, 3*time.Minute),
+		InstanceTypesMaxAge:               lo.FromPtrOr(opts.InstanceTypesMaxAge, 5*time.Minute),
+		EvictionQueueWorkers:              lo.FromPtrOr(opts.EvictionQueueWorkers, 10),
+		CanaryInterval:                    lo.FromPtrOr(opts.CanaryInterval, 0),
+		CanaryTimeout:                     lo.FromPtrOr(opts.CanaryTimeout, 15*time.Minute),
+		CanaryNamespace:                   lo.FromPtrOr(opts.CanaryNamespace, "default"),
+		DaemonOverhead:                    options.DaemonOverhead{Pods: opts.DaemonOverheadPods, DefaultTolerations: opts.DaemonDefaultTolerations},
+		DrainOrdering:                     lo.FromPtrOr(opts.DrainOrdering, "Default"),
+		MaxDeletionBlockTTL:               lo.FromPtrOr(opts.MaxDeletionBlockTTL, time.Hour),
+		NodePoolDrainTimeout:              lo.FromPtrOr(opts.NodePoolDrainTimeout, 0),
+		ComplianceRequirements:            options.ComplianceRequirements{Requirements: opts.ComplianceRequirements},
 		FeatureGates: options.FeatureGates{
 			NodeRepair:              lo.FromPtrOr(opts.FeatureGates.NodeRepair, false),
 			SpotToSpotConsolidation: lo.FromPtrOr(opts.FeatureGates.SpotToSpotConsolidation, false),
+			ZonalRebalance:          lo.FromPtrOr(opts.FeatureGates.ZonalRebalance, false),
+			PodBinding:              lo.FromPtrOr(opts.FeatureGates.PodBinding, false),
+			PartialDrain:            lo.FromPtrOr(opts.FeatureGates.PartialDrain, false),
+			NodeAdoption:            lo.FromPtrOr(opts.FeatureGates.NodeAdoption, false),
 		},
 	}
 }
@@ -24,6 +24,7 @@ import (
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -76,3 +77,20 @@ func ReschedulingCost(ctx context.Context, pods []*corev1.Pod) float64 {
 	}
 	return cost
 }
+
+// LocalDataMoveCost returns the cost of moving the pod's local data to another node for pods that opt in through the
+// karpenter.sh/costly-to-move-weight annotation. The cost is the weight multiplied by the GiB of local data.
+func LocalDataMoveCost(ctx context.Context, p *corev1.Pod, localData resource.Quantity) float64 {
+	weightStr, ok := p.Annotations[v1.CostlyToMoveWeightAnnotationKey]
+	if !ok {
+		return 0
+	}
+	weight, err := strconv.ParseFloat(weightStr, 64)
+	if err != nil {
+		log.FromContext(ctx).Error(err, fmt.Sprintf("failed parsing %s=%s from pod %s",
+			v1.CostlyToMoveWeightAnnotationKey, weightStr, client.ObjectKeyFromObject(p)))
+		return 0
+	}
+	// negative weights can't make a pod cheaper to move than a pod without local data
+	return math.Max(weight, 0) * localData.AsApproximateFloat64() / math.Pow(2, 30)
+}
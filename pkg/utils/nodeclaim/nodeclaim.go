@@ -42,6 +42,12 @@ func IsManaged(nodeClaim *v1.NodeClaim, cp cloudprovider.CloudProvider) bool {
 	})
 }
 
+// IsManuallyOwned returns whether the NodeClaim was created directly by a user, rather than provisioned for pending pods,
+// through the karpenter.sh/owner=manual annotation
+func IsManuallyOwned(nodeClaim *v1.NodeClaim) bool {
+	return nodeClaim.Annotations[v1.OwnerAnnotationKey] == v1.OwnerManual
+}
+
 // IsManagedPredicateFuncs is used to filter controller-runtime NodeClaim watches to NodeClaims managed by the given cloudprovider.
 func IsManagedPredicateFuncs(cp cloudprovider.CloudProvider) predicate.Funcs {
 	return predicate.NewPredicateFuncs(func(o client.Object) bool {
@@ -236,3 +242,21 @@ func UpdateNodeOwnerReferences(nodeClaim *v1.NodeClaim, node *corev1.Node) *core
 	})
 	return node
 }
+
+// Delete records the reason that the NodeClaim is being terminated in its status and then deletes it. If a reason has
+// already been recorded it's kept so that the NodeClaim is attributed to whatever originally triggered its termination.
+func Delete(ctx context.Context, c client.Client, nodeClaim *v1.NodeClaim, reason v1.TerminationReason) error {
+	if nodeClaim.Status.TerminationReason == "" {
+		stored := nodeClaim.DeepCopy()
+		nodeClaim.Status.TerminationReason = reason
+		if err := c.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+			return fmt.Errorf("recording termination reason, %w", err)
+		}
+	}
+	return c.Delete(ctx, nodeClaim)
+}
+
+// TerminationReason returns the reason that the NodeClaim was terminated, defaulting to manual when no reason was recorded
+func TerminationReason(nodeClaim *v1.NodeClaim) v1.TerminationReason {
+	return lo.Ternary(nodeClaim.Status.TerminationReason != "", nodeClaim.Status.TerminationReason, v1.TerminationReasonManual)
+}
@@ -17,14 +17,20 @@ limitations under the License.
 package pod
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 // IsActive checks if Karpenter should consider this pod as running by ensuring that the pod:
@@ -104,6 +110,37 @@ func IsDisruptable(pod *corev1.Pod) bool {
 	return !(IsActive(pod) && HasDoNotDisrupt(pod))
 }
 
+// IsDisruptableByClusterAutoscaler checks if the Cluster Autoscaler would scale down the node of a pod based on the
+// `cluster-autoscaler.kubernetes.io/safe-to-evict` and `cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes`
+// annotations on the pod. It returns false for an actively running pod that isn't owned by a DaemonSet or a Node and that:
+// - Has the `cluster-autoscaler.kubernetes.io/safe-to-evict=false` annotation OR
+// - Has a hostPath or disk-backed emptyDir volume that isn't listed in `cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes`,
+// unless the pod has the `cluster-autoscaler.kubernetes.io/safe-to-evict=true` annotation
+func IsDisruptableByClusterAutoscaler(pod *corev1.Pod) bool {
+	if !IsActive(pod) || IsOwnedByDaemonSet(pod) || IsOwnedByNode(pod) {
+		return true
+	}
+	switch pod.Annotations[v1.ClusterAutoscalerSafeToEvictAnnotationKey] {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return len(UnsafeLocalVolumes(pod)) == 0
+}
+
+// UnsafeLocalVolumes returns the names of the pod's hostPath and disk-backed emptyDir volumes that aren't marked as safe
+// to evict through the `cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes` annotation
+func UnsafeLocalVolumes(pod *corev1.Pod) []string {
+	safe := sets.New(lo.Map(strings.Split(pod.Annotations[v1.ClusterAutoscalerSafeToEvictLocalVolumesAnnotationKey], ","), func(name string, _ int) string {
+		return strings.TrimSpace(name)
+	})...)
+	return lo.FilterMap(pod.Spec.Volumes, func(v corev1.Volume, _ int) (string, bool) {
+		local := v.HostPath != nil || (v.EmptyDir != nil && v.EmptyDir.Medium != corev1.StorageMediumMemory)
+		return v.Name, local && !safe.Has(v.Name)
+	})
+}
+
 // FailedToSchedule ensures that the kube-scheduler has seen this pod and has intentionally
 // marked this pod with a condition, noting that it thinks that the pod can't schedule anywhere
 // It does this by marking the pod status condition "PodScheduled" as "Unschedulable"
@@ -119,6 +156,21 @@ func FailedToSchedule(pod *corev1.Pod) bool {
 	return false
 }
 
+// quotaBlockedMessage matches the messages that the capacity scheduling plugin uses when its ElasticQuota refuses to
+// schedule a pod, and the "exceeded quota: <name>" message that a ResourceQuota rejects a pod with
+var quotaBlockedMessage = regexp.MustCompile(`ElasticQuota \S+ is more than Max|total ElasticQuota used is more than min|exceeded quota: \S+`)
+
+// IsQuotaBlocked returns true if the pod was marked as unschedulable because scheduling it would exceed a quota. New
+// capacity doesn't help these pods schedule, so Karpenter shouldn't launch capacity for them.
+func IsQuotaBlocked(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Reason == corev1.PodReasonUnschedulable && quotaBlockedMessage.MatchString(condition.Message) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsScheduled(pod *corev1.Pod) bool {
 	return pod.Spec.NodeName != ""
 }
@@ -143,6 +195,17 @@ func IsStuckTerminating(pod *corev1.Pod, clk clock.Clock) bool {
 	return IsTerminating(pod) && clk.Since(pod.DeletionTimestamp.Time) > time.Minute
 }
 
+// IsTerminatingLongerThan returns true if the pod was requested to be deleted more than the passed duration ago. The
+// pod DeletionTimestamp is set to the deletion request time plus its grace period, so the grace period is subtracted
+// to find when the deletion was requested.
+func IsTerminatingLongerThan(pod *corev1.Pod, clk clock.Clock, d time.Duration) bool {
+	if !IsTerminating(pod) {
+		return false
+	}
+	gracePeriod := time.Duration(lo.FromPtr(pod.DeletionGracePeriodSeconds)) * time.Second
+	return clk.Since(pod.DeletionTimestamp.Add(-gracePeriod)) > d
+}
+
 func IsOwnedByStatefulSet(pod *corev1.Pod) bool {
 	return IsOwnedBy(pod, []schema.GroupVersionKind{
 		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
@@ -155,6 +218,12 @@ func IsOwnedByDaemonSet(pod *corev1.Pod) bool {
 	})
 }
 
+func IsOwnedByJob(pod *corev1.Pod) bool {
+	return IsOwnedBy(pod, []schema.GroupVersionKind{
+		{Group: "batch", Version: "v1", Kind: "Job"},
+	})
+}
+
 // IsOwnedByNode returns true if the pod is a static pod owned by a specific node
 func IsOwnedByNode(pod *corev1.Pod) bool {
 	return IsOwnedBy(pod, []schema.GroupVersionKind{
@@ -180,6 +249,41 @@ func HasDoNotDisrupt(pod *corev1.Pod) bool {
 	return pod.Annotations[v1.DoNotDisruptAnnotationKey] == "true"
 }
 
+// InstanceFamilyPreferences returns the instance families that the pod prefers through the
+// karpenter.sh/instance-family-preference annotation, e.g. karpenter.sh/instance-family-preference: "m7i,m6i"
+func InstanceFamilyPreferences(pod *corev1.Pod) []string {
+	return lo.FilterMap(strings.Split(pod.Annotations[v1.InstanceFamilyPreferenceAnnotationKey], ","), func(family string, _ int) (string, bool) {
+		family = strings.TrimSpace(family)
+		return family, family != ""
+	})
+}
+
+// LocalData returns the amount of data that the pod is expected to keep in disk-backed emptyDir volumes, which is
+// lost when the pod is moved to another node. This is estimated from the pod's ephemeral-storage requests, since a
+// volume's sizeLimit is only an upper bound on its usage.
+func LocalData(pod *corev1.Pod) resource.Quantity {
+	if !lo.ContainsBy(pod.Spec.Volumes, func(v corev1.Volume) bool {
+		return v.EmptyDir != nil && v.EmptyDir.Medium != corev1.StorageMediumMemory
+	}) {
+		return resource.Quantity{}
+	}
+	return resources.RequestsForPods(pod)[corev1.ResourceEphemeralStorage]
+}
+
+// ExpectedCompletionTime returns when the pod is expected to complete from the karpenter.sh/expected-completion-time
+// annotation or, if the annotation isn't set, from its activeDeadlineSeconds. False is returned if it isn't known.
+func ExpectedCompletionTime(pod *corev1.Pod) (time.Time, bool) {
+	if value, ok := pod.Annotations[v1.JobExpectedCompletionTimeAnnotationKey]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, true
+		}
+	}
+	if pod.Spec.ActiveDeadlineSeconds != nil && pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Add(time.Duration(*pod.Spec.ActiveDeadlineSeconds) * time.Second), true
+	}
+	return time.Time{}, false
+}
+
 // ToleratesDisruptedNoScheduleTaint returns true if the pod tolerates karpenter.sh/disrupted:NoSchedule taint
 func ToleratesDisruptedNoScheduleTaint(pod *corev1.Pod) bool {
 	return scheduling.Taints([]corev1.Taint{v1.DisruptedNoScheduleTaint}).Tolerates(pod) == nil
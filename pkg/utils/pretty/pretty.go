@@ -97,6 +97,14 @@ func ToSnakeCase(str string) string {
 	return strings.ToLower(snake)
 }
 
+// Truncate returns the string cut down to the max length, with an ellipsis appended if it was cut
+func Truncate(str string, maxLength int) string {
+	if len(str) <= maxLength {
+		return str
+	}
+	return str[:maxLength] + "..."
+}
+
 func Sentence(str string) string {
 	return string(unicode.ToUpper(rune(str[0]))) + str[1:]
 }
@@ -35,4 +35,8 @@ var _ = Describe("Pretty", func() {
 		camelCase := "testKeyValue"
 		Expect(pretty.ToSnakeCase(camelCase)).To(Equal("test_key_value"))
 	})
+	It("should truncate strings longer than the max length", func() {
+		Expect(pretty.Truncate("abcdef", 3)).To(Equal("abc..."))
+		Expect(pretty.Truncate("abc", 3)).To(Equal("abc"))
+	})
 })
@@ -0,0 +1,220 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerid
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+)
+
+// Mapping is an in-memory mapping from provider ids to the names of the Nodes and NodeClaims that resolve to them. It's
+// kept up to date by the manager's informers and shared between controllers, so that hot paths can get the Nodes and
+// NodeClaims for a provider id by name rather than each listing them through the provider id field indexes.
+// Lookups fall back to listing when the mapping has no entry for a provider id or when it's stale, since the informer
+// event handlers that update it run after the cache that the client reads from.
+type Mapping struct {
+	mu                   sync.RWMutex
+	nodes                map[string]sets.Set[string] // provider id -> node names
+	nodeClaims           map[string]sets.Set[string] // provider id -> node claim names
+	nodeProviderIDs      map[string]string           // node name -> provider id
+	nodeClaimProviderIDs map[string]string           // node claim name -> provider id
+}
+
+func NewMapping() *Mapping {
+	return &Mapping{
+		nodes:                map[string]sets.Set[string]{},
+		nodeClaims:           map[string]sets.Set[string]{},
+		nodeProviderIDs:      map[string]string{},
+		nodeClaimProviderIDs: map[string]string{},
+	}
+}
+
+// Register keeps the mapping up to date with the Node and NodeClaim informers of the manager's cache
+func (m *Mapping) Register(ctx context.Context, mgr manager.Manager) error {
+	nodeInformer, err := mgr.GetCache().GetInformer(ctx, &corev1.Node{})
+	if err != nil {
+		return fmt.Errorf("getting node informer, %w", err)
+	}
+	if _, err = nodeInformer.AddEventHandler(eventHandler(m.UpdateNode, m.DeleteNode)); err != nil {
+		return fmt.Errorf("adding node event handler, %w", err)
+	}
+	nodeClaimInformer, err := mgr.GetCache().GetInformer(ctx, &v1.NodeClaim{})
+	if err != nil {
+		return fmt.Errorf("getting nodeclaim informer, %w", err)
+	}
+	if _, err = nodeClaimInformer.AddEventHandler(eventHandler(m.UpdateNodeClaim, m.DeleteNodeClaim)); err != nil {
+		return fmt.Errorf("adding nodeclaim event handler, %w", err)
+	}
+	return nil
+}
+
+func eventHandler[T client.Object](update func(T), remove func(string)) toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(o any) {
+			if obj, ok := o.(T); ok {
+				update(obj)
+			}
+		},
+		UpdateFunc: func(_, o any) {
+			if obj, ok := o.(T); ok {
+				update(obj)
+			}
+		},
+		DeleteFunc: func(o any) {
+			if tombstone, ok := o.(toolscache.DeletedFinalStateUnknown); ok {
+				o = tombstone.Obj
+			}
+			if obj, ok := o.(T); ok {
+				remove(obj.GetName())
+			}
+		},
+	}
+}
+
+func (m *Mapping) UpdateNode(node *corev1.Node) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	update(m.nodes, m.nodeProviderIDs, node.Name, node.Spec.ProviderID)
+}
+
+func (m *Mapping) DeleteNode(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	remove(m.nodes, m.nodeProviderIDs, name)
+}
+
+func (m *Mapping) UpdateNodeClaim(nodeClaim *v1.NodeClaim) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	update(m.nodeClaims, m.nodeClaimProviderIDs, nodeClaim.Name, nodeClaim.Status.ProviderID)
+}
+
+func (m *Mapping) DeleteNodeClaim(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	remove(m.nodeClaims, m.nodeClaimProviderIDs, name)
+}
+
+func update(names map[string]sets.Set[string], providerIDs map[string]string, name, providerID string) {
+	remove(names, providerIDs, name)
+	// Objects that haven't resolved a provider id yet aren't mapped
+	if providerID == "" {
+		return
+	}
+	if _, ok := names[providerID]; !ok {
+		names[providerID] = sets.New[string]()
+	}
+	names[providerID].Insert(name)
+	providerIDs[name] = providerID
+}
+
+func remove(names map[string]sets.Set[string], providerIDs map[string]string, name string) {
+	providerID, ok := providerIDs[name]
+	if !ok {
+		return
+	}
+	delete(providerIDs, name)
+	names[providerID].Delete(name)
+	if names[providerID].Len() == 0 {
+		delete(names, providerID)
+	}
+}
+
+// Nodes returns the Nodes with the given provider id
+func (m *Mapping) Nodes(ctx context.Context, c client.Client, providerID string) ([]*corev1.Node, error) {
+	if nodes, ok := get(ctx, c, m.names(m.nodes, providerID), providerID, func(n *corev1.Node) string { return n.Spec.ProviderID }); ok {
+		return nodes, nil
+	}
+	return nodeclaimutils.AllNodesForNodeClaim(ctx, c, &v1.NodeClaim{Status: v1.NodeClaimStatus{ProviderID: providerID}})
+}
+
+// NodeClaims returns the NodeClaims with the given provider id
+func (m *Mapping) NodeClaims(ctx context.Context, c client.Client, providerID string) ([]*v1.NodeClaim, error) {
+	if nodeClaims, ok := get(ctx, c, m.names(m.nodeClaims, providerID), providerID, func(nc *v1.NodeClaim) string { return nc.Status.ProviderID }); ok {
+		return nodeClaims, nil
+	}
+	return nodeutils.GetNodeClaims(ctx, c, &corev1.Node{Spec: corev1.NodeSpec{ProviderID: providerID}})
+}
+
+// NodeForNodeClaim is equivalent to nodeclaimutils.NodeForNodeClaim, returning the same errors when no Node or multiple
+// Nodes have the provider id of the NodeClaim
+func (m *Mapping) NodeForNodeClaim(ctx context.Context, c client.Client, nodeClaim *v1.NodeClaim) (*corev1.Node, error) {
+	nodes, err := m.Nodes(ctx, c, nodeClaim.Status.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) > 1 {
+		return nil, &nodeclaimutils.DuplicateNodeError{ProviderID: nodeClaim.Status.ProviderID}
+	}
+	if len(nodes) == 0 {
+		return nil, &nodeclaimutils.NodeNotFoundError{ProviderID: nodeClaim.Status.ProviderID}
+	}
+	return nodes[0], nil
+}
+
+// NodeClaimForNode is equivalent to nodeutils.NodeClaimForNode, returning the same errors when no NodeClaim or multiple
+// NodeClaims have the provider id of the Node
+func (m *Mapping) NodeClaimForNode(ctx context.Context, c client.Client, node *corev1.Node) (*v1.NodeClaim, error) {
+	nodeClaims, err := m.NodeClaims(ctx, c, node.Spec.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeClaims) > 1 {
+		return nil, &nodeutils.DuplicateNodeClaimError{ProviderID: node.Spec.ProviderID}
+	}
+	if len(nodeClaims) == 0 {
+		return nil, &nodeutils.NodeClaimNotFoundError{ProviderID: node.Spec.ProviderID}
+	}
+	return nodeClaims[0], nil
+}
+
+func (m *Mapping) names(names map[string]sets.Set[string], providerID string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sets.List(names[providerID])
+}
+
+// get gets the objects with the given names, returning false if the names are empty or stale so that the caller
+// falls back to listing the objects by their provider id
+func get[T any, PT interface {
+	*T
+	client.Object
+}](ctx context.Context, c client.Client, names []string, providerID string, providerIDOf func(PT) string) ([]PT, bool) {
+	if len(names) == 0 {
+		return nil, false
+	}
+	objs := make([]PT, 0, len(names))
+	for _, name := range names {
+		obj := PT(new(T))
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, obj); err != nil || providerIDOf(obj) != providerID {
+			return nil, false
+		}
+		objs = append(objs, obj)
+	}
+	return objs, true
+}
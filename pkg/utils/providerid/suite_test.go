@@ -0,0 +1,141 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providerid_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/providerid"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	ctx     context.Context
+	env     *test.Environment
+	mapping *providerid.Mapping
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ProviderIDUtils")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx), test.NodeClaimProviderIDFieldIndexer(ctx)))
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	mapping = providerid.NewMapping()
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("Mapping", func() {
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	BeforeEach(func() {
+		nodeClaim, node = test.NodeClaimAndNode()
+	})
+	It("should get the nodeclaim and node for a provider id from the mapping", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		mapping.UpdateNodeClaim(nodeClaim)
+		mapping.UpdateNode(node)
+
+		nc, err := mapping.NodeClaimForNode(ctx, env.Client, node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nc.Name).To(Equal(nodeClaim.Name))
+		n, err := mapping.NodeForNodeClaim(ctx, env.Client, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n.Name).To(Equal(node.Name))
+	})
+	It("should fall back to listing when the provider id isn't mapped", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+
+		nc, err := mapping.NodeClaimForNode(ctx, env.Client, node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nc.Name).To(Equal(nodeClaim.Name))
+		n, err := mapping.NodeForNodeClaim(ctx, env.Client, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n.Name).To(Equal(node.Name))
+	})
+	It("should fall back to listing when the mapped objects no longer exist", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		mapping.UpdateNodeClaim(nodeClaim)
+		ExpectDeleted(ctx, env.Client, nodeClaim)
+
+		_, err := mapping.NodeClaimForNode(ctx, env.Client, node)
+		Expect(nodeutils.IsNodeClaimNotFoundError(err)).To(BeTrue())
+	})
+	It("should fall back to listing when the mapped objects have a different provider id", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		mapping.UpdateNode(node)
+		node.Spec.ProviderID = test.RandomProviderID()
+		ExpectApplied(ctx, env.Client, node)
+
+		_, err := mapping.NodeForNodeClaim(ctx, env.Client, nodeClaim)
+		Expect(nodeclaimutils.IsNodeNotFoundError(err)).To(BeTrue())
+	})
+	It("should return an error when multiple nodes are mapped to the provider id", func() {
+		node2 := test.Node(test.NodeOptions{ProviderID: node.Spec.ProviderID})
+		ExpectApplied(ctx, env.Client, nodeClaim, node, node2)
+		mapping.UpdateNode(node)
+		mapping.UpdateNode(node2)
+
+		_, err := mapping.NodeForNodeClaim(ctx, env.Client, nodeClaim)
+		Expect(nodeclaimutils.IsDuplicateNodeError(err)).To(BeTrue())
+	})
+	It("should stop mapping deleted objects to the provider id", func() {
+		node2 := test.Node(test.NodeOptions{ProviderID: node.Spec.ProviderID})
+		ExpectApplied(ctx, env.Client, nodeClaim, node, node2)
+		mapping.UpdateNode(node)
+		mapping.UpdateNode(node2)
+		ExpectDeleted(ctx, env.Client, node2)
+		mapping.DeleteNode(node2.Name)
+
+		n, err := mapping.NodeForNodeClaim(ctx, env.Client, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n.Name).To(Equal(node.Name))
+	})
+	It("should not map nodeclaims that haven't resolved a provider id", func() {
+		nodeClaim.Status.ProviderID = ""
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		mapping.UpdateNodeClaim(nodeClaim)
+
+		nodes, err := mapping.Nodes(ctx, env.Client, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodes).To(BeEmpty())
+	})
+})
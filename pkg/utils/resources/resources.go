@@ -96,6 +96,8 @@ func Subtract(lhs, rhs v1.ResourceList) v1.ResourceList {
 // podRequests calculates the max between the sum of container resources and max of initContainers along with sidecar feature consideration
 // inspired from https://github.com/kubernetes/kubernetes/blob/e2afa175e4077d767745246662170acd86affeaf/pkg/api/v1/resource/helpers.go#L96
 // https://kubernetes.io/blog/2023/08/25/native-sidecar-containers/
+// Ephemeral containers aren't included since they can't be given resources and aren't considered by the kube-scheduler.
+// This is used for both new and existing nodes so that we never nominate a node that the kube-scheduler would reject.
 func podRequests(pod *v1.Pod) v1.ResourceList {
 	requests := v1.ResourceList{}
 	restartableInitContainerReqs := v1.ResourceList{}
@@ -19,6 +19,7 @@ package termination_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -121,6 +122,81 @@ var _ = Describe("TerminationUtils", func() {
 		Expect(instanceTerminated).To(BeTrue())
 		Expect(err).NotTo(HaveOccurred())
 	})
+	It("should set the typed reason on the Terminating status condition when the cloudProvider classifies the delete error", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		cloudProvider.NextDeleteErr = cloudprovider.NewProviderError(fmt.Errorf("not authorized to terminate instance"), cloudprovider.ProviderErrorReasonUnauthorized, "UnauthorizedOperation")
+		instanceTerminated, err := termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(instanceTerminated).To(BeFalse())
+		Expect(err).To(HaveOccurred())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating)
+		Expect(cond.IsUnknown()).To(BeTrue())
+		Expect(cond.Reason).To(Equal(string(cloudprovider.ProviderErrorReasonUnauthorized)))
+
+		// Delete is retried since the instance isn't terminating yet
+		instanceTerminated, err = termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(len(cloudProvider.DeleteCalls)).To(BeEquivalentTo(2))
+		Expect(instanceTerminated).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("should truncate the message and only patch the Terminating status condition when it changes", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		cloudProvider.NextDeleteErr = cloudprovider.NewProviderError(fmt.Errorf("%s", strings.Repeat("a", 1000)), cloudprovider.ProviderErrorReasonUnauthorized, "UnauthorizedOperation")
+		_, err := termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(err).To(HaveOccurred())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(len(nodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating).Message)).To(BeNumerically("<=", 303))
+		resourceVersion := nodeClaim.ResourceVersion
+
+		cloudProvider.NextDeleteErr = cloudprovider.NewProviderError(fmt.Errorf("%s", strings.Repeat("a", 1000)), cloudprovider.ProviderErrorReasonUnauthorized, "UnauthorizedOperation")
+		_, err = termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(err).To(HaveOccurred())
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).ResourceVersion).To(Equal(resourceVersion))
+	})
+	It("should set the typed reason on the Terminating status condition when the cloudProvider classifies the get error", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		instanceTerminated, err := termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(instanceTerminated).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		cloudProvider.NextGetErr = cloudprovider.NewProviderError(fmt.Errorf("not authorized to describe instance"), cloudprovider.ProviderErrorReasonUnauthorized, "UnauthorizedOperation")
+		instanceTerminated, err = termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(instanceTerminated).To(BeFalse())
+		Expect(err).To(HaveOccurred())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating)
+		// The condition stays true so that Delete isn't called again
+		Expect(cond.IsTrue()).To(BeTrue())
+		Expect(cond.Reason).To(Equal(string(cloudprovider.ProviderErrorReasonUnauthorized)))
+		Expect(cloudProvider.DeleteCalls).To(HaveLen(1))
+	})
+	It("should track the operation and poll for completion when the cloudProvider deletes the instance asynchronously", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		cloudProvider.NextDeleteErr = cloudprovider.NewDeletionInProgressError(fmt.Errorf("instance is shutting down"), "op-1234")
+		instanceTerminated, err := termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(instanceTerminated).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating)
+		Expect(cond.IsTrue()).To(BeTrue())
+		Expect(cond.Reason).To(Equal("DeletionInProgress"))
+		Expect(cond.Message).To(ContainSubstring("op-1234"))
+
+		// Subsequent calls poll the cloudProvider for completion rather than calling Delete again
+		instanceTerminated, err = termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(len(cloudProvider.GetCalls)).To(BeEquivalentTo(1))
+		Expect(instanceTerminated).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(cloudProvider.CreatedNodeClaims, nodeClaim.Status.ProviderID)
+		instanceTerminated, err = termination.EnsureTerminated(ctx, env.Client, nodeClaim, cloudProvider)
+		Expect(instanceTerminated).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+	})
 	It("shouldn't mark the root condition of the NodeClaim as unknown when setting the Termination condition", func() {
 		for _, cond := range []string{
 			v1.ConditionTypeLaunched,
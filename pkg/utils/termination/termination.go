@@ -18,16 +18,22 @@ package termination
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/awslabs/operatorpkg/status"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
 // EnsureTerminated is a helper function that takes a v1.NodeClaim and calls cloudProvider.Delete() if status condition
-// on nodeClaim is not terminating. If it is terminating then it will call cloudProvider.Get() to check if the instance
+// on nodeClaim is not terminating. If the cloudProvider deletes the instance asynchronously, the operation is recorded on
+// the terminating status condition. If it is terminating then it will call cloudProvider.Get() to check if the instance
 // is terminated or not. It will return an error and a boolean that indicates if the instance is terminated or not. We simply return
 // conflict or a NotFound error if we encounter it while updating the status on nodeClaim.
 func EnsureTerminated(ctx context.Context, c client.Client, nodeClaim *v1.NodeClaim, cloudProvider cloudprovider.CloudProvider) (terminated bool, err error) {
@@ -35,6 +41,18 @@ func EnsureTerminated(ctx context.Context, c client.Client, nodeClaim *v1.NodeCl
 	if !nodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating).IsTrue() {
 		// If not then call Delete on cloudProvider to trigger termination and always requeue reconciliation
 		if err = cloudProvider.Delete(ctx, nodeClaim); err != nil {
+			// The CloudProvider is deleting the instance asynchronously, so we track the operation on the NodeClaim
+			// and poll for its completion rather than waiting on the deletion
+			dipErr := &cloudprovider.DeletionInProgressError{}
+			if errors.As(err, &dipErr) {
+				stored := nodeClaim.DeepCopy()
+				updateStatusConditionsForDeleting(nodeClaim)
+				nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeInstanceTerminating, "DeletionInProgress", fmt.Sprintf("Instance deletion in progress, operation %s", dipErr.OperationID))
+				if err = c.Status().Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+					return false, err
+				}
+				return false, nil
+			}
 			if cloudprovider.IsNodeClaimNotFoundError(err) {
 				stored := nodeClaim.DeepCopy()
 				updateStatusConditionsForDeleting(nodeClaim)
@@ -47,6 +65,12 @@ func EnsureTerminated(ctx context.Context, c client.Client, nodeClaim *v1.NodeCl
 				// Instance is terminated
 				return true, nil
 			}
+			// Surface classified failures on the NodeClaim so that they're visible without reading the controller logs
+			if providerErr := cloudprovider.ProviderErrorFor(err); providerErr != nil {
+				if patchErr := patchTerminatingCondition(ctx, c, nodeClaim, metav1.ConditionUnknown, providerErr); patchErr != nil {
+					return false, patchErr
+				}
+			}
 			return false, fmt.Errorf("terminating cloudprovider instance, %w", err)
 		}
 
@@ -65,11 +89,35 @@ func EnsureTerminated(ctx context.Context, c client.Client, nodeClaim *v1.NodeCl
 		if cloudprovider.IsNodeClaimNotFoundError(err) {
 			return true, nil
 		}
+		// The instance is still terminating, so classified failures are surfaced without changing the condition's status
+		// which would otherwise trigger another delete
+		if providerErr := cloudprovider.ProviderErrorFor(err); providerErr != nil {
+			if patchErr := patchTerminatingCondition(ctx, c, nodeClaim, metav1.ConditionTrue, providerErr); patchErr != nil {
+				return false, patchErr
+			}
+		}
 		return false, fmt.Errorf("getting cloudprovider instance, %w", err)
 	}
 	return false, nil
 }
 
+// patchTerminatingCondition sets the reason and message of the classified error on the InstanceTerminating status
+// condition. The NodeClaim is only patched if the condition changes so that an error that persists across reconciles
+// doesn't patch the NodeClaim every time.
+func patchTerminatingCondition(ctx context.Context, c client.Client, nodeClaim *v1.NodeClaim, conditionStatus metav1.ConditionStatus, providerErr *cloudprovider.ProviderError) error {
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.StatusConditions().Set(status.Condition{
+		Type:    v1.ConditionTypeInstanceTerminating,
+		Status:  conditionStatus,
+		Reason:  string(providerErr.Reason),
+		Message: pretty.Truncate(providerErr.Error(), 300),
+	})
+	if equality.Semantic.DeepEqual(stored.Status.Conditions, nodeClaim.Status.Conditions) {
+		return nil
+	}
+	return c.Status().Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{}))
+}
+
 func updateStatusConditionsForDeleting(nc *v1.NodeClaim) {
 	// perform a no-op for whatever the status condition is currently set to
 	// so that we bump the observed generation to the latest and prevent the nodeclaim
@@ -0,0 +1,215 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podadvisory_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+	"sigs.k8s.io/karpenter/pkg/webhooks/podadvisory"
+)
+
+var ctx context.Context
+var env *test.Environment
+var cloudProvider *fake.CloudProvider
+var webhook *podadvisory.Webhook
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PodAdvisory")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	cloudProvider = fake.NewCloudProvider()
+	webhook = podadvisory.NewWebhook(env.Client, cloudProvider)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PodAdvisoryWebhookMode: lo.ToPtr("Warn")}))
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+func admissionRequest(pod *corev1.Pod) admission.Request {
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Namespace: pod.Namespace,
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: lo.Must(json.Marshal(pod))},
+	}}
+}
+
+var _ = Describe("PodAdvisory", func() {
+	var nodePool *v1.NodePool
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{
+			ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"team": "a"}},
+		}}})
+	})
+	It("should allow pods that a nodepool can schedule without a warning", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "a"}})
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(BeEmpty())
+	})
+	It("should warn about pods with a nodeSelector that no nodepool defines", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"tema": "a"}})
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(HaveLen(1))
+		Expect(resp.Warnings[0]).To(ContainSubstring(nodePool.Name))
+		Expect(resp.Warnings[0]).To(ContainSubstring(`"tema"`))
+	})
+	It("should warn about pods that don't tolerate the taints of any nodepool", func() {
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "a", Effect: corev1.TaintEffectNoSchedule}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.Pod()
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(HaveLen(1))
+	})
+	It("should allow pods that are compatible with at least one nodepool", func() {
+		other := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{
+			ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"team": "b"}},
+		}}})
+		ExpectApplied(ctx, env.Client, nodePool, other)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "b"}})
+
+		Expect(webhook.Validate(ctx, pod)).To(Succeed())
+	})
+	It("should allow pods with any required node affinity term that a nodepool can schedule", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.Pod(test.PodOptions{NodeRequirements: []corev1.NodeSelectorRequirement{{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}}})
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = append(
+			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+		)
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(BeEmpty())
+	})
+	It("should deny pods that no nodepool can schedule in Deny mode", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PodAdvisoryWebhookMode: lo.ToPtr("Deny")}))
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "c"}})
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeFalse())
+	})
+	It("should not deny pods that an unmanaged node can schedule in Deny mode", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PodAdvisoryWebhookMode: lo.ToPtr("Deny")}))
+		node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "c"}}})
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "c"}})
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(HaveLen(1))
+	})
+	It("should deny pods that only a managed node can schedule in Deny mode", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PodAdvisoryWebhookMode: lo.ToPtr("Deny")}))
+		nodeClassRef := test.NodeClaim().Spec.NodeClassRef
+		node := test.Node(test.NodeOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+			"team": "c",
+			v1.NodeClassLabelKey(nodeClassRef.GroupKind()): nodeClassRef.Name,
+		}}})
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "c"}})
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeFalse())
+	})
+	It("should deny pods that don't tolerate the taints of the unmanaged nodes in Deny mode", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PodAdvisoryWebhookMode: lo.ToPtr("Deny")}))
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "c"}},
+			Taints:     []corev1.Taint{{Key: "dedicated", Value: "c", Effect: corev1.TaintEffectNoSchedule}},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "c"}})
+
+		resp := webhook.Handle(ctx, admissionRequest(pod))
+		Expect(resp.Allowed).To(BeFalse())
+	})
+	It("should serve admission reviews over HTTP with the operator's options", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PodAdvisoryWebhookMode: lo.ToPtr("Deny")}))
+		ExpectApplied(ctx, env.Client, nodePool)
+		server := httptest.NewServer(webhook.Admission(ctx))
+		defer server.Close()
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"team": "c"}})
+
+		review := admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+			Request:  lo.ToPtr(admissionRequest(pod).AdmissionRequest),
+		}
+		review.Request.UID = "uid"
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(lo.Must(json.Marshal(review))))
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		response := admissionv1.AdmissionReview{}
+		Expect(json.NewDecoder(resp.Body).Decode(&response)).To(Succeed())
+		Expect(response.Response).ToNot(BeNil())
+		Expect(response.Response.UID).To(BeEquivalentTo("uid"))
+		Expect(response.Response.Allowed).To(BeFalse())
+		Expect(response.Response.Result.Message).To(ContainSubstring(nodePool.Name))
+	})
+	It("should not check pods when there aren't any nodepools", func() {
+		pod := test.Pod(test.PodOptions{NodeSelector: map[string]string{"tema": "a"}})
+		Expect(webhook.Validate(ctx, pod)).To(Succeed())
+	})
+	It("should not check pods that are already bound to a node", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.Pod(test.PodOptions{NodeName: "node", NodeSelector: map[string]string{"tema": "a"}})
+		Expect(webhook.Validate(ctx, pod)).To(Succeed())
+	})
+})
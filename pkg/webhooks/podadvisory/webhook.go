@@ -0,0 +1,191 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podadvisory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// Path is the path that the webhook is served on
+const Path = "/validate-pod-advisory"
+
+// Webhook checks pods at admission against the NodePools in the cluster and warns about pods that no NodePool could
+// ever schedule, e.g. because of a typo in a nodeSelector. It only rejects these pods when the webhook is in Deny
+// mode and Karpenter would have to provision for them, i.e. they also can't schedule to a node that Karpenter doesn't
+// manage. It allows pods whenever the check itself fails so that it never blocks admission on its own errors.
+type Webhook struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	decoder       admission.Decoder
+}
+
+func NewWebhook(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Webhook {
+	return &Webhook{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		decoder:       admission.NewDecoder(kubeClient.Scheme()),
+	}
+}
+
+func (w *Webhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := w.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Pod", klog.KRef(req.Namespace, pod.Name)))
+	err := w.Validate(ctx, pod)
+	if err == nil {
+		return admission.Allowed("")
+	}
+	if !IsUnschedulableError(err) {
+		log.FromContext(ctx).Error(err, "failed checking pod against nodepools")
+		return admission.Allowed("")
+	}
+	if options.FromContext(ctx).PodAdvisoryWebhookMode == "Deny" {
+		unmanaged, unmanagedErr := w.fitsUnmanagedNode(ctx, pod)
+		if unmanagedErr != nil {
+			log.FromContext(ctx).Error(unmanagedErr, "failed checking pod against unmanaged nodes")
+		} else if !unmanaged {
+			return admission.Denied(err.Error())
+		}
+	}
+	return admission.Allowed("").WithWarnings(err.Error())
+}
+
+// fitsUnmanagedNode returns true if the pod is compatible with a node that Karpenter doesn't manage. Karpenter doesn't
+// provision for these pods, so they aren't denied even if no NodePool could schedule them.
+func (w *Webhook) fitsUnmanagedNode(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	nodeList := &corev1.NodeList{}
+	if err := w.kubeClient.List(ctx, nodeList); err != nil {
+		return false, fmt.Errorf("listing nodes, %w", err)
+	}
+	podRequirements := scheduling.NewStrictPodRequirementsPerTerm(pod)
+	return lo.ContainsBy(nodeList.Items, func(node corev1.Node) bool {
+		if nodeutils.IsManaged(&node, w.cloudProvider) {
+			return false
+		}
+		taints := lo.Filter(node.Spec.Taints, func(t corev1.Taint, _ int) bool { return t.Effect != corev1.TaintEffectPreferNoSchedule })
+		if scheduling.Taints(taints).Tolerates(pod) != nil {
+			return false
+		}
+		nodeRequirements := scheduling.NewLabelRequirements(node.Labels)
+		return lo.ContainsBy(podRequirements, func(requirements scheduling.Requirements) bool {
+			return nodeRequirements.Compatible(requirements) == nil
+		})
+	}), nil
+}
+
+// Validate returns an UnschedulableError if the pod is incompatible with every NodePool in the cluster. Pods that are
+// already bound, pods that are owned by a DaemonSet and clusters without any NodePools aren't checked.
+func (w *Webhook) Validate(ctx context.Context, pod *corev1.Pod) error {
+	if pod.Spec.NodeName != "" || podutils.IsOwnedByDaemonSet(pod) {
+		return nil
+	}
+	nodePools, err := nodepoolutils.ListManaged(ctx, w.kubeClient, w.cloudProvider)
+	if err != nil {
+		return fmt.Errorf("listing nodepools, %w", err)
+	}
+	if len(nodePools) == 0 {
+		return nil
+	}
+	// The pod's required node affinity terms are ORed, so a NodePool only has to be compatible with one of them
+	podRequirements := scheduling.NewStrictPodRequirementsPerTerm(pod)
+	reasons := map[string]error{}
+	for _, np := range nodePools {
+		template := scheduler.NewNodeClaimTemplate(np)
+		template.Requirements.Add(scheduling.NewNodeSelectorRequirements(options.FromContext(ctx).ComplianceRequirements.Requirements...).Values()...)
+		if err := scheduling.Taints(template.Spec.Taints).Tolerates(pod); err != nil {
+			reasons[np.Name] = err
+			continue
+		}
+		var errs error
+		for _, requirements := range podRequirements {
+			err := template.Requirements.Compatible(requirements, scheduling.AllowUndefinedWellKnownLabels)
+			if err == nil {
+				return nil
+			}
+			errs = multierr.Append(errs, err)
+		}
+		reasons[np.Name] = errs
+	}
+	return &UnschedulableError{reasons: reasons}
+}
+
+// UnschedulableError is returned when a pod is incompatible with every NodePool in the cluster
+type UnschedulableError struct {
+	reasons map[string]error
+}
+
+func (e *UnschedulableError) Error() string {
+	names := make([]string, 0, len(e.reasons))
+	for name := range e.reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("nodepool %q: %s", name, e.reasons[name]))
+	}
+	return fmt.Sprintf("karpenter can't schedule this pod with any nodepool, %s", strings.Join(msgs, "; "))
+}
+
+func IsUnschedulableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var unschedulableErr *UnschedulableError
+	return errors.As(err, &unschedulableErr)
+}
+
+// Admission returns the admission webhook that serves the Webhook. Admission requests are handled with the context of
+// the HTTP request, so the operator's options and logger are injected from the passed context.
+func (w *Webhook) Admission(ctx context.Context) *webhook.Admission {
+	return &webhook.Admission{
+		Handler: w,
+		WithContextFunc: func(reqCtx context.Context, _ *http.Request) context.Context {
+			return options.ToContext(log.IntoContext(reqCtx, log.FromContext(ctx)), options.FromContext(ctx))
+		},
+	}
+}
+
+func (w *Webhook) Register(ctx context.Context, m manager.Manager) error {
+	m.GetWebhookServer().Register(Path, w.Admission(ctx))
+	return nil
+}